@@ -3,28 +3,59 @@ package main
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	_ "github.com/TubagusAldiMY/go-template/docs" // Import swagger docs
+	grpcServer "github.com/TubagusAldiMY/go-template/internal/delivery/grpc/server"
+	"github.com/TubagusAldiMY/go-template/internal/delivery/http/middleware"
 	"github.com/TubagusAldiMY/go-template/internal/delivery/http/router"
+	apikeyHttp "github.com/TubagusAldiMY/go-template/internal/domain/apikey/delivery/http"
+	apikeyRepo "github.com/TubagusAldiMY/go-template/internal/domain/apikey/repository"
+	apikeyUsecase "github.com/TubagusAldiMY/go-template/internal/domain/apikey/usecase"
+	auditHttp "github.com/TubagusAldiMY/go-template/internal/domain/audit/delivery/http"
+	auditRepo "github.com/TubagusAldiMY/go-template/internal/domain/audit/repository"
+	auditUsecase "github.com/TubagusAldiMY/go-template/internal/domain/audit/usecase"
+	userGraphql "github.com/TubagusAldiMY/go-template/internal/domain/user/delivery/graphql"
+	userGrpc "github.com/TubagusAldiMY/go-template/internal/domain/user/delivery/grpc"
 	userHttp "github.com/TubagusAldiMY/go-template/internal/domain/user/delivery/http"
 	userRepo "github.com/TubagusAldiMY/go-template/internal/domain/user/repository"
 	userUsecase "github.com/TubagusAldiMY/go-template/internal/domain/user/usecase"
 	"github.com/TubagusAldiMY/go-template/internal/infrastructure/cache"
 	"github.com/TubagusAldiMY/go-template/internal/infrastructure/config"
 	"github.com/TubagusAldiMY/go-template/internal/infrastructure/database"
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/health"
 	"github.com/TubagusAldiMY/go-template/internal/infrastructure/messaging"
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/metrics"
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/notification"
+	"github.com/TubagusAldiMY/go-template/internal/shared/constants"
+	"github.com/TubagusAldiMY/go-template/pkg/authz"
+	"github.com/TubagusAldiMY/go-template/pkg/buildinfo"
 	"github.com/TubagusAldiMY/go-template/pkg/crypto"
 	"github.com/TubagusAldiMY/go-template/pkg/jwt"
 	"github.com/TubagusAldiMY/go-template/pkg/logger"
+	appMetrics "github.com/TubagusAldiMY/go-template/pkg/metrics"
+	"github.com/TubagusAldiMY/go-template/pkg/notify"
+	"github.com/TubagusAldiMY/go-template/pkg/scheduler"
+	"github.com/TubagusAldiMY/go-template/pkg/storage"
+	"github.com/TubagusAldiMY/go-template/pkg/tracing"
 	"github.com/TubagusAldiMY/go-template/pkg/validator"
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	amqp "github.com/rabbitmq/amqp091-go"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 )
 
+// emailMaxRetries is how many times the email worker retries a failed
+// delivery before dead-lettering it to email.queue.dlq.
+const emailMaxRetries = 5
+
 // @title Golang DDD Template API
 // @version 1.0
 // @description Production-ready REST API built with Clean Architecture and DDD
@@ -44,6 +75,11 @@ import (
 // @name Authorization
 // @description Type "Bearer" followed by a space and JWT token.
 
+// @securityDefinitions.apikey ApiKey
+// @in header
+// @name X-API-Key
+// @description API key issued through /api-keys, sent as is.
+
 func main() {
 	// Load configuration
 	cfg, err := config.Load()
@@ -52,30 +88,126 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Timestamps read from the database and written to logs use the
+	// configured zone; API responses convert them to UTC.
+	if time.Local, err = cfg.App.Location(); err != nil {
+		fmt.Printf("Failed to load timezone: %v\n", err)
+		os.Exit(1)
+	}
+
 	// Initialize logger
 	if err := logger.Init(logger.Config{
-		Level:  cfg.Log.Level,
-		Format: cfg.Log.Format,
-		Output: cfg.Log.Output,
+		Level:    cfg.Log.Level,
+		Format:   cfg.Log.Format,
+		Output:   cfg.Log.Output,
+		Rotation: cfg.Log.Rotation(),
 	}); err != nil {
 		fmt.Printf("Failed to initialize logger: %v\n", err)
 		os.Exit(1)
 	}
 	defer logger.Sync()
 
+	build := buildinfo.Get()
 	logger.Info("starting application",
 		zap.String("app", cfg.App.Name),
 		zap.String("env", cfg.App.Env),
 		zap.Int("port", cfg.App.Port),
+		zap.String("version", build.Version),
+		zap.String("commit", build.Commit),
+		zap.String("build_date", build.BuildDate),
+		zap.String("go_version", build.GoVersion),
 	)
 
+	// Reload log level, rate limit and CORS settings when the config file
+	// changes.
+	configManager := config.NewManager(cfg)
+	configManager.Subscribe(func(c *config.Config) {
+		logger.SetLevel(c.Log.Level)
+	})
+	if err := configManager.Watch(); err != nil {
+		logger.Warn("config hot reload disabled", zap.Error(err))
+	}
+
 	// Initialize validator
-	if err := validator.Init(); err != nil {
+	if err := validator.InitWithPolicy(newPasswordPolicy(cfg.Security)); err != nil {
 		logger.Fatal("failed to initialize validator", zap.Error(err))
 	}
 
+	// Initialize authorization policy
+	if cfg.Authz.RolePermissions != "" {
+		policy, err := authz.ParsePolicy(cfg.Authz.RolePermissions)
+		if err != nil {
+			logger.Fatal("failed to parse role permissions", zap.Error(err))
+		}
+		// Registered users are given the user role, so it must exist.
+		if _, ok := policy[constants.RoleUser]; !ok {
+			logger.Fatal("role permissions must define the user role", zap.String("role", constants.RoleUser))
+		}
+		authz.Init(policy)
+	}
+
+	// Initialize tracing
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Config{
+		Enabled:     cfg.Tracing.Enabled,
+		ServiceName: cfg.App.Name,
+		Environment: cfg.App.Env,
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
+	if err != nil {
+		logger.Fatal("failed to initialize tracing", zap.Error(err))
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Error("failed to flush traces", zap.Error(err))
+		}
+	}()
+
+	// Start the server before connecting to the dependencies so the port is
+	// open for liveness checks during warm-up. Until the full router is
+	// swapped in, /health/ready answers 503 and every other route 503.
+	readiness := health.NewReadiness()
+	handler := &switchHandler{}
+	handler.set(router.SetupStartupRouter(cfg, readiness))
+
+	srv := &http.Server{
+		Addr:         fmt.Sprintf(":%d", cfg.App.Port),
+		Handler:      handler,
+		ReadTimeout:  cfg.Server.ReadTimeout,
+		WriteTimeout: cfg.Server.WriteTimeout,
+		IdleTimeout:  cfg.Server.IdleTimeout,
+	}
+
+	// Start server in goroutine
+	go func() {
+		logger.Info("server started",
+			zap.String("address", srv.Addr),
+		)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Fatal("failed to start server", zap.Error(err))
+		}
+	}()
+
+	// Database and cache latency is only recorded while metrics are served.
+	var operations *appMetrics.Operations
+	if cfg.Metrics.Enabled {
+		if operations, err = appMetrics.NewOperations(prometheus.DefaultRegisterer); err != nil {
+			logger.Fatal("failed to register operation metrics", zap.Error(err))
+		}
+	}
+
 	// Initialize database
-	db, err := database.NewPostgreSQL(cfg.Database)
+	var dbOpts []database.Option
+	if cfg.Tracing.Enabled {
+		dbOpts = append(dbOpts, database.WithQueryTracer(tracing.NewPgxTracer()))
+	}
+	if cfg.Database.SlowQueryThreshold > 0 {
+		dbOpts = append(dbOpts, database.WithQueryTracer(database.NewSlowQueryLogger(cfg.Database.SlowQueryThreshold)))
+	}
+	db, err := database.NewPostgreSQL(cfg.Database, dbOpts...)
 	if err != nil {
 		logger.Fatal("failed to connect to database", zap.Error(err))
 	}
@@ -87,6 +219,12 @@ func main() {
 		logger.Fatal("failed to connect to redis", zap.Error(err))
 	}
 	defer redisClient.Close()
+	if cfg.Tracing.Enabled {
+		redisClient.Client.AddHook(tracing.NewRedisHook())
+	}
+	if operations != nil {
+		redisClient.Client.AddHook(operations.RedisHook())
+	}
 
 	// Initialize RabbitMQ
 	rabbitmq, err := messaging.NewRabbitMQ(cfg.RabbitMQ)
@@ -95,56 +233,276 @@ func main() {
 		// RabbitMQ is optional, continue without it
 	} else {
 		defer rabbitmq.Close()
+
+		if err := rabbitmq.DeclareExchange(constants.ExchangeUserEvents, amqp.ExchangeTopic, true, false); err != nil {
+			logger.Fatal("failed to declare user events exchange", zap.Error(err))
+		}
+	}
+
+	// Initialize notifications. In async mode requests only publish emails to
+	// the email queue and a worker delivers them with the configured driver.
+	notifier, err := newNotifier(cfg.Notify)
+	if err != nil {
+		logger.Fatal("failed to initialize notifier", zap.Error(err))
+	}
+	var emailWorker *messaging.Consumer
+	if cfg.Notify.Async {
+		if rabbitmq == nil {
+			logger.Warn("rabbitmq unavailable, sending emails synchronously")
+		} else {
+			if err := rabbitmq.DeclareQueueWithDeadLetter(constants.QueueEmailQueue); err != nil {
+				logger.Fatal("failed to declare email queue", zap.Error(err))
+			}
+			emailWorker = messaging.NewConsumer(rabbitmq, messaging.ConsumerConfig{
+				Queue:      constants.QueueEmailQueue,
+				Workers:    cfg.Notify.Workers,
+				MaxRetries: emailMaxRetries,
+			}, messaging.NotificationHandler(notifier))
+			notifier = notify.NewQueue(rabbitmq, constants.QueueEmailQueue)
+		}
 	}
 
 	// Initialize utilities
-	passwordHasher := crypto.NewPasswordHasher(cfg.Security.BcryptCost)
+	passwordHasher, err := newPasswordHasher(cfg.Security)
+	if err != nil {
+		logger.Fatal("failed to initialize password hasher", zap.Error(err))
+	}
+	tokenEpochs := userRepo.NewRedisTokenEpochRepository(redisClient)
 	jwtManager := jwt.NewManager(
 		cfg.JWT.Secret,
 		cfg.JWT.AccessTokenExpiry,
 		cfg.JWT.RefreshTokenExpiry,
+		jwt.WithIssuer(cfg.JWT.Issuer),
+		jwt.WithAudience(cfg.JWT.Audience),
+		jwt.WithRememberMeRefreshDuration(cfg.JWT.RememberMeRefreshExpiry),
+		jwt.WithImpersonationDuration(cfg.JWT.ImpersonationExpiry),
+		jwt.WithEpochStore(tokenEpochs, cfg.JWT.EpochFailOpen),
 	)
 
 	// Initialize repositories
-	userRepository := userRepo.NewPostgresUserRepository(db.GetPool())
+	var userRepoOpts []userRepo.Option
+	var userCacheOpts []userRepo.CacheOption
+	var apiKeyRepoOpts []apikeyRepo.Option
+	piiKey, piiIndexKey, err := cfg.Security.PIIKeys()
+	if err != nil {
+		logger.Fatal("invalid pii encryption keys", zap.Error(err))
+	}
+	if piiKey != nil {
+		fieldCipher, err := crypto.NewFieldCipher(piiKey, piiIndexKey)
+		if err != nil {
+			logger.Fatal("failed to initialize pii cipher", zap.Error(err))
+		}
+		userRepoOpts = append(userRepoOpts, userRepo.WithFieldEncryption(fieldCipher))
+		userCacheOpts = append(userCacheOpts, userRepo.WithCacheEncryption(fieldCipher))
+		apiKeyRepoOpts = append(apiKeyRepoOpts, apikeyRepo.WithFieldEncryption(fieldCipher))
+	} else {
+		logger.Info("PII_ENCRYPTION_KEY not set, user emails and names stored in plaintext")
+	}
+
+	userRepoOpts = append(userRepoOpts, userRepo.WithReadPool(db.GetReadPool()))
+	userRepository := userRepo.NewCachedUserRepository(
+		userRepo.NewInstrumentedUserRepository(
+			userRepo.NewPostgresUserRepository(db.GetWritePool(), cfg.Database.QueryTimeout, userRepoOpts...),
+			operations,
+		),
+		redisClient,
+		userCacheOpts...,
+	)
+
+	auditRepository := auditRepo.NewPostgresAuditRepository(db.GetPool(), cfg.Database.QueryTimeout)
+	apiKeyRepository := apikeyRepo.NewPostgresAPIKeyRepository(db.GetPool(), cfg.Database.QueryTimeout, apiKeyRepoOpts...)
 
 	// Initialize use cases
+	auditLogger := auditUsecase.NewAuditLogger(auditRepository)
+	txManager := database.NewTxManager(db.GetPool())
+	apiKeyUsecaseImpl := apikeyUsecase.NewAPIKeyUsecase(apiKeyRepository, txManager, auditLogger)
+	userOpts := []userUsecase.Option{
+		userUsecase.WithTxManager(txManager),
+		userUsecase.WithAuditLogger(auditLogger),
+		userUsecase.WithSimilarityThreshold(cfg.Pagination.SearchSimilarityThreshold),
+		userUsecase.WithSessionRepository(userRepo.NewRedisSessionRepository(redisClient, jwtManager.RememberMeRefreshTokenDuration())),
+		userUsecase.WithTokenEpochRepository(tokenEpochs),
+		userUsecase.WithNotifier(notifier),
+	}
+	switch {
+	case cfg.Events.Backend == config.EventsBackendRedisStream:
+		userOpts = append(userOpts, userUsecase.WithEventPublisher(
+			messaging.NewStreamPublisher(redisClient, constants.StreamUserEvents, cfg.Events.StreamMaxLen),
+		))
+	case rabbitmq != nil:
+		userOpts = append(userOpts, userUsecase.WithEventPublisher(
+			messaging.NewEventPublisher(rabbitmq, constants.ExchangeUserEvents),
+		))
+	}
+	totpKey, err := cfg.Security.TOTPKey()
+	if err != nil {
+		logger.Fatal("invalid totp encryption key", zap.Error(err))
+	}
+	if totpKey != nil {
+		totpCipher, err := crypto.NewAESGCMCipher(totpKey)
+		if err != nil {
+			logger.Fatal("failed to initialize totp cipher", zap.Error(err))
+		}
+		userOpts = append(userOpts, userUsecase.WithTOTP(totpCipher, cfg.Security.TOTPIssuer, userRepo.NewRedisMFARepository(redisClient)))
+	} else {
+		logger.Info("TOTP_ENCRYPTION_KEY not set, multi-factor authentication disabled")
+	}
+	if cfg.Security.BlockDisposableEmails {
+		disposableDomains, err := newDisposableDomains(cfg.Security)
+		if err != nil {
+			logger.Fatal("failed to load disposable email domains", zap.Error(err))
+		}
+		logger.Info("blocking disposable email domains", zap.Int("domains", disposableDomains.Len()))
+		userOpts = append(userOpts, userUsecase.WithDisposableEmailCheck(disposableDomains))
+	}
+	if cfg.Registration.LimitPerIP > 0 {
+		userOpts = append(userOpts, userUsecase.WithRegistrationLimit(
+			userRepo.NewRedisRegistrationCounter(redisClient, cfg.Registration.LimitWindow),
+			cfg.Registration.LimitPerIP,
+		))
+	}
+	fileStorage, err := newFileStorage(cfg.Storage)
+	if err != nil {
+		logger.Fatal("failed to initialize file storage", zap.Error(err))
+	}
+	userOpts = append(userOpts, userUsecase.WithAvatarStorage(fileStorage, cfg.Storage.MaxAvatarSize))
 	userUsecaseImpl := userUsecase.NewUserUsecase(
 		userRepository,
 		passwordHasher,
 		jwtManager,
 		redisClient,
+		userOpts...,
 	)
 
 	// Initialize handlers
-	userHandler := userHttp.NewUserHandler(userUsecaseImpl)
+	userHandler := userHttp.NewUserHandler(userUsecaseImpl, userHttp.WithTokenCookies(userHttp.TokenCookies{
+		Delivery: cfg.AuthCookie.Delivery,
+		Domain:   cfg.AuthCookie.Domain,
+		Secure:   cfg.AuthCookie.Secure,
+		SameSite: cfg.AuthCookie.SameSiteMode(),
+	}))
+	// Live subscriptions are RabbitMQ queues, so they only see events when
+	// those are published there.
+	var eventSubscriber userHttp.EventSubscriber
+	if rabbitmq != nil && cfg.Events.Backend == config.EventsBackendRabbitMQ {
+		eventSubscriber = rabbitmq
+	}
+	userEventsHandler := userHttp.NewUserEventsHandler(eventSubscriber)
+	notificationHub := notification.NewHub()
+	notificationsHandler := userHttp.NewNotificationsHandler(notificationHub, eventSubscriber, func(r *http.Request) bool {
+		// Non-browser clients send no Origin header.
+		origin := r.Header.Get("Origin")
+		return origin == "" || middleware.OriginAllowed(configManager.Current().CORS, origin)
+	})
+	forwardCtx, stopForwarding := context.WithCancel(context.Background())
+	defer stopForwarding()
+	go notificationsHandler.ForwardUserEvents(forwardCtx)
+	auditHandler := auditHttp.NewAuditHandler(auditLogger)
+	apiKeyHandler := apikeyHttp.NewAPIKeyHandler(apiKeyUsecaseImpl)
+	graphqlHandler := userGraphql.NewHandler(userGraphql.NewSchema(userUsecaseImpl))
+
+	// Start background jobs
+	jobs := scheduler.New()
+	if cfg.Scheduler.Enabled && cfg.Scheduler.DeletedUserRetention > 0 {
+		jobs.Register("purge_deleted_users", cfg.Scheduler.UserPurgeInterval, func(ctx context.Context) error {
+			_, err := userUsecaseImpl.PurgeDeletedUsers(ctx, cfg.Scheduler.DeletedUserRetention)
+			return err
+		})
+	}
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	jobs.Start(jobsCtx)
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
+	workersDone := make(chan struct{})
+	go func() {
+		defer close(workersDone)
+		if emailWorker == nil {
+			return
+		}
+		if err := emailWorker.Run(workerCtx); err != nil {
+			logger.Error("email worker stopped", zap.Error(err))
+		}
+	}()
+
+	// Warm the user cache without delaying startup
+	warmCtx, stopWarming := context.WithCancel(context.Background())
+	defer stopWarming()
+	if cfg.Cache.WarmEnabled && cfg.Cache.WarmUsers > 0 {
+		go warmUserCache(warmCtx, userRepository, cfg.Cache.WarmUsers)
+	}
+
+	// Dependency checks behind /health/ready
+	readiness.AddCheck("database", db.Health)
+	readiness.AddCheck("redis", redisClient.Health)
+	if rabbitmq != nil {
+		topology := []messaging.QueueSpec{{Exchange: constants.ExchangeUserEvents}}
+		if emailWorker != nil {
+			topology = append(topology, messaging.QueueSpec{Name: constants.QueueEmailQueue})
+		}
+		for _, queue := range cfg.RabbitMQ.ExpectedQueues {
+			topology = append(topology, messaging.QueueSpec{Name: queue})
+		}
+		readiness.AddCheck("rabbitmq", func(context.Context) error {
+			return rabbitmq.VerifyTopology(topology)
+		})
+	}
 
 	// Setup router
 	routerCfg := &router.RouterConfig{
-		Config:      cfg,
-		JWTManager:  jwtManager,
-		UserHandler: userHandler,
+		Config:               cfg,
+		ConfigManager:        configManager,
+		JWTManager:           jwtManager,
+		DB:                   db,
+		Redis:                redisClient,
+		UserHandler:          userHandler,
+		UserEventsHandler:    userEventsHandler,
+		NotificationsHandler: notificationsHandler,
+		AuditHandler:         auditHandler,
+		APIKeyHandler:        apiKeyHandler,
+		GraphQLHandler:       graphqlHandler,
+		APIKeyAuthenticator:  apiKeyUsecaseImpl,
+		Readiness:            readiness,
 	}
-	r := router.SetupRouter(routerCfg)
+	handler.set(router.SetupRouter(routerCfg))
+	readiness.SetReady(true)
+	logger.Info("service ready")
 
-	// Create HTTP server
-	srv := &http.Server{
-		Addr:         fmt.Sprintf(":%d", cfg.App.Port),
-		Handler:      r,
-		ReadTimeout:  cfg.Server.ReadTimeout,
-		WriteTimeout: cfg.Server.WriteTimeout,
-		IdleTimeout:  cfg.Server.IdleTimeout,
+	// Start metrics server
+	var metricsSrv *http.Server
+	if cfg.Metrics.Enabled {
+		if err := db.RegisterMetrics(prometheus.DefaultRegisterer); err != nil {
+			logger.Fatal("failed to register database metrics", zap.Error(err))
+		}
+
+		metricsSrv = metrics.NewServer(cfg.Metrics, prometheus.DefaultGatherer)
+		go func() {
+			logger.Info("metrics server started", zap.String("address", metricsSrv.Addr))
+			if err := metricsSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.Error("metrics server failed", zap.Error(err))
+			}
+		}()
 	}
 
-	// Start server in goroutine
-	go func() {
-		logger.Info("server started",
-			zap.String("address", srv.Addr),
-		)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			logger.Fatal("failed to start server", zap.Error(err))
+	// Start gRPC server
+	var grpcSrv *grpc.Server
+	if cfg.GRPC.Enabled {
+		grpcSrv = grpcServer.New(&grpcServer.Config{
+			Debug:      cfg.App.Debug,
+			JWTManager: jwtManager,
+			UserServer: userGrpc.NewUserServer(userUsecaseImpl),
+		})
+		grpcListener, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPC.Port))
+		if err != nil {
+			logger.Fatal("failed to listen for gRPC", zap.Error(err))
 		}
-	}()
+		go func() {
+			logger.Info("gRPC server started", zap.String("address", grpcListener.Addr().String()))
+			if err := grpcSrv.Serve(grpcListener); err != nil {
+				logger.Error("gRPC server failed", zap.Error(err))
+			}
+		}()
+	}
 
 	// Wait for interrupt signal
 	quit := make(chan os.Signal, 1)
@@ -152,6 +510,12 @@ func main() {
 	<-quit
 
 	logger.Info("shutting down server...")
+	readiness.SetReady(false)
+	stopForwarding()
+	notificationHub.Close()
+	stopJobs()
+	stopWorkers()
+	stopWarming()
 
 	// Graceful shutdown
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -161,5 +525,144 @@ func main() {
 		logger.Error("server forced to shutdown", zap.Error(err))
 	}
 
+	if metricsSrv != nil {
+		if err := metricsSrv.Shutdown(ctx); err != nil {
+			logger.Error("metrics server forced to shutdown", zap.Error(err))
+		}
+	}
+
+	if grpcSrv != nil {
+		stopGRPC(ctx, grpcSrv)
+	}
+
+	jobs.Wait()
+	<-workersDone
+
 	logger.Info("server exited")
 }
+
+// stopGRPC lets in-flight calls finish, cancelling those still running
+// when ctx is done.
+func stopGRPC(ctx context.Context, srv *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		srv.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		logger.Error("gRPC server forced to shutdown", zap.Error(ctx.Err()))
+		srv.Stop()
+	}
+}
+
+// warmUserCache loads up to limit users into the cache and logs the outcome.
+func warmUserCache(ctx context.Context, repo *userRepo.CachedUserRepository, limit int) {
+	start := time.Now()
+	warmed, err := repo.Warm(ctx, limit)
+	if err != nil {
+		logger.Warn("user cache warming stopped early",
+			zap.Int("warmed", warmed),
+			zap.Duration("duration", time.Since(start)),
+			zap.Error(err),
+		)
+		return
+	}
+
+	logger.Info("user cache warmed",
+		zap.Int("warmed", warmed),
+		zap.Duration("duration", time.Since(start)),
+	)
+}
+
+// switchHandler serves the router last passed to set, letting the server
+// start with the startup router and switch to the full one once it is built.
+type switchHandler struct {
+	engine atomic.Pointer[gin.Engine]
+}
+
+func (h *switchHandler) set(engine *gin.Engine) {
+	h.engine.Store(engine)
+}
+
+func (h *switchHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.engine.Load().ServeHTTP(w, r)
+}
+
+func newPasswordHasher(cfg config.SecurityConfig) (*crypto.PasswordHasher, error) {
+	switch cfg.PasswordAlgorithm {
+	case "", crypto.AlgorithmBcrypt:
+		return crypto.NewPasswordHasher(cfg.BcryptCost)
+	case crypto.AlgorithmArgon2id:
+		params := crypto.DefaultArgon2Params()
+		if cfg.Argon2Memory > 0 {
+			params.Memory = cfg.Argon2Memory
+		}
+		if cfg.Argon2Iterations > 0 {
+			params.Iterations = cfg.Argon2Iterations
+		}
+		if cfg.Argon2Parallelism > 0 {
+			params.Parallelism = cfg.Argon2Parallelism
+		}
+		return crypto.NewArgon2idPasswordHasher(params), nil
+	default:
+		return nil, fmt.Errorf("unsupported password algorithm %q", cfg.PasswordAlgorithm)
+	}
+}
+
+func newPasswordPolicy(cfg config.SecurityConfig) validator.PasswordPolicy {
+	return validator.PasswordPolicy{
+		MinLength:        cfg.PasswordMinLength,
+		MaxLength:        cfg.PasswordMaxLength,
+		RequireUppercase: cfg.PasswordRequireUpper,
+		RequireLowercase: cfg.PasswordRequireLower,
+		RequireDigit:     cfg.PasswordRequireDigit,
+		RequireSpecial:   cfg.PasswordRequireSpecial,
+		DisallowCommon:   cfg.PasswordDisallowCommon,
+	}
+}
+
+func newDisposableDomains(cfg config.SecurityConfig) (*validator.DisposableDomains, error) {
+	if cfg.DisposableEmailDomainsFile == "" {
+		return validator.DefaultDisposableDomains(), nil
+	}
+	return validator.LoadDisposableDomains(cfg.DisposableEmailDomainsFile)
+}
+
+func newNotifier(cfg config.NotifyConfig) (notify.Notifier, error) {
+	switch cfg.Driver {
+	case "", config.NotifyDriverLog:
+		return notify.NewLog(), nil
+	case config.NotifyDriverSMTP:
+		return notify.NewSMTP(notify.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.From,
+		})
+	default:
+		return nil, fmt.Errorf("unsupported notify driver %q", cfg.Driver)
+	}
+}
+
+func newFileStorage(cfg config.StorageConfig) (storage.Storage, error) {
+	switch cfg.Driver {
+	case "", config.StorageDriverLocal:
+		return storage.NewLocal(cfg.LocalDir, cfg.PublicURL)
+	case config.StorageDriverS3:
+		return storage.NewS3(storage.S3Config{
+			Endpoint:     cfg.S3Endpoint,
+			Region:       cfg.S3Region,
+			Bucket:       cfg.S3Bucket,
+			AccessKey:    cfg.S3AccessKey,
+			SecretKey:    cfg.S3SecretKey,
+			UsePathStyle: cfg.S3UsePathStyle,
+			PublicURL:    cfg.PublicURL,
+		})
+	default:
+		return nil, fmt.Errorf("unsupported storage driver %q", cfg.Driver)
+	}
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/config"
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/database"
+	"github.com/TubagusAldiMY/go-template/migrations"
+)
+
+const usage = `Usage: migrate <command>
+
+Commands:
+  up         Apply all pending migrations
+  down [N]   Revert the last N migrations (default 1)
+  version    Print the current schema version`
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "migrate: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	if len(args) == 0 {
+		fmt.Println(usage)
+		return errors.New("missing command")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	db, err := database.NewPostgreSQL(cfg.Database.PrimaryOnly())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	migrator, err := database.NewMigrator(db.GetPool(), migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			return err
+		}
+		if applied == 0 {
+			fmt.Println("no pending migrations")
+			return nil
+		}
+		fmt.Printf("applied %d migration(s)\n", applied)
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps %q", args[1])
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("reverted %d migration(s)\n", reverted)
+
+	case "version":
+		version, dirty, err := migrator.Version(ctx)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("%w at version %d", database.ErrDirtyMigration, version)
+		}
+		fmt.Println(version)
+
+	default:
+		fmt.Println(usage)
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+
+	return nil
+}
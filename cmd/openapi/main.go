@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/TubagusAldiMY/go-template/docs"
+)
+
+const usage = `Usage: openapi [flags]
+
+Writes the OpenAPI (Swagger 2.0) document generated from the handler
+annotations, as served by /swagger when APP_DEBUG is true. It does not need a
+config or any running dependency, so it can be used in CI and to generate
+client SDKs. Run "make swagger" first if the annotations have changed.
+
+Flags:`
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "openapi: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	flags := flag.NewFlagSet("openapi", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+	output := flags.String("output", "", "file to write the document to (default stdout)")
+	host := flags.String("host", docs.SwaggerInfo.Host, "host the API is served from")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		flags.Usage()
+		return fmt.Errorf("unexpected argument %q", flags.Arg(0))
+	}
+
+	docs.SwaggerInfo.Host = *host
+	doc, err := render(docs.SwaggerInfo.ReadDoc())
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		_, err = os.Stdout.Write(doc)
+		return err
+	}
+	if err := os.WriteFile(*output, doc, 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", *output, err)
+	}
+	return nil
+}
+
+// render checks that the templated document is valid JSON and indents it
+// consistently.
+func render(doc string) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(doc), "", "    "); err != nil {
+		return nil, fmt.Errorf("generated document is not valid JSON: %w", err)
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/entity"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/repository"
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/config"
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/database"
+	"github.com/TubagusAldiMY/go-template/internal/shared/constants"
+	sharedErrors "github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/TubagusAldiMY/go-template/pkg/authz"
+	"github.com/TubagusAldiMY/go-template/pkg/crypto"
+	"github.com/TubagusAldiMY/go-template/pkg/validator"
+	"github.com/jackc/pgx/v5"
+	"github.com/spf13/viper"
+)
+
+const usage = `Usage: seed [flags]
+
+Upserts users by email, so running it again with the same input updates the
+existing rows instead of duplicating them. With PII_ENCRYPTION_KEY set, users
+are written encrypted like the API writes them.
+
+Flags:`
+
+// generatedPasswordLength is the length of the random password given to each
+// --count user. The password is not printed; these users exist to fill lists
+// and pagination, not to log in with.
+const generatedPasswordLength = 24
+
+// seedFile is the layout of the --file seed definitions.
+type seedFile struct {
+	Users []seedUser `mapstructure:"users"`
+}
+
+type seedUser struct {
+	Email    string `mapstructure:"email" validate:"required,email"`
+	Username string `mapstructure:"username" validate:"required,username"`
+	Password string `mapstructure:"password" validate:"required,min=8"`
+	FullName string `mapstructure:"full_name" validate:"required,min=2,max=100"`
+	Role     string `mapstructure:"role" validate:"omitempty,enum_role"`
+	Status   string `mapstructure:"status" validate:"omitempty,enum_status"`
+}
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "seed: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+	file := flags.String("file", "", "JSON or YAML file with seed user definitions")
+	count := flags.Int("count", 0, "number of generated test users to upsert (seed-user-1 … seed-user-N)")
+	truncate := flags.Bool("truncate", false, "delete all users before seeding (development only)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *count < 0 {
+		return fmt.Errorf("invalid --count %d", *count)
+	}
+	if *file == "" && *count == 0 && !*truncate {
+		flags.Usage()
+		return errors.New("nothing to seed: pass --file and/or --count")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if *truncate && cfg.App.Env != "development" {
+		return fmt.Errorf("--truncate is only allowed when APP_ENV is development, got %q", cfg.App.Env)
+	}
+
+	// Seed roles are validated against the same policy the API enforces.
+	if cfg.Authz.RolePermissions != "" {
+		policy, err := authz.ParsePolicy(cfg.Authz.RolePermissions)
+		if err != nil {
+			return fmt.Errorf("failed to parse role permissions: %w", err)
+		}
+		authz.Init(policy)
+	}
+
+	if err := validator.Init(); err != nil {
+		return err
+	}
+
+	var users []seedUser
+	if *file != "" {
+		if users, err = loadSeedFile(*file); err != nil {
+			return err
+		}
+	}
+
+	generated, err := generateUsers(*count)
+	if err != nil {
+		return err
+	}
+	users = append(users, generated...)
+
+	db, err := database.NewPostgreSQL(cfg.Database.PrimaryOnly())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	hasher, err := crypto.NewPasswordHasher(cfg.Security.BcryptCost)
+	if err != nil {
+		return err
+	}
+
+	// Users go through the repository so they are stored, and matched by
+	// email, exactly as the API stores them, encrypted or not.
+	var repoOpts []repository.Option
+	piiKey, piiIndexKey, err := cfg.Security.PIIKeys()
+	if err != nil {
+		return err
+	}
+	if piiKey != nil {
+		fields, err := crypto.NewFieldCipher(piiKey, piiIndexKey)
+		if err != nil {
+			return err
+		}
+		repoOpts = append(repoOpts, repository.WithFieldEncryption(fields))
+	}
+	repo := repository.NewPostgresUserRepository(db.GetPool(), 0, repoOpts...)
+	ctx := context.Background()
+
+	// Everything runs in one transaction so a bad definition halfway through
+	// does not leave the table truncated or partially seeded.
+	return pgx.BeginFunc(ctx, db.GetPool(), func(tx pgx.Tx) error {
+		ctx := database.ContextWithTx(ctx, tx)
+		if *truncate {
+			// CASCADE also clears the API keys owned by the removed users.
+			if _, err := tx.Exec(ctx, "TRUNCATE TABLE users CASCADE"); err != nil {
+				return fmt.Errorf("failed to truncate users: %w", err)
+			}
+			fmt.Println("truncated users")
+		}
+
+		var inserted, updated int
+		for _, u := range users {
+			created, err := upsertUser(ctx, repo, hasher, u)
+			if err != nil {
+				return fmt.Errorf("failed to seed %s: %w", u.Email, err)
+			}
+			if created {
+				inserted++
+			} else {
+				updated++
+			}
+		}
+
+		fmt.Printf("seeded %d user(s): %d inserted, %d updated\n", len(users), inserted, updated)
+		return nil
+	})
+}
+
+// loadSeedFile reads user definitions from a JSON or YAML file, detected from
+// the extension, and validates them.
+func loadSeedFile(path string) ([]seedUser, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read seed file: %w", err)
+	}
+
+	var seeds seedFile
+	if err := v.Unmarshal(&seeds); err != nil {
+		return nil, fmt.Errorf("failed to parse seed file: %w", err)
+	}
+
+	seen := make(map[string]bool, len(seeds.Users))
+	for i := range seeds.Users {
+		u := &seeds.Users[i]
+		u.Email = strings.ToLower(strings.TrimSpace(u.Email))
+		if err := validator.Validate(u); err != nil {
+			return nil, fmt.Errorf("invalid user #%d in seed file: %v", i+1, validator.FormatValidationErrors(err))
+		}
+		if seen[u.Email] {
+			return nil, fmt.Errorf("duplicate email %s in seed file", u.Email)
+		}
+		seen[u.Email] = true
+	}
+
+	return seeds.Users, nil
+}
+
+// generateUsers returns count test users with stable identities, so repeated
+// runs upsert the same rows, and random passwords.
+func generateUsers(count int) ([]seedUser, error) {
+	users := make([]seedUser, 0, count)
+	for i := 1; i <= count; i++ {
+		password, err := crypto.GenerateRandomString(generatedPasswordLength)
+		if err != nil {
+			return nil, err
+		}
+
+		users = append(users, seedUser{
+			Email:    fmt.Sprintf("seed-user-%d@example.com", i),
+			Username: fmt.Sprintf("seed-user-%d", i),
+			Password: password,
+			FullName: fmt.Sprintf("Seed User %d", i),
+		})
+	}
+
+	return users, nil
+}
+
+// upsertUser inserts u or, when a user that is not deleted already has the
+// email, overwrites that user with the seed definition. A soft-deleted user
+// with the email is left alone and a new user created, as for any
+// re-registration. It reports whether a new user was created.
+func upsertUser(ctx context.Context, repo *repository.PostgresUserRepository, hasher *crypto.PasswordHasher, u seedUser) (bool, error) {
+	role := u.Role
+	if role == "" {
+		role = constants.RoleUser
+	}
+	status := u.Status
+	if status == "" {
+		status = constants.UserStatusActive
+	}
+
+	hashedPassword, err := hasher.Hash(u.Password)
+	if err != nil {
+		return false, err
+	}
+
+	existing, err := repo.GetByEmail(ctx, u.Email)
+	if errors.Is(err, sharedErrors.ErrUserNotFound) {
+		user := entity.NewUser(u.Email, u.Username, hashedPassword, u.FullName, role)
+		user.Status = status
+		return true, repo.Create(ctx, user)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	existing.ChangeUsername(u.Username)
+	existing.UpdatePassword(hashedPassword)
+	existing.SetFullName(u.FullName)
+	existing.Role = role
+	existing.ChangeStatus(status)
+	return false, repo.Update(ctx, existing)
+}
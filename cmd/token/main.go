@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/entity"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/repository"
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/cache"
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/config"
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/database"
+	"github.com/TubagusAldiMY/go-template/internal/shared/constants"
+	"github.com/TubagusAldiMY/go-template/pkg/crypto"
+	"github.com/TubagusAldiMY/go-template/pkg/jwt"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const usage = `Usage: token [flags]
+
+Prints access tokens for existing users, one JSON object per line, signed
+with the configured JWT settings, so load tests need not script the login
+endpoint. Select the users with exactly one of --user, --count or --all;
+--count and --all pick the generated users of "seed --count". Only active,
+non-deleted users get tokens. Refused when APP_ENV is production.
+
+Flags:`
+
+// seedUsernamePattern matches the usernames of users generated by
+// cmd/seed --count. Usernames, unlike emails, are never encrypted, so they can
+// be matched in SQL.
+const seedUsernamePattern = "seed-user-%"
+
+// tokenUser is a user tokens are issued for.
+type tokenUser struct {
+	ID    string
+	Email string
+	Role  string
+}
+
+// tokenLine is one line of output.
+type tokenLine struct {
+	UserID       string `json:"user_id"`
+	Email        string `json:"email"`
+	Role         string `json:"role"`
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintf(os.Stderr, "token: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	flags := flag.NewFlagSet("token", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+	user := flags.String("user", "", "ID or email of the user to issue a token for")
+	count := flags.Int("count", 0, "issue tokens for seed-user-1 … seed-user-N")
+	all := flags.Bool("all", false, "issue tokens for every generated seed user")
+	expiry := flags.Duration("expiry", 0, "access token lifetime (default JWT_ACCESS_TOKEN_EXPIRY)")
+	refresh := flags.Bool("refresh", false, "also issue refresh tokens, each in a new session of its user")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		flags.Usage()
+		return fmt.Errorf("unexpected argument %q", flags.Arg(0))
+	}
+
+	selectors := 0
+	for _, set := range []bool{*user != "", *count != 0, *all} {
+		if set {
+			selectors++
+		}
+	}
+	if selectors != 1 {
+		flags.Usage()
+		return errors.New("pass exactly one of --user, --count and --all")
+	}
+	if *count < 0 {
+		return fmt.Errorf("invalid --count %d", *count)
+	}
+	if *expiry < 0 {
+		return fmt.Errorf("invalid --expiry %s", *expiry)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if cfg.App.Env == "production" {
+		return errors.New("refusing to mint tokens when APP_ENV is production")
+	}
+
+	accessExpiry := cfg.JWT.AccessTokenExpiry
+	if *expiry > 0 {
+		accessExpiry = *expiry
+	}
+
+	// Tokens carry their user's token epoch, so they are accepted after a
+	// password change like the ones login issues.
+	redis, err := cache.NewRedis(cfg.Redis)
+	if err != nil {
+		return err
+	}
+	defer redis.Close()
+
+	manager := jwt.NewManager(
+		cfg.JWT.Secret,
+		accessExpiry,
+		cfg.JWT.RefreshTokenExpiry,
+		jwt.WithIssuer(cfg.JWT.Issuer),
+		jwt.WithAudience(cfg.JWT.Audience),
+		jwt.WithRememberMeRefreshDuration(cfg.JWT.RememberMeRefreshExpiry),
+		jwt.WithEpochStore(repository.NewRedisTokenEpochRepository(redis), false),
+	)
+
+	db, err := database.NewPostgreSQL(cfg.Database.PrimaryOnly())
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var fields *crypto.FieldCipher
+	piiKey, piiIndexKey, err := cfg.Security.PIIKeys()
+	if err != nil {
+		return err
+	}
+	if piiKey != nil {
+		if fields, err = crypto.NewFieldCipher(piiKey, piiIndexKey); err != nil {
+			return err
+		}
+	}
+
+	ctx := context.Background()
+	users, err := loadUsers(ctx, db.GetPool(), fields, *user, *count)
+	if err != nil {
+		return err
+	}
+	if len(users) == 0 {
+		return errors.New("no matching active users; run seed first")
+	}
+	if *count > 0 && len(users) < *count {
+		fmt.Fprintf(os.Stderr, "token: only %d of %d seed users exist and are active\n", len(users), *count)
+	}
+
+	// Refresh tokens are only accepted while their session is stored, so
+	// each gets one, as login does.
+	var sessions *repository.RedisSessionRepository
+	if *refresh {
+		sessions = repository.NewRedisSessionRepository(redis, manager.RememberMeRefreshTokenDuration())
+	}
+
+	out := json.NewEncoder(os.Stdout)
+	for _, u := range users {
+		line := tokenLine{UserID: u.ID, Email: u.Email, Role: u.Role, ExpiresIn: int64(accessExpiry.Seconds())}
+		if line.AccessToken, err = manager.GenerateAccessToken(ctx, u.ID, u.Email, u.Role); err != nil {
+			return fmt.Errorf("failed to sign access token for %s: %w", u.Email, err)
+		}
+		if sessions != nil {
+			if line.RefreshToken, err = issueRefreshToken(ctx, manager, sessions, u.ID); err != nil {
+				return fmt.Errorf("failed to issue refresh token for %s: %w", u.Email, err)
+			}
+		}
+		if err := out.Encode(line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadUsers returns the active users selected by the flags: the one whose ID
+// or email is user, the first count generated seed users, or all of them
+// when both are unset. Under field encryption, emails are matched by their
+// blind index, or in plaintext on rows not yet encrypted, and decrypted.
+func loadUsers(ctx context.Context, pool *pgxpool.Pool, fields *crypto.FieldCipher, user string, count int) ([]tokenUser, error) {
+	const columns = `SELECT id, email, role FROM users WHERE deleted_at IS NULL AND status = $1`
+	// Orders seed-user-2 before seed-user-10.
+	const seedOrder = ` ORDER BY length(username), username`
+
+	var rows pgx.Rows
+	var err error
+	switch {
+	case user != "" && strings.Contains(user, "@"):
+		email := strings.ToLower(user)
+		if fields == nil {
+			rows, err = pool.Query(ctx, columns+` AND email = $2`, constants.UserStatusActive, email)
+			break
+		}
+		rows, err = pool.Query(ctx, columns+` AND (email_index = $2 OR (email_index IS NULL AND email = $3))`,
+			constants.UserStatusActive, fields.BlindIndex(email), email)
+	case user != "":
+		rows, err = pool.Query(ctx, columns+` AND id::text = $2`, constants.UserStatusActive, user)
+	case count > 0:
+		usernames := make([]string, count)
+		for i := range usernames {
+			usernames[i] = fmt.Sprintf("seed-user-%d", i+1)
+		}
+		rows, err = pool.Query(ctx, columns+` AND username = ANY($2)`+seedOrder, constants.UserStatusActive, usernames)
+	default:
+		rows, err = pool.Query(ctx, columns+` AND username LIKE $2`+seedOrder, constants.UserStatusActive, seedUsernamePattern)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load users: %w", err)
+	}
+
+	users, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (tokenUser, error) {
+		var u tokenUser
+		if err := row.Scan(&u.ID, &u.Email, &u.Role); err != nil {
+			return u, err
+		}
+		if fields != nil {
+			if u.Email, err = fields.Decrypt(u.Email); err != nil {
+				return u, fmt.Errorf("failed to decrypt email of user %s: %w", u.ID, err)
+			}
+		}
+		return u, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load users: %w", err)
+	}
+	return users, nil
+}
+
+// issueRefreshToken starts a session for userID and signs its refresh
+// token. The session expires together with the token.
+func issueRefreshToken(ctx context.Context, manager *jwt.Manager, sessions *repository.RedisSessionRepository, userID string) (string, error) {
+	lifetime := manager.RefreshTokenDuration()
+	session := entity.NewSession(userID, "cmd/token", "")
+	token, tokenID, err := manager.GenerateRefreshToken(userID, session.ID, lifetime)
+	if err != nil {
+		return "", err
+	}
+
+	session.Rotate(tokenID, lifetime)
+	if err := sessions.Save(ctx, session); err != nil {
+		return "", err
+	}
+	return token, nil
+}
@@ -0,0 +1,96 @@
+package interceptor
+
+import (
+	"context"
+	"net/http"
+
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/TubagusAldiMY/go-template/pkg/logger"
+)
+
+// statusCodes maps the HTTP status of an AppError to the gRPC code it is
+// answered with.
+var statusCodes = map[int]codes.Code{
+	http.StatusBadRequest:            codes.InvalidArgument,
+	http.StatusUnauthorized:          codes.Unauthenticated,
+	http.StatusForbidden:             codes.PermissionDenied,
+	http.StatusNotFound:              codes.NotFound,
+	http.StatusConflict:              codes.AlreadyExists,
+	http.StatusRequestEntityTooLarge: codes.ResourceExhausted,
+	http.StatusUnsupportedMediaType:  codes.InvalidArgument,
+	http.StatusUnprocessableEntity:   codes.InvalidArgument,
+	http.StatusTooManyRequests:       codes.ResourceExhausted,
+	http.StatusServiceUnavailable:    codes.Unavailable,
+}
+
+// sentinelCodes override statusCodes for errors whose HTTP status has a
+// closer gRPC equivalent.
+var sentinelCodes = []struct {
+	err  error
+	code codes.Code
+}{
+	{errors.ErrStaleData, codes.Aborted},
+	{errors.ErrTimeout, codes.DeadlineExceeded},
+}
+
+// Errors converts the errors returned by handlers into gRPC statuses, the
+// counterpart of response.FromError. Errors with a client status keep their
+// message and are mapped onto the matching gRPC code, with the error code in
+// an ErrorInfo detail's reason; anything else is logged and reported as
+// Internal. Handlers may also return a status themselves, which is passed
+// through.
+func Errors() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return nil, toStatus(ctx, info.FullMethod, err)
+		}
+		return resp, nil
+	}
+}
+
+func toStatus(ctx context.Context, method string, err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+	if errors.Is(err, context.Canceled) {
+		return status.Error(codes.Canceled, "Request canceled")
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return status.Error(codes.DeadlineExceeded, "Request timed out")
+	}
+
+	if appErr := errors.StatusError(err); appErr != nil {
+		code, ok := statusCodes[appErr.Status]
+		for _, sentinel := range sentinelCodes {
+			if errors.Is(err, sentinel.err) {
+				code, ok = sentinel.code, true
+			}
+		}
+		if ok {
+			return withReason(code, appErr.Message, errors.Code(err))
+		}
+	}
+
+	logger.FromContext(ctx).Error("request failed",
+		zap.String("method", method),
+		zap.Error(err),
+	)
+	return withReason(codes.Internal, "Internal server error", errors.CodeInternal)
+}
+
+// withReason returns a status carrying reason, the machine-readable code
+// REST responses send in their code field.
+func withReason(code codes.Code, message, reason string) error {
+	st := status.New(code, message)
+	if detailed, err := st.WithDetails(&errdetails.ErrorInfo{Reason: reason}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
@@ -0,0 +1,34 @@
+// Package interceptor holds the gRPC server interceptors, the counterparts
+// of the HTTP middleware in internal/delivery/http/middleware.
+package interceptor
+
+import (
+	"context"
+	"runtime/debug"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/TubagusAldiMY/go-template/pkg/logger"
+)
+
+// Recovery turns a panic in a later handler into an Internal status and logs
+// it with its stack trace.
+func Recovery() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.FromContext(ctx).Error("panic recovered",
+					zap.Any("error", r),
+					zap.String("method", info.FullMethod),
+					zap.ByteString("stack", debug.Stack()),
+				)
+				resp, err = nil, status.Error(codes.Internal, "Internal server error")
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
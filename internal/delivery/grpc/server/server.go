@@ -0,0 +1,38 @@
+// Package server assembles the gRPC server, the counterpart of the HTTP
+// router.
+package server
+
+import (
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+
+	userv1 "github.com/TubagusAldiMY/go-template/api/proto/user/v1"
+	"github.com/TubagusAldiMY/go-template/internal/delivery/grpc/interceptor"
+	userGrpc "github.com/TubagusAldiMY/go-template/internal/domain/user/delivery/grpc"
+	"github.com/TubagusAldiMY/go-template/pkg/jwt"
+)
+
+type Config struct {
+	// Debug registers the reflection service, letting tools such as grpcurl
+	// list and call the services without the proto files.
+	Debug      bool
+	JWTManager *jwt.Manager
+	UserServer *userGrpc.UserServer
+}
+
+// New returns a gRPC server with UserService registered. Start it with
+// Serve.
+func New(cfg *Config) *grpc.Server {
+	srv := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		interceptor.Recovery(),
+		interceptor.Errors(),
+		interceptor.Auth(cfg.JWTManager, userGrpc.PublicMethods...),
+	))
+
+	userv1.RegisterUserServiceServer(srv, cfg.UserServer)
+	if cfg.Debug {
+		reflection.Register(srv)
+	}
+
+	return srv
+}
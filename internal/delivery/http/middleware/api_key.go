@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/TubagusAldiMY/go-template/internal/domain/apikey/entity"
+	"github.com/TubagusAldiMY/go-template/internal/shared/constants"
+	"github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/TubagusAldiMY/go-template/pkg/appcontext"
+	"github.com/TubagusAldiMY/go-template/pkg/logger"
+	"github.com/TubagusAldiMY/go-template/pkg/response"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// APIKeyAuthenticator resolves the user an API key authenticates as.
+type APIKeyAuthenticator interface {
+	Authenticate(ctx context.Context, key string) (*entity.Principal, error)
+}
+
+// APIKeyMiddleware authenticates requests carrying an X-API-Key header and
+// sets the same context keys as AuthMiddleware, which then lets the request
+// through. Requests without the header are left to AuthMiddleware, so it
+// must run before it.
+func APIKeyMiddleware(authenticator APIKeyAuthenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(constants.HeaderAPIKey)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		principal, err := authenticator.Authenticate(c.Request.Context(), key)
+		if err != nil {
+			if errors.Is(err, errors.ErrInvalidAPIKey) {
+				response.Unauthorized(c, "Invalid API key")
+			} else {
+				logger.FromContext(c.Request.Context()).Error("failed to authenticate api key", zap.Error(err))
+				response.InternalServerError(c, "Internal server error")
+			}
+			c.Abort()
+			return
+		}
+
+		c.Set(constants.ContextKeyUserID, principal.UserID)
+		c.Set(constants.ContextKeyUserEmail, principal.Email)
+		c.Set(constants.ContextKeyUserRole, principal.Role)
+		c.Set(constants.ContextKeyAPIKeyID, principal.KeyID)
+		ctx := appcontext.WithUserID(c.Request.Context(), principal.UserID)
+		ctx = appcontext.WithEmail(ctx, principal.Email)
+		ctx = appcontext.WithRole(ctx, principal.Role)
+		ctx = appcontext.WithAPIKeyID(ctx, principal.KeyID)
+		c.Request = c.Request.WithContext(logger.WithContext(ctx,
+			zap.String("user_id", principal.UserID),
+			zap.String("api_key_id", principal.KeyID),
+		))
+
+		c.Next()
+	}
+}
@@ -1,18 +1,36 @@
 package middleware
 
 import (
+	"errors"
 	"strings"
 
 	"github.com/TubagusAldiMY/go-template/internal/shared/constants"
+	"github.com/TubagusAldiMY/go-template/pkg/appcontext"
+	"github.com/TubagusAldiMY/go-template/pkg/authz"
 	"github.com/TubagusAldiMY/go-template/pkg/jwt"
+	"github.com/TubagusAldiMY/go-template/pkg/logger"
 	"github.com/TubagusAldiMY/go-template/pkg/response"
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
+// AuthMiddleware authenticates the bearer token in the Authorization header.
+// Without the header, the token is read from the access_token cookie; such
+// requests must also pass the CSRF middleware.
 func AuthMiddleware(jwtManager *jwt.Manager) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// Already authenticated by APIKeyMiddleware.
+		if c.GetString(constants.ContextKeyAPIKeyID) != "" {
+			c.Next()
+			return
+		}
+
 		authHeader := c.GetHeader(constants.HeaderAuthorization)
 		if authHeader == "" {
+			if token, err := c.Cookie(constants.CookieAccessToken); err == nil && token != "" {
+				authenticateToken(c, jwtManager, token)
+				return
+			}
 			response.Unauthorized(c, "Authorization header is required")
 			c.Abort()
 			return
@@ -26,21 +44,68 @@ func AuthMiddleware(jwtManager *jwt.Manager) gin.HandlerFunc {
 			return
 		}
 
-		token := parts[1]
-		claims, err := jwtManager.ValidateAccessToken(token)
-		if err != nil {
-			response.Unauthorized(c, "Invalid or expired token")
+		authenticateToken(c, jwtManager, parts[1])
+	}
+}
+
+// WebSocketAuthMiddleware is AuthMiddleware for WebSocket upgrades. Browsers
+// cannot set headers on them, so without an Authorization header the token is
+// read from the access_token query parameter instead.
+func WebSocketAuthMiddleware(jwtManager *jwt.Manager) gin.HandlerFunc {
+	headerAuth := AuthMiddleware(jwtManager)
+
+	return func(c *gin.Context) {
+		if c.GetString(constants.ContextKeyAPIKeyID) != "" || c.GetHeader(constants.HeaderAuthorization) != "" {
+			headerAuth(c)
+			return
+		}
+
+		token := c.Query(constants.QueryParamAccessToken)
+		if token == "" {
+			response.Unauthorized(c, "Authorization header or access_token parameter is required")
 			c.Abort()
 			return
 		}
 
-		// Set user context
-		c.Set(constants.ContextKeyUserID, claims.UserID)
-		c.Set(constants.ContextKeyUserEmail, claims.Email)
-		c.Set(constants.ContextKeyUserRole, claims.Role)
+		authenticateToken(c, jwtManager, token)
+	}
+}
 
-		c.Next()
+// authenticateToken validates an access token and sets the user context, or
+// aborts with 401.
+func authenticateToken(c *gin.Context, jwtManager *jwt.Manager, token string) {
+	claims, err := jwtManager.ValidateAccessToken(c.Request.Context(), token)
+	if err != nil {
+		if errors.Is(err, jwt.ErrEpochUnavailable) {
+			logger.FromContext(c.Request.Context()).Error("failed to check token epoch", zap.Error(err))
+			response.ServiceUnavailable(c, "Authentication is temporarily unavailable")
+			c.Abort()
+			return
+		}
+		response.Unauthorized(c, "Invalid or expired token")
+		c.Abort()
+		return
 	}
+
+	// Set user context
+	c.Set(constants.ContextKeyUserID, claims.UserID)
+	c.Set(constants.ContextKeyUserEmail, claims.Email)
+	c.Set(constants.ContextKeyUserRole, claims.Role)
+	ctx := appcontext.WithUserID(c.Request.Context(), claims.UserID)
+	ctx = appcontext.WithEmail(ctx, claims.Email)
+	ctx = appcontext.WithRole(ctx, claims.Role)
+	fields := []zap.Field{zap.String("user_id", claims.UserID)}
+
+	// Impersonation tokens name the admin acting as the user; logs
+	// attribute the request to both.
+	if claims.Act != nil {
+		c.Set(constants.ContextKeyActorID, claims.Act.Subject)
+		ctx = appcontext.WithActorID(ctx, claims.Act.Subject)
+		fields = append(fields, zap.String("actor_id", claims.Act.Subject))
+	}
+	c.Request = c.Request.WithContext(logger.WithContext(ctx, fields...))
+
+	c.Next()
 }
 
 func RequireRole(roles ...string) gin.HandlerFunc {
@@ -70,3 +135,24 @@ func RequireRole(roles ...string) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// RequirePermission allows the request only if the authenticated user's role
+// grants perm under the active authz policy.
+func RequirePermission(perm string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userRole := c.GetString(constants.ContextKeyUserRole)
+		if userRole == "" {
+			response.Unauthorized(c, "Unauthorized")
+			c.Abort()
+			return
+		}
+
+		if !authz.HasPermission(userRole, perm) {
+			response.Forbidden(c, "Insufficient permissions")
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,31 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/TubagusAldiMY/go-template/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// MaxBodySize limits request bodies to maxBytes. Requests that declare a
+// larger Content-Length are rejected with 413 up front; otherwise reads past
+// the limit fail with *http.MaxBytesError, which response.InvalidBody turns
+// into a 413 as well. A non-positive maxBytes disables the limit.
+func MaxBodySize(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if maxBytes <= 0 || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > maxBytes {
+			response.RequestEntityTooLarge(c, fmt.Sprintf("Request body must not exceed %d bytes", maxBytes))
+			c.Abort()
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		c.Next()
+	}
+}
@@ -1,34 +1,74 @@
 package middleware
 
 import (
+	"strconv"
 	"strings"
+	"sync/atomic"
 
 	"github.com/TubagusAldiMY/go-template/internal/infrastructure/config"
 	"github.com/gin-gonic/gin"
 )
 
 func CORS(cfg config.CORSConfig) gin.HandlerFunc {
+	return NewCORSPolicy(cfg).Middleware()
+}
+
+// CORSPolicy holds CORS settings that can be replaced while the server runs.
+type CORSPolicy struct {
+	cfg atomic.Pointer[config.CORSConfig]
+}
+
+func NewCORSPolicy(cfg config.CORSConfig) *CORSPolicy {
+	p := &CORSPolicy{}
+	p.Update(cfg)
+	return p
+}
+
+// Update makes requests handled from now on use cfg.
+func (p *CORSPolicy) Update(cfg config.CORSConfig) {
+	p.cfg.Store(&cfg)
+}
+
+// Config returns the settings in effect.
+func (p *CORSPolicy) Config() config.CORSConfig {
+	return *p.cfg.Load()
+}
+
+// Middleware is CORS for the policy's current settings.
+func (p *CORSPolicy) Middleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		cfg := p.cfg.Load()
+		maxAge := strconv.Itoa(int(cfg.MaxAge.Seconds()))
 		origin := c.GetHeader("Origin")
 
 		// Check if origin is allowed
 		allowedOrigin := ""
 		for _, allowed := range cfg.AllowedOrigins {
-			if allowed == "*" || allowed == origin {
+			if allowed == "*" {
 				allowedOrigin = allowed
 				break
 			}
+			if origin != "" && originMatches(allowed, origin) {
+				// Echo the concrete origin so credentialed requests stay valid.
+				allowedOrigin = origin
+				break
+			}
 		}
 
 		if allowedOrigin != "" {
 			c.Header("Access-Control-Allow-Origin", allowedOrigin)
+			if allowedOrigin != "*" {
+				c.Header("Vary", "Origin")
+			}
 		}
 
 		c.Header("Access-Control-Allow-Methods", strings.Join(cfg.AllowedMethods, ","))
 		c.Header("Access-Control-Allow-Headers", strings.Join(cfg.AllowedHeaders, ","))
 		c.Header("Access-Control-Expose-Headers", strings.Join(cfg.ExposedHeaders, ","))
-		c.Header("Access-Control-Max-Age", cfg.MaxAge.String())
-		c.Header("Access-Control-Allow-Credentials", "true")
+		c.Header("Access-Control-Max-Age", maxAge)
+		if cfg.AllowCredentials {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
 
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(204)
@@ -38,3 +78,35 @@ func CORS(cfg config.CORSConfig) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// OriginAllowed reports whether cfg allows requests from origin. It is used
+// where the CORS headers do not apply, such as WebSocket upgrades.
+func OriginAllowed(cfg config.CORSConfig, origin string) bool {
+	for _, allowed := range cfg.AllowedOrigins {
+		if allowed == "*" || originMatches(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// originMatches reports whether origin matches pattern. A pattern such as
+// "https://*.example.com" matches any subdomain of example.com over https,
+// but not example.com itself.
+func originMatches(pattern, origin string) bool {
+	prefix, suffix, found := strings.Cut(pattern, "*")
+	if !found {
+		return pattern == origin
+	}
+
+	if !strings.HasPrefix(suffix, ".") || len(origin) <= len(prefix)+len(suffix) {
+		return false
+	}
+
+	if !strings.HasPrefix(origin, prefix) || !strings.HasSuffix(origin, suffix) {
+		return false
+	}
+
+	subdomain := origin[len(prefix) : len(origin)-len(suffix)]
+	return !strings.ContainsAny(subdomain, "/:@")
+}
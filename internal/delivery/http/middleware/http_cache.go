@@ -0,0 +1,179 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/internal/shared/constants"
+	"github.com/TubagusAldiMY/go-template/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// credentialHeaders are the request headers that identify the caller. Every
+// cached response varies on them, so a shared cache never hands one user's
+// response to another.
+var credentialHeaders = []string{constants.HeaderAuthorization, constants.HeaderAPIKey, "Cookie"}
+
+// CacheConfig describes how clients may cache the responses of a route.
+type CacheConfig struct {
+	// MaxAge is how long a response may be reused without asking the
+	// server; zero makes clients revalidate it with If-None-Match every time.
+	MaxAge time.Duration
+	// Vary lists request headers, besides the credentials, that the
+	// response depends on.
+	Vary []string
+}
+
+// cacheWriter holds back the handler's response so its ETag can be computed
+// from the complete body, and the body dropped for a 304.
+type cacheWriter struct {
+	gin.ResponseWriter
+	body    bytes.Buffer
+	status  int
+	written bool
+}
+
+func (w *cacheWriter) WriteHeader(code int) {
+	if !w.written {
+		w.status = code
+	}
+}
+
+func (w *cacheWriter) WriteHeaderNow() {
+	w.written = true
+}
+
+func (w *cacheWriter) Write(b []byte) (int, error) {
+	w.written = true
+	return w.body.Write(b)
+}
+
+func (w *cacheWriter) WriteString(s string) (int, error) {
+	w.written = true
+	return w.body.WriteString(s)
+}
+
+func (w *cacheWriter) Status() int {
+	return w.status
+}
+
+func (w *cacheWriter) Size() int {
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *cacheWriter) Written() bool {
+	return w.written
+}
+
+// Flush is a no-op: the response is only sent once the handler returns.
+func (w *cacheWriter) Flush() {}
+
+// HTTPCache lets clients cache the 2xx responses of a GET route and
+// revalidate them cheaply. It tags each response with a weak ETag computed
+// from the body and the authenticated user, answers a matching If-None-Match
+// with 304 and no body, and sets Cache-Control and Vary from cfg. Responses
+// to authenticated requests are private and vary on the credential headers,
+// so neither a shared cache nor a matching ETag can serve one user's
+// response to another; it must therefore run after AuthMiddleware. Other
+// methods and statuses pass through untouched. The handler still runs on
+// every request; only the transfer of an unchanged body is saved.
+func HTTPCache(cfg CacheConfig) gin.HandlerFunc {
+	vary := strings.Join(append(append([]string{}, credentialHeaders...), cfg.Vary...), ", ")
+	maxAge := "max-age=" + strconv.Itoa(int(cfg.MaxAge.Seconds()))
+	if cfg.MaxAge <= 0 {
+		maxAge = "no-cache"
+	}
+
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+
+		original := c.Writer
+		writer := &cacheWriter{ResponseWriter: original, status: http.StatusOK}
+		c.Writer = writer
+		// Restore the real writer even on panic so Recovery can respond.
+		defer func() {
+			c.Writer = original
+		}()
+
+		c.Next()
+
+		c.Writer = original
+
+		status := writer.status
+		if status < http.StatusOK || status >= http.StatusMultipleChoices {
+			writeCached(c, status, writer.body.Bytes())
+			return
+		}
+
+		userID := c.GetString(constants.ContextKeyUserID)
+		etag := weakETag(userID, writer.body.Bytes())
+
+		visibility := "public"
+		if userID != "" {
+			visibility = "private"
+		}
+		header := c.Writer.Header()
+		header.Set("ETag", etag)
+		header.Set("Cache-Control", visibility+", "+maxAge)
+		header.Add("Vary", vary)
+
+		if etagMatches(c.GetHeader("If-None-Match"), etag) {
+			header.Del(constants.HeaderContentType)
+			header.Del("Content-Length")
+			writeCached(c, http.StatusNotModified, nil)
+			return
+		}
+
+		writeCached(c, status, writer.body.Bytes())
+	}
+}
+
+func writeCached(c *gin.Context, status int, body []byte) {
+	c.Writer.WriteHeader(status)
+	c.Writer.WriteHeaderNow()
+	if len(body) > 0 {
+		if _, err := c.Writer.Write(body); err != nil {
+			logger.FromContext(c.Request.Context()).Warn("failed to write response", zap.Error(err))
+		}
+	}
+}
+
+// weakETag identifies body as served to userID. Hashing the user in means a
+// client that switches accounts never revalidates one user's response as
+// another's, even when the bodies happen to match.
+func weakETag(userID string, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(userID))
+	hash.Write([]byte{0})
+	hash.Write(body)
+	return `W/"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+}
+
+// etagMatches reports whether an If-None-Match header names etag, using the
+// weak comparison RFC 9110 prescribes for If-None-Match.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+
+	opaque := strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == opaque {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,191 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/internal/shared/constants"
+	"github.com/TubagusAldiMY/go-template/pkg/logger"
+	"github.com/TubagusAldiMY/go-template/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const maxIdempotencyKeyLength = 255
+
+// IdempotencyStore is the subset of the Redis client used to record responses.
+type IdempotencyStore interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error)
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// idempotencyRecord is stored under the idempotency key. A zero Status means
+// the first request is still being processed.
+type idempotencyRecord struct {
+	Fingerprint string `json:"fingerprint"`
+	Status      int    `json:"status,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+type bodyCaptureWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyCaptureWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *bodyCaptureWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency replays the first response for requests carrying the same
+// Idempotency-Key header. Keys are scoped to the route and to the
+// authenticated user when there is one, so it must run after AuthMiddleware
+// on protected routes. A request reusing a key while the first one is still
+// in flight gets 409; reusing a key with a different body gets 422.
+// Requests without the header, and all requests while Redis is unavailable,
+// pass through untouched.
+func Idempotency(store IdempotencyStore, ttl time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		idempotencyKey := c.GetHeader(constants.HeaderIdempotencyKey)
+		if idempotencyKey == "" {
+			c.Next()
+			return
+		}
+
+		if len(idempotencyKey) > maxIdempotencyKeyLength {
+			response.BadRequest(c, "Idempotency-Key header is too long", nil)
+			c.Abort()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			response.InvalidBody(c, err)
+			c.Abort()
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		ctx := c.Request.Context()
+		key := idempotencyCacheKey(c, idempotencyKey)
+		fingerprint := requestFingerprint(body)
+
+		pending, err := json.Marshal(idempotencyRecord{Fingerprint: fingerprint})
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to encode idempotency record", zap.Error(err))
+			c.Next()
+			return
+		}
+
+		acquired, err := store.SetNX(ctx, key, pending, ttl)
+		if err != nil {
+			logger.FromContext(ctx).Warn("idempotency store unavailable, skipping", zap.Error(err))
+			c.Next()
+			return
+		}
+
+		if !acquired {
+			replayIdempotentResponse(c, store, key, fingerprint)
+			return
+		}
+
+		writer := &bodyCaptureWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+
+		c.Next()
+
+		// Server errors are not recorded so the client can retry with the
+		// same key once the failure is resolved.
+		status := writer.Status()
+		if status >= http.StatusInternalServerError {
+			if err := store.Delete(context.WithoutCancel(ctx), key); err != nil {
+				logger.FromContext(ctx).Warn("failed to release idempotency key", zap.Error(err))
+			}
+			return
+		}
+
+		record, err := json.Marshal(idempotencyRecord{
+			Fingerprint: fingerprint,
+			Status:      status,
+			ContentType: writer.Header().Get(constants.HeaderContentType),
+			Body:        writer.body.Bytes(),
+		})
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to encode idempotency record", zap.Error(err))
+			return
+		}
+
+		if err := store.Set(context.WithoutCancel(ctx), key, record, ttl); err != nil {
+			logger.FromContext(ctx).Warn("failed to store idempotent response", zap.Error(err))
+		}
+	}
+}
+
+func replayIdempotentResponse(c *gin.Context, store IdempotencyStore, key, fingerprint string) {
+	data, err := store.Get(c.Request.Context(), key)
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			// The first request failed and released the key in between.
+			response.Conflict(c, "Request with this Idempotency-Key is being processed", nil)
+		} else {
+			logger.FromContext(c.Request.Context()).Error("failed to read idempotency record", zap.Error(err))
+			response.InternalServerError(c, "Internal server error")
+		}
+		c.Abort()
+		return
+	}
+
+	var record idempotencyRecord
+	if err := json.Unmarshal([]byte(data), &record); err != nil {
+		logger.FromContext(c.Request.Context()).Error("failed to decode idempotency record", zap.Error(err))
+		response.InternalServerError(c, "Internal server error")
+		c.Abort()
+		return
+	}
+
+	if record.Fingerprint != fingerprint {
+		response.UnprocessableEntity(c, "Idempotency-Key was already used with a different request", nil)
+		c.Abort()
+		return
+	}
+
+	if record.Status == 0 {
+		response.Conflict(c, "Request with this Idempotency-Key is being processed", nil)
+		c.Abort()
+		return
+	}
+
+	c.Header(constants.HeaderIdempotentReplayed, "true")
+	c.Data(record.Status, record.ContentType, record.Body)
+	c.Abort()
+}
+
+func idempotencyCacheKey(c *gin.Context, idempotencyKey string) string {
+	scope := c.GetString(constants.ContextKeyUserID)
+	if scope == "" {
+		scope = "anonymous"
+	}
+
+	return constants.CacheKeyIdempotencyPrefix + scope + ":" + c.Request.Method + ":" + c.FullPath() + ":" + idempotencyKey
+}
+
+func requestFingerprint(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
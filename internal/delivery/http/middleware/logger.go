@@ -1,22 +1,76 @@
 package middleware
 
 import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/config"
 	"github.com/TubagusAldiMY/go-template/internal/shared/constants"
 	"github.com/TubagusAldiMY/go-template/pkg/logger"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
-func RequestLogger() gin.HandlerFunc {
+const accessLogFormatJSON = "json"
+
+// requestIDToken matches the request IDs kept under the token policy: short
+// enough for log lines, and free of spaces, newlines and quotes that could
+// forge log entries.
+var requestIDToken = regexp.MustCompile(`^[A-Za-z0-9._:=-]{1,128}$`)
+
+// redactedHeaderValue replaces the value of sensitive headers in logs.
+const redactedHeaderValue = "[REDACTED]"
+
+// sensitiveHeaders carry credentials and are always redacted from logged
+// headers.
+var sensitiveHeaders = []string{
+	constants.HeaderAuthorization,
+	constants.HeaderAPIKey,
+	constants.HeaderCSRFToken,
+	"Cookie",
+	"Proxy-Authorization",
+}
+
+// RequestLogger assigns a request ID and writes an access log entry per
+// request. An X-Request-ID sent by the client or an upstream proxy is kept
+// for tracing when cfg.RequestIDPolicy accepts it and replaced with a UUID
+// otherwise. Successful 2xx responses are sampled according to
+// cfg.AccessLogSampleRate; errors, other statuses and slow requests are
+// always logged. The logged client_ip is resolved through the engine's
+// trusted proxies. With cfg.RequestHeaders the request headers are logged
+// too, with sensitive ones redacted.
+func RequestLogger(cfg config.LogConfig) gin.HandlerFunc {
+	// Without a dedicated JSON logger entries go through the app logger.
+	var jsonLog *zap.Logger
+	if cfg.AccessLogFormat == accessLogFormatJSON {
+		var err error
+		if jsonLog, err = logger.NewJSONLogger(cfg.Output, cfg.Rotation()); err != nil {
+			logger.Warn("failed to create JSON access logger, using app logger", zap.Error(err))
+		}
+	}
+
+	sampleRate := uint64(1)
+	if cfg.AccessLogSampleRate > 1 {
+		sampleRate = uint64(cfg.AccessLogSampleRate)
+	}
+	var successCount atomic.Uint64
+
+	redact := make(map[string]struct{}, len(sensitiveHeaders)+len(cfg.RedactHeaders))
+	for _, name := range append(append([]string{}, sensitiveHeaders...), cfg.RedactHeaders...) {
+		redact[http.CanonicalHeaderKey(name)] = struct{}{}
+	}
+
 	return func(c *gin.Context) {
 		start := time.Now()
 
-		// Generate request ID
 		requestID := c.GetHeader(constants.HeaderRequestID)
-		if requestID == "" {
+		if !acceptRequestID(cfg.RequestIDPolicy, requestID) {
 			requestID = uuid.New().String()
 		}
 		c.Set(constants.ContextKeyRequestID, requestID)
@@ -25,17 +79,116 @@ func RequestLogger() gin.HandlerFunc {
 		// Process request
 		c.Next()
 
-		// Log request
 		duration := time.Since(start)
-		logger.Info("http request",
+		status := c.Writer.Status()
+		slow := cfg.SlowRequestThreshold > 0 && duration >= cfg.SlowRequestThreshold
+
+		level := zapcore.InfoLevel
+		switch {
+		case slow || status >= http.StatusInternalServerError:
+			level = zapcore.WarnLevel
+		case status >= http.StatusOK && status < http.StatusMultipleChoices && len(c.Errors) == 0:
+			if successCount.Add(1)%sampleRate != 0 {
+				return
+			}
+		}
+
+		fields := []zap.Field{
 			zap.String("request_id", requestID),
 			zap.String("method", c.Request.Method),
 			zap.String("path", c.Request.URL.Path),
-			zap.String("query", c.Request.URL.RawQuery),
-			zap.Int("status", c.Writer.Status()),
+			zap.String("query", redactQuery(c.Request.URL.RawQuery)),
+			zap.Int("status", status),
 			zap.Duration("duration", duration),
+			zap.Int("bytes", c.Writer.Size()),
 			zap.String("client_ip", c.ClientIP()),
 			zap.String("user_agent", c.GetHeader(constants.HeaderUserAgent)),
-		)
+		}
+		if traceID := c.GetString(constants.ContextKeyTraceID); traceID != "" {
+			fields = append(fields, zap.String("trace_id", traceID))
+		}
+		if cfg.RequestHeaders {
+			fields = append(fields, zap.Any("headers", redactHeaders(c.Request.Header, redact)))
+		}
+		if slow {
+			fields = append(fields, zap.Bool("slow", true))
+		}
+		if len(c.Errors) > 0 {
+			fields = append(fields, zap.String("errors", c.Errors.String()))
+		}
+
+		switch {
+		case jsonLog != nil:
+			if entry := jsonLog.Check(level, "http request"); entry != nil {
+				entry.Write(fields...)
+			}
+		case level == zapcore.WarnLevel:
+			logger.Warn("http request", fields...)
+		default:
+			logger.Info("http request", fields...)
+		}
+	}
+}
+
+// acceptRequestID reports whether an incoming request ID is kept under
+// policy.
+func acceptRequestID(policy, id string) bool {
+	switch policy {
+	case config.RequestIDPolicyRegenerate:
+		return false
+	case config.RequestIDPolicyUUID:
+		// uuid.Parse also accepts braced and urn: forms; only keep the
+		// canonical 36-character one.
+		_, err := uuid.Parse(id)
+		return err == nil && len(id) == 36
+	default:
+		return requestIDToken.MatchString(id)
+	}
+}
+
+// redactQuery hides the access_token query parameter so tokens passed on
+// WebSocket upgrades never reach the logs.
+func redactQuery(rawQuery string) string {
+	if !strings.Contains(rawQuery, constants.QueryParamAccessToken) {
+		return rawQuery
+	}
+
+	values, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return "[unparsable query redacted]"
+	}
+	if values.Has(constants.QueryParamAccessToken) {
+		values.Set(constants.QueryParamAccessToken, "REDACTED")
+	}
+	return values.Encode()
+}
+
+// redactHeaders flattens header for logging, replacing the values of the
+// headers in redact, keyed by canonical name, with [REDACTED].
+func redactHeaders(header http.Header, redact map[string]struct{}) map[string]string {
+	logged := make(map[string]string, len(header))
+	for name, values := range header {
+		if _, ok := redact[http.CanonicalHeaderKey(name)]; ok {
+			logged[name] = redactedHeaderValue
+			continue
+		}
+		logged[name] = strings.Join(values, ", ")
+	}
+	return logged
+}
+
+// ContextLogger stores a request-scoped logger in the request context, tagged
+// with the request ID and, when tracing is enabled, the trace ID. It must run
+// after RequestLogger and Tracing. AuthMiddleware adds the user ID.
+func ContextLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		fields := []zap.Field{zap.String("request_id", c.GetString(constants.ContextKeyRequestID))}
+		if traceID := c.GetString(constants.ContextKeyTraceID); traceID != "" {
+			fields = append(fields, zap.String("trace_id", traceID))
+		}
+
+		c.Request = c.Request.WithContext(logger.WithContext(c.Request.Context(), fields...))
+
+		c.Next()
 	}
 }
@@ -0,0 +1,147 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/internal/shared/constants"
+	"github.com/TubagusAldiMY/go-template/pkg/logger"
+	"github.com/TubagusAldiMY/go-template/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// defaultMaintenanceMessage is returned to refused requests when maintenance
+// mode was turned on without a message.
+const defaultMaintenanceMessage = "Service is under maintenance, please retry later"
+
+// MaintenanceStore is the subset of the Redis client holding the maintenance
+// flag.
+type MaintenanceStore interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// MaintenanceStatus is the maintenance mode shared by every replica.
+type MaintenanceStatus struct {
+	Enabled bool `json:"enabled"`
+	// Message is returned to the requests refused during maintenance.
+	Message string `json:"message,omitempty"`
+	// Since is when maintenance mode was turned on, and EnabledBy the ID of
+	// the user who did.
+	Since     *time.Time `json:"since,omitempty"`
+	EnabledBy string     `json:"enabled_by,omitempty"`
+}
+
+// Maintenance refuses writes while maintenance mode is on, so a deploy can
+// migrate data while reads stay up. The flag lives in Redis, so turning it on
+// or off through one replica applies to all of them.
+type Maintenance struct {
+	store      MaintenanceStore
+	retryAfter string
+	allowed    map[string]struct{}
+}
+
+// NewMaintenance returns a Maintenance whose refusals ask clients to retry
+// after retryAfter. allowedRoutes are gin route patterns whose writes are
+// still served during maintenance; the route that turns it off must be one.
+func NewMaintenance(store MaintenanceStore, retryAfter time.Duration, allowedRoutes ...string) *Maintenance {
+	allowed := make(map[string]struct{}, len(allowedRoutes))
+	for _, route := range allowedRoutes {
+		allowed[route] = struct{}{}
+	}
+
+	return &Maintenance{
+		store:      store,
+		retryAfter: strconv.Itoa(int(retryAfter.Seconds())),
+		allowed:    allowed,
+	}
+}
+
+// Status returns the current maintenance mode.
+func (m *Maintenance) Status(ctx context.Context) (*MaintenanceStatus, error) {
+	data, err := m.store.Get(ctx, constants.CacheKeyMaintenance)
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return &MaintenanceStatus{}, nil
+		}
+		return nil, fmt.Errorf("failed to read maintenance mode: %w", err)
+	}
+
+	var status MaintenanceStatus
+	if err := json.Unmarshal([]byte(data), &status); err != nil {
+		return nil, fmt.Errorf("failed to decode maintenance mode: %w", err)
+	}
+	status.Enabled = true
+
+	return &status, nil
+}
+
+// Enable turns maintenance mode on for every replica until Disable is
+// called. userID records who turned it on.
+func (m *Maintenance) Enable(ctx context.Context, message, userID string) (*MaintenanceStatus, error) {
+	since := time.Now().UTC()
+	status := &MaintenanceStatus{Enabled: true, Message: message, Since: &since, EnabledBy: userID}
+
+	data, err := json.Marshal(status)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode maintenance mode: %w", err)
+	}
+	if err := m.store.Set(ctx, constants.CacheKeyMaintenance, data, 0); err != nil {
+		return nil, fmt.Errorf("failed to enable maintenance mode: %w", err)
+	}
+
+	return status, nil
+}
+
+// Disable turns maintenance mode off for every replica.
+func (m *Maintenance) Disable(ctx context.Context) error {
+	if err := m.store.Delete(ctx, constants.CacheKeyMaintenance); err != nil {
+		return fmt.Errorf("failed to disable maintenance mode: %w", err)
+	}
+	return nil
+}
+
+// Middleware answers writes with 503 and a Retry-After header while
+// maintenance mode is on. GET, HEAD and OPTIONS requests and the allowed
+// routes are always served, and only writes look the flag up. While Redis is
+// unavailable the flag cannot be read and writes are served.
+func (m *Maintenance) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+		if _, ok := m.allowed[c.FullPath()]; ok {
+			c.Next()
+			return
+		}
+
+		status, err := m.Status(c.Request.Context())
+		if err != nil {
+			logger.FromContext(c.Request.Context()).Warn("maintenance mode unavailable, serving request", zap.Error(err))
+			c.Next()
+			return
+		}
+		if !status.Enabled {
+			c.Next()
+			return
+		}
+
+		message := status.Message
+		if message == "" {
+			message = defaultMaintenanceMessage
+		}
+		c.Header(constants.HeaderRetryAfter, m.retryAfter)
+		response.ServiceUnavailable(c, message)
+		c.Abort()
+	}
+}
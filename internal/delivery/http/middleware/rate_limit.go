@@ -1,18 +1,31 @@
 package middleware
 
 import (
+	"math"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/TubagusAldiMY/go-template/internal/infrastructure/config"
+	"github.com/TubagusAldiMY/go-template/internal/shared/constants"
 	"github.com/TubagusAldiMY/go-template/pkg/response"
 	"github.com/gin-gonic/gin"
 	"golang.org/x/time/rate"
 )
 
+// rateLimitDetails is returned in the errors field of a 429 response. Limit
+// requests are allowed per Window, with bursts of up to Burst.
+type rateLimitDetails struct {
+	Limit      float64 `json:"limit"`
+	Burst      int     `json:"burst"`
+	Window     string  `json:"window"`
+	RetryAfter int     `json:"retry_after"`
+}
+
 type RateLimiter struct {
 	limiters map[string]*rate.Limiter
 	mu       sync.RWMutex
+	enabled  bool
 	rate     rate.Limit
 	burst    int
 }
@@ -20,50 +33,135 @@ type RateLimiter struct {
 func NewRateLimiter(r rate.Limit, b int) *RateLimiter {
 	return &RateLimiter{
 		limiters: make(map[string]*rate.Limiter),
+		enabled:  true,
 		rate:     r,
 		burst:    b,
 	}
 }
 
-func (rl *RateLimiter) getLimiter(ip string) *rate.Limiter {
+// Update applies cfg to the limiter, including the buckets of clients seen
+// before, so the config can change while the server runs.
+func (rl *RateLimiter) Update(cfg config.RateLimitConfig) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.enabled = cfg.Enabled
+	rl.rate = rate.Limit(cfg.RequestsPerSecond)
+	rl.burst = cfg.Burst
+	for _, limiter := range rl.limiters {
+		limiter.SetLimit(rl.rate)
+		limiter.SetBurst(rl.burst)
+	}
+}
+
+// settings returns whether the limiter is enabled and its rate and burst.
+func (rl *RateLimiter) settings() (bool, rate.Limit, int) {
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+	return rl.enabled, rl.rate, rl.burst
+}
+
+func (rl *RateLimiter) getLimiter(key string) *rate.Limiter {
 	rl.mu.Lock()
 	defer rl.mu.Unlock()
 
-	limiter, exists := rl.limiters[ip]
+	limiter, exists := rl.limiters[key]
 	if !exists {
 		limiter = rate.NewLimiter(rl.rate, rl.burst)
-		rl.limiters[ip] = limiter
+		rl.limiters[key] = limiter
 	}
 
 	return limiter
 }
 
-func RateLimit(cfg config.RateLimitConfig) gin.HandlerFunc {
-	if !cfg.Enabled {
-		return func(c *gin.Context) {
-			c.Next()
-		}
+// Quota is the state of a client's token bucket.
+type Quota struct {
+	// Limit is the bucket size, the most requests allowed in a burst.
+	Limit int
+	// Remaining is how many requests can be made right now.
+	Remaining int
+	// Reset is how long until the bucket is full again.
+	Reset time.Duration
+}
+
+// QuotaAt reports the quota of l at t.
+func (rl *RateLimiter) QuotaAt(l *rate.Limiter, t time.Time) Quota {
+	tokens := l.TokensAt(t)
+	burst, limit := l.Burst(), l.Limit()
+	quota := Quota{Limit: burst, Remaining: int(math.Max(0, math.Floor(tokens)))}
+	if missing := float64(burst) - tokens; missing > 0 && limit > 0 {
+		quota.Reset = time.Duration(missing / float64(limit) * float64(time.Second))
 	}
+	return quota
+}
 
+// RateLimit limits requests per client IP, as resolved by gin.Context.ClientIP:
+// forwarding headers are only honoured from SERVER_TRUSTED_PROXIES. Every
+// response carries the client's quota in the X-RateLimit-Limit,
+// X-RateLimit-Remaining and X-RateLimit-Reset (seconds until the quota is
+// fully restored) headers; they are omitted when rate limiting is disabled.
+func RateLimit(cfg config.RateLimitConfig) gin.HandlerFunc {
 	limiter := NewRateLimiter(rate.Limit(cfg.RequestsPerSecond), cfg.Burst)
+	limiter.Update(cfg)
+	return limiter.Middleware()
+}
 
+// Middleware is RateLimit for a limiter whose settings may later change
+// through Update.
+func (rl *RateLimiter) Middleware() gin.HandlerFunc {
+	return rl.middleware(func(c *gin.Context) string {
+		return c.ClientIP()
+	})
+}
+
+// PerUserMiddleware is Middleware keyed by the authenticated user instead of
+// the client IP, so a user cannot sidestep the limit by switching networks.
+// It must run after AuthMiddleware; unauthenticated requests fall back to
+// their IP.
+func (rl *RateLimiter) PerUserMiddleware() gin.HandlerFunc {
+	return rl.middleware(func(c *gin.Context) string {
+		if userID := c.GetString(constants.ContextKeyUserID); userID != "" {
+			return "user:" + userID
+		}
+		return c.ClientIP()
+	})
+}
+
+// middleware limits requests per key(c).
+func (rl *RateLimiter) middleware(key func(c *gin.Context) string) gin.HandlerFunc {
 	// Cleanup old limiters every 5 minutes
 	go func() {
 		ticker := time.NewTicker(5 * time.Minute)
 		defer ticker.Stop()
 		for range ticker.C {
-			limiter.mu.Lock()
-			limiter.limiters = make(map[string]*rate.Limiter)
-			limiter.mu.Unlock()
+			rl.mu.Lock()
+			rl.limiters = make(map[string]*rate.Limiter)
+			rl.mu.Unlock()
 		}
 	}()
 
 	return func(c *gin.Context) {
-		ip := c.ClientIP()
-		l := limiter.getLimiter(ip)
+		enabled, limit, burst := rl.settings()
+		if !enabled {
+			c.Next()
+			return
+		}
+
+		l := rl.getLimiter(key(c))
 
-		if !l.Allow() {
-			response.Error(c, 429, "Rate limit exceeded", nil)
+		now := time.Now()
+		allowed := l.AllowN(now, 1)
+		setQuotaHeaders(c, rl.QuotaAt(l, now))
+
+		if !allowed {
+			retryAfter := retryAfterSeconds(l)
+			c.Header(constants.HeaderRetryAfter, strconv.Itoa(retryAfter))
+			response.TooManyRequests(c, "Rate limit exceeded", rateLimitDetails{
+				Limit:      float64(limit),
+				Burst:      burst,
+				Window:     "1s",
+				RetryAfter: retryAfter,
+			})
 			c.Abort()
 			return
 		}
@@ -71,3 +169,28 @@ func RateLimit(cfg config.RateLimitConfig) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+func setQuotaHeaders(c *gin.Context, quota Quota) {
+	c.Header(constants.HeaderRateLimitLimit, strconv.Itoa(quota.Limit))
+	c.Header(constants.HeaderRateLimitRemaining, strconv.Itoa(quota.Remaining))
+	c.Header(constants.HeaderRateLimitReset, strconv.Itoa(int(math.Ceil(quota.Reset.Seconds()))))
+}
+
+// retryAfterSeconds reports how long until l has a token again, rounded up to
+// whole seconds as Retry-After requires. The reservation is cancelled right
+// away so reporting the delay does not use up the token.
+func retryAfterSeconds(l *rate.Limiter) int {
+	now := time.Now()
+	reservation := l.ReserveN(now, 1)
+	if !reservation.OK() {
+		return 1
+	}
+	delay := reservation.DelayFrom(now)
+	reservation.CancelAt(now)
+
+	seconds := int(math.Ceil(delay.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return seconds
+}
@@ -3,30 +3,52 @@ package middleware
 import (
 	"fmt"
 	"net/http"
+	"runtime/debug"
 
+	"github.com/TubagusAldiMY/go-template/internal/shared/constants"
+	sharedErrors "github.com/TubagusAldiMY/go-template/internal/shared/errors"
 	"github.com/TubagusAldiMY/go-template/pkg/logger"
 	"github.com/TubagusAldiMY/go-template/pkg/response"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
-func Recovery() gin.HandlerFunc {
+// PanicDetails is the errors field of the 500 sent for a recovered panic in
+// debug mode.
+type PanicDetails struct {
+	Panic string `json:"panic"`
+	Stack string `json:"stack"`
+}
+
+// Recovery turns a panic in a later handler into a 500 and logs it with its
+// stack trace. Clients get a generic message; the panic value and stack are
+// only included in the response when debugMode is set (APP_DEBUG).
+func Recovery(debugMode bool) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer func() {
 			if err := recover(); err != nil {
-				// Log panic
-				logger.Error("panic recovered",
+				stack := debug.Stack()
+
+				logger.FromContext(c.Request.Context()).Error("panic recovered",
+					zap.String("request_id", c.GetString(constants.ContextKeyRequestID)),
 					zap.Any("error", err),
 					zap.String("path", c.Request.URL.Path),
 					zap.String("method", c.Request.Method),
+					zap.ByteString("stack", stack),
 				)
 
-				// Return internal server error
-				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
-					"success": false,
-					"message": "Internal server error",
-					"error":   fmt.Sprintf("%v", err),
-				})
+				// A response that has already started cannot be replaced.
+				if c.Writer.Written() {
+					c.Abort()
+					return
+				}
+
+				var details interface{}
+				if debugMode {
+					details = PanicDetails{Panic: fmt.Sprintf("%v", err), Stack: string(stack)}
+				}
+				response.ErrorWithCode(c, http.StatusInternalServerError, sharedErrors.CodeInternal, "Internal server error", details)
+				c.Abort()
 			}
 		}()
 		c.Next()
@@ -0,0 +1,144 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/pkg/logger"
+	"github.com/TubagusAldiMY/go-template/pkg/response"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// timeoutWriter buffers the handler's response so it can be replaced with a
+// 503 if the deadline passes before the handler returns.
+type timeoutWriter struct {
+	gin.ResponseWriter
+	header  http.Header
+	body    bytes.Buffer
+	status  int
+	written bool
+}
+
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	if !w.written {
+		w.status = code
+	}
+}
+
+func (w *timeoutWriter) WriteHeaderNow() {
+	w.written = true
+}
+
+func (w *timeoutWriter) Write(b []byte) (int, error) {
+	w.written = true
+	return w.body.Write(b)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	w.written = true
+	return w.body.WriteString(s)
+}
+
+func (w *timeoutWriter) Status() int {
+	return w.status
+}
+
+func (w *timeoutWriter) Size() int {
+	if !w.written {
+		return -1
+	}
+	return w.body.Len()
+}
+
+func (w *timeoutWriter) Written() bool {
+	return w.written
+}
+
+// Flush is a no-op: the response is only sent once the handler returns.
+func (w *timeoutWriter) Flush() {}
+
+func (w *timeoutWriter) flushTo(dst gin.ResponseWriter) {
+	header := dst.Header()
+	for k := range header {
+		delete(header, k)
+	}
+	for k, v := range w.header {
+		header[k] = v
+	}
+
+	dst.WriteHeader(w.status)
+	dst.WriteHeaderNow()
+	if w.body.Len() > 0 {
+		if _, err := dst.Write(w.body.Bytes()); err != nil {
+			logger.Warn("failed to write response", zap.Error(err))
+		}
+	}
+}
+
+// Timeout bounds each request with a context deadline of d. Handlers must pass
+// c.Request.Context() down so DB and Redis calls are cancelled when it
+// expires; if the deadline has passed by the time the handler returns, its
+// buffered response is discarded and a 503 is sent instead. Because the
+// response is buffered, streaming routes must be listed in exemptRoutes (as
+// gin route patterns) to pass through untouched. A non-positive d disables it.
+func Timeout(d time.Duration, exemptRoutes ...string) gin.HandlerFunc {
+	if d <= 0 {
+		return func(c *gin.Context) {
+			c.Next()
+		}
+	}
+
+	exempt := make(map[string]struct{}, len(exemptRoutes))
+	for _, route := range exemptRoutes {
+		exempt[route] = struct{}{}
+	}
+
+	return func(c *gin.Context) {
+		if _, ok := exempt[c.FullPath()]; ok {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), d)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+
+		original := c.Writer
+		writer := &timeoutWriter{
+			ResponseWriter: original,
+			header:         original.Header().Clone(),
+			status:         http.StatusOK,
+		}
+		c.Writer = writer
+		// Restore the real writer even on panic so Recovery can respond.
+		defer func() {
+			c.Writer = original
+		}()
+
+		c.Next()
+
+		c.Writer = original
+
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			logger.FromContext(c.Request.Context()).Warn("request timed out",
+				zap.String("method", c.Request.Method),
+				zap.String("path", c.Request.URL.Path),
+				zap.Duration("timeout", d),
+			)
+			response.ServiceUnavailable(c, "Request timed out")
+			c.Abort()
+			return
+		}
+
+		writer.flushTo(original)
+	}
+}
@@ -0,0 +1,101 @@
+package router
+
+import (
+	"github.com/TubagusAldiMY/go-template/internal/delivery/http/middleware"
+	"github.com/TubagusAldiMY/go-template/pkg/appcontext"
+	"github.com/TubagusAldiMY/go-template/pkg/logger"
+	"github.com/TubagusAldiMY/go-template/pkg/response"
+	customValidator "github.com/TubagusAldiMY/go-template/pkg/validator"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// MaintenanceRoute turns maintenance mode on and off. It is always served
+// during maintenance, so it can be turned off again.
+const MaintenanceRoute = "/api/v1/admin/maintenance"
+
+type MaintenanceRequest struct {
+	Enabled *bool `json:"enabled" validate:"required"`
+	// Message is returned to the writes refused during maintenance.
+	Message string `json:"message" validate:"max=200"`
+}
+
+type MaintenanceHandler struct {
+	maintenance *middleware.Maintenance
+}
+
+func NewMaintenanceHandler(maintenance *middleware.Maintenance) *MaintenanceHandler {
+	return &MaintenanceHandler{maintenance: maintenance}
+}
+
+// GetMaintenance godoc
+// @Summary Get maintenance mode
+// @Description Report whether maintenance mode is on (requires the maintenance:manage permission, admins under the default policy)
+// @Tags admin
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response{data=middleware.MaintenanceStatus}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /admin/maintenance [get]
+func (h *MaintenanceHandler) GetMaintenance(c *gin.Context) {
+	status, err := h.maintenance.Status(c.Request.Context())
+	if err != nil {
+		response.FromError(c, err)
+		return
+	}
+
+	response.OK(c, "Maintenance mode retrieved successfully", status)
+}
+
+// SetMaintenance godoc
+// @Summary Set maintenance mode
+// @Description Turn maintenance mode on or off for every replica (requires the maintenance:manage permission, admins under the default policy). While it is on, writes other than to MAINTENANCE_ALLOWED_ROUTES and this endpoint get 503 with a Retry-After header; reads are served as usual.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body MaintenanceRequest true "Maintenance mode"
+// @Success 200 {object} response.Response{data=middleware.MaintenanceStatus}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /admin/maintenance [put]
+func (h *MaintenanceHandler) SetMaintenance(c *gin.Context) {
+	var req MaintenanceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.InvalidBody(c, err)
+		return
+	}
+
+	if err := customValidator.Validate(&req); err != nil {
+		validationErrors := customValidator.FormatValidationErrors(err)
+		response.UnprocessableEntity(c, "Validation failed", validationErrors)
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID, _ := appcontext.UserID(ctx)
+
+	if !*req.Enabled {
+		if err := h.maintenance.Disable(ctx); err != nil {
+			response.FromError(c, err)
+			return
+		}
+		logger.FromContext(ctx).Info("maintenance mode disabled", zap.String("by", userID))
+		response.OK(c, "Maintenance mode disabled", &middleware.MaintenanceStatus{})
+		return
+	}
+
+	status, err := h.maintenance.Enable(ctx, req.Message, userID)
+	if err != nil {
+		response.FromError(c, err)
+		return
+	}
+
+	logger.FromContext(ctx).Info("maintenance mode enabled", zap.String("by", userID))
+	response.OK(c, "Maintenance mode enabled", status)
+}
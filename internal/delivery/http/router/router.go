@@ -1,74 +1,302 @@
 package router
 
 import (
+	"net/http"
+	"time"
+
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
 
 	"github.com/TubagusAldiMY/go-template/internal/delivery/http/middleware"
+	apikeyHttp "github.com/TubagusAldiMY/go-template/internal/domain/apikey/delivery/http"
+	auditHttp "github.com/TubagusAldiMY/go-template/internal/domain/audit/delivery/http"
+	userGraphql "github.com/TubagusAldiMY/go-template/internal/domain/user/delivery/graphql"
 	userHttp "github.com/TubagusAldiMY/go-template/internal/domain/user/delivery/http"
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/cache"
 	"github.com/TubagusAldiMY/go-template/internal/infrastructure/config"
-	"github.com/TubagusAldiMY/go-template/internal/shared/constants"
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/database"
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/health"
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/metrics"
+	"github.com/TubagusAldiMY/go-template/pkg/authz"
+	"github.com/TubagusAldiMY/go-template/pkg/buildinfo"
 	"github.com/TubagusAldiMY/go-template/pkg/jwt"
+	"github.com/TubagusAldiMY/go-template/pkg/logger"
 	"github.com/TubagusAldiMY/go-template/pkg/response"
 )
 
 type RouterConfig struct {
-	Config      *config.Config
-	JWTManager  *jwt.Manager
-	UserHandler *userHttp.UserHandler
+	Config *config.Config
+	// ConfigManager, when set, applies reloaded CORS and rate limit settings
+	// to the running router.
+	ConfigManager        *config.Manager
+	JWTManager           *jwt.Manager
+	DB                   *database.PostgreSQL
+	Redis                *cache.Redis
+	UserHandler          *userHttp.UserHandler
+	UserEventsHandler    *userHttp.UserEventsHandler
+	NotificationsHandler *userHttp.NotificationsHandler
+	AuditHandler         *auditHttp.AuditHandler
+	APIKeyHandler        *apikeyHttp.APIKeyHandler
+	GraphQLHandler       *userGraphql.Handler
+	// APIKeyAuthenticator lets protected routes accept X-API-Key as an
+	// alternative to a bearer token.
+	APIKeyAuthenticator middleware.APIKeyAuthenticator
+	// Readiness backs /health/ready.
+	Readiness *health.Readiness
 }
 
-func SetupRouter(cfg *RouterConfig) *gin.Engine {
-	// Set gin mode
-	if !cfg.Config.App.Debug {
+// LocalUploadsPath is where files stored by the local storage driver are
+// served.
+const LocalUploadsPath = "/uploads"
+
+// streamingRoutes write their response incrementally and are exempt from the
+// buffering Timeout middleware.
+var streamingRoutes = []string{
+	"/api/v1/users/events",
+	"/api/v1/users/export.csv",
+	"/api/v1/ws",
+	pprofRoute,
+}
+
+// pprofRoute serves the profiling handlers; CPU profiles and traces stream
+// for as long as requested.
+const pprofRoute = metrics.PprofPath + "*profile"
+
+// SetupStartupRouter returns the router served while the application is
+// connecting to its dependencies. It answers the health checks, with
+// /health/ready reporting 503 until readiness is set, and 503 for everything
+// else.
+func SetupStartupRouter(cfg *config.Config, readiness *health.Readiness) *gin.Engine {
+	router := newEngine(cfg)
+	router.Use(middleware.Recovery(cfg.App.Debug))
+
+	registerHealthRoutes(router, cfg, readiness)
+	router.NoRoute(func(c *gin.Context) {
+		response.ServiceUnavailable(c, "Service is starting")
+	})
+
+	return router
+}
+
+// newEngine returns an engine without middleware that resolves the client IP
+// used by rate limiting and request logs from forwarding headers only when
+// the request comes from one of cfg.Server.TrustedProxies.
+func newEngine(cfg *config.Config) *gin.Engine {
+	if !cfg.App.Debug {
 		gin.SetMode(gin.ReleaseMode)
 	}
 
 	router := gin.New()
 
-	// Global middleware
-	router.Use(middleware.Recovery())
-	router.Use(middleware.RequestLogger())
-	router.Use(middleware.CORS(cfg.Config.CORS))
-	router.Use(middleware.RateLimit(cfg.Config.RateLimit))
+	// An empty list trusts no proxy; gin's default trusts all of them,
+	// letting any client choose its IP through X-Forwarded-For.
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		// Validate rejects malformed entries, so this only happens with an
+		// unvalidated config. Falling back to trusting none is the safe side.
+		logger.Error("invalid trusted proxies, trusting none", zap.Error(err))
+		if err := router.SetTrustedProxies(nil); err != nil {
+			logger.Error("failed to reset trusted proxies", zap.Error(err))
+		}
+	}
 
-	// Health check
+	return router
+}
+
+// impersonationRateLimit allows each user RATE_LIMIT_IMPERSONATIONS_PER_MINUTE
+// impersonations, all of which may be used at once. It applies whether or not
+// general rate limiting is enabled.
+func impersonationRateLimit(cfg config.RateLimitConfig) config.RateLimitConfig {
+	return config.RateLimitConfig{
+		Enabled:           true,
+		RequestsPerSecond: float64(cfg.ImpersonationsPerMinute) / 60,
+		Burst:             cfg.ImpersonationsPerMinute,
+	}
+}
+
+// registerHealthRoutes adds the liveness check, /health, which succeeds as
+// long as the process is serving, and the readiness check, /health/ready,
+// which fails until readiness is set and while any dependency check fails.
+func registerHealthRoutes(router *gin.Engine, cfg *config.Config, readiness *health.Readiness) {
+	build := buildinfo.Get()
 	router.GET("/health", func(c *gin.Context) {
 		response.OK(c, "Service is healthy", gin.H{
-			"service": cfg.Config.App.Name,
-			"version": "1.0.0",
+			"service":    cfg.App.Name,
+			"version":    build.Version,
+			"commit":     build.Commit,
+			"build_date": build.BuildDate,
 		})
 	})
 
+	router.GET("/health/ready", func(c *gin.Context) {
+		if !readiness.IsReady() {
+			response.ServiceUnavailable(c, "Service is not ready")
+			return
+		}
+		if failures := readiness.Failures(c.Request.Context()); failures != nil {
+			response.Error(c, http.StatusServiceUnavailable, "Service is not ready", failures)
+			return
+		}
+		response.OK(c, "Service is ready", nil)
+	})
+}
+
+func SetupRouter(cfg *RouterConfig) *gin.Engine {
+	router := newEngine(cfg.Config)
+
+	// Global middleware
+	router.Use(middleware.Recovery(cfg.Config.App.Debug))
+	router.Use(middleware.RequestLogger(cfg.Config.Log))
+	if cfg.Config.Tracing.Enabled {
+		router.Use(middleware.Tracing())
+	}
+	router.Use(middleware.ContextLogger())
+	corsPolicy := middleware.NewCORSPolicy(cfg.Config.CORS)
+	rateLimiter := middleware.NewRateLimiter(rate.Limit(cfg.Config.RateLimit.RequestsPerSecond), cfg.Config.RateLimit.Burst)
+	rateLimiter.Update(cfg.Config.RateLimit)
+	impersonationLimiter := middleware.NewRateLimiter(0, 0)
+	impersonationLimiter.Update(impersonationRateLimit(cfg.Config.RateLimit))
+	if cfg.ConfigManager != nil {
+		cfg.ConfigManager.Subscribe(func(c *config.Config) {
+			corsPolicy.Update(c.CORS)
+			rateLimiter.Update(c.RateLimit)
+			impersonationLimiter.Update(impersonationRateLimit(c.RateLimit))
+		})
+	}
+	router.Use(corsPolicy.Middleware())
+	router.Use(rateLimiter.Middleware())
+	router.Use(middleware.MaxBodySize(cfg.Config.Server.MaxRequestBodySize))
+	router.Use(middleware.Timeout(cfg.Config.Server.HandlerTimeout, streamingRoutes...))
+	router.Use(middleware.CSRF())
+
+	// Refuses writes with 503 while maintenance mode is on
+	maintenance := middleware.NewMaintenance(cfg.Redis, cfg.Config.Maintenance.RetryAfter,
+		append([]string{MaintenanceRoute}, cfg.Config.Maintenance.AllowedRoutes...)...)
+	router.Use(maintenance.Middleware())
+
+	// Health checks
+	registerHealthRoutes(router, cfg.Config, cfg.Readiness)
+
+	// Files written by the local storage driver
+	if cfg.Config.Storage.Driver == config.StorageDriverLocal {
+		router.Static(LocalUploadsPath, cfg.Config.Storage.LocalDir)
+	}
+
 	// Swagger documentation
 	if cfg.Config.App.Debug {
 		router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	}
 
+	// Replays responses for retried requests carrying an Idempotency-Key
+	idempotent := middleware.Idempotency(cfg.Redis, cfg.Config.Idempotency.TTL)
+
+	// ETags let clients revalidate unchanged GET responses with
+	// If-None-Match; the build only changes on deploy, so /version may be
+	// reused for a while without asking.
+	revalidate := middleware.HTTPCache(middleware.CacheConfig{})
+	versionCache := middleware.HTTPCache(middleware.CacheConfig{MaxAge: 5 * time.Minute})
+
+	// Protected routes accept an API key or a bearer token
+	apiKeyAuth := middleware.APIKeyMiddleware(cfg.APIKeyAuthenticator)
+	jwtAuth := middleware.AuthMiddleware(cfg.JWTManager)
+	wsAuth := middleware.WebSocketAuthMiddleware(cfg.JWTManager)
+
 	// API v1 routes
 	v1 := router.Group("/api/v1")
 	{
+		// Running build (public)
+		v1.GET("/version", versionCache, Version)
+
+		// CSRF token for cookie-authenticated clients (public)
+		v1.GET("/csrf-token", middleware.CSRFToken(cfg.JWTManager.RememberMeRefreshTokenDuration(), cfg.Config.AuthCookie.Secure))
+
 		// Auth routes (public)
 		auth := v1.Group("/auth")
 		{
-			auth.POST("/register", cfg.UserHandler.Register)
+			auth.POST("/register", idempotent, cfg.UserHandler.Register)
 			auth.POST("/login", cfg.UserHandler.Login)
+			auth.POST("/login/totp", cfg.UserHandler.LoginTOTP)
 			auth.POST("/refresh", cfg.UserHandler.RefreshToken)
+			auth.POST("/logout", cfg.UserHandler.Logout)
+			auth.GET("/me", apiKeyAuth, jwtAuth, revalidate, cfg.UserHandler.Me)
+			auth.POST("/introspect", apiKeyAuth, jwtAuth, middleware.RequirePermission(authz.PermTokenIntrospect), cfg.UserHandler.IntrospectToken)
 		}
 
+		// Notification channel (protected)
+		v1.GET("/ws", apiKeyAuth, wsAuth, cfg.NotificationsHandler.Connect)
+
 		// User routes (protected)
 		users := v1.Group("/users")
-		users.Use(middleware.AuthMiddleware(cfg.JWTManager))
+		users.Use(apiKeyAuth, jwtAuth, auditHttp.ActorContext())
 		{
-			users.GET("/profile", cfg.UserHandler.GetProfile)
+			users.GET("/profile", revalidate, cfg.UserHandler.GetProfile)
 			users.PUT("/profile", cfg.UserHandler.UpdateProfile)
+			users.PATCH("/profile", cfg.UserHandler.PatchProfile)
+			users.DELETE("/profile", cfg.UserHandler.DeleteProfile)
+			users.POST("/profile/avatar", cfg.UserHandler.UploadAvatar)
 			users.POST("/change-password", cfg.UserHandler.ChangePassword)
+			users.POST("/mfa/totp/enable", cfg.UserHandler.EnableTOTP)
+			users.POST("/mfa/totp/verify", cfg.UserHandler.VerifyTOTP)
+			users.GET("/sessions", revalidate, cfg.UserHandler.ListSessions)
+			users.DELETE("/sessions/:id", cfg.UserHandler.RevokeSession)
 
-			// Admin only routes
-			users.GET("", middleware.RequireRole(constants.RoleAdmin), cfg.UserHandler.ListUsers)
-			users.DELETE("/:id", middleware.RequireRole(constants.RoleAdmin), cfg.UserHandler.DeleteUser)
+			// Permission-guarded routes (admin only under the default policy)
+			users.GET("", middleware.RequirePermission(authz.PermUserRead), revalidate, cfg.UserHandler.ListUsers)
+			users.GET("/stats", middleware.RequirePermission(authz.PermUserRead), revalidate, cfg.UserHandler.GetUserStats)
+			users.GET("/events", middleware.RequirePermission(authz.PermUserRead), cfg.UserEventsHandler.StreamEvents)
+			users.GET("/export.csv", middleware.RequirePermission(authz.PermUserRead), cfg.UserHandler.ExportUsers)
+			users.POST("/bulk", middleware.RequirePermission(authz.PermUserCreate), cfg.UserHandler.BulkCreateUsers)
+			users.DELETE("/:id", middleware.RequirePermission(authz.PermUserDelete), cfg.UserHandler.DeleteUser)
+			users.POST("/:id/restore", middleware.RequirePermission(authz.PermUserDelete), cfg.UserHandler.RestoreUser)
+			users.PATCH("/:id/status", middleware.RequirePermission(authz.PermUserUpdate), cfg.UserHandler.ChangeUserStatus)
+			users.POST("/:id/impersonate", middleware.RequirePermission(authz.PermUserImpersonate), impersonationLimiter.PerUserMiddleware(), cfg.UserHandler.ImpersonateUser)
 		}
+
+		// GraphQL over the user domain (protected). The endpoint is open to
+		// every authenticated user; admin-only fields check permissions in
+		// their resolvers.
+		v1.POST("/graphql", apiKeyAuth, jwtAuth, auditHttp.ActorContext(), cfg.GraphQLHandler.Serve)
+
+		// Audit routes (protected)
+		audit := v1.Group("/audit")
+		audit.Use(apiKeyAuth, jwtAuth)
+		{
+			audit.GET("", middleware.RequirePermission(authz.PermAuditRead), cfg.AuditHandler.ListAuditLogs)
+		}
+
+		// API key routes (protected)
+		apiKeys := v1.Group("/api-keys")
+		apiKeys.Use(apiKeyAuth, jwtAuth, auditHttp.ActorContext(), middleware.RequirePermission(authz.PermAPIKeyManage))
+		{
+			apiKeys.POST("", cfg.APIKeyHandler.CreateAPIKey)
+			apiKeys.DELETE("/:id", cfg.APIKeyHandler.RevokeAPIKey)
+		}
+
+		// Admin routes (protected)
+		admin := v1.Group("/admin")
+		admin.Use(apiKeyAuth, jwtAuth, middleware.RequirePermission(authz.PermMaintenanceManage))
+		{
+			maintenanceHandler := NewMaintenanceHandler(maintenance)
+			admin.GET("/maintenance", maintenanceHandler.GetMaintenance)
+			admin.PUT("/maintenance", maintenanceHandler.SetMaintenance)
+		}
+
+		// Debug routes (protected)
+		debug := v1.Group("/debug")
+		debug.Use(apiKeyAuth, jwtAuth, middleware.RequirePermission(authz.PermDebugRead))
+		{
+			debug.GET("/db-stats", func(c *gin.Context) {
+				response.OK(c, "Database pool statistics", cfg.DB.Stats())
+			})
+		}
+	}
+
+	// Profiling (debug mode, protected). Outside debug mode profiles are
+	// taken from the metrics port with METRICS_PPROF_ENABLED.
+	if cfg.Config.App.Debug {
+		router.Any(pprofRoute, apiKeyAuth, jwtAuth, middleware.RequirePermission(authz.PermDebugRead), gin.WrapH(metrics.PprofHandler()))
 	}
 
 	return router
@@ -0,0 +1,22 @@
+package router
+
+import (
+	"github.com/TubagusAldiMY/go-template/pkg/buildinfo"
+	"github.com/TubagusAldiMY/go-template/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// Version reports the running build, so a deployment can be traced back to
+// its commit.
+//
+// @Summary Get version
+// @Description Get the version, commit and build date of the running build
+// @Tags system
+// @Produce json
+// @Param If-None-Match header string false "Returns 304 without a body while the ETag still matches"
+// @Success 200 {object} response.Response{data=buildinfo.Info}
+// @Success 304 "Not Modified"
+// @Router /version [get]
+func Version(c *gin.Context) {
+	response.OK(c, "Version retrieved successfully", buildinfo.Get())
+}
@@ -0,0 +1,89 @@
+package http
+
+import (
+	"github.com/TubagusAldiMY/go-template/internal/domain/apikey/dto"
+	"github.com/TubagusAldiMY/go-template/internal/domain/apikey/usecase"
+	"github.com/TubagusAldiMY/go-template/pkg/appcontext"
+	"github.com/TubagusAldiMY/go-template/pkg/response"
+	customValidator "github.com/TubagusAldiMY/go-template/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyHandler struct {
+	apiKeyUsecase *usecase.APIKeyUsecase
+}
+
+func NewAPIKeyHandler(apiKeyUsecase *usecase.APIKeyUsecase) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyUsecase: apiKeyUsecase,
+	}
+}
+
+// CreateAPIKey godoc
+// @Summary Create API key
+// @Description Issue an API key that authenticates as the given user, or the caller when user_id is omitted (Admin only). The key is only returned in this response; send it in the X-API-Key header.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Security ApiKey
+// @Param request body dto.CreateAPIKeyRequest true "Create API key request"
+// @Success 201 {object} response.Response{data=dto.CreateAPIKeyResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	var req dto.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.InvalidBody(c, err)
+		return
+	}
+
+	if err := customValidator.Validate(&req); err != nil {
+		validationErrors := customValidator.FormatValidationErrors(err)
+		response.UnprocessableEntity(c, "Validation failed", validationErrors)
+		return
+	}
+
+	userID, _ := appcontext.UserID(c.Request.Context())
+	key, err := h.apiKeyUsecase.Create(c.Request.Context(), userID, &req)
+	if err != nil {
+		response.FromError(c, err)
+		return
+	}
+
+	response.Created(c, "API key created successfully", key)
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke API key
+// @Description Permanently disable an API key (Admin only)
+// @Tags api-keys
+// @Produce json
+// @Security Bearer
+// @Security ApiKey
+// @Param id path string true "API key ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	keyID := c.Param("id")
+	if keyID == "" {
+		response.BadRequest(c, "API key ID is required", nil)
+		return
+	}
+
+	if err := h.apiKeyUsecase.Revoke(c.Request.Context(), keyID); err != nil {
+		response.FromError(c, err)
+		return
+	}
+
+	response.OK(c, "API key revoked successfully", nil)
+}
@@ -0,0 +1,27 @@
+package dto
+
+import "time"
+
+// Request DTOs
+
+type CreateAPIKeyRequest struct {
+	Name string `json:"name" validate:"required,min=2,max=100"`
+	// UserID is the user the key authenticates as; defaults to the caller.
+	UserID string `json:"user_id" validate:"omitempty,uuid"`
+}
+
+// Response DTOs
+
+type APIKeyResponse struct {
+	ID        string    `json:"id"`
+	UserID    string    `json:"user_id"`
+	Name      string    `json:"name"`
+	Prefix    string    `json:"prefix"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateAPIKeyResponse is the only response that carries the full key.
+type CreateAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
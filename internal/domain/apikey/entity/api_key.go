@@ -0,0 +1,38 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKey authenticates a service as the user that owns it. Only a hash of
+// the key is stored.
+type APIKey struct {
+	ID        string     `json:"id"`
+	UserID    string     `json:"user_id"`
+	Name      string     `json:"name"`
+	Prefix    string     `json:"prefix"`
+	KeyHash   string     `json:"-"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// Principal is the identity an API key authenticates as.
+type Principal struct {
+	KeyID  string
+	UserID string
+	Email  string
+	Role   string
+}
+
+func NewAPIKey(userID, name, prefix, keyHash string) *APIKey {
+	return &APIKey{
+		ID:        uuid.New().String(),
+		UserID:    userID,
+		Name:      name,
+		Prefix:    prefix,
+		KeyHash:   keyHash,
+		CreatedAt: time.Now(),
+	}
+}
@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/TubagusAldiMY/go-template/internal/domain/apikey/entity"
+)
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *entity.APIKey) error
+	// GetPrincipalByHash resolves an unrevoked key whose owner is active.
+	GetPrincipalByHash(ctx context.Context, keyHash string) (*entity.Principal, error)
+	Revoke(ctx context.Context, id string) error
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/internal/domain/apikey/entity"
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/database"
+	sharedErrors "github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/TubagusAldiMY/go-template/pkg/crypto"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// pgForeignKeyViolation is the SQLSTATE of a missing referenced row.
+const pgForeignKeyViolation = "23503"
+
+// apiKeyTable maps entity.APIKey onto the api_keys table.
+var apiKeyTable = database.Table[entity.APIKey]{
+	Name:    "api_keys",
+	Entity:  "api key",
+	Columns: []string{"id", "user_id", "name", "prefix", "key_hash", "created_at", "revoked_at"},
+	Scan: func(k *entity.APIKey) []any {
+		return []any{&k.ID, &k.UserID, &k.Name, &k.Prefix, &k.KeyHash, &k.CreatedAt, &k.RevokedAt}
+	},
+	Insert: []string{"id", "user_id", "name", "prefix", "key_hash", "created_at"},
+	Values: func(k *entity.APIKey) []any {
+		return []any{k.ID, k.UserID, k.Name, k.Prefix, k.KeyHash, k.CreatedAt}
+	},
+	NotFound: sharedErrors.ErrAPIKeyNotFound,
+	MapError: missingOwner,
+}
+
+// missingOwner reports a key created for a user that does not exist.
+func missingOwner(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation {
+		return sharedErrors.ErrUserNotFound
+	}
+	return nil
+}
+
+type PostgresAPIKeyRepository struct {
+	store  *database.Store[entity.APIKey]
+	fields *crypto.FieldCipher
+}
+
+// Option configures a PostgresAPIKeyRepository.
+type Option func(*PostgresAPIKeyRepository)
+
+// WithFieldEncryption decrypts the owner emails of principals with fields,
+// for users whose emails are encrypted at rest.
+func WithFieldEncryption(fields *crypto.FieldCipher) Option {
+	return func(r *PostgresAPIKeyRepository) {
+		r.fields = fields
+	}
+}
+
+func NewPostgresAPIKeyRepository(db *pgxpool.Pool, queryTimeout time.Duration, opts ...Option) *PostgresAPIKeyRepository {
+	r := &PostgresAPIKeyRepository{store: database.NewStore(db, queryTimeout, apiKeyTable)}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+func (r *PostgresAPIKeyRepository) Create(ctx context.Context, key *entity.APIKey) error {
+	return r.store.Insert(ctx, key)
+}
+
+func (r *PostgresAPIKeyRepository) GetPrincipalByHash(ctx context.Context, keyHash string) (*entity.Principal, error) {
+	query := `
+		SELECT k.id, u.id, u.email, u.role
+		FROM api_keys k
+		JOIN users u ON u.id = k.user_id
+		WHERE k.key_hash = $1 AND k.revoked_at IS NULL
+			AND u.deleted_at IS NULL AND u.status = 'active'
+	`
+
+	var principal entity.Principal
+	err := r.store.QueryRow(ctx, "get api key", query, []any{keyHash},
+		&principal.KeyID,
+		&principal.UserID,
+		&principal.Email,
+		&principal.Role,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, sharedErrors.ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+
+	if r.fields != nil {
+		if principal.Email, err = r.fields.Decrypt(principal.Email); err != nil {
+			return nil, fmt.Errorf("failed to decrypt email of user %s: %w", principal.UserID, err)
+		}
+	}
+
+	return &principal, nil
+}
+
+func (r *PostgresAPIKeyRepository) Revoke(ctx context.Context, id string) error {
+	query := `UPDATE api_keys SET revoked_at = CURRENT_TIMESTAMP WHERE id = $1 AND revoked_at IS NULL`
+	return r.store.ExecOne(ctx, "revoke api key", query, id)
+}
@@ -0,0 +1,132 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/TubagusAldiMY/go-template/internal/domain/apikey/dto"
+	"github.com/TubagusAldiMY/go-template/internal/domain/apikey/entity"
+	"github.com/TubagusAldiMY/go-template/internal/domain/apikey/repository"
+	"github.com/TubagusAldiMY/go-template/internal/shared/constants"
+	"github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/TubagusAldiMY/go-template/pkg/apikey"
+	"github.com/TubagusAldiMY/go-template/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// AuditLogger records administrative actions. The actor is resolved from ctx.
+type AuditLogger interface {
+	Record(ctx context.Context, action, targetID string, metadata map[string]interface{}) error
+}
+
+// TxManager runs fn atomically; repository calls made with the ctx passed to
+// fn take part in the same transaction.
+type TxManager interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// APIKeyUsecase issues, revokes and authenticates API keys.
+type APIKeyUsecase struct {
+	apiKeyRepo  repository.APIKeyRepository
+	txManager   TxManager
+	auditLogger AuditLogger
+}
+
+func NewAPIKeyUsecase(apiKeyRepo repository.APIKeyRepository, txManager TxManager, auditLogger AuditLogger) *APIKeyUsecase {
+	return &APIKeyUsecase{
+		apiKeyRepo:  apiKeyRepo,
+		txManager:   txManager,
+		auditLogger: auditLogger,
+	}
+}
+
+// Create issues a key for req.UserID, or for creatorID when unset. The
+// plaintext key is only part of this response.
+func (uc *APIKeyUsecase) Create(ctx context.Context, creatorID string, req *dto.CreateAPIKeyRequest) (*dto.CreateAPIKeyResponse, error) {
+	ownerID := req.UserID
+	if ownerID == "" {
+		ownerID = creatorID
+	}
+
+	generated, err := apikey.Generate()
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to generate api key", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+
+	key := entity.NewAPIKey(ownerID, req.Name, generated.DisplayPrefix, generated.Hash)
+
+	err = uc.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := uc.apiKeyRepo.Create(ctx, key); err != nil {
+			if errors.Is(err, errors.ErrUserNotFound) {
+				return errors.ErrUserNotFound
+			}
+			logger.FromContext(ctx).Error("failed to create api key", zap.Error(err))
+			return errors.ErrInternal
+		}
+
+		return uc.auditLogger.Record(ctx, constants.AuditActionAPIKeyCreated, key.ID, map[string]interface{}{
+			"user_id": key.UserID,
+			"name":    key.Name,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx).Info("api key created",
+		zap.String("api_key_id", key.ID),
+		zap.String("user_id", key.UserID),
+	)
+
+	return &dto.CreateAPIKeyResponse{
+		APIKeyResponse: dto.APIKeyResponse{
+			ID:        key.ID,
+			UserID:    key.UserID,
+			Name:      key.Name,
+			Prefix:    key.Prefix,
+			CreatedAt: key.CreatedAt,
+		},
+		Key: generated.Plaintext,
+	}, nil
+}
+
+// Revoke permanently disables a key.
+func (uc *APIKeyUsecase) Revoke(ctx context.Context, id string) error {
+	err := uc.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := uc.apiKeyRepo.Revoke(ctx, id); err != nil {
+			if errors.Is(err, errors.ErrAPIKeyNotFound) {
+				return errors.ErrAPIKeyNotFound
+			}
+			logger.FromContext(ctx).Error("failed to revoke api key", zap.Error(err))
+			return errors.ErrInternal
+		}
+
+		return uc.auditLogger.Record(ctx, constants.AuditActionAPIKeyRevoked, id, nil)
+	})
+	if err != nil {
+		return err
+	}
+
+	logger.FromContext(ctx).Info("api key revoked", zap.String("api_key_id", id))
+
+	return nil
+}
+
+// Authenticate resolves the user a key authenticates as. Revoked keys and
+// keys of inactive users are rejected like unknown ones.
+func (uc *APIKeyUsecase) Authenticate(ctx context.Context, key string) (*entity.Principal, error) {
+	if !apikey.LooksValid(key) {
+		return nil, errors.ErrInvalidAPIKey
+	}
+
+	principal, err := uc.apiKeyRepo.GetPrincipalByHash(ctx, apikey.Hash(key))
+	if err != nil {
+		if errors.Is(err, errors.ErrAPIKeyNotFound) {
+			return nil, errors.ErrInvalidAPIKey
+		}
+		logger.FromContext(ctx).Error("failed to look up api key", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+
+	return principal, nil
+}
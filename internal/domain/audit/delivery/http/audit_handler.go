@@ -0,0 +1,93 @@
+package http
+
+import (
+	"github.com/TubagusAldiMY/go-template/internal/domain/audit/dto"
+	"github.com/TubagusAldiMY/go-template/internal/domain/audit/usecase"
+	"github.com/TubagusAldiMY/go-template/pkg/appcontext"
+	"github.com/TubagusAldiMY/go-template/pkg/response"
+	customValidator "github.com/TubagusAldiMY/go-template/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	auditLogger *usecase.AuditLogger
+}
+
+func NewAuditHandler(auditLogger *usecase.AuditLogger) *AuditHandler {
+	return &AuditHandler{
+		auditLogger: auditLogger,
+	}
+}
+
+// ActorContext adds the client IP to the identity AuthMiddleware stored in the
+// request context, so audited use cases can attribute their actions. Under an
+// impersonation token the admin is the actor. It must run after
+// AuthMiddleware.
+func ActorContext() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		userID, _ := appcontext.UserID(ctx)
+		actor := usecase.Actor{
+			UserID:   userID,
+			ClientIP: c.ClientIP(),
+		}
+		if actorID, ok := appcontext.ActorID(ctx); ok {
+			actor.OnBehalfOfID = actor.UserID
+			actor.UserID = actorID
+		}
+
+		ctx = usecase.WithActor(ctx, actor)
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// ListAuditLogs godoc
+// @Summary List audit logs
+// @Description Get the audit trail of administrative actions, newest first (Admin only)
+// @Tags audit
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param page query int false "Page number" default(1)
+// @Param page_size query int false "Page size" default(20)
+// @Param actor_id query string false "Filter by actor user ID"
+// @Param target_id query string false "Filter by target ID"
+// @Param action query string false "Filter by action, e.g. user.deleted"
+// @Success 200 {object} response.Response{data=[]dto.AuditLogResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /audit [get]
+func (h *AuditHandler) ListAuditLogs(c *gin.Context) {
+	var req dto.ListAuditLogsRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "Invalid query parameters", err.Error())
+		return
+	}
+
+	// Set defaults
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.PageSize == 0 {
+		req.PageSize = 20
+	}
+
+	if err := customValidator.Validate(&req); err != nil {
+		validationErrors := customValidator.FormatValidationErrors(err)
+		response.UnprocessableEntity(c, "Validation failed", validationErrors)
+		return
+	}
+
+	logs, total, err := h.auditLogger.List(c.Request.Context(), &req)
+	if err != nil {
+		response.FromError(c, err)
+		return
+	}
+
+	response.Paginated(c, "Audit logs retrieved successfully", logs, req.Page, req.PageSize, total)
+}
@@ -0,0 +1,25 @@
+package dto
+
+import "time"
+
+// Request DTOs
+
+type ListAuditLogsRequest struct {
+	Page     int    `form:"page" validate:"omitempty,min=1"`
+	PageSize int    `form:"page_size" validate:"omitempty,min=1,max=100"`
+	ActorID  string `form:"actor_id" validate:"omitempty,uuid"`
+	TargetID string `form:"target_id" validate:"omitempty,uuid"`
+	Action   string `form:"action" validate:"omitempty,max=100"`
+}
+
+// Response DTOs
+
+type AuditLogResponse struct {
+	ID        string                 `json:"id"`
+	ActorID   string                 `json:"actor_id,omitempty"`
+	Action    string                 `json:"action"`
+	TargetID  string                 `json:"target_id,omitempty"`
+	ClientIP  string                 `json:"client_ip,omitempty"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+}
@@ -0,0 +1,30 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditLog records a single administrative action. Entries are never updated.
+type AuditLog struct {
+	ID        string                 `json:"id"`
+	ActorID   string                 `json:"actor_id"`
+	Action    string                 `json:"action"`
+	TargetID  string                 `json:"target_id"`
+	ClientIP  string                 `json:"client_ip"`
+	Metadata  map[string]interface{} `json:"metadata,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+func NewAuditLog(actorID, action, targetID, clientIP string, metadata map[string]interface{}) *AuditLog {
+	return &AuditLog{
+		ID:        uuid.New().String(),
+		ActorID:   actorID,
+		Action:    action,
+		TargetID:  targetID,
+		ClientIP:  clientIP,
+		Metadata:  metadata,
+		CreatedAt: time.Now(),
+	}
+}
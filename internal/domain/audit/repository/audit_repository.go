@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/TubagusAldiMY/go-template/internal/domain/audit/entity"
+)
+
+type ListParams struct {
+	Page     int
+	PageSize int
+	ActorID  string
+	TargetID string
+	Action   string
+}
+
+type AuditRepository interface {
+	Create(ctx context.Context, log *entity.AuditLog) error
+	List(ctx context.Context, params ListParams) ([]*entity.AuditLog, int64, error)
+}
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/internal/domain/audit/entity"
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/database"
+	sharedErrors "github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type PostgresAuditRepository struct {
+	db           *pgxpool.Pool
+	queryTimeout time.Duration
+}
+
+func NewPostgresAuditRepository(db *pgxpool.Pool, queryTimeout time.Duration) *PostgresAuditRepository {
+	return &PostgresAuditRepository{db: db, queryTimeout: queryTimeout}
+}
+
+func (r *PostgresAuditRepository) Create(ctx context.Context, log *entity.AuditLog) error {
+	ctx, cancel := database.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	query := `
+		INSERT INTO audit_logs (id, actor_id, action, target_id, client_ip, metadata, created_at)
+		VALUES ($1, NULLIF($2, ''), $3, NULLIF($4, ''), NULLIF($5, ''), $6, $7)
+	`
+
+	var metadata []byte
+	if len(log.Metadata) > 0 {
+		var err error
+		if metadata, err = json.Marshal(log.Metadata); err != nil {
+			return fmt.Errorf("failed to encode audit metadata: %w", err)
+		}
+	}
+
+	_, err := database.Conn(ctx, r.db).Exec(ctx, query,
+		log.ID,
+		log.ActorID,
+		log.Action,
+		log.TargetID,
+		log.ClientIP,
+		metadata,
+		log.CreatedAt,
+	)
+
+	if err != nil {
+		if ctxErr := sharedErrors.ContextError(err); ctxErr != nil {
+			return ctxErr
+		}
+		return fmt.Errorf("failed to create audit log: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresAuditRepository) List(ctx context.Context, params ListParams) ([]*entity.AuditLog, int64, error) {
+	ctx, cancel := database.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	var conditions []string
+	var args []interface{}
+
+	addFilter := func(column, value string) {
+		if value == "" {
+			return
+		}
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+	addFilter("actor_id", params.ActorID)
+	addFilter("target_id", params.TargetID)
+	addFilter("action", params.Action)
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	conn := database.Conn(ctx, r.db)
+
+	var total int64
+	countQuery := "SELECT COUNT(*) FROM audit_logs " + where
+	if err := conn.QueryRow(ctx, countQuery, args...).Scan(&total); err != nil {
+		if ctxErr := sharedErrors.ContextError(err); ctxErr != nil {
+			return nil, 0, ctxErr
+		}
+		return nil, 0, fmt.Errorf("failed to count audit logs: %w", err)
+	}
+
+	offset := (params.Page - 1) * params.PageSize
+	query := fmt.Sprintf(`
+		SELECT id, COALESCE(actor_id, ''), action, COALESCE(target_id, ''), COALESCE(client_ip, ''), metadata, created_at
+		FROM audit_logs
+		%s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d OFFSET $%d
+	`, where, len(args)+1, len(args)+2)
+	args = append(args, params.PageSize, offset)
+
+	rows, err := conn.Query(ctx, query, args...)
+	if err != nil {
+		if ctxErr := sharedErrors.ContextError(err); ctxErr != nil {
+			return nil, 0, ctxErr
+		}
+		return nil, 0, fmt.Errorf("failed to list audit logs: %w", err)
+	}
+	defer rows.Close()
+
+	var logs []*entity.AuditLog
+	for rows.Next() {
+		log := &entity.AuditLog{}
+		var metadata []byte
+		if err := rows.Scan(
+			&log.ID,
+			&log.ActorID,
+			&log.Action,
+			&log.TargetID,
+			&log.ClientIP,
+			&metadata,
+			&log.CreatedAt,
+		); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan audit log: %w", err)
+		}
+		if len(metadata) > 0 {
+			if err := json.Unmarshal(metadata, &log.Metadata); err != nil {
+				return nil, 0, fmt.Errorf("failed to decode audit metadata: %w", err)
+			}
+		}
+		logs = append(logs, log)
+	}
+
+	if err := rows.Err(); err != nil {
+		if ctxErr := sharedErrors.ContextError(err); ctxErr != nil {
+			return nil, 0, ctxErr
+		}
+		return nil, 0, fmt.Errorf("failed to iterate audit logs: %w", err)
+	}
+
+	return logs, total, nil
+}
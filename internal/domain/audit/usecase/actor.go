@@ -0,0 +1,25 @@
+package usecase
+
+import "context"
+
+type actorKey struct{}
+
+// Actor identifies who performed an action and from where.
+type Actor struct {
+	UserID   string
+	ClientIP string
+	// OnBehalfOfID is the impersonated user when UserID acts through an
+	// impersonation token.
+	OnBehalfOfID string
+}
+
+// WithActor returns a copy of ctx carrying actor.
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor stored by WithActor, or the zero Actor.
+func ActorFromContext(ctx context.Context) Actor {
+	actor, _ := ctx.Value(actorKey{}).(Actor)
+	return actor
+}
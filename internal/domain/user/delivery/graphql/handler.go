@@ -0,0 +1,48 @@
+package graphql
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	gql "github.com/graph-gophers/graphql-go"
+
+	"github.com/TubagusAldiMY/go-template/pkg/response"
+)
+
+// Request is a GraphQL request as sent over HTTP POST.
+type Request struct {
+	Query         string                 `json:"query" binding:"required"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+type Handler struct {
+	schema *gql.Schema
+}
+
+func NewHandler(schema *gql.Schema) *Handler {
+	return &Handler{schema: schema}
+}
+
+// Serve godoc
+// @Summary Execute a GraphQL query
+// @Description Execute a query or mutation against the user schema: me, user(id) and users(filter, page, pageSize) queries and updateProfile and changePassword mutations. users, and user for anyone but the caller, require the user:read permission. Query errors are answered with 200 and an errors list whose extensions carry the same codes as REST errors.
+// @Tags graphql
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body Request true "GraphQL request"
+// @Success 200 {object} object "GraphQL response with data and errors"
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Router /graphql [post]
+func (h *Handler) Serve(c *gin.Context) {
+	var req Request
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.InvalidBody(c, err)
+		return
+	}
+
+	result := h.schema.Exec(c.Request.Context(), req.Query, req.OperationName, req.Variables)
+	c.JSON(http.StatusOK, result)
+}
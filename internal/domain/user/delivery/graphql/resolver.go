@@ -0,0 +1,275 @@
+package graphql
+
+import (
+	"context"
+	"net/http"
+
+	gql "github.com/graph-gophers/graphql-go"
+	"go.uber.org/zap"
+
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/dto"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/usecase"
+	"github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/TubagusAldiMY/go-template/pkg/appcontext"
+	"github.com/TubagusAldiMY/go-template/pkg/authz"
+	"github.com/TubagusAldiMY/go-template/pkg/logger"
+	customValidator "github.com/TubagusAldiMY/go-template/pkg/validator"
+)
+
+// Resolver resolves the Query and Mutation root fields.
+type Resolver struct {
+	userUsecase *usecase.UserUsecase
+}
+
+func (r *Resolver) Me(ctx context.Context) (*userResolver, error) {
+	userID, ok := appcontext.UserID(ctx)
+	if !ok {
+		return nil, resolverError(ctx, errors.ErrUnauthorized)
+	}
+
+	user, err := r.userUsecase.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, resolverError(ctx, err)
+	}
+	return &userResolver{user}, nil
+}
+
+func (r *Resolver) User(ctx context.Context, args struct{ ID gql.ID }) (*userResolver, error) {
+	userID, ok := appcontext.UserID(ctx)
+	if !ok {
+		return nil, resolverError(ctx, errors.ErrUnauthorized)
+	}
+	if string(args.ID) != userID {
+		if err := requirePermission(ctx, authz.PermUserRead); err != nil {
+			return nil, err
+		}
+	}
+
+	user, err := r.userUsecase.GetProfile(ctx, string(args.ID))
+	if err != nil {
+		return nil, resolverError(ctx, err)
+	}
+	return &userResolver{user}, nil
+}
+
+type userFilter struct {
+	Search     *string
+	SearchMode *string
+	Role       *string
+	Status     *string
+	SortBy     *string
+	SortOrder  *string
+}
+
+type usersArgs struct {
+	Filter   *userFilter
+	Page     int32
+	PageSize int32
+}
+
+func (r *Resolver) Users(ctx context.Context, args usersArgs) (*userPageResolver, error) {
+	if err := requirePermission(ctx, authz.PermUserRead); err != nil {
+		return nil, err
+	}
+
+	req := dto.ListUsersRequest{
+		Page:     int(args.Page),
+		PageSize: int(args.PageSize),
+	}
+	if f := args.Filter; f != nil {
+		req.Search = deref(f.Search)
+		req.SearchMode = deref(f.SearchMode)
+		req.Role = deref(f.Role)
+		req.Status = deref(f.Status)
+		req.SortBy = deref(f.SortBy)
+		req.SortOrder = deref(f.SortOrder)
+	}
+	// Same defaults as the REST query for explicit zeros
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.PageSize == 0 {
+		req.PageSize = 20
+	}
+	if err := customValidator.Validate(&req); err != nil {
+		return nil, validationError(customValidator.FormatValidationErrors(err))
+	}
+
+	users, total, err := r.userUsecase.ListUsers(ctx, &req)
+	if err != nil {
+		return nil, resolverError(ctx, err)
+	}
+
+	items := make([]*userResolver, len(users))
+	for i, user := range users {
+		items[i] = &userResolver{user}
+	}
+	return &userPageResolver{items: items, total: total, page: int32(req.Page), pageSize: int32(req.PageSize)}, nil
+}
+
+type updateProfileInput struct {
+	FullName *string
+	Email    *string
+	Username *string
+	Timezone *string
+	Locale   *string
+}
+
+func (r *Resolver) UpdateProfile(ctx context.Context, args struct{ Input updateProfileInput }) (*userResolver, error) {
+	userID, ok := appcontext.UserID(ctx)
+	if !ok {
+		return nil, resolverError(ctx, errors.ErrUnauthorized)
+	}
+
+	req := dto.UpdateProfileRequest{
+		FullName: deref(args.Input.FullName),
+		Email:    deref(args.Input.Email),
+		Username: deref(args.Input.Username),
+		Timezone: deref(args.Input.Timezone),
+		Locale:   deref(args.Input.Locale),
+	}
+	if err := customValidator.Validate(&req); err != nil {
+		return nil, validationError(customValidator.FormatValidationErrors(err))
+	}
+
+	user, err := r.userUsecase.UpdateProfile(ctx, userID, &req)
+	if err != nil {
+		return nil, resolverError(ctx, err)
+	}
+	return &userResolver{user}, nil
+}
+
+type changePasswordInput struct {
+	OldPassword string
+	NewPassword string
+}
+
+func (r *Resolver) ChangePassword(ctx context.Context, args struct{ Input changePasswordInput }) (bool, error) {
+	userID, ok := appcontext.UserID(ctx)
+	if !ok {
+		return false, resolverError(ctx, errors.ErrUnauthorized)
+	}
+
+	req := dto.ChangePasswordRequest{
+		OldPassword: args.Input.OldPassword,
+		NewPassword: args.Input.NewPassword,
+	}
+	if err := customValidator.Validate(&req); err != nil {
+		return false, validationError(customValidator.FormatValidationErrors(err))
+	}
+
+	if err := r.userUsecase.ChangePassword(ctx, userID, &req); err != nil {
+		return false, resolverError(ctx, err)
+	}
+	return true, nil
+}
+
+// requirePermission is the field-level counterpart of
+// middleware.RequirePermission: the endpoint is open to every authenticated
+// user, so fields restricted to some roles check the caller's role
+// themselves.
+func requirePermission(ctx context.Context, perm string) error {
+	role, ok := appcontext.Role(ctx)
+	if !ok {
+		return resolverError(ctx, errors.ErrUnauthorized)
+	}
+	if !authz.HasPermission(role, perm) {
+		return resolverError(ctx, errors.ErrForbidden.WithMessage("Insufficient permissions"))
+	}
+	return nil
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+type userResolver struct {
+	user *dto.UserResponse
+}
+
+func (u *userResolver) ID() gql.ID         { return gql.ID(u.user.ID) }
+func (u *userResolver) Email() string      { return u.user.Email }
+func (u *userResolver) Username() string   { return u.user.Username }
+func (u *userResolver) FullName() string   { return u.user.FullName }
+func (u *userResolver) Role() string       { return u.user.Role }
+func (u *userResolver) Status() string     { return u.user.Status }
+func (u *userResolver) MfaEnabled() bool   { return u.user.MFAEnabled }
+func (u *userResolver) AvatarUrl() *string { return optional(u.user.AvatarURL) }
+func (u *userResolver) Timezone() *string  { return optional(u.user.Timezone) }
+func (u *userResolver) Locale() *string    { return optional(u.user.Locale) }
+func (u *userResolver) CreatedAt() gql.Time {
+	return gql.Time{Time: u.user.CreatedAt}
+}
+func (u *userResolver) UpdatedAt() gql.Time {
+	return gql.Time{Time: u.user.UpdatedAt}
+}
+func (u *userResolver) LastLoginAt() *gql.Time {
+	if u.user.LastLoginAt == nil {
+		return nil
+	}
+	return &gql.Time{Time: *u.user.LastLoginAt}
+}
+
+// optional maps the empty strings UserResponse omits from JSON to null.
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+type userPageResolver struct {
+	items    []*userResolver
+	total    int64
+	page     int32
+	pageSize int32
+}
+
+func (p *userPageResolver) Items() []*userResolver { return p.items }
+func (p *userPageResolver) Total() int32           { return int32(p.total) }
+func (p *userPageResolver) Page() int32            { return p.page }
+func (p *userPageResolver) PageSize() int32        { return p.pageSize }
+
+// queryError is a resolver error as sent to clients: the message and code of
+// the AppError it was made from, with the code and any field errors under
+// extensions.
+type queryError struct {
+	message    string
+	extensions map[string]interface{}
+}
+
+func (e *queryError) Error() string                      { return e.message }
+func (e *queryError) Extensions() map[string]interface{} { return e.extensions }
+
+// resolverError converts err the way response.FromError does for REST:
+// errors with a client status keep their message and code, anything else is
+// logged and reported as an internal error.
+func resolverError(ctx context.Context, err error) error {
+	if appErr := errors.StatusError(err); appErr != nil && appErr.Status != http.StatusInternalServerError {
+		return &queryError{
+			message:    appErr.Message,
+			extensions: map[string]interface{}{"code": errors.Code(err)},
+		}
+	}
+
+	logger.FromContext(ctx).Error("graphql resolver failed", zap.Error(err))
+	return &queryError{
+		message:    "Internal server error",
+		extensions: map[string]interface{}{"code": errors.CodeInternal},
+	}
+}
+
+// validationError reports input that failed validation, with the message per
+// field that REST sends in errors.
+func validationError(fields map[string]string) error {
+	return &queryError{
+		message: "Validation failed",
+		extensions: map[string]interface{}{
+			"code":   errors.CodeInvalidInput,
+			"fields": fields,
+		},
+	}
+}
@@ -0,0 +1,94 @@
+// Package graphql serves the user domain over GraphQL, alongside the REST
+// handlers in delivery/http. Resolvers call the same UserUsecase, so both
+// APIs share validation, authorization and error codes.
+package graphql
+
+import (
+	gql "github.com/graph-gophers/graphql-go"
+
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/usecase"
+)
+
+// maxDepth bounds how deeply queries may nest. The schema itself is no
+// deeper than three levels; the limit only rejects abusive queries.
+const maxDepth = 10
+
+const schemaString = `
+schema {
+	query: Query
+	mutation: Mutation
+}
+
+scalar Time
+
+type Query {
+	# The authenticated user.
+	me: User!
+	# A user by ID. Users may fetch themselves; anyone else requires the
+	# user:read permission.
+	user(id: ID!): User!
+	# Users matching filter, one page at a time. Requires the user:read
+	# permission (admins under the default policy).
+	users(filter: UserFilter, page: Int = 1, pageSize: Int = 20): UserPage!
+}
+
+type Mutation {
+	# Changes the fields of the authenticated user's profile that are set.
+	updateProfile(input: UpdateProfileInput!): User!
+	# Changes the authenticated user's password.
+	changePassword(input: ChangePasswordInput!): Boolean!
+}
+
+type User {
+	id: ID!
+	email: String!
+	username: String!
+	fullName: String!
+	role: String!
+	status: String!
+	mfaEnabled: Boolean!
+	avatarUrl: String
+	timezone: String
+	locale: String
+	lastLoginAt: Time
+	createdAt: Time!
+	updatedAt: Time!
+}
+
+type UserPage {
+	items: [User!]!
+	total: Int!
+	page: Int!
+	pageSize: Int!
+}
+
+input UserFilter {
+	search: String
+	# exact (default) or fuzzy.
+	searchMode: String
+	role: String
+	status: String
+	# email, username, created_at, updated_at or status.
+	sortBy: String
+	# asc or desc.
+	sortOrder: String
+}
+
+input UpdateProfileInput {
+	fullName: String
+	email: String
+	username: String
+	timezone: String
+	locale: String
+}
+
+input ChangePasswordInput {
+	oldPassword: String!
+	newPassword: String!
+}
+`
+
+// NewSchema returns the executable user schema resolved by userUsecase.
+func NewSchema(userUsecase *usecase.UserUsecase) *gql.Schema {
+	return gql.MustParseSchema(schemaString, &Resolver{userUsecase: userUsecase}, gql.MaxDepth(maxDepth))
+}
@@ -0,0 +1,207 @@
+// Package grpc serves UserService, defined in api/proto/user/v1, alongside
+// the REST handlers in delivery/http. Both call the same UserUsecase.
+package grpc
+
+import (
+	"context"
+	"net"
+	"sort"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	userv1 "github.com/TubagusAldiMY/go-template/api/proto/user/v1"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/dto"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/usecase"
+	"github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/TubagusAldiMY/go-template/pkg/appcontext"
+	"github.com/TubagusAldiMY/go-template/pkg/authz"
+	customValidator "github.com/TubagusAldiMY/go-template/pkg/validator"
+)
+
+// PublicMethods are served without an access token.
+var PublicMethods = []string{
+	userv1.UserService_Register_FullMethodName,
+	userv1.UserService_Login_FullMethodName,
+}
+
+type UserServer struct {
+	userv1.UnimplementedUserServiceServer
+	userUsecase *usecase.UserUsecase
+}
+
+func NewUserServer(userUsecase *usecase.UserUsecase) *UserServer {
+	return &UserServer{userUsecase: userUsecase}
+}
+
+func (s *UserServer) GetProfile(ctx context.Context, _ *userv1.GetProfileRequest) (*userv1.User, error) {
+	userID, ok := appcontext.UserID(ctx)
+	if !ok {
+		return nil, errors.ErrUnauthorized
+	}
+
+	user, err := s.userUsecase.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return toProto(user), nil
+}
+
+func (s *UserServer) ListUsers(ctx context.Context, in *userv1.ListUsersRequest) (*userv1.ListUsersResponse, error) {
+	role, ok := appcontext.Role(ctx)
+	if !ok {
+		return nil, errors.ErrUnauthorized
+	}
+	if !authz.HasPermission(role, authz.PermUserRead) {
+		return nil, errors.ErrForbidden.WithMessage("Insufficient permissions")
+	}
+
+	req := dto.ListUsersRequest{
+		Page:       int(in.GetPage()),
+		PageSize:   int(in.GetPageSize()),
+		Search:     in.GetSearch(),
+		SearchMode: in.GetSearchMode(),
+		Role:       in.GetRole(),
+		Status:     in.GetStatus(),
+		SortBy:     in.GetSortBy(),
+		SortOrder:  in.GetSortOrder(),
+	}
+	// Set defaults
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.PageSize == 0 {
+		req.PageSize = 20
+	}
+	if err := customValidator.Validate(&req); err != nil {
+		return nil, validationError(err)
+	}
+
+	users, total, err := s.userUsecase.ListUsers(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &userv1.ListUsersResponse{
+		Users:    make([]*userv1.User, len(users)),
+		Total:    total,
+		Page:     int32(req.Page),
+		PageSize: int32(req.PageSize),
+	}
+	for i, user := range users {
+		resp.Users[i] = toProto(user)
+	}
+	return resp, nil
+}
+
+func (s *UserServer) Register(ctx context.Context, in *userv1.RegisterRequest) (*userv1.User, error) {
+	req := dto.RegisterRequest{
+		Email:    in.GetEmail(),
+		Username: in.GetUsername(),
+		Password: in.GetPassword(),
+		FullName: in.GetFullName(),
+	}
+	if err := customValidator.Validate(&req); err != nil {
+		return nil, validationError(err)
+	}
+
+	user, err := s.userUsecase.Register(usecase.WithClient(ctx, clientFromContext(ctx)), &req)
+	if err != nil {
+		return nil, err
+	}
+	return toProto(user), nil
+}
+
+func (s *UserServer) Login(ctx context.Context, in *userv1.LoginRequest) (*userv1.LoginResponse, error) {
+	req := dto.LoginRequest{
+		Identifier: in.GetIdentifier(),
+		Password:   in.GetPassword(),
+		RememberMe: in.GetRememberMe(),
+	}
+	if err := customValidator.Validate(&req); err != nil {
+		return nil, validationError(err)
+	}
+
+	loginResp, err := s.userUsecase.Login(usecase.WithClient(ctx, clientFromContext(ctx)), &req)
+	if err != nil {
+		if errors.Is(err, errors.ErrUnauthorized) {
+			return nil, errors.ErrUnauthorized.WithMessage("Account is not active")
+		}
+		return nil, err
+	}
+
+	resp := &userv1.LoginResponse{
+		AccessToken:      loginResp.AccessToken,
+		RefreshToken:     loginResp.RefreshToken,
+		TokenType:        loginResp.TokenType,
+		ExpiresIn:        loginResp.ExpiresIn,
+		RefreshExpiresIn: loginResp.RefreshExpiresIn,
+		MfaRequired:      loginResp.MFARequired,
+		MfaToken:         loginResp.MFAToken,
+	}
+	if loginResp.User != nil {
+		resp.User = toProto(loginResp.User)
+	}
+	return resp, nil
+}
+
+// clientFromContext describes the caller for the session a login starts:
+// the user-agent metadata gRPC clients send and the peer address.
+func clientFromContext(ctx context.Context) usecase.Client {
+	var client usecase.Client
+	if values := metadata.ValueFromIncomingContext(ctx, "user-agent"); len(values) > 0 {
+		client.UserAgent = values[0]
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		client.IPAddress = p.Addr.String()
+		if host, _, err := net.SplitHostPort(client.IPAddress); err == nil {
+			client.IPAddress = host
+		}
+	}
+	return client
+}
+
+// validationError reports input that failed validation as InvalidArgument,
+// with a field violation per failing field, named as in the REST API.
+func validationError(err error) error {
+	fields := customValidator.FormatValidationErrors(err)
+	violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(fields))
+	for field, description := range fields {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{Field: field, Description: description})
+	}
+	sort.Slice(violations, func(i, j int) bool { return violations[i].Field < violations[j].Field })
+
+	st, detailErr := status.New(codes.InvalidArgument, "Validation failed").WithDetails(
+		&errdetails.ErrorInfo{Reason: errors.CodeInvalidInput},
+		&errdetails.BadRequest{FieldViolations: violations},
+	)
+	if detailErr != nil {
+		return errors.ErrInvalidInput.WithMessage("Validation failed")
+	}
+	return st.Err()
+}
+
+func toProto(user *dto.UserResponse) *userv1.User {
+	out := &userv1.User{
+		Id:         user.ID,
+		Email:      user.Email,
+		Username:   user.Username,
+		FullName:   user.FullName,
+		Role:       user.Role,
+		Status:     user.Status,
+		MfaEnabled: user.MFAEnabled,
+		AvatarUrl:  user.AvatarURL,
+		Timezone:   user.Timezone,
+		Locale:     user.Locale,
+		CreatedAt:  timestamppb.New(user.CreatedAt),
+		UpdatedAt:  timestamppb.New(user.UpdatedAt),
+	}
+	if user.LastLoginAt != nil {
+		out.LastLoginAt = timestamppb.New(*user.LastLoginAt)
+	}
+	return out
+}
@@ -0,0 +1,139 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/dto"
+	"github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/TubagusAldiMY/go-template/pkg/appcontext"
+	"github.com/TubagusAldiMY/go-template/pkg/response"
+	customValidator "github.com/TubagusAldiMY/go-template/pkg/validator"
+	"github.com/gin-gonic/gin"
+)
+
+// LoginTOTP godoc
+// @Summary Complete login with a TOTP code
+// @Description Exchange the mfa_token returned by /auth/login and a code from the authenticator app for tokens
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.LoginTOTPRequest true "TOTP login request"
+// @Param token_delivery query string false "Token delivery" Enums(json, cookie, both)
+// @Success 200 {object} response.Response{data=dto.LoginResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 503 {object} response.Response
+// @Router /auth/login/totp [post]
+func (h *UserHandler) LoginTOTP(c *gin.Context) {
+	delivery, ok := h.tokenDelivery(c)
+	if !ok {
+		response.BadRequest(c, "token_delivery must be json, cookie or both", nil)
+		return
+	}
+
+	var req dto.LoginTOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.InvalidBody(c, err)
+		return
+	}
+
+	if err := customValidator.Validate(&req); err != nil {
+		validationErrors := customValidator.FormatValidationErrors(err)
+		response.UnprocessableEntity(c, "Validation failed", validationErrors)
+		return
+	}
+
+	loginResp, err := h.userUsecase.LoginTOTP(clientContext(c), &req)
+	if err != nil {
+		if errors.Is(err, errors.ErrUnauthorized) {
+			respondError(c, http.StatusUnauthorized, "Account is not active", err)
+			return
+		}
+		response.FromError(c, err)
+		return
+	}
+
+	h.deliverTokens(c, delivery, &loginResp.AccessToken, &loginResp.RefreshToken, loginResp.ExpiresIn, loginResp.RefreshExpiresIn)
+	response.OK(c, "Login successful", loginResp)
+}
+
+// EnableTOTP godoc
+// @Summary Start TOTP enrollment
+// @Description Generate a TOTP secret for the authenticated user. MFA is enabled once a code is confirmed at /users/mfa/totp/verify.
+// @Tags users
+// @Produce json
+// @Security Bearer
+// @Success 200 {object} response.Response{data=dto.TOTPEnrollmentResponse}
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 503 {object} response.Response
+// @Router /users/mfa/totp/enable [post]
+func (h *UserHandler) EnableTOTP(c *gin.Context) {
+	userID, ok := appcontext.UserID(c.Request.Context())
+	if !ok {
+		response.FromError(c, errors.ErrUnauthorized)
+		return
+	}
+
+	enrollment, err := h.userUsecase.EnableTOTP(c.Request.Context(), userID)
+	if err != nil {
+		response.FromError(c, err)
+		return
+	}
+
+	response.OK(c, "TOTP enrollment started", enrollment)
+}
+
+// VerifyTOTP godoc
+// @Summary Confirm TOTP enrollment
+// @Description Confirm the enrollment with a code from the authenticator app and enable MFA
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body dto.VerifyTOTPRequest true "TOTP code"
+// @Success 200 {object} response.Response{data=dto.UserResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 503 {object} response.Response
+// @Router /users/mfa/totp/verify [post]
+func (h *UserHandler) VerifyTOTP(c *gin.Context) {
+	userID, ok := appcontext.UserID(c.Request.Context())
+	if !ok {
+		response.FromError(c, errors.ErrUnauthorized)
+		return
+	}
+
+	var req dto.VerifyTOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.InvalidBody(c, err)
+		return
+	}
+
+	if err := customValidator.Validate(&req); err != nil {
+		validationErrors := customValidator.FormatValidationErrors(err)
+		response.UnprocessableEntity(c, "Validation failed", validationErrors)
+		return
+	}
+
+	user, err := h.userUsecase.VerifyTOTP(c.Request.Context(), userID, req.Code)
+	if err != nil {
+		if errors.Is(err, errors.ErrInvalidMFACode) {
+			// The user is signed in; a wrong code must not read as a lost session.
+			respondError(c, http.StatusBadRequest, "Invalid authentication code", err)
+			return
+		}
+		response.FromError(c, err)
+		return
+	}
+
+	response.OK(c, "Multi-factor authentication enabled", user)
+}
@@ -0,0 +1,141 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/dto"
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/notification"
+	"github.com/TubagusAldiMY/go-template/internal/shared/constants"
+	"github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/TubagusAldiMY/go-template/pkg/appcontext"
+	"github.com/TubagusAldiMY/go-template/pkg/logger"
+	"github.com/TubagusAldiMY/go-template/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+// resubscribeDelay is the pause before ForwardUserEvents subscribes again
+// after losing its subscription.
+const resubscribeDelay = 5 * time.Second
+
+// Notification is the message pushed to a user's WebSocket connections.
+type Notification struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+type NotificationsHandler struct {
+	hub        *notification.Hub
+	subscriber EventSubscriber
+	upgrader   websocket.Upgrader
+}
+
+// NewNotificationsHandler returns a handler registering WebSocket
+// connections with hub. checkOrigin decides which browser origins may
+// connect; subscriber may be nil, in which case ForwardUserEvents does
+// nothing.
+func NewNotificationsHandler(hub *notification.Hub, subscriber EventSubscriber, checkOrigin func(r *http.Request) bool) *NotificationsHandler {
+	return &NotificationsHandler{
+		hub:        hub,
+		subscriber: subscriber,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			CheckOrigin:     checkOrigin,
+		},
+	}
+}
+
+// Connect godoc
+// @Summary Open notification channel
+// @Description Upgrade to a WebSocket that receives the caller's notifications as JSON messages with a type and data. Browsers cannot set headers on WebSocket requests, so the access token may be passed as the access_token query parameter instead. The server pings every 54 seconds and drops connections that do not answer within 60.
+// @Tags users
+// @Security Bearer
+// @Param access_token query string false "Access token, when no Authorization header is sent"
+// @Success 101 {string} string "Switching Protocols"
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Router /ws [get]
+func (h *NotificationsHandler) Connect(c *gin.Context) {
+	userID, ok := appcontext.UserID(c.Request.Context())
+	if !ok {
+		response.FromError(c, errors.ErrUnauthorized)
+		return
+	}
+
+	// Upgrade writes the error response itself when the handshake fails.
+	ws, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		logger.FromContext(c.Request.Context()).Debug("websocket upgrade failed", zap.Error(err))
+		return
+	}
+
+	conn := notification.NewConn(ws)
+	h.hub.Register(userID, conn)
+	defer h.hub.Unregister(userID, conn)
+
+	if err := conn.Run(c.Request.Context()); err != nil {
+		logger.FromContext(c.Request.Context()).Debug("websocket connection closed", zap.Error(err))
+	}
+}
+
+// ForwardUserEvents pushes user updated and deleted events to the affected
+// user's connections until ctx is done. Every instance runs its own
+// subscription, so each delivers to the connections it holds.
+func (h *NotificationsHandler) ForwardUserEvents(ctx context.Context) {
+	if h.subscriber == nil {
+		return
+	}
+
+	for {
+		if err := h.forwardUserEvents(ctx); err != nil {
+			logger.Warn("user event forwarding interrupted", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(resubscribeDelay):
+		}
+	}
+}
+
+func (h *NotificationsHandler) forwardUserEvents(ctx context.Context) error {
+	sub, err := h.subscriber.Subscribe(ctx, constants.ExchangeUserEvents,
+		constants.RoutingKeyUserUpdated,
+		constants.RoutingKeyUserDeleted,
+	)
+	if err != nil {
+		return err
+	}
+	defer sub.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-sub.Messages():
+			if !ok {
+				return fmt.Errorf("subscription closed by broker")
+			}
+
+			var event dto.UserEvent
+			if err := json.Unmarshal(msg.Body, &event); err != nil || event.UserID == "" {
+				logger.Warn("skipping malformed user event", zap.String("routing_key", msg.RoutingKey))
+				continue
+			}
+
+			payload, err := json.Marshal(Notification{Type: msg.RoutingKey, Data: msg.Body})
+			if err != nil {
+				logger.Error("failed to encode notification", zap.Error(err))
+				continue
+			}
+			h.hub.SendToUser(event.UserID, payload)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package http
+
+import (
+	"context"
+
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/usecase"
+	"github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/TubagusAldiMY/go-template/pkg/appcontext"
+	"github.com/TubagusAldiMY/go-template/pkg/response"
+	"github.com/gin-gonic/gin"
+)
+
+// clientContext returns the request context carrying the client's
+// User-Agent and IP address, which logins record on the session they start
+// and registrations are limited by.
+func clientContext(c *gin.Context) context.Context {
+	return usecase.WithClient(c.Request.Context(), usecase.Client{
+		UserAgent: c.Request.UserAgent(),
+		IPAddress: c.ClientIP(),
+	})
+}
+
+// ListSessions godoc
+// @Summary List sessions
+// @Description Get the authenticated user's active login sessions, most recently used first
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param If-None-Match header string false "Returns 304 without a body while the ETag still matches"
+// @Success 200 {object} response.Response{data=[]dto.SessionResponse}
+// @Success 304 "Not Modified"
+// @Failure 401 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /users/sessions [get]
+func (h *UserHandler) ListSessions(c *gin.Context) {
+	userID, ok := appcontext.UserID(c.Request.Context())
+	if !ok {
+		response.FromError(c, errors.ErrUnauthorized)
+		return
+	}
+
+	sessions, err := h.userUsecase.ListSessions(c.Request.Context(), userID)
+	if err != nil {
+		response.FromError(c, err)
+		return
+	}
+
+	response.OK(c, "Sessions retrieved successfully", sessions)
+}
+
+// RevokeSession godoc
+// @Summary Revoke session
+// @Description Log the authenticated user out of one session, e.g. on a lost device. Its refresh token stops working; access tokens it issued stay valid until they expire.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "Session ID"
+// @Success 200 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /users/sessions/{id} [delete]
+func (h *UserHandler) RevokeSession(c *gin.Context) {
+	userID, ok := appcontext.UserID(c.Request.Context())
+	if !ok {
+		response.FromError(c, errors.ErrUnauthorized)
+		return
+	}
+
+	if err := h.userUsecase.RevokeSession(c.Request.Context(), userID, c.Param("id")); err != nil {
+		response.FromError(c, err)
+		return
+	}
+
+	response.OK(c, "Session revoked successfully", nil)
+}
@@ -0,0 +1,88 @@
+package http
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/internal/shared/constants"
+	"github.com/gin-gonic/gin"
+)
+
+// refreshCookiePath limits the refresh token cookie to the auth endpoints,
+// the only ones that read it.
+const refreshCookiePath = "/api/v1/auth"
+
+// TokenCookies configures delivering tokens as HttpOnly cookies, which
+// scripts cannot read, instead of in the response body.
+type TokenCookies struct {
+	// Delivery is the default delivery of login and refresh responses, one of
+	// the constants.TokenDelivery* values.
+	Delivery string
+	Domain   string
+	Secure   bool
+	SameSite http.SameSite
+}
+
+type HandlerOption func(*UserHandler)
+
+// WithTokenCookies sets how tokens are delivered as cookies. Without it,
+// tokens are delivered in the body unless the client asks for cookies, which
+// are then Secure and SameSite=Strict.
+func WithTokenCookies(cookies TokenCookies) HandlerOption {
+	return func(h *UserHandler) {
+		h.tokenCookies = cookies
+	}
+}
+
+// tokenDelivery returns the delivery requested with the token_delivery query
+// parameter, or the configured default.
+func (h *UserHandler) tokenDelivery(c *gin.Context) (string, bool) {
+	delivery := c.Query(constants.QueryParamTokenDelivery)
+	switch delivery {
+	case "":
+		return h.tokenCookies.Delivery, true
+	case constants.TokenDeliveryJSON, constants.TokenDeliveryCookie, constants.TokenDeliveryBoth:
+		return delivery, true
+	}
+	return "", false
+}
+
+// deliverTokens sets the token cookies unless delivery is json, and removes
+// the tokens from the body when it is cookie. The max ages are in seconds.
+func (h *UserHandler) deliverTokens(c *gin.Context, delivery string, accessToken, refreshToken *string, accessMaxAge, refreshMaxAge int64) {
+	if delivery == constants.TokenDeliveryJSON {
+		return
+	}
+
+	h.setCookie(c, constants.CookieAccessToken, *accessToken, "/", time.Duration(accessMaxAge)*time.Second)
+	h.setCookie(c, constants.CookieRefreshToken, *refreshToken, refreshCookiePath, time.Duration(refreshMaxAge)*time.Second)
+
+	if delivery == constants.TokenDeliveryCookie {
+		*accessToken = ""
+		*refreshToken = ""
+	}
+}
+
+// clearTokenCookies expires both token cookies.
+func (h *UserHandler) clearTokenCookies(c *gin.Context) {
+	h.setCookie(c, constants.CookieAccessToken, "", "/", -1)
+	h.setCookie(c, constants.CookieRefreshToken, "", refreshCookiePath, -1)
+}
+
+// setCookie sets an HttpOnly cookie; a negative maxAge deletes it.
+func (h *UserHandler) setCookie(c *gin.Context, name, value, path string, maxAge time.Duration) {
+	cookie := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     path,
+		Domain:   h.tokenCookies.Domain,
+		MaxAge:   int(maxAge.Seconds()),
+		Secure:   h.tokenCookies.Secure,
+		HttpOnly: true,
+		SameSite: h.tokenCookies.SameSite,
+	}
+	if maxAge < 0 {
+		cookie.MaxAge = -1
+	}
+	http.SetCookie(c.Writer, cookie)
+}
@@ -0,0 +1,118 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/messaging"
+	"github.com/TubagusAldiMY/go-template/internal/shared/constants"
+	"github.com/TubagusAldiMY/go-template/pkg/logger"
+	"github.com/TubagusAldiMY/go-template/pkg/response"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const sseHeartbeatInterval = 30 * time.Second
+
+// EventSubscriber opens a live subscription to an exchange.
+type EventSubscriber interface {
+	Subscribe(ctx context.Context, exchange string, routingKeys ...string) (*messaging.Subscription, error)
+}
+
+type UserEventsHandler struct {
+	subscriber EventSubscriber
+}
+
+// NewUserEventsHandler returns a handler streaming events from subscriber. A
+// nil subscriber, as when RabbitMQ is unavailable, makes the stream return 503.
+func NewUserEventsHandler(subscriber EventSubscriber) *UserEventsHandler {
+	return &UserEventsHandler{subscriber: subscriber}
+}
+
+// StreamEvents godoc
+// @Summary Stream user events
+// @Description Stream user created, updated and deleted events as server-sent events (Admin only). A comment line is sent every 30 seconds to keep idle connections open.
+// @Tags users
+// @Produce text/event-stream
+// @Security Bearer
+// @Success 200 {string} string "Event stream"
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 503 {object} response.Response
+// @Router /users/events [get]
+func (h *UserEventsHandler) StreamEvents(c *gin.Context) {
+	if h.subscriber == nil {
+		response.ServiceUnavailable(c, "Event stream is unavailable")
+		return
+	}
+
+	ctx := c.Request.Context()
+	sub, err := h.subscriber.Subscribe(ctx, constants.ExchangeUserEvents,
+		constants.RoutingKeyUserCreated,
+		constants.RoutingKeyUserUpdated,
+		constants.RoutingKeyUserDeleted,
+	)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to subscribe to user events", zap.Error(err))
+		response.ServiceUnavailable(c, "Event stream is unavailable")
+		return
+	}
+	defer sub.Close()
+
+	// The stream outlives the server's write timeout.
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		logger.FromContext(ctx).Warn("failed to clear write deadline for event stream", zap.Error(err))
+	}
+
+	header := c.Writer.Header()
+	header.Set(constants.HeaderContentType, "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	header.Set("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(sseHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			if _, err := io.WriteString(c.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case msg, ok := <-sub.Messages():
+			if !ok {
+				logger.FromContext(ctx).Warn("user event subscription closed by broker")
+				return
+			}
+			if err := writeServerSentEvent(c.Writer, msg.MessageId, msg.RoutingKey, msg.Body); err != nil {
+				return
+			}
+		}
+		c.Writer.Flush()
+	}
+}
+
+// writeServerSentEvent writes one SSE message. Each line of data gets its own
+// "data:" field so multi-line payloads survive framing.
+func writeServerSentEvent(w io.Writer, id, event string, data []byte) error {
+	var b strings.Builder
+	if id != "" {
+		fmt.Fprintf(&b, "id: %s\n", id)
+	}
+	fmt.Fprintf(&b, "event: %s\n", event)
+	for _, line := range strings.Split(string(data), "\n") {
+		fmt.Fprintf(&b, "data: %s\n", line)
+	}
+	b.WriteString("\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -1,12 +1,18 @@
 package http
 
 import (
+	"encoding/csv"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/TubagusAldiMY/go-template/internal/domain/user/dto"
 	"github.com/TubagusAldiMY/go-template/internal/domain/user/usecase"
 	"github.com/TubagusAldiMY/go-template/internal/shared/constants"
 	"github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/TubagusAldiMY/go-template/pkg/appcontext"
 	"github.com/TubagusAldiMY/go-template/pkg/logger"
 	"github.com/TubagusAldiMY/go-template/pkg/response"
 	customValidator "github.com/TubagusAldiMY/go-template/pkg/validator"
@@ -15,31 +21,50 @@ import (
 )
 
 type UserHandler struct {
-	userUsecase *usecase.UserUsecase
+	userUsecase  *usecase.UserUsecase
+	tokenCookies TokenCookies
 }
 
-func NewUserHandler(userUsecase *usecase.UserUsecase) *UserHandler {
-	return &UserHandler{
+func NewUserHandler(userUsecase *usecase.UserUsecase, opts ...HandlerOption) *UserHandler {
+	h := &UserHandler{
 		userUsecase: userUsecase,
+		tokenCookies: TokenCookies{
+			Delivery: constants.TokenDeliveryJSON,
+			Secure:   true,
+			SameSite: http.SameSiteStrictMode,
+		},
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// respondError is response.FromError with a status and message that fit the
+// endpoint better than the generic ones; the code still comes from err.
+func respondError(c *gin.Context, statusCode int, message string, err error) {
+	response.ErrorWithCode(c, statusCode, errors.Code(err), message, nil)
 }
 
 // Register godoc
 // @Summary Register a new user
-// @Description Register a new user account
+// @Description Register a new user account. When BLOCK_DISPOSABLE_EMAILS is set, emails at throwaway domains are rejected with 422 DISPOSABLE_EMAIL. Once REGISTRATION_LIMIT_PER_IP accounts were registered from the client IP within REGISTRATION_LIMIT_WINDOW, further registrations get 429 REGISTRATION_LIMIT_EXCEEDED.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param request body dto.RegisterRequest true "Register request"
+// @Param Idempotency-Key header string false "Replays the first response for retries with the same key"
 // @Success 201 {object} response.Response{data=dto.UserResponse}
 // @Failure 400 {object} response.Response
 // @Failure 409 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Failure 429 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /auth/register [post]
 func (h *UserHandler) Register(c *gin.Context) {
 	var req dto.RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body", err.Error())
+		response.InvalidBody(c, err)
 		return
 	}
 
@@ -49,17 +74,15 @@ func (h *UserHandler) Register(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userUsecase.Register(c.Request.Context(), &req)
+	user, err := h.userUsecase.Register(clientContext(c), &req)
 	if err != nil {
-		switch {
-		case errors.Is(err, errors.ErrEmailAlreadyExists):
-			response.Conflict(c, "Email already exists", nil)
-		case errors.Is(err, errors.ErrUsernameAlreadyExists):
-			response.Conflict(c, "Username already exists", nil)
-		default:
-			logger.Error("failed to register user", zap.Error(err))
-			response.InternalServerError(c, "Failed to register user")
+		if errors.Is(err, errors.ErrDisposableEmail) {
+			response.ErrorWithCode(c, http.StatusUnprocessableEntity, errors.Code(err), "Disposable email addresses are not allowed", map[string]string{
+				"email": "email must not use a disposable email domain",
+			})
+			return
 		}
+		response.FromError(c, err)
 		return
 	}
 
@@ -68,20 +91,27 @@ func (h *UserHandler) Register(c *gin.Context) {
 
 // Login godoc
 // @Summary User login
-// @Description Authenticate user and get tokens
+// @Description Authenticate with an email or username and get tokens. Users with MFA enabled get mfa_required and an mfa_token instead, to be completed at /auth/login/totp. With token_delivery=cookie the tokens are set as HttpOnly cookies instead of returned in the body.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param request body dto.LoginRequest true "Login request"
+// @Param token_delivery query string false "Token delivery" Enums(json, cookie, both)
 // @Success 200 {object} response.Response{data=dto.LoginResponse}
 // @Failure 400 {object} response.Response
 // @Failure 401 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /auth/login [post]
 func (h *UserHandler) Login(c *gin.Context) {
+	delivery, ok := h.tokenDelivery(c)
+	if !ok {
+		response.BadRequest(c, "token_delivery must be json, cookie or both", nil)
+		return
+	}
+
 	var req dto.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body", err.Error())
+		response.InvalidBody(c, err)
 		return
 	}
 
@@ -91,39 +121,50 @@ func (h *UserHandler) Login(c *gin.Context) {
 		return
 	}
 
-	loginResp, err := h.userUsecase.Login(c.Request.Context(), &req)
+	loginResp, err := h.userUsecase.Login(clientContext(c), &req)
 	if err != nil {
-		switch {
-		case errors.Is(err, errors.ErrInvalidCredentials):
-			response.Unauthorized(c, "Invalid email or password")
-		case errors.Is(err, errors.ErrUnauthorized):
-			response.Unauthorized(c, "Account is not active")
-		default:
-			logger.Error("failed to login", zap.Error(err))
-			response.InternalServerError(c, "Failed to login")
+		if errors.Is(err, errors.ErrUnauthorized) {
+			respondError(c, http.StatusUnauthorized, "Account is not active", err)
+			return
 		}
+		response.FromError(c, err)
 		return
 	}
 
+	if loginResp.MFARequired {
+		response.OK(c, "MFA required", loginResp)
+		return
+	}
+
+	h.deliverTokens(c, delivery, &loginResp.AccessToken, &loginResp.RefreshToken, loginResp.ExpiresIn, loginResp.RefreshExpiresIn)
 	response.OK(c, "Login successful", loginResp)
 }
 
 // RefreshToken godoc
 // @Summary Refresh access token
-// @Description Get new access token using refresh token
+// @Description Get new access token using refresh token. Without a body, the refresh token is read from the refresh_token cookie.
 // @Tags auth
 // @Accept json
 // @Produce json
-// @Param request body dto.RefreshTokenRequest true "Refresh token request"
+// @Param request body dto.RefreshTokenRequest false "Refresh token request"
+// @Param token_delivery query string false "Token delivery" Enums(json, cookie, both)
 // @Success 200 {object} response.Response{data=dto.RefreshTokenResponse}
 // @Failure 400 {object} response.Response
 // @Failure 401 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /auth/refresh [post]
 func (h *UserHandler) RefreshToken(c *gin.Context) {
+	delivery, ok := h.tokenDelivery(c)
+	if !ok {
+		response.BadRequest(c, "token_delivery must be json, cookie or both", nil)
+		return
+	}
+
 	var req dto.RefreshTokenRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body", err.Error())
+	if cookie, err := c.Cookie(constants.CookieRefreshToken); err == nil && c.Request.ContentLength == 0 {
+		req.RefreshToken = cookie
+	} else if err := c.ShouldBindJSON(&req); err != nil {
+		response.InvalidBody(c, err)
 		return
 	}
 
@@ -135,21 +176,113 @@ func (h *UserHandler) RefreshToken(c *gin.Context) {
 
 	refreshResp, err := h.userUsecase.RefreshToken(c.Request.Context(), &req)
 	if err != nil {
-		switch {
-		case errors.Is(err, errors.ErrInvalidToken):
-			response.Unauthorized(c, "Invalid refresh token")
-		case errors.Is(err, errors.ErrUnauthorized):
-			response.Unauthorized(c, "Unauthorized")
-		default:
-			logger.Error("failed to refresh token", zap.Error(err))
-			response.InternalServerError(c, "Failed to refresh token")
-		}
+		response.FromError(c, err)
 		return
 	}
 
+	h.deliverTokens(c, delivery, &refreshResp.AccessToken, &refreshResp.RefreshToken, refreshResp.ExpiresIn, refreshResp.RefreshExpiresIn)
 	response.OK(c, "Token refreshed successfully", refreshResp)
 }
 
+// Logout godoc
+// @Summary Log out
+// @Description Revoke the refresh token and clear the token cookies. Without a body, the refresh token is read from the refresh_token cookie. Access tokens stay valid until they expire.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body dto.LogoutRequest false "Logout request"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /auth/logout [post]
+func (h *UserHandler) Logout(c *gin.Context) {
+	var req dto.LogoutRequest
+	if c.Request.ContentLength == 0 {
+		if cookie, err := c.Cookie(constants.CookieRefreshToken); err == nil {
+			req.RefreshToken = cookie
+		}
+	} else if err := c.ShouldBindJSON(&req); err != nil {
+		response.InvalidBody(c, err)
+		return
+	}
+
+	// The cookies are cleared even if revoking fails, so the browser is
+	// logged out either way.
+	h.clearTokenCookies(c)
+
+	if err := h.userUsecase.Logout(c.Request.Context(), &req); err != nil {
+		response.FromError(c, err)
+		return
+	}
+
+	response.OK(c, "Logged out successfully", nil)
+}
+
+// Me godoc
+// @Summary Get current identity
+// @Description Get the authenticated user's ID, email and role from the access token without a database lookup
+// @Tags auth
+// @Produce json
+// @Security Bearer
+// @Param If-None-Match header string false "Returns 304 without a body while the ETag still matches"
+// @Success 200 {object} response.Response{data=dto.IdentityResponse}
+// @Success 304 "Not Modified"
+// @Failure 401 {object} response.Response
+// @Router /auth/me [get]
+func (h *UserHandler) Me(c *gin.Context) {
+	ctx := c.Request.Context()
+	userID, hasUserID := appcontext.UserID(ctx)
+	email, hasEmail := appcontext.Email(ctx)
+	role, hasRole := appcontext.Role(ctx)
+	if !hasUserID || !hasEmail || !hasRole {
+		response.FromError(c, errors.ErrUnauthorized)
+		return
+	}
+
+	response.OK(c, "Identity retrieved successfully", &dto.IdentityResponse{
+		UserID: userID,
+		Email:  email,
+		Role:   role,
+	})
+}
+
+// IntrospectToken godoc
+// @Summary Introspect an access token
+// @Description Let resource servers check an access token without the signing secret (requires the token:introspect permission, admins under the default policy). Invalid, expired and deactivated users' tokens return active false rather than an error.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body dto.IntrospectTokenRequest true "Token to introspect"
+// @Success 200 {object} response.Response{data=dto.IntrospectTokenResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /auth/introspect [post]
+func (h *UserHandler) IntrospectToken(c *gin.Context) {
+	var req dto.IntrospectTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.InvalidBody(c, err)
+		return
+	}
+
+	if err := customValidator.Validate(&req); err != nil {
+		validationErrors := customValidator.FormatValidationErrors(err)
+		response.UnprocessableEntity(c, "Validation failed", validationErrors)
+		return
+	}
+
+	introspection, err := h.userUsecase.IntrospectToken(c.Request.Context(), &req)
+	if err != nil {
+		response.FromError(c, err)
+		return
+	}
+
+	response.OK(c, "Token introspected successfully", introspection)
+}
+
 // GetProfile godoc
 // @Summary Get user profile
 // @Description Get authenticated user's profile
@@ -157,27 +290,23 @@ func (h *UserHandler) RefreshToken(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Security Bearer
+// @Param If-None-Match header string false "Returns 304 without a body while the ETag still matches"
 // @Success 200 {object} response.Response{data=dto.UserResponse}
+// @Success 304 "Not Modified"
 // @Failure 401 {object} response.Response
 // @Failure 404 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /users/profile [get]
 func (h *UserHandler) GetProfile(c *gin.Context) {
-	userID := c.GetString(constants.ContextKeyUserID)
-	if userID == "" {
-		response.Unauthorized(c, "Unauthorized")
+	userID, ok := appcontext.UserID(c.Request.Context())
+	if !ok {
+		response.FromError(c, errors.ErrUnauthorized)
 		return
 	}
 
 	user, err := h.userUsecase.GetProfile(c.Request.Context(), userID)
 	if err != nil {
-		switch {
-		case errors.Is(err, errors.ErrUserNotFound):
-			response.NotFound(c, "User not found")
-		default:
-			logger.Error("failed to get profile", zap.Error(err))
-			response.InternalServerError(c, "Failed to get profile")
-		}
+		response.FromError(c, err)
 		return
 	}
 
@@ -186,7 +315,7 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 
 // UpdateProfile godoc
 // @Summary Update user profile
-// @Description Update authenticated user's profile
+// @Description Update authenticated user's full name, email or username. Omitted fields are left unchanged; an email or username taken by another user is a 409.
 // @Tags users
 // @Accept json
 // @Produce json
@@ -196,18 +325,20 @@ func (h *UserHandler) GetProfile(c *gin.Context) {
 // @Failure 400 {object} response.Response
 // @Failure 401 {object} response.Response
 // @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 422 {object} response.Response
 // @Failure 500 {object} response.Response
 // @Router /users/profile [put]
 func (h *UserHandler) UpdateProfile(c *gin.Context) {
-	userID := c.GetString(constants.ContextKeyUserID)
-	if userID == "" {
-		response.Unauthorized(c, "Unauthorized")
+	userID, ok := appcontext.UserID(c.Request.Context())
+	if !ok {
+		response.FromError(c, errors.ErrUnauthorized)
 		return
 	}
 
 	var req dto.UpdateProfileRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body", err.Error())
+		response.InvalidBody(c, err)
 		return
 	}
 
@@ -219,22 +350,123 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 
 	user, err := h.userUsecase.UpdateProfile(c.Request.Context(), userID, &req)
 	if err != nil {
-		switch {
-		case errors.Is(err, errors.ErrUserNotFound):
-			response.NotFound(c, "User not found")
-		default:
-			logger.Error("failed to update profile", zap.Error(err))
-			response.InternalServerError(c, "Failed to update profile")
+		response.FromError(c, err)
+		return
+	}
+
+	response.OK(c, "Profile updated successfully", user)
+}
+
+// PatchProfile godoc
+// @Summary Patch user profile
+// @Description Partially update the authenticated user's profile with a JSON Merge Patch (RFC 7396). Fields absent from the patch are left unchanged and fields present are validated like in PUT /users/profile. full_name, timezone and locale set to null are cleared; email and username cannot be null. An email or username taken by another user is a 409.
+// @Tags users
+// @Accept json
+// @Accept application/merge-patch+json
+// @Produce json
+// @Security Bearer
+// @Param request body dto.PatchProfileRequest true "Merge patch of the profile"
+// @Success 200 {object} response.Response{data=dto.UserResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /users/profile [patch]
+func (h *UserHandler) PatchProfile(c *gin.Context) {
+	userID, ok := appcontext.UserID(c.Request.Context())
+	if !ok {
+		response.FromError(c, errors.ErrUnauthorized)
+		return
+	}
+
+	var req dto.PatchProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.InvalidBody(c, err)
+		return
+	}
+
+	validationErrors := map[string]string{}
+	if err := customValidator.Validate(&req); err != nil {
+		validationErrors = customValidator.FormatValidationErrors(err)
+	}
+	for _, field := range []string{"email", "username"} {
+		if req.Clears(field) {
+			validationErrors[field] = field + " cannot be null"
 		}
+	}
+	if len(validationErrors) > 0 {
+		response.UnprocessableEntity(c, "Validation failed", validationErrors)
+		return
+	}
+
+	user, err := h.userUsecase.PatchProfile(c.Request.Context(), userID, &req)
+	if err != nil {
+		response.FromError(c, err)
 		return
 	}
 
 	response.OK(c, "Profile updated successfully", user)
 }
 
+// UploadAvatar godoc
+// @Summary Upload avatar
+// @Description Replace the authenticated user's profile picture with a JPEG, PNG, GIF or WebP image
+// @Tags users
+// @Accept multipart/form-data
+// @Produce json
+// @Security Bearer
+// @Param avatar formData file true "Avatar image"
+// @Success 200 {object} response.Response{data=dto.UserResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 413 {object} response.Response
+// @Failure 415 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /users/profile/avatar [post]
+func (h *UserHandler) UploadAvatar(c *gin.Context) {
+	userID, ok := appcontext.UserID(c.Request.Context())
+	if !ok {
+		response.FromError(c, errors.ErrUnauthorized)
+		return
+	}
+
+	header, err := c.FormFile("avatar")
+	if err != nil {
+		if errors.Is(err, http.ErrMissingFile) {
+			response.BadRequest(c, "avatar file is required", nil)
+			return
+		}
+		response.InvalidBody(c, err)
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		response.InvalidBody(c, err)
+		return
+	}
+	defer file.Close()
+
+	user, err := h.userUsecase.UploadAvatar(c.Request.Context(), userID, file)
+	if err != nil {
+		if errors.Is(err, errors.ErrUnsupportedMediaType) {
+			respondError(c, http.StatusUnsupportedMediaType, "Avatar must be a JPEG, PNG, GIF or WebP image", err)
+			return
+		}
+		response.FromError(c, err)
+		return
+	}
+
+	response.OK(c, "Avatar updated successfully", user)
+}
+
 // ChangePassword godoc
 // @Summary Change password
-// @Description Change authenticated user's password
+// @Description Change authenticated user's password and sign them out everywhere, including the current session: their refresh tokens and access tokens stop being accepted
 // @Tags users
 // @Accept json
 // @Produce json
@@ -244,18 +476,20 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 // @Failure 400 {object} response.Response
 // @Failure 401 {object} response.Response
 // @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
 // @Failure 500 {object} response.Response
+// @Failure 503 {object} response.Response
 // @Router /users/change-password [post]
 func (h *UserHandler) ChangePassword(c *gin.Context) {
-	userID := c.GetString(constants.ContextKeyUserID)
-	if userID == "" {
-		response.Unauthorized(c, "Unauthorized")
+	userID, ok := appcontext.UserID(c.Request.Context())
+	if !ok {
+		response.FromError(c, errors.ErrUnauthorized)
 		return
 	}
 
 	var req dto.ChangePasswordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		response.BadRequest(c, "Invalid request body", err.Error())
+		response.InvalidBody(c, err)
 		return
 	}
 
@@ -266,21 +500,56 @@ func (h *UserHandler) ChangePassword(c *gin.Context) {
 	}
 
 	if err := h.userUsecase.ChangePassword(c.Request.Context(), userID, &req); err != nil {
-		switch {
-		case errors.Is(err, errors.ErrUserNotFound):
-			response.NotFound(c, "User not found")
-		case errors.Is(err, errors.ErrInvalidPassword):
-			response.BadRequest(c, "Invalid old password", nil)
-		default:
-			logger.Error("failed to change password", zap.Error(err))
-			response.InternalServerError(c, "Failed to change password")
-		}
+		response.FromError(c, err)
 		return
 	}
 
 	response.OK(c, "Password changed successfully", nil)
 }
 
+// DeleteProfile godoc
+// @Summary Delete own account
+// @Description Delete the authenticated user's account after confirming the current password. The user is signed out everywhere first: their refresh tokens and access tokens stop being accepted
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body dto.DeleteAccountRequest true "Delete account request"
+// @Success 200 {object} response.Response
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Failure 503 {object} response.Response
+// @Router /users/profile [delete]
+func (h *UserHandler) DeleteProfile(c *gin.Context) {
+	userID, ok := appcontext.UserID(c.Request.Context())
+	if !ok {
+		response.FromError(c, errors.ErrUnauthorized)
+		return
+	}
+
+	var req dto.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.InvalidBody(c, err)
+		return
+	}
+
+	if err := customValidator.Validate(&req); err != nil {
+		validationErrors := customValidator.FormatValidationErrors(err)
+		response.UnprocessableEntity(c, "Validation failed", validationErrors)
+		return
+	}
+
+	if err := h.userUsecase.DeleteOwnAccount(c.Request.Context(), userID, &req); err != nil {
+		response.FromError(c, err)
+		return
+	}
+
+	response.OK(c, "Account deleted successfully", nil)
+}
+
 // ListUsers godoc
 // @Summary List users
 // @Description Get list of users with pagination and filters (Admin only)
@@ -291,9 +560,18 @@ func (h *UserHandler) ChangePassword(c *gin.Context) {
 // @Param page query int false "Page number" default(1)
 // @Param page_size query int false "Page size" default(20)
 // @Param search query string false "Search by email, username, or full name"
+// @Param search_mode query string false "exact matches substrings; fuzzy tolerates typos and ranks by similarity (offset mode only)" Enums(exact, fuzzy) default(exact)
 // @Param role query string false "Filter by role"
 // @Param status query string false "Filter by status"
+// @Param created_after query string false "Only users created after this RFC 3339 time" format(date-time)
+// @Param created_before query string false "Only users created before this RFC 3339 time" format(date-time)
+// @Param inactive_since query string false "Only users who have not logged in since this RFC 3339 time" format(date-time)
+// @Param sort_by query string false "Sort column (offset mode only)" Enums(email, username, created_at, updated_at, status) default(created_at)
+// @Param sort_order query string false "Sort direction (offset mode only)" Enums(asc, desc) default(desc)
+// @Param cursor query string false "Opaque cursor for keyset pagination; pass it empty to fetch the first page"
+// @Param If-None-Match header string false "Returns 304 without a body while the ETag still matches"
 // @Success 200 {object} response.Response{data=[]dto.UserResponse}
+// @Success 304 "Not Modified"
 // @Failure 400 {object} response.Response
 // @Failure 401 {object} response.Response
 // @Failure 403 {object} response.Response
@@ -320,17 +598,366 @@ func (h *UserHandler) ListUsers(c *gin.Context) {
 		return
 	}
 
+	// The presence of the cursor parameter, even empty for the first page,
+	// selects keyset pagination; otherwise page/page_size offsets are used.
+	if _, cursorMode := c.GetQuery("cursor"); cursorMode {
+		h.listUsersByCursor(c, &req)
+		return
+	}
+
 	users, total, err := h.userUsecase.ListUsers(c.Request.Context(), &req)
 	if err != nil {
-		logger.Error("failed to list users", zap.Error(err))
-		response.InternalServerError(c, "Failed to list users")
+		response.FromError(c, err)
 		return
 	}
 
-	meta := response.NewMeta(req.Page, req.PageSize, total)
+	response.Paginated(c, "Users retrieved successfully", users, req.Page, req.PageSize, total)
+}
+
+func (h *UserHandler) listUsersByCursor(c *gin.Context, req *dto.ListUsersRequest) {
+	users, nextCursor, err := h.userUsecase.ListUsersByCursor(c.Request.Context(), req)
+	if err != nil {
+		response.FromError(c, err)
+		return
+	}
+
+	hasNext := nextCursor != ""
+	meta := &response.Meta{
+		PageSize:   req.PageSize,
+		HasNext:    &hasNext,
+		NextCursor: nextCursor,
+	}
 	response.SuccessWithMeta(c, "Users retrieved successfully", users, meta)
 }
 
+// userCSVColumns is the header row of the user export, in userCSVRecord order.
+var userCSVColumns = []string{
+	"id", "email", "username", "full_name", "role", "status", "mfa_enabled",
+	"timezone", "locale", "last_login_at", "created_at", "updated_at",
+}
+
+// userCSVFlushRows is how many rows ExportUsers writes between flushes, so
+// the download makes progress without a network write per row.
+const userCSVFlushRows = 100
+
+// ExportUsers godoc
+// @Summary Export users as CSV
+// @Description Download every user matching the filters as a CSV file (Admin only), ordered as ListUsers orders them. Rows are streamed from the database, so exports of any size are supported. Cells starting with =, +, - or @ are prefixed with ' so spreadsheets do not evaluate them.
+// @Tags users
+// @Produce text/csv
+// @Security Bearer
+// @Param search query string false "Search by email, username, or full name"
+// @Param search_mode query string false "exact matches substrings; fuzzy tolerates typos and ranks by similarity" Enums(exact, fuzzy) default(exact)
+// @Param role query string false "Filter by role"
+// @Param status query string false "Filter by status"
+// @Param created_after query string false "Only users created after this RFC 3339 time" format(date-time)
+// @Param created_before query string false "Only users created before this RFC 3339 time" format(date-time)
+// @Param inactive_since query string false "Only users who have not logged in since this RFC 3339 time" format(date-time)
+// @Param sort_by query string false "Sort column" Enums(email, username, created_at, updated_at, status) default(created_at)
+// @Param sort_order query string false "Sort direction" Enums(asc, desc) default(desc)
+// @Success 200 {string} string "CSV file"
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 422 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /users/export.csv [get]
+func (h *UserHandler) ExportUsers(c *gin.Context) {
+	var req dto.ListUsersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.BadRequest(c, "Invalid query parameters", err.Error())
+		return
+	}
+
+	if err := customValidator.Validate(&req); err != nil {
+		validationErrors := customValidator.FormatValidationErrors(err)
+		response.UnprocessableEntity(c, "Validation failed", validationErrors)
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	// The download outlives the server's write timeout.
+	if err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Time{}); err != nil {
+		logger.FromContext(ctx).Warn("failed to clear write deadline for user export", zap.Error(err))
+	}
+
+	// The headers are sent with the first row, so an export that fails before
+	// producing any still gets a JSON error response.
+	w := csv.NewWriter(c.Writer)
+	started := false
+	start := func() error {
+		if started {
+			return nil
+		}
+		started = true
+
+		header := c.Writer.Header()
+		header.Set(constants.HeaderContentType, "text/csv; charset=utf-8")
+		header.Set("Content-Disposition",
+			fmt.Sprintf(`attachment; filename="users-%s.csv"`, time.Now().UTC().Format("20060102-150405")))
+		header.Set("Cache-Control", "no-store")
+		c.Status(http.StatusOK)
+		return w.Write(userCSVColumns)
+	}
+
+	rows := 0
+	err := h.userUsecase.ExportUsers(ctx, &req, func(user *dto.UserResponse) error {
+		if err := start(); err != nil {
+			return err
+		}
+		if err := w.Write(userCSVRecord(user)); err != nil {
+			return err
+		}
+
+		rows++
+		if rows%userCSVFlushRows == 0 {
+			w.Flush()
+			c.Writer.Flush()
+			return w.Error()
+		}
+		return nil
+	})
+	if err == nil {
+		err = start()
+	}
+	if err != nil && !started {
+		response.FromError(c, err)
+		return
+	}
+	if err != nil {
+		// The status is already sent; the truncated file is all the client
+		// gets. Write errors mean the client went away.
+		logger.FromContext(ctx).Warn("user export aborted", zap.Int("rows", rows), zap.Error(err))
+		c.Abort()
+		return
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		logger.FromContext(ctx).Warn("failed to finish user export", zap.Int("rows", rows), zap.Error(err))
+	}
+}
+
+func userCSVRecord(user *dto.UserResponse) []string {
+	lastLogin := ""
+	if user.LastLoginAt != nil {
+		lastLogin = user.LastLoginAt.UTC().Format(time.RFC3339)
+	}
+
+	return []string{
+		user.ID,
+		csvSafe(user.Email),
+		csvSafe(user.Username),
+		csvSafe(user.FullName),
+		user.Role,
+		user.Status,
+		strconv.FormatBool(user.MFAEnabled),
+		user.Timezone,
+		user.Locale,
+		lastLogin,
+		user.CreatedAt.UTC().Format(time.RFC3339),
+		user.UpdatedAt.UTC().Format(time.RFC3339),
+	}
+}
+
+// csvSafe defuses user-supplied values a spreadsheet would read as a
+// formula by prefixing them with a quote.
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// GetUserStats godoc
+// @Summary Get user stats
+// @Description Count users by status for the admin dashboard (Admin only). Soft-deleted users are not counted. Counts are cached for up to a minute.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param If-None-Match header string false "Returns 304 without a body while the ETag still matches"
+// @Success 200 {object} response.Response{data=dto.UserStatsResponse}
+// @Success 304 "Not Modified"
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /users/stats [get]
+func (h *UserHandler) GetUserStats(c *gin.Context) {
+	stats, err := h.userUsecase.GetStats(c.Request.Context())
+	if err != nil {
+		response.FromError(c, err)
+		return
+	}
+
+	response.OK(c, "User stats retrieved successfully", stats)
+}
+
+// BulkCreateUsers godoc
+// @Summary Bulk create users
+// @Description Create up to 50 users in one transaction (Admin only). Each row is reported individually; in strict mode any failed row rejects the whole batch.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param request body dto.BulkCreateUsersRequest true "Bulk create request"
+// @Success 200 {object} response.Response{data=dto.BulkCreateUsersResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 422 {object} response.Response{errors=dto.BulkCreateUsersResponse}
+// @Failure 500 {object} response.Response
+// @Router /users/bulk [post]
+func (h *UserHandler) BulkCreateUsers(c *gin.Context) {
+	var req dto.BulkCreateUsersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.InvalidBody(c, err)
+		return
+	}
+
+	if err := customValidator.Validate(&req); err != nil {
+		validationErrors := customValidator.FormatValidationErrors(err)
+		response.UnprocessableEntity(c, "Validation failed", validationErrors)
+		return
+	}
+
+	result, err := h.userUsecase.BulkCreate(c.Request.Context(), &req)
+	if err != nil {
+		if errors.Is(err, errors.ErrEmailAlreadyExists) || errors.Is(err, errors.ErrUsernameAlreadyExists) {
+			// Another request created one of the rows after it was checked.
+			respondError(c, http.StatusConflict, "A user in the batch was created concurrently, please retry", err)
+			return
+		}
+		response.FromError(c, err)
+		return
+	}
+
+	if req.Strict && result.Failed > 0 {
+		response.UnprocessableEntity(c, "Batch rejected, no users were created", result)
+		return
+	}
+
+	response.OK(c, "Bulk import completed", result)
+}
+
+// ChangeUserStatus godoc
+// @Summary Change user status
+// @Description Activate, deactivate, or ban a user by ID (Admin only)
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "User ID"
+// @Param request body dto.ChangeStatusRequest true "Change status request"
+// @Success 200 {object} response.Response{data=dto.UserResponse}
+// @Failure 400 {object} response.Response
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /users/{id}/status [patch]
+func (h *UserHandler) ChangeUserStatus(c *gin.Context) {
+	userID := c.Param("id")
+	if userID == "" {
+		response.BadRequest(c, "User ID is required", nil)
+		return
+	}
+
+	var req dto.ChangeStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.InvalidBody(c, err)
+		return
+	}
+
+	// Unknown statuses are a client error on this endpoint, so validation
+	// failures are reported as 400 rather than the usual 422.
+	if err := customValidator.Validate(&req); err != nil {
+		validationErrors := customValidator.FormatValidationErrors(err)
+		response.BadRequest(c, "Invalid status", validationErrors)
+		return
+	}
+
+	user, err := h.userUsecase.ChangeUserStatus(c.Request.Context(), userID, req.Status)
+	if err != nil {
+		response.FromError(c, err)
+		return
+	}
+
+	response.OK(c, "User status changed successfully", user)
+}
+
+// RestoreUser godoc
+// @Summary Restore user
+// @Description Restore a soft-deleted user and reactivate the account (Admin only). Restoring a user that is not deleted is a no-op. Fails with 409 if another user has taken the email or username since.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "User ID"
+// @Success 200 {object} response.Response{data=dto.UserResponse}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 409 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /users/{id}/restore [post]
+func (h *UserHandler) RestoreUser(c *gin.Context) {
+	userID := c.Param("id")
+	if userID == "" {
+		response.BadRequest(c, "User ID is required", nil)
+		return
+	}
+
+	user, err := h.userUsecase.RestoreUser(c.Request.Context(), userID)
+	if err != nil {
+		response.FromError(c, err)
+		return
+	}
+
+	response.OK(c, "User restored successfully", user)
+}
+
+// ImpersonateUser godoc
+// @Summary Impersonate user
+// @Description Issue a short-lived access token for acting as the user, e.g. to reproduce what they see (Admin only). The token's act claim names the admin, actions taken with it are audited as the admin's, and the impersonation itself is audited. Limited per admin by RATE_LIMIT_IMPERSONATIONS_PER_MINUTE; impersonation tokens cannot start another impersonation.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Security Bearer
+// @Param id path string true "User ID"
+// @Success 200 {object} response.Response{data=dto.ImpersonationResponse}
+// @Failure 401 {object} response.Response
+// @Failure 403 {object} response.Response
+// @Failure 404 {object} response.Response
+// @Failure 429 {object} response.Response
+// @Failure 500 {object} response.Response
+// @Router /users/{id}/impersonate [post]
+func (h *UserHandler) ImpersonateUser(c *gin.Context) {
+	userID := c.Param("id")
+	if userID == "" {
+		response.BadRequest(c, "User ID is required", nil)
+		return
+	}
+
+	ctx := c.Request.Context()
+	if _, impersonating := appcontext.ActorID(ctx); impersonating {
+		response.FromError(c, errors.ErrCannotImpersonate.WithMessage("An impersonation session cannot start another impersonation"))
+		return
+	}
+
+	adminID, _ := appcontext.UserID(ctx)
+	result, err := h.userUsecase.Impersonate(ctx, adminID, userID)
+	if err != nil {
+		response.FromError(c, err)
+		return
+	}
+
+	response.OK(c, "Impersonation token issued", result)
+}
+
 // DeleteUser godoc
 // @Summary Delete user
 // @Description Delete a user by ID (Admin only)
@@ -353,13 +980,7 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 	}
 
 	if err := h.userUsecase.DeleteUser(c.Request.Context(), userID); err != nil {
-		switch {
-		case errors.Is(err, errors.ErrUserNotFound):
-			response.NotFound(c, "User not found")
-		default:
-			logger.Error("failed to delete user", zap.Error(err))
-			response.InternalServerError(c, "Failed to delete user")
-		}
+		response.FromError(c, err)
 		return
 	}
 
@@ -1,6 +1,10 @@
 package dto
 
-import "time"
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+)
 
 // Request DTOs
 
@@ -12,12 +16,76 @@ type RegisterRequest struct {
 }
 
 type LoginRequest struct {
-	Email    string `json:"email" validate:"required,email"`
+	// Identifier accepts either an email address or a username.
+	Identifier string `json:"identifier" validate:"required_without=Email,max=255"`
+	// Email is kept for clients that predate Identifier.
+	Email    string `json:"email" validate:"required_without=Identifier,omitempty,email"`
 	Password string `json:"password" validate:"required"`
+	// RememberMe issues a refresh token with the extended
+	// JWT_REMEMBER_ME_REFRESH_EXPIRY lifetime, for long-lived sessions.
+	RememberMe bool `json:"remember_me"`
+}
+
+// LoginTOTPRequest completes a login for a user with MFA enabled.
+type LoginTOTPRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required,len=6,numeric"`
 }
 
+type VerifyTOTPRequest struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+// UpdateProfileRequest changes only the fields that are set.
 type UpdateProfileRequest struct {
 	FullName string `json:"full_name" validate:"omitempty,min=2,max=100"`
+	Email    string `json:"email" validate:"omitempty,email"`
+	Username string `json:"username" validate:"omitempty,username"`
+	// Timezone is an IANA zone name, e.g. "Asia/Jakarta".
+	Timezone string `json:"timezone" validate:"omitempty,timezone"`
+	// Locale is a BCP-47 language tag, e.g. "id-ID".
+	Locale string `json:"locale" validate:"omitempty,locale"`
+}
+
+// PatchProfileRequest is a JSON Merge Patch (RFC 7396) of the profile.
+// Fields absent from the patch are left unchanged, fields set to a value are
+// validated and changed, and fields set to null are cleared. Only full_name,
+// timezone and locale can be cleared.
+type PatchProfileRequest struct {
+	FullName *string `json:"full_name" validate:"omitnil,min=2,max=100"`
+	Email    *string `json:"email" validate:"omitnil,email"`
+	Username *string `json:"username" validate:"omitnil,username"`
+	Timezone *string `json:"timezone" validate:"omitnil,timezone"`
+	Locale   *string `json:"locale" validate:"omitnil,locale"`
+
+	// nulls holds the JSON names of the fields set to null, which decode
+	// to nil just like absent ones.
+	nulls map[string]bool
+}
+
+func (r *PatchProfileRequest) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	type patch PatchProfileRequest
+	if err := json.Unmarshal(data, (*patch)(r)); err != nil {
+		return err
+	}
+
+	r.nulls = make(map[string]bool)
+	for name, value := range fields {
+		if bytes.Equal(bytes.TrimSpace(value), []byte("null")) {
+			r.nulls[name] = true
+		}
+	}
+	return nil
+}
+
+// Clears reports whether the patch sets field, named as in JSON, to null.
+func (r *PatchProfileRequest) Clears(field string) bool {
+	return r.nulls[field]
 }
 
 type ChangePasswordRequest struct {
@@ -25,33 +93,126 @@ type ChangePasswordRequest struct {
 	NewPassword string `json:"new_password" validate:"required,password"`
 }
 
+// DeleteAccountRequest confirms self-service account deletion with the
+// user's current password.
+type DeleteAccountRequest struct {
+	Password string `json:"password" validate:"required"`
+}
+
+type ChangeStatusRequest struct {
+	Status string `json:"status" validate:"required,enum_status"`
+}
+
+type BulkCreateUsersRequest struct {
+	// Users are validated individually so one bad row does not hide the others.
+	Users []RegisterRequest `json:"users" validate:"required,min=1,max=50"`
+	// Strict rejects the whole batch when any row fails. The default lenient
+	// mode creates the valid rows and reports the failed ones.
+	Strict bool `json:"strict"`
+}
+
 type ListUsersRequest struct {
 	Page     int    `form:"page" validate:"omitempty,min=1"`
+	Cursor   string `form:"cursor" validate:"omitempty,max=512"`
 	PageSize int    `form:"page_size" validate:"omitempty,min=1,max=100"`
 	Search   string `form:"search" validate:"omitempty,max=100"`
-	Role     string `form:"role" validate:"omitempty,oneof=admin user"`
-	Status   string `form:"status" validate:"omitempty,oneof=active inactive banned"`
+	// SearchMode fuzzy tolerates typos in Search and ranks the results by
+	// similarity; exact, the default, matches substrings.
+	SearchMode string `form:"search_mode" validate:"omitempty,oneof=exact fuzzy"`
+	Role       string `form:"role" validate:"omitempty,enum_role"`
+	Status     string `form:"status" validate:"omitempty,enum_status"`
+	// CreatedAfter and CreatedBefore are RFC 3339 timestamps bounding the
+	// creation time, exclusively.
+	CreatedAfter  time.Time `form:"created_after" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedBefore time.Time `form:"created_before" time_format:"2006-01-02T15:04:05Z07:00" validate:"omitempty,gtfield=CreatedAfter"`
+	// InactiveSince is an RFC 3339 timestamp; only users who have not logged
+	// in since then, or never have, are listed.
+	InactiveSince time.Time `form:"inactive_since" time_format:"2006-01-02T15:04:05Z07:00"`
+	// SortBy is interpolated into SQL, so it must stay restricted to this whitelist.
+	SortBy    string `form:"sort_by" validate:"omitempty,oneof=email username created_at updated_at status"`
+	SortOrder string `form:"sort_order" validate:"omitempty,oneof=asc desc"`
 }
 
 // Response DTOs
 
 type UserResponse struct {
-	ID        string    `json:"id"`
-	Email     string    `json:"email"`
-	Username  string    `json:"username"`
-	FullName  string    `json:"full_name"`
-	Role      string    `json:"role"`
-	Status    string    `json:"status"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID         string `json:"id"`
+	Email      string `json:"email"`
+	Username   string `json:"username"`
+	FullName   string `json:"full_name"`
+	Role       string `json:"role"`
+	Status     string `json:"status"`
+	MFAEnabled bool   `json:"mfa_enabled"`
+	AvatarURL  string `json:"avatar_url,omitempty"`
+	Timezone   string `json:"timezone,omitempty"`
+	Locale     string `json:"locale,omitempty"`
+	// Timestamps are in UTC. LastLoginAt is null for users who have never
+	// logged in.
+	LastLoginAt *time.Time `json:"last_login_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
 }
 
+type BulkCreateUserResult struct {
+	Index   int               `json:"index"`
+	Email   string            `json:"email"`
+	Success bool              `json:"success"`
+	User    *UserResponse     `json:"user,omitempty"`
+	Error   string            `json:"error,omitempty"`
+	Errors  map[string]string `json:"errors,omitempty"`
+}
+
+type BulkCreateUsersResponse struct {
+	Created int                     `json:"created"`
+	Failed  int                     `json:"failed"`
+	Results []*BulkCreateUserResult `json:"results"`
+}
+
+// UserStatsResponse counts users by status. Soft-deleted users are not
+// counted.
+type UserStatsResponse struct {
+	Total    int64 `json:"total"`
+	Active   int64 `json:"active"`
+	Inactive int64 `json:"inactive"`
+	Banned   int64 `json:"banned"`
+}
+
+// IdentityResponse describes the caller as asserted by their access token.
+type IdentityResponse struct {
+	UserID string `json:"user_id"`
+	Email  string `json:"email"`
+	Role   string `json:"role"`
+}
+
+// LoginResponse carries the issued tokens, or only MFARequired and MFAToken
+// when the user must complete the login with POST /auth/login/totp.
 type LoginResponse struct {
-	User         *UserResponse `json:"user"`
-	AccessToken  string        `json:"access_token"`
-	RefreshToken string        `json:"refresh_token"`
-	TokenType    string        `json:"token_type"`
-	ExpiresIn    int64         `json:"expires_in"` // seconds
+	User         *UserResponse `json:"user,omitempty"`
+	AccessToken  string        `json:"access_token,omitempty"`
+	RefreshToken string        `json:"refresh_token,omitempty"`
+	TokenType    string        `json:"token_type,omitempty"`
+	ExpiresIn    int64         `json:"expires_in,omitempty"` // seconds
+	// RefreshExpiresIn is the refresh token lifetime in seconds.
+	RefreshExpiresIn int64  `json:"refresh_expires_in,omitempty"`
+	MFARequired      bool   `json:"mfa_required,omitempty"`
+	MFAToken         string `json:"mfa_token,omitempty"`
+}
+
+// ImpersonationResponse carries a short-lived access token for acting as
+// User. No refresh token is issued; a new impersonation must be started once
+// it expires.
+type ImpersonationResponse struct {
+	User        *UserResponse `json:"user"`
+	AccessToken string        `json:"access_token"`
+	TokenType   string        `json:"token_type"`
+	ExpiresIn   int64         `json:"expires_in"` // seconds
+}
+
+// TOTPEnrollmentResponse is returned once when TOTP enrollment starts. The
+// secret is not retrievable afterwards.
+type TOTPEnrollmentResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
 }
 
 type RefreshTokenRequest struct {
@@ -59,8 +220,68 @@ type RefreshTokenRequest struct {
 }
 
 type RefreshTokenResponse struct {
-	AccessToken  string `json:"access_token"`
-	RefreshToken string `json:"refresh_token"`
+	AccessToken  string `json:"access_token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
 	TokenType    string `json:"token_type"`
 	ExpiresIn    int64  `json:"expires_in"`
+	// RefreshExpiresIn is the refresh token lifetime in seconds.
+	RefreshExpiresIn int64 `json:"refresh_expires_in"`
+}
+
+// SessionResponse is a login session of the user on one device. Timestamps
+// are in UTC.
+type SessionResponse struct {
+	ID         string    `json:"id"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	IPAddress  string    `json:"ip_address,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// IntrospectTokenRequest carries the access token a resource server wants
+// checked.
+type IntrospectTokenRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// IntrospectTokenResponse follows OAuth 2.0 token introspection (RFC 7662):
+// an inactive token only reports active false, and the role stands in for
+// scope. Times are Unix seconds.
+type IntrospectTokenResponse struct {
+	Active    bool     `json:"active"`
+	TokenType string   `json:"token_type,omitempty"`
+	Sub       string   `json:"sub,omitempty"`
+	Email     string   `json:"email,omitempty"`
+	Role      string   `json:"role,omitempty"`
+	Exp       int64    `json:"exp,omitempty"`
+	Iat       int64    `json:"iat,omitempty"`
+	JTI       string   `json:"jti,omitempty"`
+	Iss       string   `json:"iss,omitempty"`
+	Aud       []string `json:"aud,omitempty"`
+	// Act is set for impersonation tokens and names the acting admin.
+	Act *TokenActor `json:"act,omitempty"`
+}
+
+// TokenActor is the party acting on behalf of a token's subject.
+type TokenActor struct {
+	Sub string `json:"sub"`
+}
+
+// LogoutRequest names the refresh token to revoke. Clients that received
+// their tokens as cookies may omit it.
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// UserEvent is published to the user events exchange when a user is created,
+// updated or deleted. Deleted events only carry the user ID.
+type UserEvent struct {
+	Type       string    `json:"type"`
+	UserID     string    `json:"user_id"`
+	Email      string    `json:"email,omitempty"`
+	Username   string    `json:"username,omitempty"`
+	Role       string    `json:"role,omitempty"`
+	Status     string    `json:"status,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
 }
@@ -0,0 +1,47 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session is one login of a user, on one device. Its refresh token rotates
+// on every refresh; TokenID is the jti of the only one currently accepted.
+type Session struct {
+	ID        string `json:"id"`
+	UserID    string `json:"user_id"`
+	TokenID   string `json:"token_id"`
+	UserAgent string `json:"user_agent,omitempty"`
+	IPAddress string `json:"ip_address,omitempty"`
+	// CreatedAt is when the user logged in and LastUsedAt when the session
+	// last issued a refresh token.
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+func NewSession(userID, userAgent, ipAddress string) *Session {
+	now := time.Now()
+	return &Session{
+		ID:         uuid.New().String(),
+		UserID:     userID,
+		UserAgent:  userAgent,
+		IPAddress:  ipAddress,
+		CreatedAt:  now,
+		LastUsedAt: now,
+	}
+}
+
+// Rotate makes tokenID the session's current refresh token, valid for
+// lifetime from now.
+func (s *Session) Rotate(tokenID string, lifetime time.Duration) {
+	now := time.Now()
+	s.TokenID = tokenID
+	s.LastUsedAt = now
+	s.ExpiresAt = now.Add(lifetime)
+}
+
+func (s *Session) IsExpired(now time.Time) bool {
+	return !now.Before(s.ExpiresAt)
+}
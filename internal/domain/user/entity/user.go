@@ -7,16 +7,31 @@ import (
 )
 
 type User struct {
-	ID        string     `json:"id"`
-	Email     string     `json:"email"`
-	Username  string     `json:"username"`
-	Password  string     `json:"-"` // Never expose password in JSON
-	FullName  string     `json:"full_name"`
-	Role      string     `json:"role"`
-	Status    string     `json:"status"`
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
-	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	ID       string `json:"id"`
+	Email    string `json:"email"`
+	Username string `json:"username"`
+	Password string `json:"-"` // Never expose password in JSON
+	FullName string `json:"full_name"`
+	Role     string `json:"role"`
+	Status   string `json:"status"`
+	// TOTPSecret holds the encrypted TOTP secret; it is set when enrollment
+	// starts and MFAEnabled flips once the first code is verified.
+	TOTPSecret string `json:"-"`
+	MFAEnabled bool   `json:"mfa_enabled"`
+	AvatarURL  string `json:"avatar_url,omitempty"`
+	// Timezone is an IANA zone name such as "Asia/Jakarta" and Locale a
+	// BCP-47 language tag such as "id-ID"; both are empty until the user
+	// sets them.
+	Timezone string `json:"timezone,omitempty"`
+	Locale   string `json:"locale,omitempty"`
+	// LastLoginAt is nil until the user first completes a login.
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+	// Version is incremented on every write and guards updates against
+	// concurrent modification.
+	Version int `json:"version"`
 }
 
 func NewUser(email, username, password, fullName, role string) *User {
@@ -31,6 +46,7 @@ func NewUser(email, username, password, fullName, role string) *User {
 		Status:    "active",
 		CreatedAt: now,
 		UpdatedAt: now,
+		Version:   1,
 	}
 }
 
@@ -49,6 +65,12 @@ func (u *User) MarkAsDeleted() {
 	u.UpdatedAt = now
 }
 
+func (u *User) Restore() {
+	u.DeletedAt = nil
+	u.Status = "active"
+	u.UpdatedAt = time.Now()
+}
+
 func (u *User) UpdateProfile(fullName string) {
 	if fullName != "" {
 		u.FullName = fullName
@@ -56,11 +78,68 @@ func (u *User) UpdateProfile(fullName string) {
 	u.UpdatedAt = time.Now()
 }
 
+// SetPreferences changes the user's timezone and locale, leaving empty values
+// unchanged.
+func (u *User) SetPreferences(timezone, locale string) {
+	if timezone != "" {
+		u.Timezone = timezone
+	}
+	if locale != "" {
+		u.Locale = locale
+	}
+	u.UpdatedAt = time.Now()
+}
+
+// SetFullName changes the user's full name. Unlike UpdateProfile, an empty
+// name clears it.
+func (u *User) SetFullName(fullName string) {
+	u.FullName = fullName
+	u.UpdatedAt = time.Now()
+}
+
+// SetTimezone and SetLocale change one preference each; an empty value
+// clears it.
+func (u *User) SetTimezone(timezone string) {
+	u.Timezone = timezone
+	u.UpdatedAt = time.Now()
+}
+
+func (u *User) SetLocale(locale string) {
+	u.Locale = locale
+	u.UpdatedAt = time.Now()
+}
+
+func (u *User) ChangeEmail(email string) {
+	u.Email = email
+	u.UpdatedAt = time.Now()
+}
+
+func (u *User) ChangeUsername(username string) {
+	u.Username = username
+	u.UpdatedAt = time.Now()
+}
+
+func (u *User) ChangeAvatar(avatarURL string) {
+	u.AvatarURL = avatarURL
+	u.UpdatedAt = time.Now()
+}
+
 func (u *User) UpdatePassword(hashedPassword string) {
 	u.Password = hashedPassword
 	u.UpdatedAt = time.Now()
 }
 
+func (u *User) SetTOTPSecret(encryptedSecret string) {
+	u.TOTPSecret = encryptedSecret
+	u.MFAEnabled = false
+	u.UpdatedAt = time.Now()
+}
+
+func (u *User) EnableMFA() {
+	u.MFAEnabled = true
+	u.UpdatedAt = time.Now()
+}
+
 func (u *User) ChangeStatus(status string) {
 	u.Status = status
 	u.UpdatedAt = time.Now()
@@ -0,0 +1,429 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/entity"
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/database"
+	"github.com/TubagusAldiMY/go-template/internal/shared/constants"
+	"github.com/TubagusAldiMY/go-template/internal/shared/utils"
+	"github.com/TubagusAldiMY/go-template/pkg/crypto"
+	"github.com/TubagusAldiMY/go-template/pkg/logger"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+	"golang.org/x/sync/singleflight"
+)
+
+// Cache is the key-value store used by CachedUserRepository.
+type Cache interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+	DeleteMany(ctx context.Context, keys ...string) error
+	Pipeline(ctx context.Context, fn func(pipe redis.Pipeliner) error) error
+}
+
+// CachedUserRepository decorates a UserRepository with a read-through cache
+// for single-user lookups. Writes go to the wrapped repository and then
+// invalidate every key derived from the affected user. Cache fills and the
+// lookups writes make read from the primary, so a lagging read replica can
+// neither put a stale user back in the cache nor fail an update.
+//
+// Cached users include the password hash. Entries are plaintext JSON unless
+// WithCacheEncryption is given, which encrypts them and keys emails by their
+// blind index, so Redis holds no personal data in the clear.
+type CachedUserRepository struct {
+	next   UserRepository
+	cache  Cache
+	ttl    time.Duration
+	fields *crypto.FieldCipher
+
+	// loads collapses concurrent misses for the same key into one query.
+	loads singleflight.Group
+}
+
+// warmPageSize is how many users Warm loads per query.
+const warmPageSize = 100
+
+// CacheOption configures a CachedUserRepository.
+type CacheOption func(*CachedUserRepository)
+
+// WithCacheEncryption encrypts cached users with fields and keys the email
+// lookups by the email's blind index rather than the email itself.
+func WithCacheEncryption(fields *crypto.FieldCipher) CacheOption {
+	return func(r *CachedUserRepository) {
+		r.fields = fields
+	}
+}
+
+func NewCachedUserRepository(next UserRepository, cache Cache, opts ...CacheOption) *CachedUserRepository {
+	r := &CachedUserRepository{
+		next:  next,
+		cache: cache,
+		ttl:   time.Duration(constants.CacheTTLMedium) * time.Second,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// cachedUser is the cache representation of entity.User. Unlike the entity it
+// keeps the password hash and the encrypted TOTP secret so cached lookups can
+// still authenticate a login.
+type cachedUser struct {
+	ID          string     `json:"id"`
+	Email       string     `json:"email"`
+	Username    string     `json:"username"`
+	Password    string     `json:"password"`
+	FullName    string     `json:"full_name"`
+	Role        string     `json:"role"`
+	Status      string     `json:"status"`
+	TOTPSecret  string     `json:"totp_secret,omitempty"`
+	MFAEnabled  bool       `json:"mfa_enabled"`
+	AvatarURL   string     `json:"avatar_url,omitempty"`
+	Timezone    string     `json:"timezone,omitempty"`
+	Locale      string     `json:"locale,omitempty"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+	Version     int        `json:"version"`
+}
+
+func toCachedUser(u *entity.User) cachedUser {
+	return cachedUser{
+		ID:          u.ID,
+		Email:       u.Email,
+		Username:    u.Username,
+		Password:    u.Password,
+		FullName:    u.FullName,
+		Role:        u.Role,
+		Status:      u.Status,
+		TOTPSecret:  u.TOTPSecret,
+		MFAEnabled:  u.MFAEnabled,
+		AvatarURL:   u.AvatarURL,
+		Timezone:    u.Timezone,
+		Locale:      u.Locale,
+		LastLoginAt: u.LastLoginAt,
+		CreatedAt:   u.CreatedAt,
+		UpdatedAt:   u.UpdatedAt,
+		DeletedAt:   u.DeletedAt,
+		Version:     u.Version,
+	}
+}
+
+func (c cachedUser) toEntity() *entity.User {
+	return &entity.User{
+		ID:          c.ID,
+		Email:       c.Email,
+		Username:    c.Username,
+		Password:    c.Password,
+		FullName:    c.FullName,
+		Role:        c.Role,
+		Status:      c.Status,
+		TOTPSecret:  c.TOTPSecret,
+		MFAEnabled:  c.MFAEnabled,
+		AvatarURL:   c.AvatarURL,
+		Timezone:    c.Timezone,
+		Locale:      c.Locale,
+		LastLoginAt: c.LastLoginAt,
+		CreatedAt:   c.CreatedAt,
+		UpdatedAt:   c.UpdatedAt,
+		DeletedAt:   c.DeletedAt,
+		Version:     c.Version,
+	}
+}
+
+func userIDKey(id string) string {
+	return constants.CacheKeyUserPrefix + id
+}
+
+// emailKey is the key of the user with email, which is its blind index under
+// cache encryption.
+func (r *CachedUserRepository) emailKey(email string) string {
+	if r.fields != nil {
+		return constants.CacheKeyUserEmailPrefix + r.fields.BlindIndex(utils.NormalizeEmail(email))
+	}
+	return constants.CacheKeyUserEmailPrefix + email
+}
+
+func userUsernameKey(username string) string {
+	return constants.CacheKeyUserUsernamePrefix + username
+}
+
+func (r *CachedUserRepository) userKeys(u *entity.User) []string {
+	return []string{userIDKey(u.ID), r.emailKey(u.Email), userUsernameKey(u.Username)}
+}
+
+func (r *CachedUserRepository) Create(ctx context.Context, user *entity.User) error {
+	return r.next.Create(ctx, user)
+}
+
+func (r *CachedUserRepository) CreateBatch(ctx context.Context, users []*entity.User) error {
+	return r.next.CreateBatch(ctx, users)
+}
+
+func (r *CachedUserRepository) GetByID(ctx context.Context, id string) (*entity.User, error) {
+	return r.getOrLoad(ctx, userIDKey(id), func(ctx context.Context) (*entity.User, error) {
+		return r.next.GetByID(ctx, id)
+	})
+}
+
+// GetByIDIncludingDeleted is not cached; it only backs rare admin operations.
+func (r *CachedUserRepository) GetByIDIncludingDeleted(ctx context.Context, id string) (*entity.User, error) {
+	return r.next.GetByIDIncludingDeleted(ctx, id)
+}
+
+func (r *CachedUserRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
+	return r.getOrLoad(ctx, r.emailKey(email), func(ctx context.Context) (*entity.User, error) {
+		return r.next.GetByEmail(ctx, email)
+	})
+}
+
+func (r *CachedUserRepository) GetByUsername(ctx context.Context, username string) (*entity.User, error) {
+	return r.getOrLoad(ctx, userUsernameKey(username), func(ctx context.Context) (*entity.User, error) {
+		return r.next.GetByUsername(ctx, username)
+	})
+}
+
+func (r *CachedUserRepository) Update(ctx context.Context, user *entity.User) error {
+	// Look up the stored row first so keys for a previous email or username
+	// are invalidated too, not just the ones derived from the new values.
+	previous, err := r.next.GetByID(database.ContextWithPrimary(ctx), user.ID)
+	if err != nil {
+		return err
+	}
+
+	if err := r.next.Update(ctx, user); err != nil {
+		return err
+	}
+
+	r.invalidate(ctx, append(r.userKeys(previous), r.userKeys(user)...)...)
+
+	return nil
+}
+
+// UpdateLastLogin evicts the user if cached. The keys to drop are taken from
+// the cached entry itself, avoiding a lookup of the stored row on every login.
+func (r *CachedUserRepository) UpdateLastLogin(ctx context.Context, id string, t time.Time) error {
+	if err := r.next.UpdateLastLogin(ctx, id, t); err != nil {
+		return err
+	}
+
+	if cached, ok := r.get(ctx, userIDKey(id)); ok {
+		r.invalidate(ctx, r.userKeys(cached)...)
+	}
+
+	return nil
+}
+
+func (r *CachedUserRepository) Delete(ctx context.Context, id string) error {
+	user, err := r.next.GetByID(database.ContextWithPrimary(ctx), id)
+	if err != nil {
+		return err
+	}
+
+	if err := r.next.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	r.invalidate(ctx, r.userKeys(user)...)
+
+	return nil
+}
+
+func (r *CachedUserRepository) Restore(ctx context.Context, id string) error {
+	user, err := r.next.GetByIDIncludingDeleted(database.ContextWithPrimary(ctx), id)
+	if err != nil {
+		return err
+	}
+
+	if err := r.next.Restore(ctx, id); err != nil {
+		return err
+	}
+
+	r.invalidate(ctx, r.userKeys(user)...)
+
+	return nil
+}
+
+// PurgeDeleted needs no invalidation: soft-deleted users were already evicted
+// by Delete and are never cached again.
+func (r *CachedUserRepository) PurgeDeleted(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	return r.next.PurgeDeleted(ctx, cutoff, limit)
+}
+
+func (r *CachedUserRepository) List(ctx context.Context, params ListParams) ([]*entity.User, int64, error) {
+	return r.next.List(ctx, params)
+}
+
+func (r *CachedUserRepository) ListByCursor(ctx context.Context, params ListParams, cursor string) ([]*entity.User, string, error) {
+	return r.next.ListByCursor(ctx, params, cursor)
+}
+
+func (r *CachedUserRepository) Export(ctx context.Context, params ListParams, fn func(user *entity.User) error) error {
+	return r.next.Export(ctx, params, fn)
+}
+
+func (r *CachedUserRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
+	return r.next.ExistsByEmail(ctx, email)
+}
+
+func (r *CachedUserRepository) ExistsByUsername(ctx context.Context, username string) (bool, error) {
+	return r.next.ExistsByUsername(ctx, username)
+}
+
+func (r *CachedUserRepository) CountByStatus(ctx context.Context) (map[string]int64, error) {
+	return r.next.CountByStatus(ctx)
+}
+
+// Warm loads up to limit active users, most recently updated first, into the
+// cache so the first lookups after a deploy don't all miss. Users record no
+// activity of their own, so their last update stands in for it. It returns
+// how many users were cached; users that could not be written are logged and
+// skipped, while a failed query or a cancelled ctx stops warming early.
+func (r *CachedUserRepository) Warm(ctx context.Context, limit int) (int, error) {
+	warmed, seen := 0, 0
+	for page := 1; seen < limit; page++ {
+		users, _, err := r.next.List(ctx, ListParams{
+			Page:      page,
+			PageSize:  warmPageSize,
+			Status:    constants.UserStatusActive,
+			SortBy:    "updated_at",
+			SortOrder: "desc",
+		})
+		if err != nil {
+			return warmed, err
+		}
+
+		for _, user := range users {
+			if err := ctx.Err(); err != nil {
+				return warmed, err
+			}
+			if seen == limit {
+				break
+			}
+			seen++
+			if r.store(ctx, user) {
+				warmed++
+			}
+		}
+
+		if len(users) < warmPageSize {
+			break
+		}
+	}
+
+	return warmed, nil
+}
+
+// getOrLoad returns the user cached under key, falling back to load on a miss
+// or on any cache failure. Loaded users are cached under all of their keys.
+// Concurrent misses for the same key share a single load, so a burst of
+// lookups of a cold user sends one query. It reads from the primary, since a
+// replica may not have applied the write that evicted the user yet. Inside a
+// transaction the cache is bypassed so uncommitted rows are never cached and
+// reads see the transaction's own writes.
+func (r *CachedUserRepository) getOrLoad(ctx context.Context, key string, load func(ctx context.Context) (*entity.User, error)) (*entity.User, error) {
+	if _, inTx := database.TxFromContext(ctx); inTx {
+		return load(ctx)
+	}
+
+	if user, ok := r.get(ctx, key); ok {
+		return user, nil
+	}
+
+	// The shared load is not cancelled by whichever caller happens to start it.
+	result, err, _ := r.loads.Do(key, func() (interface{}, error) {
+		loadCtx := database.ContextWithPrimary(context.WithoutCancel(ctx))
+		user, err := load(loadCtx)
+		if err != nil {
+			return nil, err
+		}
+		r.store(loadCtx, user)
+		return user, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Every caller gets its own copy, which it may modify.
+	user := *result.(*entity.User)
+	return &user, nil
+}
+
+func (r *CachedUserRepository) get(ctx context.Context, key string) (*entity.User, bool) {
+	data, err := r.cache.Get(ctx, key)
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			logger.FromContext(ctx).Warn("failed to read user from cache", zap.String("key", key), zap.Error(err))
+		}
+		return nil, false
+	}
+
+	if r.fields != nil {
+		if data, err = r.fields.Decrypt(data); err != nil {
+			logger.FromContext(ctx).Warn("failed to decrypt cached user", zap.String("key", key), zap.Error(err))
+			r.invalidate(ctx, key)
+			return nil, false
+		}
+	}
+
+	var cached cachedUser
+	if err := json.Unmarshal([]byte(data), &cached); err != nil {
+		logger.FromContext(ctx).Warn("failed to decode cached user", zap.String("key", key), zap.Error(err))
+		r.invalidate(ctx, key)
+		return nil, false
+	}
+
+	// Entries cached before users were versioned would make every update
+	// fail the version check, so treat them as misses.
+	if cached.Version == 0 {
+		return nil, false
+	}
+
+	return cached.toEntity(), true
+}
+
+// store caches user under all of its keys and reports whether it succeeded.
+// Failures are logged.
+func (r *CachedUserRepository) store(ctx context.Context, user *entity.User) bool {
+	encoded, err := json.Marshal(toCachedUser(user))
+	if err != nil {
+		logger.FromContext(ctx).Warn("failed to encode user for cache", zap.String("user_id", user.ID), zap.Error(err))
+		return false
+	}
+
+	data := string(encoded)
+	if r.fields != nil {
+		if data, err = r.fields.Encrypt(data); err != nil {
+			logger.FromContext(ctx).Warn("failed to encrypt user for cache", zap.String("user_id", user.ID), zap.Error(err))
+			return false
+		}
+	}
+
+	keys := r.userKeys(user)
+	err = r.cache.Pipeline(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			pipe.Set(ctx, key, data, r.ttl)
+		}
+		return nil
+	})
+	if err != nil {
+		logger.FromContext(ctx).Warn("failed to write user to cache", zap.Strings("keys", keys), zap.Error(err))
+		return false
+	}
+
+	return true
+}
+
+// invalidate drops keys in one atomic round-trip so no derived key of a
+// changed user survives a partial failure.
+func (r *CachedUserRepository) invalidate(ctx context.Context, keys ...string) {
+	if err := r.cache.DeleteMany(ctx, keys...); err != nil {
+		logger.FromContext(ctx).Warn("failed to invalidate user cache", zap.Strings("keys", keys), zap.Error(err))
+	}
+}
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/entity"
+	sharedErrors "github.com/TubagusAldiMY/go-template/internal/shared/errors"
+)
+
+// listCursor is the keyset position encoded into the opaque cursor token.
+type listCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        string    `json:"id"`
+}
+
+func encodeCursor(user *entity.User) string {
+	data, err := json.Marshal(listCursor{CreatedAt: user.CreatedAt, ID: user.ID})
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(token string) (*listCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, sharedErrors.ErrInvalidCursor
+	}
+
+	var c listCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == "" || c.CreatedAt.IsZero() {
+		return nil, sharedErrors.ErrInvalidCursor
+	}
+
+	return &c, nil
+}
@@ -0,0 +1,41 @@
+package repository
+
+import (
+	"errors"
+
+	sharedErrors "github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const (
+	pgUniqueViolation = "23505"
+
+	// Unique indexes on the email and username of users that are not
+	// deleted. They keep the default names Postgres gave the UNIQUE
+	// constraints they replaced.
+	constraintUsersEmail    = "users_email_key"
+	constraintUsersUsername = "users_username_key"
+
+	// Unique index on the blind index of encrypted emails of users that are
+	// not deleted.
+	constraintUsersEmailIndex = "users_email_index_key"
+)
+
+// uniqueViolation translates a unique-constraint failure on users into the
+// matching domain error. It returns nil for any other error, including
+// violations of other constraints, so callers fall back to wrapping err.
+func uniqueViolation(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != pgUniqueViolation {
+		return nil
+	}
+
+	switch pgErr.ConstraintName {
+	case constraintUsersEmail, constraintUsersEmailIndex:
+		return sharedErrors.ErrEmailAlreadyExists
+	case constraintUsersUsername:
+		return sharedErrors.ErrUsernameAlreadyExists
+	default:
+		return nil
+	}
+}
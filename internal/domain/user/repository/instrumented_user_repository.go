@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/entity"
+	sharedErrors "github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/TubagusAldiMY/go-template/pkg/metrics"
+)
+
+// InstrumentedUserRepository decorates a UserRepository, recording the
+// duration and outcome of every call in operations under the method's name,
+// such as get_by_id. A lookup that finds no user still succeeds. Export's
+// duration includes the time its callback takes.
+type InstrumentedUserRepository struct {
+	next       UserRepository
+	operations *metrics.Operations
+}
+
+func NewInstrumentedUserRepository(next UserRepository, operations *metrics.Operations) *InstrumentedUserRepository {
+	return &InstrumentedUserRepository{next: next, operations: operations}
+}
+
+func (r *InstrumentedUserRepository) observe(operation string, start time.Time, err error) {
+	if errors.Is(err, sharedErrors.ErrUserNotFound) {
+		err = nil
+	}
+	r.operations.Observe(operation, start, err)
+}
+
+func (r *InstrumentedUserRepository) Create(ctx context.Context, user *entity.User) error {
+	start := time.Now()
+	err := r.next.Create(ctx, user)
+	r.observe("create", start, err)
+	return err
+}
+
+func (r *InstrumentedUserRepository) CreateBatch(ctx context.Context, users []*entity.User) error {
+	start := time.Now()
+	err := r.next.CreateBatch(ctx, users)
+	r.observe("create_batch", start, err)
+	return err
+}
+
+func (r *InstrumentedUserRepository) GetByID(ctx context.Context, id string) (*entity.User, error) {
+	start := time.Now()
+	user, err := r.next.GetByID(ctx, id)
+	r.observe("get_by_id", start, err)
+	return user, err
+}
+
+func (r *InstrumentedUserRepository) GetByIDIncludingDeleted(ctx context.Context, id string) (*entity.User, error) {
+	start := time.Now()
+	user, err := r.next.GetByIDIncludingDeleted(ctx, id)
+	r.observe("get_by_id_including_deleted", start, err)
+	return user, err
+}
+
+func (r *InstrumentedUserRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
+	start := time.Now()
+	user, err := r.next.GetByEmail(ctx, email)
+	r.observe("get_by_email", start, err)
+	return user, err
+}
+
+func (r *InstrumentedUserRepository) GetByUsername(ctx context.Context, username string) (*entity.User, error) {
+	start := time.Now()
+	user, err := r.next.GetByUsername(ctx, username)
+	r.observe("get_by_username", start, err)
+	return user, err
+}
+
+func (r *InstrumentedUserRepository) Update(ctx context.Context, user *entity.User) error {
+	start := time.Now()
+	err := r.next.Update(ctx, user)
+	r.observe("update", start, err)
+	return err
+}
+
+func (r *InstrumentedUserRepository) UpdateLastLogin(ctx context.Context, id string, t time.Time) error {
+	start := time.Now()
+	err := r.next.UpdateLastLogin(ctx, id, t)
+	r.observe("update_last_login", start, err)
+	return err
+}
+
+func (r *InstrumentedUserRepository) Delete(ctx context.Context, id string) error {
+	start := time.Now()
+	err := r.next.Delete(ctx, id)
+	r.observe("delete", start, err)
+	return err
+}
+
+func (r *InstrumentedUserRepository) Restore(ctx context.Context, id string) error {
+	start := time.Now()
+	err := r.next.Restore(ctx, id)
+	r.observe("restore", start, err)
+	return err
+}
+
+func (r *InstrumentedUserRepository) PurgeDeleted(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	start := time.Now()
+	purged, err := r.next.PurgeDeleted(ctx, cutoff, limit)
+	r.observe("purge_deleted", start, err)
+	return purged, err
+}
+
+func (r *InstrumentedUserRepository) List(ctx context.Context, params ListParams) ([]*entity.User, int64, error) {
+	start := time.Now()
+	users, total, err := r.next.List(ctx, params)
+	r.observe("list", start, err)
+	return users, total, err
+}
+
+func (r *InstrumentedUserRepository) ListByCursor(ctx context.Context, params ListParams, cursor string) ([]*entity.User, string, error) {
+	start := time.Now()
+	users, nextCursor, err := r.next.ListByCursor(ctx, params, cursor)
+	r.observe("list_by_cursor", start, err)
+	return users, nextCursor, err
+}
+
+func (r *InstrumentedUserRepository) Export(ctx context.Context, params ListParams, fn func(user *entity.User) error) error {
+	start := time.Now()
+	err := r.next.Export(ctx, params, fn)
+	r.observe("export", start, err)
+	return err
+}
+
+func (r *InstrumentedUserRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
+	start := time.Now()
+	exists, err := r.next.ExistsByEmail(ctx, email)
+	r.observe("exists_by_email", start, err)
+	return exists, err
+}
+
+func (r *InstrumentedUserRepository) ExistsByUsername(ctx context.Context, username string) (bool, error) {
+	start := time.Now()
+	exists, err := r.next.ExistsByUsername(ctx, username)
+	r.observe("exists_by_username", start, err)
+	return exists, err
+}
+
+func (r *InstrumentedUserRepository) CountByStatus(ctx context.Context) (map[string]int64, error) {
+	start := time.Now()
+	counts, err := r.next.CountByStatus(ctx)
+	r.observe("count_by_status", start, err)
+	return counts, err
+}
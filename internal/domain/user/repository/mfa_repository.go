@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/internal/shared/constants"
+	"github.com/redis/go-redis/v9"
+)
+
+// totpStepTTL outlives the three time steps a TOTP code is accepted in, so a
+// recorded step is kept for as long as its code could be replayed.
+const totpStepTTL = 2 * time.Minute
+
+// MFARepository keeps the state that limits guessing and replaying TOTP
+// codes. Each call is one atomic step, so concurrent requests cannot slip
+// past the limits.
+type MFARepository interface {
+	// CountAttempt counts an attempt at the MFA challenge token and returns
+	// the number of attempts so far. The count expires after ttl.
+	CountAttempt(ctx context.Context, token string, ttl time.Duration) (int64, error)
+	// UseTOTPStep records step as the time step of the last TOTP code the
+	// user gave. It returns false without recording anything if the user
+	// already gave a code of that step or a later one.
+	UseTOTPStep(ctx context.Context, userID string, step int64) (bool, error)
+}
+
+// MFACache is the Redis access used by RedisMFARepository.
+type MFACache interface {
+	Pipeline(ctx context.Context, fn func(pipe redis.Pipeliner) error) error
+}
+
+type RedisMFARepository struct {
+	cache MFACache
+}
+
+func NewRedisMFARepository(cache MFACache) *RedisMFARepository {
+	return &RedisMFARepository{cache: cache}
+}
+
+// countAttemptScript increments the counter KEYS[1] and, when it was just
+// created, expires it in ARGV[1] milliseconds.
+var countAttemptScript = redis.NewScript(`
+local attempts = redis.call("INCR", KEYS[1])
+if attempts == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return attempts
+`)
+
+func (r *RedisMFARepository) CountAttempt(ctx context.Context, token string, ttl time.Duration) (int64, error) {
+	var cmd *redis.Cmd
+	err := r.cache.Pipeline(ctx, func(pipe redis.Pipeliner) error {
+		cmd = countAttemptScript.Eval(ctx, pipe, []string{constants.CacheKeyMFAAttemptsPrefix + token}, ttl.Milliseconds())
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count mfa attempt: %w", err)
+	}
+
+	attempts, err := cmd.Int64()
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode mfa attempts: %w", err)
+	}
+	return attempts, nil
+}
+
+// useStepScript stores the time step ARGV[1] in KEYS[1], expiring in ARGV[2]
+// milliseconds, unless the stored step is not older. It returns 1 when it
+// stored the step and 0 otherwise.
+var useStepScript = redis.NewScript(`
+local last = tonumber(redis.call("GET", KEYS[1]))
+if last and last >= tonumber(ARGV[1]) then
+	return 0
+end
+redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+return 1
+`)
+
+func (r *RedisMFARepository) UseTOTPStep(ctx context.Context, userID string, step int64) (bool, error) {
+	var cmd *redis.Cmd
+	err := r.cache.Pipeline(ctx, func(pipe redis.Pipeliner) error {
+		cmd = useStepScript.Eval(ctx, pipe, []string{constants.CacheKeyTOTPStepPrefix + userID}, step, totpStepTTL.Milliseconds())
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to record totp step: %w", err)
+	}
+
+	used, _ := cmd.Int()
+	return used == 1, nil
+}
@@ -4,283 +4,622 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/TubagusAldiMY/go-template/internal/domain/user/entity"
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/database"
 	sharedErrors "github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/TubagusAldiMY/go-template/internal/shared/utils"
+	"github.com/TubagusAldiMY/go-template/pkg/crypto"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// userTable maps entity.User onto the users table.
+var userTable = database.Table[entity.User]{
+	Name:   "users",
+	Entity: "user",
+	Columns: []string{
+		"id", "email", "username", "password", "full_name", "role", "status",
+		"created_at", "updated_at", "deleted_at", "version",
+		"COALESCE(totp_secret, '')", "mfa_enabled", "COALESCE(avatar_url, '')", "last_login_at",
+		"COALESCE(timezone, '')", "COALESCE(locale, '')",
+	},
+	Scan: func(u *entity.User) []any {
+		return []any{
+			&u.ID, &u.Email, &u.Username, &u.Password, &u.FullName, &u.Role, &u.Status,
+			&u.CreatedAt, &u.UpdatedAt, &u.DeletedAt, &u.Version,
+			&u.TOTPSecret, &u.MFAEnabled, &u.AvatarURL, &u.LastLoginAt,
+			&u.Timezone, &u.Locale,
+		}
+	},
+	Insert: []string{"id", "email", "username", "password", "full_name", "role", "status", "created_at", "updated_at", "version", "email_index"},
+	// Values writes the email and full name in plaintext, without a blind
+	// index; the repository writes through insertValues instead.
+	Values: func(u *entity.User) []any {
+		return userValues(u, nil)
+	},
+	SoftDelete: true,
+	NotFound:   sharedErrors.ErrUserNotFound,
+	MapError:   uniqueViolation,
+}
+
+func userValues(u *entity.User, emailIndex any) []any {
+	return []any{u.ID, u.Email, u.Username, u.Password, u.FullName, u.Role, u.Status, u.CreatedAt, u.UpdatedAt, u.Version, emailIndex}
+}
+
 type PostgresUserRepository struct {
-	db *pgxpool.Pool
+	store *database.Store[entity.User]
+	// reads runs the read-only queries; it is store unless a read pool is
+	// configured.
+	reads        *database.Store[entity.User]
+	queryTimeout time.Duration
+	fields       *crypto.FieldCipher
 }
 
-func NewPostgresUserRepository(db *pgxpool.Pool) *PostgresUserRepository {
-	return &PostgresUserRepository{db: db}
+// Option configures a PostgresUserRepository.
+type Option func(*PostgresUserRepository)
+
+// WithFieldEncryption encrypts users' emails and full names at rest with
+// fields and looks emails up by their blind index. Rows written without it
+// are still read, and are encrypted when they are next updated.
+func WithFieldEncryption(fields *crypto.FieldCipher) Option {
+	return func(r *PostgresUserRepository) {
+		r.fields = fields
+	}
 }
 
-func (r *PostgresUserRepository) Create(ctx context.Context, user *entity.User) error {
-	query := `
-		INSERT INTO users (id, email, username, password, full_name, role, status, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-	`
+// WithReadPool runs lookups, listings and counts on pool, such as a read
+// replica's, while writes stay on the primary. Inside a transaction every
+// query runs on the transaction, so reads there still see its writes, and a
+// ctx from database.ContextWithPrimary reads from the primary.
+func WithReadPool(pool *pgxpool.Pool) Option {
+	return func(r *PostgresUserRepository) {
+		r.reads = database.NewStore(pool, r.queryTimeout, userTable)
+	}
+}
 
-	_, err := r.db.Exec(ctx, query,
-		user.ID,
-		user.Email,
-		user.Username,
-		user.Password,
-		user.FullName,
-		user.Role,
-		user.Status,
-		user.CreatedAt,
-		user.UpdatedAt,
-	)
+// NewPostgresUserRepository returns a repository whose methods each run under
+// queryTimeout; zero leaves deadlines to the caller.
+func NewPostgresUserRepository(db *pgxpool.Pool, queryTimeout time.Duration, opts ...Option) *PostgresUserRepository {
+	store := database.NewStore(db, queryTimeout, userTable)
+	r := &PostgresUserRepository{
+		store:        store,
+		reads:        store,
+		queryTimeout: queryTimeout,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
 
+// reader returns the store reads under ctx run on.
+func (r *PostgresUserRepository) reader(ctx context.Context) *database.Store[entity.User] {
+	if database.PrimaryRequested(ctx) {
+		return r.store
+	}
+	return r.reads
+}
+
+// insertValues returns the userTable.Insert values of user, with its email
+// and full name encrypted under field encryption.
+func (r *PostgresUserRepository) insertValues(user *entity.User) ([]any, error) {
+	if r.fields == nil {
+		return userValues(user, nil), nil
+	}
+
+	sealed, err := r.seal(user)
 	if err != nil {
-		return fmt.Errorf("failed to create user: %w", err)
+		return nil, err
 	}
+	return userValues(sealed, r.emailIndex(user.Email)), nil
+}
 
-	return nil
+// seal returns a copy of user whose email and full name are encrypted.
+func (r *PostgresUserRepository) seal(user *entity.User) (*entity.User, error) {
+	sealed := *user
+
+	var err error
+	if sealed.Email, err = r.fields.Encrypt(user.Email); err != nil {
+		return nil, fmt.Errorf("failed to encrypt email: %w", err)
+	}
+	if sealed.FullName, err = r.fields.Encrypt(user.FullName); err != nil {
+		return nil, fmt.Errorf("failed to encrypt full name: %w", err)
+	}
+
+	return &sealed, nil
 }
 
-func (r *PostgresUserRepository) GetByID(ctx context.Context, id string) (*entity.User, error) {
-	query := `
-		SELECT id, email, username, password, full_name, role, status, created_at, updated_at, deleted_at
-		FROM users
-		WHERE id = $1 AND deleted_at IS NULL
-	`
+// open decrypts the email and full name of a user read from the table.
+func (r *PostgresUserRepository) open(user *entity.User) error {
+	if r.fields == nil {
+		return nil
+	}
 
-	user := &entity.User{}
-	err := r.db.QueryRow(ctx, query, id).Scan(
-		&user.ID,
-		&user.Email,
-		&user.Username,
-		&user.Password,
-		&user.FullName,
-		&user.Role,
-		&user.Status,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-		&user.DeletedAt,
-	)
+	var err error
+	if user.Email, err = r.fields.Decrypt(user.Email); err != nil {
+		return fmt.Errorf("failed to decrypt email of user %s: %w", user.ID, err)
+	}
+	if user.FullName, err = r.fields.Decrypt(user.FullName); err != nil {
+		return fmt.Errorf("failed to decrypt full name of user %s: %w", user.ID, err)
+	}
 
+	return nil
+}
+
+// opened decrypts the user a lookup returned.
+func (r *PostgresUserRepository) opened(user *entity.User, err error) (*entity.User, error) {
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, sharedErrors.ErrUserNotFound
+		return nil, err
+	}
+	if err := r.open(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// openAll decrypts the users a listing returned.
+func (r *PostgresUserRepository) openAll(users []*entity.User) error {
+	for _, user := range users {
+		if err := r.open(user); err != nil {
+			return err
 		}
-		return nil, fmt.Errorf("failed to get user by id: %w", err)
 	}
+	return nil
+}
 
-	return user, nil
+// emailIndex returns the blind index of email, normalized first so that the
+// index does not depend on how the address was capitalized.
+func (r *PostgresUserRepository) emailIndex(email string) string {
+	return r.fields.BlindIndex(utils.NormalizeEmail(email))
 }
 
-func (r *PostgresUserRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
-	query := `
-		SELECT id, email, username, password, full_name, role, status, created_at, updated_at, deleted_at
-		FROM users
-		WHERE email = $1 AND deleted_at IS NULL
-	`
+// emailFilter matches the user with email. Under field encryption it
+// matches the blind index, or the plaintext email of rows not yet encrypted.
+func (r *PostgresUserRepository) emailFilter(email string) *database.Filter {
+	filter := r.store.Filter()
+	if r.fields == nil {
+		return filter.Where("email = ?", email)
+	}
 
-	user := &entity.User{}
-	err := r.db.QueryRow(ctx, query, email).Scan(
-		&user.ID,
-		&user.Email,
-		&user.Username,
-		&user.Password,
-		&user.FullName,
-		&user.Role,
-		&user.Status,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-		&user.DeletedAt,
-	)
+	emailIndex := r.emailIndex(email)
+	return filter.Or(func(g *database.Filter) {
+		g.Where("email_index = ?", emailIndex)
+		g.Where("email_index IS NULL AND email = ?", email)
+	})
+}
 
+func (r *PostgresUserRepository) Create(ctx context.Context, user *entity.User) error {
+	values, err := r.insertValues(user)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, sharedErrors.ErrUserNotFound
-		}
-		return nil, fmt.Errorf("failed to get user by email: %w", err)
+		return err
 	}
 
-	return user, nil
+	_, err = r.store.Exec(ctx, "create user", r.store.InsertSQL(), values...)
+	return err
 }
 
-func (r *PostgresUserRepository) GetByUsername(ctx context.Context, username string) (*entity.User, error) {
-	query := `
-		SELECT id, email, username, password, full_name, role, status, created_at, updated_at, deleted_at
-		FROM users
-		WHERE username = $1 AND deleted_at IS NULL
-	`
-
-	user := &entity.User{}
-	err := r.db.QueryRow(ctx, query, username).Scan(
-		&user.ID,
-		&user.Email,
-		&user.Username,
-		&user.Password,
-		&user.FullName,
-		&user.Role,
-		&user.Status,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-		&user.DeletedAt,
-	)
+func (r *PostgresUserRepository) CreateBatch(ctx context.Context, users []*entity.User) (err error) {
+	ctx, cancel := database.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
 
+	// Inside an outer transaction Begin creates a savepoint.
+	tx, err := r.store.Conn(ctx).Begin(ctx)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return nil, sharedErrors.ErrUserNotFound
+		if ctxErr := sharedErrors.ContextError(err); ctxErr != nil {
+			return ctxErr
+		}
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			if rbErr := tx.Rollback(ctx); rbErr != nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+				err = fmt.Errorf("%w (rollback failed: %v)", err, rbErr)
+			}
+		}
+	}()
+
+	batch := &pgx.Batch{}
+	for _, user := range users {
+		values, valuesErr := r.insertValues(user)
+		if valuesErr != nil {
+			err = valuesErr
+			return err
 		}
-		return nil, fmt.Errorf("failed to get user by username: %w", err)
+		batch.Queue(r.store.InsertSQL(), values...)
 	}
 
-	return user, nil
+	results := tx.SendBatch(ctx, batch)
+	var execErr error
+	for range users {
+		if _, execErr = results.Exec(); execErr != nil {
+			break
+		}
+	}
+	closeErr := results.Close()
+	if execErr != nil {
+		if conflict := uniqueViolation(execErr); conflict != nil {
+			err = conflict
+			return err
+		}
+		if ctxErr := sharedErrors.ContextError(execErr); ctxErr != nil {
+			err = ctxErr
+			return err
+		}
+		err = fmt.Errorf("failed to create users: %w", execErr)
+		return err
+	}
+	if closeErr != nil {
+		if ctxErr := sharedErrors.ContextError(closeErr); ctxErr != nil {
+			err = ctxErr
+			return err
+		}
+		err = fmt.Errorf("failed to create users: %w", closeErr)
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		if ctxErr := sharedErrors.ContextError(err); ctxErr != nil {
+			return ctxErr
+		}
+		return fmt.Errorf("failed to commit users: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PostgresUserRepository) GetByID(ctx context.Context, id string) (*entity.User, error) {
+	return r.opened(r.reader(ctx).GetByID(ctx, id))
+}
+
+func (r *PostgresUserRepository) GetByIDIncludingDeleted(ctx context.Context, id string) (*entity.User, error) {
+	return r.opened(r.reader(ctx).Get(ctx, database.NewFilter().Where("id = ?", id)))
+}
+
+func (r *PostgresUserRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
+	return r.opened(r.reader(ctx).Get(ctx, r.emailFilter(email)))
+}
+
+func (r *PostgresUserRepository) GetByUsername(ctx context.Context, username string) (*entity.User, error) {
+	return r.opened(r.reader(ctx).GetBy(ctx, "username", username))
 }
 
+// Update writes user only if the stored row still has user.Version, and then
+// increments user.Version. A version mismatch returns ErrStaleData so that
+// concurrent writers fail instead of overwriting each other's changes.
 func (r *PostgresUserRepository) Update(ctx context.Context, user *entity.User) error {
 	query := `
 		UPDATE users
-		SET email = $2, username = $3, password = $4, full_name = $5, role = $6, status = $7, updated_at = $8
-		WHERE id = $1 AND deleted_at IS NULL
+		SET email = $2, username = $3, password = $4, full_name = $5, role = $6, status = $7, updated_at = $8,
+			totp_secret = NULLIF($10, ''), mfa_enabled = $11, avatar_url = NULLIF($12, ''),
+			timezone = NULLIF($13, ''), locale = NULLIF($14, ''), email_index = $15, version = version + 1
+		WHERE id = $1 AND version = $9 AND deleted_at IS NULL
 	`
 
-	result, err := r.db.Exec(ctx, query,
+	stored := user
+	var emailIndex any
+	if r.fields != nil {
+		sealed, err := r.seal(user)
+		if err != nil {
+			return err
+		}
+		stored, emailIndex = sealed, r.emailIndex(user.Email)
+	}
+
+	result, err := r.store.Exec(ctx, "update user", query,
 		user.ID,
-		user.Email,
+		stored.Email,
 		user.Username,
 		user.Password,
-		user.FullName,
+		stored.FullName,
 		user.Role,
 		user.Status,
 		user.UpdatedAt,
+		user.Version,
+		user.TOTPSecret,
+		user.MFAEnabled,
+		user.AvatarURL,
+		user.Timezone,
+		user.Locale,
+		emailIndex,
 	)
-
 	if err != nil {
-		return fmt.Errorf("failed to update user: %w", err)
+		return err
 	}
 
 	if result.RowsAffected() == 0 {
-		return sharedErrors.ErrUserNotFound
+		return r.updateMissError(ctx, user.ID)
 	}
 
+	user.Version++
+
 	return nil
 }
 
+// updateMissError tells a stale version apart from a missing user after an
+// update matched no rows. It asks the primary, which a replica may lag.
+func (r *PostgresUserRepository) updateMissError(ctx context.Context, id string) error {
+	exists, err := r.store.ExistsBy(ctx, "id", id)
+	if err != nil {
+		return err
+	}
+
+	if exists {
+		return sharedErrors.ErrStaleData
+	}
+	return sharedErrors.ErrUserNotFound
+}
+
+// UpdateLastLogin sets only last_login_at, leaving version and updated_at
+// alone so a login never conflicts with a concurrent Update.
+func (r *PostgresUserRepository) UpdateLastLogin(ctx context.Context, id string, t time.Time) error {
+	query := `UPDATE users SET last_login_at = $2 WHERE id = $1 AND deleted_at IS NULL`
+	return r.store.ExecOne(ctx, "update last login", query, id, t)
+}
+
 func (r *PostgresUserRepository) Delete(ctx context.Context, id string) error {
 	query := `
 		UPDATE users
-		SET deleted_at = NOW(), status = 'inactive', updated_at = NOW()
+		SET deleted_at = NOW(), status = 'inactive', updated_at = NOW(), version = version + 1
 		WHERE id = $1 AND deleted_at IS NULL
 	`
+	return r.store.ExecOne(ctx, "delete user", query, id)
+}
+
+func (r *PostgresUserRepository) Restore(ctx context.Context, id string) error {
+	query := `
+		UPDATE users
+		SET deleted_at = NULL, status = 'active', updated_at = NOW(), version = version + 1
+		WHERE id = $1 AND deleted_at IS NOT NULL
+	`
+	return r.store.ExecOne(ctx, "restore user", query, id)
+}
 
-	result, err := r.db.Exec(ctx, query, id)
+func (r *PostgresUserRepository) PurgeDeleted(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	query := `
+		DELETE FROM users
+		WHERE id IN (
+			SELECT id FROM users
+			WHERE deleted_at IS NOT NULL AND deleted_at < $1
+			ORDER BY deleted_at
+			LIMIT $2
+		)
+	`
+
+	result, err := r.store.Exec(ctx, "purge deleted users", query, cutoff, limit)
 	if err != nil {
-		return fmt.Errorf("failed to delete user: %w", err)
+		return 0, err
 	}
 
-	if result.RowsAffected() == 0 {
-		return sharedErrors.ErrUserNotFound
-	}
+	return result.RowsAffected(), nil
+}
 
+// checkListParams rejects the listing options that need the plaintext of
+// columns encrypted under field encryption: searching, which matches emails
+// and full names, and sorting by email.
+func (r *PostgresUserRepository) checkListParams(params ListParams) error {
+	if r.fields == nil {
+		return nil
+	}
+	if params.Search != "" {
+		return sharedErrors.ErrInvalidInput.WithMessage("Searching users is not available while personal data is encrypted")
+	}
+	if params.SortBy == "email" {
+		return sharedErrors.ErrInvalidInput.WithMessage("Sorting users by email is not available while personal data is encrypted")
+	}
 	return nil
 }
 
-func (r *PostgresUserRepository) List(ctx context.Context, page, pageSize int, search, role, status string) ([]*entity.User, int64, error) {
-	offset := (page - 1) * pageSize
+func (r *PostgresUserRepository) List(ctx context.Context, params ListParams) ([]*entity.User, int64, error) {
+	if err := r.checkListParams(params); err != nil {
+		return nil, 0, err
+	}
 
-	// Build query with filters
-	query := `
-		SELECT id, email, username, password, full_name, role, status, created_at, updated_at, deleted_at
-		FROM users
-		WHERE deleted_at IS NULL
-	`
-	countQuery := `SELECT COUNT(*) FROM users WHERE deleted_at IS NULL`
-	args := []interface{}{}
-	argPos := 1
+	offset := (params.Page - 1) * params.PageSize
 
-	if search != "" {
-		query += fmt.Sprintf(" AND (email ILIKE $%d OR username ILIKE $%d OR full_name ILIKE $%d)", argPos, argPos, argPos)
-		countQuery += fmt.Sprintf(" AND (email ILIKE $%d OR username ILIKE $%d OR full_name ILIKE $%d)", argPos, argPos, argPos)
-		args = append(args, "%"+search+"%")
-		argPos++
+	filter := buildListFilter(params)
+	total, err := r.reader(ctx).Count(ctx, filter)
+	if err != nil {
+		return nil, 0, err
 	}
 
-	if role != "" {
-		query += fmt.Sprintf(" AND role = $%d", argPos)
-		countQuery += fmt.Sprintf(" AND role = $%d", argPos)
-		args = append(args, role)
-		argPos++
+	users, err := r.reader(ctx).List(ctx, filter,
+		" ORDER BY "+buildListOrder(params)+
+			" LIMIT "+filter.Arg(params.PageSize)+" OFFSET "+filter.Arg(offset))
+	if err != nil {
+		return nil, 0, err
 	}
+	if err := r.openAll(users); err != nil {
+		return nil, 0, err
+	}
+
+	return users, total, nil
+}
 
-	if status != "" {
-		query += fmt.Sprintf(" AND status = $%d", argPos)
-		countQuery += fmt.Sprintf(" AND status = $%d", argPos)
-		args = append(args, status)
-		argPos++
+// ListByCursor returns the page of users that follows cursor using keyset
+// pagination on (created_at, id), which stays stable while rows are inserted.
+// An empty cursor starts from the newest user. The returned cursor is empty
+// when there are no further pages.
+func (r *PostgresUserRepository) ListByCursor(ctx context.Context, params ListParams, cursor string) ([]*entity.User, string, error) {
+	if err := r.checkListParams(params); err != nil {
+		return nil, "", err
 	}
 
-	query += " ORDER BY created_at DESC"
-	query += fmt.Sprintf(" LIMIT $%d OFFSET $%d", argPos, argPos+1)
+	filter := buildListFilter(params)
+	if cursor != "" {
+		position, err := decodeCursor(cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		filter.Where("(created_at, id) < (?, ?)", position.CreatedAt, position.ID)
+	}
 
-	// Get total count
-	var total int64
-	err := r.db.QueryRow(ctx, countQuery, args...).Scan(&total)
+	// Fetch one extra row to learn whether another page exists.
+	users, err := r.reader(ctx).List(ctx, filter,
+		" ORDER BY created_at DESC, id DESC LIMIT "+filter.Arg(params.PageSize+1))
 	if err != nil {
-		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+		return nil, "", err
+	}
+	if err := r.openAll(users); err != nil {
+		return nil, "", err
 	}
 
-	// Get users
-	args = append(args, pageSize, offset)
-	rows, err := r.db.Query(ctx, query, args...)
-	if err != nil {
-		return nil, 0, fmt.Errorf("failed to list users: %w", err)
+	nextCursor := ""
+	if len(users) > params.PageSize {
+		users = users[:params.PageSize]
+		nextCursor = encodeCursor(users[len(users)-1])
 	}
-	defer rows.Close()
 
-	users := make([]*entity.User, 0)
-	for rows.Next() {
-		user := &entity.User{}
-		err := rows.Scan(
-			&user.ID,
-			&user.Email,
-			&user.Username,
-			&user.Password,
-			&user.FullName,
-			&user.Role,
-			&user.Status,
-			&user.CreatedAt,
-			&user.UpdatedAt,
-			&user.DeletedAt,
-		)
-		if err != nil {
-			return nil, 0, fmt.Errorf("failed to scan user: %w", err)
+	return users, nextCursor, nil
+}
+
+// exportBatchSize is how many users Export reads from its cursor at a time.
+const exportBatchSize = 500
+
+func (r *PostgresUserRepository) Export(ctx context.Context, params ListParams, fn func(user *entity.User) error) error {
+	if err := r.checkListParams(params); err != nil {
+		return err
+	}
+
+	return r.reader(ctx).Each(ctx, buildListFilter(params), " ORDER BY "+buildListOrder(params), exportBatchSize, func(user *entity.User) error {
+		if err := r.open(user); err != nil {
+			return err
+		}
+		return fn(user)
+	})
+}
+
+// buildListFilter renders the List filters, excluding soft-deleted users.
+// The search term is bound first, so a fuzzy search term is always $1.
+func buildListFilter(params ListParams) *database.Filter {
+	filter := database.NewFilter()
+	filter.Where("deleted_at IS NULL")
+
+	if isFuzzySearch(params) {
+		threshold := params.SimilarityThreshold
+		if threshold <= 0 {
+			threshold = DefaultSimilarityThreshold
 		}
-		users = append(users, user)
+		filter.Arg(params.Search)
+		filter.Where(similarityScore+" >= ?", threshold)
+	} else if params.Search != "" {
+		term := filter.Arg("%" + params.Search + "%")
+		filter.Or(func(g *database.Filter) {
+			g.Where("email ILIKE " + term)
+			g.Where("username ILIKE " + term)
+			g.Where("full_name ILIKE " + term)
+		})
 	}
 
-	return users, total, nil
+	if params.Role != "" {
+		filter.Where("role = ?", params.Role)
+	}
+
+	if params.Status != "" {
+		filter.Where("status = ?", params.Status)
+	}
+
+	if !params.CreatedAfter.IsZero() {
+		filter.Where("created_at > ?", params.CreatedAfter)
+	}
+
+	if !params.CreatedBefore.IsZero() {
+		filter.Where("created_at < ?", params.CreatedBefore)
+	}
+
+	if !params.InactiveSince.IsZero() {
+		since := filter.Arg(params.InactiveSince)
+		filter.Or(func(g *database.Filter) {
+			g.Where("last_login_at IS NULL")
+			g.Where("last_login_at < " + since)
+		})
+	}
+
+	return filter
 }
 
-func (r *PostgresUserRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
-	query := `SELECT EXISTS(SELECT 1 FROM users WHERE email = $1 AND deleted_at IS NULL)`
+// sortableColumns whitelists the columns List may order by. The DTO already
+// validates sort_by, but the value is interpolated into SQL so it is checked
+// again here rather than trusted.
+var sortableColumns = map[string]string{
+	"email":      "email",
+	"username":   "username",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+	"status":     "status",
+}
 
-	var exists bool
-	err := r.db.QueryRow(ctx, query, email).Scan(&exists)
-	if err != nil {
-		return false, fmt.Errorf("failed to check email existence: %w", err)
+// similarityScore is a user's best pg_trgm similarity to the search term in
+// $1 across the searched columns.
+const similarityScore = "GREATEST(similarity(email, $1), similarity(username, $1), similarity(full_name, $1))"
+
+func isFuzzySearch(params ListParams) bool {
+	return params.Search != "" && params.SearchMode == SearchModeFuzzy
+}
+
+// buildListOrder renders the ORDER BY expression for List, ranking fuzzy
+// searches by similarity and otherwise sorting by SortBy, creation time by
+// default, in SortOrder, descending by default. Ties are broken by id so
+// offset pages stay deterministic.
+func buildListOrder(params ListParams) string {
+	if isFuzzySearch(params) {
+		return similarityScore + " DESC, created_at DESC, id DESC"
+	}
+
+	column, ok := sortableColumns[params.SortBy]
+	if !ok {
+		column = "created_at"
 	}
 
-	return exists, nil
+	direction := "DESC"
+	if strings.EqualFold(params.SortOrder, "asc") {
+		direction = "ASC"
+	}
+
+	return fmt.Sprintf("%s %s, id %s", column, direction, direction)
+}
+
+func (r *PostgresUserRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
+	return r.reader(ctx).Exists(ctx, r.emailFilter(email))
 }
 
 func (r *PostgresUserRepository) ExistsByUsername(ctx context.Context, username string) (bool, error) {
-	query := `SELECT EXISTS(SELECT 1 FROM users WHERE username = $1 AND deleted_at IS NULL)`
+	return r.reader(ctx).ExistsBy(ctx, "username", username)
+}
 
-	var exists bool
-	err := r.db.QueryRow(ctx, query, username).Scan(&exists)
+func (r *PostgresUserRepository) CountByStatus(ctx context.Context) (map[string]int64, error) {
+	ctx, cancel := database.WithQueryTimeout(ctx, r.queryTimeout)
+	defer cancel()
+
+	query := `SELECT status, COUNT(*) FROM users WHERE deleted_at IS NULL GROUP BY status`
+
+	rows, err := r.reader(ctx).Conn(ctx).Query(ctx, query)
 	if err != nil {
-		return false, fmt.Errorf("failed to check username existence: %w", err)
+		if ctxErr := sharedErrors.ContextError(err); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, fmt.Errorf("failed to count users by status: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int64)
+	for rows.Next() {
+		var status string
+		var count int64
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan user count: %w", err)
+		}
+		counts[status] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		if ctxErr := sharedErrors.ContextError(err); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, fmt.Errorf("failed to count users by status: %w", err)
 	}
 
-	return exists, nil
+	return counts, nil
 }
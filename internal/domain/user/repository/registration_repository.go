@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/internal/shared/constants"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// RegistrationCounter counts the accounts registered from each IP address
+// within a rolling window.
+type RegistrationCounter interface {
+	// Count returns how many registrations ip made within the window.
+	Count(ctx context.Context, ip string) (int64, error)
+	// Reserve counts a registration from ip now, before it is made, and
+	// returns how many registrations ip made within the window, this one
+	// included. Counting and reserving are one atomic step, so concurrent
+	// registrations each see the others. The returned reservation releases
+	// the count through Release.
+	Reserve(ctx context.Context, ip string) (reservation string, count int64, err error)
+	// Release uncounts a reservation of ip whose registration was not made.
+	Release(ctx context.Context, ip, reservation string) error
+}
+
+// RegistrationCache is the Redis access used by RedisRegistrationCounter.
+type RegistrationCache interface {
+	Pipeline(ctx context.Context, fn func(pipe redis.Pipeliner) error) error
+}
+
+// RedisRegistrationCounter keeps the registration times of each IP address
+// in a Redis sorted set scored by time. Entries older than the window are
+// trimmed whenever the set is counted or added to, and the set itself
+// expires one window after its last registration.
+type RedisRegistrationCounter struct {
+	cache  RegistrationCache
+	window time.Duration
+}
+
+func NewRedisRegistrationCounter(cache RegistrationCache, window time.Duration) *RedisRegistrationCounter {
+	return &RedisRegistrationCounter{
+		cache:  cache,
+		window: window,
+	}
+}
+
+func (r *RedisRegistrationCounter) Count(ctx context.Context, ip string) (int64, error) {
+	key := registrationKey(ip)
+	since := time.Now().Add(-r.window).UnixMilli()
+
+	var cmd *redis.IntCmd
+	err := r.cache.Pipeline(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRemRangeByScore(ctx, key, "-inf", "("+strconv.FormatInt(since, 10))
+		cmd = pipe.ZCard(ctx, key)
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count registrations: %w", err)
+	}
+	return cmd.Val(), nil
+}
+
+func (r *RedisRegistrationCounter) Reserve(ctx context.Context, ip string) (string, int64, error) {
+	key := registrationKey(ip)
+	now := time.Now()
+	since := now.Add(-r.window).UnixMilli()
+	// Registrations in the same millisecond need distinct members.
+	reservation := uuid.New().String()
+
+	var cmd *redis.IntCmd
+	err := r.cache.Pipeline(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRemRangeByScore(ctx, key, "-inf", "("+strconv.FormatInt(since, 10))
+		pipe.ZAdd(ctx, key, redis.Z{Score: float64(now.UnixMilli()), Member: reservation})
+		cmd = pipe.ZCard(ctx, key)
+		pipe.Expire(ctx, key, r.window)
+		return nil
+	})
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to reserve registration: %w", err)
+	}
+	return reservation, cmd.Val(), nil
+}
+
+func (r *RedisRegistrationCounter) Release(ctx context.Context, ip, reservation string) error {
+	key := registrationKey(ip)
+	err := r.cache.Pipeline(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRem(ctx, key, reservation)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to release registration: %w", err)
+	}
+	return nil
+}
+
+func registrationKey(ip string) string {
+	return constants.CacheKeyRegistrationPrefix + ip
+}
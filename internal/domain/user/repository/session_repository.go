@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/entity"
+	"github.com/TubagusAldiMY/go-template/internal/shared/constants"
+	sharedErrors "github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/TubagusAldiMY/go-template/pkg/logger"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// SessionRepository stores the login sessions of users. Expired sessions are
+// never returned.
+type SessionRepository interface {
+	// Save creates the session or replaces the stored one with the same ID.
+	Save(ctx context.Context, session *entity.Session) error
+	// Rotate replaces the stored session like Save, but only while its
+	// current refresh token is still previousTokenID. The check and the
+	// write are one atomic step, so of concurrent refreshes with the same
+	// token only one succeeds. It returns ErrSessionNotFound if the user has
+	// no such session and ErrStaleData if its token was already rotated.
+	Rotate(ctx context.Context, session *entity.Session, previousTokenID string) error
+	// Get returns ErrSessionNotFound if the user has no such session.
+	Get(ctx context.Context, userID, sessionID string) (*entity.Session, error)
+	// List returns the user's sessions, most recently used first.
+	List(ctx context.Context, userID string) ([]*entity.Session, error)
+	// Delete returns ErrSessionNotFound if the user has no such session.
+	Delete(ctx context.Context, userID, sessionID string) error
+	DeleteAll(ctx context.Context, userID string) error
+}
+
+// SessionCache is the Redis access used by RedisSessionRepository.
+type SessionCache interface {
+	Pipeline(ctx context.Context, fn func(pipe redis.Pipeliner) error) error
+}
+
+// RedisSessionRepository keeps the sessions of a user in one Redis hash keyed
+// by session ID. Hash fields cannot expire on their own, so expired sessions
+// are skipped on reads and removed by List; the hash itself expires
+// maxLifetime after its last write, by which time every session in it has.
+type RedisSessionRepository struct {
+	cache       SessionCache
+	maxLifetime time.Duration
+}
+
+// NewRedisSessionRepository returns a repository for sessions that live at
+// most maxLifetime, the longest refresh token lifetime.
+func NewRedisSessionRepository(cache SessionCache, maxLifetime time.Duration) *RedisSessionRepository {
+	return &RedisSessionRepository{
+		cache:       cache,
+		maxLifetime: maxLifetime,
+	}
+}
+
+func (r *RedisSessionRepository) Save(ctx context.Context, session *entity.Session) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+
+	key := sessionKey(session.UserID)
+	err = r.cache.Pipeline(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, key, session.ID, data)
+		pipe.Expire(ctx, key, r.maxLifetime)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save session: %w", err)
+	}
+	return nil
+}
+
+// rotateScript replaces session ARGV[1] in hash KEYS[1] with ARGV[3] if the
+// stored session's token_id is ARGV[2], and then expires the hash in ARGV[4]
+// milliseconds. It returns 1 when it replaced the session, 0 when there is
+// none and -1 when its token differs.
+var rotateScript = redis.NewScript(`
+local stored = redis.call("HGET", KEYS[1], ARGV[1])
+if not stored then
+	return 0
+end
+if cjson.decode(stored).token_id ~= ARGV[2] then
+	return -1
+end
+redis.call("HSET", KEYS[1], ARGV[1], ARGV[3])
+redis.call("PEXPIRE", KEYS[1], ARGV[4])
+return 1
+`)
+
+func (r *RedisSessionRepository) Rotate(ctx context.Context, session *entity.Session, previousTokenID string) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return fmt.Errorf("failed to encode session: %w", err)
+	}
+
+	var cmd *redis.Cmd
+	err = r.cache.Pipeline(ctx, func(pipe redis.Pipeliner) error {
+		cmd = rotateScript.Eval(ctx, pipe, []string{sessionKey(session.UserID)},
+			session.ID, previousTokenID, data, r.maxLifetime.Milliseconds())
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to rotate session: %w", err)
+	}
+
+	switch result, _ := cmd.Int(); result {
+	case 0:
+		return sharedErrors.ErrSessionNotFound
+	case -1:
+		return sharedErrors.ErrStaleData
+	}
+	return nil
+}
+
+func (r *RedisSessionRepository) Get(ctx context.Context, userID, sessionID string) (*entity.Session, error) {
+	var cmd *redis.StringCmd
+	err := r.cache.Pipeline(ctx, func(pipe redis.Pipeliner) error {
+		cmd = pipe.HGet(ctx, sessionKey(userID), sessionID)
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, sharedErrors.ErrSessionNotFound
+		}
+		return nil, fmt.Errorf("failed to get session: %w", err)
+	}
+
+	var session entity.Session
+	if err := json.Unmarshal([]byte(cmd.Val()), &session); err != nil {
+		return nil, fmt.Errorf("failed to decode session: %w", err)
+	}
+	if session.IsExpired(time.Now()) {
+		return nil, sharedErrors.ErrSessionNotFound
+	}
+	return &session, nil
+}
+
+func (r *RedisSessionRepository) List(ctx context.Context, userID string) ([]*entity.Session, error) {
+	key := sessionKey(userID)
+	var cmd *redis.MapStringStringCmd
+	err := r.cache.Pipeline(ctx, func(pipe redis.Pipeliner) error {
+		cmd = pipe.HGetAll(ctx, key)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	now := time.Now()
+	sessions := make([]*entity.Session, 0, len(cmd.Val()))
+	var stale []string
+	for id, data := range cmd.Val() {
+		var session entity.Session
+		if err := json.Unmarshal([]byte(data), &session); err != nil || session.IsExpired(now) {
+			stale = append(stale, id)
+			continue
+		}
+		sessions = append(sessions, &session)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt)
+	})
+
+	if len(stale) > 0 {
+		err := r.cache.Pipeline(ctx, func(pipe redis.Pipeliner) error {
+			pipe.HDel(ctx, key, stale...)
+			return nil
+		})
+		if err != nil {
+			logger.FromContext(ctx).Warn("failed to remove expired sessions", zap.String("user_id", userID), zap.Error(err))
+		}
+	}
+
+	return sessions, nil
+}
+
+func (r *RedisSessionRepository) Delete(ctx context.Context, userID, sessionID string) error {
+	var cmd *redis.IntCmd
+	err := r.cache.Pipeline(ctx, func(pipe redis.Pipeliner) error {
+		cmd = pipe.HDel(ctx, sessionKey(userID), sessionID)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete session: %w", err)
+	}
+	if cmd.Val() == 0 {
+		return sharedErrors.ErrSessionNotFound
+	}
+	return nil
+}
+
+func (r *RedisSessionRepository) DeleteAll(ctx context.Context, userID string) error {
+	err := r.cache.Pipeline(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, sessionKey(userID))
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete sessions: %w", err)
+	}
+	return nil
+}
+
+func sessionKey(userID string) string {
+	return constants.CacheKeySessionPrefix + userID
+}
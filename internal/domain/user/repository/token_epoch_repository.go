@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/TubagusAldiMY/go-template/internal/shared/constants"
+	"github.com/redis/go-redis/v9"
+)
+
+// TokenEpochRepository stores the token epoch of users, a counter embedded in
+// their access tokens. Bumping it revokes every access token issued before.
+type TokenEpochRepository interface {
+	// TokenEpoch returns zero for users whose epoch was never bumped.
+	TokenEpoch(ctx context.Context, userID string) (int64, error)
+	Bump(ctx context.Context, userID string) (int64, error)
+}
+
+// TokenEpochCache is the Redis access used by RedisTokenEpochRepository.
+type TokenEpochCache interface {
+	Get(ctx context.Context, key string) (string, error)
+	Incr(ctx context.Context, key string) (int64, error)
+}
+
+// RedisTokenEpochRepository keeps each user's token epoch in its own Redis
+// key. The keys never expire: an epoch that reset to zero would let tokens
+// revoked by an earlier bump through again.
+type RedisTokenEpochRepository struct {
+	cache TokenEpochCache
+}
+
+func NewRedisTokenEpochRepository(cache TokenEpochCache) *RedisTokenEpochRepository {
+	return &RedisTokenEpochRepository{cache: cache}
+}
+
+func (r *RedisTokenEpochRepository) TokenEpoch(ctx context.Context, userID string) (int64, error) {
+	value, err := r.cache.Get(ctx, tokenEpochKey(userID))
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to get token epoch: %w", err)
+	}
+
+	epoch, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode token epoch: %w", err)
+	}
+	return epoch, nil
+}
+
+func (r *RedisTokenEpochRepository) Bump(ctx context.Context, userID string) (int64, error) {
+	epoch, err := r.cache.Incr(ctx, tokenEpochKey(userID))
+	if err != nil {
+		return 0, fmt.Errorf("failed to bump token epoch: %w", err)
+	}
+	return epoch, nil
+}
+
+func tokenEpochKey(userID string) string {
+	return constants.CacheKeyTokenEpochPrefix + userID
+}
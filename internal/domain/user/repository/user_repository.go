@@ -2,18 +2,79 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"github.com/TubagusAldiMY/go-template/internal/domain/user/entity"
 )
 
+// Search modes.
+const (
+	// SearchModeExact matches users whose email, username or full name
+	// contains the search term.
+	SearchModeExact = "exact"
+	// SearchModeFuzzy matches users whose email, username or full name has a
+	// pg_trgm similarity of at least the threshold to the search term,
+	// tolerating typos.
+	SearchModeFuzzy = "fuzzy"
+)
+
+// DefaultSimilarityThreshold is the fuzzy search threshold used when
+// ListParams sets none; it matches pg_trgm's own default.
+const DefaultSimilarityThreshold = 0.3
+
+// ListParams holds the filters and paging options shared by List and
+// ListByCursor. Page and the sort options are ignored in cursor mode, which
+// always orders by creation time.
+type ListParams struct {
+	Page     int
+	PageSize int
+	Search   string
+	// SearchMode is exact (the default) or fuzzy. Fuzzy results are ordered
+	// by similarity instead of SortBy, except in cursor mode.
+	SearchMode          string
+	SimilarityThreshold float64
+	Role                string
+	Status              string
+	// CreatedAfter and CreatedBefore bound the creation time, exclusively.
+	// The zero time leaves that end open.
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	// InactiveSince keeps users who have not logged in since then, including
+	// those who never have. The zero time disables the filter.
+	InactiveSince time.Time
+	SortBy        string
+	SortOrder     string
+}
+
 type UserRepository interface {
 	Create(ctx context.Context, user *entity.User) error
+	// CreateBatch inserts all users atomically: either every row is stored or none.
+	CreateBatch(ctx context.Context, users []*entity.User) error
 	GetByID(ctx context.Context, id string) (*entity.User, error)
+	// GetByIDIncludingDeleted is GetByID without the soft-delete filter.
+	GetByIDIncludingDeleted(ctx context.Context, id string) (*entity.User, error)
 	GetByEmail(ctx context.Context, email string) (*entity.User, error)
 	GetByUsername(ctx context.Context, username string) (*entity.User, error)
 	Update(ctx context.Context, user *entity.User) error
+	// UpdateLastLogin records t as the user's last login without touching
+	// any other column, so it cannot clobber a concurrent Update.
+	UpdateLastLogin(ctx context.Context, id string, t time.Time) error
 	Delete(ctx context.Context, id string) error
-	List(ctx context.Context, page, pageSize int, search, role, status string) ([]*entity.User, int64, error)
+	// Restore undoes a soft delete and reactivates the user. It returns
+	// ErrUserNotFound if no soft-deleted user has the ID.
+	Restore(ctx context.Context, id string) error
+	// PurgeDeleted permanently removes up to limit users soft-deleted before
+	// cutoff, oldest first, and returns how many were removed.
+	PurgeDeleted(ctx context.Context, cutoff time.Time, limit int) (int64, error)
+	List(ctx context.Context, params ListParams) ([]*entity.User, int64, error)
+	ListByCursor(ctx context.Context, params ListParams, cursor string) ([]*entity.User, string, error)
+	// Export calls fn for every user matching the List filters, in List
+	// order, streaming them instead of loading them all. Paging options are
+	// ignored. It stops at the first error fn returns and returns that error.
+	Export(ctx context.Context, params ListParams, fn func(user *entity.User) error) error
 	ExistsByEmail(ctx context.Context, email string) (bool, error)
 	ExistsByUsername(ctx context.Context, username string) (bool, error)
+	// CountByStatus returns how many users have each status, leaving out
+	// soft-deleted users. Statuses without users are absent.
+	CountByStatus(ctx context.Context) (map[string]int64, error)
 }
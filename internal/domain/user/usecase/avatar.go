@@ -0,0 +1,122 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/dto"
+	"github.com/TubagusAldiMY/go-template/internal/shared/constants"
+	"github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/TubagusAldiMY/go-template/pkg/crypto"
+	"github.com/TubagusAldiMY/go-template/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// FileStorage stores uploaded files under keys and serves them from URL(key).
+type FileStorage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+}
+
+// avatarTypes maps the accepted image types, as detected from the file
+// content, to the extension the file is stored with.
+var avatarTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// WithAvatarStorage enables avatar uploads of up to maxSize bytes.
+func WithAvatarStorage(storage FileStorage, maxSize int64) Option {
+	return func(uc *UserUsecase) {
+		uc.avatarStorage = storage
+		uc.maxAvatarSize = maxSize
+	}
+}
+
+// UploadAvatar stores the image read from file as the user's avatar. The
+// type is detected from the content rather than taken from the client, and
+// each upload gets a new key so cached copies of the old picture are never
+// served for the new URL. The previous avatar is deleted on a best-effort
+// basis.
+func (uc *UserUsecase) UploadAvatar(ctx context.Context, userID string, file io.Reader) (*dto.UserResponse, error) {
+	if uc.avatarStorage == nil {
+		return nil, errors.ErrStorageNotConfigured
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, uc.maxAvatarSize+1))
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to read avatar upload", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+	if int64(len(data)) > uc.maxAvatarSize {
+		return nil, errors.ErrFileTooLarge
+	}
+
+	contentType := http.DetectContentType(data)
+	ext, ok := avatarTypes[contentType]
+	if !ok {
+		return nil, errors.ErrUnsupportedMediaType
+	}
+
+	user, err := uc.Load(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := crypto.GenerateRandomBytes(16)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to generate avatar name", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+	key := fmt.Sprintf("avatars/%s/%s%s", user.ID, hex.EncodeToString(name), ext)
+
+	if err := uc.avatarStorage.Put(ctx, key, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		logger.FromContext(ctx).Error("failed to store avatar", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+
+	previousURL := user.AvatarURL
+	user.ChangeAvatar(uc.avatarStorage.URL(key))
+
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		uc.deleteAvatar(ctx, key)
+		if errors.Is(err, errors.ErrStaleData) {
+			return nil, err
+		}
+		logger.FromContext(ctx).Error("failed to update user", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+
+	// Only avatars stored by this backend can be deleted; anything else was
+	// set some other way and is left alone.
+	if previousKey, ok := strings.CutPrefix(previousURL, uc.avatarStorage.URL("")); ok && previousKey != "" {
+		uc.deleteAvatar(ctx, previousKey)
+	}
+
+	logger.FromContext(ctx).Info("user avatar updated",
+		zap.String("user_id", userID),
+	)
+
+	uc.publishUserEvent(ctx, constants.RoutingKeyUserUpdated, newUserEvent(constants.RoutingKeyUserUpdated, user))
+
+	return uc.Response(user), nil
+}
+
+// deleteAvatar removes a stored avatar. Failures only leave an orphaned file
+// behind, so they are logged rather than returned.
+func (uc *UserUsecase) deleteAvatar(ctx context.Context, key string) {
+	if err := uc.avatarStorage.Delete(ctx, key); err != nil {
+		logger.FromContext(ctx).Warn("failed to delete avatar",
+			zap.String("key", key),
+			zap.Error(err),
+		)
+	}
+}
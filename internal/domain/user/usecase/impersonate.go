@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/dto"
+	"github.com/TubagusAldiMY/go-template/internal/shared/constants"
+	"github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/TubagusAldiMY/go-template/pkg/appcontext"
+	"github.com/TubagusAldiMY/go-template/pkg/authz"
+	"github.com/TubagusAldiMY/go-template/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Impersonate issues actorID a short-lived access token for the user
+// identified by userID, so support staff can reproduce what the user sees.
+// The token carries actorID in its act claim and the impersonation is
+// audited before the token is handed out; if it cannot be recorded, no token
+// is issued. The token grants the user's role, so the actor's role, read from
+// ctx, must hold every permission of it.
+func (uc *UserUsecase) Impersonate(ctx context.Context, actorID, userID string) (*dto.ImpersonationResponse, error) {
+	if actorID == userID {
+		return nil, errors.ErrCannotImpersonate.WithMessage("You cannot impersonate yourself")
+	}
+
+	user, err := uc.Load(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	actorRole, _ := appcontext.Role(ctx)
+	if !authz.Covers(actorRole, user.Role) {
+		return nil, errors.ErrCannotImpersonate.WithMessage("You cannot impersonate a user with permissions you do not have")
+	}
+
+	accessToken, err := uc.jwtManager.GenerateImpersonationToken(ctx, user.ID, user.Email, user.Role, actorID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to generate impersonation token", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+
+	lifetime := uc.jwtManager.ImpersonationTokenDuration()
+	if err := uc.auditLogger.Record(ctx, constants.AuditActionUserImpersonated, user.ID, map[string]interface{}{
+		"expires_in": int64(lifetime.Seconds()),
+	}); err != nil {
+		return nil, errors.ErrInternal
+	}
+
+	logger.FromContext(ctx).Info("user impersonation started",
+		zap.String("user_id", user.ID),
+		zap.String("actor_id", actorID),
+		zap.Duration("lifetime", lifetime),
+	)
+
+	return &dto.ImpersonationResponse{
+		User:        uc.Response(user),
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(lifetime.Seconds()),
+	}, nil
+}
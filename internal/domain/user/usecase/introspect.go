@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/dto"
+	"github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/TubagusAldiMY/go-template/pkg/jwt"
+	"github.com/TubagusAldiMY/go-template/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// IntrospectToken reports whether an access token is currently active and,
+// if so, what it carries, in the style of OAuth 2.0 token introspection
+// (RFC 7662). Invalid and expired tokens are inactive, and so are tokens of
+// users who have since been deleted or deactivated, since those can no longer
+// use the API. Only a failed user or token epoch lookup returns an error.
+func (uc *UserUsecase) IntrospectToken(ctx context.Context, req *dto.IntrospectTokenRequest) (*dto.IntrospectTokenResponse, error) {
+	inactive := &dto.IntrospectTokenResponse{Active: false}
+
+	claims, err := uc.jwtManager.ValidateAccessToken(ctx, req.Token)
+	if err != nil {
+		if errors.Is(err, jwt.ErrEpochUnavailable) {
+			logger.FromContext(ctx).Error("failed to check token epoch", zap.Error(err))
+			return nil, errors.ErrInternal
+		}
+		return inactive, nil
+	}
+
+	user, err := uc.userRepo.GetByID(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, errors.ErrUserNotFound) {
+			return inactive, nil
+		}
+		logger.FromContext(ctx).Error("failed to get user for token introspection", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+	if !user.IsActive() {
+		return inactive, nil
+	}
+
+	resp := &dto.IntrospectTokenResponse{
+		Active:    true,
+		TokenType: "access_token",
+		Sub:       claims.Subject,
+		Email:     claims.Email,
+		Role:      claims.Role,
+		JTI:       claims.ID,
+		Iss:       claims.Issuer,
+		Aud:       claims.Audience,
+	}
+	if claims.ExpiresAt != nil {
+		resp.Exp = claims.ExpiresAt.Unix()
+	}
+	if claims.IssuedAt != nil {
+		resp.Iat = claims.IssuedAt.Unix()
+	}
+	if claims.Act != nil {
+		resp.Act = &dto.TokenActor{Sub: claims.Act.Subject}
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,287 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/dto"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/entity"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/repository"
+	"github.com/TubagusAldiMY/go-template/internal/shared/constants"
+	"github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/TubagusAldiMY/go-template/pkg/crypto"
+	"github.com/TubagusAldiMY/go-template/pkg/logger"
+	"github.com/TubagusAldiMY/go-template/pkg/totp"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const (
+	// mfaChallengeTTL bounds how long a password-verified login may wait for
+	// its TOTP code.
+	mfaChallengeTTL = 5 * time.Minute
+	// maxMFAAttempts is the number of codes checked against a challenge
+	// before it is discarded and the user has to log in again.
+	maxMFAAttempts = 5
+	mfaTokenLength = 43
+)
+
+// SecretCipher encrypts TOTP secrets before they are stored.
+type SecretCipher interface {
+	Encrypt(plaintext string) (string, error)
+	Decrypt(ciphertext string) (string, error)
+}
+
+// mfaChallenge is cached under the MFA token handed out by Login.
+type mfaChallenge struct {
+	UserID     string    `json:"user_id"`
+	RememberMe bool      `json:"remember_me,omitempty"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// WithTOTP enables TOTP enrollment and the second login step. Secrets are
+// encrypted with cipher and labelled with issuer in authenticator apps; repo
+// counts attempts at login challenges and remembers used codes.
+func WithTOTP(cipher SecretCipher, issuer string, repo repository.MFARepository) Option {
+	return func(uc *UserUsecase) {
+		uc.totpCipher = cipher
+		uc.totpIssuer = issuer
+		uc.totpRepo = repo
+	}
+}
+
+// EnableTOTP starts enrollment by generating a new secret for the user. MFA
+// stays off until the first code is confirmed with VerifyTOTP, so starting
+// over replaces an unconfirmed secret.
+func (uc *UserUsecase) EnableTOTP(ctx context.Context, userID string) (*dto.TOTPEnrollmentResponse, error) {
+	if uc.totpCipher == nil {
+		return nil, errors.ErrMFANotConfigured
+	}
+
+	user, err := uc.Load(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.MFAEnabled {
+		return nil, errors.ErrMFAAlreadyEnabled
+	}
+
+	key, err := totp.Generate(uc.totpIssuer, user.Email)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to generate totp secret", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+
+	encrypted, err := uc.totpCipher.Encrypt(key.Secret)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to encrypt totp secret", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+
+	user.SetTOTPSecret(encrypted)
+
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		if errors.Is(err, errors.ErrStaleData) {
+			return nil, errors.ErrStaleData
+		}
+		logger.FromContext(ctx).Error("failed to store totp secret", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+
+	logger.FromContext(ctx).Info("totp enrollment started", zap.String("user_id", user.ID))
+
+	return &dto.TOTPEnrollmentResponse{
+		Secret:          key.Secret,
+		ProvisioningURI: key.URI,
+	}, nil
+}
+
+// VerifyTOTP confirms enrollment with a code from the authenticator app and
+// turns MFA on for subsequent logins.
+func (uc *UserUsecase) VerifyTOTP(ctx context.Context, userID, code string) (*dto.UserResponse, error) {
+	if uc.totpCipher == nil {
+		return nil, errors.ErrMFANotConfigured
+	}
+
+	user, err := uc.Load(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.MFAEnabled {
+		return nil, errors.ErrMFAAlreadyEnabled
+	}
+
+	if user.TOTPSecret == "" {
+		return nil, errors.ErrMFANotEnrolled
+	}
+
+	if err := uc.checkTOTP(ctx, user, code); err != nil {
+		return nil, err
+	}
+
+	user.EnableMFA()
+
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		if errors.Is(err, errors.ErrStaleData) {
+			return nil, errors.ErrStaleData
+		}
+		logger.FromContext(ctx).Error("failed to enable mfa", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+
+	logger.FromContext(ctx).Info("mfa enabled", zap.String("user_id", user.ID))
+
+	return uc.Response(user), nil
+}
+
+// LoginTOTP completes a login started by Login for a user with MFA enabled.
+// The MFA token is single-use and is discarded once maxMFAAttempts codes
+// were checked against it.
+func (uc *UserUsecase) LoginTOTP(ctx context.Context, req *dto.LoginTOTPRequest) (*dto.LoginResponse, error) {
+	if uc.totpCipher == nil {
+		return nil, errors.ErrMFANotConfigured
+	}
+
+	key := mfaChallengeKey(req.MFAToken)
+
+	challenge, err := uc.getMFAChallenge(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := uc.userRepo.GetByID(ctx, challenge.UserID)
+	if err != nil {
+		if errors.Is(err, errors.ErrUserNotFound) {
+			return nil, errors.ErrInvalidMFAToken
+		}
+		logger.FromContext(ctx).Error("failed to get user for mfa login", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+
+	if !user.IsActive() || !user.MFAEnabled {
+		uc.discardMFAChallenge(ctx, key)
+		return nil, errors.ErrUnauthorized
+	}
+
+	// The attempt is counted before the code is checked, so concurrent
+	// guesses cannot exceed the limit.
+	ttl := time.Until(challenge.ExpiresAt)
+	if ttl <= 0 {
+		uc.discardMFAChallenge(ctx, key)
+		return nil, errors.ErrInvalidMFAToken
+	}
+	attempts, err := uc.totpRepo.CountAttempt(ctx, req.MFAToken, ttl)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to count mfa attempt", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+	if attempts > maxMFAAttempts {
+		uc.discardMFAChallenge(ctx, key)
+		return nil, errors.ErrInvalidMFAToken
+	}
+
+	if err := uc.checkTOTP(ctx, user, req.Code); err != nil {
+		if attempts == maxMFAAttempts {
+			logger.FromContext(ctx).Warn("mfa challenge discarded after failed attempts", zap.String("user_id", user.ID))
+			uc.discardMFAChallenge(ctx, key)
+		}
+		return nil, err
+	}
+
+	uc.discardMFAChallenge(ctx, key)
+
+	return uc.issueLoginTokens(ctx, user, challenge.RememberMe)
+}
+
+// startMFAChallenge stands in for the tokens of a password-verified login
+// when the user has MFA enabled. rememberMe is carried over to the tokens
+// issued once the second step succeeds.
+func (uc *UserUsecase) startMFAChallenge(ctx context.Context, user *entity.User, rememberMe bool) (*dto.LoginResponse, error) {
+	token, err := crypto.GenerateRandomString(mfaTokenLength)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to generate mfa token", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+
+	data, err := json.Marshal(mfaChallenge{
+		UserID:     user.ID,
+		RememberMe: rememberMe,
+		ExpiresAt:  time.Now().Add(mfaChallengeTTL),
+	})
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to encode mfa challenge", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+
+	if err := uc.cache.Set(ctx, mfaChallengeKey(token), data, mfaChallengeTTL); err != nil {
+		logger.FromContext(ctx).Error("failed to store mfa challenge", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+
+	logger.FromContext(ctx).Info("mfa challenge issued", zap.String("user_id", user.ID))
+
+	return &dto.LoginResponse{
+		MFARequired: true,
+		MFAToken:    token,
+	}, nil
+}
+
+func (uc *UserUsecase) getMFAChallenge(ctx context.Context, key string) (*mfaChallenge, error) {
+	data, err := uc.cache.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, errors.ErrInvalidMFAToken
+		}
+		logger.FromContext(ctx).Error("failed to get mfa challenge", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+
+	var challenge mfaChallenge
+	if err := json.Unmarshal([]byte(data), &challenge); err != nil {
+		logger.FromContext(ctx).Error("failed to decode mfa challenge", zap.Error(err))
+		return nil, errors.ErrInvalidMFAToken
+	}
+
+	return &challenge, nil
+}
+
+func (uc *UserUsecase) discardMFAChallenge(ctx context.Context, key string) {
+	if err := uc.cache.Delete(ctx, key); err != nil {
+		logger.FromContext(ctx).Warn("failed to delete mfa challenge", zap.Error(err))
+	}
+}
+
+// checkTOTP returns ErrInvalidMFACode unless code is valid for the user's
+// secret and of a later time step than the last code the user gave, so an
+// intercepted code cannot be used a second time.
+func (uc *UserUsecase) checkTOTP(ctx context.Context, user *entity.User, code string) error {
+	secret, err := uc.totpCipher.Decrypt(user.TOTPSecret)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to decrypt totp secret", zap.String("user_id", user.ID), zap.Error(err))
+		return errors.ErrInternal
+	}
+
+	step, valid := totp.ValidateStep(code, secret)
+	if !valid {
+		return errors.ErrInvalidMFACode
+	}
+
+	fresh, err := uc.totpRepo.UseTOTPStep(ctx, user.ID, step)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to record totp step", zap.String("user_id", user.ID), zap.Error(err))
+		return errors.ErrInternal
+	}
+	if !fresh {
+		logger.FromContext(ctx).Warn("totp code reused", zap.String("user_id", user.ID))
+		return errors.ErrInvalidMFACode
+	}
+
+	return nil
+}
+
+func mfaChallengeKey(token string) string {
+	return constants.CacheKeyMFAChallengePrefix + token
+}
@@ -0,0 +1,40 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// purgeBatchSize bounds the rows removed per statement, keeping locks short.
+const purgeBatchSize = 500
+
+// PurgeDeletedUsers permanently removes users soft-deleted more than
+// retention ago, in batches, and returns how many were removed. It stops
+// early when ctx is cancelled.
+func (uc *UserUsecase) PurgeDeletedUsers(ctx context.Context, retention time.Duration) (int64, error) {
+	cutoff := time.Now().Add(-retention)
+
+	var total int64
+	for {
+		purged, err := uc.userRepo.PurgeDeleted(ctx, cutoff, purgeBatchSize)
+		total += purged
+		if err != nil {
+			return total, err
+		}
+		if purged < purgeBatchSize || ctx.Err() != nil {
+			break
+		}
+	}
+
+	if total > 0 {
+		logger.FromContext(ctx).Info("purged deleted users",
+			zap.Int64("count", total),
+			zap.Time("deleted_before", cutoff),
+		)
+	}
+
+	return total, ctx.Err()
+}
@@ -0,0 +1,54 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/repository"
+	"github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/TubagusAldiMY/go-template/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// WithRegistrationLimit makes Register refuse with
+// ErrRegistrationLimitExceeded once limit accounts were registered from the
+// client's IP address within the window of counter. The address is that of
+// the Client in ctx; registrations without one are not limited.
+func WithRegistrationLimit(counter repository.RegistrationCounter, limit int) Option {
+	return func(uc *UserUsecase) {
+		uc.registrations = counter
+		uc.registrationLimit = int64(limit)
+	}
+}
+
+// reserveRegistration counts a registration against ip before it is made,
+// so concurrent registrations cannot all slip under the limit, and returns a
+// function releasing the reservation should the registration fail. Once ip
+// has used up its registrations it returns ErrRegistrationLimitExceeded and
+// keeps nothing reserved. While they cannot be counted, registration stays
+// open.
+func (uc *UserUsecase) reserveRegistration(ctx context.Context, ip string) (release func(), err error) {
+	release = func() {}
+	if uc.registrations == nil || ip == "" {
+		return release, nil
+	}
+
+	reservation, count, err := uc.registrations.Reserve(ctx, ip)
+	if err != nil {
+		logger.FromContext(ctx).Warn("registration limit unavailable, allowing registration", zap.Error(err))
+		return release, nil
+	}
+
+	release = func() {
+		// Released even when the request was cancelled, or the failed
+		// registration would keep counting.
+		if err := uc.registrations.Release(context.WithoutCancel(ctx), ip, reservation); err != nil {
+			logger.FromContext(ctx).Warn("failed to release registration", zap.String("ip", ip), zap.Error(err))
+		}
+	}
+	if count > uc.registrationLimit {
+		release()
+		logger.FromContext(ctx).Warn("registration limit exceeded", zap.String("ip", ip), zap.Int64("count", count-1))
+		return nil, errors.ErrRegistrationLimitExceeded
+	}
+	return release, nil
+}
@@ -0,0 +1,173 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/dto"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/entity"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/repository"
+	"github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/TubagusAldiMY/go-template/pkg/jwt"
+	"github.com/TubagusAldiMY/go-template/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Client describes the device a login request comes from.
+type Client struct {
+	UserAgent string
+	IPAddress string
+}
+
+type clientKey struct{}
+
+// WithClient returns a copy of ctx carrying client, which logins record on
+// the session they start.
+func WithClient(ctx context.Context, client Client) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+// clientFromContext returns the client stored by WithClient, or the zero
+// Client.
+func clientFromContext(ctx context.Context) Client {
+	client, _ := ctx.Value(clientKey{}).(Client)
+	return client
+}
+
+// WithSessionRepository stores the sessions that refresh tokens belong to.
+// It is required for logins and token refreshes.
+func WithSessionRepository(sessionRepo repository.SessionRepository) Option {
+	return func(uc *UserUsecase) {
+		uc.sessionRepo = sessionRepo
+	}
+}
+
+// ListSessions returns the user's active sessions, most recently used first.
+func (uc *UserUsecase) ListSessions(ctx context.Context, userID string) ([]*dto.SessionResponse, error) {
+	sessions, err := uc.sessionRepo.List(ctx, userID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to list sessions", zap.String("user_id", userID), zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+
+	resp := make([]*dto.SessionResponse, len(sessions))
+	for i, session := range sessions {
+		resp[i] = toSessionResponse(session)
+	}
+	return resp, nil
+}
+
+// RevokeSession ends one of the user's sessions, so its refresh token is no
+// longer accepted. Access tokens it issued stay valid until they expire.
+func (uc *UserUsecase) RevokeSession(ctx context.Context, userID, sessionID string) error {
+	if err := uc.sessionRepo.Delete(ctx, userID, sessionID); err != nil {
+		if errors.Is(err, errors.ErrSessionNotFound) {
+			return errors.ErrSessionNotFound
+		}
+		logger.FromContext(ctx).Error("failed to revoke session", zap.String("user_id", userID), zap.Error(err))
+		return errors.ErrInternal
+	}
+
+	logger.FromContext(ctx).Info("session revoked",
+		zap.String("user_id", userID),
+		zap.String("session_id", sessionID),
+	)
+	return nil
+}
+
+// startSession starts a session for the client in ctx and returns its first
+// refresh token, valid for lifetime.
+func (uc *UserUsecase) startSession(ctx context.Context, userID string, lifetime time.Duration) (string, error) {
+	client := clientFromContext(ctx)
+	return uc.issueRefreshToken(ctx, entity.NewSession(userID, client.UserAgent, client.IPAddress), lifetime)
+}
+
+// issueRefreshToken generates a refresh token of session valid for lifetime
+// and stores it as the session's only accepted one, implicitly invalidating
+// the token it replaces.
+func (uc *UserUsecase) issueRefreshToken(ctx context.Context, session *entity.Session, lifetime time.Duration) (string, error) {
+	refreshToken, tokenID, err := uc.jwtManager.GenerateRefreshToken(session.UserID, session.ID, lifetime)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to generate refresh token", zap.Error(err))
+		return "", errors.ErrInternal
+	}
+
+	session.Rotate(tokenID, lifetime)
+	if err := uc.sessionRepo.Save(ctx, session); err != nil {
+		logger.FromContext(ctx).Error("failed to store session", zap.Error(err))
+		return "", errors.ErrInternal
+	}
+
+	return refreshToken, nil
+}
+
+// rotateRefreshToken issues session's next refresh token in place of
+// previousTokenID. Should a concurrent refresh with the same token have
+// rotated the session first, the token was used twice and the session is
+// revoked as for any reuse.
+func (uc *UserUsecase) rotateRefreshToken(ctx context.Context, session *entity.Session, previousTokenID string, lifetime time.Duration) (string, error) {
+	refreshToken, tokenID, err := uc.jwtManager.GenerateRefreshToken(session.UserID, session.ID, lifetime)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to generate refresh token", zap.Error(err))
+		return "", errors.ErrInternal
+	}
+
+	session.Rotate(tokenID, lifetime)
+	if err := uc.sessionRepo.Rotate(ctx, session, previousTokenID); err != nil {
+		switch {
+		case errors.Is(err, errors.ErrStaleData):
+			return "", uc.revokeReusedSession(ctx, session)
+		case errors.Is(err, errors.ErrSessionNotFound):
+			return "", errors.ErrInvalidToken
+		}
+		logger.FromContext(ctx).Error("failed to store session", zap.Error(err))
+		return "", errors.ErrInternal
+	}
+
+	return refreshToken, nil
+}
+
+// revokeReusedSession ends session after one of its already rotated refresh
+// tokens was replayed, which means it has leaked: the legitimate holder must
+// log in again too. It returns the error to answer the replay with.
+func (uc *UserUsecase) revokeReusedSession(ctx context.Context, session *entity.Session) error {
+	if err := uc.sessionRepo.Delete(ctx, session.UserID, session.ID); err != nil && !errors.Is(err, errors.ErrSessionNotFound) {
+		logger.FromContext(ctx).Error("failed to revoke session", zap.Error(err))
+	}
+	logger.FromContext(ctx).Warn("refresh token reuse detected, session revoked",
+		zap.String("user_id", session.UserID),
+		zap.String("session_id", session.ID),
+	)
+	return errors.ErrInvalidToken
+}
+
+// refreshSession returns the session a validated refresh token belongs to,
+// or ErrInvalidToken once the session has ended. Callers must still check
+// that the token is the session's current one.
+func (uc *UserUsecase) refreshSession(ctx context.Context, claims *jwt.RefreshClaims) (*entity.Session, error) {
+	// Tokens issued before sessions were tracked carry no session ID.
+	if claims.SessionID == "" {
+		return nil, errors.ErrInvalidToken
+	}
+
+	session, err := uc.sessionRepo.Get(ctx, claims.UserID, claims.SessionID)
+	if err != nil {
+		if errors.Is(err, errors.ErrSessionNotFound) {
+			return nil, errors.ErrInvalidToken
+		}
+		logger.FromContext(ctx).Error("failed to get session", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+	return session, nil
+}
+
+func toSessionResponse(session *entity.Session) *dto.SessionResponse {
+	return &dto.SessionResponse{
+		ID:         session.ID,
+		UserAgent:  session.UserAgent,
+		IPAddress:  session.IPAddress,
+		CreatedAt:  session.CreatedAt.UTC(),
+		LastUsedAt: session.LastUsedAt.UTC(),
+		ExpiresAt:  session.ExpiresAt.UTC(),
+	}
+}
@@ -0,0 +1,83 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/dto"
+	"github.com/TubagusAldiMY/go-template/internal/shared/constants"
+	"github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/TubagusAldiMY/go-template/pkg/logger"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// statsTTL bounds how stale cached stats can get. Writes that change the
+// counts invalidate them, but a count computed concurrently with such a write
+// may be stored right after the invalidation.
+const statsTTL = time.Minute
+
+// GetStats returns user counts by status. They are cached under a single key;
+// concurrent misses share one count query, and a cache that cannot be read
+// or written only costs that query.
+func (uc *UserUsecase) GetStats(ctx context.Context) (*dto.UserStatsResponse, error) {
+	data, err := uc.cache.Get(ctx, constants.CacheKeyUserStats)
+	if err == nil {
+		var stats dto.UserStatsResponse
+		if err := json.Unmarshal([]byte(data), &stats); err == nil {
+			return &stats, nil
+		}
+		logger.FromContext(ctx).Warn("failed to decode cached user stats", zap.Error(err))
+	} else if !errors.Is(err, redis.Nil) {
+		logger.FromContext(ctx).Warn("failed to get cached user stats", zap.Error(err))
+	}
+
+	// The shared load is not cancelled by whichever caller happens to start it.
+	result, err, _ := uc.statsGroup.Do(constants.CacheKeyUserStats, func() (interface{}, error) {
+		return uc.loadStats(context.WithoutCancel(ctx))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	stats := *result.(*dto.UserStatsResponse)
+	return &stats, nil
+}
+
+// loadStats counts users by status and caches the result.
+func (uc *UserUsecase) loadStats(ctx context.Context) (*dto.UserStatsResponse, error) {
+	counts, err := uc.userRepo.CountByStatus(ctx)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to count users by status", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+
+	stats := &dto.UserStatsResponse{
+		Active:   counts[constants.UserStatusActive],
+		Inactive: counts[constants.UserStatusInactive],
+		Banned:   counts[constants.UserStatusBanned],
+	}
+	for _, count := range counts {
+		stats.Total += count
+	}
+
+	data, err := json.Marshal(stats)
+	if err != nil {
+		logger.FromContext(ctx).Warn("failed to encode user stats", zap.Error(err))
+		return stats, nil
+	}
+	if err := uc.cache.Set(ctx, constants.CacheKeyUserStats, data, statsTTL); err != nil {
+		logger.FromContext(ctx).Warn("failed to cache user stats", zap.Error(err))
+	}
+
+	return stats, nil
+}
+
+// invalidateStats drops the cached stats after a change to the counts. It is
+// best-effort: the change is already committed and the TTL caps staleness.
+func (uc *UserUsecase) invalidateStats(ctx context.Context) {
+	if err := uc.cache.Delete(ctx, constants.CacheKeyUserStats); err != nil {
+		logger.FromContext(ctx).Warn("failed to invalidate user stats", zap.Error(err))
+	}
+}
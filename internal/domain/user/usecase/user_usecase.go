@@ -2,94 +2,437 @@ package usecase
 
 import (
 	"context"
-	"fmt"
+	"runtime"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/TubagusAldiMY/go-template/internal/domain/user/dto"
 	"github.com/TubagusAldiMY/go-template/internal/domain/user/entity"
 	"github.com/TubagusAldiMY/go-template/internal/domain/user/repository"
-	"github.com/TubagusAldiMY/go-template/internal/infrastructure/cache"
 	"github.com/TubagusAldiMY/go-template/internal/shared/constants"
+	"github.com/TubagusAldiMY/go-template/internal/shared/crud"
 	"github.com/TubagusAldiMY/go-template/internal/shared/errors"
-	"github.com/TubagusAldiMY/go-template/pkg/crypto"
+	"github.com/TubagusAldiMY/go-template/internal/shared/utils"
 	"github.com/TubagusAldiMY/go-template/pkg/jwt"
 	"github.com/TubagusAldiMY/go-template/pkg/logger"
+	"github.com/TubagusAldiMY/go-template/pkg/notify"
+	"github.com/TubagusAldiMY/go-template/pkg/validator"
 	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/singleflight"
 )
 
+// PasswordHasher hashes and verifies user passwords.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Compare(hashedPassword, password string) error
+	IsValid(hashedPassword, password string) bool
+	NeedsRehash(hashedPassword string) bool
+}
+
+// JWTManager issues and validates authentication tokens.
+type JWTManager interface {
+	GenerateAccessToken(ctx context.Context, userID, email, role string) (string, error)
+	GenerateRefreshToken(userID, sessionID string, duration time.Duration) (string, string, error)
+	// GenerateImpersonationToken returns an access token for userID whose
+	// act claim records actorID.
+	GenerateImpersonationToken(ctx context.Context, userID, email, role, actorID string) (string, error)
+	ImpersonationTokenDuration() time.Duration
+	ValidateAccessToken(ctx context.Context, tokenString string) (*jwt.Claims, error)
+	ValidateRefreshToken(tokenString string) (*jwt.RefreshClaims, error)
+	RefreshTokenDuration() time.Duration
+	RememberMeRefreshTokenDuration() time.Duration
+}
+
+// Cache is the key-value store used by the use case.
+type Cache interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// DisposableEmailChecker recognizes addresses at throwaway email domains.
+type DisposableEmailChecker interface {
+	IsDisposable(email string) bool
+}
+
+// TxManager runs fn atomically; repository calls made with the ctx passed to
+// fn take part in the same transaction.
+type TxManager interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// AuditLogger records administrative actions. The actor is resolved from ctx.
+type AuditLogger interface {
+	Record(ctx context.Context, action, targetID string, metadata map[string]interface{}) error
+}
+
+// EventPublisher broadcasts user lifecycle events under a routing key.
+type EventPublisher interface {
+	Publish(ctx context.Context, routingKey string, event interface{}) error
+}
+
+type noEventPublisher struct{}
+
+func (noEventPublisher) Publish(context.Context, string, interface{}) error {
+	return nil
+}
+
+type noAuditLogger struct{}
+
+func (noAuditLogger) Record(context.Context, string, string, map[string]interface{}) error {
+	return nil
+}
+
+// noTxManager runs fn directly, for use cases built without a TxManager.
+type noTxManager struct{}
+
+func (noTxManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
 type UserUsecase struct {
+	// Base loads users by ID and maps them to responses.
+	crud.Base[entity.User, dto.UserResponse]
+
 	userRepo       repository.UserRepository
-	passwordHasher *crypto.PasswordHasher
-	jwtManager     *jwt.Manager
-	cache          *cache.Redis
+	passwordHasher PasswordHasher
+	jwtManager     JWTManager
+	cache          Cache
+	sessionRepo    repository.SessionRepository
+	tokenEpochs    repository.TokenEpochRepository
+	txManager      TxManager
+	auditLogger    AuditLogger
+	eventPublisher EventPublisher
+	notifier       notify.Notifier
+	totpCipher     SecretCipher
+	totpIssuer     string
+	totpRepo       repository.MFARepository
+	avatarStorage  FileStorage
+	maxAvatarSize  int64
+
+	// disposableEmails, when set, rejects registrations from throwaway
+	// email domains.
+	disposableEmails DisposableEmailChecker
+
+	// registrations, when set, limits the accounts registered per IP
+	// address to registrationLimit.
+	registrations     repository.RegistrationCounter
+	registrationLimit int64
+
+	similarityThreshold float64
+
+	// statsGroup collapses concurrent stats cache misses into one query.
+	statsGroup singleflight.Group
+}
+
+// Option configures optional UserUsecase dependencies.
+type Option func(*UserUsecase)
+
+// WithAuditLogger records admin actions such as deletes and status changes.
+func WithAuditLogger(auditLogger AuditLogger) Option {
+	return func(uc *UserUsecase) {
+		uc.auditLogger = auditLogger
+	}
+}
+
+// WithEventPublisher publishes user created, updated and deleted events.
+func WithEventPublisher(eventPublisher EventPublisher) Option {
+	return func(uc *UserUsecase) {
+		uc.eventPublisher = eventPublisher
+	}
+}
+
+// WithNotifier sends the emails addressed to users, such as password reset
+// links. Without it they are dropped.
+func WithNotifier(notifier notify.Notifier) Option {
+	return func(uc *UserUsecase) {
+		uc.notifier = notifier
+	}
+}
+
+// WithSimilarityThreshold sets the minimum similarity of fuzzy user search
+// results, between 0 and 1. Without it the repository default applies.
+func WithSimilarityThreshold(threshold float64) Option {
+	return func(uc *UserUsecase) {
+		uc.similarityThreshold = threshold
+	}
+}
+
+// WithDisposableEmailCheck makes Register reject emails that checker reports
+// as disposable with ErrDisposableEmail.
+func WithDisposableEmailCheck(checker DisposableEmailChecker) Option {
+	return func(uc *UserUsecase) {
+		uc.disposableEmails = checker
+	}
+}
+
+// WithTokenEpochRepository makes password changes revoke the user's access
+// tokens by bumping their token epoch. The JWT manager must check epochs
+// against the same store.
+func WithTokenEpochRepository(tokenEpochs repository.TokenEpochRepository) Option {
+	return func(uc *UserUsecase) {
+		uc.tokenEpochs = tokenEpochs
+	}
+}
+
+// WithTxManager makes multi-step writes atomic.
+func WithTxManager(txManager TxManager) Option {
+	return func(uc *UserUsecase) {
+		uc.txManager = txManager
+	}
 }
 
 func NewUserUsecase(
 	userRepo repository.UserRepository,
-	passwordHasher *crypto.PasswordHasher,
-	jwtManager *jwt.Manager,
-	cache *cache.Redis,
+	passwordHasher PasswordHasher,
+	jwtManager JWTManager,
+	cache Cache,
+	opts ...Option,
 ) *UserUsecase {
-	return &UserUsecase{
+	uc := &UserUsecase{
 		userRepo:       userRepo,
 		passwordHasher: passwordHasher,
 		jwtManager:     jwtManager,
 		cache:          cache,
+		txManager:      noTxManager{},
+		auditLogger:    noAuditLogger{},
+		eventPublisher: noEventPublisher{},
+		notifier:       notify.Discard,
+	}
+	uc.Base = crud.NewBase(userRepo, "user", errors.ErrUserNotFound, toUserResponse)
+
+	for _, opt := range opts {
+		opt(uc)
 	}
+
+	return uc
 }
 
 func (uc *UserUsecase) Register(ctx context.Context, req *dto.RegisterRequest) (*dto.UserResponse, error) {
-	// Check if email already exists
-	exists, err := uc.userRepo.ExistsByEmail(ctx, req.Email)
-	if err != nil {
-		logger.Error("failed to check email existence", zap.Error(err))
-		return nil, errors.ErrInternal
-	}
-	if exists {
-		return nil, errors.ErrEmailAlreadyExists
+	email := utils.NormalizeEmail(req.Email)
+	if uc.disposableEmails != nil && uc.disposableEmails.IsDisposable(email) {
+		return nil, errors.ErrDisposableEmail
 	}
 
-	// Check if username already exists
-	exists, err = uc.userRepo.ExistsByUsername(ctx, req.Username)
+	release, err := uc.reserveRegistration(ctx, clientFromContext(ctx).IPAddress)
 	if err != nil {
-		logger.Error("failed to check username existence", zap.Error(err))
-		return nil, errors.ErrInternal
+		return nil, err
 	}
-	if exists {
-		return nil, errors.ErrUsernameAlreadyExists
+	registered := false
+	defer func() {
+		if !registered {
+			release()
+		}
+	}()
+
+	// Check if email or username already exists
+	if err := uc.checkAvailability(ctx, email, req.Username); err != nil {
+		return nil, err
 	}
 
 	// Hash password
 	hashedPassword, err := uc.passwordHasher.Hash(req.Password)
 	if err != nil {
-		logger.Error("failed to hash password", zap.Error(err))
+		logger.FromContext(ctx).Error("failed to hash password", zap.Error(err))
 		return nil, errors.ErrInternal
 	}
 
 	// Create user entity
-	user := entity.NewUser(req.Email, req.Username, hashedPassword, req.FullName, constants.RoleUser)
+	user := entity.NewUser(email, req.Username, hashedPassword, req.FullName, constants.RoleUser)
 
-	// Save to database
+	// Save to database. A concurrent registration can still win the race
+	// past checkAvailability; the repository reports it as a conflict.
 	if err := uc.userRepo.Create(ctx, user); err != nil {
-		logger.Error("failed to create user", zap.Error(err))
+		if errors.Is(err, errors.ErrEmailAlreadyExists) || errors.Is(err, errors.ErrUsernameAlreadyExists) {
+			return nil, err
+		}
+		logger.FromContext(ctx).Error("failed to create user", zap.Error(err))
 		return nil, errors.ErrInternal
 	}
 
-	logger.Info("user registered successfully",
+	logger.FromContext(ctx).Info("user registered successfully",
 		zap.String("user_id", user.ID),
 		zap.String("email", user.Email),
 	)
 
-	return uc.toUserResponse(user), nil
+	registered = true
+	uc.invalidateStats(ctx)
+	uc.publishUserEvent(ctx, constants.RoutingKeyUserCreated, newUserEvent(constants.RoutingKeyUserCreated, user))
+
+	return uc.Response(user), nil
+}
+
+// BulkCreate validates and creates many users in one transaction. Rows that
+// fail validation or collide with an existing or earlier email/username are
+// reported per row. In strict mode any failure rejects the whole batch;
+// otherwise the remaining rows are still created.
+func (uc *UserUsecase) BulkCreate(ctx context.Context, req *dto.BulkCreateUsersRequest) (*dto.BulkCreateUsersResponse, error) {
+	resp := &dto.BulkCreateUsersResponse{
+		Results: make([]*dto.BulkCreateUserResult, len(req.Users)),
+	}
+
+	seenEmails := make(map[string]bool, len(req.Users))
+	seenUsernames := make(map[string]bool, len(req.Users))
+	rows := make([]*dto.RegisterRequest, 0, len(req.Users))
+	results := make([]*dto.BulkCreateUserResult, 0, len(req.Users))
+
+	for i := range req.Users {
+		row := &req.Users[i]
+		result := &dto.BulkCreateUserResult{Index: i, Email: row.Email}
+		resp.Results[i] = result
+
+		if err := validator.Validate(row); err != nil {
+			result.Error = "validation failed"
+			result.Errors = validator.FormatValidationErrors(err)
+			continue
+		}
+
+		row.Email = utils.NormalizeEmail(row.Email)
+		email := row.Email
+		username := strings.ToLower(row.Username)
+		if seenEmails[email] {
+			result.Error = errors.ErrEmailAlreadyExists.Error()
+			continue
+		}
+		if seenUsernames[username] {
+			result.Error = errors.ErrUsernameAlreadyExists.Error()
+			continue
+		}
+		seenEmails[email] = true
+		seenUsernames[username] = true
+
+		if err := uc.checkAvailability(ctx, row.Email, row.Username); err != nil {
+			if !errors.Is(err, errors.ErrEmailAlreadyExists) && !errors.Is(err, errors.ErrUsernameAlreadyExists) {
+				return nil, err
+			}
+			result.Error = err.Error()
+			continue
+		}
+
+		rows = append(rows, row)
+		results = append(results, result)
+	}
+
+	resp.Failed = len(req.Users) - len(rows)
+	if len(rows) == 0 || (req.Strict && resp.Failed > 0) {
+		return resp, nil
+	}
+
+	users, err := uc.newUsers(ctx, rows)
+	if err != nil {
+		return nil, err
+	}
+
+	userIDs := make([]string, len(users))
+	for i, user := range users {
+		userIDs[i] = user.ID
+	}
+
+	err = uc.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := uc.userRepo.CreateBatch(ctx, users); err != nil {
+			return err
+		}
+		return uc.auditLogger.Record(ctx, constants.AuditActionUserBulkCreated, "", map[string]interface{}{
+			"user_ids": userIDs,
+		})
+	})
+	if err != nil {
+		if errors.Is(err, errors.ErrEmailAlreadyExists) || errors.Is(err, errors.ErrUsernameAlreadyExists) {
+			return nil, err
+		}
+		logger.FromContext(ctx).Error("failed to create users in bulk", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+
+	for i, user := range users {
+		results[i].Success = true
+		results[i].User = uc.Response(user)
+		uc.publishUserEvent(ctx, constants.RoutingKeyUserCreated, newUserEvent(constants.RoutingKeyUserCreated, user))
+	}
+	resp.Created = len(users)
+	uc.invalidateStats(ctx)
+
+	logger.FromContext(ctx).Info("users created in bulk",
+		zap.Int("created", resp.Created),
+		zap.Int("failed", resp.Failed),
+	)
+
+	return resp, nil
+}
+
+// newUsers hashes the passwords of rows, a few at a time, and returns the
+// users to create from them, in order. Hashing dominates a bulk create, so
+// running it on every CPU keeps a full batch within the handler timeout.
+func (uc *UserUsecase) newUsers(ctx context.Context, rows []*dto.RegisterRequest) ([]*entity.User, error) {
+	users := make([]*entity.User, len(rows))
+
+	var g errgroup.Group
+	g.SetLimit(runtime.GOMAXPROCS(0))
+	for i, row := range rows {
+		i, row := i, row
+		g.Go(func() error {
+			hashedPassword, err := uc.passwordHasher.Hash(row.Password)
+			if err != nil {
+				return err
+			}
+			users[i] = entity.NewUser(row.Email, row.Username, hashedPassword, row.FullName, constants.RoleUser)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		logger.FromContext(ctx).Error("failed to hash password", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+
+	return users, nil
+}
+
+// checkAvailability returns ErrEmailAlreadyExists or ErrUsernameAlreadyExists
+// when either is taken, and ErrInternal if the lookup fails.
+func (uc *UserUsecase) checkAvailability(ctx context.Context, email, username string) error {
+	exists, err := uc.userRepo.ExistsByEmail(ctx, email)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to check email existence", zap.Error(err))
+		return errors.ErrInternal
+	}
+	if exists {
+		return errors.ErrEmailAlreadyExists
+	}
+
+	exists, err = uc.userRepo.ExistsByUsername(ctx, username)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to check username existence", zap.Error(err))
+		return errors.ErrInternal
+	}
+	if exists {
+		return errors.ErrUsernameAlreadyExists
+	}
+
+	return nil
 }
 
 func (uc *UserUsecase) Login(ctx context.Context, req *dto.LoginRequest) (*dto.LoginResponse, error) {
-	// Get user by email
-	user, err := uc.userRepo.GetByEmail(ctx, req.Email)
+	identifier := req.Identifier
+	if identifier == "" {
+		identifier = req.Email
+	}
+
+	// Look the user up by email or username depending on the identifier's
+	// shape. Either miss maps to the same error so usernames are not leaked.
+	var user *entity.User
+	var err error
+	if utils.IsValidEmail(identifier) {
+		user, err = uc.userRepo.GetByEmail(ctx, utils.NormalizeEmail(identifier))
+	} else {
+		user, err = uc.userRepo.GetByUsername(ctx, identifier)
+	}
 	if err != nil {
 		if errors.Is(err, errors.ErrUserNotFound) {
 			return nil, errors.ErrInvalidCredentials
 		}
-		logger.Error("failed to get user by email", zap.Error(err))
+		logger.FromContext(ctx).Error("failed to get user for login", zap.Error(err))
 		return nil, errors.ErrInternal
 	}
 
@@ -103,39 +446,104 @@ func (uc *UserUsecase) Login(ctx context.Context, req *dto.LoginRequest) (*dto.L
 		return nil, errors.ErrInvalidCredentials
 	}
 
-	// Generate tokens
-	accessToken, err := uc.jwtManager.GenerateAccessToken(user.ID, user.Email, user.Role)
+	if uc.passwordHasher.NeedsRehash(user.Password) {
+		uc.rehashPassword(ctx, user, req.Password)
+	}
+
+	if user.MFAEnabled {
+		return uc.startMFAChallenge(ctx, user, req.RememberMe)
+	}
+
+	return uc.issueLoginTokens(ctx, user, req.RememberMe)
+}
+
+// issueLoginTokens completes a successful login by issuing the user's
+// access and refresh tokens. rememberMe selects the extended refresh token
+// lifetime.
+func (uc *UserUsecase) issueLoginTokens(ctx context.Context, user *entity.User, rememberMe bool) (*dto.LoginResponse, error) {
+	accessToken, err := uc.jwtManager.GenerateAccessToken(ctx, user.ID, user.Email, user.Role)
 	if err != nil {
-		logger.Error("failed to generate access token", zap.Error(err))
+		logger.FromContext(ctx).Error("failed to generate access token", zap.Error(err))
 		return nil, errors.ErrInternal
 	}
 
-	refreshToken, err := uc.jwtManager.GenerateRefreshToken(user.ID)
+	lifetime := uc.jwtManager.RefreshTokenDuration()
+	if rememberMe {
+		lifetime = uc.jwtManager.RememberMeRefreshTokenDuration()
+	}
+
+	refreshToken, err := uc.startSession(ctx, user.ID, lifetime)
 	if err != nil {
-		logger.Error("failed to generate refresh token", zap.Error(err))
-		return nil, errors.ErrInternal
+		return nil, err
 	}
 
-	logger.Info("user logged in successfully",
+	uc.recordLogin(ctx, user)
+
+	logger.FromContext(ctx).Info("user logged in successfully",
 		zap.String("user_id", user.ID),
 		zap.String("email", user.Email),
+		zap.Bool("remember_me", rememberMe),
 	)
 
 	return &dto.LoginResponse{
-		User:         uc.toUserResponse(user),
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		TokenType:    "Bearer",
-		ExpiresIn:    900, // 15 minutes
+		User:             uc.Response(user),
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		TokenType:        "Bearer",
+		ExpiresIn:        900, // 15 minutes
+		RefreshExpiresIn: int64(lifetime.Seconds()),
 	}, nil
 }
 
+// rehashPassword upgrades a verified password to the current hashing
+// settings. Failures are logged and never block the login.
+func (uc *UserUsecase) rehashPassword(ctx context.Context, user *entity.User, password string) {
+	hashedPassword, err := uc.passwordHasher.Hash(password)
+	if err != nil {
+		logger.FromContext(ctx).Warn("failed to rehash password", zap.String("user_id", user.ID), zap.Error(err))
+		return
+	}
+
+	previous := user.Password
+	user.UpdatePassword(hashedPassword)
+
+	if err := uc.userRepo.Update(ctx, user); err != nil {
+		user.Password = previous
+		logger.FromContext(ctx).Warn("failed to persist rehashed password", zap.String("user_id", user.ID), zap.Error(err))
+		return
+	}
+
+	logger.FromContext(ctx).Info("password rehashed", zap.String("user_id", user.ID))
+}
+
+// recordLogin stores the current time as the user's last login. Failures are
+// logged and never block the login.
+func (uc *UserUsecase) recordLogin(ctx context.Context, user *entity.User) {
+	now := time.Now()
+	if err := uc.userRepo.UpdateLastLogin(ctx, user.ID, now); err != nil {
+		logger.FromContext(ctx).Warn("failed to record last login", zap.String("user_id", user.ID), zap.Error(err))
+		return
+	}
+
+	user.LastLoginAt = &now
+}
+
 func (uc *UserUsecase) RefreshToken(ctx context.Context, req *dto.RefreshTokenRequest) (*dto.RefreshTokenResponse, error) {
 	// Validate refresh token
-	userID, err := uc.jwtManager.ValidateRefreshToken(req.RefreshToken)
+	claims, err := uc.jwtManager.ValidateRefreshToken(req.RefreshToken)
 	if err != nil {
 		return nil, errors.ErrInvalidToken
 	}
+	userID := claims.UserID
+
+	// Only the most recently issued refresh token of a session is valid.
+	session, err := uc.refreshSession(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+	if session.TokenID != claims.TokenID {
+		return nil, uc.revokeReusedSession(ctx, session)
+	}
 
 	// Get user
 	user, err := uc.userRepo.GetByID(ctx, userID)
@@ -143,7 +551,7 @@ func (uc *UserUsecase) RefreshToken(ctx context.Context, req *dto.RefreshTokenRe
 		if errors.Is(err, errors.ErrUserNotFound) {
 			return nil, errors.ErrUnauthorized
 		}
-		logger.Error("failed to get user by id", zap.Error(err))
+		logger.FromContext(ctx).Error("failed to get user by id", zap.Error(err))
 		return nil, errors.ErrInternal
 	}
 
@@ -153,81 +561,199 @@ func (uc *UserUsecase) RefreshToken(ctx context.Context, req *dto.RefreshTokenRe
 	}
 
 	// Generate new tokens
-	accessToken, err := uc.jwtManager.GenerateAccessToken(user.ID, user.Email, user.Role)
+	accessToken, err := uc.jwtManager.GenerateAccessToken(ctx, user.ID, user.Email, user.Role)
 	if err != nil {
-		logger.Error("failed to generate access token", zap.Error(err))
+		logger.FromContext(ctx).Error("failed to generate access token", zap.Error(err))
 		return nil, errors.ErrInternal
 	}
 
-	refreshToken, err := uc.jwtManager.GenerateRefreshToken(user.ID)
+	// The rotated token keeps the lifetime of the one it replaces, so
+	// "remember me" sessions stay long-lived.
+	refreshToken, err := uc.rotateRefreshToken(ctx, session, claims.TokenID, claims.Lifetime)
 	if err != nil {
-		logger.Error("failed to generate refresh token", zap.Error(err))
-		return nil, errors.ErrInternal
+		return nil, err
 	}
 
 	return &dto.RefreshTokenResponse{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		TokenType:    "Bearer",
-		ExpiresIn:    900,
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		TokenType:        "Bearer",
+		ExpiresIn:        900,
+		RefreshExpiresIn: int64(claims.Lifetime.Seconds()),
 	}, nil
 }
 
-func (uc *UserUsecase) GetProfile(ctx context.Context, userID string) (*dto.UserResponse, error) {
-	// Try to get from cache first
-	cacheKey := fmt.Sprintf("%s%s", constants.CacheKeyUserPrefix, userID)
+// Logout ends the session of the refresh token when it is the session's
+// current token. Invalid, expired or already rotated tokens have nothing left
+// to revoke, so logging out with them still succeeds.
+func (uc *UserUsecase) Logout(ctx context.Context, req *dto.LogoutRequest) error {
+	if req.RefreshToken == "" {
+		return nil
+	}
 
-	user, err := uc.userRepo.GetByID(ctx, userID)
+	claims, err := uc.jwtManager.ValidateRefreshToken(req.RefreshToken)
 	if err != nil {
-		if errors.Is(err, errors.ErrUserNotFound) {
-			return nil, errors.ErrUserNotFound
+		return nil
+	}
+
+	session, err := uc.refreshSession(ctx, claims)
+	if err != nil {
+		if errors.Is(err, errors.ErrInvalidToken) {
+			return nil
 		}
-		logger.Error("failed to get user profile", zap.Error(err))
-		return nil, errors.ErrInternal
+		return err
+	}
+	if session.TokenID != claims.TokenID {
+		return nil
+	}
+
+	if err := uc.sessionRepo.Delete(ctx, claims.UserID, session.ID); err != nil && !errors.Is(err, errors.ErrSessionNotFound) {
+		logger.FromContext(ctx).Error("failed to revoke session", zap.Error(err))
+		return errors.ErrInternal
 	}
 
-	// Cache the user
-	_ = uc.cache.Set(ctx, cacheKey, user, constants.CacheTTLMedium)
+	logger.FromContext(ctx).Info("user logged out",
+		zap.String("user_id", claims.UserID),
+		zap.String("session_id", session.ID),
+	)
 
-	return uc.toUserResponse(user), nil
+	return nil
+}
+
+func (uc *UserUsecase) GetProfile(ctx context.Context, userID string) (*dto.UserResponse, error) {
+	return uc.Get(ctx, userID)
 }
 
 func (uc *UserUsecase) UpdateProfile(ctx context.Context, userID string, req *dto.UpdateProfileRequest) (*dto.UserResponse, error) {
-	user, err := uc.userRepo.GetByID(ctx, userID)
+	user, err := uc.Load(ctx, userID)
 	if err != nil {
-		if errors.Is(err, errors.ErrUserNotFound) {
-			return nil, errors.ErrUserNotFound
+		return nil, err
+	}
+
+	if req.Email != "" {
+		if err := uc.changeEmail(ctx, user, req.Email); err != nil {
+			return nil, err
+		}
+	}
+	if req.Username != "" {
+		if err := uc.changeUsername(ctx, user, req.Username); err != nil {
+			return nil, err
 		}
-		logger.Error("failed to get user", zap.Error(err))
-		return nil, errors.ErrInternal
 	}
 
 	user.UpdateProfile(req.FullName)
+	user.SetPreferences(req.Timezone, req.Locale)
+
+	return uc.saveProfile(ctx, user)
+}
 
+// PatchProfile applies a JSON Merge Patch to the user's profile, changing
+// only the fields present in req and clearing those set to null.
+func (uc *UserUsecase) PatchProfile(ctx context.Context, userID string, req *dto.PatchProfileRequest) (*dto.UserResponse, error) {
+	user, err := uc.Load(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Email != nil {
+		if err := uc.changeEmail(ctx, user, *req.Email); err != nil {
+			return nil, err
+		}
+	}
+	if req.Username != nil {
+		if err := uc.changeUsername(ctx, user, *req.Username); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.FullName != nil || req.Clears("full_name") {
+		user.SetFullName(valueOrEmpty(req.FullName))
+	}
+	if req.Timezone != nil || req.Clears("timezone") {
+		user.SetTimezone(valueOrEmpty(req.Timezone))
+	}
+	if req.Locale != nil || req.Clears("locale") {
+		user.SetLocale(valueOrEmpty(req.Locale))
+	}
+
+	return uc.saveProfile(ctx, user)
+}
+
+func valueOrEmpty(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// changeEmail changes the user's email, unless another user has it. Only an
+// email that actually changes is checked, so resubmitting the user's own is
+// not a conflict.
+func (uc *UserUsecase) changeEmail(ctx context.Context, user *entity.User, email string) error {
+	email = utils.NormalizeEmail(email)
+	if email == user.Email {
+		return nil
+	}
+
+	exists, err := uc.userRepo.ExistsByEmail(ctx, email)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to check email existence", zap.Error(err))
+		return errors.ErrInternal
+	}
+	if exists {
+		return errors.ErrEmailAlreadyExists
+	}
+	user.ChangeEmail(email)
+	return nil
+}
+
+// changeUsername is changeEmail for the username.
+func (uc *UserUsecase) changeUsername(ctx context.Context, user *entity.User, username string) error {
+	if username == user.Username {
+		return nil
+	}
+
+	exists, err := uc.userRepo.ExistsByUsername(ctx, username)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to check username existence", zap.Error(err))
+		return errors.ErrInternal
+	}
+	if exists {
+		return errors.ErrUsernameAlreadyExists
+	}
+	user.ChangeUsername(username)
+	return nil
+}
+
+// saveProfile stores the user's changed profile and publishes the updated
+// event.
+func (uc *UserUsecase) saveProfile(ctx context.Context, user *entity.User) (*dto.UserResponse, error) {
 	if err := uc.userRepo.Update(ctx, user); err != nil {
-		logger.Error("failed to update user", zap.Error(err))
+		switch {
+		case errors.Is(err, errors.ErrStaleData),
+			errors.Is(err, errors.ErrEmailAlreadyExists),
+			errors.Is(err, errors.ErrUsernameAlreadyExists):
+			return nil, err
+		}
+		logger.FromContext(ctx).Error("failed to update user", zap.Error(err))
 		return nil, errors.ErrInternal
 	}
 
-	// Invalidate cache
-	cacheKey := fmt.Sprintf("%s%s", constants.CacheKeyUserPrefix, userID)
-	_ = uc.cache.Delete(ctx, cacheKey)
-
-	logger.Info("user profile updated",
-		zap.String("user_id", userID),
+	logger.FromContext(ctx).Info("user profile updated",
+		zap.String("user_id", user.ID),
 	)
 
-	return uc.toUserResponse(user), nil
+	uc.publishUserEvent(ctx, constants.RoutingKeyUserUpdated, newUserEvent(constants.RoutingKeyUserUpdated, user))
+
+	return uc.Response(user), nil
 }
 
+// ChangePassword signs the user out everywhere before saving the new password,
+// so a failed sign-out leaves the old password in place and can be retried.
 func (uc *UserUsecase) ChangePassword(ctx context.Context, userID string, req *dto.ChangePasswordRequest) error {
-	user, err := uc.userRepo.GetByID(ctx, userID)
+	user, err := uc.Load(ctx, userID)
 	if err != nil {
-		if errors.Is(err, errors.ErrUserNotFound) {
-			return errors.ErrUserNotFound
-		}
-		logger.Error("failed to get user", zap.Error(err))
-		return errors.ErrInternal
+		return err
 	}
 
 	// Verify old password
@@ -238,68 +764,328 @@ func (uc *UserUsecase) ChangePassword(ctx context.Context, userID string, req *d
 	// Hash new password
 	hashedPassword, err := uc.passwordHasher.Hash(req.NewPassword)
 	if err != nil {
-		logger.Error("failed to hash password", zap.Error(err))
+		logger.FromContext(ctx).Error("failed to hash password", zap.Error(err))
 		return errors.ErrInternal
 	}
 
+	if err := uc.revokeTokens(ctx, userID); err != nil {
+		return errors.ErrSignOutFailed.Wrap(err)
+	}
+
 	user.UpdatePassword(hashedPassword)
 
 	if err := uc.userRepo.Update(ctx, user); err != nil {
-		logger.Error("failed to update password", zap.Error(err))
+		if errors.Is(err, errors.ErrStaleData) {
+			return errors.ErrStaleData
+		}
+		logger.FromContext(ctx).Error("failed to update password", zap.Error(err))
 		return errors.ErrInternal
 	}
 
-	logger.Info("password changed successfully",
+	logger.FromContext(ctx).Info("password changed successfully",
 		zap.String("user_id", userID),
 	)
 
 	return nil
 }
 
+// revokeTokens signs the user out everywhere, including the session that
+// made the request: bumping the token epoch revokes their access tokens and
+// deleting their sessions their refresh tokens. Both are attempted even if
+// one fails, and any failure is returned so callers can abort the change
+// that called for revocation rather than leave tokens valid after it.
+func (uc *UserUsecase) revokeTokens(ctx context.Context, userID string) error {
+	var errs []error
+	if uc.tokenEpochs != nil {
+		if _, err := uc.tokenEpochs.Bump(ctx, userID); err != nil {
+			logger.FromContext(ctx).Error("failed to revoke access tokens", zap.String("user_id", userID), zap.Error(err))
+			errs = append(errs, err)
+		}
+	}
+
+	if uc.sessionRepo != nil {
+		if err := uc.sessionRepo.DeleteAll(ctx, userID); err != nil {
+			logger.FromContext(ctx).Error("failed to revoke sessions", zap.String("user_id", userID), zap.Error(err))
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
 func (uc *UserUsecase) ListUsers(ctx context.Context, req *dto.ListUsersRequest) ([]*dto.UserResponse, int64, error) {
-	users, total, err := uc.userRepo.List(ctx, req.Page, req.PageSize, req.Search, req.Role, req.Status)
+	users, total, err := uc.userRepo.List(ctx, uc.toListParams(req))
 	if err != nil {
-		logger.Error("failed to list users", zap.Error(err))
+		if errors.Is(err, errors.ErrInvalidInput) {
+			return nil, 0, err
+		}
+		logger.FromContext(ctx).Error("failed to list users", zap.Error(err))
 		return nil, 0, errors.ErrInternal
 	}
 
-	responses := make([]*dto.UserResponse, len(users))
-	for i, user := range users {
-		responses[i] = uc.toUserResponse(user)
+	return uc.Responses(users), total, nil
+}
+
+// ListUsersByCursor lists users with keyset pagination and returns the cursor
+// for the next page, which is empty once the last page is reached.
+func (uc *UserUsecase) ListUsersByCursor(ctx context.Context, req *dto.ListUsersRequest) ([]*dto.UserResponse, string, error) {
+	users, nextCursor, err := uc.userRepo.ListByCursor(ctx, uc.toListParams(req), req.Cursor)
+	if err != nil {
+		if errors.Is(err, errors.ErrInvalidCursor) {
+			return nil, "", errors.ErrInvalidCursor
+		}
+		if errors.Is(err, errors.ErrInvalidInput) {
+			return nil, "", err
+		}
+		logger.FromContext(ctx).Error("failed to list users by cursor", zap.Error(err))
+		return nil, "", errors.ErrInternal
+	}
+
+	return uc.Responses(users), nextCursor, nil
+}
+
+// ExportUsers calls fn for every user matching the ListUsers filters in req,
+// in ListUsers order, streaming them from the database so exports of any size
+// run in constant memory. Paging options in req are ignored. An error from fn
+// stops the export and is returned as is.
+func (uc *UserUsecase) ExportUsers(ctx context.Context, req *dto.ListUsersRequest, fn func(user *dto.UserResponse) error) error {
+	var fnErr error
+	err := uc.userRepo.Export(ctx, uc.toListParams(req), func(user *entity.User) error {
+		fnErr = fn(toUserResponse(user))
+		return fnErr
+	})
+	if err == nil || fnErr != nil {
+		return err
+	}
+	// A client hanging up mid-download is routine, not a failure to log.
+	if errors.Is(err, context.Canceled) || errors.Is(err, errors.ErrInvalidInput) {
+		return err
+	}
+
+	logger.FromContext(ctx).Error("failed to export users", zap.Error(err))
+	return errors.ErrInternal
+}
+
+// ChangeUserStatus sets the status of the user identified by userID. Unknown
+// statuses return ErrInvalidStatus and no-op changes ErrStatusUnchanged.
+func (uc *UserUsecase) ChangeUserStatus(ctx context.Context, userID, status string) (*dto.UserResponse, error) {
+	if !slices.Contains(constants.UserStatuses, status) {
+		return nil, errors.ErrInvalidStatus
+	}
+
+	user, err := uc.Load(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Status == status {
+		return nil, errors.ErrStatusUnchanged
+	}
+
+	previousStatus := user.Status
+	user.ChangeStatus(status)
+
+	// The cached repository invalidates the user's entries on update, so a
+	// banned user is not served from cache afterwards.
+	err = uc.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := uc.userRepo.Update(ctx, user); err != nil {
+			return err
+		}
+		return uc.auditLogger.Record(ctx, constants.AuditActionUserStatusChanged, user.ID, map[string]interface{}{
+			"from": previousStatus,
+			"to":   status,
+		})
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, errors.ErrUserNotFound):
+			return nil, errors.ErrUserNotFound
+		case errors.Is(err, errors.ErrStaleData):
+			return nil, errors.ErrStaleData
+		}
+		logger.FromContext(ctx).Error("failed to change user status", zap.Error(err))
+		return nil, errors.ErrInternal
 	}
 
-	return responses, total, nil
+	logger.FromContext(ctx).Info("user status changed",
+		zap.String("user_id", userID),
+		zap.String("from", previousStatus),
+		zap.String("to", status),
+	)
+
+	uc.invalidateStats(ctx)
+	uc.publishUserEvent(ctx, constants.RoutingKeyUserUpdated, newUserEvent(constants.RoutingKeyUserUpdated, user))
+
+	return uc.Response(user), nil
 }
 
 func (uc *UserUsecase) DeleteUser(ctx context.Context, userID string) error {
-	if err := uc.userRepo.Delete(ctx, userID); err != nil {
+	return uc.deleteUser(ctx, userID, constants.AuditActionUserDeleted)
+}
+
+// DeleteOwnAccount soft-deletes the authenticated user's own account once
+// the current password is confirmed, so a hijacked session alone cannot
+// delete it. The user is signed out everywhere first, revoking both access
+// and refresh tokens; should that fail, the account is left in place.
+func (uc *UserUsecase) DeleteOwnAccount(ctx context.Context, userID string, req *dto.DeleteAccountRequest) error {
+	user, err := uc.Load(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !uc.passwordHasher.IsValid(user.Password, req.Password) {
+		return errors.ErrInvalidPassword
+	}
+
+	if err := uc.revokeTokens(ctx, userID); err != nil {
+		return errors.ErrSignOutFailed.Wrap(err)
+	}
+
+	return uc.deleteUser(ctx, userID, constants.AuditActionUserSelfDeleted)
+}
+
+// deleteUser soft-deletes the user, recording auditAction in the same
+// transaction, and publishes the deleted event.
+func (uc *UserUsecase) deleteUser(ctx context.Context, userID, auditAction string) error {
+	err := uc.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := uc.userRepo.Delete(ctx, userID); err != nil {
+			return err
+		}
+		return uc.auditLogger.Record(ctx, auditAction, userID, nil)
+	})
+	if err != nil {
 		if errors.Is(err, errors.ErrUserNotFound) {
 			return errors.ErrUserNotFound
 		}
-		logger.Error("failed to delete user", zap.Error(err))
+		logger.FromContext(ctx).Error("failed to delete user", zap.Error(err))
 		return errors.ErrInternal
 	}
 
-	// Invalidate cache
-	cacheKey := fmt.Sprintf("%s%s", constants.CacheKeyUserPrefix, userID)
-	_ = uc.cache.Delete(ctx, cacheKey)
-
-	logger.Info("user deleted successfully",
+	logger.FromContext(ctx).Info("user deleted successfully",
 		zap.String("user_id", userID),
+		zap.String("action", auditAction),
 	)
 
+	uc.invalidateStats(ctx)
+	uc.publishUserEvent(ctx, constants.RoutingKeyUserDeleted, &dto.UserEvent{
+		Type:       constants.RoutingKeyUserDeleted,
+		UserID:     userID,
+		OccurredAt: time.Now().UTC(),
+	})
+
 	return nil
 }
 
-func (uc *UserUsecase) toUserResponse(user *entity.User) *dto.UserResponse {
+// RestoreUser undoes a soft delete and reactivates the user. Restoring a user
+// that is not deleted changes nothing and returns the user as is.
+func (uc *UserUsecase) RestoreUser(ctx context.Context, userID string) (*dto.UserResponse, error) {
+	user, err := uc.userRepo.GetByIDIncludingDeleted(ctx, userID)
+	if err != nil {
+		if errors.Is(err, errors.ErrUserNotFound) {
+			return nil, errors.ErrUserNotFound
+		}
+		logger.FromContext(ctx).Error("failed to get user", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+
+	if user.DeletedAt == nil {
+		return uc.Response(user), nil
+	}
+
+	err = uc.txManager.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := uc.userRepo.Restore(ctx, userID); err != nil {
+			return err
+		}
+		return uc.auditLogger.Record(ctx, constants.AuditActionUserRestored, userID, nil)
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, errors.ErrUserNotFound):
+			return nil, errors.ErrUserNotFound
+		// Another user took the email or username while this one was deleted.
+		case errors.Is(err, errors.ErrEmailAlreadyExists),
+			errors.Is(err, errors.ErrUsernameAlreadyExists):
+			return nil, err
+		}
+		logger.FromContext(ctx).Error("failed to restore user", zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+
+	user.Restore()
+
+	logger.FromContext(ctx).Info("user restored successfully",
+		zap.String("user_id", userID),
+	)
+
+	uc.invalidateStats(ctx)
+	uc.publishUserEvent(ctx, constants.RoutingKeyUserUpdated, newUserEvent(constants.RoutingKeyUserUpdated, user))
+
+	return uc.Response(user), nil
+}
+
+// publishUserEvent is best-effort: the change is already committed, so a
+// publishing failure is logged instead of failing the request.
+func (uc *UserUsecase) publishUserEvent(ctx context.Context, routingKey string, event *dto.UserEvent) {
+	if err := uc.eventPublisher.Publish(ctx, routingKey, event); err != nil {
+		logger.FromContext(ctx).Warn("failed to publish user event",
+			zap.String("routing_key", routingKey),
+			zap.String("user_id", event.UserID),
+			zap.Error(err),
+		)
+	}
+}
+
+func newUserEvent(eventType string, user *entity.User) *dto.UserEvent {
+	return &dto.UserEvent{
+		Type:       eventType,
+		UserID:     user.ID,
+		Email:      user.Email,
+		Username:   user.Username,
+		Role:       user.Role,
+		Status:     user.Status,
+		OccurredAt: time.Now().UTC(),
+	}
+}
+
+func (uc *UserUsecase) toListParams(req *dto.ListUsersRequest) repository.ListParams {
+	return repository.ListParams{
+		Page:                req.Page,
+		PageSize:            req.PageSize,
+		Search:              req.Search,
+		SearchMode:          req.SearchMode,
+		SimilarityThreshold: uc.similarityThreshold,
+		Role:                req.Role,
+		Status:              req.Status,
+		CreatedAfter:        req.CreatedAfter,
+		CreatedBefore:       req.CreatedBefore,
+		InactiveSince:       req.InactiveSince,
+		SortBy:              req.SortBy,
+		SortOrder:           req.SortOrder,
+	}
+}
+
+// toUserResponse maps user to its API representation. Timestamps are
+// converted to UTC so they serialize the same regardless of APP_TIMEZONE.
+func toUserResponse(user *entity.User) *dto.UserResponse {
+	var lastLoginAt *time.Time
+	if user.LastLoginAt != nil {
+		t := user.LastLoginAt.UTC()
+		lastLoginAt = &t
+	}
+
 	return &dto.UserResponse{
-		ID:        user.ID,
-		Email:     user.Email,
-		Username:  user.Username,
-		FullName:  user.FullName,
-		Role:      user.Role,
-		Status:    user.Status,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+		ID:          user.ID,
+		Email:       user.Email,
+		Username:    user.Username,
+		FullName:    user.FullName,
+		Role:        user.Role,
+		Status:      user.Status,
+		MFAEnabled:  user.MFAEnabled,
+		AvatarURL:   user.AvatarURL,
+		Timezone:    user.Timezone,
+		Locale:      user.Locale,
+		LastLoginAt: lastLoginAt,
+		CreatedAt:   user.CreatedAt.UTC(),
+		UpdatedAt:   user.UpdatedAt.UTC(),
 	}
 }
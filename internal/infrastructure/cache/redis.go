@@ -2,49 +2,137 @@ package cache
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/TubagusAldiMY/go-template/internal/infrastructure/config"
 	"github.com/TubagusAldiMY/go-template/pkg/logger"
+	"github.com/TubagusAldiMY/go-template/pkg/retry"
 	"github.com/redis/go-redis/v9"
 	"go.uber.org/zap"
 )
 
+const (
+	lockKeySuffix    = ":lock"
+	lockTTL          = 5 * time.Second
+	lockPollInterval = 50 * time.Millisecond
+
+	// connectBackoff is the wait before the first connection retry; it
+	// doubles with every further attempt.
+	connectBackoff = time.Second
+)
+
+// Redis wraps a single-node, sentinel-backed or cluster client. Client is
+// the common go-redis interface, so the methods below behave the same in
+// every mode.
 type Redis struct {
-	Client *redis.Client
+	Client redis.UniversalClient
 }
 
 func NewRedis(cfg config.RedisConfig) (*Redis, error) {
-	client := redis.NewClient(&redis.Options{
-		Addr:         fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
-		Password:     cfg.Password,
-		DB:           cfg.DB,
-		PoolSize:     cfg.PoolSize,
-		MinIdleConns: cfg.PoolSize / 2,
-		MaxRetries:   3,
-		DialTimeout:  5 * time.Second,
-		ReadTimeout:  3 * time.Second,
-		WriteTimeout: 3 * time.Second,
-		PoolTimeout:  4 * time.Second,
-	})
-
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	client, err := newClient(cfg)
+	if err != nil {
+		return nil, err
+	}
 
-	if err := client.Ping(ctx).Err(); err != nil {
+	retryCtx := logger.WithContext(context.Background(), zap.String("dependency", "redis"))
+	err = retry.Do(retryCtx, cfg.ConnectAttempts, connectBackoff, func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return client.Ping(ctx).Err()
+	})
+	if err != nil {
+		if closeErr := client.Close(); closeErr != nil {
+			logger.Warn("failed to close redis client", zap.Error(closeErr))
+		}
 		return nil, fmt.Errorf("failed to connect to redis: %w", err)
 	}
 
 	logger.Info("redis connection established",
-		zap.String("host", cfg.Host),
-		zap.Int("port", cfg.Port),
+		zap.String("mode", redisMode(cfg)),
+		zap.Strings("addrs", redisAddrs(cfg)),
 		zap.Int("db", cfg.DB),
 	)
 
 	return &Redis{Client: client}, nil
 }
 
+// newClient builds the client for cfg.Mode with the shared pool and timeout
+// settings.
+func newClient(cfg config.RedisConfig) (redis.UniversalClient, error) {
+	const (
+		maxRetries   = 3
+		dialTimeout  = 5 * time.Second
+		readTimeout  = 3 * time.Second
+		writeTimeout = 3 * time.Second
+		poolTimeout  = 4 * time.Second
+	)
+
+	switch redisMode(cfg) {
+	case config.RedisModeSingle:
+		return redis.NewClient(&redis.Options{
+			Addr:         fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+			Password:     cfg.Password,
+			DB:           cfg.DB,
+			PoolSize:     cfg.PoolSize,
+			MinIdleConns: cfg.PoolSize / 2,
+			MaxRetries:   maxRetries,
+			DialTimeout:  dialTimeout,
+			ReadTimeout:  readTimeout,
+			WriteTimeout: writeTimeout,
+			PoolTimeout:  poolTimeout,
+		}), nil
+
+	case config.RedisModeSentinel:
+		return redis.NewFailoverClient(&redis.FailoverOptions{
+			MasterName:       cfg.MasterName,
+			SentinelAddrs:    cfg.Addrs,
+			SentinelPassword: cfg.SentinelPassword,
+			Password:         cfg.Password,
+			DB:               cfg.DB,
+			PoolSize:         cfg.PoolSize,
+			MinIdleConns:     cfg.PoolSize / 2,
+			MaxRetries:       maxRetries,
+			DialTimeout:      dialTimeout,
+			ReadTimeout:      readTimeout,
+			WriteTimeout:     writeTimeout,
+			PoolTimeout:      poolTimeout,
+		}), nil
+
+	case config.RedisModeCluster:
+		return redis.NewClusterClient(&redis.ClusterOptions{
+			Addrs:        cfg.Addrs,
+			Password:     cfg.Password,
+			PoolSize:     cfg.PoolSize,
+			MinIdleConns: cfg.PoolSize / 2,
+			MaxRetries:   maxRetries,
+			DialTimeout:  dialTimeout,
+			ReadTimeout:  readTimeout,
+			WriteTimeout: writeTimeout,
+			PoolTimeout:  poolTimeout,
+		}), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported redis mode %q", cfg.Mode)
+	}
+}
+
+func redisMode(cfg config.RedisConfig) string {
+	if cfg.Mode == "" {
+		return config.RedisModeSingle
+	}
+	return cfg.Mode
+}
+
+func redisAddrs(cfg config.RedisConfig) []string {
+	if redisMode(cfg) == config.RedisModeSingle {
+		return []string{fmt.Sprintf("%s:%d", cfg.Host, cfg.Port)}
+	}
+	return cfg.Addrs
+}
+
 func (r *Redis) Close() error {
 	if r.Client != nil {
 		err := r.Client.Close()
@@ -73,6 +161,32 @@ func (r *Redis) Delete(ctx context.Context, keys ...string) error {
 	return r.Client.Del(ctx, keys...).Err()
 }
 
+// DeleteMany deletes keys in a single round-trip. Unlike a multi-key DEL it
+// issues one DEL per key, so the keys need not share a cluster hash slot.
+func (r *Redis) DeleteMany(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	return r.Pipeline(ctx, func(pipe redis.Pipeliner) error {
+		for _, key := range keys {
+			pipe.Del(ctx, key)
+		}
+		return nil
+	})
+}
+
+// Pipeline queues the commands issued by fn and sends them in a single
+// round-trip wrapped in MULTI/EXEC, so they are applied atomically. In
+// cluster mode the commands are grouped by hash slot and each group is its
+// own transaction, so atomicity only holds per slot. Nothing is sent if fn
+// returns an error. Otherwise the first failing command's
+// error is returned, including redis.Nil for a queued GET that missed.
+func (r *Redis) Pipeline(ctx context.Context, fn func(pipe redis.Pipeliner) error) error {
+	_, err := r.Client.TxPipelined(ctx, fn)
+	return err
+}
+
 func (r *Redis) Exists(ctx context.Context, keys ...string) (int64, error) {
 	return r.Client.Exists(ctx, keys...).Result()
 }
@@ -85,6 +199,112 @@ func (r *Redis) SetNX(ctx context.Context, key string, value interface{}, expira
 	return r.Client.SetNX(ctx, key, value, expiration).Result()
 }
 
-func (r *Redis) GetClient() *redis.Client {
+// Incr increments the integer stored at key, starting from zero, and
+// returns the new value.
+func (r *Redis) Incr(ctx context.Context, key string) (int64, error) {
+	return r.Client.Incr(ctx, key).Result()
+}
+
+func (r *Redis) GetClient() redis.UniversalClient {
 	return r.Client
 }
+
+// GetOrSet decodes the JSON value cached under key into dest. On a miss it
+// calls loader, caches the result for ttl and decodes it into dest.
+//
+// Only one caller per key runs loader at a time: the others wait for the
+// value to appear, and fall back to calling loader themselves if the lock
+// holder does not populate the key before the lock expires. Cache failures
+// degrade to calling loader directly; loader errors are returned unchanged.
+func (r *Redis) GetOrSet(ctx context.Context, key string, ttl time.Duration, loader func() (any, error), dest any) error {
+	if found, err := r.getJSON(ctx, key, dest); err == nil && found {
+		return nil
+	}
+
+	lockKey := key + lockKeySuffix
+	acquired, err := r.SetNX(ctx, lockKey, "1", lockTTL)
+	if err != nil {
+		logger.Warn("failed to acquire cache lock", zap.String("key", key), zap.Error(err))
+		return r.load(ctx, key, ttl, loader, dest)
+	}
+
+	if acquired {
+		defer func() {
+			if err := r.Delete(context.WithoutCancel(ctx), lockKey); err != nil {
+				logger.Warn("failed to release cache lock", zap.String("key", key), zap.Error(err))
+			}
+		}()
+		return r.load(ctx, key, ttl, loader, dest)
+	}
+
+	if err := r.waitFor(ctx, key, dest); err == nil {
+		return nil
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	return r.load(ctx, key, ttl, loader, dest)
+}
+
+// waitFor polls key until it is populated, the lock TTL elapses or ctx ends.
+func (r *Redis) waitFor(ctx context.Context, key string, dest any) error {
+	ctx, cancel := context.WithTimeout(ctx, lockTTL)
+	defer cancel()
+
+	ticker := time.NewTicker(lockPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if found, err := r.getJSON(ctx, key, dest); err == nil && found {
+				return nil
+			}
+		}
+	}
+}
+
+func (r *Redis) load(ctx context.Context, key string, ttl time.Duration, loader func() (any, error), dest any) error {
+	value, err := loader()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to encode cache value: %w", err)
+	}
+
+	if err := r.Set(ctx, key, data, ttl); err != nil {
+		logger.Warn("failed to write cache value", zap.String("key", key), zap.Error(err))
+	}
+
+	if err := json.Unmarshal(data, dest); err != nil {
+		return fmt.Errorf("failed to decode cache value: %w", err)
+	}
+
+	return nil
+}
+
+// getJSON reports whether key was present and decoded into dest. Undecodable
+// entries are treated as a miss.
+func (r *Redis) getJSON(ctx context.Context, key string, dest any) (bool, error) {
+	data, err := r.Get(ctx, key)
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return false, nil
+		}
+		logger.Warn("failed to read cache value", zap.String("key", key), zap.Error(err))
+		return false, err
+	}
+
+	if err := json.Unmarshal([]byte(data), dest); err != nil {
+		logger.Warn("failed to decode cached value", zap.String("key", key), zap.Error(err))
+		return false, nil
+	}
+
+	return true, nil
+}
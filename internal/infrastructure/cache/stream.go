@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// XAdd appends an entry with values to stream and returns its ID. A positive
+// maxLen trims the stream to roughly that many entries; the trimming is
+// approximate so Redis can drop whole macro nodes cheaply.
+func (r *Redis) XAdd(ctx context.Context, stream string, maxLen int64, values map[string]interface{}) (string, error) {
+	args := &redis.XAddArgs{
+		Stream: stream,
+		Values: values,
+	}
+	if maxLen > 0 {
+		args.MaxLen = maxLen
+		args.Approx = true
+	}
+
+	return r.Client.XAdd(ctx, args).Result()
+}
+
+// XRead returns up to count entries of stream added after afterID, so
+// passing the last ID seen pages through the stream; "0" starts from the
+// beginning and "$" only returns new entries. A positive block waits that
+// long for entries to arrive. No entries is not an error.
+func (r *Redis) XRead(ctx context.Context, stream, afterID string, count int64, block time.Duration) ([]redis.XMessage, error) {
+	if block <= 0 {
+		// go-redis only sends BLOCK for non-negative values, and zero would
+		// block forever.
+		block = -1
+	}
+
+	streams, err := r.Client.XRead(ctx, &redis.XReadArgs{
+		Streams: []string{stream, afterID},
+		Count:   count,
+		Block:   block,
+	}).Result()
+	return firstStream(streams, err)
+}
+
+// EnsureConsumerGroup creates group on stream, and the stream itself when it
+// does not exist yet. New groups start with the entries added from now on.
+// An existing group is left as is.
+func (r *Redis) EnsureConsumerGroup(ctx context.Context, stream, group string) error {
+	err := r.Client.XGroupCreateMkStream(ctx, stream, group, "$").Err()
+	if err != nil && strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return nil
+	}
+	return err
+}
+
+// XReadGroup delivers up to count entries of stream that no consumer of group
+// has received yet to consumer, waiting up to block for them. The entries
+// stay pending for the group until they are acknowledged with XAck.
+func (r *Redis) XReadGroup(ctx context.Context, stream, group, consumer string, count int64, block time.Duration) ([]redis.XMessage, error) {
+	if block <= 0 {
+		block = -1
+	}
+
+	streams, err := r.Client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    group,
+		Consumer: consumer,
+		Streams:  []string{stream, ">"},
+		Count:    count,
+		Block:    block,
+	}).Result()
+	return firstStream(streams, err)
+}
+
+// XAck acknowledges entries delivered to group, removing them from its
+// pending list.
+func (r *Redis) XAck(ctx context.Context, stream, group string, ids ...string) error {
+	return r.Client.XAck(ctx, stream, group, ids...).Err()
+}
+
+// XAutoClaim transfers to consumer up to count entries that have been
+// pending in group for longer than minIdle, such as those delivered to a
+// consumer that crashed before acknowledging them.
+func (r *Redis) XAutoClaim(ctx context.Context, stream, group, consumer string, minIdle time.Duration, count int64) ([]redis.XMessage, error) {
+	messages, _, err := r.Client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+		Stream:   stream,
+		Group:    group,
+		Consumer: consumer,
+		MinIdle:  minIdle,
+		Start:    "0-0",
+		Count:    count,
+	}).Result()
+	return messages, err
+}
+
+// firstStream unwraps the entries of a single-stream read, treating the
+// redis.Nil of a read that timed out as no entries.
+func firstStream(streams []redis.XStream, err error) ([]redis.XMessage, error) {
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if len(streams) == 0 {
+		return nil, nil
+	}
+	return streams[0].Messages, nil
+}
@@ -1,25 +1,79 @@
 package config
 
 import (
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/TubagusAldiMY/go-template/internal/shared/constants"
+	"github.com/TubagusAldiMY/go-template/pkg/logger"
 	"github.com/spf13/viper"
+	"golang.org/x/crypto/bcrypt"
 )
 
+// MinJWTSecretLength is the shortest JWT_SECRET accepted; HS256 keys should
+// carry at least 256 bits.
+const MinJWTSecretLength = 32
+
+// TOTPKeyLength is the decoded length of TOTP_ENCRYPTION_KEY,
+// PII_ENCRYPTION_KEY and PII_BLIND_INDEX_KEY (AES-256).
+const TOTPKeyLength = 32
+
+// DefaultMaxRequestBodySize is used when SERVER_MAX_REQUEST_BODY_SIZE is unset.
+const DefaultMaxRequestBodySize = 1 << 20 // 1 MiB
+
+// DefaultMaxAvatarSize is used when STORAGE_MAX_AVATAR_SIZE is unset. It
+// leaves room for the multipart envelope within DefaultMaxRequestBodySize.
+const DefaultMaxAvatarSize = 512 << 10 // 512 KiB
+
+// DefaultPasswordMinLength is used when PASSWORD_MIN_LENGTH is unset.
+const DefaultPasswordMinLength = 8
+
+// DefaultConnectAttempts is used when DB_CONNECT_ATTEMPTS,
+// REDIS_CONNECT_ATTEMPTS or RABBITMQ_CONNECT_ATTEMPTS is unset. Zero or one
+// connects once without retrying.
+const DefaultConnectAttempts = 5
+
+// DefaultImpersonationsPerMinute is used when
+// RATE_LIMIT_IMPERSONATIONS_PER_MINUTE is unset.
+const DefaultImpersonationsPerMinute = 5
+
+// DefaultRegistrationsPerIP is used when REGISTRATION_LIMIT_PER_IP is unset.
+const DefaultRegistrationsPerIP = 10
+
 type Config struct {
-	App        AppConfig
-	Server     ServerConfig
-	Database   DatabaseConfig
-	Redis      RedisConfig
-	RabbitMQ   RabbitMQConfig
-	JWT        JWTConfig
-	CORS       CORSConfig
-	RateLimit  RateLimitConfig
-	Log        LogConfig
-	Metrics    MetricsConfig
-	Security   SecurityConfig
-	Pagination PaginationConfig
+	App          AppConfig
+	Server       ServerConfig
+	Database     DatabaseConfig
+	Redis        RedisConfig
+	Cache        CacheConfig
+	RabbitMQ     RabbitMQConfig
+	Events       EventsConfig
+	JWT          JWTConfig
+	AuthCookie   AuthCookieConfig
+	Authz        AuthzConfig
+	CORS         CORSConfig
+	RateLimit    RateLimitConfig
+	Idempotency  IdempotencyConfig
+	Maintenance  MaintenanceConfig
+	Registration RegistrationConfig
+	Log          LogConfig
+	Metrics      MetricsConfig
+	GRPC         GRPCConfig
+	Tracing      TracingConfig
+	Security     SecurityConfig
+	Pagination   PaginationConfig
+	Storage      StorageConfig
+	Scheduler    SchedulerConfig
+	Notify       NotifyConfig
 }
 
 type AppConfig struct {
@@ -30,10 +84,36 @@ type AppConfig struct {
 	Timezone string
 }
 
+// Location loads Timezone. An unset timezone means UTC; "Local" is rejected
+// because it would depend on the host the server happens to run on.
+func (a AppConfig) Location() (*time.Location, error) {
+	if a.Timezone == "" {
+		return time.UTC, nil
+	}
+	if a.Timezone == "Local" {
+		return nil, fmt.Errorf("APP_TIMEZONE: must be an IANA zone name, not Local")
+	}
+
+	loc, err := time.LoadLocation(a.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("APP_TIMEZONE: unknown time zone %q", a.Timezone)
+	}
+	return loc, nil
+}
+
 type ServerConfig struct {
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
 	IdleTimeout  time.Duration
+	// HandlerTimeout is the per-request deadline; keep it below WriteTimeout
+	// so the 503 can still be written. Zero disables it.
+	HandlerTimeout time.Duration
+	// MaxRequestBodySize caps request bodies in bytes. Zero disables it.
+	MaxRequestBodySize int64
+	// TrustedProxies lists the IPs or CIDRs of reverse proxies whose
+	// X-Forwarded-For and X-Real-IP headers are believed when resolving the
+	// client IP. Empty trusts none, so the client IP is the peer address.
+	TrustedProxies []string
 }
 
 type DatabaseConfig struct {
@@ -46,14 +126,54 @@ type DatabaseConfig struct {
 	MaxOpenConns    int
 	MaxIdleConns    int
 	ConnMaxLifetime time.Duration
+	// QueryTimeout bounds each repository call; zero disables it.
+	QueryTimeout time.Duration
+	// ConnectAttempts is how many times startup tries to reach the database
+	// before giving up.
+	ConnectAttempts int
+	// PoolWarmup opens MaxIdleConns connections during startup instead of
+	// on demand. Turning it off speeds up local startup.
+	PoolWarmup bool
+	// SlowQueryThreshold logs queries taking at least this long at warn
+	// level. Zero disables it.
+	SlowQueryThreshold time.Duration
+	// ReplicaHosts lists read replicas as host or host:port, port defaulting
+	// to Port. They share the primary's credentials and pool settings.
+	// Empty sends reads to the primary.
+	ReplicaHosts []string
 }
 
+// PrimaryOnly returns a copy of c without the read replicas, for tools that
+// only talk to the primary.
+func (c DatabaseConfig) PrimaryOnly() DatabaseConfig {
+	c.ReplicaHosts = nil
+	return c
+}
+
+// Redis connection modes.
+const (
+	RedisModeSingle   = "single"
+	RedisModeSentinel = "sentinel"
+	RedisModeCluster  = "cluster"
+)
+
 type RedisConfig struct {
+	// Mode is single (the default), sentinel or cluster.
+	Mode     string
 	Host     string
 	Port     int
 	Password string
 	DB       int
 	PoolSize int
+	// Addrs lists the sentinel or cluster seed nodes as host:port; Host and
+	// Port are only used in single mode.
+	Addrs []string
+	// MasterName and SentinelPassword are used in sentinel mode.
+	MasterName       string
+	SentinelPassword string
+	// ConnectAttempts is how many times startup tries to reach Redis before
+	// giving up.
+	ConnectAttempts int
 }
 
 type RabbitMQConfig struct {
@@ -62,72 +182,517 @@ type RabbitMQConfig struct {
 	User     string
 	Password string
 	VHost    string
+	// ConnectAttempts is how many times startup tries to reach RabbitMQ
+	// before giving up.
+	ConnectAttempts int
+	// ExpectedQueues are queues, such as those of downstream consumers, whose
+	// absence marks the service not ready. The user events exchange is always
+	// checked.
+	ExpectedQueues []string
+}
+
+// DefaultCacheWarmUsers is used when CACHE_WARM_USERS is unset.
+const DefaultCacheWarmUsers = 1000
+
+type CacheConfig struct {
+	// WarmEnabled loads the WarmUsers most recently updated active users into
+	// the cache in the background at startup.
+	WarmEnabled bool
+	WarmUsers   int
+}
+
+// Event backends.
+const (
+	EventsBackendRabbitMQ    = "rabbitmq"
+	EventsBackendRedisStream = "redis_stream"
+)
+
+// DefaultEventsStreamMaxLen is the approximate number of entries kept in the
+// user events stream.
+const DefaultEventsStreamMaxLen = 100000
+
+type EventsConfig struct {
+	// Backend is where user events are published: rabbitmq (the default) or
+	// redis_stream.
+	Backend string
+	// StreamMaxLen caps the redis_stream backend's stream; 0 keeps every
+	// entry.
+	StreamMaxLen int64
+}
+
+// AuthCookieConfig controls delivering tokens to browser clients as HttpOnly
+// cookies instead of in the response body.
+type AuthCookieConfig struct {
+	// Delivery is how login and refresh responses deliver tokens by default:
+	// json (the default), cookie or both. Clients can override it with the
+	// token_delivery query parameter.
+	Delivery string
+	// Domain is the cookies' Domain attribute; empty scopes them to the API
+	// host.
+	Domain string
+	Secure bool
+	// SameSite is strict (the default), lax or none. none requires Secure.
+	SameSite string
+}
+
+// SameSiteMode returns SameSite as an http.SameSite.
+func (c AuthCookieConfig) SameSiteMode() http.SameSite {
+	switch c.SameSite {
+	case "lax":
+		return http.SameSiteLaxMode
+	case "none":
+		return http.SameSiteNoneMode
+	}
+	return http.SameSiteStrictMode
 }
 
 type JWTConfig struct {
 	Secret             string
 	AccessTokenExpiry  time.Duration
 	RefreshTokenExpiry time.Duration
+	// RememberMeRefreshExpiry is the refresh token lifetime for logins with
+	// remember_me set. Zero uses RefreshTokenExpiry.
+	RememberMeRefreshExpiry time.Duration
+	// Issuer and Audience are set on access tokens and required when
+	// validating them. Empty values skip the check.
+	Issuer   string
+	Audience string
+	// ImpersonationExpiry is the lifetime of impersonation tokens; it must
+	// be shorter than AccessTokenExpiry.
+	ImpersonationExpiry time.Duration
+	// EpochFailOpen accepts access tokens without checking their token
+	// epoch while Redis is unavailable, instead of refusing them.
+	EpochFailOpen bool
+}
+
+type AuthzConfig struct {
+	// RolePermissions overrides the default role policy, for example
+	// "admin=*;moderator=user:read,user:update". Empty keeps the default.
+	RolePermissions string
 }
 
 type CORSConfig struct {
-	AllowedOrigins []string
-	AllowedMethods []string
-	AllowedHeaders []string
-	ExposedHeaders []string
-	MaxAge         time.Duration
+	// AllowedOrigins holds exact origins, "*", or subdomain wildcards such
+	// as "https://*.example.com".
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	MaxAge           time.Duration
+	AllowCredentials bool
+}
+
+// Validate rejects combinations browsers refuse, such as a "*" origin with
+// credentials allowed.
+func (c CORSConfig) Validate() error {
+	for _, origin := range c.AllowedOrigins {
+		if origin == "*" && c.AllowCredentials {
+			return fmt.Errorf("CORS_ALLOWED_ORIGINS cannot contain \"*\" when CORS_ALLOW_CREDENTIALS is true")
+		}
+		if strings.Count(origin, "*") > 1 || (origin != "*" && strings.Contains(origin, "*") && !strings.Contains(origin, "://*.")) {
+			return fmt.Errorf("invalid CORS origin pattern %q: wildcards must take the form scheme://*.domain", origin)
+		}
+	}
+	return nil
 }
 
 type RateLimitConfig struct {
 	Enabled           bool
 	RequestsPerSecond float64
 	Burst             int
+	// ImpersonationsPerMinute limits how many impersonation tokens each
+	// user may request, independently of Enabled.
+	ImpersonationsPerMinute int
+}
+
+type IdempotencyConfig struct {
+	// TTL is how long a recorded response is replayed for the same key.
+	TTL time.Duration
+}
+
+type MaintenanceConfig struct {
+	// RetryAfter is sent in the Retry-After header of the writes refused
+	// while maintenance mode is on.
+	RetryAfter time.Duration
+	// AllowedRoutes are route patterns, such as /api/v1/auth/login, whose
+	// writes are still served during maintenance. Turning maintenance mode
+	// off is always allowed.
+	AllowedRoutes []string
+}
+
+type RegistrationConfig struct {
+	// LimitPerIP is how many accounts may be registered from one IP address
+	// within LimitWindow. Zero disables the limit.
+	LimitPerIP  int
+	LimitWindow time.Duration
 }
 
 type LogConfig struct {
 	Level  string
 	Format string
-	Output string
+	// Output lists the sinks every entry is written to: stdout, stderr or
+	// file paths. Files are rotated once they reach MaxSizeMB (100 when
+	// zero); rotated files older than MaxAgeDays or beyond the newest
+	// MaxBackups are removed, and zero keeps them.
+	Output     []string
+	MaxSizeMB  int
+	MaxAgeDays int
+	MaxBackups int
+
+	// AccessLogFormat is "fields" (log through the app logger) or "json"
+	// (one flat JSON line per request, whatever Format is).
+	AccessLogFormat string
+	// AccessLogSampleRate logs one in N successful 2xx requests; values
+	// below 2 log every request. Other statuses are always logged.
+	AccessLogSampleRate int
+	// SlowRequestThreshold marks requests that are always logged, at warn
+	// level. Zero disables it.
+	SlowRequestThreshold time.Duration
+	// RequestHeaders adds the request headers to access log entries.
+	RequestHeaders bool
+	// RedactHeaders names headers logged as [REDACTED], in addition to the
+	// built-in Authorization, Cookie, X-API-Key and other credentials.
+	RedactHeaders []string
+	// RequestIDPolicy decides which X-Request-ID values sent by clients or
+	// upstream proxies are kept: token (the default), uuid or regenerate.
+	// Rejected and missing IDs are replaced with a generated UUID.
+	RequestIDPolicy string
+}
+
+// Request ID policies.
+const (
+	// RequestIDPolicyToken keeps IDs of up to 128 letters, digits and
+	// "-", "_", ".", ":" or "=".
+	RequestIDPolicyToken = "token"
+	// RequestIDPolicyUUID keeps only UUIDs.
+	RequestIDPolicyUUID = "uuid"
+	// RequestIDPolicyRegenerate ignores incoming IDs.
+	RequestIDPolicyRegenerate = "regenerate"
+)
+
+// Rotation returns the file rotation settings of the log outputs.
+func (c LogConfig) Rotation() logger.Rotation {
+	return logger.Rotation{
+		MaxSizeMB:  c.MaxSizeMB,
+		MaxAgeDays: c.MaxAgeDays,
+		MaxBackups: c.MaxBackups,
+	}
 }
 
 type MetricsConfig struct {
 	Enabled bool
 	Port    int
+	// Pprof serves the net/http/pprof handlers on the metrics port, which
+	// has no authentication and must not be reachable publicly.
+	Pprof bool
+}
+
+// DefaultGRPCPort is used when GRPC_PORT is unset.
+const DefaultGRPCPort = 50051
+
+// GRPCConfig configures the gRPC listener serving UserService next to the
+// HTTP API.
+type GRPCConfig struct {
+	Enabled bool
+	Port    int
+}
+
+type TracingConfig struct {
+	Enabled bool
+	// Endpoint is the OTLP/HTTP collector address, e.g. "localhost:4318".
+	Endpoint    string
+	Insecure    bool
+	SampleRatio float64
 }
 
 type SecurityConfig struct {
-	BcryptCost        int
-	PasswordMinLength int
+	// PasswordAlgorithm selects the hash used for new passwords: "bcrypt"
+	// (default) or "argon2id". Stored hashes of either kind keep validating.
+	PasswordAlgorithm string
+	// BcryptCost is the bcrypt work factor; zero selects bcrypt's default.
+	BcryptCost int
+
+	// Password policy enforced on registration and password changes.
+	// PasswordMaxLength of zero allows any length; PasswordDisallowCommon
+	// rejects passwords from an embedded list of common ones.
+	PasswordMinLength      int
+	PasswordMaxLength      int
+	PasswordRequireUpper   bool
+	PasswordRequireLower   bool
+	PasswordRequireDigit   bool
+	PasswordRequireSpecial bool
+	PasswordDisallowCommon bool
+
+	// BlockDisposableEmails rejects registrations from throwaway email
+	// domains: the embedded list, or the one in DisposableEmailDomainsFile
+	// (one domain per line) when set.
+	BlockDisposableEmails      bool
+	DisposableEmailDomainsFile string
+
+	// Argon2 tuning, used when PasswordAlgorithm is "argon2id". Memory is in KiB.
+	Argon2Memory      uint32
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+
+	// TOTPEncryptionKey is a base64-encoded 32-byte key used to encrypt TOTP
+	// secrets at rest. TOTP enrollment is unavailable while it is empty.
+	TOTPEncryptionKey string
+	// TOTPIssuer labels accounts in authenticator apps; defaults to APP_NAME.
+	TOTPIssuer string
+
+	// PIIEncryptionKey is a base64-encoded 32-byte key used to encrypt user
+	// emails and full names at rest, and PIIBlindIndexKey a different one
+	// that keys the blind index emails are looked up by. Set both or
+	// neither; personal data is stored in plaintext while they are empty.
+	PIIEncryptionKey string
+	PIIBlindIndexKey string
+}
+
+// TOTPKey decodes TOTPEncryptionKey. It returns nil when no key is set.
+func (s SecurityConfig) TOTPKey() ([]byte, error) {
+	if s.TOTPEncryptionKey == "" {
+		return nil, nil
+	}
+
+	return decodeKey("TOTP_ENCRYPTION_KEY", s.TOTPEncryptionKey)
+}
+
+// PIIKeys decodes PIIEncryptionKey and PIIBlindIndexKey. It returns nil keys
+// when neither is set.
+func (s SecurityConfig) PIIKeys() (encryptionKey, indexKey []byte, err error) {
+	if s.PIIEncryptionKey == "" && s.PIIBlindIndexKey == "" {
+		return nil, nil, nil
+	}
+	if s.PIIEncryptionKey == "" || s.PIIBlindIndexKey == "" {
+		return nil, nil, fmt.Errorf("PII_ENCRYPTION_KEY: must be set together with PII_BLIND_INDEX_KEY")
+	}
+	if s.PIIEncryptionKey == s.PIIBlindIndexKey {
+		return nil, nil, fmt.Errorf("PII_BLIND_INDEX_KEY: must differ from PII_ENCRYPTION_KEY")
+	}
+
+	if encryptionKey, err = decodeKey("PII_ENCRYPTION_KEY", s.PIIEncryptionKey); err != nil {
+		return nil, nil, err
+	}
+	if indexKey, err = decodeKey("PII_BLIND_INDEX_KEY", s.PIIBlindIndexKey); err != nil {
+		return nil, nil, err
+	}
+
+	return encryptionKey, indexKey, nil
+}
+
+// decodeKey decodes the base64-encoded AES-256 key in the setting name.
+func decodeKey(name, value string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("%s: must be base64 encoded", name)
+	}
+	if len(key) != TOTPKeyLength {
+		return nil, fmt.Errorf("%s: must decode to %d bytes, got %d", name, TOTPKeyLength, len(key))
+	}
+
+	return key, nil
 }
 
 type PaginationConfig struct {
 	DefaultPageSize int
 	MaxPageSize     int
+	// SearchSimilarityThreshold is the minimum pg_trgm similarity, between 0
+	// and 1, of fuzzy user search results; 0 uses the repository default.
+	SearchSimilarityThreshold float64
 }
 
-func Load() (*Config, error) {
-	v := viper.New()
+// Storage drivers.
+const (
+	StorageDriverLocal = "local"
+	StorageDriverS3    = "s3"
+)
+
+type SchedulerConfig struct {
+	// Enabled runs the background jobs. Every replica runs them; the jobs
+	// are idempotent.
+	Enabled bool
+	// UserPurgeInterval is how often soft-deleted users are purged, and
+	// DeletedUserRetention how long they are kept before that. A zero
+	// retention disables the purge.
+	UserPurgeInterval    time.Duration
+	DeletedUserRetention time.Duration
+}
+
+// Notification drivers.
+const (
+	NotifyDriverLog  = "log"
+	NotifyDriverSMTP = "smtp"
+)
+
+// DefaultNotifyWorkers is used when NOTIFY_WORKERS is unset.
+const DefaultNotifyWorkers = 2
+
+type NotifyConfig struct {
+	// Driver delivers emails: log (the default) writes them to the log, smtp
+	// sends them through the SMTP server.
+	Driver string
+	// Async publishes emails to the email queue and delivers them from a
+	// worker of Workers goroutines, so requests do not wait on the mail
+	// server. It needs RabbitMQ; without it emails are sent directly.
+	Async   bool
+	Workers int
+	// From is the sender address, optionally with a display name.
+	From string
+
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+}
 
-	// Set config file
-	v.SetConfigFile(".env")
-	v.SetConfigType("env")
+type StorageConfig struct {
+	// Driver is local (the default) or s3.
+	Driver string
+	// PublicURL is the base URL stored files are served from. The local
+	// driver serves them under /uploads, so it defaults to that path; for s3
+	// an empty value uses the bucket URL.
+	PublicURL string
+	// LocalDir is the directory the local driver writes to.
+	LocalDir string
+	// MaxAvatarSize caps avatar uploads in bytes.
+	MaxAvatarSize int64
 
-	// Auto env
+	S3Endpoint     string
+	S3Region       string
+	S3Bucket       string
+	S3AccessKey    string
+	S3SecretKey    string
+	S3UsePathStyle bool
+}
+
+const (
+	// EnvConfigFile names the config file to load instead of .env. The type
+	// is detected from the extension: .env, .yaml/.yml or .json.
+	EnvConfigFile     = "CONFIG_FILE"
+	defaultConfigFile = ".env"
+)
+
+// sectionPrefixes maps top-level sections of a YAML or JSON config file onto
+// the env variable prefix of the keys they hold, so "database.host" is read
+// as DB_HOST. Sections with an empty prefix hold keys whose env names have
+// none, e.g. "security.bcrypt_cost" is BCRYPT_COST.
+var sectionPrefixes = map[string]string{
+	"app":          "APP_",
+	"server":       "SERVER_",
+	"database":     "DB_",
+	"redis":        "REDIS_",
+	"cache":        "CACHE_",
+	"rabbitmq":     "RABBITMQ_",
+	"events":       "EVENTS_",
+	"jwt":          "JWT_",
+	"auth_cookie":  "AUTH_COOKIE_",
+	"authz":        "AUTHZ_",
+	"cors":         "CORS_",
+	"rate_limit":   "RATE_LIMIT_",
+	"idempotency":  "IDEMPOTENCY_",
+	"maintenance":  "MAINTENANCE_",
+	"registration": "REGISTRATION_",
+	"log":          "LOG_",
+	"metrics":      "METRICS_",
+	"grpc":         "GRPC_",
+	"tracing":      "TRACING_",
+	"security":     "",
+	"pagination":   "",
+	"storage":      "STORAGE_",
+	"scheduler":    "SCHEDULER_",
+	"notify":       "NOTIFY_",
+}
+
+// Load builds the config from environment variables, using the file named by
+// CONFIG_FILE (or .env when unset) for values not set in the environment.
+func Load() (*Config, error) {
+	v := viper.New()
 	v.AutomaticEnv()
 
-	// Read config
-	if err := v.ReadInConfig(); err != nil {
-		return nil, fmt.Errorf("failed to read config: %w", err)
+	if err := readConfigFile(v); err != nil {
+		return nil, err
 	}
 
 	// Parse durations
-	serverReadTimeout, _ := time.ParseDuration(v.GetString("SERVER_READ_TIMEOUT"))
-	serverWriteTimeout, _ := time.ParseDuration(v.GetString("SERVER_WRITE_TIMEOUT"))
-	serverIdleTimeout, _ := time.ParseDuration(v.GetString("SERVER_IDLE_TIMEOUT"))
-	dbConnMaxLifetime, _ := time.ParseDuration(v.GetString("DB_CONN_MAX_LIFETIME"))
-	jwtAccessExpiry, _ := time.ParseDuration(v.GetString("JWT_ACCESS_TOKEN_EXPIRY"))
-	jwtRefreshExpiry, _ := time.ParseDuration(v.GetString("JWT_REFRESH_TOKEN_EXPIRY"))
-	corsMaxAge, _ := time.ParseDuration(v.GetString("CORS_MAX_AGE"))
+	durations := durationParser{v: v}
+	serverReadTimeout := durations.parse("SERVER_READ_TIMEOUT", 0)
+	serverWriteTimeout := durations.parse("SERVER_WRITE_TIMEOUT", 0)
+	serverIdleTimeout := durations.parse("SERVER_IDLE_TIMEOUT", 0)
+	serverHandlerTimeout := durations.parse("SERVER_HANDLER_TIMEOUT", 0)
+	dbConnMaxLifetime := durations.parse("DB_CONN_MAX_LIFETIME", 0)
+	dbQueryTimeout := durations.parse("DB_QUERY_TIMEOUT", 5*time.Second)
+	dbSlowQueryThreshold := durations.parse("DB_SLOW_QUERY_THRESHOLD", 0)
+	jwtAccessExpiry := durations.parse("JWT_ACCESS_TOKEN_EXPIRY", 0)
+	jwtRefreshExpiry := durations.parse("JWT_REFRESH_TOKEN_EXPIRY", 0)
+	jwtRememberMeExpiry := durations.parse("JWT_REMEMBER_ME_REFRESH_EXPIRY", 30*24*time.Hour)
+	jwtImpersonationExpiry := durations.parse("JWT_IMPERSONATION_EXPIRY", 5*time.Minute)
+	corsMaxAge := durations.parse("CORS_MAX_AGE", 0)
+	slowRequestThreshold := durations.parse("LOG_SLOW_REQUEST_THRESHOLD", 0)
+	idempotencyTTL := durations.parse("IDEMPOTENCY_TTL", 24*time.Hour)
+	maintenanceRetryAfter := durations.parse("MAINTENANCE_RETRY_AFTER", 2*time.Minute)
+	registrationLimitWindow := durations.parse("REGISTRATION_LIMIT_WINDOW", 24*time.Hour)
+	userPurgeInterval := durations.parse("SCHEDULER_USER_PURGE_INTERVAL", time.Hour)
+	deletedUserRetention := durations.parse("SCHEDULER_DELETED_USER_RETENTION", 30*24*time.Hour)
+	maxRequestBodySize := int64(DefaultMaxRequestBodySize)
+	if v.IsSet("SERVER_MAX_REQUEST_BODY_SIZE") {
+		maxRequestBodySize = v.GetInt64("SERVER_MAX_REQUEST_BODY_SIZE")
+	}
+	redisMode := strings.ToLower(v.GetString("REDIS_MODE"))
+	if redisMode == "" {
+		redisMode = RedisModeSingle
+	}
+	searchSimilarityThreshold := 0.3
+	if v.IsSet("SEARCH_SIMILARITY_THRESHOLD") {
+		searchSimilarityThreshold = v.GetFloat64("SEARCH_SIMILARITY_THRESHOLD")
+	}
+	tracingSampleRatio := 1.0
+	if v.IsSet("TRACING_SAMPLE_RATIO") {
+		tracingSampleRatio = v.GetFloat64("TRACING_SAMPLE_RATIO")
+	}
+	storageDriver := strings.ToLower(v.GetString("STORAGE_DRIVER"))
+	if storageDriver == "" {
+		storageDriver = StorageDriverLocal
+	}
+	storagePublicURL := v.GetString("STORAGE_PUBLIC_URL")
+	if storagePublicURL == "" && storageDriver == StorageDriverLocal {
+		storagePublicURL = "/uploads"
+	}
+	storageLocalDir := v.GetString("STORAGE_LOCAL_DIR")
+	if storageLocalDir == "" {
+		storageLocalDir = "./uploads"
+	}
+	maxAvatarSize := int64(DefaultMaxAvatarSize)
+	if v.IsSet("STORAGE_MAX_AVATAR_SIZE") {
+		maxAvatarSize = v.GetInt64("STORAGE_MAX_AVATAR_SIZE")
+	}
+	eventsBackend := strings.ToLower(v.GetString("EVENTS_BACKEND"))
+	if eventsBackend == "" {
+		eventsBackend = EventsBackendRabbitMQ
+	}
+	eventsStreamMaxLen := int64(DefaultEventsStreamMaxLen)
+	if v.IsSet("EVENTS_STREAM_MAX_LEN") {
+		eventsStreamMaxLen = v.GetInt64("EVENTS_STREAM_MAX_LEN")
+	}
+	authCookieDelivery := strings.ToLower(v.GetString("AUTH_COOKIE_DELIVERY"))
+	if authCookieDelivery == "" {
+		authCookieDelivery = constants.TokenDeliveryJSON
+	}
+	authCookieSameSite := strings.ToLower(v.GetString("AUTH_COOKIE_SAME_SITE"))
+	if authCookieSameSite == "" {
+		authCookieSameSite = "strict"
+	}
+	totpIssuer := v.GetString("TOTP_ISSUER")
+	if totpIssuer == "" {
+		totpIssuer = v.GetString("APP_NAME")
+	}
+	requestIDPolicy := strings.ToLower(v.GetString("LOG_REQUEST_ID_POLICY"))
+	if requestIDPolicy == "" {
+		requestIDPolicy = RequestIDPolicyToken
+	}
+	notifyDriver := strings.ToLower(v.GetString("NOTIFY_DRIVER"))
+	if notifyDriver == "" {
+		notifyDriver = NotifyDriverLog
+	}
 
 	config := &Config{
 		App: AppConfig{
@@ -138,74 +703,527 @@ func Load() (*Config, error) {
 			Timezone: v.GetString("APP_TIMEZONE"),
 		},
 		Server: ServerConfig{
-			ReadTimeout:  serverReadTimeout,
-			WriteTimeout: serverWriteTimeout,
-			IdleTimeout:  serverIdleTimeout,
+			ReadTimeout:        serverReadTimeout,
+			WriteTimeout:       serverWriteTimeout,
+			IdleTimeout:        serverIdleTimeout,
+			HandlerTimeout:     serverHandlerTimeout,
+			MaxRequestBodySize: maxRequestBodySize,
+			TrustedProxies:     getCommaSeparated(v, "SERVER_TRUSTED_PROXIES"),
 		},
 		Database: DatabaseConfig{
-			Host:            v.GetString("DB_HOST"),
-			Port:            v.GetInt("DB_PORT"),
-			User:            v.GetString("DB_USER"),
-			Password:        v.GetString("DB_PASSWORD"),
-			Name:            v.GetString("DB_NAME"),
-			SSLMode:         v.GetString("DB_SSLMODE"),
-			MaxOpenConns:    v.GetInt("DB_MAX_OPEN_CONNS"),
-			MaxIdleConns:    v.GetInt("DB_MAX_IDLE_CONNS"),
-			ConnMaxLifetime: dbConnMaxLifetime,
+			Host:               v.GetString("DB_HOST"),
+			Port:               v.GetInt("DB_PORT"),
+			User:               v.GetString("DB_USER"),
+			Password:           v.GetString("DB_PASSWORD"),
+			Name:               v.GetString("DB_NAME"),
+			SSLMode:            v.GetString("DB_SSLMODE"),
+			MaxOpenConns:       v.GetInt("DB_MAX_OPEN_CONNS"),
+			MaxIdleConns:       v.GetInt("DB_MAX_IDLE_CONNS"),
+			ConnMaxLifetime:    dbConnMaxLifetime,
+			QueryTimeout:       dbQueryTimeout,
+			ConnectAttempts:    getIntOrDefault(v, "DB_CONNECT_ATTEMPTS", DefaultConnectAttempts),
+			PoolWarmup:         getBoolOrDefault(v, "DB_POOL_WARMUP", true),
+			SlowQueryThreshold: dbSlowQueryThreshold,
+			ReplicaHosts:       getCommaSeparated(v, "DB_REPLICA_HOSTS"),
 		},
 		Redis: RedisConfig{
-			Host:     v.GetString("REDIS_HOST"),
-			Port:     v.GetInt("REDIS_PORT"),
-			Password: v.GetString("REDIS_PASSWORD"),
-			DB:       v.GetInt("REDIS_DB"),
-			PoolSize: v.GetInt("REDIS_POOL_SIZE"),
+			Mode:             redisMode,
+			Host:             v.GetString("REDIS_HOST"),
+			Port:             v.GetInt("REDIS_PORT"),
+			Password:         v.GetString("REDIS_PASSWORD"),
+			DB:               v.GetInt("REDIS_DB"),
+			PoolSize:         v.GetInt("REDIS_POOL_SIZE"),
+			Addrs:            getCommaSeparated(v, "REDIS_ADDRS"),
+			MasterName:       v.GetString("REDIS_MASTER_NAME"),
+			SentinelPassword: v.GetString("REDIS_SENTINEL_PASSWORD"),
+			ConnectAttempts:  getIntOrDefault(v, "REDIS_CONNECT_ATTEMPTS", DefaultConnectAttempts),
 		},
 		RabbitMQ: RabbitMQConfig{
-			Host:     v.GetString("RABBITMQ_HOST"),
-			Port:     v.GetInt("RABBITMQ_PORT"),
-			User:     v.GetString("RABBITMQ_USER"),
-			Password: v.GetString("RABBITMQ_PASSWORD"),
-			VHost:    v.GetString("RABBITMQ_VHOST"),
+			Host:            v.GetString("RABBITMQ_HOST"),
+			Port:            v.GetInt("RABBITMQ_PORT"),
+			User:            v.GetString("RABBITMQ_USER"),
+			Password:        v.GetString("RABBITMQ_PASSWORD"),
+			VHost:           v.GetString("RABBITMQ_VHOST"),
+			ConnectAttempts: getIntOrDefault(v, "RABBITMQ_CONNECT_ATTEMPTS", DefaultConnectAttempts),
+			ExpectedQueues:  getCommaSeparated(v, "RABBITMQ_EXPECTED_QUEUES"),
+		},
+		Cache: CacheConfig{
+			WarmEnabled: v.GetBool("CACHE_WARM_ENABLED"),
+			WarmUsers:   getIntOrDefault(v, "CACHE_WARM_USERS", DefaultCacheWarmUsers),
+		},
+		Events: EventsConfig{
+			Backend:      eventsBackend,
+			StreamMaxLen: eventsStreamMaxLen,
 		},
 		JWT: JWTConfig{
-			Secret:             v.GetString("JWT_SECRET"),
-			AccessTokenExpiry:  jwtAccessExpiry,
-			RefreshTokenExpiry: jwtRefreshExpiry,
+			Secret:                  v.GetString("JWT_SECRET"),
+			AccessTokenExpiry:       jwtAccessExpiry,
+			RefreshTokenExpiry:      jwtRefreshExpiry,
+			RememberMeRefreshExpiry: jwtRememberMeExpiry,
+			Issuer:                  v.GetString("JWT_ISSUER"),
+			Audience:                v.GetString("JWT_AUDIENCE"),
+			ImpersonationExpiry:     jwtImpersonationExpiry,
+			EpochFailOpen:           getBoolOrDefault(v, "JWT_EPOCH_FAIL_OPEN", false),
+		},
+		AuthCookie: AuthCookieConfig{
+			Delivery: authCookieDelivery,
+			Domain:   v.GetString("AUTH_COOKIE_DOMAIN"),
+			Secure:   getBoolOrDefault(v, "AUTH_COOKIE_SECURE", true),
+			SameSite: authCookieSameSite,
+		},
+		Authz: AuthzConfig{
+			RolePermissions: v.GetString("AUTHZ_ROLE_PERMISSIONS"),
 		},
 		CORS: CORSConfig{
-			AllowedOrigins: v.GetStringSlice("CORS_ALLOWED_ORIGINS"),
-			AllowedMethods: v.GetStringSlice("CORS_ALLOWED_METHODS"),
-			AllowedHeaders: v.GetStringSlice("CORS_ALLOWED_HEADERS"),
-			ExposedHeaders: v.GetStringSlice("CORS_EXPOSED_HEADERS"),
-			MaxAge:         corsMaxAge,
+			AllowedOrigins:   getCommaSeparated(v, "CORS_ALLOWED_ORIGINS"),
+			AllowedMethods:   getCommaSeparated(v, "CORS_ALLOWED_METHODS"),
+			AllowedHeaders:   getCommaSeparated(v, "CORS_ALLOWED_HEADERS"),
+			ExposedHeaders:   getCommaSeparated(v, "CORS_EXPOSED_HEADERS"),
+			MaxAge:           corsMaxAge,
+			AllowCredentials: v.GetBool("CORS_ALLOW_CREDENTIALS"),
 		},
 		RateLimit: RateLimitConfig{
 			Enabled:           v.GetBool("RATE_LIMIT_ENABLED"),
 			RequestsPerSecond: v.GetFloat64("RATE_LIMIT_REQUESTS_PER_SECOND"),
 			Burst:             v.GetInt("RATE_LIMIT_BURST"),
+			ImpersonationsPerMinute: getIntOrDefault(v, "RATE_LIMIT_IMPERSONATIONS_PER_MINUTE",
+				DefaultImpersonationsPerMinute),
+		},
+		Idempotency: IdempotencyConfig{
+			TTL: idempotencyTTL,
+		},
+		Maintenance: MaintenanceConfig{
+			RetryAfter:    maintenanceRetryAfter,
+			AllowedRoutes: getCommaSeparated(v, "MAINTENANCE_ALLOWED_ROUTES"),
+		},
+		Registration: RegistrationConfig{
+			LimitPerIP:  getIntOrDefault(v, "REGISTRATION_LIMIT_PER_IP", DefaultRegistrationsPerIP),
+			LimitWindow: registrationLimitWindow,
 		},
 		Log: LogConfig{
 			Level:  v.GetString("LOG_LEVEL"),
 			Format: v.GetString("LOG_FORMAT"),
-			Output: v.GetString("LOG_OUTPUT"),
+			Output: getCommaSeparated(v, "LOG_OUTPUT"),
+
+			MaxSizeMB:  v.GetInt("LOG_MAX_SIZE_MB"),
+			MaxAgeDays: v.GetInt("LOG_MAX_AGE_DAYS"),
+			MaxBackups: v.GetInt("LOG_MAX_BACKUPS"),
+
+			AccessLogFormat:      v.GetString("LOG_ACCESS_FORMAT"),
+			AccessLogSampleRate:  v.GetInt("LOG_ACCESS_SAMPLE_RATE"),
+			SlowRequestThreshold: slowRequestThreshold,
+			RequestHeaders:       v.GetBool("LOG_REQUEST_HEADERS"),
+			RedactHeaders:        getCommaSeparated(v, "LOG_REDACT_HEADERS"),
+			RequestIDPolicy:      requestIDPolicy,
 		},
 		Metrics: MetricsConfig{
 			Enabled: v.GetBool("METRICS_ENABLED"),
 			Port:    v.GetInt("METRICS_PORT"),
+			Pprof:   v.GetBool("METRICS_PPROF_ENABLED"),
+		},
+		GRPC: GRPCConfig{
+			Enabled: v.GetBool("GRPC_ENABLED"),
+			Port:    getIntOrDefault(v, "GRPC_PORT", DefaultGRPCPort),
+		},
+		Tracing: TracingConfig{
+			Enabled:     v.GetBool("TRACING_ENABLED"),
+			Endpoint:    v.GetString("TRACING_OTLP_ENDPOINT"),
+			Insecure:    v.GetBool("TRACING_INSECURE"),
+			SampleRatio: tracingSampleRatio,
 		},
 		Security: SecurityConfig{
-			BcryptCost:        v.GetInt("BCRYPT_COST"),
-			PasswordMinLength: v.GetInt("PASSWORD_MIN_LENGTH"),
+			PasswordAlgorithm:          v.GetString("PASSWORD_ALGORITHM"),
+			BcryptCost:                 v.GetInt("BCRYPT_COST"),
+			PasswordMinLength:          getIntOrDefault(v, "PASSWORD_MIN_LENGTH", DefaultPasswordMinLength),
+			PasswordMaxLength:          v.GetInt("PASSWORD_MAX_LENGTH"),
+			PasswordRequireUpper:       getBoolOrDefault(v, "PASSWORD_REQUIRE_UPPERCASE", true),
+			PasswordRequireLower:       getBoolOrDefault(v, "PASSWORD_REQUIRE_LOWERCASE", true),
+			PasswordRequireDigit:       getBoolOrDefault(v, "PASSWORD_REQUIRE_DIGIT", true),
+			PasswordRequireSpecial:     getBoolOrDefault(v, "PASSWORD_REQUIRE_SPECIAL", true),
+			PasswordDisallowCommon:     v.GetBool("PASSWORD_DISALLOW_COMMON"),
+			BlockDisposableEmails:      v.GetBool("BLOCK_DISPOSABLE_EMAILS"),
+			DisposableEmailDomainsFile: v.GetString("DISPOSABLE_EMAIL_DOMAINS_FILE"),
+			Argon2Memory:               v.GetUint32("ARGON2_MEMORY"),
+			Argon2Iterations:           v.GetUint32("ARGON2_ITERATIONS"),
+			Argon2Parallelism:          uint8(v.GetUint("ARGON2_PARALLELISM")),
+			TOTPEncryptionKey:          v.GetString("TOTP_ENCRYPTION_KEY"),
+			TOTPIssuer:                 totpIssuer,
+			PIIEncryptionKey:           v.GetString("PII_ENCRYPTION_KEY"),
+			PIIBlindIndexKey:           v.GetString("PII_BLIND_INDEX_KEY"),
 		},
 		Pagination: PaginationConfig{
-			DefaultPageSize: v.GetInt("DEFAULT_PAGE_SIZE"),
-			MaxPageSize:     v.GetInt("MAX_PAGE_SIZE"),
+			DefaultPageSize:           v.GetInt("DEFAULT_PAGE_SIZE"),
+			MaxPageSize:               v.GetInt("MAX_PAGE_SIZE"),
+			SearchSimilarityThreshold: searchSimilarityThreshold,
+		},
+		Scheduler: SchedulerConfig{
+			Enabled:              getBoolOrDefault(v, "SCHEDULER_ENABLED", true),
+			UserPurgeInterval:    userPurgeInterval,
+			DeletedUserRetention: deletedUserRetention,
 		},
+		Storage: StorageConfig{
+			Driver:         storageDriver,
+			PublicURL:      storagePublicURL,
+			LocalDir:       storageLocalDir,
+			MaxAvatarSize:  maxAvatarSize,
+			S3Endpoint:     v.GetString("STORAGE_S3_ENDPOINT"),
+			S3Region:       v.GetString("STORAGE_S3_REGION"),
+			S3Bucket:       v.GetString("STORAGE_S3_BUCKET"),
+			S3AccessKey:    v.GetString("STORAGE_S3_ACCESS_KEY"),
+			S3SecretKey:    v.GetString("STORAGE_S3_SECRET_KEY"),
+			S3UsePathStyle: v.GetBool("STORAGE_S3_USE_PATH_STYLE"),
+		},
+		Notify: NotifyConfig{
+			Driver:       notifyDriver,
+			Async:        v.GetBool("NOTIFY_ASYNC"),
+			Workers:      getIntOrDefault(v, "NOTIFY_WORKERS", DefaultNotifyWorkers),
+			From:         v.GetString("NOTIFY_FROM"),
+			SMTPHost:     v.GetString("NOTIFY_SMTP_HOST"),
+			SMTPPort:     getIntOrDefault(v, "NOTIFY_SMTP_PORT", 587),
+			SMTPUsername: v.GetString("NOTIFY_SMTP_USERNAME"),
+			SMTPPassword: v.GetString("NOTIFY_SMTP_PASSWORD"),
+		},
+	}
+
+	// Report malformed durations together with the validation problems so a
+	// single startup attempt lists everything that needs fixing.
+	if problems := append(durations.errs, config.validate()...); len(problems) > 0 {
+		return nil, invalidConfig(problems)
 	}
 
 	return config, nil
 }
 
+// durationParser parses duration settings, collecting every malformed value
+// instead of stopping at the first. Unset values take the given default.
+type durationParser struct {
+	v    *viper.Viper
+	errs []error
+}
+
+func (p *durationParser) parse(key string, fallback time.Duration) time.Duration {
+	raw := strings.TrimSpace(p.v.GetString(key))
+	if raw == "" {
+		return fallback
+	}
+
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		p.errs = append(p.errs, fmt.Errorf("%s: invalid duration %q", key, raw))
+		return fallback
+	}
+
+	return d
+}
+
+// Validate reports every invalid or missing setting in a single error, one
+// problem per line.
+func (c *Config) Validate() error {
+	if errs := c.validate(); len(errs) > 0 {
+		return invalidConfig(errs)
+	}
+	return nil
+}
+
+func (c *Config) validate() []error {
+	var errs []error
+	check := func(ok bool, format string, args ...interface{}) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	check(validPort(c.App.Port), "APP_PORT: must be between 1 and 65535, got %d", c.App.Port)
+	if _, err := c.App.Location(); err != nil {
+		errs = append(errs, err)
+	}
+	check(validPort(c.Database.Port), "DB_PORT: must be between 1 and 65535, got %d", c.Database.Port)
+	if c.Metrics.Enabled {
+		check(validPort(c.Metrics.Port), "METRICS_PORT: must be between 1 and 65535, got %d", c.Metrics.Port)
+		check(c.Metrics.Port != c.App.Port, "METRICS_PORT: must differ from APP_PORT")
+	}
+	if c.GRPC.Enabled {
+		check(validPort(c.GRPC.Port), "GRPC_PORT: must be between 1 and 65535, got %d", c.GRPC.Port)
+		check(c.GRPC.Port != c.App.Port, "GRPC_PORT: must differ from APP_PORT")
+		check(!c.Metrics.Enabled || c.GRPC.Port != c.Metrics.Port, "GRPC_PORT: must differ from METRICS_PORT")
+	}
+
+	check(c.Server.MaxRequestBodySize >= 0, "SERVER_MAX_REQUEST_BODY_SIZE: must not be negative")
+	for _, proxy := range c.Server.TrustedProxies {
+		check(validIPOrCIDR(proxy), "SERVER_TRUSTED_PROXIES: %q is not an IP address or CIDR", proxy)
+	}
+
+	check(c.Database.Host != "", "DB_HOST: is required")
+	check(c.Database.Name != "", "DB_NAME: is required")
+	check(c.Database.QueryTimeout >= 0, "DB_QUERY_TIMEOUT: must not be negative")
+	check(c.Database.SlowQueryThreshold >= 0, "DB_SLOW_QUERY_THRESHOLD: must not be negative")
+	check(c.Database.ConnectAttempts >= 0, "DB_CONNECT_ATTEMPTS: must not be negative")
+	for _, host := range c.Database.ReplicaHosts {
+		check(validHostPort(host), "DB_REPLICA_HOSTS: %q is not a host or host:port", host)
+	}
+	check(c.Redis.ConnectAttempts >= 0, "REDIS_CONNECT_ATTEMPTS: must not be negative")
+	check(c.RabbitMQ.ConnectAttempts >= 0, "RABBITMQ_CONNECT_ATTEMPTS: must not be negative")
+	switch c.Redis.Mode {
+	case "", RedisModeSingle:
+		check(c.Redis.Host != "", "REDIS_HOST: is required")
+		check(validPort(c.Redis.Port), "REDIS_PORT: must be between 1 and 65535, got %d", c.Redis.Port)
+	case RedisModeSentinel:
+		check(len(c.Redis.Addrs) > 0, "REDIS_ADDRS: is required in sentinel mode")
+		check(c.Redis.MasterName != "", "REDIS_MASTER_NAME: is required in sentinel mode")
+	case RedisModeCluster:
+		check(len(c.Redis.Addrs) > 0, "REDIS_ADDRS: is required in cluster mode")
+		check(c.Redis.DB == 0, "REDIS_DB: must be 0 in cluster mode, got %d", c.Redis.DB)
+	default:
+		check(false, "REDIS_MODE: must be one of single, sentinel or cluster, got %q", c.Redis.Mode)
+	}
+
+	check(c.Cache.WarmUsers >= 0, "CACHE_WARM_USERS: must not be negative")
+
+	check(c.Log.MaxSizeMB >= 0, "LOG_MAX_SIZE_MB: must not be negative")
+	check(c.Log.MaxAgeDays >= 0, "LOG_MAX_AGE_DAYS: must not be negative")
+	check(c.Log.MaxBackups >= 0, "LOG_MAX_BACKUPS: must not be negative")
+	switch c.Log.RequestIDPolicy {
+	case "", RequestIDPolicyToken, RequestIDPolicyUUID, RequestIDPolicyRegenerate:
+	default:
+		check(false, "LOG_REQUEST_ID_POLICY: must be token, uuid or regenerate, got %q", c.Log.RequestIDPolicy)
+	}
+
+	switch c.Events.Backend {
+	case "", EventsBackendRabbitMQ, EventsBackendRedisStream:
+	default:
+		check(false, "EVENTS_BACKEND: must be rabbitmq or redis_stream, got %q", c.Events.Backend)
+	}
+	check(c.Events.StreamMaxLen >= 0, "EVENTS_STREAM_MAX_LEN: must not be negative")
+
+	check(c.JWT.Secret != "", "JWT_SECRET: is required")
+	check(c.JWT.Secret == "" || len(c.JWT.Secret) >= MinJWTSecretLength,
+		"JWT_SECRET: must be at least %d characters, got %d", MinJWTSecretLength, len(c.JWT.Secret))
+	check(c.JWT.AccessTokenExpiry > 0, "JWT_ACCESS_TOKEN_EXPIRY: must be a positive duration")
+	check(c.JWT.RefreshTokenExpiry > 0, "JWT_REFRESH_TOKEN_EXPIRY: must be a positive duration")
+	check(c.JWT.RefreshTokenExpiry == 0 || c.JWT.RefreshTokenExpiry > c.JWT.AccessTokenExpiry,
+		"JWT_REFRESH_TOKEN_EXPIRY: must be longer than JWT_ACCESS_TOKEN_EXPIRY")
+	check(c.JWT.RememberMeRefreshExpiry == 0 || c.JWT.RememberMeRefreshExpiry >= c.JWT.RefreshTokenExpiry,
+		"JWT_REMEMBER_ME_REFRESH_EXPIRY: must not be shorter than JWT_REFRESH_TOKEN_EXPIRY")
+	check(c.JWT.ImpersonationExpiry > 0, "JWT_IMPERSONATION_EXPIRY: must be a positive duration")
+	check(c.JWT.ImpersonationExpiry < c.JWT.AccessTokenExpiry || c.JWT.AccessTokenExpiry <= 0,
+		"JWT_IMPERSONATION_EXPIRY: must be shorter than JWT_ACCESS_TOKEN_EXPIRY")
+	check(c.RateLimit.ImpersonationsPerMinute > 0, "RATE_LIMIT_IMPERSONATIONS_PER_MINUTE: must be positive")
+	check(c.Maintenance.RetryAfter >= 0, "MAINTENANCE_RETRY_AFTER: must not be negative")
+	check(c.Registration.LimitPerIP >= 0, "REGISTRATION_LIMIT_PER_IP: must not be negative")
+	check(c.Registration.LimitPerIP == 0 || c.Registration.LimitWindow > 0,
+		"REGISTRATION_LIMIT_WINDOW: must be a positive duration")
+
+	switch c.AuthCookie.Delivery {
+	case "", constants.TokenDeliveryJSON, constants.TokenDeliveryCookie, constants.TokenDeliveryBoth:
+	default:
+		check(false, "AUTH_COOKIE_DELIVERY: must be json, cookie or both, got %q", c.AuthCookie.Delivery)
+	}
+	switch c.AuthCookie.SameSite {
+	case "", "strict", "lax":
+	case "none":
+		check(c.AuthCookie.Secure, "AUTH_COOKIE_SAME_SITE: none requires AUTH_COOKIE_SECURE")
+	default:
+		check(false, "AUTH_COOKIE_SAME_SITE: must be strict, lax or none, got %q", c.AuthCookie.SameSite)
+	}
+
+	// Zero, an unset BCRYPT_COST, falls back to bcrypt's default cost.
+	check(c.Security.BcryptCost == 0 || (c.Security.BcryptCost >= bcrypt.MinCost && c.Security.BcryptCost <= bcrypt.MaxCost),
+		"BCRYPT_COST: must be between %d and %d, got %d", bcrypt.MinCost, bcrypt.MaxCost, c.Security.BcryptCost)
+	switch c.Security.PasswordAlgorithm {
+	case "", "bcrypt", "argon2id":
+	default:
+		check(false, "PASSWORD_ALGORITHM: must be bcrypt or argon2id, got %q", c.Security.PasswordAlgorithm)
+	}
+	check(c.Security.PasswordMinLength >= 0, "PASSWORD_MIN_LENGTH: must not be negative")
+	check(c.Security.PasswordMaxLength == 0 || c.Security.PasswordMaxLength >= c.Security.PasswordMinLength,
+		"PASSWORD_MAX_LENGTH: must be 0 or at least PASSWORD_MIN_LENGTH")
+
+	if _, err := c.Security.TOTPKey(); err != nil {
+		errs = append(errs, err)
+	}
+	if _, _, err := c.Security.PIIKeys(); err != nil {
+		errs = append(errs, err)
+	}
+
+	check(c.Storage.MaxAvatarSize > 0, "STORAGE_MAX_AVATAR_SIZE: must be positive")
+	check(c.Server.MaxRequestBodySize == 0 || c.Storage.MaxAvatarSize < c.Server.MaxRequestBodySize,
+		"STORAGE_MAX_AVATAR_SIZE: must be below SERVER_MAX_REQUEST_BODY_SIZE (%d)", c.Server.MaxRequestBodySize)
+	switch c.Storage.Driver {
+	case "", StorageDriverLocal:
+	case StorageDriverS3:
+		check(c.Storage.S3Endpoint != "", "STORAGE_S3_ENDPOINT: is required for the s3 driver")
+		check(c.Storage.S3Bucket != "", "STORAGE_S3_BUCKET: is required for the s3 driver")
+		check(c.Storage.S3AccessKey != "", "STORAGE_S3_ACCESS_KEY: is required for the s3 driver")
+		check(c.Storage.S3SecretKey != "", "STORAGE_S3_SECRET_KEY: is required for the s3 driver")
+	default:
+		check(false, "STORAGE_DRIVER: must be local or s3, got %q", c.Storage.Driver)
+	}
+
+	switch c.Notify.Driver {
+	case "", NotifyDriverLog:
+	case NotifyDriverSMTP:
+		check(c.Notify.SMTPHost != "", "NOTIFY_SMTP_HOST: is required for the smtp driver")
+		check(validPort(c.Notify.SMTPPort), "NOTIFY_SMTP_PORT: must be between 1 and 65535, got %d", c.Notify.SMTPPort)
+		check(c.Notify.From != "", "NOTIFY_FROM: is required for the smtp driver")
+	default:
+		check(false, "NOTIFY_DRIVER: must be log or smtp, got %q", c.Notify.Driver)
+	}
+	if c.Notify.Async {
+		check(c.Notify.Workers > 0, "NOTIFY_WORKERS: must be positive")
+	}
+
+	if c.Scheduler.Enabled {
+		check(c.Scheduler.UserPurgeInterval > 0, "SCHEDULER_USER_PURGE_INTERVAL: must be a positive duration")
+	}
+	check(c.Scheduler.DeletedUserRetention >= 0, "SCHEDULER_DELETED_USER_RETENTION: must not be negative")
+
+	check(c.Pagination.SearchSimilarityThreshold >= 0 && c.Pagination.SearchSimilarityThreshold <= 1,
+		"SEARCH_SIMILARITY_THRESHOLD: must be between 0 and 1, got %g", c.Pagination.SearchSimilarityThreshold)
+
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1,
+		"TRACING_SAMPLE_RATIO: must be between 0 and 1, got %g", c.Tracing.SampleRatio)
+
+	if err := c.CORS.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+func invalidConfig(errs []error) error {
+	return fmt.Errorf("invalid config:\n%w", errors.Join(errs...))
+}
+
+func validIPOrCIDR(s string) bool {
+	if net.ParseIP(s) != nil {
+		return true
+	}
+	_, _, err := net.ParseCIDR(s)
+	return err == nil
+}
+
+func validPort(port int) bool {
+	return port > 0 && port <= 65535
+}
+
+// validHostPort reports whether s is a host, optionally followed by a valid
+// :port.
+func validHostPort(s string) bool {
+	if !strings.Contains(s, ":") {
+		return s != ""
+	}
+	host, port, err := net.SplitHostPort(s)
+	if err != nil || host == "" {
+		return false
+	}
+	n, err := strconv.Atoi(port)
+	return err == nil && validPort(n)
+}
+
+// readConfigFile loads the config file into v as defaults keyed by their env
+// variable names, so the environment still takes precedence. A missing .env
+// is not an error, which lets deployments configure the app purely through the
+// environment; a missing file explicitly named by CONFIG_FILE is.
+func readConfigFile(v *viper.Viper) error {
+	path := configFilePath()
+	explicit := os.Getenv(EnvConfigFile) != ""
+
+	if _, err := os.Stat(path); err != nil {
+		if !explicit && errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+
+	configType, err := configFileType(path)
+	if err != nil {
+		return err
+	}
+
+	file := viper.New()
+	file.SetConfigFile(path)
+	file.SetConfigType(configType)
+	if err := file.ReadInConfig(); err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+
+	for _, key := range file.AllKeys() {
+		v.SetDefault(envKey(key), file.Get(key))
+	}
+
+	return nil
+}
+
+// configFilePath returns the file named by CONFIG_FILE, or .env when unset.
+func configFilePath() string {
+	if path := os.Getenv(EnvConfigFile); path != "" {
+		return path
+	}
+	return defaultConfigFile
+}
+
+func configFileType(path string) (string, error) {
+	// Variants such as .env.local or .env.production are dotenv files too.
+	if strings.HasPrefix(filepath.Base(path), ".env") {
+		return "env", nil
+	}
+
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".env":
+		return "env", nil
+	case ".yaml", ".yml":
+		return "yaml", nil
+	case ".json":
+		return "json", nil
+	default:
+		return "", fmt.Errorf("unsupported config file type %q: use .env, .yaml, .yml or .json", ext)
+	}
+}
+
+// envKey converts a viper key read from a config file into the env variable
+// name Load looks up. Flat keys, as found in .env files, are only upper-cased.
+func envKey(key string) string {
+	section, rest, nested := strings.Cut(key, ".")
+	prefix, known := sectionPrefixes[section]
+	if !nested || !known {
+		return strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+	}
+	return prefix + strings.ToUpper(strings.ReplaceAll(rest, ".", "_"))
+}
+
+// getIntOrDefault returns the int value of key, or fallback when it is unset.
+func getIntOrDefault(v *viper.Viper, key string, fallback int) int {
+	if !v.IsSet(key) {
+		return fallback
+	}
+	return v.GetInt(key)
+}
+
+// getBoolOrDefault returns the bool value of key, or fallback when it is unset.
+func getBoolOrDefault(v *viper.Viper, key string, fallback bool) bool {
+	if !v.IsSet(key) {
+		return fallback
+	}
+	return v.GetBool(key)
+}
+
+// getCommaSeparated splits a comma-separated value. viper only splits env
+// values on whitespace, so lists like "a,b" would otherwise stay one item.
+// Lists from YAML or JSON config files are used as they are.
+func getCommaSeparated(v *viper.Viper, key string) []string {
+	if _, isList := v.Get(key).([]interface{}); isList {
+		return v.GetStringSlice(key)
+	}
+
+	var values []string
+	for _, item := range strings.Split(v.GetString(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
+
 func (c *Config) GetDSN() string {
 	return fmt.Sprintf(
 		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s",
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"reflect"
+	"sync"
+
+	"github.com/TubagusAldiMY/go-template/pkg/logger"
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// Manager holds the running config and reloads it when the config file
+// changes. Only the hot-reloadable settings are taken over from a reload:
+// LOG_LEVEL and the RATE_LIMIT_* and CORS_* settings. Changes to any other
+// setting need a restart and are logged and ignored.
+type Manager struct {
+	mu          sync.RWMutex
+	current     *Config
+	subscribers []func(*Config)
+}
+
+// NewManager returns a manager starting from cfg, as returned by Load.
+func NewManager(cfg *Config) *Manager {
+	return &Manager{current: cfg}
+}
+
+// Current returns the config in effect. A reload replaces it with a new
+// value, so callers may keep the returned pointer but must not modify it.
+func (m *Manager) Current() *Config {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.current
+}
+
+// Subscribe calls fn with the new config after every reload that changes a
+// hot-reloadable setting. fn runs on the watcher's goroutine.
+func (m *Manager) Subscribe(fn func(*Config)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.subscribers = append(m.subscribers, fn)
+}
+
+// Watch reloads the config whenever the file named by CONFIG_FILE (or .env)
+// is written. Without a config file there is nothing to watch and Watch
+// does nothing.
+func (m *Manager) Watch() error {
+	path := configFilePath()
+	if _, err := os.Stat(path); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to watch config: %w", err)
+	}
+
+	configType, err := configFileType(path)
+	if err != nil {
+		return err
+	}
+
+	watcher := viper.New()
+	watcher.SetConfigFile(path)
+	watcher.SetConfigType(configType)
+	if err := watcher.ReadInConfig(); err != nil {
+		return fmt.Errorf("failed to watch config: %w", err)
+	}
+	watcher.OnConfigChange(func(event fsnotify.Event) {
+		if err := m.Reload(); err != nil {
+			logger.Warn("config reload failed, keeping the current config",
+				zap.String("file", event.Name),
+				zap.Error(err),
+			)
+		}
+	})
+	watcher.WatchConfig()
+
+	logger.Info("watching config file for changes", zap.String("file", path))
+
+	return nil
+}
+
+// Reload loads the config again and applies its hot-reloadable settings.
+// An invalid config is rejected as a whole and the current one kept.
+func (m *Manager) Reload() error {
+	next, err := Load()
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	current := m.current
+
+	// The next config with the hot-reloadable settings reset differs from
+	// the current one only in settings that need a restart.
+	frozen := *next
+	copyHotReloadable(&frozen, current)
+	if ignored := changedSections(current, &frozen); len(ignored) > 0 {
+		logger.Warn("config changes require a restart and were ignored", zap.Strings("sections", ignored))
+	}
+
+	updated := *current
+	copyHotReloadable(&updated, next)
+	if reflect.DeepEqual(&updated, current) {
+		m.mu.Unlock()
+		return nil
+	}
+
+	m.current = &updated
+	subscribers := append([]func(*Config){}, m.subscribers...)
+	m.mu.Unlock()
+
+	logger.Info("config reloaded",
+		zap.String("log_level", updated.Log.Level),
+		zap.Bool("rate_limit_enabled", updated.RateLimit.Enabled),
+	)
+	for _, fn := range subscribers {
+		fn(&updated)
+	}
+
+	return nil
+}
+
+// copyHotReloadable copies the settings that can change without a restart
+// from src to dst.
+func copyHotReloadable(dst, src *Config) {
+	dst.Log.Level = src.Log.Level
+	dst.RateLimit = src.RateLimit
+	dst.CORS = src.CORS
+}
+
+// changedSections names the top-level sections that differ between a and b.
+func changedSections(a, b *Config) []string {
+	va, vb := reflect.ValueOf(a).Elem(), reflect.ValueOf(b).Elem()
+
+	var changed []string
+	for i := 0; i < va.NumField(); i++ {
+		if !reflect.DeepEqual(va.Field(i).Interface(), vb.Field(i).Interface()) {
+			changed = append(changed, va.Type().Field(i).Name)
+		}
+	}
+	return changed
+}
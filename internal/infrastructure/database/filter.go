@@ -0,0 +1,100 @@
+package database
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Filter accumulates the conditions of a WHERE clause together with their
+// positional arguments, numbering the placeholders as it goes so callers never
+// track argument positions by hand. Conditions are joined with AND; Or and And
+// add parenthesised groups. Values must always be bound, never interpolated.
+//
+//	f := database.NewFilter()
+//	f.Where("deleted_at IS NULL")
+//	f.Where("role = ?", role)
+//	f.Or(func(g *database.Filter) {
+//		g.Where("status = ?", "active")
+//		g.Where("created_at > ?", since)
+//	})
+//	query := "SELECT id FROM users" + f.SQL() // ... WHERE ... AND role = $1 AND (status = $2 OR created_at > $3)
+type Filter struct {
+	args  *[]any
+	op    string
+	conds []string
+}
+
+// NewFilter returns an empty filter whose conditions are joined with AND.
+func NewFilter() *Filter {
+	return &Filter{args: new([]any), op: " AND "}
+}
+
+// Arg binds value and returns its placeholder. Use it to refer to one value
+// from several conditions, or to bind values outside the WHERE clause such as
+// LIMIT and OFFSET. Placeholders keep counting across groups.
+func (f *Filter) Arg(value any) string {
+	*f.args = append(*f.args, value)
+	return "$" + strconv.Itoa(len(*f.args))
+}
+
+// Where adds cond, replacing each ? in it with the placeholder of the next
+// value in args. cond must not contain any other ?.
+func (f *Filter) Where(cond string, args ...any) *Filter {
+	if len(args) > 0 {
+		parts := strings.Split(cond, "?")
+		if len(parts)-1 != len(args) {
+			panic("database: Filter.Where placeholder count does not match args in " + strconv.Quote(cond))
+		}
+
+		var b strings.Builder
+		b.WriteString(parts[0])
+		for i, arg := range args {
+			b.WriteString(f.Arg(arg))
+			b.WriteString(parts[i+1])
+		}
+		cond = b.String()
+	}
+
+	f.conds = append(f.conds, cond)
+	return f
+}
+
+// Or adds the conditions build adds to its group, joined with OR. An empty
+// group adds nothing.
+func (f *Filter) Or(build func(g *Filter)) *Filter {
+	return f.group(" OR ", build)
+}
+
+// And adds the conditions build adds to its group, joined with AND. It is
+// only needed inside an Or group.
+func (f *Filter) And(build func(g *Filter)) *Filter {
+	return f.group(" AND ", build)
+}
+
+func (f *Filter) group(op string, build func(g *Filter)) *Filter {
+	g := &Filter{args: f.args, op: op}
+	build(g)
+
+	if cond := g.render(); cond != "" {
+		f.conds = append(f.conds, "("+cond+")")
+	}
+	return f
+}
+
+// SQL renders the filter as " WHERE ...", or "" when it has no conditions.
+func (f *Filter) SQL() string {
+	cond := f.render()
+	if cond == "" {
+		return ""
+	}
+	return " WHERE " + cond
+}
+
+// Args returns the values bound so far, in placeholder order.
+func (f *Filter) Args() []any {
+	return append([]any(nil), *f.args...)
+}
+
+func (f *Filter) render() string {
+	return strings.Join(f.conds, f.op)
+}
@@ -3,23 +3,97 @@ package database
 import (
 	"context"
 	"fmt"
+	"net"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/TubagusAldiMY/go-template/internal/infrastructure/config"
 	"github.com/TubagusAldiMY/go-template/pkg/logger"
+	"github.com/TubagusAldiMY/go-template/pkg/retry"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
 )
 
+// connectBackoff is the wait before the first connection retry; it doubles
+// with every further attempt.
+const connectBackoff = time.Second
+
+// connectTimeout bounds each connection attempt and the pool warmup.
+const connectTimeout = 10 * time.Second
+
 type PostgreSQL struct {
 	Pool *pgxpool.Pool
+	// ReadPool connects to the read replicas; it is nil when none are
+	// configured.
+	ReadPool *pgxpool.Pool
 }
 
-func NewPostgreSQL(cfg config.DatabaseConfig) (*PostgreSQL, error) {
+// Option customizes the pool configuration before the pool is created.
+type Option func(*pgxpool.Config)
+
+// WithQueryTracer adds tracer to the pool's connections. Multiple tracers are
+// called in the order they were added.
+func WithQueryTracer(tracer pgx.QueryTracer) Option {
+	return func(poolConfig *pgxpool.Config) {
+		if existing := poolConfig.ConnConfig.Tracer; existing != nil {
+			poolConfig.ConnConfig.Tracer = multiQueryTracer{existing, tracer}
+			return
+		}
+		poolConfig.ConnConfig.Tracer = tracer
+	}
+}
+
+// NewPostgreSQL connects to the primary and, when cfg.ReplicaHosts is set, to
+// the read replicas. opts apply to both pools.
+func NewPostgreSQL(cfg config.DatabaseConfig, opts ...Option) (*PostgreSQL, error) {
+	pool, err := connect(cfg, cfg.Host, strconv.Itoa(cfg.Port), "postgres", opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(cfg.ReplicaHosts) == 0 {
+		return &PostgreSQL{Pool: pool}, nil
+	}
+
+	hosts, ports, err := replicaAddrs(cfg)
+	if err != nil {
+		pool.Close()
+		return nil, err
+	}
+	readPool, err := connect(cfg, hosts, ports, "postgres replica", opts)
+	if err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("replica: %w", err)
+	}
+
+	return &PostgreSQL{Pool: pool, ReadPool: readPool}, nil
+}
+
+// replicaAddrs returns the replica hosts and ports as comma-separated lists,
+// defaulting the port of hosts without one to the primary's. pgx tries the
+// hosts in order for every new connection and uses the first that accepts.
+func replicaAddrs(cfg config.DatabaseConfig) (hosts, ports string, err error) {
+	hostList := make([]string, len(cfg.ReplicaHosts))
+	portList := make([]string, len(cfg.ReplicaHosts))
+	for i, addr := range cfg.ReplicaHosts {
+		hostList[i], portList[i] = addr, strconv.Itoa(cfg.Port)
+		if strings.Contains(addr, ":") {
+			if hostList[i], portList[i], err = net.SplitHostPort(addr); err != nil {
+				return "", "", fmt.Errorf("invalid replica host %q: %w", addr, err)
+			}
+		}
+	}
+	return strings.Join(hostList, ","), strings.Join(portList, ","), nil
+}
+
+// connect opens a pool to host and port, which may list several hosts, and
+// waits until it answers. dependency names the database in logs.
+func connect(cfg config.DatabaseConfig, host, port, dependency string, opts []Option) (*pgxpool.Pool, error) {
 	dsn := fmt.Sprintf(
-		"host=%s port=%d user=%s password=%s dbname=%s sslmode=%s pool_max_conns=%d pool_min_conns=%d pool_max_conn_lifetime=%s",
-		cfg.Host,
-		cfg.Port,
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s pool_max_conns=%d pool_min_conns=%d pool_max_conn_lifetime=%s",
+		host,
+		port,
 		cfg.User,
 		cfg.Password,
 		cfg.Name,
@@ -41,42 +115,159 @@ func NewPostgreSQL(cfg config.DatabaseConfig) (*PostgreSQL, error) {
 	poolConfig.MaxConnIdleTime = 10 * time.Minute
 	poolConfig.HealthCheckPeriod = 1 * time.Minute
 
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	for _, opt := range opts {
+		opt(poolConfig)
+	}
 
-	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create connection pool: %w", err)
 	}
 
-	// Test connection
-	if err := pool.Ping(ctx); err != nil {
+	// Test connection, retrying while the database is still starting up
+	retryCtx := logger.WithContext(context.Background(), zap.String("dependency", dependency))
+	err = retry.Do(retryCtx, cfg.ConnectAttempts, connectBackoff, func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), connectTimeout)
+		defer cancel()
+		return pool.Ping(ctx)
+	})
+	if err != nil {
+		pool.Close()
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
 	logger.Info("database connection established",
-		zap.String("host", cfg.Host),
-		zap.Int("port", cfg.Port),
+		zap.String("dependency", dependency),
+		zap.String("host", host),
+		zap.String("port", port),
 		zap.String("database", cfg.Name),
 	)
 
-	return &PostgreSQL{Pool: pool}, nil
+	if cfg.PoolWarmup && poolConfig.MinConns > 0 {
+		if err := warmPool(pool, int(poolConfig.MinConns)); err != nil {
+			pool.Close()
+			return nil, err
+		}
+	}
+
+	return pool, nil
+}
+
+// warmPool opens minConns connections up front, which pgxpool otherwise only
+// does in the background after its first health check, so the first burst of
+// requests does not pay for connection setup. The connections are acquired
+// together, forcing the pool to open each of them, and then released to it.
+// Startup only fails if not a single connection can be opened; a partly
+// warmed pool is logged and fills up on demand.
+func warmPool(pool *pgxpool.Pool, minConns int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), connectTimeout)
+	defer cancel()
+
+	start := time.Now()
+	conns := make(chan *pgxpool.Conn, minConns)
+	errs := make(chan error, minConns)
+	for i := 0; i < minConns; i++ {
+		go func() {
+			conn, err := pool.Acquire(ctx)
+			if err != nil {
+				errs <- err
+				return
+			}
+			conns <- conn
+		}()
+	}
+
+	// Hold every connection until all are acquired, so none is reused.
+	warmed := 0
+	var lastErr error
+	for i := 0; i < minConns; i++ {
+		select {
+		case conn := <-conns:
+			defer conn.Release()
+			warmed++
+		case err := <-errs:
+			lastErr = err
+		}
+	}
+
+	if warmed == 0 {
+		return fmt.Errorf("failed to warm connection pool: %w", lastErr)
+	}
+	if lastErr != nil {
+		logger.Warn("database connection pool partly warmed",
+			zap.Int("warmed", warmed),
+			zap.Int("min_conns", minConns),
+			zap.Duration("duration", time.Since(start)),
+			zap.Error(lastErr),
+		)
+		return nil
+	}
+
+	logger.Info("database connection pool warmed",
+		zap.Int("warmed", warmed),
+		zap.Duration("duration", time.Since(start)),
+	)
+	return nil
 }
 
 func (db *PostgreSQL) Close() {
+	if db.ReadPool != nil {
+		db.ReadPool.Close()
+	}
 	if db.Pool != nil {
 		db.Pool.Close()
 		logger.Info("database connection closed")
 	}
 }
 
+// Health pings the primary and, when configured, the read replicas.
 func (db *PostgreSQL) Health(ctx context.Context) error {
 	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	return db.Pool.Ping(ctx)
+	if err := db.Pool.Ping(ctx); err != nil {
+		return err
+	}
+	if db.ReadPool != nil {
+		if err := db.ReadPool.Ping(ctx); err != nil {
+			return fmt.Errorf("replica: %w", err)
+		}
+	}
+	return nil
 }
 
+// GetPool returns the primary's pool, like GetWritePool.
 func (db *PostgreSQL) GetPool() *pgxpool.Pool {
 	return db.Pool
 }
+
+// GetWritePool returns the primary's pool, for writes, transactions and
+// reads that must see the latest writes.
+func (db *PostgreSQL) GetWritePool() *pgxpool.Pool {
+	return db.Pool
+}
+
+// GetReadPool returns the read replicas' pool, or the primary's when no
+// replica is configured. Replicas may lag behind the primary.
+func (db *PostgreSQL) GetReadPool() *pgxpool.Pool {
+	if db.ReadPool != nil {
+		return db.ReadPool
+	}
+	return db.Pool
+}
+
+// multiQueryTracer fans query events out to several tracers.
+type multiQueryTracer []pgx.QueryTracer
+
+func (m multiQueryTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	for _, tracer := range m {
+		ctx = tracer.TraceQueryStart(ctx, conn, data)
+	}
+	return ctx
+}
+
+func (m multiQueryTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	for i := len(m) - 1; i >= 0; i-- {
+		m[i].TraceQueryEnd(ctx, conn, data)
+	}
+}
@@ -0,0 +1,16 @@
+package database
+
+import (
+	"context"
+	"time"
+)
+
+// WithQueryTimeout bounds ctx by timeout for a single repository call. An
+// earlier deadline already on ctx still applies, and a zero timeout leaves ctx
+// unchanged.
+func WithQueryTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
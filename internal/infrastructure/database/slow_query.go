@@ -0,0 +1,73 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/pkg/logger"
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+type slowQueryKey struct{}
+
+// slowQuery is what TraceQueryStart hands to TraceQueryEnd through the
+// query's context.
+type slowQuery struct {
+	start time.Time
+	sql   string
+	args  []any
+}
+
+// SlowQueryLogger is a pgx.QueryTracer that logs every query taking at least
+// its threshold at warn level, through the logger of the query's context so
+// the entry carries the request ID. Argument values may hold credentials or
+// personal data and are logged as their types only.
+type SlowQueryLogger struct {
+	threshold time.Duration
+}
+
+func NewSlowQueryLogger(threshold time.Duration) *SlowQueryLogger {
+	return &SlowQueryLogger{threshold: threshold}
+}
+
+func (l *SlowQueryLogger) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, slowQueryKey{}, &slowQuery{start: time.Now(), sql: data.SQL, args: data.Args})
+}
+
+func (l *SlowQueryLogger) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	query, ok := ctx.Value(slowQueryKey{}).(*slowQuery)
+	if !ok {
+		return
+	}
+
+	duration := time.Since(query.start)
+	if duration < l.threshold {
+		return
+	}
+
+	fields := []zap.Field{
+		zap.String("sql", strings.Join(strings.Fields(query.sql), " ")),
+		zap.Strings("args", redactArgs(query.args)),
+		zap.Duration("duration", duration),
+	}
+	if data.Err != nil {
+		fields = append(fields, zap.Error(data.Err))
+	}
+	logger.FromContext(ctx).Warn("slow query", fields...)
+}
+
+// redactArgs replaces each query argument with its type, or "NULL".
+func redactArgs(args []any) []string {
+	redacted := make([]string, len(args))
+	for i, arg := range args {
+		if arg == nil {
+			redacted[i] = "NULL"
+			continue
+		}
+		redacted[i] = fmt.Sprintf("%T", arg)
+	}
+	return redacted
+}
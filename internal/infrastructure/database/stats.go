@@ -0,0 +1,110 @@
+package database
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PoolStats is a snapshot of the connection pool.
+type PoolStats struct {
+	MaxConns          int32 `json:"max_conns"`
+	TotalConns        int32 `json:"total_conns"`
+	AcquiredConns     int32 `json:"acquired_conns"`
+	IdleConns         int32 `json:"idle_conns"`
+	ConstructingConns int32 `json:"constructing_conns"`
+
+	AcquireCount         int64 `json:"acquire_count"`
+	CanceledAcquireCount int64 `json:"canceled_acquire_count"`
+	// EmptyAcquireCount counts acquires that had to wait for a connection.
+	EmptyAcquireCount       int64 `json:"empty_acquire_count"`
+	NewConnsCount           int64 `json:"new_conns_count"`
+	MaxLifetimeDestroyCount int64 `json:"max_lifetime_destroy_count"`
+	MaxIdleDestroyCount     int64 `json:"max_idle_destroy_count"`
+
+	// AcquireDuration is the cumulative time spent acquiring connections and
+	// AverageAcquireDuration the mean per acquire; a rising average means
+	// requests are queueing for connections.
+	AcquireDuration        time.Duration `json:"acquire_duration_ns"`
+	AverageAcquireDuration time.Duration `json:"average_acquire_duration_ns"`
+}
+
+// Stats returns the current connection pool statistics.
+func (db *PostgreSQL) Stats() PoolStats {
+	s := db.Pool.Stat()
+
+	stats := PoolStats{
+		MaxConns:                s.MaxConns(),
+		TotalConns:              s.TotalConns(),
+		AcquiredConns:           s.AcquiredConns(),
+		IdleConns:               s.IdleConns(),
+		ConstructingConns:       s.ConstructingConns(),
+		AcquireCount:            s.AcquireCount(),
+		CanceledAcquireCount:    s.CanceledAcquireCount(),
+		EmptyAcquireCount:       s.EmptyAcquireCount(),
+		NewConnsCount:           s.NewConnsCount(),
+		MaxLifetimeDestroyCount: s.MaxLifetimeDestroyCount(),
+		MaxIdleDestroyCount:     s.MaxIdleDestroyCount(),
+		AcquireDuration:         s.AcquireDuration(),
+	}
+	if stats.AcquireCount > 0 {
+		stats.AverageAcquireDuration = stats.AcquireDuration / time.Duration(stats.AcquireCount)
+	}
+
+	return stats
+}
+
+// RegisterMetrics exposes the pool statistics to Prometheus. Values are read
+// from the pool on every scrape.
+func (db *PostgreSQL) RegisterMetrics(reg prometheus.Registerer) error {
+	return reg.Register(&poolCollector{db: db})
+}
+
+var (
+	poolMaxConnsDesc          = poolDesc("max_conns", "Maximum size of the pool.")
+	poolTotalConnsDesc        = poolDesc("total_conns", "Total number of connections in the pool.")
+	poolAcquiredConnsDesc     = poolDesc("acquired_conns", "Number of currently acquired connections.")
+	poolIdleConnsDesc         = poolDesc("idle_conns", "Number of currently idle connections.")
+	poolConstructingConnsDesc = poolDesc("constructing_conns", "Number of connections being established.")
+	poolAcquireCountDesc      = poolDesc("acquire_total", "Cumulative count of successful acquires.")
+	poolCanceledAcquireDesc   = poolDesc("canceled_acquire_total", "Cumulative count of acquires cancelled by a context.")
+	poolEmptyAcquireDesc      = poolDesc("empty_acquire_total", "Cumulative count of acquires that waited for a connection.")
+	poolAcquireDurationDesc   = poolDesc("acquire_duration_seconds_total", "Cumulative time spent acquiring connections.")
+	poolNewConnsDesc          = poolDesc("new_conns_total", "Cumulative count of new connections opened.")
+)
+
+func poolDesc(name, help string) *prometheus.Desc {
+	return prometheus.NewDesc("db_pool_"+name, help, nil, nil)
+}
+
+type poolCollector struct {
+	db *PostgreSQL
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- poolMaxConnsDesc
+	ch <- poolTotalConnsDesc
+	ch <- poolAcquiredConnsDesc
+	ch <- poolIdleConnsDesc
+	ch <- poolConstructingConnsDesc
+	ch <- poolAcquireCountDesc
+	ch <- poolCanceledAcquireDesc
+	ch <- poolEmptyAcquireDesc
+	ch <- poolAcquireDurationDesc
+	ch <- poolNewConnsDesc
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.db.Stats()
+
+	ch <- prometheus.MustNewConstMetric(poolMaxConnsDesc, prometheus.GaugeValue, float64(s.MaxConns))
+	ch <- prometheus.MustNewConstMetric(poolTotalConnsDesc, prometheus.GaugeValue, float64(s.TotalConns))
+	ch <- prometheus.MustNewConstMetric(poolAcquiredConnsDesc, prometheus.GaugeValue, float64(s.AcquiredConns))
+	ch <- prometheus.MustNewConstMetric(poolIdleConnsDesc, prometheus.GaugeValue, float64(s.IdleConns))
+	ch <- prometheus.MustNewConstMetric(poolConstructingConnsDesc, prometheus.GaugeValue, float64(s.ConstructingConns))
+	ch <- prometheus.MustNewConstMetric(poolAcquireCountDesc, prometheus.CounterValue, float64(s.AcquireCount))
+	ch <- prometheus.MustNewConstMetric(poolCanceledAcquireDesc, prometheus.CounterValue, float64(s.CanceledAcquireCount))
+	ch <- prometheus.MustNewConstMetric(poolEmptyAcquireDesc, prometheus.CounterValue, float64(s.EmptyAcquireCount))
+	ch <- prometheus.MustNewConstMetric(poolAcquireDurationDesc, prometheus.CounterValue, s.AcquireDuration.Seconds())
+	ch <- prometheus.MustNewConstMetric(poolNewConnsDesc, prometheus.CounterValue, float64(s.NewConnsCount))
+}
@@ -0,0 +1,380 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	sharedErrors "github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Table describes how entities of type T are stored, so that Store can run
+// the queries every repository repeats. Describe a table once per domain:
+//
+//	var productTable = database.Table[entity.Product]{
+//		Name:       "products",
+//		Entity:     "product",
+//		Columns:    []string{"id", "name", "COALESCE(description, '')", "created_at"},
+//		Scan:       func(p *entity.Product) []any { return []any{&p.ID, &p.Name, &p.Description, &p.CreatedAt} },
+//		Insert:     []string{"id", "name", "description", "created_at"},
+//		Values:     func(p *entity.Product) []any { return []any{p.ID, p.Name, p.Description, p.CreatedAt} },
+//		SoftDelete: true,
+//		NotFound:   sharedErrors.ErrProductNotFound,
+//	}
+type Table[T any] struct {
+	// Name is the table name.
+	Name string
+	// Entity names one row in error messages, such as "user".
+	Entity string
+	// Columns are the columns read for an entity, in Scan order. They may be
+	// expressions such as COALESCE(avatar_url, '').
+	Columns []string
+	// Scan returns pointers to the fields of e that Columns are read into.
+	Scan func(e *T) []any
+	// Insert are the columns Store.Insert writes, in Values order.
+	Insert []string
+	// Values returns the values of e for the Insert columns.
+	Values func(e *T) []any
+	// SoftDelete marks tables whose deleted rows keep a deleted_at
+	// timestamp. Filter then leaves those rows out and Store.Delete sets
+	// deleted_at instead of removing the row.
+	SoftDelete bool
+	// NotFound is returned when no row matches.
+	NotFound error
+	// MapError, when set, translates errors from writes into domain errors,
+	// such as a unique violation into a conflict. It returns nil for errors
+	// it does not recognize.
+	MapError func(err error) error
+}
+
+// Store runs the common queries for a Table: lookups, existence checks,
+// counts, listing, inserts and deletes. Every call is bounded by the query
+// timeout, joins the transaction carried by ctx, and reports cancellation
+// and timeouts through sharedErrors.ContextError. Repositories embed or hold
+// a Store and write only their domain-specific queries by hand, using Exec
+// and QueryRow for those so they get the same treatment.
+type Store[T any] struct {
+	pool         *pgxpool.Pool
+	table        Table[T]
+	queryTimeout time.Duration
+	selectSQL    string
+	insertSQL    string
+}
+
+// NewStore returns a Store for table whose calls each run under
+// queryTimeout; zero leaves deadlines to the caller.
+func NewStore[T any](pool *pgxpool.Pool, queryTimeout time.Duration, table Table[T]) *Store[T] {
+	placeholders := make([]string, len(table.Insert))
+	for i := range placeholders {
+		placeholders[i] = "$" + strconv.Itoa(i+1)
+	}
+
+	return &Store[T]{
+		pool:         pool,
+		table:        table,
+		queryTimeout: queryTimeout,
+		selectSQL:    "SELECT " + strings.Join(table.Columns, ", ") + " FROM " + table.Name,
+		insertSQL: "INSERT INTO " + table.Name + " (" + strings.Join(table.Insert, ", ") + ")" +
+			" VALUES (" + strings.Join(placeholders, ", ") + ")",
+	}
+}
+
+// Conn returns the transaction carried by ctx, or the pool outside one.
+func (s *Store[T]) Conn(ctx context.Context) Querier {
+	return Conn(ctx, s.pool)
+}
+
+// Filter returns an empty filter that, on soft-delete tables, already
+// excludes deleted rows. Use NewFilter to include them.
+func (s *Store[T]) Filter() *Filter {
+	filter := NewFilter()
+	if s.table.SoftDelete {
+		filter.Where("deleted_at IS NULL")
+	}
+	return filter
+}
+
+// SelectSQL returns "SELECT <columns> FROM <table>", for hand-written queries
+// whose rows are read with Scan.
+func (s *Store[T]) SelectSQL() string {
+	return s.selectSQL
+}
+
+// InsertSQL returns the statement Insert runs, taking the Values of an
+// entity, for example to queue inserts in a pgx.Batch.
+func (s *Store[T]) InsertSQL() string {
+	return s.insertSQL
+}
+
+// GetByID returns the entity with id, or the table's NotFound error.
+func (s *Store[T]) GetByID(ctx context.Context, id string) (*T, error) {
+	return s.GetBy(ctx, "id", id)
+}
+
+// GetBy returns the entity whose column equals value, or the table's
+// NotFound error. column is interpolated and must not come from user input.
+func (s *Store[T]) GetBy(ctx context.Context, column string, value any) (*T, error) {
+	return s.Get(ctx, s.Filter().Where(column+" = ?", value))
+}
+
+// Get returns the first entity matching filter, or the table's NotFound
+// error.
+func (s *Store[T]) Get(ctx context.Context, filter *Filter) (*T, error) {
+	ctx, cancel := WithQueryTimeout(ctx, s.queryTimeout)
+	defer cancel()
+
+	query := s.selectSQL + filter.SQL() + " LIMIT 1"
+
+	entity := new(T)
+	if err := s.Conn(ctx).QueryRow(ctx, query, filter.Args()...).Scan(s.table.Scan(entity)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, s.table.NotFound
+		}
+		if ctxErr := sharedErrors.ContextError(err); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, fmt.Errorf("failed to get %s: %w", s.table.Entity, err)
+	}
+
+	return entity, nil
+}
+
+// List returns the entities matching filter. suffix follows the WHERE
+// clause, for ORDER BY and LIMIT; bind its values with filter.Arg.
+func (s *Store[T]) List(ctx context.Context, filter *Filter, suffix string) ([]*T, error) {
+	ctx, cancel := WithQueryTimeout(ctx, s.queryTimeout)
+	defer cancel()
+
+	rows, err := s.Conn(ctx).Query(ctx, s.selectSQL+filter.SQL()+suffix, filter.Args()...)
+	if err != nil {
+		if ctxErr := sharedErrors.ContextError(err); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, fmt.Errorf("failed to list %ss: %w", s.table.Entity, err)
+	}
+	defer rows.Close()
+
+	entities := make([]*T, 0)
+	for rows.Next() {
+		// Stop scanning a large page as soon as the caller goes away.
+		if err := ctx.Err(); err != nil {
+			return nil, sharedErrors.ContextError(err)
+		}
+
+		entity := new(T)
+		if err := rows.Scan(s.table.Scan(entity)...); err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", s.table.Entity, err)
+		}
+		entities = append(entities, entity)
+	}
+
+	if err := rows.Err(); err != nil {
+		if ctxErr := sharedErrors.ContextError(err); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, fmt.Errorf("failed to iterate %ss: %w", s.table.Entity, err)
+	}
+
+	return entities, nil
+}
+
+// Each calls fn for every entity matching filter, in the order set by
+// suffix, reading them through a server-side cursor batchSize rows at a time
+// so result sets of any size are streamed rather than loaded into memory. It
+// stops at the first error fn returns and returns that error as is.
+//
+// The cursor lives in a transaction of its own, or a savepoint of the one
+// carried by ctx, that is rolled back however Each ends, so the connection
+// goes back to the pool even when ctx is cancelled mid-stream. Each fetch is
+// bounded by the query timeout; the iteration as a whole is not.
+func (s *Store[T]) Each(ctx context.Context, filter *Filter, suffix string, batchSize int, fn func(e *T) error) (err error) {
+	tx, err := s.Conn(ctx).Begin(ctx)
+	if err != nil {
+		if ctxErr := sharedErrors.ContextError(err); ctxErr != nil {
+			return ctxErr
+		}
+		return fmt.Errorf("failed to begin %s cursor: %w", s.table.Entity, err)
+	}
+	defer func() {
+		// Ending the transaction closes the cursor. ctx may be cancelled by
+		// now, so the rollback must not depend on it.
+		if rbErr := tx.Rollback(context.WithoutCancel(ctx)); rbErr != nil && err == nil && !errors.Is(rbErr, pgx.ErrTxClosed) {
+			err = fmt.Errorf("failed to close %s cursor: %w", s.table.Entity, rbErr)
+		}
+	}()
+
+	cursor := s.table.Name + "_cursor"
+	declare := "DECLARE " + cursor + " NO SCROLL CURSOR FOR " + s.selectSQL + filter.SQL() + suffix
+	if err := s.cursorExec(ctx, tx, declare, filter.Args()...); err != nil {
+		return err
+	}
+
+	fetch := "FETCH " + strconv.Itoa(batchSize) + " FROM " + cursor
+	for {
+		batch, err := s.fetch(ctx, tx, fetch)
+		if err != nil {
+			return err
+		}
+
+		for _, entity := range batch {
+			if err := fn(entity); err != nil {
+				return err
+			}
+		}
+
+		if len(batch) < batchSize {
+			return nil
+		}
+	}
+}
+
+func (s *Store[T]) cursorExec(ctx context.Context, tx pgx.Tx, query string, args ...any) error {
+	ctx, cancel := WithQueryTimeout(ctx, s.queryTimeout)
+	defer cancel()
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		if ctxErr := sharedErrors.ContextError(err); ctxErr != nil {
+			return ctxErr
+		}
+		return fmt.Errorf("failed to open %s cursor: %w", s.table.Entity, err)
+	}
+	return nil
+}
+
+// fetch reads the next batch from the cursor. The rows are scanned in full
+// before fn sees any of them, so a slow consumer does not count against the
+// query timeout.
+func (s *Store[T]) fetch(ctx context.Context, tx pgx.Tx, query string) ([]*T, error) {
+	ctx, cancel := WithQueryTimeout(ctx, s.queryTimeout)
+	defer cancel()
+
+	rows, err := tx.Query(ctx, query)
+	if err != nil {
+		if ctxErr := sharedErrors.ContextError(err); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, fmt.Errorf("failed to fetch %ss: %w", s.table.Entity, err)
+	}
+	defer rows.Close()
+
+	batch := make([]*T, 0)
+	for rows.Next() {
+		entity := new(T)
+		if err := rows.Scan(s.table.Scan(entity)...); err != nil {
+			return nil, fmt.Errorf("failed to scan %s: %w", s.table.Entity, err)
+		}
+		batch = append(batch, entity)
+	}
+
+	if err := rows.Err(); err != nil {
+		if ctxErr := sharedErrors.ContextError(err); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, fmt.Errorf("failed to fetch %ss: %w", s.table.Entity, err)
+	}
+
+	return batch, nil
+}
+
+// Count returns the number of rows matching filter.
+func (s *Store[T]) Count(ctx context.Context, filter *Filter) (int64, error) {
+	var count int64
+	query := "SELECT COUNT(*) FROM " + s.table.Name + filter.SQL()
+	if err := s.QueryRow(ctx, "count "+s.table.Entity+"s", query, filter.Args(), &count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// ExistsBy reports whether a row's column equals value. column is
+// interpolated and must not come from user input.
+func (s *Store[T]) ExistsBy(ctx context.Context, column string, value any) (bool, error) {
+	return s.Exists(ctx, s.Filter().Where(column+" = ?", value))
+}
+
+// Exists reports whether any row matches filter.
+func (s *Store[T]) Exists(ctx context.Context, filter *Filter) (bool, error) {
+	var exists bool
+	query := "SELECT EXISTS(SELECT 1 FROM " + s.table.Name + filter.SQL() + ")"
+	if err := s.QueryRow(ctx, "check "+s.table.Entity+" existence", query, filter.Args(), &exists); err != nil {
+		return false, err
+	}
+	return exists, nil
+}
+
+// Insert writes e's Insert columns as a new row.
+func (s *Store[T]) Insert(ctx context.Context, e *T) error {
+	_, err := s.Exec(ctx, "create "+s.table.Entity, s.insertSQL, s.table.Values(e)...)
+	return err
+}
+
+// Delete removes the row with id, or on soft-delete tables sets its
+// deleted_at. It returns the table's NotFound error if there is no such
+// row, or it was already deleted.
+func (s *Store[T]) Delete(ctx context.Context, id string) error {
+	query := "DELETE FROM " + s.table.Name + " WHERE id = $1"
+	if s.table.SoftDelete {
+		query = "UPDATE " + s.table.Name + " SET deleted_at = NOW() WHERE id = $1 AND deleted_at IS NULL"
+	}
+	return s.ExecOne(ctx, "delete "+s.table.Entity, query, id)
+}
+
+// Exec runs a statement that returns no rows. Errors are passed through
+// MapError and ContextError, and otherwise wrapped as "failed to <action>".
+func (s *Store[T]) Exec(ctx context.Context, action, query string, args ...any) (pgconn.CommandTag, error) {
+	ctx, cancel := WithQueryTimeout(ctx, s.queryTimeout)
+	defer cancel()
+
+	result, err := s.Conn(ctx).Exec(ctx, query, args...)
+	if err != nil {
+		return result, s.writeError(action, err)
+	}
+	return result, nil
+}
+
+// ExecOne is Exec for statements that must affect a row, returning the
+// table's NotFound error when none matched.
+func (s *Store[T]) ExecOne(ctx context.Context, action, query string, args ...any) error {
+	result, err := s.Exec(ctx, action, query, args...)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return s.table.NotFound
+	}
+	return nil
+}
+
+// QueryRow runs a query returning a single row and scans it into dest.
+// Errors are passed through ContextError and otherwise wrapped as "failed to
+// <action>"; pgx.ErrNoRows is returned wrapped for the caller to handle.
+func (s *Store[T]) QueryRow(ctx context.Context, action, query string, args []any, dest ...any) error {
+	ctx, cancel := WithQueryTimeout(ctx, s.queryTimeout)
+	defer cancel()
+
+	if err := s.Conn(ctx).QueryRow(ctx, query, args...).Scan(dest...); err != nil {
+		if ctxErr := sharedErrors.ContextError(err); ctxErr != nil {
+			return ctxErr
+		}
+		return fmt.Errorf("failed to %s: %w", action, err)
+	}
+	return nil
+}
+
+func (s *Store[T]) writeError(action string, err error) error {
+	if s.table.MapError != nil {
+		if mapped := s.table.MapError(err); mapped != nil {
+			return mapped
+		}
+	}
+	if ctxErr := sharedErrors.ContextError(err); ctxErr != nil {
+		return ctxErr
+	}
+	return fmt.Errorf("failed to %s: %w", action, err)
+}
@@ -0,0 +1,77 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// checkTimeout bounds each dependency check so one hung dependency cannot
+// stall the readiness probe.
+const checkTimeout = 3 * time.Second
+
+// Check reports whether a dependency is usable, returning nil if it is.
+type Check func(ctx context.Context) error
+
+// Readiness reports whether the application can serve traffic. It starts not
+// ready; main marks it ready once the database and Redis are connected, and
+// not ready again when shutdown begins so load balancers stop routing to it.
+// While ready, the dependency checks added with AddCheck must also pass.
+type Readiness struct {
+	ready atomic.Bool
+
+	mu     sync.RWMutex
+	names  []string
+	checks map[string]Check
+}
+
+func NewReadiness() *Readiness {
+	return &Readiness{checks: make(map[string]Check)}
+}
+
+func (r *Readiness) IsReady() bool {
+	return r.ready.Load()
+}
+
+func (r *Readiness) SetReady(ready bool) {
+	r.ready.Store(ready)
+}
+
+// AddCheck registers check under name, replacing any check already
+// registered with that name.
+func (r *Readiness) AddCheck(name string, check Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.checks[name]; !exists {
+		r.names = append(r.names, name)
+	}
+	r.checks[name] = check
+}
+
+// Failures runs every registered check and returns the error message of each
+// one that failed, keyed by check name. It returns nil when all pass.
+func (r *Readiness) Failures(ctx context.Context) map[string]string {
+	r.mu.RLock()
+	names := append([]string(nil), r.names...)
+	checks := make([]Check, len(names))
+	for i, name := range names {
+		checks[i] = r.checks[name]
+	}
+	r.mu.RUnlock()
+
+	var failures map[string]string
+	for i, check := range checks {
+		checkCtx, cancel := context.WithTimeout(ctx, checkTimeout)
+		err := check(checkCtx)
+		cancel()
+		if err != nil {
+			if failures == nil {
+				failures = make(map[string]string)
+			}
+			failures[names[i]] = err.Error()
+		}
+	}
+	return failures
+}
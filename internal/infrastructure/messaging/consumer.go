@@ -0,0 +1,297 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/TubagusAldiMY/go-template/pkg/logger"
+	"github.com/google/uuid"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+)
+
+const (
+	// HeaderRetryCount carries the number of times a message has been retried.
+	HeaderRetryCount = "x-retry-count"
+
+	deadLetterExchangeSuffix = ".dlx"
+	deadLetterQueueSuffix    = ".dlq"
+)
+
+// ErrPermanent marks a handler error that must not be retried.
+var ErrPermanent = errors.New("permanent failure")
+
+// Permanent wraps err so the consumer dead-letters the message immediately.
+func Permanent(err error) error {
+	return fmt.Errorf("%w: %v", ErrPermanent, err)
+}
+
+// Handler processes a single delivery.
+type Handler func(ctx context.Context, delivery amqp.Delivery) error
+
+// Channel is the part of *amqp.Channel the consumer and the dead-letter
+// declaration use, so tests can stand in for the broker.
+type Channel interface {
+	Qos(prefetchCount, prefetchSize int, global bool) error
+	Consume(queue, consumer string, autoAck, exclusive, noLocal, noWait bool, args amqp.Table) (<-chan amqp.Delivery, error)
+	Cancel(consumer string, noWait bool) error
+	PublishWithContext(ctx context.Context, exchange, key string, mandatory, immediate bool, msg amqp.Publishing) error
+	ExchangeDeclare(name, kind string, durable, autoDelete, internal, noWait bool, args amqp.Table) error
+	QueueDeclare(name string, durable, autoDelete, exclusive, noWait bool, args amqp.Table) (amqp.Queue, error)
+	QueueBind(name, key, exchange string, noWait bool, args amqp.Table) error
+	Close() error
+}
+
+var _ Channel = (*amqp.Channel)(nil)
+
+type ConsumerConfig struct {
+	Queue       string
+	ConsumerTag string
+	Workers     int
+	MaxRetries  int
+	Prefetch    int
+}
+
+// Consumer dispatches deliveries from a queue to a Handler.
+//
+// A nil error acks the message. A transient error requeues the message with
+// an incremented retry header until MaxRetries is reached, after which the
+// message is rejected without requeue so the broker routes it to the queue's
+// dead-letter exchange. Errors wrapped with Permanent skip the retries.
+type Consumer struct {
+	openChannel func() (Channel, error)
+	cfg         ConsumerConfig
+	handler     Handler
+}
+
+func NewConsumer(rabbitmq *RabbitMQ, cfg ConsumerConfig, handler Handler) *Consumer {
+	// A dedicated channel keeps the prefetch setting and consumer lifecycle
+	// isolated from the shared publishing channel.
+	return NewChannelConsumer(func() (Channel, error) {
+		channel, err := rabbitmq.conn.Channel()
+		if err != nil {
+			return nil, err
+		}
+		return channel, nil
+	}, cfg, handler)
+}
+
+// NewChannelConsumer builds a Consumer that calls openChannel once per Run
+// for the channel it consumes and republishes on. Run closes that channel.
+func NewChannelConsumer(openChannel func() (Channel, error), cfg ConsumerConfig, handler Handler) *Consumer {
+	if cfg.Workers < 1 {
+		cfg.Workers = 1
+	}
+	if cfg.Prefetch < cfg.Workers {
+		cfg.Prefetch = cfg.Workers
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	}
+	if cfg.ConsumerTag == "" {
+		cfg.ConsumerTag = fmt.Sprintf("%s-%s", cfg.Queue, uuid.New().String())
+	}
+
+	return &Consumer{
+		openChannel: openChannel,
+		cfg:         cfg,
+		handler:     handler,
+	}
+}
+
+// Run consumes messages until ctx is cancelled or the channel is closed by the
+// broker. It waits for in-flight handlers to finish before returning.
+func (c *Consumer) Run(ctx context.Context) error {
+	channel, err := c.openChannel()
+	if err != nil {
+		return fmt.Errorf("failed to open consumer channel: %w", err)
+	}
+	defer channel.Close()
+
+	if err := channel.Qos(c.cfg.Prefetch, 0, false); err != nil {
+		return fmt.Errorf("failed to set qos: %w", err)
+	}
+
+	deliveries, err := channel.Consume(
+		c.cfg.Queue,
+		c.cfg.ConsumerTag,
+		false, // auto-ack
+		false, // exclusive
+		false, // no-local
+		false, // no-wait
+		nil,   // args
+	)
+	if err != nil {
+		return fmt.Errorf("failed to start consuming %s: %w", c.cfg.Queue, err)
+	}
+
+	logger.Info("consumer started",
+		zap.String("queue", c.cfg.Queue),
+		zap.Int("workers", c.cfg.Workers),
+		zap.Int("max_retries", c.cfg.MaxRetries),
+	)
+
+	var wg sync.WaitGroup
+	for i := 0; i < c.cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.work(ctx, channel, deliveries)
+		}()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-ctx.Done():
+		// Stop the broker from sending more deliveries; workers drain what is
+		// already buffered and exit once the deliveries channel closes.
+		if err := channel.Cancel(c.cfg.ConsumerTag, false); err != nil {
+			logger.Warn("failed to cancel consumer", zap.String("queue", c.cfg.Queue), zap.Error(err))
+		}
+		<-done
+	case <-done:
+		return fmt.Errorf("delivery channel for %s closed unexpectedly", c.cfg.Queue)
+	}
+
+	logger.Info("consumer stopped", zap.String("queue", c.cfg.Queue))
+
+	return nil
+}
+
+func (c *Consumer) work(ctx context.Context, channel Channel, deliveries <-chan amqp.Delivery) {
+	for delivery := range deliveries {
+		if ctx.Err() != nil {
+			// Shutting down: hand the message back to the broker untouched.
+			_ = delivery.Nack(false, true)
+			continue
+		}
+		c.handle(ctx, channel, delivery)
+	}
+}
+
+func (c *Consumer) handle(ctx context.Context, channel Channel, delivery amqp.Delivery) {
+	err := c.handler(ctx, delivery)
+	if err == nil {
+		if ackErr := delivery.Ack(false); ackErr != nil {
+			logger.Error("failed to ack message", zap.String("queue", c.cfg.Queue), zap.Error(ackErr))
+		}
+		return
+	}
+
+	retries := retryCount(delivery)
+	if errors.Is(err, ErrPermanent) || retries >= c.cfg.MaxRetries {
+		logger.Error("message dead-lettered",
+			zap.String("queue", c.cfg.Queue),
+			zap.String("message_id", delivery.MessageId),
+			zap.Int("retries", retries),
+			zap.Error(err),
+		)
+		if nackErr := delivery.Nack(false, false); nackErr != nil {
+			logger.Error("failed to nack message", zap.String("queue", c.cfg.Queue), zap.Error(nackErr))
+		}
+		return
+	}
+
+	logger.Warn("message processing failed, requeueing",
+		zap.String("queue", c.cfg.Queue),
+		zap.String("message_id", delivery.MessageId),
+		zap.Int("retry", retries+1),
+		zap.Error(err),
+	)
+
+	// A plain nack-with-requeue cannot change the message headers, so the
+	// retry is republished with an incremented counter and the original acked.
+	if pubErr := c.requeue(ctx, channel, delivery, retries+1); pubErr != nil {
+		logger.Error("failed to republish message, falling back to nack", zap.Error(pubErr))
+		_ = delivery.Nack(false, true)
+		return
+	}
+	if ackErr := delivery.Ack(false); ackErr != nil {
+		logger.Error("failed to ack requeued message", zap.String("queue", c.cfg.Queue), zap.Error(ackErr))
+	}
+}
+
+func (c *Consumer) requeue(ctx context.Context, channel Channel, delivery amqp.Delivery, retry int) error {
+	headers := amqp.Table{}
+	for k, v := range delivery.Headers {
+		headers[k] = v
+	}
+	headers[HeaderRetryCount] = int32(retry)
+
+	return channel.PublishWithContext(
+		context.WithoutCancel(ctx),
+		"", // default exchange routes by queue name
+		c.cfg.Queue,
+		false, // mandatory
+		false, // immediate
+		amqp.Publishing{
+			Headers:       headers,
+			ContentType:   delivery.ContentType,
+			CorrelationId: delivery.CorrelationId,
+			MessageId:     delivery.MessageId,
+			Type:          delivery.Type,
+			Body:          delivery.Body,
+			DeliveryMode:  amqp.Persistent,
+			Timestamp:     delivery.Timestamp,
+		},
+	)
+}
+
+func retryCount(delivery amqp.Delivery) int {
+	switch v := delivery.Headers[HeaderRetryCount].(type) {
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case int:
+		return v
+	default:
+		return 0
+	}
+}
+
+// DeclareQueueWithDeadLetter declares a durable queue whose rejected messages
+// are routed to a dead-letter exchange "<name>.dlx" and stored in "<name>.dlq".
+func (r *RabbitMQ) DeclareQueueWithDeadLetter(name string) error {
+	return DeclareQueueWithDeadLetter(r.channel, name)
+}
+
+// DeclareQueueWithDeadLetter is RabbitMQ.DeclareQueueWithDeadLetter on a
+// channel of the caller's choosing.
+func DeclareQueueWithDeadLetter(channel Channel, name string) error {
+	dlx := name + deadLetterExchangeSuffix
+	dlq := name + deadLetterQueueSuffix
+
+	if err := channel.ExchangeDeclare(dlx, amqp.ExchangeFanout, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare dead-letter exchange: %w", err)
+	}
+
+	if _, err := channel.QueueDeclare(dlq, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("failed to declare dead-letter queue: %w", err)
+	}
+
+	if err := channel.QueueBind(dlq, "", dlx, false, nil); err != nil {
+		return fmt.Errorf("failed to bind dead-letter queue: %w", err)
+	}
+
+	_, err := channel.QueueDeclare(
+		name,
+		true,  // durable
+		false, // auto-delete
+		false, // exclusive
+		false, // no-wait
+		amqp.Table{"x-dead-letter-exchange": dlx},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to declare queue %s: %w", name, err)
+	}
+
+	return nil
+}
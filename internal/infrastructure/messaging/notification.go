@@ -0,0 +1,21 @@
+package messaging
+
+import (
+	"context"
+
+	"github.com/TubagusAldiMY/go-template/pkg/notify"
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// NotificationHandler returns a Handler that delivers the notifications
+// queued by notify.Queue through sender. Messages that cannot be decoded are
+// dead-lettered right away; failed deliveries are retried.
+func NotificationHandler(sender notify.Notifier) Handler {
+	return func(ctx context.Context, delivery amqp.Delivery) error {
+		n, err := notify.Decode(delivery.Body)
+		if err != nil {
+			return Permanent(err)
+		}
+		return sender.Send(ctx, n)
+	}
+}
@@ -0,0 +1,30 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// EventPublisher publishes JSON-encoded events to a single exchange.
+type EventPublisher struct {
+	rabbitmq *RabbitMQ
+	exchange string
+}
+
+func NewEventPublisher(rabbitmq *RabbitMQ, exchange string) *EventPublisher {
+	return &EventPublisher{rabbitmq: rabbitmq, exchange: exchange}
+}
+
+func (p *EventPublisher) Publish(ctx context.Context, routingKey string, event interface{}) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	if err := p.rabbitmq.Publish(ctx, p.exchange, routingKey, body); err != nil {
+		return fmt.Errorf("failed to publish %s event: %w", routingKey, err)
+	}
+
+	return nil
+}
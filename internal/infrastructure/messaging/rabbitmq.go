@@ -7,10 +7,15 @@ import (
 
 	"github.com/TubagusAldiMY/go-template/internal/infrastructure/config"
 	"github.com/TubagusAldiMY/go-template/pkg/logger"
+	"github.com/TubagusAldiMY/go-template/pkg/retry"
 	amqp "github.com/rabbitmq/amqp091-go"
 	"go.uber.org/zap"
 )
 
+// connectBackoff is the wait before the first connection retry; it doubles
+// with every further attempt.
+const connectBackoff = time.Second
+
 type RabbitMQ struct {
 	conn    *amqp.Connection
 	channel *amqp.Channel
@@ -26,7 +31,13 @@ func NewRabbitMQ(cfg config.RabbitMQConfig) (*RabbitMQ, error) {
 		cfg.VHost,
 	)
 
-	conn, err := amqp.Dial(url)
+	var conn *amqp.Connection
+	retryCtx := logger.WithContext(context.Background(), zap.String("dependency", "rabbitmq"))
+	err := retry.Do(retryCtx, cfg.ConnectAttempts, connectBackoff, func() error {
+		var err error
+		conn, err = amqp.Dial(url)
+		return err
+	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
 	}
@@ -0,0 +1,261 @@
+package messaging
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/cache"
+	"github.com/TubagusAldiMY/go-template/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+// Fields of a stream entry.
+const (
+	StreamFieldRoutingKey = "routing_key"
+	StreamFieldBody       = "body"
+	StreamFieldRetries    = "retries"
+	StreamFieldError      = "error"
+)
+
+// StreamPublisher publishes JSON-encoded events to a Redis stream, an
+// alternative to EventPublisher whose entries are durable and can be replayed
+// with cache.Redis.XRead.
+type StreamPublisher struct {
+	redis  *cache.Redis
+	stream string
+	maxLen int64
+}
+
+// NewStreamPublisher returns a publisher appending to stream. A positive
+// maxLen caps the stream at roughly that many entries.
+func NewStreamPublisher(redis *cache.Redis, stream string, maxLen int64) *StreamPublisher {
+	return &StreamPublisher{redis: redis, stream: stream, maxLen: maxLen}
+}
+
+func (p *StreamPublisher) Publish(ctx context.Context, routingKey string, event interface{}) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	_, err = p.redis.XAdd(ctx, p.stream, p.maxLen, map[string]interface{}{
+		StreamFieldRoutingKey: routingKey,
+		StreamFieldBody:       body,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to publish %s event: %w", routingKey, err)
+	}
+
+	return nil
+}
+
+// StreamMessage is an entry read from a stream.
+type StreamMessage struct {
+	ID         string
+	RoutingKey string
+	Body       []byte
+	// Retries is how many times the message has been retried.
+	Retries int
+}
+
+// StreamHandler processes a single stream message.
+type StreamHandler func(ctx context.Context, msg StreamMessage) error
+
+type StreamConsumerConfig struct {
+	Stream string
+	// Group is the consumer group; replicas using the same group share the
+	// stream, each message going to one of them.
+	Group string
+	// Consumer names this replica within the group.
+	Consumer   string
+	BatchSize  int64
+	MaxRetries int
+	// Block is how long a read waits for new messages.
+	Block time.Duration
+	// ClaimIdle is how long a message may stay unacknowledged before another
+	// consumer of the group takes it over, e.g. after a crash.
+	ClaimIdle time.Duration
+}
+
+// StreamConsumer dispatches messages from a Redis stream consumer group to a
+// StreamHandler, with the same retry semantics as Consumer.
+//
+// A nil error acks the message. A transient error appends the message again
+// with an incremented retry count until MaxRetries is reached, after which it
+// is moved to the "<stream>.dlq" stream. Errors wrapped with Permanent skip
+// the retries. Messages left pending by a consumer that stopped without
+// acking them are claimed once they have been idle for ClaimIdle.
+type StreamConsumer struct {
+	redis   *cache.Redis
+	cfg     StreamConsumerConfig
+	handler StreamHandler
+}
+
+func NewStreamConsumer(redis *cache.Redis, cfg StreamConsumerConfig, handler StreamHandler) *StreamConsumer {
+	if cfg.BatchSize < 1 {
+		cfg.BatchSize = 10
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	}
+	if cfg.Block <= 0 {
+		cfg.Block = 5 * time.Second
+	}
+	if cfg.ClaimIdle <= 0 {
+		cfg.ClaimIdle = time.Minute
+	}
+	if cfg.Consumer == "" {
+		cfg.Consumer = fmt.Sprintf("%s-%s", cfg.Group, uuid.New().String())
+	}
+
+	return &StreamConsumer{
+		redis:   redis,
+		cfg:     cfg,
+		handler: handler,
+	}
+}
+
+// Run consumes messages until ctx is cancelled. The message being handled
+// when ctx is cancelled is finished first.
+func (c *StreamConsumer) Run(ctx context.Context) error {
+	if err := c.redis.EnsureConsumerGroup(ctx, c.cfg.Stream, c.cfg.Group); err != nil {
+		return fmt.Errorf("failed to create consumer group %s: %w", c.cfg.Group, err)
+	}
+
+	logger.Info("stream consumer started",
+		zap.String("stream", c.cfg.Stream),
+		zap.String("group", c.cfg.Group),
+		zap.String("consumer", c.cfg.Consumer),
+		zap.Int("max_retries", c.cfg.MaxRetries),
+	)
+
+	for ctx.Err() == nil {
+		if err := c.poll(ctx); err != nil && ctx.Err() == nil {
+			logger.Warn("failed to read stream",
+				zap.String("stream", c.cfg.Stream),
+				zap.Error(err),
+			)
+			select {
+			case <-ctx.Done():
+			case <-time.After(time.Second):
+			}
+		}
+	}
+
+	logger.Info("stream consumer stopped", zap.String("stream", c.cfg.Stream))
+
+	return nil
+}
+
+// poll handles the stale messages claimed from other consumers, then the new
+// ones.
+func (c *StreamConsumer) poll(ctx context.Context) error {
+	claimed, err := c.redis.XAutoClaim(ctx, c.cfg.Stream, c.cfg.Group, c.cfg.Consumer, c.cfg.ClaimIdle, c.cfg.BatchSize)
+	if err != nil {
+		return err
+	}
+	c.handleAll(ctx, claimed)
+
+	messages, err := c.redis.XReadGroup(ctx, c.cfg.Stream, c.cfg.Group, c.cfg.Consumer, c.cfg.BatchSize, c.cfg.Block)
+	if err != nil {
+		return err
+	}
+	c.handleAll(ctx, messages)
+
+	return nil
+}
+
+func (c *StreamConsumer) handleAll(ctx context.Context, messages []redis.XMessage) {
+	for _, message := range messages {
+		if ctx.Err() != nil {
+			// Shutting down: the rest stay pending and are claimed later.
+			return
+		}
+		c.handle(ctx, message)
+	}
+}
+
+func (c *StreamConsumer) handle(ctx context.Context, message redis.XMessage) {
+	msg := toStreamMessage(message)
+
+	err := c.handler(ctx, msg)
+	if err == nil {
+		c.ack(ctx, msg)
+		return
+	}
+
+	// The outcome must be recorded even if the handler ran into shutdown.
+	ctx = context.WithoutCancel(ctx)
+
+	if errors.Is(err, ErrPermanent) || msg.Retries >= c.cfg.MaxRetries {
+		logger.Error("message dead-lettered",
+			zap.String("stream", c.cfg.Stream),
+			zap.String("message_id", msg.ID),
+			zap.Int("retries", msg.Retries),
+			zap.Error(err),
+		)
+		if addErr := c.append(ctx, c.cfg.Stream+deadLetterQueueSuffix, msg, msg.Retries, err); addErr != nil {
+			logger.Error("failed to dead-letter message, leaving it pending", zap.Error(addErr))
+			return
+		}
+		c.ack(ctx, msg)
+		return
+	}
+
+	logger.Warn("message processing failed, requeueing",
+		zap.String("stream", c.cfg.Stream),
+		zap.String("message_id", msg.ID),
+		zap.Int("retry", msg.Retries+1),
+		zap.Error(err),
+	)
+
+	// Entries are immutable, so the retry is appended as a new entry with an
+	// incremented counter and the original acked.
+	if addErr := c.append(ctx, c.cfg.Stream, msg, msg.Retries+1, nil); addErr != nil {
+		logger.Error("failed to requeue message, leaving it pending", zap.Error(addErr))
+		return
+	}
+	c.ack(ctx, msg)
+}
+
+func (c *StreamConsumer) append(ctx context.Context, stream string, msg StreamMessage, retries int, cause error) error {
+	values := map[string]interface{}{
+		StreamFieldRoutingKey: msg.RoutingKey,
+		StreamFieldBody:       msg.Body,
+		StreamFieldRetries:    retries,
+	}
+	if cause != nil {
+		values[StreamFieldError] = cause.Error()
+	}
+
+	_, err := c.redis.XAdd(ctx, stream, 0, values)
+	return err
+}
+
+func (c *StreamConsumer) ack(ctx context.Context, msg StreamMessage) {
+	if err := c.redis.XAck(ctx, c.cfg.Stream, c.cfg.Group, msg.ID); err != nil {
+		logger.Error("failed to ack message", zap.String("stream", c.cfg.Stream), zap.Error(err))
+	}
+}
+
+func toStreamMessage(message redis.XMessage) StreamMessage {
+	msg := StreamMessage{ID: message.ID}
+	if v, ok := message.Values[StreamFieldRoutingKey].(string); ok {
+		msg.RoutingKey = v
+	}
+	if v, ok := message.Values[StreamFieldBody].(string); ok {
+		msg.Body = []byte(v)
+	}
+	if v, ok := message.Values[StreamFieldRetries].(string); ok {
+		if retries, err := strconv.Atoi(v); err == nil {
+			msg.Retries = retries
+		}
+	}
+	return msg
+}
@@ -0,0 +1,94 @@
+package messaging
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/TubagusAldiMY/go-template/pkg/logger"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.uber.org/zap"
+)
+
+// Subscription receives messages from a transient, exclusive queue bound to an
+// exchange. The queue is deleted by the broker once the subscription closes.
+type Subscription struct {
+	channel    *amqp.Channel
+	deliveries <-chan amqp.Delivery
+}
+
+// Subscribe binds a new server-named queue to exchange for each of
+// routingKeys and starts consuming it with auto-ack, since a live subscriber
+// has no use for redelivery. The subscription is closed when ctx is done.
+func (r *RabbitMQ) Subscribe(ctx context.Context, exchange string, routingKeys ...string) (*Subscription, error) {
+	// Each subscription gets its own channel so closing it only tears down
+	// this subscriber's queue and consumer.
+	channel, err := r.conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open subscription channel: %w", err)
+	}
+
+	deliveries, err := subscribe(channel, exchange, routingKeys)
+	if err != nil {
+		channel.Close()
+		return nil, err
+	}
+
+	sub := &Subscription{channel: channel, deliveries: deliveries}
+
+	go func() {
+		<-ctx.Done()
+		if err := sub.Close(); err != nil {
+			logger.Warn("failed to close subscription", zap.String("exchange", exchange), zap.Error(err))
+		}
+	}()
+
+	return sub, nil
+}
+
+func subscribe(channel *amqp.Channel, exchange string, routingKeys []string) (<-chan amqp.Delivery, error) {
+	queue, err := channel.QueueDeclare(
+		"",    // server-generated name
+		false, // durable
+		true,  // auto-delete
+		true,  // exclusive
+		false, // no-wait
+		nil,   // arguments
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to declare subscription queue: %w", err)
+	}
+
+	for _, key := range routingKeys {
+		if err := channel.QueueBind(queue.Name, key, exchange, false, nil); err != nil {
+			return nil, fmt.Errorf("failed to bind subscription queue to %s: %w", exchange, err)
+		}
+	}
+
+	deliveries, err := channel.Consume(
+		queue.Name,
+		"",    // consumer tag
+		true,  // auto-ack
+		true,  // exclusive
+		false, // no-local
+		false, // no-wait
+		nil,   // args
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume subscription queue: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// Messages returns the delivery channel. It is closed when the subscription
+// or the underlying connection closes.
+func (s *Subscription) Messages() <-chan amqp.Delivery {
+	return s.deliveries
+}
+
+func (s *Subscription) Close() error {
+	if s.channel.IsClosed() {
+		return nil
+	}
+	return s.channel.Close()
+}
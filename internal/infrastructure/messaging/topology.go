@@ -0,0 +1,90 @@
+package messaging
+
+import (
+	"errors"
+	"fmt"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+)
+
+// QueueSpec names broker objects the application expects to exist. Either
+// field may be left empty to check only the other.
+type QueueSpec struct {
+	// Name is a queue that must exist.
+	Name string
+	// Exchange is an exchange that must exist, typically the one Name is
+	// bound to.
+	Exchange string
+}
+
+// VerifyTopology checks that every queue and exchange in expected exists,
+// returning an error naming each one that does not. It uses passive declares,
+// so nothing missing is created.
+//
+// The broker closes a channel whose passive declare fails, so the checks run
+// on a channel of their own, reopened after each failure, and never disturb
+// the channel used for publishing.
+func (r *RabbitMQ) VerifyTopology(expected []QueueSpec) error {
+	if err := r.Health(); err != nil {
+		return err
+	}
+
+	verifier := &topologyVerifier{conn: r.conn}
+	defer verifier.close()
+
+	var errs []error
+	for _, spec := range expected {
+		if spec.Exchange != "" {
+			err := verifier.declare(func(ch *amqp.Channel) error {
+				// The kind is not compared on a passive declare.
+				return ch.ExchangeDeclarePassive(spec.Exchange, amqp.ExchangeTopic, true, false, false, false, nil)
+			})
+			if err != nil {
+				errs = append(errs, fmt.Errorf("exchange %s: %w", spec.Exchange, err))
+			}
+		}
+		if spec.Name != "" {
+			err := verifier.declare(func(ch *amqp.Channel) error {
+				_, err := ch.QueueDeclarePassive(spec.Name, true, false, false, false, nil)
+				return err
+			})
+			if err != nil {
+				errs = append(errs, fmt.Errorf("queue %s: %w", spec.Name, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// topologyVerifier holds the channel VerifyTopology declares on, opening a
+// new one whenever the last was closed by a failed declare.
+type topologyVerifier struct {
+	conn    *amqp.Connection
+	channel *amqp.Channel
+}
+
+func (v *topologyVerifier) declare(fn func(ch *amqp.Channel) error) error {
+	if v.channel == nil {
+		channel, err := v.conn.Channel()
+		if err != nil {
+			return fmt.Errorf("failed to open channel: %w", err)
+		}
+		v.channel = channel
+	}
+
+	if err := fn(v.channel); err != nil {
+		v.close()
+		return err
+	}
+	return nil
+}
+
+func (v *topologyVerifier) close() {
+	if v.channel != nil {
+		// After a failed declare the broker has closed the channel already
+		// and Close only reports that.
+		_ = v.channel.Close()
+		v.channel = nil
+	}
+}
@@ -0,0 +1,23 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/pprof"
+)
+
+// PprofPath is where the profiling handlers are served. The pprof index links
+// to the profiles relative to it, so it cannot be moved.
+const PprofPath = "/debug/pprof/"
+
+// PprofHandler serves the net/http/pprof handlers under PprofPath. Unlike
+// importing net/http/pprof for its side effects, it leaves
+// http.DefaultServeMux alone.
+func PprofHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(PprofPath, pprof.Index)
+	mux.HandleFunc(PprofPath+"cmdline", pprof.Cmdline)
+	mux.HandleFunc(PprofPath+"profile", pprof.Profile)
+	mux.HandleFunc(PprofPath+"symbol", pprof.Symbol)
+	mux.HandleFunc(PprofPath+"trace", pprof.Trace)
+	return mux
+}
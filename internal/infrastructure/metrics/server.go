@@ -0,0 +1,28 @@
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/config"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// NewServer returns an HTTP server exposing gatherer on /metrics at the
+// configured metrics port, and the pprof handlers under /debug/pprof/ when
+// cfg.Pprof is set. Start it with ListenAndServe.
+func NewServer(cfg config.MetricsConfig, gatherer prometheus.Gatherer) *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{}))
+	if cfg.Pprof {
+		mux.Handle(PprofPath, PprofHandler())
+	}
+
+	return &http.Server{
+		Addr:              fmt.Sprintf(":%d", cfg.Port),
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+}
@@ -0,0 +1,208 @@
+// Package notification delivers real-time messages to users over their open
+// WebSocket connections.
+package notification
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/pkg/logger"
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+)
+
+const (
+	// writeWait bounds a single write, including pings.
+	writeWait = 10 * time.Second
+	// pongWait is how long a connection may stay silent before it is
+	// considered dead; pings are sent well within it.
+	pongWait   = 60 * time.Second
+	pingPeriod = pongWait * 9 / 10
+	// maxMessageSize caps client messages, which are read only to process
+	// control frames and otherwise ignored.
+	maxMessageSize = 512
+)
+
+// ErrClosed is returned when writing to a connection that has been closed.
+var ErrClosed = errors.New("connection closed")
+
+// Hub tracks the open connections of each user. A user may hold several, for
+// example one per browser tab, and every one receives the user's messages.
+type Hub struct {
+	mu    sync.RWMutex
+	conns map[string]map[*Conn]struct{}
+}
+
+func NewHub() *Hub {
+	return &Hub{conns: make(map[string]map[*Conn]struct{})}
+}
+
+func (h *Hub) Register(userID string, conn *Conn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.conns[userID] == nil {
+		h.conns[userID] = make(map[*Conn]struct{})
+	}
+	h.conns[userID][conn] = struct{}{}
+}
+
+func (h *Hub) Unregister(userID string, conn *Conn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.conns[userID], conn)
+	if len(h.conns[userID]) == 0 {
+		delete(h.conns, userID)
+	}
+}
+
+// SendToUser writes msg to every connection of userID and returns how many
+// received it. Connections that fail the write are closed; their Run then
+// returns and the handler unregisters them.
+func (h *Hub) SendToUser(userID string, msg []byte) int {
+	h.mu.RLock()
+	conns := make([]*Conn, 0, len(h.conns[userID]))
+	for conn := range h.conns[userID] {
+		conns = append(conns, conn)
+	}
+	h.mu.RUnlock()
+
+	sent := 0
+	for _, conn := range conns {
+		if err := conn.WriteMessage(msg); err != nil {
+			logger.Debug("failed to deliver notification", zap.String("user_id", userID), zap.Error(err))
+			conn.Close()
+			continue
+		}
+		sent++
+	}
+	return sent
+}
+
+// Connections returns the number of open connections for userID.
+func (h *Hub) Connections(userID string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.conns[userID])
+}
+
+// Close closes every connection. http.Server.Shutdown does not track
+// upgraded connections, so this must be called on shutdown.
+func (h *Hub) Close() {
+	h.mu.RLock()
+	var conns []*Conn
+	for _, userConns := range h.conns {
+		for conn := range userConns {
+			conns = append(conns, conn)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, conn := range conns {
+		conn.closeWith(websocket.CloseGoingAway)
+	}
+}
+
+// Conn is a WebSocket connection that is safe for concurrent writers: the
+// hub, delivering notifications, and Run, sending pings.
+type Conn struct {
+	ws        *websocket.Conn
+	writeMu   sync.Mutex
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func NewConn(ws *websocket.Conn) *Conn {
+	return &Conn{ws: ws, closed: make(chan struct{})}
+}
+
+// WriteMessage sends data as a text message.
+func (c *Conn) WriteMessage(data []byte) error {
+	return c.write(websocket.TextMessage, data)
+}
+
+// Run serves the connection until the client disconnects, a ping fails, ctx
+// is done or the connection is closed, and closes it before returning. The
+// client must answer pings within pongWait.
+func (c *Conn) Run(ctx context.Context) error {
+	defer c.Close()
+
+	c.ws.SetReadLimit(maxMessageSize)
+	if err := c.ws.SetReadDeadline(time.Now().Add(pongWait)); err != nil {
+		return err
+	}
+	c.ws.SetPongHandler(func(string) error {
+		return c.ws.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	// Reading is needed to process pong and close frames.
+	readErr := make(chan error, 1)
+	go func() {
+		for {
+			if _, _, err := c.ws.ReadMessage(); err != nil {
+				readErr <- err
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(pingPeriod)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			c.closeWith(websocket.CloseGoingAway)
+			return nil
+		case <-c.closed:
+			return nil
+		case err := <-readErr:
+			if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				return nil
+			}
+			return err
+		case <-ping.C:
+			if err := c.write(websocket.PingMessage, nil); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Close closes the connection without a close handshake. It is safe to call
+// more than once.
+func (c *Conn) Close() {
+	c.closeOnce.Do(func() {
+		close(c.closed)
+		if err := c.ws.Close(); err != nil {
+			logger.Debug("failed to close websocket connection", zap.Error(err))
+		}
+	})
+}
+
+// closeWith sends a close frame with code before closing the connection.
+func (c *Conn) closeWith(code int) {
+	if err := c.write(websocket.CloseMessage, websocket.FormatCloseMessage(code, "")); err != nil && !errors.Is(err, ErrClosed) {
+		logger.Debug("failed to send websocket close frame", zap.Error(err))
+	}
+	c.Close()
+}
+
+func (c *Conn) write(messageType int, data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	select {
+	case <-c.closed:
+		return ErrClosed
+	default:
+	}
+
+	if err := c.ws.SetWriteDeadline(time.Now().Add(writeWait)); err != nil {
+		return err
+	}
+	return c.ws.WriteMessage(messageType, data)
+}
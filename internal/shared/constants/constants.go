@@ -13,27 +13,89 @@ const (
 	UserStatusBanned   = "banned"
 )
 
+// UserStatuses lists every user status. The enum_status validation tag
+// accepts exactly these, so a new status only has to be added here. Roles
+// are not listed: they are those of the authz policy.
+var UserStatuses = []string{UserStatusActive, UserStatusInactive, UserStatusBanned}
+
 // Context keys
 const (
 	ContextKeyUserID    = "user_id"
 	ContextKeyUserEmail = "user_email"
 	ContextKeyUserRole  = "user_role"
 	ContextKeyRequestID = "request_id"
+	ContextKeyTraceID   = "trace_id"
+	ContextKeyAPIKeyID  = "api_key_id"
+	// ContextKeyActorID holds the ID of the admin behind an impersonation
+	// token; it is unset for regular sessions.
+	ContextKeyActorID = "actor_id"
 )
 
 // Header keys
 const (
 	HeaderAuthorization = "Authorization"
+	HeaderAPIKey        = "X-API-Key"
 	HeaderContentType   = "Content-Type"
 	HeaderRequestID     = "X-Request-ID"
+	HeaderTraceID       = "X-Trace-ID"
 	HeaderUserAgent     = "User-Agent"
+	HeaderRetryAfter    = "Retry-After"
+
+	HeaderRateLimitLimit     = "X-RateLimit-Limit"
+	HeaderRateLimitRemaining = "X-RateLimit-Remaining"
+	HeaderRateLimitReset     = "X-RateLimit-Reset"
+
+	HeaderIdempotencyKey     = "Idempotency-Key"
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+
+	HeaderCSRFToken = "X-CSRF-Token"
+)
+
+// Cookies
+const (
+	// CookieAccessToken carries the access token for browser clients, as an
+	// alternative to the Authorization header.
+	CookieAccessToken = "access_token"
+	// CookieRefreshToken carries the refresh token for browser clients. It is
+	// only sent to the auth endpoints.
+	CookieRefreshToken = "refresh_token"
+	// CookieCSRFToken holds the token that cookie-authenticated requests must
+	// echo in the X-CSRF-Token header.
+	CookieCSRFToken = "csrf_token"
+)
+
+// Query parameters
+const (
+	// QueryParamAccessToken carries the access token on WebSocket upgrades,
+	// since browsers cannot set headers on them. It is redacted from logs.
+	QueryParamAccessToken = "access_token"
+	// QueryParamTokenDelivery overrides AUTH_COOKIE_DELIVERY for a login or
+	// refresh request.
+	QueryParamTokenDelivery = "token_delivery"
+)
+
+// Token delivery modes of login and refresh responses
+const (
+	TokenDeliveryJSON   = "json"
+	TokenDeliveryCookie = "cookie"
+	TokenDeliveryBoth   = "both"
 )
 
 // Cache keys
 const (
-	CacheKeyUserPrefix    = "user:"
-	CacheKeyTokenPrefix   = "token:"
-	CacheKeySessionPrefix = "session:"
+	CacheKeyUserPrefix         = "user:"
+	CacheKeyUserEmailPrefix    = "user:email:"
+	CacheKeyUserUsernamePrefix = "user:username:"
+	CacheKeyTokenPrefix        = "token:"
+	CacheKeySessionPrefix      = "session:"
+	CacheKeyIdempotencyPrefix  = "idempotency:"
+	CacheKeyMFAChallengePrefix = "mfa_challenge:"
+	CacheKeyMFAAttemptsPrefix  = "mfa_attempts:"
+	CacheKeyTOTPStepPrefix     = "totp_step:"
+	CacheKeyUserStats          = "stats:users"
+	CacheKeyMaintenance        = "maintenance"
+	CacheKeyTokenEpochPrefix   = "token_epoch:"
+	CacheKeyRegistrationPrefix = "registrations:"
 )
 
 // Cache TTL
@@ -43,6 +105,18 @@ const (
 	CacheTTLLong   = 3600 // 1 hour
 )
 
+// Audit actions
+const (
+	AuditActionUserDeleted       = "user.deleted"
+	AuditActionUserSelfDeleted   = "user.self_deleted"
+	AuditActionUserStatusChanged = "user.status_changed"
+	AuditActionUserBulkCreated   = "user.bulk_created"
+	AuditActionUserRestored      = "user.restored"
+	AuditActionUserImpersonated  = "user.impersonated"
+	AuditActionAPIKeyCreated     = "api_key.created"
+	AuditActionAPIKeyRevoked     = "api_key.revoked"
+)
+
 // Queue names
 const (
 	QueueUserEvents = "user.events"
@@ -54,6 +128,11 @@ const (
 	ExchangeUserEvents = "user.events"
 )
 
+// Stream names
+const (
+	StreamUserEvents = "user.events"
+)
+
 // Routing keys
 const (
 	RoutingKeyUserCreated = "user.created"
@@ -0,0 +1,77 @@
+// Package crud holds the usecase plumbing every domain repeats around its
+// repository: loading an entity by ID with consistent error handling, and
+// mapping entities to response DTOs. Embed Base in a domain usecase and write
+// only the domain-specific operations.
+package crud
+
+import (
+	"context"
+
+	"github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/TubagusAldiMY/go-template/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Getter loads an entity by ID. Repositories built on database.Store get it
+// from Store.GetByID.
+type Getter[T any] interface {
+	GetByID(ctx context.Context, id string) (*T, error)
+}
+
+// Base loads entities of type T and maps them to responses of type R.
+type Base[T, R any] struct {
+	repo       Getter[T]
+	entity     string
+	notFound   error
+	toResponse func(*T) *R
+}
+
+// NewBase returns a Base loading from repo. entity names T in logs, such as
+// "user"; notFound is the error repo returns for a missing entity, passed on
+// to clients as is; toResponse builds the response DTO for an entity.
+func NewBase[T, R any](repo Getter[T], entity string, notFound error, toResponse func(*T) *R) Base[T, R] {
+	return Base[T, R]{
+		repo:       repo,
+		entity:     entity,
+		notFound:   notFound,
+		toResponse: toResponse,
+	}
+}
+
+// Load returns the entity with id. A missing entity returns the notFound
+// error; any other failure is logged and returned as ErrInternal so storage
+// details never reach clients.
+func (b Base[T, R]) Load(ctx context.Context, id string) (*T, error) {
+	entity, err := b.repo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, b.notFound) {
+			return nil, b.notFound
+		}
+		logger.FromContext(ctx).Error("failed to get "+b.entity, zap.Error(err))
+		return nil, errors.ErrInternal
+	}
+	return entity, nil
+}
+
+// Get is Load returning the response for the entity.
+func (b Base[T, R]) Get(ctx context.Context, id string) (*R, error) {
+	entity, err := b.Load(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return b.toResponse(entity), nil
+}
+
+// Response returns the response for entity.
+func (b Base[T, R]) Response(entity *T) *R {
+	return b.toResponse(entity)
+}
+
+// Responses returns the responses for entities, in order.
+func (b Base[T, R]) Responses(entities []*T) []*R {
+	responses := make([]*R, len(entities))
+	for i, entity := range entities {
+		responses[i] = b.toResponse(entity)
+	}
+	return responses
+}
@@ -0,0 +1,75 @@
+package errors
+
+// Machine-readable error codes returned in the code field of error
+// responses. They are part of the API contract: clients key translations
+// and handling off them, so existing values must not change.
+const (
+	CodeInternal      = "INTERNAL_ERROR"
+	CodeNotFound      = "NOT_FOUND"
+	CodeAlreadyExists = "ALREADY_EXISTS"
+	CodeInvalidInput  = "INVALID_INPUT"
+	CodeUnauthorized  = "UNAUTHORIZED"
+	CodeForbidden     = "FORBIDDEN"
+	CodeInvalidCursor = "INVALID_CURSOR"
+	CodeStaleData     = "STALE_DATA"
+	CodeTimeout       = "TIMEOUT"
+
+	CodeUserNotFound              = "USER_NOT_FOUND"
+	CodeUserAlreadyExists         = "USER_ALREADY_EXISTS"
+	CodeInvalidCredentials        = "INVALID_CREDENTIALS"
+	CodeEmailAlreadyExists        = "EMAIL_ALREADY_EXISTS"
+	CodeUsernameAlreadyExists     = "USERNAME_ALREADY_EXISTS"
+	CodeInvalidStatus             = "INVALID_STATUS"
+	CodeStatusUnchanged           = "STATUS_UNCHANGED"
+	CodeCannotImpersonate         = "CANNOT_IMPERSONATE"
+	CodeDisposableEmail           = "DISPOSABLE_EMAIL"
+	CodeRegistrationLimitExceeded = "REGISTRATION_LIMIT_EXCEEDED"
+
+	CodeInvalidToken    = "INVALID_TOKEN"
+	CodeExpiredToken    = "TOKEN_EXPIRED"
+	CodeInvalidPassword = "INVALID_PASSWORD"
+	CodePasswordTooWeak = "PASSWORD_TOO_WEAK"
+	CodeSessionNotFound = "SESSION_NOT_FOUND"
+	CodeSignOutFailed   = "SIGN_OUT_FAILED"
+
+	CodeAPIKeyNotFound = "API_KEY_NOT_FOUND"
+	CodeInvalidAPIKey  = "INVALID_API_KEY"
+
+	CodeMFANotConfigured  = "MFA_NOT_CONFIGURED"
+	CodeMFAAlreadyEnabled = "MFA_ALREADY_ENABLED"
+	CodeMFANotEnrolled    = "MFA_NOT_ENROLLED"
+	CodeInvalidMFACode    = "INVALID_MFA_CODE"
+	CodeInvalidMFAToken   = "INVALID_MFA_TOKEN"
+
+	CodeStorageNotConfigured = "STORAGE_NOT_CONFIGURED"
+	CodeFileTooLarge         = "FILE_TOO_LARGE"
+	CodeUnsupportedMediaType = "UNSUPPORTED_MEDIA_TYPE"
+)
+
+// Code returns the machine-readable code of the first AppError in err's
+// chain that has one. Unknown errors are reported as CodeInternal.
+func Code(err error) string {
+	var appErr *AppError
+	for As(err, &appErr) {
+		if appErr.Code != "" {
+			return appErr.Code
+		}
+		err = appErr.Err
+	}
+
+	return CodeInternal
+}
+
+// StatusError returns the first AppError in err's chain that has a Status,
+// or nil if there is none.
+func StatusError(err error) *AppError {
+	var appErr *AppError
+	for As(err, &appErr) {
+		if appErr.Status != 0 {
+			return appErr
+		}
+		err = appErr.Err
+	}
+
+	return nil
+}
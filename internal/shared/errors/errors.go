@@ -1,39 +1,74 @@
 package errors
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net/http"
 )
 
-// Domain errors
+// Domain errors. Each is an AppError carrying its code, HTTP status and the
+// message sent to clients; errors.Is matches them, and copies made with
+// WithMessage or Wrap, by code.
 var (
 	// Generic errors
-	ErrInternal      = errors.New("internal server error")
-	ErrNotFound      = errors.New("resource not found")
-	ErrAlreadyExists = errors.New("resource already exists")
-	ErrInvalidInput  = errors.New("invalid input")
-	ErrUnauthorized  = errors.New("unauthorized")
-	ErrForbidden     = errors.New("forbidden")
+	ErrInternal      = Internal(CodeInternal, "Internal server error")
+	ErrNotFound      = NotFound(CodeNotFound, "Resource not found")
+	ErrAlreadyExists = Conflict(CodeAlreadyExists, "Resource already exists")
+	ErrInvalidInput  = BadRequest(CodeInvalidInput, "Invalid input")
+	ErrUnauthorized  = Unauthorized(CodeUnauthorized, "Unauthorized")
+	ErrForbidden     = Forbidden(CodeForbidden, "Forbidden")
+	ErrInvalidCursor = BadRequest(CodeInvalidCursor, "Invalid cursor")
+	ErrStaleData     = Conflict(CodeStaleData, "Resource was modified concurrently, please retry")
+	ErrTimeout       = Unavailable(CodeTimeout, "Request timed out")
 
 	// User errors
-	ErrUserNotFound          = errors.New("user not found")
-	ErrUserAlreadyExists     = errors.New("user already exists")
-	ErrInvalidCredentials    = errors.New("invalid credentials")
-	ErrEmailAlreadyExists    = errors.New("email already exists")
-	ErrUsernameAlreadyExists = errors.New("username already exists")
+	ErrUserNotFound              = NotFound(CodeUserNotFound, "User not found")
+	ErrUserAlreadyExists         = Conflict(CodeUserAlreadyExists, "User already exists")
+	ErrInvalidCredentials        = Unauthorized(CodeInvalidCredentials, "Invalid credentials")
+	ErrEmailAlreadyExists        = Conflict(CodeEmailAlreadyExists, "Email already exists")
+	ErrUsernameAlreadyExists     = Conflict(CodeUsernameAlreadyExists, "Username already exists")
+	ErrInvalidStatus             = BadRequest(CodeInvalidStatus, "Invalid status")
+	ErrStatusUnchanged           = BadRequest(CodeStatusUnchanged, "User already has this status")
+	ErrCannotImpersonate         = Forbidden(CodeCannotImpersonate, "This user cannot be impersonated")
+	ErrDisposableEmail           = New(http.StatusUnprocessableEntity, CodeDisposableEmail, "Disposable email addresses are not allowed")
+	ErrRegistrationLimitExceeded = New(http.StatusTooManyRequests, CodeRegistrationLimitExceeded, "Too many accounts registered from this address, please try again later")
 
 	// Auth errors
-	ErrInvalidToken    = errors.New("invalid token")
-	ErrExpiredToken    = errors.New("token has expired")
-	ErrInvalidPassword = errors.New("invalid password")
-	ErrPasswordTooWeak = errors.New("password too weak")
+	ErrInvalidToken    = Unauthorized(CodeInvalidToken, "Invalid token")
+	ErrExpiredToken    = Unauthorized(CodeExpiredToken, "Token has expired")
+	ErrInvalidPassword = BadRequest(CodeInvalidPassword, "Invalid password")
+	ErrPasswordTooWeak = New(http.StatusUnprocessableEntity, CodePasswordTooWeak, "Password is too weak")
+	ErrSessionNotFound = NotFound(CodeSessionNotFound, "Session not found")
+	ErrSignOutFailed   = Unavailable(CodeSignOutFailed, "Existing sessions could not be signed out, so nothing was changed; try again")
+
+	// API key errors
+	ErrAPIKeyNotFound = NotFound(CodeAPIKeyNotFound, "API key not found")
+	ErrInvalidAPIKey  = Unauthorized(CodeInvalidAPIKey, "Invalid API key")
+
+	// MFA errors
+	ErrMFANotConfigured  = Unavailable(CodeMFANotConfigured, "Multi-factor authentication is not available")
+	ErrMFAAlreadyEnabled = Conflict(CodeMFAAlreadyEnabled, "Multi-factor authentication is already enabled")
+	ErrMFANotEnrolled    = BadRequest(CodeMFANotEnrolled, "TOTP enrollment has not been started")
+	ErrInvalidMFACode    = Unauthorized(CodeInvalidMFACode, "Invalid authentication code")
+	ErrInvalidMFAToken   = Unauthorized(CodeInvalidMFAToken, "Invalid or expired MFA token")
+
+	// Upload errors
+	ErrStorageNotConfigured = Unavailable(CodeStorageNotConfigured, "File uploads are not available")
+	ErrFileTooLarge         = New(http.StatusRequestEntityTooLarge, CodeFileTooLarge, "File is too large")
+	ErrUnsupportedMediaType = New(http.StatusUnsupportedMediaType, CodeUnsupportedMediaType, "Unsupported file type")
 )
 
-// AppError represents a custom application error
+// AppError is an application error with a machine-readable code, a message
+// safe to show clients and the HTTP status it is answered with. Err, when
+// set, is the underlying cause; it is unwrapped but never sent to clients.
 type AppError struct {
 	Code    string
 	Message string
-	Err     error
+	// Status is the HTTP status of the error response. Zero leaves it to
+	// the errors Err wraps.
+	Status int
+	Err    error
 }
 
 func (e *AppError) Error() string {
@@ -47,7 +82,71 @@ func (e *AppError) Unwrap() error {
 	return e.Err
 }
 
-// NewAppError creates a new AppError
+// Is reports whether target is an AppError with the same code, so a copy
+// with a more specific message still matches the sentinel it came from.
+func (e *AppError) Is(target error) bool {
+	t, ok := target.(*AppError)
+	return ok && t.Code != "" && t.Code == e.Code
+}
+
+// WithMessage returns a copy of e with message, e.g. naming the resource
+// that was not found.
+func (e *AppError) WithMessage(message string) *AppError {
+	c := *e
+	c.Message = message
+	return &c
+}
+
+// Wrap returns a copy of e caused by err.
+func (e *AppError) Wrap(err error) *AppError {
+	c := *e
+	c.Err = err
+	return &c
+}
+
+// New returns an AppError answered with status.
+func New(status int, code, message string) *AppError {
+	return &AppError{Code: code, Message: message, Status: status}
+}
+
+// NotFound returns an AppError answered with 404.
+func NotFound(code, message string) *AppError {
+	return New(http.StatusNotFound, code, message)
+}
+
+// Conflict returns an AppError answered with 409.
+func Conflict(code, message string) *AppError {
+	return New(http.StatusConflict, code, message)
+}
+
+// BadRequest returns an AppError answered with 400.
+func BadRequest(code, message string) *AppError {
+	return New(http.StatusBadRequest, code, message)
+}
+
+// Unauthorized returns an AppError answered with 401.
+func Unauthorized(code, message string) *AppError {
+	return New(http.StatusUnauthorized, code, message)
+}
+
+// Forbidden returns an AppError answered with 403.
+func Forbidden(code, message string) *AppError {
+	return New(http.StatusForbidden, code, message)
+}
+
+// Unavailable returns an AppError answered with 503.
+func Unavailable(code, message string) *AppError {
+	return New(http.StatusServiceUnavailable, code, message)
+}
+
+// Internal returns an AppError answered with 500. Its message is not sent to
+// clients either; they get a generic one.
+func Internal(code, message string) *AppError {
+	return New(http.StatusInternalServerError, code, message)
+}
+
+// NewAppError returns an AppError without a status of its own, answered
+// like the error it wraps, or with 500 when it wraps none.
 func NewAppError(code, message string, err error) *AppError {
 	return &AppError{
 		Code:    code,
@@ -64,6 +163,21 @@ func Wrap(err error, message string) error {
 	return fmt.Errorf("%s: %w", message, err)
 }
 
+// ContextError translates a failure caused by an expired or cancelled context
+// into ErrTimeout (still matching context.DeadlineExceeded) or
+// context.Canceled. It returns nil for any other error, so callers fall back
+// to wrapping err.
+func ContextError(err error) error {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return fmt.Errorf("%w: %w", ErrTimeout, context.DeadlineExceeded)
+	case errors.Is(err, context.Canceled):
+		return context.Canceled
+	default:
+		return nil
+	}
+}
+
 // Is checks if an error is of a specific type
 func Is(err, target error) bool {
 	return errors.Is(err, target)
@@ -73,3 +187,8 @@ func Is(err, target error) bool {
 func As(err error, target interface{}) bool {
 	return errors.As(err, target)
 }
+
+// Join returns an error wrapping errs, or nil if every one of them is nil
+func Join(errs ...error) error {
+	return errors.Join(errs...)
+}
@@ -7,6 +7,8 @@ import (
 	"regexp"
 	"strings"
 	"time"
+
+	"github.com/TubagusAldiMY/go-template/pkg/response"
 )
 
 // ParseTime parses time string to time.Time
@@ -63,6 +65,12 @@ func IsValidEmail(email string) bool {
 	return emailRegex.MatchString(email)
 }
 
+// NormalizeEmail returns email trimmed and lowercased, the form emails are
+// stored, compared and blind-indexed in.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
 // TruncateString truncates string to specified length with ellipsis
 func TruncateString(s string, maxLen int) string {
 	if len(s) <= maxLen {
@@ -124,10 +132,7 @@ func Paginate(page, pageSize int, total int64) (offset int, limit int, totalPage
 
 	offset = (page - 1) * pageSize
 	limit = pageSize
-	totalPages = int(total) / pageSize
-	if int(total)%pageSize != 0 {
-		totalPages++
-	}
+	totalPages = response.TotalPages(pageSize, total)
 
 	return offset, limit, totalPages
 }
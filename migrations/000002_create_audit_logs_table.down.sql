@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS trg_audit_logs_immutable ON audit_logs;
+DROP FUNCTION IF EXISTS prevent_audit_log_change();
+DROP TABLE IF EXISTS audit_logs;
@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id VARCHAR(36) PRIMARY KEY,
+    actor_id VARCHAR(36) NULL,
+    action VARCHAR(100) NOT NULL,
+    target_id VARCHAR(36) NULL,
+    client_ip VARCHAR(45) NULL,
+    metadata JSONB NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Create indexes
+CREATE INDEX idx_audit_logs_created_at ON audit_logs(created_at DESC);
+CREATE INDEX idx_audit_logs_actor_id ON audit_logs(actor_id);
+CREATE INDEX idx_audit_logs_target_id ON audit_logs(target_id);
+CREATE INDEX idx_audit_logs_action ON audit_logs(action);
+
+-- Audit entries are append-only
+CREATE OR REPLACE FUNCTION prevent_audit_log_change() RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_logs is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trg_audit_logs_immutable
+    BEFORE UPDATE OR DELETE ON audit_logs
+    FOR EACH ROW EXECUTE FUNCTION prevent_audit_log_change();
+
+-- Comments
+COMMENT ON TABLE audit_logs IS 'Append-only trail of administrative actions';
+COMMENT ON COLUMN audit_logs.actor_id IS 'ID of the user who performed the action';
+COMMENT ON COLUMN audit_logs.action IS 'Action name, e.g. user.deleted';
+COMMENT ON COLUMN audit_logs.target_id IS 'ID of the affected resource';
+COMMENT ON COLUMN audit_logs.client_ip IS 'Client IP address of the request';
+COMMENT ON COLUMN audit_logs.metadata IS 'Action-specific details';
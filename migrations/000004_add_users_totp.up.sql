@@ -0,0 +1,5 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS totp_secret TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS mfa_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+
+COMMENT ON COLUMN users.totp_secret IS 'Encrypted TOTP secret, set once enrollment starts';
+COMMENT ON COLUMN users.mfa_enabled IS 'Whether login requires a TOTP code';
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL REFERENCES users(id),
+    name VARCHAR(100) NOT NULL,
+    prefix VARCHAR(20) NOT NULL,
+    key_hash CHAR(64) UNIQUE NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    revoked_at TIMESTAMP NULL
+);
+
+-- Create indexes
+CREATE INDEX idx_api_keys_user_id ON api_keys(user_id);
+
+-- Comments
+COMMENT ON TABLE api_keys IS 'API keys for service-to-service authentication';
+COMMENT ON COLUMN api_keys.user_id IS 'User the key authenticates as';
+COMMENT ON COLUMN api_keys.prefix IS 'Leading characters of the key, kept to identify it';
+COMMENT ON COLUMN api_keys.key_hash IS 'SHA-256 hex digest of the key; the key itself is never stored';
+COMMENT ON COLUMN api_keys.revoked_at IS 'Set when the key is revoked';
@@ -0,0 +1,3 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS avatar_url TEXT;
+
+COMMENT ON COLUMN users.avatar_url IS 'Public URL of the uploaded profile picture';
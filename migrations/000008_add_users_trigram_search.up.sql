@@ -0,0 +1,9 @@
+-- Fuzzy user search ranks rows with pg_trgm's similarity(). Creating the
+-- extension needs a role allowed to do so, e.g. the database owner on
+-- PostgreSQL 13+ where pg_trgm is a trusted extension.
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Trigram indexes also serve the default ILIKE '%term%' search.
+CREATE INDEX IF NOT EXISTS idx_users_email_trgm ON users USING GIN (email gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_users_username_trgm ON users USING GIN (username gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_users_full_name_trgm ON users USING GIN (full_name gin_trgm_ops);
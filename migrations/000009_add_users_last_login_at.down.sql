@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_users_last_login_at;
+
+ALTER TABLE users DROP COLUMN IF EXISTS last_login_at;
@@ -0,0 +1,6 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS last_login_at TIMESTAMP NULL;
+
+COMMENT ON COLUMN users.last_login_at IS 'When the user last completed a login; NULL if never';
+
+-- Serves the inactive_since filter for finding dormant accounts.
+CREATE INDEX IF NOT EXISTS idx_users_last_login_at ON users(last_login_at) WHERE deleted_at IS NULL;
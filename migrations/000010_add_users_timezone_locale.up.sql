@@ -0,0 +1,5 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NULL;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locale VARCHAR(35) NULL;
+
+COMMENT ON COLUMN users.timezone IS 'IANA time zone name, such as Asia/Jakarta; NULL if not set';
+COMMENT ON COLUMN users.locale IS 'BCP-47 language tag, such as id-ID; NULL if not set';
@@ -0,0 +1,13 @@
+-- Fails if a deleted user shares an email or username with another user;
+-- purge or rename those rows first.
+DROP INDEX IF EXISTS users_email_key;
+DROP INDEX IF EXISTS users_username_key;
+
+ALTER TABLE users ADD CONSTRAINT users_email_key UNIQUE (email);
+ALTER TABLE users ADD CONSTRAINT users_username_key UNIQUE (username);
+
+CREATE INDEX IF NOT EXISTS idx_users_email ON users(email) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_users_username ON users(username) WHERE deleted_at IS NULL;
+
+COMMENT ON COLUMN users.email IS 'User email address (unique, lowercase)';
+COMMENT ON COLUMN users.username IS 'User username (unique, 3-30 chars)';
@@ -0,0 +1,16 @@
+-- Email and username only need to be unique among users that are not
+-- deleted, so a soft-deleted user's email and username can be registered
+-- again. The partial indexes keep the names of the constraints they replace,
+-- which the repository maps to conflict errors.
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_email_key;
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_username_key;
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_key ON users(email) WHERE deleted_at IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS users_username_key ON users(username) WHERE deleted_at IS NULL;
+
+-- Superseded by the unique indexes above.
+DROP INDEX IF EXISTS idx_users_email;
+DROP INDEX IF EXISTS idx_users_username;
+
+COMMENT ON COLUMN users.email IS 'User email address (unique among non-deleted users, lowercase)';
+COMMENT ON COLUMN users.username IS 'User username (unique among non-deleted users, 3-30 chars)';
@@ -0,0 +1,10 @@
+-- Fails while encrypted values remain; run with PII encryption disabled only
+-- after restoring plaintext emails and full names.
+DROP INDEX IF EXISTS users_email_index_key;
+ALTER TABLE users DROP COLUMN IF EXISTS email_index;
+
+ALTER TABLE users ALTER COLUMN full_name TYPE VARCHAR(100);
+ALTER TABLE users ALTER COLUMN email TYPE VARCHAR(255);
+
+COMMENT ON COLUMN users.email IS 'User email address (unique among non-deleted users, lowercase)';
+COMMENT ON COLUMN users.full_name IS 'User full name';
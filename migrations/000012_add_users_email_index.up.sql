@@ -0,0 +1,15 @@
+-- With PII_ENCRYPTION_KEY set, email and full_name hold AES-GCM ciphertext,
+-- which outgrows their VARCHAR limits; the DTOs still bound the plaintext.
+ALTER TABLE users ALTER COLUMN email TYPE TEXT;
+ALTER TABLE users ALTER COLUMN full_name TYPE TEXT;
+
+-- Encrypted emails are looked up, and kept unique among users that are not
+-- deleted, through their blind index. Rows written before encryption was
+-- enabled have none until they are next updated.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_index VARCHAR(64) NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_index_key ON users(email_index) WHERE deleted_at IS NULL;
+
+COMMENT ON COLUMN users.email IS 'User email address (unique among non-deleted users, lowercase); encrypted when PII encryption is enabled';
+COMMENT ON COLUMN users.full_name IS 'User full name; encrypted when PII encryption is enabled';
+COMMENT ON COLUMN users.email_index IS 'Hex HMAC-SHA256 blind index of the email; NULL while the email is stored in plaintext';
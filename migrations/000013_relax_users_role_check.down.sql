@@ -0,0 +1,5 @@
+-- Fails while users hold roles other than admin and user; change their roles
+-- first.
+ALTER TABLE users ADD CONSTRAINT chk_role CHECK (role IN ('admin', 'user'));
+
+COMMENT ON COLUMN users.role IS 'User role: admin or user';
@@ -0,0 +1,6 @@
+-- Roles are defined by the authorization policy (AUTHZ_ROLE_PERMISSIONS), so
+-- the database no longer limits them to admin and user. The application
+-- validates roles against the policy before storing them.
+ALTER TABLE users DROP CONSTRAINT IF EXISTS chk_role;
+
+COMMENT ON COLUMN users.role IS 'User role, one of the roles of the authorization policy';
@@ -0,0 +1,10 @@
+// Package migrations embeds the versioned SQL migrations so the migrate
+// command works without the files on disk.
+package migrations
+
+import "embed"
+
+// FS holds files named <version>_<name>.up.sql and <version>_<name>.down.sql.
+//
+//go:embed *.sql
+var FS embed.FS
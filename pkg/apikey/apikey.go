@@ -0,0 +1,62 @@
+// Package apikey generates API keys and derives the values stored for them.
+// Keys carry 256 bits of randomness, so a fast SHA-256 digest is enough to
+// store them safely and still look them up by hash.
+package apikey
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/TubagusAldiMY/go-template/pkg/crypto"
+)
+
+const (
+	// Prefix starts every key so leaked keys are easy to recognise and scan for.
+	Prefix = "gtk_"
+
+	secretLength = 32
+	// displayLength is how much of the key is kept in clear text to let
+	// users tell their keys apart.
+	displayLength = len(Prefix) + 8
+)
+
+// Key is a newly generated API key. Plaintext is shown to the caller once
+// and never stored.
+type Key struct {
+	Plaintext string
+	// DisplayPrefix identifies the key in listings without revealing it.
+	DisplayPrefix string
+	Hash          string
+}
+
+// Generate creates a random API key.
+func Generate() (*Key, error) {
+	secret, err := crypto.GenerateRandomBytes(secretLength)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate api key: %w", err)
+	}
+
+	plaintext := Prefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	return &Key{
+		Plaintext:     plaintext,
+		DisplayPrefix: plaintext[:displayLength],
+		Hash:          Hash(plaintext),
+	}, nil
+}
+
+// Hash returns the hex SHA-256 digest under which key is stored.
+func Hash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// LooksValid reports whether key has the shape of a generated key, so
+// malformed values can be rejected without a database lookup.
+func LooksValid(key string) bool {
+	return strings.HasPrefix(key, Prefix) &&
+		len(key) == len(Prefix)+base64.RawURLEncoding.EncodedLen(secretLength)
+}
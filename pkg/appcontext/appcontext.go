@@ -0,0 +1,77 @@
+// Package appcontext carries the authenticated identity of a request in its
+// context.Context, so use cases and repositories can read who is calling
+// without access to the gin context. The auth middleware stores it; values
+// are absent outside authenticated requests.
+package appcontext
+
+import "context"
+
+type (
+	userIDKey   struct{}
+	emailKey    struct{}
+	roleKey     struct{}
+	actorIDKey  struct{}
+	apiKeyIDKey struct{}
+)
+
+// WithUserID returns a copy of ctx carrying the authenticated user's ID.
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDKey{}, userID)
+}
+
+// UserID returns the authenticated user's ID. Under an impersonation token
+// this is the impersonated user; see ActorID.
+func UserID(ctx context.Context) (string, bool) {
+	return value(ctx, userIDKey{})
+}
+
+// WithEmail returns a copy of ctx carrying the authenticated user's email.
+func WithEmail(ctx context.Context, email string) context.Context {
+	return context.WithValue(ctx, emailKey{}, email)
+}
+
+// Email returns the authenticated user's email.
+func Email(ctx context.Context) (string, bool) {
+	return value(ctx, emailKey{})
+}
+
+// WithRole returns a copy of ctx carrying the authenticated user's role.
+func WithRole(ctx context.Context, role string) context.Context {
+	return context.WithValue(ctx, roleKey{}, role)
+}
+
+// Role returns the authenticated user's role.
+func Role(ctx context.Context) (string, bool) {
+	return value(ctx, roleKey{})
+}
+
+// WithActorID returns a copy of ctx carrying the ID of the admin behind an
+// impersonation token.
+func WithActorID(ctx context.Context, actorID string) context.Context {
+	return context.WithValue(ctx, actorIDKey{}, actorID)
+}
+
+// ActorID returns the ID of the admin acting through an impersonation token.
+// It is absent on requests made with the user's own credentials.
+func ActorID(ctx context.Context) (string, bool) {
+	return value(ctx, actorIDKey{})
+}
+
+// WithAPIKeyID returns a copy of ctx carrying the ID of the API key the
+// request authenticated with.
+func WithAPIKeyID(ctx context.Context, keyID string) context.Context {
+	return context.WithValue(ctx, apiKeyIDKey{}, keyID)
+}
+
+// APIKeyID returns the ID of the API key the request authenticated with. It
+// is absent on requests authenticated with a token.
+func APIKeyID(ctx context.Context) (string, bool) {
+	return value(ctx, apiKeyIDKey{})
+}
+
+// value returns the string stored under key, reporting false when it is
+// missing or empty.
+func value(ctx context.Context, key any) (string, bool) {
+	v, _ := ctx.Value(key).(string)
+	return v, v != ""
+}
@@ -0,0 +1,142 @@
+package authz
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Permissions
+const (
+	// PermAll grants every permission.
+	PermAll = "*"
+
+	PermUserCreate = "user:create"
+	PermUserRead   = "user:read"
+	PermUserUpdate = "user:update"
+	PermUserDelete = "user:delete"
+	// PermUserImpersonate allows acting as another user through a
+	// short-lived impersonation token.
+	PermUserImpersonate = "user:impersonate"
+
+	PermAPIKeyManage = "api_key:manage"
+
+	PermAuditRead = "audit:read"
+	PermDebugRead = "debug:read"
+
+	PermTokenIntrospect = "token:introspect"
+
+	// PermMaintenanceManage allows turning maintenance mode on and off.
+	PermMaintenanceManage = "maintenance:manage"
+)
+
+// Policy maps a role to the permissions it grants.
+type Policy map[string][]string
+
+var permissions = compile(DefaultPolicy())
+
+// DefaultPolicy grants admins every permission and regular users none beyond
+// their own account, which is authorized by authentication alone.
+func DefaultPolicy() Policy {
+	return Policy{
+		"admin": {PermAll},
+		"user":  {},
+	}
+}
+
+// Init replaces the active policy. It is meant to be called once at startup.
+func Init(policy Policy) {
+	permissions = compile(policy)
+}
+
+// HasPermission reports whether role grants perm under the active policy.
+func HasPermission(role, perm string) bool {
+	granted, ok := permissions[role]
+	if !ok {
+		return false
+	}
+
+	_, all := granted[PermAll]
+	_, exact := granted[perm]
+	return all || exact
+}
+
+// Roles returns the roles of the active policy, sorted.
+func Roles() []string {
+	roles := make([]string, 0, len(permissions))
+	for role := range permissions {
+		roles = append(roles, role)
+	}
+	sort.Strings(roles)
+	return roles
+}
+
+// HasRole reports whether the active policy defines role, even if it grants
+// nothing.
+func HasRole(role string) bool {
+	_, ok := permissions[role]
+	return ok
+}
+
+// Covers reports whether role holds every permission other grants under the
+// active policy, so acting as other gives role nothing it lacks. Only a role
+// granted PermAll covers a role granted PermAll.
+func Covers(role, other string) bool {
+	granted := permissions[role]
+	if _, all := granted[PermAll]; all {
+		return true
+	}
+
+	for perm := range permissions[other] {
+		if _, ok := granted[perm]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// ParsePolicy parses a policy of the form
+// "admin=*;moderator=user:read,user:update", as used by AUTHZ_ROLE_PERMISSIONS.
+// A role with an empty permission list is kept and grants nothing.
+func ParsePolicy(s string) (Policy, error) {
+	policy := Policy{}
+
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		role, perms, ok := strings.Cut(entry, "=")
+		role = strings.TrimSpace(role)
+		if !ok || role == "" {
+			return nil, fmt.Errorf("invalid role permission entry %q", entry)
+		}
+
+		granted := []string{}
+		for _, perm := range strings.Split(perms, ",") {
+			if perm = strings.TrimSpace(perm); perm != "" {
+				granted = append(granted, perm)
+			}
+		}
+		policy[role] = granted
+	}
+
+	if len(policy) == 0 {
+		return nil, fmt.Errorf("role permission policy is empty")
+	}
+
+	return policy, nil
+}
+
+func compile(policy Policy) map[string]map[string]struct{} {
+	compiled := make(map[string]map[string]struct{}, len(policy))
+	for role, perms := range policy {
+		set := make(map[string]struct{}, len(perms))
+		for _, perm := range perms {
+			set[perm] = struct{}{}
+		}
+		compiled[role] = set
+	}
+	return compiled
+}
@@ -0,0 +1,63 @@
+// Package buildinfo reports which build of the application is running. The
+// values are set at link time:
+//
+//	go build -ldflags "-X github.com/TubagusAldiMY/go-template/pkg/buildinfo.Version=v1.4.0 \
+//	  -X github.com/TubagusAldiMY/go-template/pkg/buildinfo.Commit=$(git rev-parse HEAD) \
+//	  -X github.com/TubagusAldiMY/go-template/pkg/buildinfo.BuildDate=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Binaries built without them, such as with go run, report "dev" and take the
+// commit and its time from the VCS stamp Go embeds when available.
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Set with -ldflags "-X". The linker can only override string variables that
+// are uninitialized or initialized to a constant.
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildDate = ""
+)
+
+// Info describes the running build.
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date"`
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the build information. Values that were not set at link time
+// and cannot be recovered from the binary are "unknown".
+func Get() Info {
+	info := Info{
+		Version:   Version,
+		Commit:    Commit,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+	}
+
+	if info.Commit == "" || info.BuildDate == "" {
+		if bi, ok := debug.ReadBuildInfo(); ok {
+			for _, setting := range bi.Settings {
+				switch {
+				case setting.Key == "vcs.revision" && info.Commit == "":
+					info.Commit = setting.Value
+				case setting.Key == "vcs.time" && info.BuildDate == "":
+					info.BuildDate = setting.Value
+				}
+			}
+		}
+	}
+	if info.Commit == "" {
+		info.Commit = "unknown"
+	}
+	if info.BuildDate == "" {
+		info.BuildDate = "unknown"
+	}
+
+	return info
+}
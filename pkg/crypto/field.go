@@ -0,0 +1,70 @@
+package crypto
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// fieldPrefix marks values written by FieldCipher.Encrypt, so rows written
+// before encryption was enabled are still read as plaintext.
+const fieldPrefix = "enc:v1:"
+
+// FieldCipher encrypts personal data stored in database columns. Values are
+// encrypted with AES-256-GCM, so equal values encrypt differently; columns
+// that must still be looked up carry a blind index alongside: an HMAC-SHA256
+// of the plaintext under a separate key, which is deterministic and can be
+// matched with equality but not reversed.
+type FieldCipher struct {
+	cipher   *AESGCMCipher
+	indexKey []byte
+}
+
+// NewFieldCipher returns a cipher encrypting with the 32-byte encryptionKey
+// and indexing with indexKey, which must be at least 32 bytes and differ from
+// encryptionKey.
+func NewFieldCipher(encryptionKey, indexKey []byte) (*FieldCipher, error) {
+	if len(indexKey) < 32 {
+		return nil, fmt.Errorf("blind index key must be at least 32 bytes, got %d", len(indexKey))
+	}
+	if hmac.Equal(encryptionKey, indexKey) {
+		return nil, fmt.Errorf("blind index key must differ from the encryption key")
+	}
+
+	cipher, err := NewAESGCMCipher(encryptionKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FieldCipher{cipher: cipher, indexKey: indexKey}, nil
+}
+
+// Encrypt returns plaintext encrypted for storage.
+func (f *FieldCipher) Encrypt(plaintext string) (string, error) {
+	sealed, err := f.cipher.Encrypt(plaintext)
+	if err != nil {
+		return "", err
+	}
+	return fieldPrefix + sealed, nil
+}
+
+// Decrypt reverses Encrypt. Values Encrypt did not produce are returned as
+// they are, so columns can be encrypted row by row as they are rewritten.
+func (f *FieldCipher) Decrypt(value string) (string, error) {
+	sealed, ok := strings.CutPrefix(value, fieldPrefix)
+	if !ok {
+		return value, nil
+	}
+	return f.cipher.Decrypt(sealed)
+}
+
+// BlindIndex returns the hex-encoded HMAC-SHA256 of value. It is computed on
+// value as given, so callers normalize values that should match regardless
+// of case.
+func (f *FieldCipher) BlindIndex(value string) string {
+	mac := hmac.New(sha256.New, f.indexKey)
+	mac.Write([]byte(value))
+	return hex.EncodeToString(mac.Sum(nil))
+}
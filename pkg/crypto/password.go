@@ -2,21 +2,82 @@ package crypto
 
 import (
 	"crypto/rand"
+	"crypto/subtle"
 	"encoding/base64"
+	"errors"
 	"fmt"
+	"strings"
 
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
+const (
+	AlgorithmBcrypt   = "bcrypt"
+	AlgorithmArgon2id = "argon2id"
+
+	argon2idPrefix = "$argon2id$"
+)
+
+var (
+	ErrPasswordMismatch = errors.New("password does not match")
+	ErrInvalidHash      = errors.New("invalid password hash")
+	ErrInvalidCost      = errors.New("invalid bcrypt cost")
+)
+
+// Argon2Params tunes argon2id hashing. Memory is in KiB.
+type Argon2Params struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2Params follows the OWASP baseline recommendation for argon2id.
+func DefaultArgon2Params() Argon2Params {
+	return Argon2Params{
+		Memory:      64 * 1024,
+		Iterations:  3,
+		Parallelism: 2,
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+}
+
+// PasswordHasher hashes new passwords with the configured algorithm and
+// verifies stored hashes of either algorithm, detected from the hash prefix,
+// so existing bcrypt hashes keep working after switching to argon2id.
 type PasswordHasher struct {
-	cost int
+	algorithm string
+	cost      int
+	argon2    Argon2Params
+}
+
+// NewPasswordHasher returns a bcrypt hasher with the given cost. Zero selects
+// bcrypt.DefaultCost; any other cost outside bcrypt.MinCost..bcrypt.MaxCost
+// returns ErrInvalidCost, instead of failing every Hash call later.
+func NewPasswordHasher(cost int) (*PasswordHasher, error) {
+	if cost == 0 {
+		cost = bcrypt.DefaultCost
+	}
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return nil, fmt.Errorf("%w: must be between %d and %d, got %d", ErrInvalidCost, bcrypt.MinCost, bcrypt.MaxCost, cost)
+	}
+	return &PasswordHasher{algorithm: AlgorithmBcrypt, cost: cost, argon2: DefaultArgon2Params()}, nil
 }
 
-func NewPasswordHasher(cost int) *PasswordHasher {
-	return &PasswordHasher{cost: cost}
+// NewArgon2idPasswordHasher returns an argon2id hasher. Bcrypt hashes are
+// still accepted by Compare.
+func NewArgon2idPasswordHasher(params Argon2Params) *PasswordHasher {
+	return &PasswordHasher{algorithm: AlgorithmArgon2id, cost: bcrypt.DefaultCost, argon2: params}
 }
 
 func (h *PasswordHasher) Hash(password string) (string, error) {
+	if h.algorithm == AlgorithmArgon2id {
+		return h.hashArgon2id(password)
+	}
+
 	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
 	if err != nil {
 		return "", fmt.Errorf("failed to hash password: %w", err)
@@ -25,13 +86,114 @@ func (h *PasswordHasher) Hash(password string) (string, error) {
 }
 
 func (h *PasswordHasher) Compare(hashedPassword, password string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+	if strings.HasPrefix(hashedPassword, argon2idPrefix) {
+		return compareArgon2id(hashedPassword, password)
+	}
+
+	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return ErrPasswordMismatch
+	}
+	return err
 }
 
 func (h *PasswordHasher) IsValid(hashedPassword, password string) bool {
 	return h.Compare(hashedPassword, password) == nil
 }
 
+// NeedsRehash reports whether hashedPassword was produced with a different
+// algorithm or weaker parameters than the hasher is configured with. Hashes
+// that cannot be parsed report false.
+func (h *PasswordHasher) NeedsRehash(hashedPassword string) bool {
+	if strings.HasPrefix(hashedPassword, argon2idPrefix) {
+		if h.algorithm != AlgorithmArgon2id {
+			return true
+		}
+		params, _, _, err := decodeArgon2id(hashedPassword)
+		if err != nil {
+			return false
+		}
+		return params.Memory != h.argon2.Memory ||
+			params.Iterations != h.argon2.Iterations ||
+			params.Parallelism != h.argon2.Parallelism ||
+			params.KeyLength != h.argon2.KeyLength
+	}
+
+	cost, err := bcrypt.Cost([]byte(hashedPassword))
+	if err != nil {
+		return false
+	}
+	return h.algorithm != AlgorithmBcrypt || cost != h.cost
+}
+
+// hashArgon2id encodes the hash in the PHC string format:
+// $argon2id$v=19$m=<memory>,t=<iterations>,p=<parallelism>$<salt>$<key>
+func (h *PasswordHasher) hashArgon2id(password string) (string, error) {
+	salt, err := GenerateRandomBytes(int(h.argon2.SaltLength))
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.argon2.Iterations, h.argon2.Memory, h.argon2.Parallelism, h.argon2.KeyLength)
+
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2idPrefix,
+		argon2.Version,
+		h.argon2.Memory,
+		h.argon2.Iterations,
+		h.argon2.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func compareArgon2id(hashedPassword, password string) error {
+	params, salt, key, err := decodeArgon2id(hashedPassword)
+	if err != nil {
+		return err
+	}
+
+	candidate := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+	if subtle.ConstantTimeCompare(key, candidate) != 1 {
+		return ErrPasswordMismatch
+	}
+	return nil
+}
+
+func decodeArgon2id(hashedPassword string) (Argon2Params, []byte, []byte, error) {
+	var params Argon2Params
+
+	// "", "argon2id", "v=19", "m=..,t=..,p=..", salt, key
+	parts := strings.Split(hashedPassword, "$")
+	if len(parts) != 6 {
+		return params, nil, nil, ErrInvalidHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, ErrInvalidHash
+	}
+
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, ErrInvalidHash
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, ErrInvalidHash
+	}
+
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return params, nil, nil, ErrInvalidHash
+	}
+
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+
+	return params, salt, key, nil
+}
+
 func GenerateRandomString(length int) (string, error) {
 	bytes := make([]byte, length)
 	if _, err := rand.Read(bytes); err != nil {
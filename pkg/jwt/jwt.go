@@ -1,6 +1,7 @@
 package jwt
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"time"
@@ -13,69 +14,241 @@ var (
 	ErrInvalidToken         = errors.New("invalid token")
 	ErrExpiredToken         = errors.New("token has expired")
 	ErrInvalidSigningMethod = errors.New("invalid signing method")
+	// ErrRevokedToken is returned for access tokens issued before their
+	// user's token epoch was bumped, for example by a password change.
+	ErrRevokedToken = errors.New("token has been revoked")
+	// ErrEpochUnavailable is returned when the token epoch of a user cannot
+	// be read, so the token can neither be issued nor checked.
+	ErrEpochUnavailable = errors.New("token epoch unavailable")
 )
 
 type Claims struct {
 	UserID string `json:"user_id"`
 	Email  string `json:"email"`
 	Role   string `json:"role"`
+	// Act is set on impersonation tokens and names the user acting as
+	// UserID.
+	Act *ActorClaim `json:"act,omitempty"`
+	// Epoch is the token epoch of the user when the token was issued.
+	Epoch int64 `json:"epoch,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// ActorClaim is the act claim of RFC 8693: the party acting on behalf of
+// the token's subject.
+type ActorClaim struct {
+	Subject string `json:"sub"`
+}
+
+// RefreshClaims identify a validated refresh token.
+type RefreshClaims struct {
+	UserID  string
+	TokenID string
+	// SessionID names the login session the token belongs to; it stays the
+	// same across rotations.
+	SessionID string
+	// Lifetime is the validity the token was issued with, so the token that
+	// replaces it on rotation can be given the same one.
+	Lifetime time.Duration
+}
+
+// refreshTokenClaims are the claims of a signed refresh token.
+type refreshTokenClaims struct {
+	SessionID string `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
 type Manager struct {
-	secretKey            string
-	accessTokenDuration  time.Duration
-	refreshTokenDuration time.Duration
+	secretKey                      string
+	accessTokenDuration            time.Duration
+	refreshTokenDuration           time.Duration
+	rememberMeRefreshTokenDuration time.Duration
+	impersonationTokenDuration     time.Duration
+	issuer                         string
+	audience                       string
+	epochs                         EpochStore
+	epochFailOpen                  bool
+}
+
+// EpochStore returns the token epoch of users: a counter bumped whenever
+// every access token issued to the user so far must stop being accepted.
+type EpochStore interface {
+	TokenEpoch(ctx context.Context, userID string) (int64, error)
+}
+
+// Option configures optional Manager settings.
+type Option func(*Manager)
+
+// WithIssuer sets the iss claim of access tokens and rejects access tokens
+// issued by anyone else. An empty issuer disables the check.
+func WithIssuer(issuer string) Option {
+	return func(m *Manager) {
+		m.issuer = issuer
+	}
+}
+
+// WithAudience sets the aud claim of access tokens and rejects access tokens
+// not meant for audience. An empty audience disables the check.
+func WithAudience(audience string) Option {
+	return func(m *Manager) {
+		m.audience = audience
+	}
+}
+
+// WithRememberMeRefreshDuration sets the lifetime of refresh tokens issued
+// for "remember me" logins. Zero, the default, gives them the regular
+// refresh token lifetime.
+func WithRememberMeRefreshDuration(duration time.Duration) Option {
+	return func(m *Manager) {
+		m.rememberMeRefreshTokenDuration = duration
+	}
+}
+
+// WithImpersonationDuration sets the lifetime of impersonation tokens. Zero,
+// the default, gives them the regular access token lifetime.
+func WithImpersonationDuration(duration time.Duration) Option {
+	return func(m *Manager) {
+		m.impersonationTokenDuration = duration
+	}
+}
+
+// WithEpochStore embeds the user's token epoch from epochs in access tokens
+// and rejects access tokens whose epoch is older than the current one with
+// ErrRevokedToken. When the epoch cannot be read, tokens are not issued and,
+// unless failOpen is set, not accepted either; failOpen accepts them
+// unchecked instead.
+func WithEpochStore(epochs EpochStore, failOpen bool) Option {
+	return func(m *Manager) {
+		m.epochs = epochs
+		m.epochFailOpen = failOpen
+	}
 }
 
-func NewManager(secretKey string, accessTokenDuration, refreshTokenDuration time.Duration) *Manager {
-	return &Manager{
+func NewManager(secretKey string, accessTokenDuration, refreshTokenDuration time.Duration, opts ...Option) *Manager {
+	m := &Manager{
 		secretKey:            secretKey,
 		accessTokenDuration:  accessTokenDuration,
 		refreshTokenDuration: refreshTokenDuration,
 	}
+
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
 }
 
-func (m *Manager) GenerateAccessToken(userID, email, role string) (string, error) {
-	now := time.Now()
-	claims := Claims{
+func (m *Manager) GenerateAccessToken(ctx context.Context, userID, email, role string) (string, error) {
+	return m.signAccessToken(ctx, Claims{UserID: userID, Email: email, Role: role}, m.accessTokenDuration)
+}
+
+// GenerateImpersonationToken returns an access token for userID whose act
+// claim records actorID, valid for ImpersonationTokenDuration. It grants the
+// user's role, not the actor's.
+func (m *Manager) GenerateImpersonationToken(ctx context.Context, userID, email, role, actorID string) (string, error) {
+	return m.signAccessToken(ctx, Claims{
 		UserID: userID,
 		Email:  email,
 		Role:   role,
+		Act:    &ActorClaim{Subject: actorID},
+	}, m.ImpersonationTokenDuration())
+}
+
+// ImpersonationTokenDuration returns the lifetime of impersonation tokens.
+func (m *Manager) ImpersonationTokenDuration() time.Duration {
+	if m.impersonationTokenDuration <= 0 {
+		return m.accessTokenDuration
+	}
+	return m.impersonationTokenDuration
+}
+
+// signAccessToken fills in the epoch and registered claims of claims, valid
+// for duration from now, and signs it.
+func (m *Manager) signAccessToken(ctx context.Context, claims Claims, duration time.Duration) (string, error) {
+	if m.epochs != nil {
+		epoch, err := m.epochs.TokenEpoch(ctx, claims.UserID)
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", ErrEpochUnavailable, err)
+		}
+		claims.Epoch = epoch
+	}
+
+	now := time.Now()
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        uuid.New().String(),
+		Subject:   claims.UserID,
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(duration)),
+		NotBefore: jwt.NewNumericDate(now),
+		Issuer:    m.issuer,
+	}
+	if m.audience != "" {
+		claims.Audience = jwt.ClaimStrings{m.audience}
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString([]byte(m.secretKey))
+}
+
+// GenerateRefreshToken returns a signed refresh token of sessionID valid for
+// duration, together with its unique ID (jti), which callers use to track
+// token rotation. A zero duration uses RefreshTokenDuration.
+func (m *Manager) GenerateRefreshToken(userID, sessionID string, duration time.Duration) (string, string, error) {
+	if duration <= 0 {
+		duration = m.refreshTokenDuration
+	}
+
+	now := time.Now()
+	claims := refreshTokenClaims{
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ID:        uuid.New().String(),
 			Subject:   userID,
 			IssuedAt:  jwt.NewNumericDate(now),
-			ExpiresAt: jwt.NewNumericDate(now.Add(m.accessTokenDuration)),
+			ExpiresAt: jwt.NewNumericDate(now.Add(duration)),
 			NotBefore: jwt.NewNumericDate(now),
 		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(m.secretKey))
+	signed, err := token.SignedString([]byte(m.secretKey))
+	if err != nil {
+		return "", "", err
+	}
+	return signed, claims.ID, nil
 }
 
-func (m *Manager) GenerateRefreshToken(userID string) (string, error) {
-	now := time.Now()
-	claims := jwt.RegisteredClaims{
-		ID:        uuid.New().String(),
-		Subject:   userID,
-		IssuedAt:  jwt.NewNumericDate(now),
-		ExpiresAt: jwt.NewNumericDate(now.Add(m.refreshTokenDuration)),
-		NotBefore: jwt.NewNumericDate(now),
-	}
+// RefreshTokenDuration returns the lifetime of issued refresh tokens.
+func (m *Manager) RefreshTokenDuration() time.Duration {
+	return m.refreshTokenDuration
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(m.secretKey))
+// RememberMeRefreshTokenDuration returns the lifetime of refresh tokens
+// issued for "remember me" logins.
+func (m *Manager) RememberMeRefreshTokenDuration() time.Duration {
+	if m.rememberMeRefreshTokenDuration <= 0 {
+		return m.refreshTokenDuration
+	}
+	return m.rememberMeRefreshTokenDuration
 }
 
-func (m *Manager) ValidateAccessToken(tokenString string) (*Claims, error) {
+// ValidateAccessToken verifies an access token and returns its claims. With
+// an EpochStore, tokens issued before the user's current epoch are rejected.
+func (m *Manager) ValidateAccessToken(ctx context.Context, tokenString string) (*Claims, error) {
+	var opts []jwt.ParserOption
+	if m.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(m.issuer))
+	}
+	if m.audience != "" {
+		opts = append(opts, jwt.WithAudience(m.audience))
+	}
+
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, ErrInvalidSigningMethod
 		}
 		return []byte(m.secretKey), nil
-	})
+	}, opts...)
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
@@ -89,11 +262,35 @@ func (m *Manager) ValidateAccessToken(tokenString string) (*Claims, error) {
 		return nil, ErrInvalidToken
 	}
 
+	if err := m.checkEpoch(ctx, claims); err != nil {
+		return nil, err
+	}
+
 	return claims, nil
 }
 
-func (m *Manager) ValidateRefreshToken(tokenString string) (string, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &jwt.RegisteredClaims{}, func(token *jwt.Token) (interface{}, error) {
+// checkEpoch rejects claims issued before the user's current token epoch.
+func (m *Manager) checkEpoch(ctx context.Context, claims *Claims) error {
+	if m.epochs == nil {
+		return nil
+	}
+
+	epoch, err := m.epochs.TokenEpoch(ctx, claims.UserID)
+	if err != nil {
+		if m.epochFailOpen {
+			return nil
+		}
+		return fmt.Errorf("%w: %v", ErrEpochUnavailable, err)
+	}
+	if claims.Epoch < epoch {
+		return ErrRevokedToken
+	}
+	return nil
+}
+
+// ValidateRefreshToken verifies a refresh token and returns its claims.
+func (m *Manager) ValidateRefreshToken(tokenString string) (*RefreshClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &refreshTokenClaims{}, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, ErrInvalidSigningMethod
 		}
@@ -102,21 +299,26 @@ func (m *Manager) ValidateRefreshToken(tokenString string) (string, error) {
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
-			return "", ErrExpiredToken
+			return nil, ErrExpiredToken
 		}
-		return "", fmt.Errorf("%w: %v", ErrInvalidToken, err)
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
 	}
 
-	claims, ok := token.Claims.(*jwt.RegisteredClaims)
-	if !ok || !token.Valid {
-		return "", ErrInvalidToken
+	claims, ok := token.Claims.(*refreshTokenClaims)
+	if !ok || !token.Valid || claims.IssuedAt == nil || claims.ExpiresAt == nil {
+		return nil, ErrInvalidToken
 	}
 
-	return claims.Subject, nil
+	return &RefreshClaims{
+		UserID:    claims.Subject,
+		TokenID:   claims.ID,
+		SessionID: claims.SessionID,
+		Lifetime:  claims.ExpiresAt.Sub(claims.IssuedAt.Time),
+	}, nil
 }
 
-func (m *Manager) ExtractUserID(tokenString string) (string, error) {
-	claims, err := m.ValidateAccessToken(tokenString)
+func (m *Manager) ExtractUserID(ctx context.Context, tokenString string) (string, error) {
+	claims, err := m.ValidateAccessToken(ctx, tokenString)
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,31 @@
+package logger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying l, retrieved with FromContext.
+func NewContext(ctx context.Context, l *zap.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// WithContext returns a copy of ctx whose logger has fields added, so every
+// later FromContext(ctx) call logs them.
+func WithContext(ctx context.Context, fields ...zap.Field) context.Context {
+	return NewContext(ctx, FromContext(ctx).With(fields...))
+}
+
+// FromContext returns the request-scoped logger stored in ctx, tagged with
+// fields such as the request ID, or the global logger when there is none.
+func FromContext(ctx context.Context) *zap.Logger {
+	if l, ok := ctx.Value(contextKey{}).(*zap.Logger); ok {
+		return l
+	}
+	// The global logger skips one caller frame for the package-level helpers;
+	// callers of FromContext log through it directly.
+	return log.WithOptions(zap.AddCallerSkip(-1))
+}
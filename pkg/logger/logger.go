@@ -8,12 +8,18 @@ import (
 	"go.uber.org/zap/zapcore"
 )
 
-var log *zap.Logger
+var (
+	log   = zap.NewNop()
+	level = zap.NewAtomicLevel()
+)
 
 type Config struct {
 	Level  string
 	Format string
-	Output string
+	// Output lists where entries are written: "stdout", "stderr" or file
+	// paths, which are rotated according to Rotation. Empty means stdout.
+	Output   []string
+	Rotation Rotation
 }
 
 func Init(cfg Config) error {
@@ -26,36 +32,77 @@ func Init(cfg Config) error {
 	}
 
 	// Set log level
-	level := zapcore.InfoLevel
-	switch cfg.Level {
+	SetLevel(cfg.Level)
+
+	newEncoder := func() zapcore.Encoder {
+		if config.Encoding == "json" {
+			return zapcore.NewJSONEncoder(config.EncoderConfig)
+		}
+		return zapcore.NewConsoleEncoder(config.EncoderConfig)
+	}
+
+	core, err := newCore(newEncoder, cfg.Output, cfg.Rotation, level)
+	if err != nil {
+		return err
+	}
+	if config.Sampling != nil {
+		core = zapcore.NewSamplerWithOptions(core, time.Second, config.Sampling.Initial, config.Sampling.Thereafter)
+	}
+
+	opts := []zap.Option{
+		zap.ErrorOutput(zapcore.Lock(os.Stderr)),
+		zap.AddCaller(),
+		zap.AddCallerSkip(1),
+		zap.AddStacktrace(zapcore.ErrorLevel),
+	}
+	if config.Development {
+		opts = append(opts, zap.Development())
+	}
+
+	log = zap.New(core, opts...)
+
+	return nil
+}
+
+// SetLevel changes the level of the logger built by Init, also after it is
+// built. Unknown levels mean info.
+func SetLevel(name string) {
+	level.SetLevel(parseLevel(name))
+}
+
+func parseLevel(name string) zapcore.Level {
+	switch name {
 	case "debug":
-		level = zapcore.DebugLevel
-	case "info":
-		level = zapcore.InfoLevel
+		return zapcore.DebugLevel
 	case "warn":
-		level = zapcore.WarnLevel
+		return zapcore.WarnLevel
 	case "error":
-		level = zapcore.ErrorLevel
+		return zapcore.ErrorLevel
 	case "fatal":
-		level = zapcore.FatalLevel
+		return zapcore.FatalLevel
+	default:
+		return zapcore.InfoLevel
 	}
-	config.Level = zap.NewAtomicLevelAt(level)
+}
+
+// NewJSONLogger returns a logger that writes flat JSON lines to outputs, as
+// in Config.Output, regardless of the configured format. It omits caller and
+// stack trace fields, which suits access logs.
+func NewJSONLogger(outputs []string, rotation Rotation) (*zap.Logger, error) {
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.TimeKey = "time"
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
 
-	// Set output
-	if cfg.Output != "" && cfg.Output != "stdout" {
-		config.OutputPaths = []string{cfg.Output}
+	newEncoder := func() zapcore.Encoder {
+		return zapcore.NewJSONEncoder(encoderConfig)
 	}
 
-	var err error
-	log, err = config.Build(
-		zap.AddCallerSkip(1),
-		zap.AddStacktrace(zapcore.ErrorLevel),
-	)
+	core, err := newCore(newEncoder, outputs, rotation, zapcore.InfoLevel)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return nil
+	return zap.New(core, zap.ErrorOutput(zapcore.Lock(os.Stderr))), nil
 }
 
 func Sync() error {
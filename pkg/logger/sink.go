@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Rotation bounds the files written by file outputs. Zero values keep
+// lumberjack's defaults: 100 MB files, kept forever.
+type Rotation struct {
+	// MaxSizeMB is the size in megabytes at which a file is rotated.
+	MaxSizeMB int
+	// MaxAgeDays removes rotated files older than this many days.
+	MaxAgeDays int
+	// MaxBackups is how many rotated files are kept.
+	MaxBackups int
+}
+
+var (
+	filesMu sync.Mutex
+	// files holds one rotating writer per path, shared by every logger
+	// writing there, because separate writers would rotate the same file
+	// from under each other.
+	files = map[string]*lumberjack.Logger{}
+)
+
+// newCore returns a core writing entries encoded by newEncoder to each of
+// outputs, "stdout", "stderr" or a file path, at the levels enabled by
+// enabler. No outputs means stdout.
+func newCore(newEncoder func() zapcore.Encoder, outputs []string, rotation Rotation, enabler zapcore.LevelEnabler) (zapcore.Core, error) {
+	if len(outputs) == 0 {
+		outputs = []string{"stdout"}
+	}
+
+	cores := make([]zapcore.Core, 0, len(outputs))
+	for _, output := range outputs {
+		sink, err := openSink(output, rotation)
+		if err != nil {
+			return nil, err
+		}
+		cores = append(cores, zapcore.NewCore(newEncoder(), sink, enabler))
+	}
+
+	return zapcore.NewTee(cores...), nil
+}
+
+func openSink(output string, rotation Rotation) (zapcore.WriteSyncer, error) {
+	switch output {
+	case "stdout":
+		return zapcore.Lock(os.Stdout), nil
+	case "stderr":
+		return zapcore.Lock(os.Stderr), nil
+	}
+
+	path, err := filepath.Abs(output)
+	if err != nil {
+		return nil, fmt.Errorf("invalid log output %q: %w", output, err)
+	}
+
+	filesMu.Lock()
+	defer filesMu.Unlock()
+
+	if file, ok := files[path]; ok {
+		return zapcore.AddSync(file), nil
+	}
+
+	file := &lumberjack.Logger{
+		Filename:   path,
+		MaxSize:    rotation.MaxSizeMB,
+		MaxAge:     rotation.MaxAgeDays,
+		MaxBackups: rotation.MaxBackups,
+	}
+	// lumberjack opens the file on the first write; an empty one surfaces an
+	// unwritable path now rather than on the first log entry.
+	if _, err := file.Write(nil); err != nil {
+		return nil, fmt.Errorf("failed to open log output %q: %w", output, err)
+	}
+	files[path] = file
+
+	return zapcore.AddSync(file), nil
+}
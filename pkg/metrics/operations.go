@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+)
+
+// Outcomes an operation is labeled with.
+const (
+	OutcomeOK    = "ok"
+	OutcomeError = "error"
+)
+
+// Operations records how long database and cache operations take in the
+// operation_duration_seconds histogram, labeled by operation, such as
+// "get_by_id" or "cache_get", and outcome. A nil *Operations records nothing,
+// so instrumented code runs unchanged while metrics are disabled.
+type Operations struct {
+	duration *prometheus.HistogramVec
+}
+
+// NewOperations returns Operations registered with reg.
+func NewOperations(reg prometheus.Registerer) (*Operations, error) {
+	duration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "operation_duration_seconds",
+		Help:    "Duration of database and cache operations.",
+		Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+	}, []string{"operation", "outcome"})
+	if err := reg.Register(duration); err != nil {
+		return nil, err
+	}
+
+	return &Operations{duration: duration}, nil
+}
+
+// Observe records operation as having run since start, failing when err is
+// not nil.
+func (o *Operations) Observe(operation string, start time.Time, err error) {
+	if o == nil {
+		return
+	}
+
+	outcome := OutcomeOK
+	if err != nil {
+		outcome = OutcomeError
+	}
+	o.duration.WithLabelValues(operation, outcome).Observe(time.Since(start).Seconds())
+}
+
+// RedisHook returns a redis.Hook recording every command as "cache_<name>",
+// such as cache_get, and every pipeline as cache_pipeline. Cache misses
+// (redis.Nil) are not errors.
+func (o *Operations) RedisHook() redis.Hook {
+	return redisHook{operations: o}
+}
+
+type redisHook struct {
+	operations *Operations
+}
+
+func (h redisHook) DialHook(next redis.DialHook) redis.DialHook {
+	return next
+}
+
+func (h redisHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmd)
+		h.operations.Observe("cache_"+strings.ToLower(cmd.Name()), start, redisError(err))
+		return err
+	}
+}
+
+func (h redisHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		start := time.Now()
+		err := next(ctx, cmds)
+		h.operations.Observe("cache_pipeline", start, redisError(err))
+		return err
+	}
+}
+
+func redisError(err error) error {
+	if errors.Is(err, redis.Nil) {
+		return nil
+	}
+	return err
+}
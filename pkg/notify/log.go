@@ -0,0 +1,28 @@
+package notify
+
+import (
+	"context"
+
+	"github.com/TubagusAldiMY/go-template/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// Log writes notifications to the log instead of delivering them, so links
+// in emails can be followed during development without a mail server.
+type Log struct{}
+
+func NewLog() *Log {
+	return &Log{}
+}
+
+func (*Log) Send(ctx context.Context, n Notification) error {
+	if n.To == "" {
+		return ErrNoRecipient
+	}
+	logger.FromContext(ctx).Info("notification",
+		zap.String("to", n.To),
+		zap.String("subject", n.Subject),
+		zap.String("body", n.Body),
+	)
+	return nil
+}
@@ -0,0 +1,80 @@
+// Package notify sends notifications such as emails to users behind a small
+// interface, with an SMTP sender, a log-only sender for development and a
+// queue sender that hands notifications to a background worker.
+package notify
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"text/template"
+)
+
+// ErrNoRecipient is returned by Send for a notification without a recipient.
+var ErrNoRecipient = errors.New("notification has no recipient")
+
+// Notification is a plain-text message to a single recipient.
+type Notification struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// Notifier sends notifications. Implementations must be safe for concurrent
+// use.
+type Notifier interface {
+	Send(ctx context.Context, n Notification) error
+}
+
+// Discard drops every notification. It stands in when no Notifier is
+// configured.
+var Discard Notifier = discard{}
+
+type discard struct{}
+
+func (discard) Send(context.Context, Notification) error { return nil }
+
+// Template renders the subject and body of a notification with text/template.
+// Data fields the templates reference but data lacks are an error, so a typo
+// surfaces instead of sending an email with a blank in it.
+type Template struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+// NewTemplate parses the subject and body templates.
+func NewTemplate(name, subject, body string) (*Template, error) {
+	subjectTmpl, err := template.New(name + ".subject").Option("missingkey=error").Parse(subject)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s subject: %w", name, err)
+	}
+	bodyTmpl, err := template.New(name + ".body").Option("missingkey=error").Parse(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s body: %w", name, err)
+	}
+	return &Template{subject: subjectTmpl, body: bodyTmpl}, nil
+}
+
+// MustTemplate is like NewTemplate but panics on a parse error. It is meant
+// for templates compiled into the binary.
+func MustTemplate(name, subject, body string) *Template {
+	t, err := NewTemplate(name, subject, body)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Render executes the templates with data and returns the notification to
+// send to the recipient.
+func (t *Template) Render(to string, data interface{}) (Notification, error) {
+	var subject, body bytes.Buffer
+	if err := t.subject.Execute(&subject, data); err != nil {
+		return Notification{}, fmt.Errorf("failed to render subject: %w", err)
+	}
+	if err := t.body.Execute(&body, data); err != nil {
+		return Notification{}, fmt.Errorf("failed to render body: %w", err)
+	}
+	return Notification{To: to, Subject: subject.String(), Body: body.String()}, nil
+}
@@ -0,0 +1,54 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Publisher publishes a message body to an exchange. *messaging.RabbitMQ
+// implements it.
+type Publisher interface {
+	Publish(ctx context.Context, exchange, routingKey string, body []byte) error
+}
+
+// Queue sends notifications asynchronously by publishing them to a queue,
+// from which a worker decodes them with Decode and hands them to the Notifier
+// that delivers them. Send returns once the broker has the message, so
+// delivery failures are only seen by the worker.
+type Queue struct {
+	publisher Publisher
+	queue     string
+}
+
+// NewQueue returns a Queue publishing to queue through the default exchange,
+// which routes by queue name.
+func NewQueue(publisher Publisher, queue string) *Queue {
+	return &Queue{publisher: publisher, queue: queue}
+}
+
+func (q *Queue) Send(ctx context.Context, n Notification) error {
+	if n.To == "" {
+		return ErrNoRecipient
+	}
+	body, err := json.Marshal(n)
+	if err != nil {
+		return fmt.Errorf("failed to encode notification: %w", err)
+	}
+	if err := q.publisher.Publish(ctx, "", q.queue, body); err != nil {
+		return fmt.Errorf("failed to queue notification: %w", err)
+	}
+	return nil
+}
+
+// Decode parses a message body published by Queue.
+func Decode(body []byte) (Notification, error) {
+	var n Notification
+	if err := json.Unmarshal(body, &n); err != nil {
+		return Notification{}, fmt.Errorf("failed to decode notification: %w", err)
+	}
+	if n.To == "" {
+		return Notification{}, ErrNoRecipient
+	}
+	return n, nil
+}
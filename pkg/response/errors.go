@@ -0,0 +1,29 @@
+package response
+
+import (
+	"net/http"
+
+	sharedErrors "github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/TubagusAldiMY/go-template/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// FromError writes the error response for err: the status and message of
+// the first AppError in its chain that has a status, with its code from
+// sharedErrors.Code. Anything else, including internal AppErrors such as
+// ErrInternal, is logged and answered with a generic 500 so internal details
+// never reach the client.
+func FromError(c *gin.Context, err error) {
+	if appErr := sharedErrors.StatusError(err); appErr != nil && appErr.Status != http.StatusInternalServerError {
+		ErrorWithCode(c, appErr.Status, sharedErrors.Code(err), appErr.Message, nil)
+		return
+	}
+
+	logger.FromContext(c.Request.Context()).Error("request failed",
+		zap.String("method", c.Request.Method),
+		zap.String("route", c.FullPath()),
+		zap.Error(err),
+	)
+	ErrorWithCode(c, http.StatusInternalServerError, sharedErrors.CodeInternal, "Internal server error", nil)
+}
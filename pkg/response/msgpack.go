@@ -0,0 +1,57 @@
+package response
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/ugorji/go/codec"
+)
+
+// msgpackHandle encodes responses as MessagePack. Field names come from the
+// msgpack tag, falling back to the json tag, so DTOs tagged only for JSON
+// keep the same keys in both formats. Times use the MessagePack timestamp
+// extension.
+var msgpackHandle = func() *codec.MsgpackHandle {
+	h := &codec.MsgpackHandle{WriteExt: true}
+	h.TypeInfos = codec.NewTypeInfos([]string{"msgpack", "json"})
+	return h
+}()
+
+// write sends body with statusCode in the format negotiated from the Accept
+// header: MessagePack when the client asks for application/msgpack or
+// application/x-msgpack before JSON, JSON otherwise.
+func write(c *gin.Context, statusCode int, body Response) {
+	c.Writer.Header().Add("Vary", "Accept")
+
+	switch format := negotiateFormat(c); format {
+	case binding.MIMEMSGPACK2, binding.MIMEMSGPACK:
+		c.Render(statusCode, msgpackRender{contentType: format, data: body})
+	default:
+		c.JSON(statusCode, body)
+	}
+}
+
+// negotiateFormat returns the first of the supported MIME types listed in
+// the Accept header, JSON when there is none.
+func negotiateFormat(c *gin.Context) string {
+	if c.Request == nil {
+		return binding.MIMEJSON
+	}
+	return c.NegotiateFormat(binding.MIMEJSON, binding.MIMEMSGPACK2, binding.MIMEMSGPACK)
+}
+
+// msgpackRender is a gin renderer using msgpackHandle.
+type msgpackRender struct {
+	contentType string
+	data        interface{}
+}
+
+func (r msgpackRender) Render(w http.ResponseWriter) error {
+	r.WriteContentType(w)
+	return codec.NewEncoder(w, msgpackHandle).Encode(r.data)
+}
+
+func (r msgpackRender) WriteContentType(w http.ResponseWriter) {
+	w.Header()["Content-Type"] = []string{r.contentType}
+}
@@ -1,28 +1,39 @@
 package response
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
 type Response struct {
-	Success bool        `json:"success"`
-	Message string      `json:"message,omitempty"`
-	Data    interface{} `json:"data,omitempty"`
-	Errors  interface{} `json:"errors,omitempty"`
-	Meta    *Meta       `json:"meta,omitempty"`
+	Success bool   `json:"success" msgpack:"success"`
+	Message string `json:"message,omitempty" msgpack:"message,omitempty"`
+	// Code is a stable machine-readable error code, such as
+	// EMAIL_ALREADY_EXISTS. It is omitted on success and on errors that do
+	// not carry one.
+	Code   string      `json:"code,omitempty" msgpack:"code,omitempty"`
+	Data   interface{} `json:"data,omitempty" msgpack:"data,omitempty"`
+	Errors interface{} `json:"errors,omitempty" msgpack:"errors,omitempty"`
+	Meta   *Meta       `json:"meta,omitempty" msgpack:"meta,omitempty"`
 }
 
 type Meta struct {
-	Page       int   `json:"page,omitempty"`
-	PageSize   int   `json:"page_size,omitempty"`
-	TotalItems int64 `json:"total_items,omitempty"`
-	TotalPages int   `json:"total_pages,omitempty"`
+	Page       int   `json:"page,omitempty" msgpack:"page,omitempty"`
+	PageSize   int   `json:"page_size,omitempty" msgpack:"page_size,omitempty"`
+	TotalItems int64 `json:"total_items,omitempty" msgpack:"total_items,omitempty"`
+	TotalPages int   `json:"total_pages,omitempty" msgpack:"total_pages,omitempty"`
+	// HasNext and HasPrev are omitted when the paging mode cannot tell.
+	HasNext *bool `json:"has_next,omitempty" msgpack:"has_next,omitempty"`
+	HasPrev *bool `json:"has_prev,omitempty" msgpack:"has_prev,omitempty"`
+	// NextCursor is set in cursor pagination mode; empty on the last page.
+	NextCursor string `json:"next_cursor,omitempty" msgpack:"next_cursor,omitempty"`
 }
 
 func Success(c *gin.Context, statusCode int, message string, data interface{}) {
-	c.JSON(statusCode, Response{
+	write(c, statusCode, Response{
 		Success: true,
 		Message: message,
 		Data:    data,
@@ -30,7 +41,7 @@ func Success(c *gin.Context, statusCode int, message string, data interface{}) {
 }
 
 func SuccessWithMeta(c *gin.Context, message string, data interface{}, meta *Meta) {
-	c.JSON(http.StatusOK, Response{
+	write(c, http.StatusOK, Response{
 		Success: true,
 		Message: message,
 		Data:    data,
@@ -38,6 +49,12 @@ func SuccessWithMeta(c *gin.Context, message string, data interface{}, meta *Met
 	})
 }
 
+// Paginated responds 200 with data and the offset pagination metadata for
+// the given page.
+func Paginated(c *gin.Context, message string, data interface{}, page, pageSize int, total int64) {
+	SuccessWithMeta(c, message, data, NewMeta(page, pageSize, total))
+}
+
 func Created(c *gin.Context, message string, data interface{}) {
 	Success(c, http.StatusCreated, message, data)
 }
@@ -51,9 +68,16 @@ func NoContent(c *gin.Context) {
 }
 
 func Error(c *gin.Context, statusCode int, message string, errors interface{}) {
-	c.JSON(statusCode, Response{
+	ErrorWithCode(c, statusCode, "", message, errors)
+}
+
+// ErrorWithCode is Error with a machine-readable code for clients to key
+// translations and handling off.
+func ErrorWithCode(c *gin.Context, statusCode int, code, message string, errors interface{}) {
+	write(c, statusCode, Response{
 		Success: false,
 		Message: message,
+		Code:    code,
 		Errors:  errors,
 	})
 }
@@ -82,6 +106,26 @@ func UnprocessableEntity(c *gin.Context, message string, errors interface{}) {
 	Error(c, http.StatusUnprocessableEntity, message, errors)
 }
 
+func RequestEntityTooLarge(c *gin.Context, message string) {
+	Error(c, http.StatusRequestEntityTooLarge, message, nil)
+}
+
+// InvalidBody responds to a request body that could not be read or bound:
+// 413 when it exceeded the size limit, 400 otherwise.
+func InvalidBody(c *gin.Context, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		RequestEntityTooLarge(c, fmt.Sprintf("Request body must not exceed %d bytes", tooLarge.Limit))
+		return
+	}
+
+	BadRequest(c, "Invalid request body", err.Error())
+}
+
+func TooManyRequests(c *gin.Context, message string, errors interface{}) {
+	Error(c, http.StatusTooManyRequests, message, errors)
+}
+
 func InternalServerError(c *gin.Context, message string) {
 	Error(c, http.StatusInternalServerError, message, nil)
 }
@@ -91,15 +135,25 @@ func ServiceUnavailable(c *gin.Context, message string) {
 }
 
 func NewMeta(page, pageSize int, totalItems int64) *Meta {
-	totalPages := int(totalItems) / pageSize
-	if int(totalItems)%pageSize != 0 {
-		totalPages++
-	}
+	totalPages := TotalPages(pageSize, totalItems)
+	hasNext := page < totalPages
+	hasPrev := page > 1
 
 	return &Meta{
 		Page:       page,
 		PageSize:   pageSize,
 		TotalItems: totalItems,
 		TotalPages: totalPages,
+		HasNext:    &hasNext,
+		HasPrev:    &hasPrev,
+	}
+}
+
+// TotalPages returns how many pages of pageSize items hold totalItems. It is
+// zero when pageSize is not positive.
+func TotalPages(pageSize int, totalItems int64) int {
+	if pageSize <= 0 {
+		return 0
 	}
+	return int((totalItems + int64(pageSize) - 1) / int64(pageSize))
 }
@@ -0,0 +1,53 @@
+// Package retry repeats failing operations with exponential backoff.
+package retry
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// MaxBackoff caps the wait between two attempts.
+const MaxBackoff = 30 * time.Second
+
+// Do calls fn until it succeeds or has been called attempts times, waiting
+// backoff before the first retry and doubling the wait before each further
+// one, up to MaxBackoff. Every failed attempt that is retried is logged at warn
+// level through the logger in ctx. Do returns the last error from fn, also
+// when ctx is done while waiting. Attempts below 1 are treated as 1.
+func Do(ctx context.Context, attempts int, backoff time.Duration, fn func() error) error {
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	wait := backoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if attempt == attempts {
+			return err
+		}
+
+		logger.FromContext(ctx).Warn("attempt failed, retrying",
+			zap.Int("attempt", attempt),
+			zap.Int("max_attempts", attempts),
+			zap.Duration("backoff", wait),
+			zap.Error(err),
+		)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%w (retry aborted: %v)", err, ctx.Err())
+		case <-timer.C:
+		}
+
+		wait = min(wait*2, MaxBackoff)
+	}
+}
@@ -0,0 +1,103 @@
+// Package scheduler runs background jobs at fixed intervals.
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/pkg/logger"
+	"go.uber.org/zap"
+)
+
+// JobFunc is the work of a job. ctx is cancelled when the scheduler stops.
+type JobFunc func(ctx context.Context) error
+
+type job struct {
+	name     string
+	interval time.Duration
+	fn       JobFunc
+	running  atomic.Bool
+}
+
+// Scheduler runs registered jobs every interval until its context is
+// cancelled. A run that is due while the previous run of the same job is
+// still in progress is skipped, and a panicking job is recovered and logged,
+// so one job cannot stall or crash the others.
+type Scheduler struct {
+	jobs []*job
+	wg   sync.WaitGroup
+}
+
+func New() *Scheduler {
+	return &Scheduler{}
+}
+
+// Register adds a job that runs every interval, the first time one interval
+// after Start. Jobs must be registered before Start.
+func (s *Scheduler) Register(name string, interval time.Duration, fn JobFunc) {
+	s.jobs = append(s.jobs, &job{name: name, interval: interval, fn: fn})
+}
+
+// Start runs the registered jobs in the background until ctx is cancelled.
+// Call Wait to block until the runs in progress have returned.
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go s.loop(ctx, j)
+	}
+}
+
+// Wait blocks until Start's context is cancelled and every job run has
+// returned.
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+func (s *Scheduler) loop(ctx context.Context, j *job) {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if !j.running.CompareAndSwap(false, true) {
+			logger.Warn("skipping job run, previous run still in progress", zap.String("job", j.name))
+			continue
+		}
+
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer j.running.Store(false)
+			s.run(ctx, j)
+		}()
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, j *job) {
+	ctx = logger.WithContext(ctx, zap.String("job", j.name))
+	start := time.Now()
+
+	defer func() {
+		if r := recover(); r != nil {
+			logger.FromContext(ctx).Error("job panicked",
+				zap.Any("error", r),
+				zap.Stack("stack"),
+			)
+		}
+	}()
+
+	if err := j.fn(ctx); err != nil {
+		logger.FromContext(ctx).Error("job failed", zap.Duration("duration", time.Since(start)), zap.Error(err))
+		return
+	}
+	logger.FromContext(ctx).Debug("job finished", zap.Duration("duration", time.Since(start)))
+}
@@ -0,0 +1,48 @@
+// Package storage stores uploaded files behind a small interface with a
+// local filesystem and an S3-compatible implementation.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrNotFound is returned by Get when no object is stored under the key.
+var ErrNotFound = errors.New("object not found")
+
+// Storage stores objects under slash-separated keys such as
+// "avatars/<user>/<name>.png".
+type Storage interface {
+	// Put stores size bytes read from r under key, replacing any existing
+	// object.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get opens the object stored under key. The caller closes it.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object under key. Deleting a missing key is not an
+	// error.
+	Delete(ctx context.Context, key string) error
+	// URL returns the public URL of key. It is the base URL followed by
+	// "/" and the key, so URL("") is the prefix shared by every object.
+	URL(key string) string
+}
+
+// validateKey rejects keys that are empty or could escape the storage root.
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return fmt.Errorf("invalid storage key %q", key)
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return fmt.Errorf("invalid storage key %q", key)
+		}
+	}
+	return nil
+}
+
+// joinURL appends key to base with exactly one slash between them.
+func joinURL(base, key string) string {
+	return strings.TrimRight(base, "/") + "/" + key
+}
@@ -0,0 +1,67 @@
+// Package totp wraps RFC 6238 time-based one-time passwords as used by
+// authenticator apps: 6 digits, 30 second period, SHA-1.
+package totp
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+)
+
+// Key is a newly generated TOTP secret.
+type Key struct {
+	// Secret is the base32-encoded shared secret.
+	Secret string
+	// URI is the otpauth:// provisioning URI, usually shown as a QR code.
+	URI string
+}
+
+// Generate creates a random secret for account, labelled with issuer in
+// authenticator apps.
+func Generate(issuer, account string) (*Key, error) {
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      issuer,
+		AccountName: account,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate totp key: %w", err)
+	}
+
+	return &Key{Secret: key.Secret(), URI: key.URL()}, nil
+}
+
+// period is the lifetime of a code in seconds.
+const period = 30
+
+// Validate reports whether code is valid for secret now, allowing one period
+// of clock skew either way.
+func Validate(code, secret string) bool {
+	_, valid := ValidateStep(code, secret)
+	return valid
+}
+
+// ValidateStep is Validate that also returns the time step, the number of
+// periods since the Unix epoch, that code belongs to. A code stays valid for
+// three steps, so callers record the step to refuse the code a second time.
+func ValidateStep(code, secret string) (int64, bool) {
+	now := time.Now().UTC()
+	for _, skew := range []int64{0, -1, 1} {
+		at := now.Add(time.Duration(skew*period) * time.Second)
+		valid, err := totp.ValidateCustom(code, secret, at, totp.ValidateOpts{
+			Period:    period,
+			Digits:    otp.DigitsSix,
+			Algorithm: otp.AlgorithmSHA1,
+		})
+		if err == nil && valid {
+			return at.Unix() / period, true
+		}
+	}
+	return 0, false
+}
+
+// GenerateCode returns the code for secret at t.
+func GenerateCode(secret string, t time.Time) (string, error) {
+	return totp.GenerateCode(secret, t)
+}
@@ -0,0 +1,97 @@
+package validator
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// disposableDomainList holds one throwaway email domain per line.
+//
+//go:embed disposable_domains.txt
+var disposableDomainList string
+
+// DisposableDomains is a set of throwaway email domains.
+type DisposableDomains struct {
+	domains map[string]struct{}
+}
+
+// DefaultDisposableDomains returns the embedded list of well-known throwaway
+// email domains.
+func DefaultDisposableDomains() *DisposableDomains {
+	domains, _ := ParseDisposableDomains(strings.NewReader(disposableDomainList))
+	return domains
+}
+
+// LoadDisposableDomains reads the list from path in the embedded list's
+// format, replacing it entirely.
+func LoadDisposableDomains(path string) (*DisposableDomains, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open disposable email domains: %w", err)
+	}
+	defer f.Close()
+
+	domains, err := ParseDisposableDomains(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read disposable email domains: %w", err)
+	}
+	return domains, nil
+}
+
+// ParseDisposableDomains reads one domain per line. Blank lines and lines
+// starting with # are skipped; domains are matched case-insensitively.
+func ParseDisposableDomains(r io.Reader) (*DisposableDomains, error) {
+	d := &DisposableDomains{domains: make(map[string]struct{})}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		d.domains[normalizeDomain(line)] = struct{}{}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// Len returns the number of listed domains.
+func (d *DisposableDomains) Len() int {
+	return len(d.domains)
+}
+
+// IsDisposable reports whether the domain of email is listed, or is a
+// subdomain of a listed one, so "x@eu.mailinator.com" matches
+// "mailinator.com". Addresses without a domain report false.
+func (d *DisposableDomains) IsDisposable(email string) bool {
+	at := strings.LastIndexByte(email, '@')
+	if at < 0 {
+		return false
+	}
+
+	domain := normalizeDomain(email[at+1:])
+	for domain != "" {
+		if _, ok := d.domains[domain]; ok {
+			return true
+		}
+		dot := strings.IndexByte(domain, '.')
+		if dot < 0 {
+			break
+		}
+		domain = domain[dot+1:]
+	}
+	return false
+}
+
+// normalizeDomain lowercases domain and drops the trailing dot of a fully
+// qualified name.
+func normalizeDomain(domain string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+}
@@ -0,0 +1,145 @@
+package validator
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// specialCharacters are the characters that satisfy RequireSpecial.
+const specialCharacters = `!@#$%^&*(),.?":{}|<>`
+
+// PasswordPolicy is the set of rules the "password" tag enforces.
+type PasswordPolicy struct {
+	// MinLength and MaxLength bound the length in characters. A zero
+	// MaxLength allows any length.
+	MinLength int
+	MaxLength int
+
+	RequireUppercase bool
+	RequireLowercase bool
+	RequireDigit     bool
+	RequireSpecial   bool
+
+	// DisallowCommon rejects passwords found, case-insensitively, in the
+	// embedded list of commonly used passwords.
+	DisallowCommon bool
+}
+
+// DefaultPasswordPolicy is the policy used by Init: at least 8 characters
+// with an uppercase letter, a lowercase letter, a digit and a special
+// character.
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:        8,
+		RequireUppercase: true,
+		RequireLowercase: true,
+		RequireDigit:     true,
+		RequireSpecial:   true,
+	}
+}
+
+// Allows reports whether password satisfies every rule of p.
+func (p PasswordPolicy) Allows(password string) bool {
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength || (p.MaxLength > 0 && length > p.MaxLength) {
+		return false
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSpecial bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case strings.ContainsRune(specialCharacters, r):
+			hasSpecial = true
+		}
+	}
+
+	switch {
+	case p.RequireUppercase && !hasUpper,
+		p.RequireLowercase && !hasLower,
+		p.RequireDigit && !hasDigit,
+		p.RequireSpecial && !hasSpecial:
+		return false
+	}
+
+	return !p.DisallowCommon || !isCommonPassword(password)
+}
+
+// Describe explains the policy to users, for validation error messages.
+func (p PasswordPolicy) Describe() string {
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "password must be at least %d characters", p.MinLength)
+	if p.MaxLength > 0 {
+		fmt.Fprintf(&msg, " and at most %d characters", p.MaxLength)
+	}
+
+	var classes []string
+	if p.RequireUppercase {
+		classes = append(classes, "uppercase")
+	}
+	if p.RequireLowercase {
+		classes = append(classes, "lowercase")
+	}
+	if p.RequireDigit {
+		classes = append(classes, "digit")
+	}
+	if p.RequireSpecial {
+		classes = append(classes, "special character")
+	}
+	if len(classes) > 0 {
+		msg.WriteString(" and contain ")
+		msg.WriteString(joinList(classes))
+	}
+
+	if p.DisallowCommon {
+		msg.WriteString(", and must not be a commonly used password")
+	}
+
+	return msg.String()
+}
+
+// joinList joins items as "a, b, and c".
+func joinList(items []string) string {
+	switch len(items) {
+	case 1:
+		return items[0]
+	case 2:
+		return items[0] + " and " + items[1]
+	default:
+		return strings.Join(items[:len(items)-1], ", ") + ", and " + items[len(items)-1]
+	}
+}
+
+// commonPasswordList holds one commonly used password per line.
+//
+//go:embed common_passwords.txt
+var commonPasswordList string
+
+var (
+	commonPasswordsOnce sync.Once
+	commonPasswords     map[string]struct{}
+)
+
+func isCommonPassword(password string) bool {
+	commonPasswordsOnce.Do(func() {
+		lines := strings.Split(commonPasswordList, "\n")
+		commonPasswords = make(map[string]struct{}, len(lines))
+		for _, line := range lines {
+			if line = strings.TrimSpace(line); line != "" {
+				commonPasswords[strings.ToLower(line)] = struct{}{}
+			}
+		}
+	})
+
+	_, found := commonPasswords[strings.ToLower(password)]
+	return found
+}
@@ -2,18 +2,50 @@ package validator
 
 import (
 	"fmt"
+	"reflect"
 	"regexp"
 	"strings"
+	"time"
 
+	"github.com/TubagusAldiMY/go-template/internal/shared/constants"
+	"github.com/TubagusAldiMY/go-template/pkg/authz"
 	"github.com/go-playground/validator/v10"
 )
 
-var validate *validator.Validate
+var (
+	validate       *validator.Validate
+	passwordPolicy = DefaultPasswordPolicy()
+
+	// localePattern matches BCP-47 tags made of a language, an optional
+	// script and an optional region, such as "en", "id-ID" or "zh-Hant-TW".
+	localePattern = regexp.MustCompile(`^[a-zA-Z]{2,3}(-[a-zA-Z]{4})?(-([a-zA-Z]{2}|[0-9]{3}))?$`)
+
+	// enums maps tags to the values they accept, read from where the values
+	// are defined so the DTOs cannot drift from them. Roles are those of the
+	// active authz policy, so roles added with AUTHZ_ROLE_PERMISSIONS are
+	// accepted once authz.Init has run.
+	enums = map[string]func() []string{
+		"enum_role":   authz.Roles,
+		"enum_status": func() []string { return constants.UserStatuses },
+	}
+)
 
+// Init sets up the validator with DefaultPasswordPolicy.
 func Init() error {
+	return InitWithPolicy(DefaultPasswordPolicy())
+}
+
+// InitWithPolicy sets up the validator with the "password" tag enforcing
+// policy.
+func InitWithPolicy(policy PasswordPolicy) error {
 	validate = validator.New()
+	validate.RegisterTagNameFunc(fieldName)
+	passwordPolicy = policy
 
 	// Register custom validators
+	validatePassword := func(fl validator.FieldLevel) bool {
+		return policy.Allows(fl.Field().String())
+	}
 	if err := validate.RegisterValidation("password", validatePassword); err != nil {
 		return fmt.Errorf("failed to register password validator: %w", err)
 	}
@@ -22,6 +54,21 @@ func Init() error {
 		return fmt.Errorf("failed to register username validator: %w", err)
 	}
 
+	// Replaces the built-in timezone tag, which also accepts "Local".
+	if err := validate.RegisterValidation("timezone", validateTimezone); err != nil {
+		return fmt.Errorf("failed to register timezone validator: %w", err)
+	}
+
+	if err := validate.RegisterValidation("locale", validateLocale); err != nil {
+		return fmt.Errorf("failed to register locale validator: %w", err)
+	}
+
+	for tag, values := range enums {
+		if err := validate.RegisterValidation(tag, validateEnum(values)); err != nil {
+			return fmt.Errorf("failed to register %s validator: %w", tag, err)
+		}
+	}
+
 	return nil
 }
 
@@ -37,28 +84,33 @@ func GetValidator() *validator.Validate {
 	return validate
 }
 
-// Custom validators
-
-func validatePassword(fl validator.FieldLevel) bool {
-	password := fl.Field().String()
-
-	// Minimum 8 characters
-	if len(password) < 8 {
-		return false
+// fieldName reports a struct field by the name clients use for it: its json
+// tag, or its form tag for query DTOs, falling back to the lowercased Go name.
+func fieldName(fld reflect.StructField) string {
+	for _, key := range []string{"json", "form"} {
+		name, _, _ := strings.Cut(fld.Tag.Get(key), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
 	}
+	return strings.ToLower(fld.Name)
+}
 
-	// At least one uppercase letter
-	hasUpper := regexp.MustCompile(`[A-Z]`).MatchString(password)
-	// At least one lowercase letter
-	hasLower := regexp.MustCompile(`[a-z]`).MatchString(password)
-	// At least one digit
-	hasDigit := regexp.MustCompile(`[0-9]`).MatchString(password)
-	// At least one special character
-	hasSpecial := regexp.MustCompile(`[!@#$%^&*(),.?":{}|<>]`).MatchString(password)
-
-	return hasUpper && hasLower && hasDigit && hasSpecial
+// fieldPath returns the dotted path of e from the top-level struct, such as
+// "users[0].email", dropping the struct's type name.
+func fieldPath(e validator.FieldError) string {
+	_, path, found := strings.Cut(e.Namespace(), ".")
+	if !found {
+		return e.Field()
+	}
+	return path
 }
 
+// Custom validators
+
 func validateUsername(fl validator.FieldLevel) bool {
 	username := fl.Field().String()
 
@@ -72,17 +124,48 @@ func validateUsername(fl validator.FieldLevel) bool {
 	return matched
 }
 
-// FormatValidationErrors formats validation errors into readable messages
+// validateTimezone accepts IANA zone names known to time.LoadLocation. "Local"
+// is rejected because it means the server's zone rather than the user's.
+func validateTimezone(fl validator.FieldLevel) bool {
+	name := fl.Field().String()
+	if name == "" || name == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(name)
+	return err == nil
+}
+
+func validateLocale(fl validator.FieldLevel) bool {
+	return localePattern.MatchString(fl.Field().String())
+}
+
+// validateEnum accepts exactly values.
+func validateEnum(values func() []string) validator.Func {
+	return func(fl validator.FieldLevel) bool {
+		value := fl.Field().String()
+		for _, allowed := range values() {
+			if value == allowed {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// FormatValidationErrors formats validation errors into readable messages,
+// keyed by the path of the offending field in the request JSON.
 func FormatValidationErrors(err error) map[string]string {
 	errors := make(map[string]string)
 
 	if validationErrors, ok := err.(validator.ValidationErrors); ok {
 		for _, e := range validationErrors {
-			field := strings.ToLower(e.Field())
+			field := fieldPath(e)
 
 			switch e.Tag() {
 			case "required":
 				errors[field] = fmt.Sprintf("%s is required", field)
+			case "required_without":
+				errors[field] = fmt.Sprintf("%s is required when %s is not provided", field, strings.ToLower(e.Param()))
 			case "email":
 				errors[field] = "invalid email format"
 			case "min":
@@ -90,9 +173,21 @@ func FormatValidationErrors(err error) map[string]string {
 			case "max":
 				errors[field] = fmt.Sprintf("%s must not exceed %s characters", field, e.Param())
 			case "password":
-				errors[field] = "password must be at least 8 characters and contain uppercase, lowercase, digit, and special character"
+				errors[field] = passwordPolicy.Describe()
 			case "username":
 				errors[field] = "username must be 3-30 characters and contain only alphanumeric, underscore, or hyphen"
+			case "timezone":
+				errors[field] = "timezone must be an IANA time zone name such as Asia/Jakarta"
+			case "locale":
+				errors[field] = "locale must be a BCP-47 language tag such as id-ID"
+			case "len":
+				errors[field] = fmt.Sprintf("%s must be exactly %s characters", field, e.Param())
+			case "numeric":
+				errors[field] = fmt.Sprintf("%s must contain only digits", field)
+			case "oneof":
+				errors[field] = fmt.Sprintf("%s must be one of: %s", field, e.Param())
+			case "enum_role", "enum_status":
+				errors[field] = fmt.Sprintf("%s must be one of: %s", field, strings.Join(enums[e.Tag()](), " "))
 			case "uuid":
 				errors[field] = "invalid UUID format"
 			default:
@@ -0,0 +1,157 @@
+package usecase_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/TubagusAldiMY/go-template/internal/domain/apikey/dto"
+	"github.com/TubagusAldiMY/go-template/internal/domain/apikey/entity"
+	apikeyUsecase "github.com/TubagusAldiMY/go-template/internal/domain/apikey/usecase"
+	sharedErrors "github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/TubagusAldiMY/go-template/pkg/apikey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockAPIKeyRepository is a mock implementation of APIKeyRepository
+type MockAPIKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyRepository) Create(ctx context.Context, key *entity.APIKey) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) GetPrincipalByHash(ctx context.Context, keyHash string) (*entity.Principal, error) {
+	args := m.Called(ctx, keyHash)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Principal), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) Revoke(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+// passthroughTxManager runs fn without a transaction.
+type passthroughTxManager struct{}
+
+func (passthroughTxManager) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+func TestAPIKeyGenerate(t *testing.T) {
+	// Act
+	key, err := apikey.Generate()
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(key.Plaintext, apikey.Prefix))
+	assert.True(t, apikey.LooksValid(key.Plaintext))
+	assert.True(t, strings.HasPrefix(key.Plaintext, key.DisplayPrefix))
+	assert.Equal(t, apikey.Hash(key.Plaintext), key.Hash)
+	assert.NotContains(t, key.Hash, key.Plaintext)
+	assert.False(t, apikey.LooksValid(key.DisplayPrefix))
+}
+
+func TestAPIKeyCreate_DefaultsOwnerToCaller(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockAPIKeyRepository)
+	mockAudit := new(MockAuditLogger)
+	uc := apikeyUsecase.NewAPIKeyUsecase(mockRepo, passthroughTxManager{}, mockAudit)
+
+	var stored *entity.APIKey
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.APIKey")).
+		Run(func(args mock.Arguments) { stored = args.Get(1).(*entity.APIKey) }).
+		Return(nil)
+	mockAudit.On("Record", mock.Anything, "api_key.created", mock.Anything, mock.Anything).Return(nil)
+
+	// Act
+	result, err := uc.Create(context.Background(), "admin-1", &dto.CreateAPIKeyRequest{Name: "billing"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "admin-1", result.UserID)
+	assert.True(t, apikey.LooksValid(result.Key))
+	assert.Equal(t, apikey.Hash(result.Key), stored.KeyHash)
+	assert.Equal(t, stored.Prefix, result.Prefix)
+	mockAudit.AssertExpectations(t)
+}
+
+func TestAPIKeyCreate_UnknownUser(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockAPIKeyRepository)
+	uc := apikeyUsecase.NewAPIKeyUsecase(mockRepo, passthroughTxManager{}, new(MockAuditLogger))
+
+	mockRepo.On("Create", mock.Anything, mock.Anything).Return(sharedErrors.ErrUserNotFound)
+
+	// Act
+	result, err := uc.Create(context.Background(), "admin-1", &dto.CreateAPIKeyRequest{
+		Name:   "billing",
+		UserID: "6f1c1d1e-0000-4000-8000-000000000000",
+	})
+
+	// Assert
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, sharedErrors.ErrUserNotFound)
+}
+
+func TestAPIKeyAuthenticate_ResolvesPrincipal(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockAPIKeyRepository)
+	uc := apikeyUsecase.NewAPIKeyUsecase(mockRepo, passthroughTxManager{}, new(MockAuditLogger))
+
+	key, err := apikey.Generate()
+	require.NoError(t, err)
+	principal := &entity.Principal{KeyID: "key-1", UserID: "user-1", Email: "svc@example.com", Role: "admin"}
+	mockRepo.On("GetPrincipalByHash", mock.Anything, key.Hash).Return(principal, nil)
+
+	// Act
+	result, err := uc.Authenticate(context.Background(), key.Plaintext)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, principal, result)
+}
+
+func TestAPIKeyAuthenticate_RejectsUnknownAndMalformedKeys(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockAPIKeyRepository)
+	uc := apikeyUsecase.NewAPIKeyUsecase(mockRepo, passthroughTxManager{}, new(MockAuditLogger))
+
+	key, err := apikey.Generate()
+	require.NoError(t, err)
+	mockRepo.On("GetPrincipalByHash", mock.Anything, key.Hash).Return(nil, sharedErrors.ErrAPIKeyNotFound)
+
+	// Act
+	_, unknownErr := uc.Authenticate(context.Background(), key.Plaintext)
+	_, malformedErr := uc.Authenticate(context.Background(), "not-a-key")
+
+	// Assert
+	assert.ErrorIs(t, unknownErr, sharedErrors.ErrInvalidAPIKey)
+	assert.ErrorIs(t, malformedErr, sharedErrors.ErrInvalidAPIKey)
+	mockRepo.AssertNumberOfCalls(t, "GetPrincipalByHash", 1)
+}
+
+func TestAPIKeyRevoke_RecordsAuditLog(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockAPIKeyRepository)
+	mockAudit := new(MockAuditLogger)
+	uc := apikeyUsecase.NewAPIKeyUsecase(mockRepo, passthroughTxManager{}, mockAudit)
+
+	mockRepo.On("Revoke", mock.Anything, "key-1").Return(nil)
+	mockAudit.On("Record", mock.Anything, "api_key.revoked", "key-1", map[string]interface{}(nil)).Return(nil)
+
+	// Act
+	err := uc.Revoke(context.Background(), "key-1")
+
+	// Assert
+	require.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockAudit.AssertExpectations(t)
+}
@@ -0,0 +1,78 @@
+package usecase_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/internal/delivery/http/middleware"
+	"github.com/TubagusAldiMY/go-template/pkg/appcontext"
+	"github.com/TubagusAldiMY/go-template/pkg/jwt"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppContext_RoundTrip(t *testing.T) {
+	// Arrange
+	ctx := appcontext.WithUserID(context.Background(), "user-123")
+	ctx = appcontext.WithRole(ctx, "admin")
+
+	// Act
+	userID, hasUserID := appcontext.UserID(ctx)
+	role, hasRole := appcontext.Role(ctx)
+	_, hasActor := appcontext.ActorID(ctx)
+
+	// Assert
+	assert.True(t, hasUserID)
+	assert.Equal(t, "user-123", userID)
+	assert.True(t, hasRole)
+	assert.Equal(t, "admin", role)
+	assert.False(t, hasActor)
+}
+
+func TestAppContext_EmptyValueIsAbsent(t *testing.T) {
+	// Arrange
+	ctx := appcontext.WithUserID(context.Background(), "")
+
+	// Act
+	_, ok := appcontext.UserID(ctx)
+
+	// Assert
+	assert.False(t, ok)
+}
+
+func TestAuthMiddleware_StoresIdentityInRequestContext(t *testing.T) {
+	// Arrange
+	manager := jwt.NewManager(testJWTSecret, 15*time.Minute, time.Hour)
+	token, err := manager.GenerateAccessToken(context.Background(), "user-123", "test@example.com", "user")
+	require.NoError(t, err)
+
+	var ctx context.Context
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/", middleware.AuthMiddleware(manager), func(c *gin.Context) {
+		ctx = c.Request.Context()
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	userID, _ := appcontext.UserID(ctx)
+	email, _ := appcontext.Email(ctx)
+	role, _ := appcontext.Role(ctx)
+	assert.Equal(t, "user-123", userID)
+	assert.Equal(t, "test@example.com", email)
+	assert.Equal(t, "user", role)
+	_, impersonating := appcontext.ActorID(ctx)
+	assert.False(t, impersonating)
+}
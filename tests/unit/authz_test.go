@@ -0,0 +1,160 @@
+package usecase_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TubagusAldiMY/go-template/internal/delivery/http/middleware"
+	"github.com/TubagusAldiMY/go-template/internal/shared/constants"
+	"github.com/TubagusAldiMY/go-template/pkg/authz"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// initPolicy makes policy the active authz policy for the rest of the test.
+func initPolicy(t *testing.T, policy string) {
+	t.Helper()
+	parsed, err := authz.ParsePolicy(policy)
+	require.NoError(t, err)
+	authz.Init(parsed)
+	t.Cleanup(func() { authz.Init(authz.DefaultPolicy()) })
+}
+
+func TestParsePolicy(t *testing.T) {
+	// Act
+	policy, err := authz.ParsePolicy(" admin = * ; moderator=user:read, user:update,;guest=;")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, authz.Policy{
+		"admin":     {authz.PermAll},
+		"moderator": {authz.PermUserRead, authz.PermUserUpdate},
+		"guest":     {},
+	}, policy)
+}
+
+func TestParsePolicy_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy string
+		want   string
+	}{
+		{name: "empty", policy: "", want: "role permission policy is empty"},
+		{name: "only separators", policy: " ; ;", want: "role permission policy is empty"},
+		{name: "missing equals", policy: "admin=*;moderator", want: `invalid role permission entry "moderator"`},
+		{name: "missing role", policy: "=user:read", want: `invalid role permission entry "=user:read"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			policy, err := authz.ParsePolicy(tt.policy)
+
+			// Assert
+			require.Error(t, err)
+			assert.Nil(t, policy)
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+}
+
+func TestHasPermission(t *testing.T) {
+	initPolicy(t, "admin=*;moderator=user:read,user:update;guest=")
+
+	tests := []struct {
+		name string
+		role string
+		perm string
+		want bool
+	}{
+		{name: "wildcard grants anything", role: "admin", perm: authz.PermMaintenanceManage, want: true},
+		{name: "wildcard grants unknown permissions", role: "admin", perm: "report:export", want: true},
+		{name: "exact permission", role: "moderator", perm: authz.PermUserUpdate, want: true},
+		{name: "permission not granted", role: "moderator", perm: authz.PermUserDelete, want: false},
+		{name: "wildcard is not a prefix match", role: "moderator", perm: "user:*", want: false},
+		{name: "role granting nothing", role: "guest", perm: authz.PermUserRead, want: false},
+		{name: "unknown role", role: "superuser", perm: authz.PermUserRead, want: false},
+		{name: "empty role", role: "", perm: authz.PermUserRead, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act & Assert
+			assert.Equal(t, tt.want, authz.HasPermission(tt.role, tt.perm))
+		})
+	}
+}
+
+func TestHasPermission_DefaultPolicy(t *testing.T) {
+	// Arrange
+	authz.Init(authz.DefaultPolicy())
+
+	// Act & Assert
+	assert.True(t, authz.HasPermission("admin", authz.PermUserDelete))
+	assert.False(t, authz.HasPermission("user", authz.PermUserRead))
+}
+
+func TestCovers(t *testing.T) {
+	initPolicy(t, "admin=*;root=*;moderator=user:read,user:update;support=user:read;guest=")
+
+	// Act & Assert
+	assert.True(t, authz.Covers("admin", "root"))
+	assert.True(t, authz.Covers("moderator", "support"))
+	assert.True(t, authz.Covers("support", "guest"))
+	assert.True(t, authz.Covers("support", "unknown"))
+	assert.False(t, authz.Covers("support", "moderator"))
+	assert.False(t, authz.Covers("moderator", "admin"))
+	assert.False(t, authz.Covers("unknown", "support"))
+}
+
+func TestRoles(t *testing.T) {
+	initPolicy(t, "user=;admin=*;guest=")
+
+	// Act & Assert
+	assert.Equal(t, []string{"admin", "guest", "user"}, authz.Roles())
+	assert.True(t, authz.HasRole("guest"))
+	assert.False(t, authz.HasRole("moderator"))
+}
+
+func TestRequirePermission(t *testing.T) {
+	initPolicy(t, "admin=*;moderator=user:read;guest=")
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name string
+		role string
+		want int
+	}{
+		{name: "unauthenticated", role: "", want: http.StatusUnauthorized},
+		{name: "unknown role", role: "superuser", want: http.StatusForbidden},
+		{name: "role without the permission", role: "guest", want: http.StatusForbidden},
+		{name: "role with the permission", role: "moderator", want: http.StatusOK},
+		{name: "wildcard role", role: "admin", want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			reached := false
+			router := gin.New()
+			router.GET("/users", func(c *gin.Context) {
+				if tt.role != "" {
+					c.Set(constants.ContextKeyUserRole, tt.role)
+				}
+			}, middleware.RequirePermission(authz.PermUserRead), func(c *gin.Context) {
+				reached = true
+				c.Status(http.StatusOK)
+			})
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+
+			// Assert
+			assert.Equal(t, tt.want, w.Code)
+			assert.Equal(t, tt.want == http.StatusOK, reached)
+		})
+	}
+}
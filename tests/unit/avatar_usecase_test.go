@@ -0,0 +1,122 @@
+package usecase_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/entity"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/usecase"
+	sharedErrors "github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockFileStorage is a mock implementation of FileStorage
+type MockFileStorage struct {
+	mock.Mock
+}
+
+func (m *MockFileStorage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	args := m.Called(ctx, key, r, size, contentType)
+	return args.Error(0)
+}
+
+func (m *MockFileStorage) Delete(ctx context.Context, key string) error {
+	args := m.Called(ctx, key)
+	return args.Error(0)
+}
+
+func (m *MockFileStorage) URL(key string) string {
+	return "https://cdn.example.com/" + key
+}
+
+// pngHeader is enough of a PNG file for content sniffing.
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func newAvatarUsecase(repo *MockUserRepository, store *MockFileStorage) *usecase.UserUsecase {
+	return usecase.NewUserUsecase(repo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis),
+		usecase.WithAvatarStorage(store, 64))
+}
+
+func TestUploadAvatar_StoresImageAndReplacesPrevious(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockStore := new(MockFileStorage)
+	uc := newAvatarUsecase(mockRepo, mockStore)
+
+	user := &entity.User{ID: "user-123", AvatarURL: "https://cdn.example.com/avatars/user-123/old.png", Version: 1}
+	mockRepo.On("GetByID", mock.Anything, "user-123").Return(user, nil)
+	mockRepo.On("Update", mock.Anything, user).Return(nil)
+	var storedKey string
+	mockStore.On("Put", mock.Anything, mock.MatchedBy(func(key string) bool {
+		storedKey = key
+		return strings.HasPrefix(key, "avatars/user-123/") && strings.HasSuffix(key, ".png")
+	}), mock.Anything, int64(len(pngHeader)), "image/png").Return(nil)
+	mockStore.On("Delete", mock.Anything, "avatars/user-123/old.png").Return(nil)
+
+	// Act
+	result, err := uc.UploadAvatar(context.Background(), "user-123", bytes.NewReader(pngHeader))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "https://cdn.example.com/"+storedKey, result.AvatarURL)
+	mockStore.AssertExpectations(t)
+}
+
+func TestUploadAvatar_RejectsNonImage(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockStore := new(MockFileStorage)
+	uc := newAvatarUsecase(mockRepo, mockStore)
+
+	// Act
+	result, err := uc.UploadAvatar(context.Background(), "user-123", strings.NewReader("<html>not an image</html>"))
+
+	// Assert
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, sharedErrors.ErrUnsupportedMediaType)
+	mockStore.AssertNotCalled(t, "Put", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUploadAvatar_RejectsOversizedFile(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockStore := new(MockFileStorage)
+	uc := newAvatarUsecase(mockRepo, mockStore)
+	oversized := append(append([]byte{}, pngHeader...), make([]byte, 64)...)
+
+	// Act
+	result, err := uc.UploadAvatar(context.Background(), "user-123", bytes.NewReader(oversized))
+
+	// Assert
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, sharedErrors.ErrFileTooLarge)
+	mockStore.AssertNotCalled(t, "Put", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUploadAvatar_UpdateFailureDeletesUpload(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockStore := new(MockFileStorage)
+	uc := newAvatarUsecase(mockRepo, mockStore)
+
+	user := &entity.User{ID: "user-123", Version: 1}
+	mockRepo.On("GetByID", mock.Anything, "user-123").Return(user, nil)
+	mockRepo.On("Update", mock.Anything, user).Return(sharedErrors.ErrStaleData)
+	mockStore.On("Put", mock.Anything, mock.Anything, mock.Anything, mock.Anything, "image/png").Return(nil)
+	mockStore.On("Delete", mock.Anything, mock.MatchedBy(func(key string) bool {
+		return strings.HasPrefix(key, "avatars/user-123/")
+	})).Return(nil)
+
+	// Act
+	result, err := uc.UploadAvatar(context.Background(), "user-123", bytes.NewReader(pngHeader))
+
+	// Assert
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, sharedErrors.ErrStaleData)
+	mockStore.AssertExpectations(t)
+}
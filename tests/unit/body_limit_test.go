@@ -0,0 +1,61 @@
+package usecase_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/TubagusAldiMY/go-template/internal/delivery/http/middleware"
+	"github.com/TubagusAldiMY/go-template/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func newBodyLimitRouter(maxBytes int64) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.MaxBodySize(maxBytes))
+	router.POST("/echo", func(c *gin.Context) {
+		var req map[string]interface{}
+		if err := c.ShouldBindJSON(&req); err != nil {
+			response.InvalidBody(c, err)
+			return
+		}
+		response.OK(c, "ok", req)
+	})
+	return router
+}
+
+func TestMaxBodySize(t *testing.T) {
+	largeBody := `{"name":"` + strings.Repeat("a", 64) + `"}`
+
+	tests := []struct {
+		name          string
+		body          string
+		contentLength int64
+		want          int
+	}{
+		{name: "within limit", body: `{"name":"a"}`, contentLength: 12, want: http.StatusOK},
+		{name: "declared too large", body: largeBody, contentLength: int64(len(largeBody)), want: http.StatusRequestEntityTooLarge},
+		{name: "streamed too large", body: largeBody, contentLength: -1, want: http.StatusRequestEntityTooLarge},
+		{name: "malformed", body: `{"name":`, contentLength: 8, want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			router := newBodyLimitRouter(32)
+			req := httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader(tt.body))
+			req.ContentLength = tt.contentLength
+			req.Header.Set("Content-Type", "application/json")
+			rec := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.want, rec.Code)
+		})
+	}
+}
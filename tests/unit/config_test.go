@@ -0,0 +1,458 @@
+package usecase_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func validConfig() *config.Config {
+	return &config.Config{
+		App:      config.AppConfig{Port: 8080},
+		Database: config.DatabaseConfig{Host: "localhost", Port: 5432, Name: "app"},
+		Redis:    config.RedisConfig{Host: "localhost", Port: 6379},
+		JWT: config.JWTConfig{
+			Secret:              "0123456789abcdef0123456789abcdef",
+			AccessTokenExpiry:   15 * time.Minute,
+			RefreshTokenExpiry:  168 * time.Hour,
+			ImpersonationExpiry: 5 * time.Minute,
+		},
+		RateLimit: config.RateLimitConfig{ImpersonationsPerMinute: config.DefaultImpersonationsPerMinute},
+		Security:  config.SecurityConfig{BcryptCost: 10},
+		Tracing:   config.TracingConfig{SampleRatio: 1},
+		Storage:   config.StorageConfig{MaxAvatarSize: config.DefaultMaxAvatarSize},
+	}
+}
+
+func TestConfigValidate_Valid(t *testing.T) {
+	// Arrange
+	cfg := validConfig()
+
+	// Act & Assert
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestConfigValidate_UnsetBcryptCostUsesDefault(t *testing.T) {
+	// Arrange
+	cfg := validConfig()
+	cfg.Security.BcryptCost = 0
+
+	// Act & Assert
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestConfigValidate_ImpersonationMustBeShorterThanAccessTokens(t *testing.T) {
+	// Arrange
+	cfg := validConfig()
+	cfg.JWT.ImpersonationExpiry = cfg.JWT.AccessTokenExpiry
+
+	// Act
+	err := cfg.Validate()
+
+	// Assert
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "JWT_IMPERSONATION_EXPIRY: must be shorter than JWT_ACCESS_TOKEN_EXPIRY")
+}
+
+func TestConfigValidate_ReportsEveryProblem(t *testing.T) {
+	// Arrange
+	cfg := validConfig()
+	cfg.App.Port = 0
+	cfg.JWT.Secret = "short"
+	cfg.JWT.AccessTokenExpiry = 0
+	cfg.Security.BcryptCost = 99
+
+	// Act
+	err := cfg.Validate()
+
+	// Assert
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "APP_PORT")
+	assert.Contains(t, err.Error(), "JWT_SECRET: must be at least 32 characters")
+	assert.Contains(t, err.Error(), "JWT_ACCESS_TOKEN_EXPIRY")
+	assert.Contains(t, err.Error(), "BCRYPT_COST")
+}
+
+func TestConfigValidate_TrustedProxies(t *testing.T) {
+	// Arrange
+	cfg := validConfig()
+	cfg.Server.TrustedProxies = []string{"10.0.0.0/8", "192.168.1.10", "proxy.internal"}
+
+	// Act
+	err := cfg.Validate()
+
+	// Assert
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `SERVER_TRUSTED_PROXIES: "proxy.internal" is not an IP address or CIDR`)
+	assert.NotContains(t, err.Error(), "10.0.0.0/8")
+}
+
+func TestConfigValidate_ReplicaHosts(t *testing.T) {
+	// Arrange
+	cfg := validConfig()
+	cfg.Database.ReplicaHosts = []string{"replica-1", "replica-2:5433", "replica-3:99999"}
+
+	// Act
+	err := cfg.Validate()
+
+	// Assert
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `DB_REPLICA_HOSTS: "replica-3:99999" is not a host or host:port`)
+	assert.NotContains(t, err.Error(), "replica-2:5433")
+}
+
+func TestConfigValidate_TOTPEncryptionKey(t *testing.T) {
+	// Arrange
+	cfg := validConfig()
+	cfg.Security.TOTPEncryptionKey = "c2hvcnQ="
+
+	// Act
+	err := cfg.Validate()
+
+	// Assert
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "TOTP_ENCRYPTION_KEY: must decode to 32 bytes, got 5")
+
+	cfg.Security.TOTPEncryptionKey = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestConfigValidate_PIIKeys(t *testing.T) {
+	// Arrange
+	cfg := validConfig()
+	cfg.Security.PIIEncryptionKey = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+
+	// Act
+	err := cfg.Validate()
+
+	// Assert
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "PII_ENCRYPTION_KEY: must be set together with PII_BLIND_INDEX_KEY")
+
+	cfg.Security.PIIBlindIndexKey = cfg.Security.PIIEncryptionKey
+	assert.ErrorContains(t, cfg.Validate(), "PII_BLIND_INDEX_KEY: must differ from PII_ENCRYPTION_KEY")
+
+	cfg.Security.PIIBlindIndexKey = "c2hvcnQ="
+	assert.ErrorContains(t, cfg.Validate(), "PII_BLIND_INDEX_KEY: must decode to 32 bytes, got 5")
+
+	cfg.Security.PIIBlindIndexKey = "ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA="
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestConfigValidate_AuthCookie(t *testing.T) {
+	// Arrange
+	cfg := validConfig()
+	cfg.AuthCookie = config.AuthCookieConfig{Delivery: "header", SameSite: "none"}
+
+	// Act
+	err := cfg.Validate()
+
+	// Assert
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `AUTH_COOKIE_DELIVERY: must be json, cookie or both, got "header"`)
+	assert.Contains(t, err.Error(), "AUTH_COOKIE_SAME_SITE: none requires AUTH_COOKIE_SECURE")
+
+	cfg.AuthCookie = config.AuthCookieConfig{Delivery: "both", Secure: true, SameSite: "none"}
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestConfigValidate_RedisModes(t *testing.T) {
+	tests := []struct {
+		name  string
+		redis config.RedisConfig
+		want  []string
+	}{
+		{
+			name:  "sentinel without master name",
+			redis: config.RedisConfig{Mode: config.RedisModeSentinel, Addrs: []string{"sentinel:26379"}},
+			want:  []string{"REDIS_MASTER_NAME"},
+		},
+		{
+			name:  "cluster without addrs on a non-zero db",
+			redis: config.RedisConfig{Mode: config.RedisModeCluster, DB: 1},
+			want:  []string{"REDIS_ADDRS", "REDIS_DB: must be 0 in cluster mode"},
+		},
+		{
+			name:  "unknown mode",
+			redis: config.RedisConfig{Mode: "ring"},
+			want:  []string{"REDIS_MODE"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			cfg := validConfig()
+			cfg.Redis = tt.redis
+
+			// Act
+			err := cfg.Validate()
+
+			// Assert
+			require.Error(t, err)
+			for _, want := range tt.want {
+				assert.Contains(t, err.Error(), want)
+			}
+			assert.NotContains(t, err.Error(), "REDIS_HOST")
+		})
+	}
+
+	cfg := validConfig()
+	cfg.Redis = config.RedisConfig{Mode: config.RedisModeCluster, Addrs: []string{"node-1:6379", "node-2:6379"}}
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestConfigLoad_SurfacesMalformedDurations(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+app:
+  port: 8080
+database:
+  host: localhost
+  port: 5432
+  name: app
+redis:
+  host: localhost
+  port: 6379
+jwt:
+  secret: 0123456789abcdef0123456789abcdef
+  access_token_expiry: 15 minutes
+  refresh_token_expiry: 168h
+security:
+  bcrypt_cost: 10
+`), 0o600))
+	t.Setenv(config.EnvConfigFile, path)
+
+	// Act
+	cfg, err := config.Load()
+
+	// Assert
+	require.Error(t, err)
+	assert.Nil(t, cfg)
+	assert.Contains(t, err.Error(), `JWT_ACCESS_TOKEN_EXPIRY: invalid duration "15 minutes"`)
+}
+
+func TestConfigLoad_MapsNestedYAMLKeys(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+app:
+  port: 8080
+database:
+  host: db.internal
+  port: 5432
+  name: app
+redis:
+  host: localhost
+  port: 6379
+jwt:
+  secret: 0123456789abcdef0123456789abcdef
+  access_token_expiry: 15m
+  refresh_token_expiry: 168h
+cors:
+  allowed_origins: [https://example.com, https://*.example.com]
+events:
+  backend: redis_stream
+security:
+  bcrypt_cost: 10
+`), 0o600))
+	t.Setenv(config.EnvConfigFile, path)
+	t.Setenv("DB_PORT", "6543")
+
+	// Act
+	cfg, err := config.Load()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "db.internal", cfg.Database.Host)
+	assert.Equal(t, 6543, cfg.Database.Port, "environment overrides the file")
+	assert.Equal(t, 15*time.Minute, cfg.JWT.AccessTokenExpiry)
+	assert.Equal(t, []string{"https://example.com", "https://*.example.com"}, cfg.CORS.AllowedOrigins)
+	assert.Equal(t, config.EventsBackendRedisStream, cfg.Events.Backend)
+	assert.Equal(t, int64(config.DefaultEventsStreamMaxLen), cfg.Events.StreamMaxLen)
+}
+
+func TestConfigLoad_SplitsLogOutputs(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(path, []byte("APP_PORT=8080\nDB_HOST=localhost\nDB_PORT=5432\nDB_NAME=app\nREDIS_HOST=localhost\nREDIS_PORT=6379\n"+
+		"JWT_SECRET=0123456789abcdef0123456789abcdef\nJWT_ACCESS_TOKEN_EXPIRY=15m\nJWT_REFRESH_TOKEN_EXPIRY=168h\nBCRYPT_COST=10\n"), 0o600))
+	t.Setenv(config.EnvConfigFile, path)
+	t.Setenv("LOG_OUTPUT", "stdout, /var/log/app/app.log")
+	t.Setenv("LOG_MAX_BACKUPS", "7")
+
+	// Act
+	cfg, err := config.Load()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"stdout", "/var/log/app/app.log"}, cfg.Log.Output)
+	assert.Equal(t, 7, cfg.Log.Rotation().MaxBackups)
+}
+
+func TestConfigLoad_PoolWarmup(t *testing.T) {
+	tests := []struct {
+		name string
+		env  string
+		want bool
+	}{
+		{name: "unset defaults to on", want: true},
+		{name: "disabled", env: "false", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			path := filepath.Join(t.TempDir(), ".env")
+			require.NoError(t, os.WriteFile(path, []byte("APP_PORT=8080\nDB_HOST=localhost\nDB_PORT=5432\nDB_NAME=app\nREDIS_HOST=localhost\nREDIS_PORT=6379\n"+
+				"JWT_SECRET=0123456789abcdef0123456789abcdef\nJWT_ACCESS_TOKEN_EXPIRY=15m\nJWT_REFRESH_TOKEN_EXPIRY=168h\nBCRYPT_COST=10\n"), 0o600))
+			t.Setenv(config.EnvConfigFile, path)
+			if tt.env != "" {
+				t.Setenv("DB_POOL_WARMUP", tt.env)
+			}
+
+			// Act
+			cfg, err := config.Load()
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, cfg.Database.PoolWarmup)
+		})
+	}
+}
+
+func TestConfigManager_ReloadAppliesOnlyHotReloadableSettings(t *testing.T) {
+	// Arrange
+	write := func(path, logLevel, dbHost string) {
+		require.NoError(t, os.WriteFile(path, []byte(`
+app:
+  port: 8080
+database:
+  host: `+dbHost+`
+  port: 5432
+  name: app
+redis:
+  host: localhost
+  port: 6379
+jwt:
+  secret: 0123456789abcdef0123456789abcdef
+  access_token_expiry: 15m
+  refresh_token_expiry: 168h
+log:
+  level: `+logLevel+`
+security:
+  bcrypt_cost: 10
+`), 0o600))
+	}
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	write(path, "info", "db.internal")
+	t.Setenv(config.EnvConfigFile, path)
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	manager := config.NewManager(cfg)
+
+	var notified *config.Config
+	manager.Subscribe(func(c *config.Config) { notified = c })
+
+	write(path, "debug", "other.internal")
+
+	// Act
+	err = manager.Reload()
+
+	// Assert
+	require.NoError(t, err)
+	current := manager.Current()
+	assert.Equal(t, "debug", current.Log.Level)
+	assert.Equal(t, "db.internal", current.Database.Host, "restart-required settings are ignored")
+	assert.Same(t, current, notified)
+	assert.Equal(t, "info", cfg.Log.Level, "the previous snapshot is not modified")
+}
+
+func TestConfigManager_ReloadKeepsConfigWhenInvalid(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(path, []byte("APP_PORT=8080\nDB_HOST=localhost\nDB_PORT=5432\nDB_NAME=app\n"+
+		"REDIS_HOST=localhost\nREDIS_PORT=6379\nJWT_SECRET=0123456789abcdef0123456789abcdef\n"+
+		"JWT_ACCESS_TOKEN_EXPIRY=15m\nJWT_REFRESH_TOKEN_EXPIRY=168h\nBCRYPT_COST=10\nLOG_LEVEL=warn\n"), 0o600))
+	t.Setenv(config.EnvConfigFile, path)
+
+	cfg, err := config.Load()
+	require.NoError(t, err)
+	manager := config.NewManager(cfg)
+	require.NoError(t, os.WriteFile(path, []byte("LOG_LEVEL=debug\n"), 0o600))
+
+	// Act
+	err = manager.Reload()
+
+	// Assert
+	require.Error(t, err)
+	assert.Same(t, cfg, manager.Current())
+}
+
+func TestConfigValidate_Timezone(t *testing.T) {
+	// Arrange
+	cfg := validConfig()
+	cfg.App.Timezone = "Mars/Olympus_Mons"
+
+	// Act
+	err := cfg.Validate()
+
+	// Assert
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `APP_TIMEZONE: unknown time zone "Mars/Olympus_Mons"`)
+
+	cfg.App.Timezone = "Local"
+	assert.Error(t, cfg.Validate())
+
+	cfg.App.Timezone = "Asia/Jakarta"
+	assert.NoError(t, cfg.Validate())
+}
+
+func TestAppConfigLocation_UnsetIsUTC(t *testing.T) {
+	// Act
+	loc, err := config.AppConfig{}.Location()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, time.UTC, loc)
+}
+
+func TestConfigLoad_Maintenance(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+app:
+  port: 8080
+database:
+  host: localhost
+  port: 5432
+  name: app
+redis:
+  host: localhost
+  port: 6379
+jwt:
+  secret: 0123456789abcdef0123456789abcdef
+  access_token_expiry: 15m
+  refresh_token_expiry: 168h
+security:
+  bcrypt_cost: 10
+maintenance:
+  allowed_routes: [/api/v1/auth/login]
+`), 0o600))
+	t.Setenv(config.EnvConfigFile, path)
+	t.Setenv("MAINTENANCE_ALLOWED_ROUTES", "/api/v1/auth/login, /api/v1/auth/refresh")
+
+	// Act
+	cfg, err := config.Load()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Minute, cfg.Maintenance.RetryAfter)
+	assert.Equal(t, []string{"/api/v1/auth/login", "/api/v1/auth/refresh"}, cfg.Maintenance.AllowedRoutes)
+}
@@ -0,0 +1,276 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/messaging"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAcknowledger records how a delivery was settled.
+type fakeAcknowledger struct {
+	mu      sync.Mutex
+	acked   bool
+	nacked  bool
+	requeue bool
+}
+
+func (a *fakeAcknowledger) Ack(uint64, bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.acked = true
+	return nil
+}
+
+func (a *fakeAcknowledger) Nack(_ uint64, _ bool, requeue bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.nacked, a.requeue = true, requeue
+	return nil
+}
+
+func (a *fakeAcknowledger) Reject(_ uint64, requeue bool) error {
+	return a.Nack(0, false, requeue)
+}
+
+func (a *fakeAcknowledger) settled() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.acked || a.nacked
+}
+
+type publishedMessage struct {
+	exchange string
+	key      string
+	msg      amqp.Publishing
+}
+
+type declaredQueue struct {
+	name    string
+	durable bool
+	args    amqp.Table
+}
+
+// fakeChannel is a messaging.Channel that feeds deliveries from a Go channel
+// and records everything published or declared on it.
+type fakeChannel struct {
+	mu         sync.Mutex
+	deliveries chan amqp.Delivery
+	publishErr error
+	declareErr error
+	published  []publishedMessage
+	exchanges  []string
+	queues     []declaredQueue
+	bindings   [][2]string
+}
+
+func newFakeChannel() *fakeChannel {
+	return &fakeChannel{deliveries: make(chan amqp.Delivery, 1)}
+}
+
+func (f *fakeChannel) Qos(int, int, bool) error { return nil }
+
+func (f *fakeChannel) Consume(string, string, bool, bool, bool, bool, amqp.Table) (<-chan amqp.Delivery, error) {
+	return f.deliveries, nil
+}
+
+func (f *fakeChannel) Cancel(string, bool) error {
+	close(f.deliveries)
+	return nil
+}
+
+func (f *fakeChannel) PublishWithContext(_ context.Context, exchange, key string, _, _ bool, msg amqp.Publishing) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.publishErr != nil {
+		return f.publishErr
+	}
+	f.published = append(f.published, publishedMessage{exchange: exchange, key: key, msg: msg})
+	return nil
+}
+
+func (f *fakeChannel) ExchangeDeclare(name, kind string, _, _, _, _ bool, _ amqp.Table) error {
+	f.exchanges = append(f.exchanges, name+" "+kind)
+	return nil
+}
+
+func (f *fakeChannel) QueueDeclare(name string, durable, _, _, _ bool, args amqp.Table) (amqp.Queue, error) {
+	if f.declareErr != nil {
+		return amqp.Queue{}, f.declareErr
+	}
+	f.queues = append(f.queues, declaredQueue{name: name, durable: durable, args: args})
+	return amqp.Queue{Name: name}, nil
+}
+
+func (f *fakeChannel) QueueBind(name, _, exchange string, _ bool, _ amqp.Table) error {
+	f.bindings = append(f.bindings, [2]string{name, exchange})
+	return nil
+}
+
+func (f *fakeChannel) Close() error { return nil }
+
+// consumeOne runs a single-worker consumer on channel until delivery is
+// settled and returns its acknowledger.
+func consumeOne(t *testing.T, channel *fakeChannel, maxRetries int, handlerErr error, delivery amqp.Delivery) *fakeAcknowledger {
+	t.Helper()
+	ack := &fakeAcknowledger{}
+	delivery.Acknowledger = ack
+
+	consumer := messaging.NewChannelConsumer(func() (messaging.Channel, error) {
+		return channel, nil
+	}, messaging.ConsumerConfig{Queue: "emails", MaxRetries: maxRetries}, func(context.Context, amqp.Delivery) error {
+		return handlerErr
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- consumer.Run(ctx) }()
+
+	channel.deliveries <- delivery
+	require.Eventually(t, ack.settled, time.Second, 5*time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+
+	return ack
+}
+
+func TestConsumer_AcksHandledMessage(t *testing.T) {
+	// Arrange
+	channel := newFakeChannel()
+
+	// Act
+	ack := consumeOne(t, channel, 3, nil, amqp.Delivery{Body: []byte("hello")})
+
+	// Assert
+	assert.True(t, ack.acked)
+	assert.False(t, ack.nacked)
+	assert.Empty(t, channel.published)
+}
+
+func TestConsumer_RetryRepublishesWithIncrementedCount(t *testing.T) {
+	tests := []struct {
+		name    string
+		headers amqp.Table
+		want    int32
+	}{
+		{name: "first failure", headers: nil, want: 1},
+		{name: "int32 header", headers: amqp.Table{messaging.HeaderRetryCount: int32(1)}, want: 2},
+		{name: "int64 header", headers: amqp.Table{messaging.HeaderRetryCount: int64(2)}, want: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			channel := newFakeChannel()
+			headers := amqp.Table{"x-trace-id": "abc"}
+			for k, v := range tt.headers {
+				headers[k] = v
+			}
+
+			// Act
+			ack := consumeOne(t, channel, 3, errors.New("smtp unavailable"), amqp.Delivery{
+				Headers:     headers,
+				MessageId:   "msg-1",
+				ContentType: "application/json",
+				Body:        []byte(`{"to":"john@example.com"}`),
+			})
+
+			// Assert
+			assert.True(t, ack.acked, "the original is acked once its retry is republished")
+			assert.False(t, ack.nacked)
+			require.Len(t, channel.published, 1)
+			republished := channel.published[0]
+			assert.Empty(t, republished.exchange)
+			assert.Equal(t, "emails", republished.key)
+			assert.Equal(t, tt.want, republished.msg.Headers[messaging.HeaderRetryCount])
+			assert.Equal(t, "abc", republished.msg.Headers["x-trace-id"])
+			assert.Equal(t, "msg-1", republished.msg.MessageId)
+			assert.Equal(t, "application/json", republished.msg.ContentType)
+			assert.JSONEq(t, `{"to":"john@example.com"}`, string(republished.msg.Body))
+			assert.Equal(t, amqp.Persistent, republished.msg.DeliveryMode)
+		})
+	}
+}
+
+func TestConsumer_RepublishFailureRequeues(t *testing.T) {
+	// Arrange
+	channel := newFakeChannel()
+	channel.publishErr = errors.New("channel closed")
+
+	// Act
+	ack := consumeOne(t, channel, 3, errors.New("smtp unavailable"), amqp.Delivery{Body: []byte("hello")})
+
+	// Assert
+	assert.False(t, ack.acked)
+	assert.True(t, ack.nacked)
+	assert.True(t, ack.requeue)
+}
+
+func TestConsumer_DeadLetters(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxRetries int
+		retries    int32
+		err        error
+	}{
+		{name: "retries exhausted", maxRetries: 3, retries: 3, err: errors.New("smtp unavailable")},
+		{name: "retries disabled", maxRetries: 0, retries: 0, err: errors.New("smtp unavailable")},
+		{name: "permanent error", maxRetries: 3, retries: 0, err: messaging.Permanent(errors.New("malformed payload"))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			channel := newFakeChannel()
+
+			// Act
+			ack := consumeOne(t, channel, tt.maxRetries, tt.err, amqp.Delivery{
+				Headers: amqp.Table{messaging.HeaderRetryCount: tt.retries},
+				Body:    []byte("hello"),
+			})
+
+			// Assert
+			assert.False(t, ack.acked)
+			assert.True(t, ack.nacked)
+			assert.False(t, ack.requeue, "a rejected message must go to the dead-letter exchange")
+			assert.Empty(t, channel.published)
+		})
+	}
+}
+
+func TestDeclareQueueWithDeadLetter(t *testing.T) {
+	// Arrange
+	channel := newFakeChannel()
+
+	// Act
+	err := messaging.DeclareQueueWithDeadLetter(channel, "emails")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"emails.dlx " + amqp.ExchangeFanout}, channel.exchanges)
+	assert.Equal(t, []declaredQueue{
+		{name: "emails.dlq", durable: true},
+		{name: "emails", durable: true, args: amqp.Table{"x-dead-letter-exchange": "emails.dlx"}},
+	}, channel.queues)
+	assert.Equal(t, [][2]string{{"emails.dlq", "emails.dlx"}}, channel.bindings)
+}
+
+func TestDeclareQueueWithDeadLetter_Error(t *testing.T) {
+	// Arrange
+	channel := newFakeChannel()
+	channel.declareErr = errors.New("access refused")
+
+	// Act
+	err := messaging.DeclareQueueWithDeadLetter(channel, "emails")
+
+	// Assert
+	require.Error(t, err)
+	assert.EqualError(t, err, "failed to declare dead-letter queue: access refused")
+	assert.Empty(t, channel.bindings)
+}
@@ -0,0 +1,87 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/TubagusAldiMY/go-template/internal/shared/crud"
+	sharedErrors "github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type widgetResponse struct {
+	Label string
+}
+
+// widgetGetter serves widgets from a map, or fails every call with err.
+type widgetGetter struct {
+	widgets map[string]*widget
+	err     error
+}
+
+func (g widgetGetter) GetByID(_ context.Context, id string) (*widget, error) {
+	if g.err != nil {
+		return nil, g.err
+	}
+	if w, ok := g.widgets[id]; ok {
+		return w, nil
+	}
+	return nil, errWidgetNotFound
+}
+
+func newWidgetBase(getter widgetGetter) crud.Base[widget, widgetResponse] {
+	return crud.NewBase(getter, "widget", errWidgetNotFound, func(w *widget) *widgetResponse {
+		return &widgetResponse{Label: w.ID + ":" + w.Name}
+	})
+}
+
+func TestBase_GetMapsToResponse(t *testing.T) {
+	// Arrange
+	base := newWidgetBase(widgetGetter{widgets: map[string]*widget{"w-1": {ID: "w-1", Name: "gear"}}})
+
+	// Act
+	got, err := base.Get(context.Background(), "w-1")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "w-1:gear", got.Label)
+}
+
+func TestBase_LoadPassesNotFoundThrough(t *testing.T) {
+	// Arrange
+	base := newWidgetBase(widgetGetter{})
+
+	// Act
+	got, err := base.Load(context.Background(), "missing")
+
+	// Assert
+	assert.Nil(t, got)
+	assert.ErrorIs(t, err, errWidgetNotFound)
+}
+
+func TestBase_LoadHidesStorageErrors(t *testing.T) {
+	// Arrange
+	base := newWidgetBase(widgetGetter{err: errors.New("connection reset")})
+
+	// Act
+	_, err := base.Load(context.Background(), "w-1")
+
+	// Assert
+	assert.ErrorIs(t, err, sharedErrors.ErrInternal)
+	assert.NotContains(t, err.Error(), "connection reset")
+}
+
+func TestBase_ResponsesKeepsOrder(t *testing.T) {
+	// Arrange
+	base := newWidgetBase(widgetGetter{})
+
+	// Act
+	got := base.Responses([]*widget{{ID: "a", Name: "x"}, {ID: "b", Name: "y"}})
+
+	// Assert
+	require.Len(t, got, 2)
+	assert.Equal(t, "a:x", got[0].Label)
+	assert.Equal(t, "b:y", got[1].Label)
+}
@@ -0,0 +1,108 @@
+package usecase_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/internal/delivery/http/middleware"
+	"github.com/TubagusAldiMY/go-template/internal/shared/constants"
+	"github.com/TubagusAldiMY/go-template/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newCSRFRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.CSRF())
+	router.GET("/csrf-token", middleware.CSRFToken(24*time.Hour, true))
+	router.Any("/resource", func(c *gin.Context) {
+		response.OK(c, "ok", nil)
+	})
+	return router
+}
+
+func TestCSRF(t *testing.T) {
+	tests := []struct {
+		name          string
+		method        string
+		authorization string
+		accessCookie  bool
+		refreshCookie bool
+		csrfCookie    string
+		csrfHeader    string
+		want          int
+	}{
+		{name: "safe method with cookie auth", method: http.MethodGet, accessCookie: true, want: http.StatusOK},
+		{name: "bearer auth", method: http.MethodPost, authorization: "Bearer token", accessCookie: true, want: http.StatusOK},
+		{name: "no credentials", method: http.MethodPost, want: http.StatusOK},
+		{name: "cookie auth without token", method: http.MethodPost, accessCookie: true, want: http.StatusForbidden},
+		{name: "refresh cookie without token", method: http.MethodPost, refreshCookie: true, want: http.StatusForbidden},
+		{name: "cookie auth with header only", method: http.MethodDelete, accessCookie: true, csrfHeader: "abc", want: http.StatusForbidden},
+		{name: "cookie auth with mismatched token", method: http.MethodPut, accessCookie: true, csrfCookie: "abc", csrfHeader: "abd", want: http.StatusForbidden},
+		{name: "cookie auth with matching token", method: http.MethodPost, accessCookie: true, csrfCookie: "abc", csrfHeader: "abc", want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			router := newCSRFRouter()
+			req := httptest.NewRequest(tt.method, "/resource", nil)
+			if tt.authorization != "" {
+				req.Header.Set(constants.HeaderAuthorization, tt.authorization)
+			}
+			if tt.accessCookie {
+				req.AddCookie(&http.Cookie{Name: constants.CookieAccessToken, Value: "token"})
+			}
+			if tt.refreshCookie {
+				req.AddCookie(&http.Cookie{Name: constants.CookieRefreshToken, Value: "token"})
+			}
+			if tt.csrfCookie != "" {
+				req.AddCookie(&http.Cookie{Name: constants.CookieCSRFToken, Value: tt.csrfCookie})
+			}
+			if tt.csrfHeader != "" {
+				req.Header.Set(constants.HeaderCSRFToken, tt.csrfHeader)
+			}
+			rec := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(rec, req)
+
+			// Assert
+			assert.Equal(t, tt.want, rec.Code)
+		})
+	}
+}
+
+func TestCSRFToken_IssuesUsableToken(t *testing.T) {
+	// Arrange
+	router := newCSRFRouter()
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/csrf-token", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	cookies := rec.Result().Cookies()
+	require.Len(t, cookies, 1)
+	issued := cookies[0]
+
+	req := httptest.NewRequest(http.MethodPost, "/resource", nil)
+	req.AddCookie(&http.Cookie{Name: constants.CookieAccessToken, Value: "token"})
+	req.AddCookie(&http.Cookie{Name: issued.Name, Value: issued.Value})
+	req.Header.Set(constants.HeaderCSRFToken, issued.Value)
+	rec = httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(rec, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, constants.CookieCSRFToken, issued.Name)
+	assert.NotEmpty(t, issued.Value)
+	assert.Equal(t, int((24 * time.Hour).Seconds()), issued.MaxAge)
+	assert.True(t, issued.Secure)
+	assert.False(t, issued.HttpOnly)
+	assert.Equal(t, http.SameSiteStrictMode, issued.SameSite)
+}
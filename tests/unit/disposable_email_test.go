@@ -0,0 +1,127 @@
+package usecase_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	userHttp "github.com/TubagusAldiMY/go-template/internal/domain/user/delivery/http"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/dto"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/usecase"
+	sharedErrors "github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/TubagusAldiMY/go-template/pkg/validator"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDisposableDomains_MatchesCaseInsensitivelyAndSubdomains(t *testing.T) {
+	// Arrange
+	domains := validator.DefaultDisposableDomains()
+
+	// Act & Assert
+	assert.True(t, domains.IsDisposable("someone@mailinator.com"))
+	assert.True(t, domains.IsDisposable("Someone@MAILINATOR.com"))
+	assert.True(t, domains.IsDisposable("someone@eu.mailinator.com"))
+	assert.False(t, domains.IsDisposable("someone@example.com"))
+	assert.False(t, domains.IsDisposable("someone@notmailinator.com"))
+	assert.False(t, domains.IsDisposable("not-an-email"))
+}
+
+func TestParseDisposableDomains_ReplacesEmbeddedList(t *testing.T) {
+	// Arrange
+	list := "# internal blocklist\n\nThrowaway.Example\n"
+
+	// Act
+	domains, err := validator.ParseDisposableDomains(strings.NewReader(list))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 1, domains.Len())
+	assert.True(t, domains.IsDisposable("a@throwaway.example"))
+	assert.False(t, domains.IsDisposable("a@mailinator.com"))
+}
+
+func TestRegister_RejectsDisposableEmail(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis),
+		usecase.WithDisposableEmailCheck(validator.DefaultDisposableDomains()))
+	req := &dto.RegisterRequest{
+		Email:    "spam@Mailinator.com",
+		Username: "spammer",
+		Password: "SecurePass123!",
+		FullName: "Spam Bot",
+	}
+
+	// Act
+	result, err := uc.Register(context.Background(), req)
+
+	// Assert
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, sharedErrors.ErrDisposableEmail)
+	mockRepo.AssertNotCalled(t, "ExistsByEmail", mock.Anything, mock.Anything)
+}
+
+func TestRegister_AllowsDisposableEmailWithoutCheck(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockHasher := new(MockPasswordHasher)
+	uc := usecase.NewUserUsecase(mockRepo, mockHasher, new(MockJWTManager), expectStatsInvalidation(new(MockRedis)))
+	req := &dto.RegisterRequest{
+		Email:    "tester@mailinator.com",
+		Username: "tester",
+		Password: "SecurePass123!",
+		FullName: "Test User",
+	}
+
+	mockRepo.On("ExistsByEmail", mock.Anything, req.Email).Return(false, nil)
+	mockRepo.On("ExistsByUsername", mock.Anything, req.Username).Return(false, nil)
+	mockHasher.On("Hash", req.Password).Return("hashedpassword", nil)
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.User")).Return(nil)
+
+	// Act
+	result, err := uc.Register(context.Background(), req)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, req.Email, result.Email)
+}
+
+func TestRegisterHandler_DisposableEmailIs422(t *testing.T) {
+	// Arrange
+	require.NoError(t, validator.Init())
+	gin.SetMode(gin.TestMode)
+	uc := usecase.NewUserUsecase(new(MockUserRepository), new(MockPasswordHasher), new(MockJWTManager), new(MockRedis),
+		usecase.WithDisposableEmailCheck(validator.DefaultDisposableDomains()))
+	router := gin.New()
+	router.POST("/auth/register", userHttp.NewUserHandler(uc).Register)
+
+	body, _ := json.Marshal(map[string]string{
+		"email":     "spam@sub.guerrillamail.com",
+		"username":  "spammer",
+		"password":  "SecurePass123!",
+		"full_name": "Spam Bot",
+	})
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/auth/register", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	var resp struct {
+		Code   string            `json:"code"`
+		Errors map[string]string `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, sharedErrors.CodeDisposableEmail, resp.Code)
+	assert.Contains(t, resp.Errors, "email")
+}
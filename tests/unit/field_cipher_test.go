@@ -0,0 +1,103 @@
+package usecase_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/TubagusAldiMY/go-template/pkg/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testPIIKey      = []byte("0123456789abcdef0123456789abcdef")
+	testPIIIndexKey = []byte("fedcba9876543210fedcba9876543210")
+)
+
+func newFieldCipher(t *testing.T) *crypto.FieldCipher {
+	t.Helper()
+	fields, err := crypto.NewFieldCipher(testPIIKey, testPIIIndexKey)
+	require.NoError(t, err)
+	return fields
+}
+
+func TestFieldCipher_EncryptDecrypt(t *testing.T) {
+	// Arrange
+	fields := newFieldCipher(t)
+
+	// Act
+	first, err := fields.Encrypt("john@example.com")
+	require.NoError(t, err)
+	second, err := fields.Encrypt("john@example.com")
+	require.NoError(t, err)
+	plaintext, err := fields.Decrypt(first)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "john@example.com", plaintext)
+	assert.NotEqual(t, first, second)
+	assert.False(t, strings.Contains(first, "john"))
+}
+
+func TestFieldCipher_DecryptPassesPlaintextThrough(t *testing.T) {
+	// Arrange
+	fields := newFieldCipher(t)
+
+	// Act
+	plaintext, err := fields.Decrypt("John Doe")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "John Doe", plaintext)
+}
+
+func TestFieldCipher_DecryptRejectsForeignKey(t *testing.T) {
+	// Arrange
+	other, err := crypto.NewFieldCipher(testPIIIndexKey, testPIIKey)
+	require.NoError(t, err)
+	ciphertext, err := other.Encrypt("John Doe")
+	require.NoError(t, err)
+
+	// Act
+	_, err = newFieldCipher(t).Decrypt(ciphertext)
+
+	// Assert
+	assert.ErrorIs(t, err, crypto.ErrInvalidCiphertext)
+}
+
+func TestFieldCipher_BlindIndex(t *testing.T) {
+	// Arrange
+	fields := newFieldCipher(t)
+	other, err := crypto.NewFieldCipher(testPIIKey, []byte("another-blind-index-key-32-bytes"))
+	require.NoError(t, err)
+
+	// Act
+	index := fields.BlindIndex("john@example.com")
+
+	// Assert
+	assert.Len(t, index, 64)
+	assert.Equal(t, index, fields.BlindIndex("john@example.com"))
+	assert.NotEqual(t, index, fields.BlindIndex("jane@example.com"))
+	assert.NotEqual(t, index, other.BlindIndex("john@example.com"))
+}
+
+func TestNewFieldCipher_RejectsKeys(t *testing.T) {
+	tests := []struct {
+		name          string
+		encryptionKey []byte
+		indexKey      []byte
+	}{
+		{name: "short encryption key", encryptionKey: []byte("short"), indexKey: testPIIIndexKey},
+		{name: "short index key", encryptionKey: testPIIKey, indexKey: []byte("short")},
+		{name: "same keys", encryptionKey: testPIIKey, indexKey: testPIIKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			_, err := crypto.NewFieldCipher(tt.encryptionKey, tt.indexKey)
+
+			// Assert
+			assert.Error(t, err)
+		})
+	}
+}
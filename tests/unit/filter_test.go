@@ -0,0 +1,65 @@
+package usecase_test
+
+import (
+	"testing"
+
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilter_NumbersPlaceholdersAcrossGroups(t *testing.T) {
+	// Arrange
+	filter := database.NewFilter()
+	filter.Where("deleted_at IS NULL")
+	filter.Where("role = ?", "admin")
+	filter.Or(func(g *database.Filter) {
+		g.Where("status = ?", "active")
+		g.And(func(g *database.Filter) {
+			g.Where("status = ?", "banned")
+			g.Where("updated_at > ?", "2024-01-01")
+		})
+	})
+
+	// Act
+	limit := filter.Arg(20)
+
+	// Assert
+	assert.Equal(t, " WHERE deleted_at IS NULL AND role = $1 AND (status = $2 OR (status = $3 AND updated_at > $4))", filter.SQL())
+	assert.Equal(t, "$5", limit)
+	assert.Equal(t, []any{"admin", "active", "banned", "2024-01-01", 20}, filter.Args())
+}
+
+func TestFilter_EmptyRendersNothing(t *testing.T) {
+	// Arrange
+	filter := database.NewFilter()
+
+	// Act
+	filter.Or(func(*database.Filter) {})
+
+	// Assert
+	assert.Empty(t, filter.SQL())
+	assert.Empty(t, filter.Args())
+}
+
+func TestFilter_ArgCanBeReused(t *testing.T) {
+	// Arrange
+	filter := database.NewFilter()
+
+	// Act
+	term := filter.Arg("%john%")
+	filter.Or(func(g *database.Filter) {
+		g.Where("email ILIKE " + term)
+		g.Where("username ILIKE " + term)
+	})
+	filter.Where("role = ?", "user")
+
+	// Assert
+	assert.Equal(t, " WHERE (email ILIKE $1 OR username ILIKE $1) AND role = $2", filter.SQL())
+	assert.Equal(t, []any{"%john%", "user"}, filter.Args())
+}
+
+func TestFilter_WherePanicsOnPlaceholderMismatch(t *testing.T) {
+	filter := database.NewFilter()
+
+	assert.Panics(t, func() { filter.Where("a = ? AND b = ?", 1) })
+}
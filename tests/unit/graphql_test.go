@@ -0,0 +1,204 @@
+package usecase_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	userGraphql "github.com/TubagusAldiMY/go-template/internal/domain/user/delivery/graphql"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/entity"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/repository"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/usecase"
+	"github.com/TubagusAldiMY/go-template/pkg/appcontext"
+	"github.com/TubagusAldiMY/go-template/pkg/validator"
+)
+
+type graphqlResponse struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string                 `json:"message"`
+		Extensions map[string]interface{} `json:"extensions"`
+	} `json:"errors"`
+}
+
+// serveGraphQL posts query to a router authenticating every request as
+// user-123 with role.
+func serveGraphQL(t *testing.T, uc *usecase.UserUsecase, role, query string, variables map[string]interface{}) (*httptest.ResponseRecorder, graphqlResponse) {
+	t.Helper()
+	require.NoError(t, validator.Init())
+	gin.SetMode(gin.TestMode)
+
+	router := gin.New()
+	router.POST("/api/v1/graphql", func(c *gin.Context) {
+		ctx := appcontext.WithUserID(c.Request.Context(), "user-123")
+		c.Request = c.Request.WithContext(appcontext.WithRole(ctx, role))
+	}, userGraphql.NewHandler(userGraphql.NewSchema(uc)).Serve)
+
+	body, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	require.NoError(t, err)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/graphql", bytes.NewReader(body)))
+
+	var resp graphqlResponse
+	if w.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	}
+	return w, resp
+}
+
+func TestGraphQL_Me(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis))
+	mockRepo.On("GetByID", mock.Anything, "user-123").Return(&entity.User{
+		ID: "user-123", Email: "john@example.com", Username: "john", Role: "user", Status: "active",
+	}, nil)
+
+	// Act
+	w, resp := serveGraphQL(t, uc, "user", `{ me { id email username avatarUrl lastLoginAt } }`, nil)
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{"id":"user-123","email":"john@example.com","username":"john","avatarUrl":null,"lastLoginAt":null}`, string(resp.Data["me"]))
+}
+
+func TestGraphQL_UsersRequiresPermission(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis))
+
+	// Act
+	w, resp := serveGraphQL(t, uc, "user", `{ users { total items { id } } }`, nil)
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, "Insufficient permissions", resp.Errors[0].Message)
+	assert.Equal(t, "FORBIDDEN", resp.Errors[0].Extensions["code"])
+	mockRepo.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+}
+
+func TestGraphQL_UsersAsAdmin(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis))
+	users := []*entity.User{{ID: "user-456", Email: "jane@example.com", Username: "jane", Role: "user", Status: "banned"}}
+	mockRepo.On("List", mock.Anything, mock.MatchedBy(func(p repository.ListParams) bool {
+		return p.Page == 2 && p.PageSize == 20 && p.Status == "banned"
+	})).Return(users, int64(21), nil)
+
+	// Act
+	w, resp := serveGraphQL(t, uc, "admin",
+		`query($status: String) { users(filter: {status: $status}, page: 2) { total page pageSize items { id status } } }`,
+		map[string]interface{}{"status": "banned"})
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, resp.Errors)
+	assert.JSONEq(t, `{"total":21,"page":2,"pageSize":20,"items":[{"id":"user-456","status":"banned"}]}`, string(resp.Data["users"]))
+}
+
+func TestGraphQL_UsersInvalidFilter(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis))
+
+	// Act
+	w, resp := serveGraphQL(t, uc, "admin", `{ users(filter: {sortBy: "password"}) { total } }`, nil)
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, "INVALID_INPUT", resp.Errors[0].Extensions["code"])
+	assert.Contains(t, resp.Errors[0].Extensions["fields"], "sort_by")
+	mockRepo.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+}
+
+func TestGraphQL_UserByID(t *testing.T) {
+	tests := []struct {
+		name     string
+		role     string
+		id       string
+		wantCode string
+	}{
+		{name: "self", role: "user", id: "user-123"},
+		{name: "other user without permission", role: "user", id: "user-456", wantCode: "FORBIDDEN"},
+		{name: "other user as admin", role: "admin", id: "user-456"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockUserRepository)
+			uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis))
+			mockRepo.On("GetByID", mock.Anything, tt.id).Return(&entity.User{ID: tt.id, Status: "active"}, nil)
+
+			// Act
+			_, resp := serveGraphQL(t, uc, tt.role, `query($id: ID!) { user(id: $id) { id } }`,
+				map[string]interface{}{"id": tt.id})
+
+			// Assert
+			if tt.wantCode != "" {
+				require.Len(t, resp.Errors, 1)
+				assert.Equal(t, tt.wantCode, resp.Errors[0].Extensions["code"])
+				mockRepo.AssertNotCalled(t, "GetByID", mock.Anything, tt.id)
+				return
+			}
+			assert.Empty(t, resp.Errors)
+			assert.JSONEq(t, `{"id":"`+tt.id+`"}`, string(resp.Data["user"]))
+		})
+	}
+}
+
+func TestGraphQL_UpdateProfileValidation(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis))
+
+	// Act
+	_, resp := serveGraphQL(t, uc, "user", `mutation { updateProfile(input: {email: "not-an-email"}) { id } }`, nil)
+
+	// Assert
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, "Validation failed", resp.Errors[0].Message)
+	assert.Equal(t, "INVALID_INPUT", resp.Errors[0].Extensions["code"])
+	assert.Contains(t, resp.Errors[0].Extensions["fields"], "email")
+	mockRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+}
+
+func TestGraphQL_ChangePasswordWrongOldPassword(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockHasher := new(MockPasswordHasher)
+	uc := usecase.NewUserUsecase(mockRepo, mockHasher, new(MockJWTManager), new(MockRedis))
+	user := &entity.User{ID: "user-123", Password: "hashedpassword", Status: "active"}
+	mockRepo.On("GetByID", mock.Anything, "user-123").Return(user, nil)
+	mockHasher.On("IsValid", user.Password, "WrongPass123!").Return(false)
+
+	// Act
+	_, resp := serveGraphQL(t, uc, "user",
+		`mutation { changePassword(input: {oldPassword: "WrongPass123!", newPassword: "N3wSecure!Pass"}) }`, nil)
+
+	// Assert
+	require.Len(t, resp.Errors, 1)
+	assert.Equal(t, "INVALID_PASSWORD", resp.Errors[0].Extensions["code"])
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestGraphQL_MissingQuery(t *testing.T) {
+	// Arrange
+	uc := usecase.NewUserUsecase(new(MockUserRepository), new(MockPasswordHasher), new(MockJWTManager), new(MockRedis))
+
+	// Act
+	w, _ := serveGraphQL(t, uc, "user", "", nil)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
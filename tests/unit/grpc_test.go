@@ -0,0 +1,253 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	userv1 "github.com/TubagusAldiMY/go-template/api/proto/user/v1"
+	"github.com/TubagusAldiMY/go-template/internal/delivery/grpc/interceptor"
+	grpcServer "github.com/TubagusAldiMY/go-template/internal/delivery/grpc/server"
+	userGrpc "github.com/TubagusAldiMY/go-template/internal/domain/user/delivery/grpc"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/entity"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/usecase"
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/config"
+	sharedErrors "github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/TubagusAldiMY/go-template/pkg/jwt"
+	"github.com/TubagusAldiMY/go-template/pkg/validator"
+)
+
+const grpcTestSecret = "0123456789abcdef0123456789abcdef"
+
+// newGRPCClient serves uc over an in-memory connection and returns a client
+// for it.
+func newGRPCClient(t *testing.T, uc *usecase.UserUsecase) userv1.UserServiceClient {
+	t.Helper()
+	require.NoError(t, validator.Init())
+
+	srv := grpcServer.New(&grpcServer.Config{
+		JWTManager: jwt.NewManager(grpcTestSecret, 15*time.Minute, time.Hour),
+		UserServer: userGrpc.NewUserServer(uc),
+	})
+	listener := bufconn.Listen(1 << 20)
+	go func() { _ = srv.Serve(listener) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return userv1.NewUserServiceClient(conn)
+}
+
+// withToken returns a context sending an access token for user-123 with role.
+func withToken(t *testing.T, role string) context.Context {
+	t.Helper()
+	token, err := jwt.NewManager(grpcTestSecret, 15*time.Minute, time.Hour).
+		GenerateAccessToken(context.Background(), "user-123", "john@example.com", role)
+	require.NoError(t, err)
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+// errorReason returns the reason of the ErrorInfo detail in err's status.
+func errorReason(err error) string {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			return info.Reason
+		}
+	}
+	return ""
+}
+
+func TestGRPC_GetProfile(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	client := newGRPCClient(t, usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis)))
+	created := time.Date(2026, 3, 1, 9, 30, 0, 0, time.UTC)
+	mockRepo.On("GetByID", mock.Anything, "user-123").Return(&entity.User{
+		ID: "user-123", Email: "john@example.com", Username: "john", Role: "user", Status: "active", CreatedAt: created,
+	}, nil)
+
+	// Act
+	user, err := client.GetProfile(withToken(t, "user"), &userv1.GetProfileRequest{})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "user-123", user.GetId())
+	assert.Equal(t, "john", user.GetUsername())
+	assert.Equal(t, created, user.GetCreatedAt().AsTime())
+	assert.Nil(t, user.GetLastLoginAt())
+}
+
+func TestGRPC_AuthInterceptor(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  context.Context
+	}{
+		{name: "missing token", ctx: context.Background()},
+		{name: "not a bearer token", ctx: metadata.AppendToOutgoingContext(context.Background(), "authorization", "Basic abc")},
+		{name: "invalid token", ctx: metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer not-a-jwt")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockUserRepository)
+			client := newGRPCClient(t, usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis)))
+
+			// Act
+			_, err := client.GetProfile(tt.ctx, &userv1.GetProfileRequest{})
+
+			// Assert
+			assert.Equal(t, codes.Unauthenticated, status.Code(err))
+			mockRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestGRPC_ListUsersRequiresPermission(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	client := newGRPCClient(t, usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis)))
+
+	// Act
+	_, err := client.ListUsers(withToken(t, "user"), &userv1.ListUsersRequest{})
+
+	// Assert
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	assert.Equal(t, "FORBIDDEN", errorReason(err))
+	mockRepo.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+}
+
+func TestGRPC_ListUsersAsAdmin(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	client := newGRPCClient(t, usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis)))
+	users := []*entity.User{{ID: "user-456", Email: "jane@example.com", Username: "jane", Status: "active"}}
+	mockRepo.On("List", mock.Anything, mock.Anything).Return(users, int64(1), nil)
+
+	// Act
+	resp, err := client.ListUsers(withToken(t, "admin"), &userv1.ListUsersRequest{Status: "active"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), resp.GetTotal())
+	assert.Equal(t, int32(1), resp.GetPage())
+	assert.Equal(t, int32(20), resp.GetPageSize())
+	require.Len(t, resp.GetUsers(), 1)
+	assert.Equal(t, "user-456", resp.GetUsers()[0].GetId())
+}
+
+func TestGRPC_RegisterValidation(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	client := newGRPCClient(t, usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis)))
+
+	// Act
+	_, err := client.Register(context.Background(), &userv1.RegisterRequest{
+		Email: "not-an-email", Username: "john", Password: "SecurePass123!", FullName: "John Doe",
+	})
+
+	// Assert
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, "INVALID_INPUT", errorReason(err))
+	var fields []string
+	for _, detail := range status.Convert(err).Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, violation := range badRequest.GetFieldViolations() {
+				fields = append(fields, violation.GetField())
+			}
+		}
+	}
+	assert.Equal(t, []string{"email"}, fields)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestGRPC_LoginInactiveAccount(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	client := newGRPCClient(t, usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis)))
+	mockRepo.On("GetByEmail", mock.Anything, "john@example.com").Return(&entity.User{ID: "user-123", Status: "banned"}, nil)
+
+	// Act
+	_, err := client.Login(context.Background(), &userv1.LoginRequest{Identifier: "john@example.com", Password: "SecurePass123!"})
+
+	// Assert
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Equal(t, "Account is not active", status.Convert(err).Message())
+}
+
+func TestErrorsInterceptor_MapsErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantCode   codes.Code
+		wantMsg    string
+		wantReason string
+	}{
+		{name: "not found", err: sharedErrors.ErrUserNotFound, wantCode: codes.NotFound, wantMsg: "User not found", wantReason: "USER_NOT_FOUND"},
+		{name: "conflict", err: sharedErrors.ErrEmailAlreadyExists, wantCode: codes.AlreadyExists, wantMsg: "Email already exists", wantReason: "EMAIL_ALREADY_EXISTS"},
+		{name: "stale data", err: sharedErrors.ErrStaleData, wantCode: codes.Aborted, wantMsg: "Resource was modified concurrently, please retry", wantReason: "STALE_DATA"},
+		{name: "unprocessable", err: sharedErrors.ErrPasswordTooWeak, wantCode: codes.InvalidArgument, wantMsg: "Password is too weak", wantReason: "PASSWORD_TOO_WEAK"},
+		{name: "internal", err: sharedErrors.ErrInternal, wantCode: codes.Internal, wantMsg: "Internal server error", wantReason: "INTERNAL_ERROR"},
+		{name: "unknown", err: errors.New("connection refused"), wantCode: codes.Internal, wantMsg: "Internal server error", wantReason: "INTERNAL_ERROR"},
+		{name: "canceled", err: context.Canceled, wantCode: codes.Canceled, wantMsg: "Request canceled"},
+		{name: "status", err: status.Error(codes.Unavailable, "try later"), wantCode: codes.Unavailable, wantMsg: "try later"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			handler := func(context.Context, interface{}) (interface{}, error) { return nil, tt.err }
+
+			// Act
+			_, err := interceptor.Errors()(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/test/Method"}, handler)
+
+			// Assert
+			st := status.Convert(err)
+			assert.Equal(t, tt.wantCode, st.Code())
+			assert.Equal(t, tt.wantMsg, st.Message())
+			assert.Equal(t, tt.wantReason, errorReason(err))
+		})
+	}
+}
+
+func TestRecoveryInterceptor_ReturnsInternal(t *testing.T) {
+	// Arrange
+	handler := func(context.Context, interface{}) (interface{}, error) { panic("boom") }
+
+	// Act
+	_, err := interceptor.Recovery()(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/test/Method"}, handler)
+
+	// Assert
+	assert.Equal(t, codes.Internal, status.Code(err))
+}
+
+func TestConfigValidate_GRPC(t *testing.T) {
+	// Arrange
+	cfg := validConfig()
+	cfg.Metrics = config.MetricsConfig{Enabled: true, Port: 9090}
+	cfg.GRPC = config.GRPCConfig{Enabled: true, Port: 9090}
+
+	// Act
+	err := cfg.Validate()
+
+	// Assert
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "GRPC_PORT: must differ from METRICS_PORT")
+}
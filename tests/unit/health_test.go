@@ -0,0 +1,177 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+
+	"github.com/TubagusAldiMY/go-template/internal/delivery/http/router"
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/config"
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/health"
+	"github.com/TubagusAldiMY/go-template/pkg/buildinfo"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serveStartupRouter(readiness *health.Readiness, path string) int {
+	r := router.SetupStartupRouter(&config.Config{App: config.AppConfig{Name: "test", Debug: true}}, readiness)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return w.Code
+}
+
+func TestStartupRouter_ReportsNotReadyUntilWarm(t *testing.T) {
+	// Arrange
+	readiness := health.NewReadiness()
+
+	// Act & Assert
+	assert.Equal(t, http.StatusOK, serveStartupRouter(readiness, "/health"))
+	assert.Equal(t, http.StatusServiceUnavailable, serveStartupRouter(readiness, "/health/ready"))
+	assert.Equal(t, http.StatusServiceUnavailable, serveStartupRouter(readiness, "/api/v1/users"))
+
+	readiness.SetReady(true)
+	assert.Equal(t, http.StatusOK, serveStartupRouter(readiness, "/health/ready"))
+
+	readiness.SetReady(false)
+	assert.Equal(t, http.StatusServiceUnavailable, serveStartupRouter(readiness, "/health/ready"))
+}
+
+func TestReadiness_FailingCheckMarksNotReady(t *testing.T) {
+	// Arrange
+	readiness := health.NewReadiness()
+	readiness.SetReady(true)
+	readiness.AddCheck("database", func(context.Context) error { return nil })
+	readiness.AddCheck("rabbitmq", func(context.Context) error {
+		return errors.New(`queue email.queue: Exception (404) Reason: "NOT_FOUND - no queue 'email.queue' in vhost '/'"`)
+	})
+	r := router.SetupStartupRouter(&config.Config{App: config.AppConfig{Name: "test", Debug: true}}, readiness)
+	w := httptest.NewRecorder()
+
+	// Act
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health/ready", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	var body struct {
+		Errors map[string]string `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Len(t, body.Errors, 1)
+	assert.Contains(t, body.Errors["rabbitmq"], "no queue 'email.queue'")
+}
+
+func TestReadiness_AddCheckReplacesSameName(t *testing.T) {
+	// Arrange
+	readiness := health.NewReadiness()
+	readiness.AddCheck("redis", func(context.Context) error { return errors.New("connection refused") })
+	readiness.AddCheck("redis", func(context.Context) error { return nil })
+
+	// Act
+	failures := readiness.Failures(context.Background())
+
+	// Assert
+	assert.Nil(t, failures)
+}
+
+func TestReadiness_ChecksRunWithDeadline(t *testing.T) {
+	// Arrange
+	readiness := health.NewReadiness()
+	readiness.AddCheck("slow", func(ctx context.Context) error {
+		if _, ok := ctx.Deadline(); !ok {
+			return errors.New("no deadline")
+		}
+		return nil
+	})
+
+	// Act
+	failures := readiness.Failures(context.Background())
+
+	// Assert
+	assert.Nil(t, failures)
+}
+
+func TestRouter_ResolvesClientIPOnlyThroughTrustedProxies(t *testing.T) {
+	tests := []struct {
+		name    string
+		trusted []string
+		want    string
+	}{
+		{name: "no trusted proxies ignores forwarding headers", trusted: nil, want: "10.0.0.5"},
+		{name: "trusted proxy forwards the client IP", trusted: []string{"10.0.0.0/8"}, want: "203.0.113.7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			cfg := &config.Config{
+				App:    config.AppConfig{Debug: true},
+				Server: config.ServerConfig{TrustedProxies: tt.trusted},
+			}
+			r := router.SetupStartupRouter(cfg, health.NewReadiness())
+			r.GET("/ip", func(c *gin.Context) { c.String(http.StatusOK, c.ClientIP()) })
+
+			req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+			req.RemoteAddr = "10.0.0.5:41000"
+			req.Header.Set("X-Forwarded-For", "203.0.113.7")
+			w := httptest.NewRecorder()
+
+			// Act
+			r.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, tt.want, w.Body.String())
+		})
+	}
+}
+
+func TestHealth_ReportsBuildInfo(t *testing.T) {
+	// Arrange
+	previous := buildinfo.Version
+	buildinfo.Version = "v1.4.0"
+	t.Cleanup(func() { buildinfo.Version = previous })
+	r := router.SetupStartupRouter(&config.Config{App: config.AppConfig{Name: "test", Debug: true}}, health.NewReadiness())
+	w := httptest.NewRecorder()
+
+	// Act
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Data map[string]string `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "test", body.Data["service"])
+	assert.Equal(t, "v1.4.0", body.Data["version"])
+	assert.NotEmpty(t, body.Data["commit"])
+	assert.NotEmpty(t, body.Data["build_date"])
+}
+
+func TestVersion_ReturnsBuildInfo(t *testing.T) {
+	// Arrange
+	previous := buildinfo.Commit
+	buildinfo.Commit = "0123abc"
+	t.Cleanup(func() { buildinfo.Commit = previous })
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/api/v1/version", router.Version)
+	w := httptest.NewRecorder()
+
+	// Act
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/version", nil))
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	var body struct {
+		Data buildinfo.Info `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "0123abc", body.Data.Commit)
+	assert.Equal(t, runtime.Version(), body.Data.GoVersion)
+	assert.NotEmpty(t, body.Data.Version)
+}
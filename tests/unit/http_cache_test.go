@@ -0,0 +1,134 @@
+package usecase_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/internal/delivery/http/middleware"
+	"github.com/TubagusAldiMY/go-template/internal/shared/constants"
+	"github.com/TubagusAldiMY/go-template/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newHTTPCacheRouter serves /profile, answering with the user named in the
+// X-User header, and /missing, which always fails, behind HTTPCache.
+func newHTTPCacheRouter(cfg middleware.CacheConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if userID := c.GetHeader("X-User"); userID != "" {
+			c.Set(constants.ContextKeyUserID, userID)
+		}
+	})
+	router.Use(middleware.HTTPCache(cfg))
+	router.GET("/profile", func(c *gin.Context) {
+		response.OK(c, "Profile retrieved", gin.H{"name": "John"})
+	})
+	router.GET("/missing", func(c *gin.Context) {
+		response.NotFound(c, "Not found")
+	})
+	router.POST("/profile", func(c *gin.Context) {
+		response.OK(c, "Profile updated", nil)
+	})
+	return router
+}
+
+func serveCached(router *gin.Engine, method, path, userID, ifNoneMatch string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if userID != "" {
+		req.Header.Set("X-User", userID)
+	}
+	if ifNoneMatch != "" {
+		req.Header.Set("If-None-Match", ifNoneMatch)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestHTTPCache_SetsHeaders(t *testing.T) {
+	// Arrange
+	router := newHTTPCacheRouter(middleware.CacheConfig{Vary: []string{"Accept-Language"}})
+
+	// Act
+	rec := serveCached(router, http.MethodGet, "/profile", "user-123", "")
+
+	// Assert
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Regexp(t, `^W/"[0-9a-f]{32}"$`, rec.Header().Get("ETag"))
+	assert.Equal(t, "private, no-cache", rec.Header().Get("Cache-Control"))
+	assert.Contains(t, rec.Header().Values("Vary"), "Authorization, X-API-Key, Cookie, Accept-Language")
+	assert.Contains(t, rec.Body.String(), "John")
+}
+
+func TestHTTPCache_NotModified(t *testing.T) {
+	// Arrange
+	router := newHTTPCacheRouter(middleware.CacheConfig{})
+	etag := serveCached(router, http.MethodGet, "/profile", "user-123", "").Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	// Act
+	rec := serveCached(router, http.MethodGet, "/profile", "user-123", `"other", `+etag)
+
+	// Assert
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.String())
+	assert.Equal(t, etag, rec.Header().Get("ETag"))
+	assert.Empty(t, rec.Header().Get(constants.HeaderContentType))
+}
+
+func TestHTTPCache_ETagDependsOnUser(t *testing.T) {
+	// Arrange
+	router := newHTTPCacheRouter(middleware.CacheConfig{})
+	etag := serveCached(router, http.MethodGet, "/profile", "user-123", "").Header().Get("ETag")
+
+	// Act
+	rec := serveCached(router, http.MethodGet, "/profile", "user-456", etag)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.NotEqual(t, etag, rec.Header().Get("ETag"))
+	assert.Contains(t, rec.Body.String(), "John")
+}
+
+func TestHTTPCache_PublicMaxAge(t *testing.T) {
+	// Arrange
+	router := newHTTPCacheRouter(middleware.CacheConfig{MaxAge: 5 * time.Minute})
+
+	// Act
+	rec := serveCached(router, http.MethodGet, "/profile", "", "")
+
+	// Assert
+	assert.Equal(t, "public, max-age=300", rec.Header().Get("Cache-Control"))
+}
+
+func TestHTTPCache_SkipsErrorsAndOtherMethods(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		want   int
+	}{
+		{name: "error response", method: http.MethodGet, path: "/missing", want: http.StatusNotFound},
+		{name: "post", method: http.MethodPost, path: "/profile", want: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			router := newHTTPCacheRouter(middleware.CacheConfig{})
+
+			// Act
+			rec := serveCached(router, tt.method, tt.path, "user-123", "*")
+
+			// Assert
+			assert.Equal(t, tt.want, rec.Code)
+			assert.Empty(t, rec.Header().Get("ETag"))
+			assert.Empty(t, rec.Header().Get("Cache-Control"))
+			assert.NotEmpty(t, rec.Body.String())
+		})
+	}
+}
@@ -0,0 +1,202 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/internal/delivery/http/middleware"
+	auditHttp "github.com/TubagusAldiMY/go-template/internal/domain/audit/delivery/http"
+	auditUsecase "github.com/TubagusAldiMY/go-template/internal/domain/audit/usecase"
+	userHttp "github.com/TubagusAldiMY/go-template/internal/domain/user/delivery/http"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/entity"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/usecase"
+	"github.com/TubagusAldiMY/go-template/internal/shared/constants"
+	sharedErrors "github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/TubagusAldiMY/go-template/pkg/appcontext"
+	"github.com/TubagusAldiMY/go-template/pkg/authz"
+	"github.com/TubagusAldiMY/go-template/pkg/jwt"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImpersonate_IssuesAuditedToken(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockJWT := new(MockJWTManager)
+	mockAudit := new(MockAuditLogger)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), mockJWT, new(MockRedis),
+		usecase.WithAuditLogger(mockAudit))
+
+	user := &entity.User{ID: "user-123", Email: "test@example.com", Role: "user", Status: "active"}
+	mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	mockJWT.On("GenerateImpersonationToken", mock.Anything, user.ID, user.Email, user.Role, "admin-1").Return("impersonation-token", nil)
+	mockAudit.On("Record", mock.Anything, "user.impersonated", user.ID, map[string]interface{}{
+		"expires_in": int64(300),
+	}).Return(nil)
+
+	// Act
+	result, err := uc.Impersonate(appcontext.WithRole(context.Background(), "admin"), "admin-1", user.ID)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "impersonation-token", result.AccessToken)
+	assert.Equal(t, "Bearer", result.TokenType)
+	assert.Equal(t, int64(300), result.ExpiresIn)
+	assert.Equal(t, user.ID, result.User.ID)
+	mockAudit.AssertExpectations(t)
+}
+
+func TestImpersonate_RejectsSelf(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis))
+
+	// Act
+	result, err := uc.Impersonate(context.Background(), "admin-1", "admin-1")
+
+	// Assert
+	assert.Nil(t, result)
+	assert.True(t, errors.Is(err, sharedErrors.ErrCannotImpersonate))
+	mockRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+}
+
+func TestImpersonateHandler_NonAdminCannotImpersonateAdmin(t *testing.T) {
+	// Arrange
+	policy, err := authz.ParsePolicy("admin=*;support=user:read,user:impersonate;user=")
+	require.NoError(t, err)
+	authz.Init(policy)
+	t.Cleanup(func() { authz.Init(authz.DefaultPolicy()) })
+
+	gin.SetMode(gin.TestMode)
+	mockRepo := new(MockUserRepository)
+	mockJWT := new(MockJWTManager)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), mockJWT, new(MockRedis))
+	mockRepo.On("GetByID", mock.Anything, "admin-1").Return(&entity.User{ID: "admin-1", Role: "admin", Status: "active"}, nil)
+
+	router := gin.New()
+	router.POST("/users/:id/impersonate", func(c *gin.Context) {
+		ctx := appcontext.WithUserID(c.Request.Context(), "support-1")
+		c.Request = c.Request.WithContext(appcontext.WithRole(ctx, "support"))
+	}, userHttp.NewUserHandler(uc).ImpersonateUser)
+	w := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/users/admin-1/impersonate", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	mockJWT.AssertNotCalled(t, "GenerateImpersonationToken", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestImpersonate_CoveredRolesOnly(t *testing.T) {
+	policy, err := authz.ParsePolicy("admin=*;support=user:read,user:impersonate;auditor=audit:read;user=")
+	require.NoError(t, err)
+	authz.Init(policy)
+	t.Cleanup(func() { authz.Init(authz.DefaultPolicy()) })
+
+	tests := []struct {
+		name       string
+		actorRole  string
+		targetRole string
+		want       bool
+	}{
+		{name: "admin impersonates admin", actorRole: "admin", targetRole: "admin", want: true},
+		{name: "support impersonates user", actorRole: "support", targetRole: "user", want: true},
+		{name: "support impersonates admin", actorRole: "support", targetRole: "admin", want: false},
+		{name: "support impersonates auditor", actorRole: "support", targetRole: "auditor", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockUserRepository)
+			mockJWT := new(MockJWTManager)
+			uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), mockJWT, new(MockRedis))
+			user := &entity.User{ID: "user-123", Email: "test@example.com", Role: tt.targetRole, Status: "active"}
+			mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+			mockJWT.On("GenerateImpersonationToken", mock.Anything, user.ID, user.Email, user.Role, "actor-1").Return("impersonation-token", nil)
+
+			// Act
+			_, err := uc.Impersonate(appcontext.WithRole(context.Background(), tt.actorRole), "actor-1", user.ID)
+
+			// Assert
+			if tt.want {
+				require.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, sharedErrors.ErrCannotImpersonate)
+		})
+	}
+}
+
+func TestImpersonate_UserNotFound(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis))
+	mockRepo.On("GetByID", mock.Anything, "missing").Return(nil, sharedErrors.ErrUserNotFound)
+
+	// Act
+	result, err := uc.Impersonate(context.Background(), "admin-1", "missing")
+
+	// Assert
+	assert.Nil(t, result)
+	assert.True(t, errors.Is(err, sharedErrors.ErrUserNotFound))
+}
+
+func TestImpersonate_AuditFailureWithholdsToken(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockJWT := new(MockJWTManager)
+	mockAudit := new(MockAuditLogger)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), mockJWT, new(MockRedis),
+		usecase.WithAuditLogger(mockAudit))
+
+	user := &entity.User{ID: "user-123", Email: "test@example.com", Role: "user", Status: "active"}
+	mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	mockJWT.On("GenerateImpersonationToken", mock.Anything, user.ID, user.Email, user.Role, "admin-1").Return("impersonation-token", nil)
+	mockAudit.On("Record", mock.Anything, "user.impersonated", user.ID, mock.Anything).Return(sharedErrors.ErrInternal)
+
+	// Act
+	result, err := uc.Impersonate(context.Background(), "admin-1", user.ID)
+
+	// Assert
+	assert.Nil(t, result)
+	assert.True(t, errors.Is(err, sharedErrors.ErrInternal))
+}
+
+func TestAuthMiddleware_AttributesImpersonationToAdmin(t *testing.T) {
+	// Arrange
+	manager := jwt.NewManager(testJWTSecret, 15*time.Minute, time.Hour, jwt.WithImpersonationDuration(time.Minute))
+	token, err := manager.GenerateImpersonationToken(context.Background(), "user-123", "test@example.com", "user", "admin-1")
+	require.NoError(t, err)
+
+	var contextActorID, requestActorID string
+	var actor auditUsecase.Actor
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/", middleware.AuthMiddleware(manager), auditHttp.ActorContext(), func(c *gin.Context) {
+		contextActorID = c.GetString(constants.ContextKeyActorID)
+		requestActorID, _ = appcontext.ActorID(c.Request.Context())
+		actor = auditUsecase.ActorFromContext(c.Request.Context())
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "admin-1", contextActorID)
+	assert.Equal(t, "admin-1", requestActorID)
+	assert.Equal(t, "admin-1", actor.UserID)
+	assert.Equal(t, "user-123", actor.OnBehalfOfID)
+}
@@ -0,0 +1,121 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/dto"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/entity"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/usecase"
+	sharedErrors "github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/TubagusAldiMY/go-template/pkg/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newIntrospectionUsecase(t *testing.T, repo *MockUserRepository) (*usecase.UserUsecase, *jwt.Manager) {
+	t.Helper()
+
+	manager := jwt.NewManager("test-secret", 15*time.Minute, time.Hour, jwt.WithIssuer("go-template"))
+	return usecase.NewUserUsecase(repo, new(MockPasswordHasher), manager, new(MockRedis)), manager
+}
+
+func TestIntrospectToken_ActiveToken(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	uc, manager := newIntrospectionUsecase(t, mockRepo)
+	user := &entity.User{ID: "user-123", Email: "test@example.com", Role: "admin", Status: "active"}
+	mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+
+	token, err := manager.GenerateAccessToken(context.Background(), user.ID, user.Email, user.Role)
+	require.NoError(t, err)
+
+	// Act
+	result, err := uc.IntrospectToken(context.Background(), &dto.IntrospectTokenRequest{Token: token})
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, result.Active)
+	assert.Equal(t, "access_token", result.TokenType)
+	assert.Equal(t, user.ID, result.Sub)
+	assert.Equal(t, user.Email, result.Email)
+	assert.Equal(t, user.Role, result.Role)
+	assert.Equal(t, "go-template", result.Iss)
+	assert.NotEmpty(t, result.JTI)
+	assert.InDelta(t, time.Now().Add(15*time.Minute).Unix(), result.Exp, 5)
+}
+
+func TestIntrospectToken_InvalidTokensAreInactive(t *testing.T) {
+	expired := jwt.NewManager("test-secret", -time.Minute, time.Hour)
+	expiredToken, err := expired.GenerateAccessToken(context.Background(), "user-123", "test@example.com", "user")
+	require.NoError(t, err)
+
+	foreign := jwt.NewManager("other-secret", time.Minute, time.Hour)
+	foreignToken, err := foreign.GenerateAccessToken(context.Background(), "user-123", "test@example.com", "user")
+	require.NoError(t, err)
+
+	for name, token := range map[string]string{
+		"malformed":       "not-a-jwt",
+		"expired":         expiredToken,
+		"wrong signature": foreignToken,
+	} {
+		t.Run(name, func(t *testing.T) {
+			// Arrange
+			mockRepo := new(MockUserRepository)
+			uc, _ := newIntrospectionUsecase(t, mockRepo)
+
+			// Act
+			result, err := uc.IntrospectToken(context.Background(), &dto.IntrospectTokenRequest{Token: token})
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, &dto.IntrospectTokenResponse{Active: false}, result)
+			mockRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestIntrospectToken_DeactivatedUserIsInactive(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	uc, manager := newIntrospectionUsecase(t, mockRepo)
+	banned := &entity.User{ID: "user-123", Email: "test@example.com", Role: "user", Status: "banned"}
+	mockRepo.On("GetByID", mock.Anything, banned.ID).Return(banned, nil)
+	mockRepo.On("GetByID", mock.Anything, "user-456").Return(nil, sharedErrors.ErrUserNotFound)
+
+	bannedToken, err := manager.GenerateAccessToken(context.Background(), banned.ID, banned.Email, banned.Role)
+	require.NoError(t, err)
+	deletedToken, err := manager.GenerateAccessToken(context.Background(), "user-456", "gone@example.com", "user")
+	require.NoError(t, err)
+
+	// Act
+	bannedResult, err := uc.IntrospectToken(context.Background(), &dto.IntrospectTokenRequest{Token: bannedToken})
+	require.NoError(t, err)
+	deletedResult, err := uc.IntrospectToken(context.Background(), &dto.IntrospectTokenRequest{Token: deletedToken})
+	require.NoError(t, err)
+
+	// Assert
+	assert.False(t, bannedResult.Active)
+	assert.Empty(t, bannedResult.Sub)
+	assert.False(t, deletedResult.Active)
+}
+
+func TestIntrospectToken_LookupFailure(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	uc, manager := newIntrospectionUsecase(t, mockRepo)
+	mockRepo.On("GetByID", mock.Anything, "user-123").Return(nil, errors.New("database unavailable"))
+
+	token, err := manager.GenerateAccessToken(context.Background(), "user-123", "test@example.com", "user")
+	require.NoError(t, err)
+
+	// Act
+	result, err := uc.IntrospectToken(context.Background(), &dto.IntrospectTokenRequest{Token: token})
+
+	// Assert
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, sharedErrors.ErrInternal)
+}
@@ -0,0 +1,134 @@
+package usecase_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/pkg/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testJWTSecret = "0123456789abcdef0123456789abcdef"
+
+func TestJWTManager_SetsAndEnforcesIssuerAndAudience(t *testing.T) {
+	// Arrange
+	manager := jwt.NewManager(testJWTSecret, time.Minute, time.Hour,
+		jwt.WithIssuer("go-template"), jwt.WithAudience("gateway"))
+
+	token, err := manager.GenerateAccessToken(context.Background(), "user-123", "test@example.com", "user")
+	require.NoError(t, err)
+
+	// Act
+	claims, err := manager.ValidateAccessToken(context.Background(), token)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "go-template", claims.Issuer)
+	assert.Equal(t, []string{"gateway"}, []string(claims.Audience))
+}
+
+func TestJWTManager_RejectsWrongOrMissingIssuerAndAudience(t *testing.T) {
+	tests := []struct {
+		name   string
+		signer *jwt.Manager
+	}{
+		{name: "missing claims", signer: jwt.NewManager(testJWTSecret, time.Minute, time.Hour)},
+		{name: "wrong issuer", signer: jwt.NewManager(testJWTSecret, time.Minute, time.Hour,
+			jwt.WithIssuer("someone-else"), jwt.WithAudience("gateway"))},
+		{name: "wrong audience", signer: jwt.NewManager(testJWTSecret, time.Minute, time.Hour,
+			jwt.WithIssuer("go-template"), jwt.WithAudience("other-service"))},
+	}
+
+	validator := jwt.NewManager(testJWTSecret, time.Minute, time.Hour,
+		jwt.WithIssuer("go-template"), jwt.WithAudience("gateway"))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			token, err := tt.signer.GenerateAccessToken(context.Background(), "user-123", "test@example.com", "user")
+			require.NoError(t, err)
+
+			// Act
+			_, err = validator.ValidateAccessToken(context.Background(), token)
+
+			// Assert
+			assert.ErrorIs(t, err, jwt.ErrInvalidToken)
+		})
+	}
+}
+
+func TestJWTManager_SkipsChecksWhenUnconfigured(t *testing.T) {
+	// Arrange
+	issuer := jwt.NewManager(testJWTSecret, time.Minute, time.Hour,
+		jwt.WithIssuer("go-template"), jwt.WithAudience("gateway"))
+	validator := jwt.NewManager(testJWTSecret, time.Minute, time.Hour, jwt.WithIssuer(""), jwt.WithAudience(""))
+
+	token, err := issuer.GenerateAccessToken(context.Background(), "user-123", "test@example.com", "user")
+	require.NoError(t, err)
+
+	// Act
+	_, err = validator.ValidateAccessToken(context.Background(), token)
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestJWTManager_RefreshTokenLifetime(t *testing.T) {
+	// Arrange
+	manager := jwt.NewManager(testJWTSecret, time.Minute, time.Hour,
+		jwt.WithRememberMeRefreshDuration(30*24*time.Hour))
+
+	regular, regularID, err := manager.GenerateRefreshToken("user-123", "session-1", 0)
+	require.NoError(t, err)
+	extended, _, err := manager.GenerateRefreshToken("user-123", "session-1", manager.RememberMeRefreshTokenDuration())
+	require.NoError(t, err)
+
+	// Act
+	regularClaims, regularErr := manager.ValidateRefreshToken(regular)
+	extendedClaims, extendedErr := manager.ValidateRefreshToken(extended)
+
+	// Assert
+	require.NoError(t, regularErr)
+	require.NoError(t, extendedErr)
+	assert.Equal(t, "user-123", regularClaims.UserID)
+	assert.Equal(t, regularID, regularClaims.TokenID)
+	assert.Equal(t, "session-1", regularClaims.SessionID)
+	assert.Equal(t, time.Hour, regularClaims.Lifetime)
+	assert.Equal(t, 30*24*time.Hour, extendedClaims.Lifetime)
+}
+
+func TestJWTManager_ImpersonationTokenNamesActor(t *testing.T) {
+	// Arrange
+	manager := jwt.NewManager(testJWTSecret, 15*time.Minute, time.Hour, jwt.WithImpersonationDuration(5*time.Minute))
+
+	token, err := manager.GenerateImpersonationToken(context.Background(), "user-123", "test@example.com", "user", "admin-1")
+	require.NoError(t, err)
+
+	// Act
+	claims, err := manager.ValidateAccessToken(context.Background(), token)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "user-123", claims.UserID)
+	assert.Equal(t, "user", claims.Role)
+	require.NotNil(t, claims.Act)
+	assert.Equal(t, "admin-1", claims.Act.Subject)
+	assert.Equal(t, 5*time.Minute, claims.ExpiresAt.Sub(claims.IssuedAt.Time))
+}
+
+func TestJWTManager_RegularTokensHaveNoActor(t *testing.T) {
+	// Arrange
+	manager := jwt.NewManager(testJWTSecret, time.Minute, time.Hour)
+
+	token, err := manager.GenerateAccessToken(context.Background(), "user-123", "test@example.com", "user")
+	require.NoError(t, err)
+
+	// Act
+	claims, err := manager.ValidateAccessToken(context.Background(), token)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Nil(t, claims.Act)
+}
@@ -0,0 +1,221 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/TubagusAldiMY/go-template/internal/delivery/http/middleware"
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/config"
+	"github.com/TubagusAldiMY/go-template/pkg/logger"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestFromContext_CarriesRequestFields(t *testing.T) {
+	// Arrange
+	core, logs := observer.New(zap.InfoLevel)
+	ctx := logger.NewContext(context.Background(), zap.New(core))
+	ctx = logger.WithContext(ctx, zap.String("request_id", "req-123"))
+	ctx = logger.WithContext(ctx, zap.String("user_id", "user-123"))
+
+	// Act
+	logger.FromContext(ctx).Info("user updated")
+
+	// Assert
+	require.Equal(t, 1, logs.Len())
+	fields := logs.All()[0].ContextMap()
+	assert.Equal(t, "req-123", fields["request_id"])
+	assert.Equal(t, "user-123", fields["user_id"])
+}
+
+func TestFromContext_FallsBackToGlobalLogger(t *testing.T) {
+	// Act
+	l := logger.FromContext(context.Background())
+
+	// Assert
+	assert.NotNil(t, l)
+}
+
+func TestRequestLogger_RedactsSensitiveHeaders(t *testing.T) {
+	// Arrange
+	output := filepath.Join(t.TempDir(), "access.log")
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.RequestLogger(config.LogConfig{
+		AccessLogFormat: "json",
+		Output:          []string{output},
+		RequestHeaders:  true,
+		RedactHeaders:   []string{"x-session-secret"},
+	}))
+	router.GET("/ping", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	req.Header.Set("Authorization", "Bearer secret-access-token")
+	req.Header.Set("Cookie", "access_token=secret-cookie-token")
+	req.Header.Set("X-API-Key", "secret-api-key")
+	req.Header.Set("X-Session-Secret", "secret-session")
+	req.Header.Set("Accept-Language", "en")
+
+	// Act
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	// Assert
+	raw, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "secret-")
+
+	var entry struct {
+		Headers map[string]string `json:"headers"`
+	}
+	require.NoError(t, json.Unmarshal(raw, &entry))
+	assert.Equal(t, "[REDACTED]", entry.Headers["Authorization"])
+	assert.Equal(t, "[REDACTED]", entry.Headers["Cookie"])
+	assert.Equal(t, "[REDACTED]", entry.Headers["X-Api-Key"])
+	assert.Equal(t, "[REDACTED]", entry.Headers["X-Session-Secret"])
+	assert.Equal(t, "en", entry.Headers["Accept-Language"])
+}
+
+// serveWithRequestID sends a request carrying requestID through RequestLogger
+// and returns the ID echoed in the response and the access log written.
+func serveWithRequestID(t *testing.T, policy, requestID string) (string, string) {
+	t.Helper()
+	output := filepath.Join(t.TempDir(), "access.log")
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.RequestLogger(config.LogConfig{
+		AccessLogFormat: "json",
+		Output:          []string{output},
+		RequestIDPolicy: policy,
+	}))
+	router.GET("/ping", func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+	if requestID != "" {
+		req.Header.Set("X-Request-ID", requestID)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	raw, err := os.ReadFile(output)
+	require.NoError(t, err)
+	return rec.Header().Get("X-Request-ID"), string(raw)
+}
+
+func TestRequestLogger_ReplacesMaliciousRequestID(t *testing.T) {
+	// Arrange
+	forged := "req-1\n{\"level\":\"error\",\"msg\":\"admin password reset\"}"
+
+	// Act
+	echoed, logged := serveWithRequestID(t, config.RequestIDPolicyToken, forged)
+
+	// Assert
+	assert.NotEqual(t, forged, echoed)
+	_, err := uuid.Parse(echoed)
+	assert.NoError(t, err)
+	assert.NotContains(t, logged, "admin password reset")
+	assert.NotContains(t, strings.TrimSpace(logged), "\n")
+	assert.Contains(t, logged, `"request_id":"`+echoed+`"`)
+}
+
+func TestRequestLogger_RequestIDPolicies(t *testing.T) {
+	upstreamUUID := "3f2b8c1e-7a4d-4e2f-9b6a-1c0d5e8f7a9b"
+	tests := []struct {
+		name      string
+		policy    string
+		requestID string
+		kept      bool
+	}{
+		{"token keeps upstream token", config.RequestIDPolicyToken, "Root=1-67891233-abcdef012345678912345678", true},
+		{"token keeps uuid", config.RequestIDPolicyToken, upstreamUUID, true},
+		{"token rejects spaces", config.RequestIDPolicyToken, "req 1", false},
+		{"token rejects oversized id", config.RequestIDPolicyToken, strings.Repeat("a", 129), false},
+		{"uuid keeps uuid", config.RequestIDPolicyUUID, upstreamUUID, true},
+		{"uuid rejects token", config.RequestIDPolicyUUID, "req-1", false},
+		{"uuid rejects braced uuid", config.RequestIDPolicyUUID, "{" + upstreamUUID + "}", false},
+		{"regenerate ignores uuid", config.RequestIDPolicyRegenerate, upstreamUUID, false},
+		{"missing id is generated", config.RequestIDPolicyToken, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			echoed, logged := serveWithRequestID(t, tt.policy, tt.requestID)
+
+			// Assert
+			if tt.kept {
+				assert.Equal(t, tt.requestID, echoed)
+			} else {
+				assert.NotEqual(t, tt.requestID, echoed)
+				_, err := uuid.Parse(echoed)
+				assert.NoError(t, err)
+			}
+			assert.Contains(t, logged, `"request_id":"`+echoed+`"`)
+		})
+	}
+}
+
+func TestNewJSONLogger_WritesToEveryOutput(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.log")
+	second := filepath.Join(dir, "nested", "second.log")
+
+	l, err := logger.NewJSONLogger([]string{first, second}, logger.Rotation{MaxSizeMB: 1, MaxBackups: 2})
+	require.NoError(t, err)
+
+	// Act
+	l.Info("hello", zap.String("sink", "all"))
+	require.NoError(t, l.Sync())
+
+	// Assert
+	for _, path := range []string{first, second} {
+		raw, err := os.ReadFile(path)
+		require.NoError(t, err)
+		assert.Contains(t, string(raw), `"msg":"hello"`)
+		assert.Contains(t, string(raw), `"sink":"all"`)
+	}
+}
+
+func TestNewJSONLogger_SharesFilesBetweenLoggers(t *testing.T) {
+	// Arrange
+	output := filepath.Join(t.TempDir(), "app.log")
+	a, err := logger.NewJSONLogger([]string{output}, logger.Rotation{})
+	require.NoError(t, err)
+	b, err := logger.NewJSONLogger([]string{output}, logger.Rotation{})
+	require.NoError(t, err)
+
+	// Act
+	a.Info("from a")
+	b.Info("from b")
+
+	// Assert
+	raw, err := os.ReadFile(output)
+	require.NoError(t, err)
+	assert.Contains(t, string(raw), "from a")
+	assert.Contains(t, string(raw), "from b")
+}
+
+func TestNewJSONLogger_RejectsUnwritableOutput(t *testing.T) {
+	// Arrange
+	blocker := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(blocker, nil, 0o600))
+
+	// Act
+	_, err := logger.NewJSONLogger([]string{filepath.Join(blocker, "app.log")}, logger.Rotation{})
+
+	// Assert
+	assert.Error(t, err)
+}
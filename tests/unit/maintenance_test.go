@@ -0,0 +1,124 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/internal/delivery/http/middleware"
+	"github.com/TubagusAldiMY/go-template/internal/shared/constants"
+	"github.com/TubagusAldiMY/go-template/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newMaintenanceRouter(store middleware.MaintenanceStore) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.NewMaintenance(store, 2*time.Minute, "/login").Middleware())
+	handler := func(c *gin.Context) { response.OK(c, "ok", nil) }
+	router.GET("/profile", handler)
+	router.PUT("/profile", handler)
+	router.POST("/login", handler)
+	return router
+}
+
+func TestMaintenance_RefusesWrites(t *testing.T) {
+	// Arrange
+	store := new(MockRedis)
+	store.On("Get", mock.Anything, constants.CacheKeyMaintenance).Return(`{"message":"Upgrading"}`, nil)
+	router := newMaintenanceRouter(store)
+	rec := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/profile", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Equal(t, "120", rec.Header().Get(constants.HeaderRetryAfter))
+	assert.Contains(t, rec.Body.String(), "Upgrading")
+}
+
+func TestMaintenance_ServesReadsAndAllowedRoutes(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+	}{
+		{name: "read", method: http.MethodGet, path: "/profile"},
+		{name: "allowed route", method: http.MethodPost, path: "/login"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			store := new(MockRedis)
+			router := newMaintenanceRouter(store)
+			rec := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+
+			// Assert
+			assert.Equal(t, http.StatusOK, rec.Code)
+			store.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestMaintenance_ServesWritesWhenOff(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{name: "flag unset", err: redis.Nil},
+		{name: "redis unavailable", err: errors.New("connection refused")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			store := new(MockRedis)
+			store.On("Get", mock.Anything, constants.CacheKeyMaintenance).Return("", tt.err)
+			router := newMaintenanceRouter(store)
+			rec := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/profile", nil))
+
+			// Assert
+			assert.Equal(t, http.StatusOK, rec.Code)
+		})
+	}
+}
+
+func TestMaintenance_EnableAndDisable(t *testing.T) {
+	// Arrange
+	store := new(MockRedis)
+	maintenance := middleware.NewMaintenance(store, time.Minute)
+	var stored []byte
+	store.On("Set", mock.Anything, constants.CacheKeyMaintenance, mock.Anything, time.Duration(0)).
+		Run(func(args mock.Arguments) { stored = args.Get(2).([]byte) }).Return(nil)
+	store.On("Delete", mock.Anything, []string{constants.CacheKeyMaintenance}).Return(nil)
+
+	// Act
+	enabled, err := maintenance.Enable(context.Background(), "Upgrading", "admin-1")
+	require.NoError(t, err)
+	store.On("Get", mock.Anything, constants.CacheKeyMaintenance).Return(string(stored), nil)
+	status, statusErr := maintenance.Status(context.Background())
+	disableErr := maintenance.Disable(context.Background())
+
+	// Assert
+	require.NoError(t, statusErr)
+	require.NoError(t, disableErr)
+	assert.True(t, enabled.Enabled)
+	assert.True(t, status.Enabled)
+	assert.Equal(t, "Upgrading", status.Message)
+	assert.Equal(t, "admin-1", status.EnabledBy)
+	assert.NotNil(t, status.Since)
+	store.AssertExpectations(t)
+}
@@ -0,0 +1,134 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/entity"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/repository"
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/config"
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/metrics"
+	sharedErrors "github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	appMetrics "github.com/TubagusAldiMY/go-template/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsServer_Pprof(t *testing.T) {
+	tests := []struct {
+		name  string
+		pprof bool
+		path  string
+		want  int
+	}{
+		{name: "metrics", path: "/metrics", want: http.StatusOK},
+		{name: "pprof disabled", path: "/debug/pprof/", want: http.StatusNotFound},
+		{name: "pprof index", pprof: true, path: "/debug/pprof/", want: http.StatusOK},
+		{name: "pprof heap profile", pprof: true, path: "/debug/pprof/heap", want: http.StatusOK},
+		{name: "pprof cmdline", pprof: true, path: "/debug/pprof/cmdline", want: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			srv := metrics.NewServer(config.MetricsConfig{Port: 9090, Pprof: tt.pprof}, prometheus.NewRegistry())
+			rec := httptest.NewRecorder()
+
+			// Act
+			srv.Handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			// Assert
+			assert.Equal(t, tt.want, rec.Code)
+		})
+	}
+}
+
+// operationCount returns how many times operation was observed with outcome.
+func operationCount(t *testing.T, reg *prometheus.Registry, operation, outcome string) uint64 {
+	t.Helper()
+
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != "operation_duration_seconds" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range m.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["operation"] == operation && labels["outcome"] == outcome {
+				return m.GetHistogram().GetSampleCount()
+			}
+		}
+	}
+	return 0
+}
+
+func TestInstrumentedUserRepository_CountsOperations(t *testing.T) {
+	// Arrange
+	reg := prometheus.NewRegistry()
+	operations, err := appMetrics.NewOperations(reg)
+	require.NoError(t, err)
+	mockRepo := new(MockUserRepository)
+	repo := repository.NewInstrumentedUserRepository(mockRepo, operations)
+
+	mockRepo.On("GetByID", mock.Anything, "user-123").Return(&entity.User{ID: "user-123"}, nil)
+	mockRepo.On("GetByEmail", mock.Anything, "missing@example.com").Return(nil, sharedErrors.ErrUserNotFound)
+	mockRepo.On("List", mock.Anything, mock.Anything).Return(nil, int64(0), errors.New("connection refused"))
+
+	// Act
+	_, _ = repo.GetByID(context.Background(), "user-123")
+	_, _ = repo.GetByID(context.Background(), "user-123")
+	_, _ = repo.GetByEmail(context.Background(), "missing@example.com")
+	_, _, _ = repo.List(context.Background(), repository.ListParams{})
+
+	// Assert
+	assert.Equal(t, uint64(2), operationCount(t, reg, "get_by_id", appMetrics.OutcomeOK))
+	assert.Equal(t, uint64(1), operationCount(t, reg, "get_by_email", appMetrics.OutcomeOK))
+	assert.Equal(t, uint64(1), operationCount(t, reg, "list", appMetrics.OutcomeError))
+	assert.Equal(t, uint64(0), operationCount(t, reg, "list", appMetrics.OutcomeOK))
+}
+
+func TestOperationsRedisHook_CountsCommands(t *testing.T) {
+	// Arrange
+	reg := prometheus.NewRegistry()
+	operations, err := appMetrics.NewOperations(reg)
+	require.NoError(t, err)
+	rdb, server := newTestRedis(t)
+	rdb.Client.AddHook(operations.RedisHook())
+	ctx := context.Background()
+
+	// Act
+	require.NoError(t, rdb.Set(ctx, "key", "value", time.Minute))
+	_, hitErr := rdb.Get(ctx, "key")
+	_, missErr := rdb.Get(ctx, "missing")
+	server.SetError("LOADING Redis is loading the dataset in memory")
+	_, failErr := rdb.Get(ctx, "key")
+
+	// Assert
+	require.NoError(t, hitErr)
+	assert.ErrorIs(t, missErr, redis.Nil)
+	require.Error(t, failErr)
+	assert.Equal(t, uint64(1), operationCount(t, reg, "cache_set", appMetrics.OutcomeOK))
+	assert.Equal(t, uint64(2), operationCount(t, reg, "cache_get", appMetrics.OutcomeOK))
+	assert.Equal(t, uint64(1), operationCount(t, reg, "cache_get", appMetrics.OutcomeError))
+}
+
+func TestOperations_NilRecordsNothing(t *testing.T) {
+	// Arrange
+	var operations *appMetrics.Operations
+
+	// Act & Assert
+	assert.NotPanics(t, func() {
+		operations.Observe("get_by_id", time.Now(), nil)
+	})
+}
@@ -0,0 +1,443 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/dto"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/entity"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/repository"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/usecase"
+	sharedErrors "github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/TubagusAldiMY/go-template/pkg/totp"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sync/errgroup"
+)
+
+// reversibleCipher stands in for AES-GCM so tests can inspect stored secrets.
+type reversibleCipher struct{}
+
+func (reversibleCipher) Encrypt(plaintext string) (string, error) {
+	return "enc:" + plaintext, nil
+}
+
+func (reversibleCipher) Decrypt(ciphertext string) (string, error) {
+	return strings.TrimPrefix(ciphertext, "enc:"), nil
+}
+
+// withTOTP enables TOTP with an MFA repository backed by miniredis.
+func withTOTP(t *testing.T) usecase.Option {
+	option, _ := withTOTPServer(t)
+	return option
+}
+
+// withTOTPServer is withTOTP that also returns the miniredis server.
+func withTOTPServer(t *testing.T) (usecase.Option, *miniredis.Miniredis) {
+	t.Helper()
+
+	rdb, server := newTestRedis(t)
+	return usecase.WithTOTP(reversibleCipher{}, "go-template", repository.NewRedisMFARepository(rdb)), server
+}
+
+func newMFAUser(t *testing.T, enabled bool) (*entity.User, string) {
+	t.Helper()
+
+	key, err := totp.Generate("go-template", "test@example.com")
+	require.NoError(t, err)
+
+	return &entity.User{
+		ID:         "user-123",
+		Email:      "test@example.com",
+		Username:   "testuser",
+		Password:   "hashedpassword",
+		Role:       "user",
+		Status:     "active",
+		TOTPSecret: "enc:" + key.Secret,
+		MFAEnabled: enabled,
+		Version:    1,
+	}, key.Secret
+}
+
+func currentCode(t *testing.T, secret string) string {
+	t.Helper()
+
+	code, err := totp.GenerateCode(secret, time.Now())
+	require.NoError(t, err)
+	return code
+}
+
+func challengeJSON(t *testing.T, userID string) string {
+	t.Helper()
+
+	data, err := json.Marshal(map[string]interface{}{
+		"user_id":    userID,
+		"expires_at": time.Now().Add(time.Minute),
+	})
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestEnableTOTP_StoresEncryptedSecret(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis),
+		withTOTP(t))
+
+	user := &entity.User{ID: "user-123", Email: "test@example.com", Status: "active", Version: 1}
+	mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	mockRepo.On("Update", mock.Anything, user).Return(nil)
+
+	// Act
+	result, err := uc.EnableTOTP(context.Background(), user.ID)
+
+	// Assert
+	require.NoError(t, err)
+	assert.NotEmpty(t, result.Secret)
+	assert.True(t, strings.HasPrefix(result.ProvisioningURI, "otpauth://totp/go-template:test@example.com"))
+	assert.Equal(t, "enc:"+result.Secret, user.TOTPSecret)
+	assert.False(t, user.MFAEnabled)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestEnableTOTP_NotConfigured(t *testing.T) {
+	// Arrange
+	uc := usecase.NewUserUsecase(new(MockUserRepository), new(MockPasswordHasher), new(MockJWTManager), new(MockRedis))
+
+	// Act
+	result, err := uc.EnableTOTP(context.Background(), "user-123")
+
+	// Assert
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, sharedErrors.ErrMFANotConfigured)
+}
+
+func TestEnableTOTP_AlreadyEnabled(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis),
+		withTOTP(t))
+
+	user, _ := newMFAUser(t, true)
+	mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+
+	// Act
+	_, err := uc.EnableTOTP(context.Background(), user.ID)
+
+	// Assert
+	assert.ErrorIs(t, err, sharedErrors.ErrMFAAlreadyEnabled)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestVerifyTOTP_EnablesMFA(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis),
+		withTOTP(t))
+
+	user, secret := newMFAUser(t, false)
+	mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	mockRepo.On("Update", mock.Anything, user).Return(nil)
+
+	// Act
+	result, err := uc.VerifyTOTP(context.Background(), user.ID, currentCode(t, secret))
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, result.MFAEnabled)
+	assert.True(t, user.MFAEnabled)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestVerifyTOTP_InvalidCode(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis),
+		withTOTP(t))
+
+	user, secret := newMFAUser(t, false)
+	mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+
+	// Act
+	_, err := uc.VerifyTOTP(context.Background(), user.ID, wrongCode(currentCode(t, secret)))
+
+	// Assert
+	assert.ErrorIs(t, err, sharedErrors.ErrInvalidMFACode)
+	assert.False(t, user.MFAEnabled)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestVerifyTOTP_NotEnrolled(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis),
+		withTOTP(t))
+
+	user := &entity.User{ID: "user-123", Status: "active", Version: 1}
+	mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+
+	// Act
+	_, err := uc.VerifyTOTP(context.Background(), user.ID, "123456")
+
+	// Assert
+	assert.ErrorIs(t, err, sharedErrors.ErrMFANotEnrolled)
+}
+
+func TestLogin_MFAEnabledReturnsChallenge(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockHasher := new(MockPasswordHasher)
+	mockJWT := new(MockJWTManager)
+	mockRedis := new(MockRedis)
+	uc := usecase.NewUserUsecase(mockRepo, mockHasher, mockJWT, mockRedis,
+		withTOTP(t))
+
+	user, _ := newMFAUser(t, true)
+	req := &dto.LoginRequest{Email: user.Email, Password: "SecurePass123!"}
+
+	mockRepo.On("GetByEmail", mock.Anything, user.Email).Return(user, nil)
+	mockHasher.On("IsValid", user.Password, req.Password).Return(true)
+	mockHasher.On("NeedsRehash", user.Password).Return(false)
+	mockRedis.On("Set", mock.Anything, mock.MatchedBy(func(key string) bool {
+		return strings.HasPrefix(key, "mfa_challenge:")
+	}), mock.Anything, 5*time.Minute).Return(nil)
+
+	// Act
+	result, err := uc.Login(context.Background(), req)
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, result.MFARequired)
+	assert.NotEmpty(t, result.MFAToken)
+	assert.Empty(t, result.AccessToken)
+	assert.Nil(t, result.User)
+	mockJWT.AssertNotCalled(t, "GenerateAccessToken", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockRedis.AssertExpectations(t)
+}
+
+func TestLoginTOTP_IssuesTokens(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockJWT := new(MockJWTManager)
+	mockRedis := new(MockRedis)
+	mockSessions := new(MockSessionRepository)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), mockJWT, mockRedis,
+		usecase.WithSessionRepository(mockSessions),
+		withTOTP(t))
+
+	user, secret := newMFAUser(t, true)
+	key := "mfa_challenge:mfa-token"
+
+	mockRedis.On("Get", mock.Anything, key).Return(challengeJSON(t, user.ID), nil)
+	mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	mockRedis.On("Delete", mock.Anything, []string{key}).Return(nil)
+	mockRepo.On("UpdateLastLogin", mock.Anything, user.ID, mock.AnythingOfType("time.Time")).Return(nil)
+	mockJWT.On("GenerateAccessToken", mock.Anything, user.ID, user.Email, user.Role).Return("access-token", nil)
+	mockJWT.On("GenerateRefreshToken", user.ID, mock.Anything, mockRefreshTokenDuration).Return("refresh-token", "refresh-jti", nil)
+	expectSessionSaved(mockSessions, user.ID, "refresh-jti")
+
+	// Act
+	result, err := uc.LoginTOTP(context.Background(), &dto.LoginTOTPRequest{
+		MFAToken: "mfa-token",
+		Code:     currentCode(t, secret),
+	})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "access-token", result.AccessToken)
+	assert.Equal(t, "refresh-token", result.RefreshToken)
+	assert.False(t, result.MFARequired)
+	mockRedis.AssertExpectations(t)
+	mockJWT.AssertExpectations(t)
+}
+
+func TestLoginTOTP_InvalidCodeCountsAttempt(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockRedis := new(MockRedis)
+	option, server := withTOTPServer(t)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), mockRedis, option)
+
+	user, secret := newMFAUser(t, true)
+	key := "mfa_challenge:mfa-token"
+	require.NoError(t, server.Set("mfa_attempts:mfa-token", "1"))
+
+	mockRedis.On("Get", mock.Anything, key).Return(challengeJSON(t, user.ID), nil)
+	mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+
+	// Act
+	_, err := uc.LoginTOTP(context.Background(), &dto.LoginTOTPRequest{
+		MFAToken: "mfa-token",
+		Code:     wrongCode(currentCode(t, secret)),
+	})
+
+	// Assert
+	assert.ErrorIs(t, err, sharedErrors.ErrInvalidMFACode)
+	attempts, getErr := server.Get("mfa_attempts:mfa-token")
+	require.NoError(t, getErr)
+	assert.Equal(t, "2", attempts)
+	mockRedis.AssertExpectations(t)
+	mockRedis.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestLoginTOTP_LastAttemptDiscardsChallenge(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockRedis := new(MockRedis)
+	option, server := withTOTPServer(t)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), mockRedis, option)
+
+	user, secret := newMFAUser(t, true)
+	key := "mfa_challenge:mfa-token"
+	require.NoError(t, server.Set("mfa_attempts:mfa-token", "4"))
+
+	mockRedis.On("Get", mock.Anything, key).Return(challengeJSON(t, user.ID), nil)
+	mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	mockRedis.On("Delete", mock.Anything, []string{key}).Return(nil)
+
+	// Act
+	_, err := uc.LoginTOTP(context.Background(), &dto.LoginTOTPRequest{
+		MFAToken: "mfa-token",
+		Code:     wrongCode(currentCode(t, secret)),
+	})
+
+	// Assert
+	assert.ErrorIs(t, err, sharedErrors.ErrInvalidMFACode)
+	mockRedis.AssertExpectations(t)
+}
+
+func TestLoginTOTP_AttemptsUsedUpRejectsValidCode(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockRedis := new(MockRedis)
+	mockJWT := new(MockJWTManager)
+	option, server := withTOTPServer(t)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), mockJWT, mockRedis, option)
+
+	user, secret := newMFAUser(t, true)
+	key := "mfa_challenge:mfa-token"
+	require.NoError(t, server.Set("mfa_attempts:mfa-token", "5"))
+
+	mockRedis.On("Get", mock.Anything, key).Return(challengeJSON(t, user.ID), nil)
+	mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	mockRedis.On("Delete", mock.Anything, []string{key}).Return(nil)
+
+	// Act
+	_, err := uc.LoginTOTP(context.Background(), &dto.LoginTOTPRequest{
+		MFAToken: "mfa-token",
+		Code:     currentCode(t, secret),
+	})
+
+	// Assert
+	assert.ErrorIs(t, err, sharedErrors.ErrInvalidMFAToken)
+	mockRedis.AssertExpectations(t)
+	mockJWT.AssertNotCalled(t, "GenerateAccessToken", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestLoginTOTP_ReusedCodeIsRejected(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockRedis := new(MockRedis)
+	mockJWT := new(MockJWTManager)
+	option, server := withTOTPServer(t)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), mockJWT, mockRedis, option)
+
+	user, secret := newMFAUser(t, true)
+	key := "mfa_challenge:mfa-token"
+	step, valid := totp.ValidateStep(currentCode(t, secret), secret)
+	require.True(t, valid)
+	require.NoError(t, server.Set("totp_step:"+user.ID, fmt.Sprint(step)))
+
+	mockRedis.On("Get", mock.Anything, key).Return(challengeJSON(t, user.ID), nil)
+	mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+
+	// Act
+	_, err := uc.LoginTOTP(context.Background(), &dto.LoginTOTPRequest{
+		MFAToken: "mfa-token",
+		Code:     currentCode(t, secret),
+	})
+
+	// Assert
+	assert.ErrorIs(t, err, sharedErrors.ErrInvalidMFACode)
+	mockJWT.AssertNotCalled(t, "GenerateAccessToken", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestMFARepository_CountAttemptIsAtomic(t *testing.T) {
+	// Arrange
+	rdb, server := newTestRedis(t)
+	repo := repository.NewRedisMFARepository(rdb)
+	const workers = 20
+
+	// Act
+	var g errgroup.Group
+	counts := make([]int64, workers)
+	for i := range counts {
+		i := i
+		g.Go(func() error {
+			count, err := repo.CountAttempt(context.Background(), "mfa-token", time.Minute)
+			counts[i] = count
+			return err
+		})
+	}
+	require.NoError(t, g.Wait())
+
+	// Assert
+	sort.Slice(counts, func(i, j int) bool { return counts[i] < counts[j] })
+	for i, count := range counts {
+		assert.Equal(t, int64(i+1), count)
+	}
+	assert.Equal(t, time.Minute, server.TTL("mfa_attempts:mfa-token"))
+}
+
+func TestMFARepository_UseTOTPStep(t *testing.T) {
+	// Arrange
+	rdb, _ := newTestRedis(t)
+	repo := repository.NewRedisMFARepository(rdb)
+	ctx := context.Background()
+
+	// Act & Assert
+	for _, tt := range []struct {
+		step int64
+		want bool
+	}{
+		{step: 100, want: true},
+		{step: 100, want: false},
+		{step: 99, want: false},
+		{step: 101, want: true},
+	} {
+		used, err := repo.UseTOTPStep(ctx, "user-123", tt.step)
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, used, "step %d", tt.step)
+	}
+}
+
+func TestLoginTOTP_UnknownToken(t *testing.T) {
+	// Arrange
+	mockRedis := new(MockRedis)
+	uc := usecase.NewUserUsecase(new(MockUserRepository), new(MockPasswordHasher), new(MockJWTManager), mockRedis,
+		withTOTP(t))
+
+	mockRedis.On("Get", mock.Anything, "mfa_challenge:expired").Return("", redis.Nil)
+
+	// Act
+	_, err := uc.LoginTOTP(context.Background(), &dto.LoginTOTPRequest{MFAToken: "expired", Code: "123456"})
+
+	// Assert
+	assert.ErrorIs(t, err, sharedErrors.ErrInvalidMFAToken)
+}
+
+// wrongCode returns a six-digit code that differs from code.
+func wrongCode(code string) string {
+	if code == "000000" {
+		return "111111"
+	}
+	return "000000"
+}
@@ -0,0 +1,77 @@
+package usecase_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/internal/delivery/http/middleware"
+	userHttp "github.com/TubagusAldiMY/go-template/internal/domain/user/delivery/http"
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/notification"
+	"github.com/TubagusAldiMY/go-template/pkg/jwt"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newNotificationServer(t *testing.T, hub *notification.Hub, jwtManager *jwt.Manager) string {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	handler := userHttp.NewNotificationsHandler(hub, nil, func(*http.Request) bool { return true })
+	router := gin.New()
+	router.GET("/ws", middleware.WebSocketAuthMiddleware(jwtManager), handler.Connect)
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+}
+
+func TestNotifications_DeliversToConnectedUser(t *testing.T) {
+	// Arrange
+	hub := notification.NewHub()
+	jwtManager := jwt.NewManager(testJWTSecret, time.Minute, time.Hour)
+	url := newNotificationServer(t, hub, jwtManager)
+	token, err := jwtManager.GenerateAccessToken(context.Background(), "user-1", "user@example.com", "user")
+	require.NoError(t, err)
+
+	client, _, err := websocket.DefaultDialer.Dial(url+"?access_token="+token, nil)
+	require.NoError(t, err)
+	defer client.Close()
+	require.Eventually(t, func() bool { return hub.Connections("user-1") == 1 }, time.Second, 10*time.Millisecond)
+
+	// Act
+	sentToOwner := hub.SendToUser("user-1", []byte(`{"type":"user.updated"}`))
+	sentToOther := hub.SendToUser("user-2", []byte(`{"type":"user.updated"}`))
+
+	// Assert
+	assert.Equal(t, 1, sentToOwner)
+	assert.Equal(t, 0, sentToOther)
+	require.NoError(t, client.SetReadDeadline(time.Now().Add(time.Second)))
+	_, msg, err := client.ReadMessage()
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"type":"user.updated"}`, string(msg))
+
+	require.NoError(t, client.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")))
+	assert.Eventually(t, func() bool { return hub.Connections("user-1") == 0 }, time.Second, 10*time.Millisecond)
+}
+
+func TestNotifications_RejectsInvalidToken(t *testing.T) {
+	// Arrange
+	hub := notification.NewHub()
+	jwtManager := jwt.NewManager(testJWTSecret, time.Minute, time.Hour)
+	url := newNotificationServer(t, hub, jwtManager)
+
+	// Act
+	_, resp, err := websocket.DefaultDialer.DialContext(context.Background(), url+"?access_token=invalid", nil)
+
+	// Assert
+	require.Error(t, err)
+	require.NotNil(t, resp)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+}
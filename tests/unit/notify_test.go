@@ -0,0 +1,263 @@
+package usecase_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/config"
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/messaging"
+	"github.com/TubagusAldiMY/go-template/pkg/logger"
+	"github.com/TubagusAldiMY/go-template/pkg/notify"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// recordingNotifier is a fake notify.Notifier that keeps what it was sent.
+type recordingNotifier struct {
+	sent []notify.Notification
+	err  error
+}
+
+func (r *recordingNotifier) Send(_ context.Context, n notify.Notification) error {
+	r.sent = append(r.sent, n)
+	return r.err
+}
+
+type fakePublisher struct {
+	exchange   string
+	routingKey string
+	body       []byte
+}
+
+func (p *fakePublisher) Publish(_ context.Context, exchange, routingKey string, body []byte) error {
+	p.exchange, p.routingKey, p.body = exchange, routingKey, body
+	return nil
+}
+
+func TestTemplate_Render(t *testing.T) {
+	// Arrange
+	tmpl := notify.MustTemplate("reset",
+		"Reset your {{.App}} password",
+		"Hi {{.Name}},\n\nOpen {{.Link}} to choose a new password.\n")
+
+	// Act
+	n, err := tmpl.Render("john@example.com", map[string]string{
+		"App":  "Go Template",
+		"Name": "John",
+		"Link": "https://example.com/reset?token=abc",
+	})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "john@example.com", n.To)
+	assert.Equal(t, "Reset your Go Template password", n.Subject)
+	assert.Equal(t, "Hi John,\n\nOpen https://example.com/reset?token=abc to choose a new password.\n", n.Body)
+}
+
+func TestTemplate_MissingFieldIsAnError(t *testing.T) {
+	// Arrange
+	tmpl := notify.MustTemplate("welcome", "Welcome", "Hi {{.Name}}")
+
+	// Act
+	_, err := tmpl.Render("john@example.com", map[string]string{"Nmae": "John"})
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func TestNewTemplate_InvalidSyntax(t *testing.T) {
+	// Act
+	_, err := notify.NewTemplate("broken", "Hello", "Hi {{.Name")
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func TestLogNotifier_LogsNotification(t *testing.T) {
+	// Arrange
+	core, logs := observer.New(zap.InfoLevel)
+	ctx := logger.NewContext(context.Background(), zap.New(core))
+
+	// Act
+	err := notify.NewLog().Send(ctx, notify.Notification{To: "john@example.com", Subject: "Hello", Body: "Hi"})
+
+	// Assert
+	require.NoError(t, err)
+	require.Equal(t, 1, logs.Len())
+	fields := logs.All()[0].ContextMap()
+	assert.Equal(t, "john@example.com", fields["to"])
+	assert.Equal(t, "Hello", fields["subject"])
+	assert.Equal(t, "Hi", fields["body"])
+}
+
+func TestQueueNotifier_IsDeliveredByWorker(t *testing.T) {
+	// Arrange
+	publisher := &fakePublisher{}
+	queue := notify.NewQueue(publisher, "email.queue")
+	sender := &recordingNotifier{}
+	handler := messaging.NotificationHandler(sender)
+	n := notify.Notification{To: "john@example.com", Subject: "Hello", Body: "Hi"}
+
+	// Act
+	sendErr := queue.Send(context.Background(), n)
+	handleErr := handler(context.Background(), amqp.Delivery{Body: publisher.body})
+
+	// Assert
+	require.NoError(t, sendErr)
+	assert.Equal(t, "", publisher.exchange)
+	assert.Equal(t, "email.queue", publisher.routingKey)
+	require.NoError(t, handleErr)
+	assert.Equal(t, []notify.Notification{n}, sender.sent)
+}
+
+func TestNotificationHandler_Errors(t *testing.T) {
+	// Arrange
+	failing := messaging.NotificationHandler(&recordingNotifier{err: errors.New("connection refused")})
+
+	// Act
+	malformedErr := failing(context.Background(), amqp.Delivery{Body: []byte("not json")})
+	deliveryErr := failing(context.Background(), amqp.Delivery{Body: []byte(`{"to":"john@example.com"}`)})
+
+	// Assert
+	assert.ErrorIs(t, malformedErr, messaging.ErrPermanent)
+	require.Error(t, deliveryErr)
+	assert.NotErrorIs(t, deliveryErr, messaging.ErrPermanent)
+}
+
+func TestNotifiers_RejectMissingRecipient(t *testing.T) {
+	smtpNotifier, err := notify.NewSMTP(notify.SMTPConfig{Host: "localhost", Port: 25, From: "no-reply@example.com"})
+	require.NoError(t, err)
+
+	notifiers := map[string]notify.Notifier{
+		"log":   notify.NewLog(),
+		"queue": notify.NewQueue(&fakePublisher{}, "email.queue"),
+		"smtp":  smtpNotifier,
+	}
+	for name, notifier := range notifiers {
+		t.Run(name, func(t *testing.T) {
+			// Act
+			err := notifier.Send(context.Background(), notify.Notification{Subject: "Hello"})
+
+			// Assert
+			assert.ErrorIs(t, err, notify.ErrNoRecipient)
+		})
+	}
+}
+
+// startSMTPServer accepts one SMTP session and sends the DATA it receives
+// on the returned channel.
+func startSMTPServer(t *testing.T) (string, int, <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		r := bufio.NewReader(conn)
+		reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+		reply("220 localhost ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch cmd := strings.ToUpper(strings.TrimSpace(line)); {
+			case strings.HasPrefix(cmd, "EHLO"):
+				reply("250 localhost")
+			case strings.HasPrefix(cmd, "DATA"):
+				reply("354 go ahead")
+				var data strings.Builder
+				for {
+					dataLine, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if dataLine == ".\r\n" {
+						break
+					}
+					data.WriteString(dataLine)
+				}
+				received <- data.String()
+				reply("250 queued")
+			case strings.HasPrefix(cmd, "QUIT"):
+				reply("221 bye")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	host, portStr, err := net.SplitHostPort(ln.Addr().String())
+	require.NoError(t, err)
+	port, err := strconv.Atoi(portStr)
+	require.NoError(t, err)
+	return host, port, received
+}
+
+func TestSMTPNotifier_SendsEmail(t *testing.T) {
+	// Arrange
+	host, port, received := startSMTPServer(t)
+	sender, err := notify.NewSMTP(notify.SMTPConfig{Host: host, Port: port, From: "Go Template <no-reply@example.com>"})
+	require.NoError(t, err)
+
+	// Act
+	err = sender.Send(context.Background(), notify.Notification{
+		To:      "john@example.com",
+		Subject: "Welcome, Jöhn",
+		Body:    "Hi John,\nwelcome aboard.",
+	})
+
+	// Assert
+	require.NoError(t, err)
+	msg := <-received
+	assert.Contains(t, msg, "From: \"Go Template\" <no-reply@example.com>\r\n")
+	assert.Contains(t, msg, "To: <john@example.com>\r\n")
+	assert.Contains(t, msg, "Subject: =?utf-8?q?Welcome,_J=C3=B6hn?=\r\n")
+	assert.Contains(t, msg, "\r\n\r\nHi John,\r\nwelcome aboard.")
+}
+
+func TestSMTPNotifier_RejectsHeaderInjection(t *testing.T) {
+	// Arrange
+	sender, err := notify.NewSMTP(notify.SMTPConfig{Host: "localhost", Port: 25, From: "no-reply@example.com"})
+	require.NoError(t, err)
+
+	// Act
+	err = sender.Send(context.Background(), notify.Notification{
+		To:      "john@example.com",
+		Subject: "Hello\r\nBcc: victim@example.com",
+	})
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func TestConfigValidate_Notify(t *testing.T) {
+	// Arrange
+	cfg := validConfig()
+	cfg.Notify = config.NotifyConfig{Driver: config.NotifyDriverSMTP, Async: true}
+
+	// Act
+	err := cfg.Validate()
+
+	// Assert
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "NOTIFY_SMTP_HOST: is required for the smtp driver")
+	assert.Contains(t, err.Error(), "NOTIFY_FROM: is required for the smtp driver")
+	assert.Contains(t, err.Error(), "NOTIFY_WORKERS: must be positive")
+}
@@ -0,0 +1,26 @@
+package usecase_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/TubagusAldiMY/go-template/docs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPIDocument_IsValidSwaggerJSON(t *testing.T) {
+	// Act
+	var doc struct {
+		Swagger  string                     `json:"swagger"`
+		BasePath string                     `json:"basePath"`
+		Paths    map[string]json.RawMessage `json:"paths"`
+	}
+	err := json.Unmarshal([]byte(docs.SwaggerInfo.ReadDoc()), &doc)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "2.0", doc.Swagger)
+	assert.Equal(t, docs.SwaggerInfo.BasePath, doc.BasePath)
+	assert.Contains(t, doc.Paths, "/auth/login")
+}
@@ -0,0 +1,166 @@
+package usecase_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/TubagusAldiMY/go-template/pkg/crypto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testArgon2Params keeps argon2id cheap enough for unit tests.
+var testArgon2Params = crypto.Argon2Params{
+	Memory:      8 * 1024,
+	Iterations:  1,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+// newBcryptHasher returns a bcrypt hasher with cost, failing the test if the
+// cost is rejected.
+func newBcryptHasher(t *testing.T, cost int) *crypto.PasswordHasher {
+	t.Helper()
+	hasher, err := crypto.NewPasswordHasher(cost)
+	require.NoError(t, err)
+	return hasher
+}
+
+func TestArgon2idHasher_HashAndCompare(t *testing.T) {
+	// Arrange
+	hasher := crypto.NewArgon2idPasswordHasher(testArgon2Params)
+
+	// Act
+	hash, err := hasher.Hash("SecurePass123!")
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=8192,t=1,p=1$"))
+	assert.True(t, hasher.IsValid(hash, "SecurePass123!"))
+	assert.ErrorIs(t, hasher.Compare(hash, "WrongPass123!"), crypto.ErrPasswordMismatch)
+}
+
+func TestArgon2idHasher_AcceptsPasswordsBeyondBcryptLimit(t *testing.T) {
+	// Arrange
+	hasher := crypto.NewArgon2idPasswordHasher(testArgon2Params)
+	long := strings.Repeat("a", 100)
+
+	// Act
+	hash, err := hasher.Hash(long)
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, hasher.IsValid(hash, long))
+	assert.False(t, hasher.IsValid(hash, long[:72]))
+}
+
+func TestArgon2idHasher_VerifiesExistingBcryptHash(t *testing.T) {
+	// Arrange
+	bcryptHash, err := newBcryptHasher(t, bcrypt.MinCost).Hash("SecurePass123!")
+	require.NoError(t, err)
+	hasher := crypto.NewArgon2idPasswordHasher(testArgon2Params)
+
+	// Act & Assert
+	assert.True(t, hasher.IsValid(bcryptHash, "SecurePass123!"))
+	assert.ErrorIs(t, hasher.Compare(bcryptHash, "WrongPass123!"), crypto.ErrPasswordMismatch)
+}
+
+func TestBcryptHasher_VerifiesExistingArgon2idHash(t *testing.T) {
+	// Arrange
+	argonHash, err := crypto.NewArgon2idPasswordHasher(testArgon2Params).Hash("SecurePass123!")
+	require.NoError(t, err)
+	hasher := newBcryptHasher(t, bcrypt.MinCost)
+
+	// Act & Assert
+	assert.True(t, hasher.IsValid(argonHash, "SecurePass123!"))
+	assert.False(t, hasher.IsValid(argonHash, "WrongPass123!"))
+}
+
+func TestArgon2idHasher_RejectsMalformedHash(t *testing.T) {
+	// Arrange
+	hasher := crypto.NewArgon2idPasswordHasher(testArgon2Params)
+
+	// Act
+	err := hasher.Compare("$argon2id$v=19$m=8192,t=1$salt", "SecurePass123!")
+
+	// Assert
+	assert.ErrorIs(t, err, crypto.ErrInvalidHash)
+}
+
+func TestBcryptHasher_NeedsRehash(t *testing.T) {
+	// Arrange
+	hash, err := newBcryptHasher(t, bcrypt.MinCost).Hash("SecurePass123!")
+	require.NoError(t, err)
+
+	// Act & Assert
+	assert.False(t, newBcryptHasher(t, bcrypt.MinCost).NeedsRehash(hash))
+	assert.True(t, newBcryptHasher(t, bcrypt.MinCost+1).NeedsRehash(hash))
+	assert.True(t, crypto.NewArgon2idPasswordHasher(testArgon2Params).NeedsRehash(hash))
+}
+
+func TestArgon2idHasher_NeedsRehash(t *testing.T) {
+	// Arrange
+	hash, err := crypto.NewArgon2idPasswordHasher(testArgon2Params).Hash("SecurePass123!")
+	require.NoError(t, err)
+
+	stronger := testArgon2Params
+	stronger.Iterations++
+
+	// Act & Assert
+	assert.False(t, crypto.NewArgon2idPasswordHasher(testArgon2Params).NeedsRehash(hash))
+	assert.True(t, crypto.NewArgon2idPasswordHasher(stronger).NeedsRehash(hash))
+	assert.True(t, newBcryptHasher(t, bcrypt.MinCost).NeedsRehash(hash))
+}
+
+func TestPasswordHasher_NeedsRehashIgnoresMalformedHash(t *testing.T) {
+	// Arrange
+	hasher := newBcryptHasher(t, bcrypt.MinCost)
+
+	// Act & Assert
+	assert.False(t, hasher.NeedsRehash("not-a-hash"))
+}
+
+func TestNewPasswordHasher_CostBounds(t *testing.T) {
+	tests := []struct {
+		name     string
+		cost     int
+		wantCost int
+		wantErr  bool
+	}{
+		{name: "zero uses default", cost: 0, wantCost: bcrypt.DefaultCost},
+		{name: "minimum", cost: bcrypt.MinCost, wantCost: bcrypt.MinCost},
+		{name: "below minimum", cost: bcrypt.MinCost - 1, wantErr: true},
+		{name: "negative", cost: -1, wantErr: true},
+		// A hash at MaxCost takes minutes, so its cost is not checked.
+		{name: "maximum", cost: bcrypt.MaxCost},
+		{name: "above maximum", cost: bcrypt.MaxCost + 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			hasher, err := crypto.NewPasswordHasher(tt.cost)
+
+			// Assert
+			if tt.wantErr {
+				assert.ErrorIs(t, err, crypto.ErrInvalidCost)
+				assert.Nil(t, hasher)
+				return
+			}
+			require.NoError(t, err)
+			if tt.wantCost != 0 {
+				// A hash at the expected cost needs no rehash.
+				assert.False(t, hasher.NeedsRehash(mustBcryptHash(t, tt.wantCost)))
+			}
+		})
+	}
+}
+
+func mustBcryptHash(t *testing.T, cost int) string {
+	t.Helper()
+	hash, err := bcrypt.GenerateFromPassword([]byte("SecurePass123!"), cost)
+	require.NoError(t, err)
+	return string(hash)
+}
@@ -0,0 +1,139 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	userHttp "github.com/TubagusAldiMY/go-template/internal/domain/user/delivery/http"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/dto"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/entity"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/usecase"
+	sharedErrors "github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/TubagusAldiMY/go-template/pkg/appcontext"
+	"github.com/TubagusAldiMY/go-template/pkg/validator"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newProfileUser() *entity.User {
+	return &entity.User{
+		ID: "user-123", Email: "john@example.com", Username: "john", FullName: "John Doe",
+		Timezone: "Asia/Jakarta", Locale: "id-ID", Status: "active",
+	}
+}
+
+func decodePatch(t *testing.T, patch string) *dto.PatchProfileRequest {
+	t.Helper()
+	var req dto.PatchProfileRequest
+	require.NoError(t, json.Unmarshal([]byte(patch), &req))
+	return &req
+}
+
+func TestPatchProfile_ClearsNullFieldsAndKeepsAbsentOnes(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis))
+	user := newProfileUser()
+	mockRepo.On("GetByID", mock.Anything, "user-123").Return(user, nil)
+	mockRepo.On("Update", mock.Anything, user).Return(nil)
+
+	// Act
+	resp, err := uc.PatchProfile(context.Background(), "user-123", decodePatch(t, `{"full_name": null, "locale": "en-US"}`))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "", resp.FullName)
+	assert.Equal(t, "en-US", resp.Locale)
+	assert.Equal(t, "Asia/Jakarta", resp.Timezone)
+	assert.Equal(t, "john@example.com", resp.Email)
+	mockRepo.AssertNotCalled(t, "ExistsByEmail", mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "ExistsByUsername", mock.Anything, mock.Anything)
+}
+
+func TestPatchProfile_ChecksChangedEmail(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis))
+	user := newProfileUser()
+	mockRepo.On("GetByID", mock.Anything, "user-123").Return(user, nil)
+	mockRepo.On("ExistsByEmail", mock.Anything, "jane@example.com").Return(true, nil)
+
+	// Act
+	_, err := uc.PatchProfile(context.Background(), "user-123", decodePatch(t, `{"email": "jane@example.com"}`))
+
+	// Assert
+	assert.ErrorIs(t, err, sharedErrors.ErrEmailAlreadyExists)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestPatchProfileHandler_Validation(t *testing.T) {
+	tests := []struct {
+		name       string
+		patch      string
+		wantFields []string
+	}{
+		{name: "empty full name", patch: `{"full_name": ""}`, wantFields: []string{"full_name"}},
+		{name: "invalid email", patch: `{"email": "not-an-email"}`, wantFields: []string{"email"}},
+		{name: "null email and username", patch: `{"email": null, "username": null}`, wantFields: []string{"email", "username"}},
+		{name: "unknown timezone", patch: `{"timezone": "Mars/Olympus"}`, wantFields: []string{"timezone"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			require.NoError(t, validator.Init())
+			gin.SetMode(gin.TestMode)
+			mockRepo := new(MockUserRepository)
+			uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis))
+			router := gin.New()
+			router.PATCH("/users/profile", func(c *gin.Context) {
+				c.Request = c.Request.WithContext(appcontext.WithUserID(c.Request.Context(), "user-123"))
+			}, userHttp.NewUserHandler(uc).PatchProfile)
+
+			req := httptest.NewRequest(http.MethodPatch, "/users/profile", strings.NewReader(tt.patch))
+			req.Header.Set("Content-Type", "application/merge-patch+json")
+			w := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(w, req)
+
+			// Assert
+			assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+			var resp struct {
+				Errors map[string]string `json:"errors"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			for _, field := range tt.wantFields {
+				assert.Contains(t, resp.Errors, field)
+			}
+			assert.Len(t, resp.Errors, len(tt.wantFields))
+			mockRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestPatchProfileHandler_RejectsNonObjectPatch(t *testing.T) {
+	// Arrange
+	require.NoError(t, validator.Init())
+	gin.SetMode(gin.TestMode)
+	uc := usecase.NewUserUsecase(new(MockUserRepository), new(MockPasswordHasher), new(MockJWTManager), new(MockRedis))
+	router := gin.New()
+	router.PATCH("/users/profile", func(c *gin.Context) {
+		c.Request = c.Request.WithContext(appcontext.WithUserID(c.Request.Context(), "user-123"))
+	}, userHttp.NewUserHandler(uc).PatchProfile)
+
+	req := httptest.NewRequest(http.MethodPatch, "/users/profile", strings.NewReader(`["full_name"]`))
+	req.Header.Set("Content-Type", "application/merge-patch+json")
+	w := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
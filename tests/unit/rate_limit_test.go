@@ -0,0 +1,115 @@
+package usecase_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TubagusAldiMY/go-template/internal/delivery/http/middleware"
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/config"
+	"github.com/TubagusAldiMY/go-template/internal/shared/constants"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newRateLimitedRouter(cfg config.RateLimitConfig) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middleware.RateLimit(cfg))
+	r.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return r
+}
+
+func TestRateLimit_SetsQuotaHeadersOnEveryResponse(t *testing.T) {
+	// Arrange
+	r := newRateLimitedRouter(config.RateLimitConfig{Enabled: true, RequestsPerSecond: 1, Burst: 2})
+	serve := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		return w
+	}
+
+	// Act
+	first, second, third := serve(), serve(), serve()
+
+	// Assert
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, "2", first.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, "1", first.Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, "1", first.Header().Get("X-RateLimit-Reset"))
+
+	assert.Equal(t, http.StatusOK, second.Code)
+	assert.Equal(t, "0", second.Header().Get("X-RateLimit-Remaining"))
+	assert.Equal(t, "2", second.Header().Get("X-RateLimit-Reset"))
+
+	assert.Equal(t, http.StatusTooManyRequests, third.Code)
+	assert.Equal(t, "0", third.Header().Get("X-RateLimit-Remaining"))
+	assert.NotEmpty(t, third.Header().Get("Retry-After"))
+}
+
+func TestRateLimit_DisabledOmitsHeaders(t *testing.T) {
+	// Arrange
+	r := newRateLimitedRouter(config.RateLimitConfig{Enabled: false})
+	w := httptest.NewRecorder()
+
+	// Act
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("X-RateLimit-Limit"))
+	assert.Empty(t, w.Header().Get("X-RateLimit-Remaining"))
+	assert.Empty(t, w.Header().Get("X-RateLimit-Reset"))
+}
+
+func TestRateLimiter_UpdateAppliesToKnownClients(t *testing.T) {
+	// Arrange
+	limiter := middleware.NewRateLimiter(1, 1)
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(limiter.Middleware())
+	r.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+	serve := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		return w
+	}
+	require.Equal(t, http.StatusOK, serve().Code)
+	require.Equal(t, http.StatusTooManyRequests, serve().Code)
+
+	// Act
+	limiter.Update(config.RateLimitConfig{Enabled: true, RequestsPerSecond: 1, Burst: 5})
+	limited := serve()
+	limiter.Update(config.RateLimitConfig{Enabled: false})
+	disabled := serve()
+
+	// Assert
+	assert.Equal(t, "5", limited.Header().Get("X-RateLimit-Limit"))
+	assert.Equal(t, http.StatusOK, disabled.Code)
+	assert.Empty(t, disabled.Header().Get("X-RateLimit-Limit"))
+}
+
+func TestRateLimiter_PerUserMiddlewareLimitsEachUser(t *testing.T) {
+	// Arrange
+	limiter := middleware.NewRateLimiter(1, 1)
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/", func(c *gin.Context) {
+		c.Set(constants.ContextKeyUserID, c.Query("user"))
+		c.Next()
+	}, limiter.PerUserMiddleware(), func(c *gin.Context) { c.Status(http.StatusOK) })
+	serve := func(user string) int {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?user="+user, nil))
+		return w.Code
+	}
+
+	// Act
+	first, repeated, other := serve("admin-1"), serve("admin-1"), serve("admin-2")
+
+	// Assert
+	assert.Equal(t, http.StatusOK, first)
+	assert.Equal(t, http.StatusTooManyRequests, repeated)
+	assert.Equal(t, http.StatusOK, other)
+}
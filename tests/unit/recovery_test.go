@@ -0,0 +1,63 @@
+package usecase_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/TubagusAldiMY/go-template/internal/delivery/http/middleware"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecovery(t *testing.T) {
+	tests := []struct {
+		name        string
+		debug       bool
+		wantDetails bool
+	}{
+		{name: "production hides the panic", debug: false, wantDetails: false},
+		{name: "debug includes the panic and stack", debug: true, wantDetails: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			router.Use(middleware.Recovery(tt.debug))
+			router.GET("/panic", func(c *gin.Context) {
+				panic("db password is hunter2")
+			})
+			rec := httptest.NewRecorder()
+
+			// Act
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+			// Assert
+			require.Equal(t, http.StatusInternalServerError, rec.Code)
+
+			var body struct {
+				Success bool                     `json:"success"`
+				Message string                   `json:"message"`
+				Code    string                   `json:"code"`
+				Errors  *middleware.PanicDetails `json:"errors"`
+			}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.False(t, body.Success)
+			assert.Equal(t, "Internal server error", body.Message)
+			assert.Equal(t, "INTERNAL_ERROR", body.Code)
+
+			if !tt.wantDetails {
+				assert.Nil(t, body.Errors)
+				assert.NotContains(t, rec.Body.String(), "hunter2")
+				return
+			}
+			require.NotNil(t, body.Errors)
+			assert.Equal(t, "db password is hunter2", body.Errors.Panic)
+			assert.Contains(t, body.Errors.Stack, "goroutine")
+		})
+	}
+}
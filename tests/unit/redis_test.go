@@ -0,0 +1,299 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/entity"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/repository"
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/cache"
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/config"
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/database"
+	"github.com/TubagusAldiMY/go-template/pkg/crypto"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRedis(t *testing.T) (*cache.Redis, *miniredis.Miniredis) {
+	t.Helper()
+
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { assert.NoError(t, client.Close()) })
+
+	return &cache.Redis{Client: client}, server
+}
+
+func TestRedisDeleteMany_RemovesAllKeys(t *testing.T) {
+	// Arrange
+	rdb, server := newTestRedis(t)
+	require.NoError(t, server.Set("a", "1"))
+	require.NoError(t, server.Set("b", "2"))
+	require.NoError(t, server.Set("keep", "3"))
+
+	// Act
+	err := rdb.DeleteMany(context.Background(), "a", "b", "missing")
+
+	// Assert
+	require.NoError(t, err)
+	assert.False(t, server.Exists("a"))
+	assert.False(t, server.Exists("b"))
+	assert.True(t, server.Exists("keep"))
+}
+
+func TestRedisPipeline_AppliesQueuedCommands(t *testing.T) {
+	// Arrange
+	rdb, server := newTestRedis(t)
+	ctx := context.Background()
+	require.NoError(t, server.Set("stale", "1"))
+
+	// Act
+	err := rdb.Pipeline(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, "fresh", "value", 0)
+		pipe.Expire(ctx, "fresh", time.Minute)
+		pipe.Del(ctx, "stale")
+		return nil
+	})
+
+	// Assert
+	require.NoError(t, err)
+	got, err := server.Get("fresh")
+	require.NoError(t, err)
+	assert.Equal(t, "value", got)
+	assert.Equal(t, time.Minute, server.TTL("fresh"))
+	assert.False(t, server.Exists("stale"))
+}
+
+func TestRedisPipeline_CallbackErrorSendsNothing(t *testing.T) {
+	// Arrange
+	rdb, server := newTestRedis(t)
+	ctx := context.Background()
+	errAbort := errors.New("abort")
+
+	// Act
+	err := rdb.Pipeline(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, "key", "value", 0)
+		return errAbort
+	})
+
+	// Assert
+	assert.ErrorIs(t, err, errAbort)
+	assert.False(t, server.Exists("key"))
+}
+
+func TestNewRedis_ClusterMode(t *testing.T) {
+	// Arrange
+	server := miniredis.RunT(t)
+	ctx := context.Background()
+
+	// Act
+	rdb, err := cache.NewRedis(config.RedisConfig{
+		Mode:  config.RedisModeCluster,
+		Addrs: []string{server.Addr()},
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, rdb.Close()) })
+
+	// Assert
+	_, isCluster := rdb.Client.(*redis.ClusterClient)
+	assert.True(t, isCluster)
+	require.NoError(t, rdb.Set(ctx, "key", "value", time.Minute))
+	got, err := rdb.Get(ctx, "key")
+	require.NoError(t, err)
+	assert.Equal(t, "value", got)
+	require.NoError(t, rdb.DeleteMany(ctx, "key", "other"))
+	assert.False(t, server.Exists("key"))
+}
+
+func TestCachedUserRepository_UpdateInvalidatesDerivedKeys(t *testing.T) {
+	// Arrange
+	rdb, server := newTestRedis(t)
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	repo := repository.NewCachedUserRepository(mockRepo, rdb)
+
+	user := &entity.User{ID: "user-123", Email: "old@example.com", Username: "olduser", Status: "active", Version: 1}
+	mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+
+	_, err := repo.GetByID(ctx, user.ID)
+	require.NoError(t, err)
+	require.True(t, server.Exists("user:email:old@example.com"))
+
+	updated := *user
+	updated.Email = "new@example.com"
+	mockRepo.On("Update", mock.Anything, &updated).Return(nil)
+
+	// Act
+	err = repo.Update(ctx, &updated)
+
+	// Assert
+	require.NoError(t, err)
+	assert.False(t, server.Exists("user:user-123"))
+	assert.False(t, server.Exists("user:email:old@example.com"))
+	assert.False(t, server.Exists("user:username:olduser"))
+}
+
+func TestCachedUserRepository_WarmCachesUpToLimit(t *testing.T) {
+	// Arrange
+	rdb, server := newTestRedis(t)
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	repo := repository.NewCachedUserRepository(mockRepo, rdb)
+
+	users := []*entity.User{
+		{ID: "user-1", Email: "one@example.com", Username: "one", Status: "active"},
+		{ID: "user-2", Email: "two@example.com", Username: "two", Status: "active"},
+		{ID: "user-3", Email: "three@example.com", Username: "three", Status: "active"},
+	}
+	mockRepo.On("List", mock.Anything, mock.MatchedBy(func(p repository.ListParams) bool {
+		return p.Page == 1 && p.Status == "active" && p.SortBy == "updated_at" && p.SortOrder == "desc"
+	})).Return(users, int64(len(users)), nil)
+
+	// Act
+	warmed, err := repo.Warm(ctx, 2)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 2, warmed)
+	assert.True(t, server.Exists("user:user-1"))
+	assert.True(t, server.Exists("user:email:two@example.com"))
+	assert.False(t, server.Exists("user:user-3"))
+}
+
+func TestCachedUserRepository_WarmStopsOnCancellation(t *testing.T) {
+	// Arrange
+	rdb, server := newTestRedis(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	mockRepo := new(MockUserRepository)
+	repo := repository.NewCachedUserRepository(mockRepo, rdb)
+
+	users := []*entity.User{{ID: "user-1", Email: "one@example.com", Username: "one", Status: "active"}}
+	mockRepo.On("List", mock.Anything, mock.Anything).Return(users, int64(1), nil)
+
+	// Act
+	warmed, err := repo.Warm(ctx, 10)
+
+	// Assert
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Zero(t, warmed)
+	assert.False(t, server.Exists("user:user-1"))
+}
+
+func TestCachedUserRepository_ConcurrentMissesLoadOnce(t *testing.T) {
+	// Arrange
+	rdb, _ := newTestRedis(t)
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	repo := repository.NewCachedUserRepository(mockRepo, rdb)
+
+	user := &entity.User{ID: "user-123", Email: "john@example.com", Username: "john", Status: "active", Version: 1}
+	release := make(chan struct{})
+	mockRepo.On("GetByID", mock.Anything, user.ID).
+		Run(func(mock.Arguments) { <-release }).
+		Return(user, nil)
+
+	const callers = 50
+	var started, done sync.WaitGroup
+	started.Add(callers)
+	done.Add(callers)
+	results := make([]*entity.User, callers)
+	errs := make([]error, callers)
+
+	// Act
+	for i := 0; i < callers; i++ {
+		go func(i int) {
+			defer done.Done()
+			started.Done()
+			results[i], errs[i] = repo.GetByID(ctx, user.ID)
+		}(i)
+	}
+	started.Wait()
+	// Callers that reach the cache after the load finishes hit the entry it
+	// stored, so releasing early cannot cause a second query.
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	done.Wait()
+
+	// Assert
+	mockRepo.AssertNumberOfCalls(t, "GetByID", 1)
+	for i := 0; i < callers; i++ {
+		require.NoError(t, errs[i])
+		assert.Equal(t, user.ID, results[i].ID)
+	}
+	assert.NotSame(t, results[0], results[1])
+}
+
+func TestCachedUserRepository_LaggingReplicaCannotRefillStaleUser(t *testing.T) {
+	// Arrange
+	rdb, _ := newTestRedis(t)
+	ctx := context.Background()
+	mockRepo := new(MockUserRepository)
+	repo := repository.NewCachedUserRepository(mockRepo, rdb)
+
+	// The replica still serves the row as it was before the update below.
+	stale := &entity.User{ID: "user-123", Email: "john@example.com", Username: "john", FullName: "John", Status: "active", Version: 1}
+	fresh := *stale
+	fresh.FullName = "John Doe"
+	fresh.Version = 2
+	onPrimary := mock.MatchedBy(database.PrimaryRequested)
+	onReplica := mock.MatchedBy(func(ctx context.Context) bool { return !database.PrimaryRequested(ctx) })
+	mockRepo.On("GetByID", onReplica, stale.ID).Return(stale, nil)
+	previous := *stale
+	mockRepo.On("GetByID", onPrimary, stale.ID).Return(&previous, nil).Once()
+	mockRepo.On("Update", mock.Anything, &fresh).Return(nil)
+	mockRepo.On("GetByID", onPrimary, stale.ID).Return(&fresh, nil).Once()
+
+	// Act
+	updateErr := repo.Update(ctx, &fresh)
+	first, firstErr := repo.GetByID(ctx, stale.ID)
+	second, secondErr := repo.GetByID(ctx, stale.ID)
+
+	// Assert
+	require.NoError(t, updateErr)
+	require.NoError(t, firstErr)
+	require.NoError(t, secondErr)
+	assert.Equal(t, 2, first.Version)
+	assert.Equal(t, 2, second.Version)
+	mockRepo.AssertNotCalled(t, "GetByID", onReplica, stale.ID)
+}
+
+func TestCachedUserRepository_EncryptionKeepsPersonalDataOutOfRedis(t *testing.T) {
+	// Arrange
+	rdb, server := newTestRedis(t)
+	ctx := context.Background()
+	fields, err := crypto.NewFieldCipher([]byte(strings.Repeat("k", 32)), []byte(strings.Repeat("i", 32)))
+	require.NoError(t, err)
+	mockRepo := new(MockUserRepository)
+	repo := repository.NewCachedUserRepository(mockRepo, rdb, repository.WithCacheEncryption(fields))
+
+	user := &entity.User{ID: "user-123", Email: "john@example.com", Username: "john", Password: "$2a$12$hash", FullName: "John Doe", Status: "active", Version: 1}
+	mockRepo.On("GetByEmail", mock.Anything, user.Email).Return(user, nil).Once()
+
+	// Act
+	_, err = repo.GetByEmail(ctx, user.Email)
+	require.NoError(t, err)
+	cached, cachedErr := repo.GetByEmail(ctx, user.Email)
+
+	// Assert
+	require.NoError(t, cachedErr)
+	assert.Equal(t, user.FullName, cached.FullName)
+	assert.Equal(t, user.Password, cached.Password)
+	mockRepo.AssertNumberOfCalls(t, "GetByEmail", 1)
+	assert.True(t, server.Exists("user:email:"+fields.BlindIndex(user.Email)))
+	for _, key := range server.Keys() {
+		assert.NotContains(t, key, user.Email)
+		value, err := server.Get(key)
+		require.NoError(t, err)
+		for _, secret := range []string{user.Email, user.FullName, user.Password} {
+			assert.NotContains(t, value, secret)
+		}
+	}
+}
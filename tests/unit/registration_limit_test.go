@@ -0,0 +1,218 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	userHttp "github.com/TubagusAldiMY/go-template/internal/domain/user/delivery/http"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/dto"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/repository"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/usecase"
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/config"
+	sharedErrors "github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/TubagusAldiMY/go-template/pkg/validator"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func reserveRegistration(t *testing.T, counter *repository.RedisRegistrationCounter, ip string) {
+	t.Helper()
+	_, _, err := counter.Reserve(context.Background(), ip)
+	require.NoError(t, err)
+}
+
+func TestRedisRegistrationCounter_CountsWithinWindow(t *testing.T) {
+	// Arrange
+	rdb, server := newTestRedis(t)
+	counter := repository.NewRedisRegistrationCounter(rdb, 24*time.Hour)
+	ctx := context.Background()
+
+	// Act
+	reserveRegistration(t, counter, "203.0.113.7")
+	reserveRegistration(t, counter, "203.0.113.7")
+	reserveRegistration(t, counter, "198.51.100.1")
+	count, err := counter.Count(ctx, "203.0.113.7")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+	assert.Equal(t, 24*time.Hour, server.TTL("registrations:203.0.113.7"))
+}
+
+func TestRegister_RefusedOnceIPLimitReached(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	rdb, _ := newTestRedis(t)
+	counter := repository.NewRedisRegistrationCounter(rdb, 24*time.Hour)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis),
+		usecase.WithRegistrationLimit(counter, 2))
+	ctx := usecase.WithClient(context.Background(), usecase.Client{IPAddress: "203.0.113.7"})
+	reserveRegistration(t, counter, "203.0.113.7")
+	reserveRegistration(t, counter, "203.0.113.7")
+
+	// Act
+	_, err := uc.Register(ctx, &dto.RegisterRequest{
+		Email: "test@example.com", Username: "testuser", Password: "SecurePass123!", FullName: "Test User",
+	})
+
+	// Assert
+	assert.ErrorIs(t, err, sharedErrors.ErrRegistrationLimitExceeded)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestRegister_CountsSuccessfulRegistrations(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockHasher := new(MockPasswordHasher)
+	rdb, _ := newTestRedis(t)
+	counter := repository.NewRedisRegistrationCounter(rdb, 24*time.Hour)
+	uc := usecase.NewUserUsecase(mockRepo, mockHasher, new(MockJWTManager), expectStatsInvalidation(new(MockRedis)),
+		usecase.WithRegistrationLimit(counter, 2))
+	ctx := usecase.WithClient(context.Background(), usecase.Client{IPAddress: "203.0.113.7"})
+	req := &dto.RegisterRequest{
+		Email: "test@example.com", Username: "testuser", Password: "SecurePass123!", FullName: "Test User",
+	}
+
+	mockRepo.On("ExistsByEmail", mock.Anything, req.Email).Return(false, nil)
+	mockRepo.On("ExistsByUsername", mock.Anything, req.Username).Return(false, nil)
+	mockHasher.On("Hash", req.Password).Return("hashedpassword", nil)
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.User")).Return(nil)
+
+	// Act
+	_, err := uc.Register(ctx, req)
+
+	// Assert
+	require.NoError(t, err)
+	count, countErr := counter.Count(ctx, "203.0.113.7")
+	require.NoError(t, countErr)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestRegister_FailedRegistrationReleasesReservation(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	rdb, _ := newTestRedis(t)
+	counter := repository.NewRedisRegistrationCounter(rdb, 24*time.Hour)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis),
+		usecase.WithRegistrationLimit(counter, 2))
+	ctx := usecase.WithClient(context.Background(), usecase.Client{IPAddress: "203.0.113.7"})
+	mockRepo.On("ExistsByEmail", mock.Anything, "test@example.com").Return(true, nil)
+
+	// Act
+	_, err := uc.Register(ctx, &dto.RegisterRequest{
+		Email: "test@example.com", Username: "testuser", Password: "SecurePass123!", FullName: "Test User",
+	})
+
+	// Assert
+	assert.ErrorIs(t, err, sharedErrors.ErrEmailAlreadyExists)
+	count, countErr := counter.Count(ctx, "203.0.113.7")
+	require.NoError(t, countErr)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestRegister_ConcurrentRegistrationsStayWithinLimit(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockHasher := new(MockPasswordHasher)
+	rdb, _ := newTestRedis(t)
+	counter := repository.NewRedisRegistrationCounter(rdb, 24*time.Hour)
+	uc := usecase.NewUserUsecase(mockRepo, mockHasher, new(MockJWTManager), expectStatsInvalidation(new(MockRedis)),
+		usecase.WithRegistrationLimit(counter, 3))
+	ctx := usecase.WithClient(context.Background(), usecase.Client{IPAddress: "203.0.113.7"})
+
+	mockRepo.On("ExistsByEmail", mock.Anything, mock.Anything).Return(false, nil)
+	mockRepo.On("ExistsByUsername", mock.Anything, mock.Anything).Return(false, nil)
+	mockHasher.On("Hash", mock.Anything).Return("hashedpassword", nil)
+	mockRepo.On("Create", mock.Anything, mock.AnythingOfType("*entity.User")).Return(nil)
+
+	const attempts = 20
+	var wg sync.WaitGroup
+	errs := make([]error, attempts)
+
+	// Act
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = uc.Register(ctx, &dto.RegisterRequest{
+				Email:    fmt.Sprintf("user%d@example.com", i),
+				Username: fmt.Sprintf("user%d", i),
+				Password: "SecurePass123!",
+				FullName: "Test User",
+			})
+		}(i)
+	}
+	wg.Wait()
+
+	// Assert
+	registered := 0
+	for _, err := range errs {
+		if err == nil {
+			registered++
+			continue
+		}
+		assert.ErrorIs(t, err, sharedErrors.ErrRegistrationLimitExceeded)
+	}
+	assert.Equal(t, 3, registered)
+	mockRepo.AssertNumberOfCalls(t, "Create", 3)
+	count, err := counter.Count(ctx, "203.0.113.7")
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+}
+
+func TestRegisterHandler_LimitsForwardedClientIP(t *testing.T) {
+	// Arrange
+	require.NoError(t, validator.Init())
+	gin.SetMode(gin.TestMode)
+	rdb, _ := newTestRedis(t)
+	counter := repository.NewRedisRegistrationCounter(rdb, 24*time.Hour)
+	reserveRegistration(t, counter, "203.0.113.7")
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis),
+		usecase.WithRegistrationLimit(counter, 1))
+
+	router := gin.New()
+	require.NoError(t, router.SetTrustedProxies([]string{"192.0.2.1"}))
+	router.POST("/auth/register", userHttp.NewUserHandler(uc).Register)
+
+	req := httptest.NewRequest(http.MethodPost, "/auth/register", strings.NewReader(
+		`{"email":"test@example.com","username":"testuser","password":"SecurePass123!","full_name":"Test User"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Forwarded-For", "203.0.113.7")
+	req.RemoteAddr = "192.0.2.1:51234"
+	w := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	var resp struct {
+		Code string `json:"code"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, sharedErrors.CodeRegistrationLimitExceeded, resp.Code)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+}
+
+func TestConfigValidate_RegistrationLimit(t *testing.T) {
+	// Arrange
+	cfg := validConfig()
+	cfg.Registration = config.RegistrationConfig{LimitPerIP: 10}
+
+	// Act
+	err := cfg.Validate()
+
+	// Assert
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "REGISTRATION_LIMIT_WINDOW: must be a positive duration")
+}
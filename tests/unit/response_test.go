@@ -0,0 +1,258 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	sharedErrors "github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/TubagusAldiMY/go-template/pkg/response"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/ugorji/go/codec"
+)
+
+func TestNewMeta_PageIndicators(t *testing.T) {
+	tests := []struct {
+		name       string
+		page       int
+		total      int64
+		totalPages int
+		hasNext    bool
+		hasPrev    bool
+	}{
+		{name: "first page", page: 1, total: 25, totalPages: 3, hasNext: true, hasPrev: false},
+		{name: "middle page", page: 2, total: 25, totalPages: 3, hasNext: true, hasPrev: true},
+		{name: "last page", page: 3, total: 25, totalPages: 3, hasNext: false, hasPrev: true},
+		{name: "empty result", page: 1, total: 0, totalPages: 0, hasNext: false, hasPrev: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			meta := response.NewMeta(tt.page, 10, tt.total)
+
+			// Assert
+			require.NotNil(t, meta.HasNext)
+			require.NotNil(t, meta.HasPrev)
+			assert.Equal(t, tt.totalPages, meta.TotalPages)
+			assert.Equal(t, tt.hasNext, *meta.HasNext)
+			assert.Equal(t, tt.hasPrev, *meta.HasPrev)
+		})
+	}
+}
+
+func TestErrorWithCode_IncludesCode(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+
+	// Act
+	response.ErrorWithCode(c, http.StatusConflict, sharedErrors.CodeEmailAlreadyExists, "Email already exists", nil)
+
+	// Assert
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Equal(t, "EMAIL_ALREADY_EXISTS", body["code"])
+	assert.Equal(t, "Email already exists", body["message"])
+}
+
+func TestError_OmitsCode(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+
+	// Act
+	response.BadRequest(c, "Invalid request body", nil)
+
+	// Assert
+	var body map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.NotContains(t, body, "code")
+}
+
+func TestErrorCode_MapsSentinels(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{name: "sentinel", err: sharedErrors.ErrUsernameAlreadyExists, want: sharedErrors.CodeUsernameAlreadyExists},
+		{name: "wrapped sentinel", err: fmt.Errorf("update: %w", sharedErrors.ErrStaleData), want: sharedErrors.CodeStaleData},
+		{name: "app error", err: sharedErrors.NewAppError("QUOTA_EXCEEDED", "quota exceeded", nil), want: "QUOTA_EXCEEDED"},
+		{name: "unknown", err: fmt.Errorf("boom"), want: sharedErrors.CodeInternal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act & Assert
+			assert.Equal(t, tt.want, sharedErrors.Code(tt.err))
+		})
+	}
+}
+
+func TestFromError(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		status  int
+		code    string
+		message string
+	}{
+		{
+			name:    "sentinel",
+			err:     fmt.Errorf("update profile: %w", sharedErrors.ErrEmailAlreadyExists),
+			status:  http.StatusConflict,
+			code:    sharedErrors.CodeEmailAlreadyExists,
+			message: "Email already exists",
+		},
+		{
+			name:    "timeout",
+			err:     sharedErrors.ContextError(context.DeadlineExceeded),
+			status:  http.StatusServiceUnavailable,
+			code:    sharedErrors.CodeTimeout,
+			message: "Request timed out",
+		},
+		{
+			name:    "sentinel with specific message",
+			err:     sharedErrors.ErrUserNotFound.WithMessage("User user-123 not found"),
+			status:  http.StatusNotFound,
+			code:    sharedErrors.CodeUserNotFound,
+			message: "User user-123 not found",
+		},
+		{
+			name:    "custom app error",
+			err:     sharedErrors.New(http.StatusTooManyRequests, "QUOTA_EXCEEDED", "Upload quota exceeded"),
+			status:  http.StatusTooManyRequests,
+			code:    "QUOTA_EXCEEDED",
+			message: "Upload quota exceeded",
+		},
+		{
+			name:    "app error without status is answered like the error it wraps",
+			err:     sharedErrors.NewAppError("PROFILE_CONFLICT", "profile conflict", sharedErrors.ErrStaleData),
+			status:  http.StatusConflict,
+			code:    "PROFILE_CONFLICT",
+			message: "Resource was modified concurrently, please retry",
+		},
+		{
+			name:    "internal app error is not leaked",
+			err:     sharedErrors.ErrInternal.Wrap(fmt.Errorf("pq: connection refused")),
+			status:  http.StatusInternalServerError,
+			code:    sharedErrors.CodeInternal,
+			message: "Internal server error",
+		},
+		{
+			name:    "unexpected error is not leaked",
+			err:     fmt.Errorf("pq: connection refused"),
+			status:  http.StatusInternalServerError,
+			code:    sharedErrors.CodeInternal,
+			message: "Internal server error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+			// Act
+			response.FromError(c, tt.err)
+
+			// Assert
+			var body map[string]interface{}
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Equal(t, tt.status, rec.Code)
+			assert.Equal(t, tt.code, body["code"])
+			assert.Equal(t, tt.message, body["message"])
+		})
+	}
+}
+
+func TestAppError_MatchesSentinelByCode(t *testing.T) {
+	// Arrange
+	cause := fmt.Errorf("no rows")
+	err := fmt.Errorf("get user: %w", sharedErrors.ErrUserNotFound.WithMessage("User user-123 not found").Wrap(cause))
+
+	// Act
+	var appErr *sharedErrors.AppError
+	found := sharedErrors.As(err, &appErr)
+
+	// Assert
+	require.True(t, found)
+	assert.Equal(t, http.StatusNotFound, appErr.Status)
+	assert.Equal(t, "User user-123 not found", appErr.Message)
+	assert.True(t, sharedErrors.Is(err, sharedErrors.ErrUserNotFound))
+	assert.True(t, sharedErrors.Is(err, cause))
+	assert.False(t, sharedErrors.Is(err, sharedErrors.ErrNotFound))
+	assert.Equal(t, "User not found", sharedErrors.ErrUserNotFound.Message, "copies must not change the sentinel")
+}
+
+func TestSuccess_NegotiatesFormat(t *testing.T) {
+	tests := []struct {
+		name        string
+		accept      string
+		contentType string
+	}{
+		{name: "no accept header", accept: "", contentType: "application/json; charset=utf-8"},
+		{name: "json", accept: "application/json", contentType: "application/json; charset=utf-8"},
+		{name: "msgpack", accept: "application/msgpack", contentType: "application/msgpack"},
+		{name: "legacy msgpack", accept: "application/x-msgpack", contentType: "application/x-msgpack"},
+		{name: "unknown falls back to json", accept: "application/xml", contentType: "application/json; charset=utf-8"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			gin.SetMode(gin.TestMode)
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.accept != "" {
+				c.Request.Header.Set("Accept", tt.accept)
+			}
+
+			// Act
+			response.OK(c, "ok", nil)
+
+			// Assert
+			assert.Equal(t, tt.contentType, rec.Header().Get("Content-Type"))
+			assert.Contains(t, rec.Header().Values("Vary"), "Accept")
+		})
+	}
+}
+
+func TestSuccess_MessagePackUsesJSONFieldNames(t *testing.T) {
+	// Arrange
+	gin.SetMode(gin.TestMode)
+	rec := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(rec)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	c.Request.Header.Set("Accept", "application/msgpack")
+	type payload struct {
+		FullName string `json:"full_name"`
+		Secret   string `json:"-"`
+	}
+
+	// Act
+	response.Paginated(c, "Users retrieved", []payload{{FullName: "John Doe", Secret: "hidden"}}, 1, 10, 1)
+
+	// Assert
+	var body map[string]interface{}
+	handle := &codec.MsgpackHandle{}
+	handle.RawToString = true
+	require.NoError(t, codec.NewDecoderBytes(rec.Body.Bytes(), handle).Decode(&body))
+	assert.Equal(t, true, body["success"])
+	assert.Equal(t, "Users retrieved", body["message"])
+	assert.Equal(t, []interface{}{map[interface{}]interface{}{"full_name": "John Doe"}}, body["data"])
+	assert.Contains(t, body["meta"], "total_items")
+}
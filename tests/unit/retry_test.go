@@ -0,0 +1,82 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/pkg/retry"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryDo_SucceedsAfterFailures(t *testing.T) {
+	// Arrange
+	calls := 0
+	fn := func() error {
+		calls++
+		if calls < 3 {
+			return errors.New("not yet")
+		}
+		return nil
+	}
+
+	// Act
+	err := retry.Do(context.Background(), 5, time.Millisecond, fn)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestRetryDo_ReturnsLastErrorAfterMaxAttempts(t *testing.T) {
+	// Arrange
+	calls := 0
+	fn := func() error {
+		calls++
+		return fmt.Errorf("attempt %d failed", calls)
+	}
+
+	// Act
+	err := retry.Do(context.Background(), 3, time.Millisecond, fn)
+
+	// Assert
+	assert.EqualError(t, err, "attempt 3 failed")
+	assert.Equal(t, 3, calls)
+}
+
+func TestRetryDo_NonPositiveAttemptsCallsOnce(t *testing.T) {
+	// Arrange
+	calls := 0
+	errDown := errors.New("down")
+
+	// Act
+	err := retry.Do(context.Background(), 0, time.Millisecond, func() error {
+		calls++
+		return errDown
+	})
+
+	// Assert
+	assert.ErrorIs(t, err, errDown)
+	assert.Equal(t, 1, calls)
+}
+
+func TestRetryDo_StopsWhenContextIsDone(t *testing.T) {
+	// Arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	errDown := errors.New("down")
+
+	// Act
+	err := retry.Do(ctx, 5, time.Hour, func() error {
+		calls++
+		cancel()
+		return errDown
+	})
+
+	// Assert
+	assert.ErrorIs(t, err, errDown)
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,109 @@
+package usecase_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/usecase"
+	"github.com/TubagusAldiMY/go-template/pkg/scheduler"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduler_RunsJobsUntilStopped(t *testing.T) {
+	// Arrange
+	var runs atomic.Int32
+	jobs := scheduler.New()
+	jobs.Register("count", 5*time.Millisecond, func(context.Context) error {
+		runs.Add(1)
+		return nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Act
+	jobs.Start(ctx)
+	require.Eventually(t, func() bool { return runs.Load() >= 3 }, time.Second, time.Millisecond)
+	cancel()
+	jobs.Wait()
+	stopped := runs.Load()
+	time.Sleep(20 * time.Millisecond)
+
+	// Assert
+	assert.Equal(t, stopped, runs.Load())
+}
+
+func TestScheduler_SkipsRunWhilePreviousIsInProgress(t *testing.T) {
+	// Arrange
+	var running, maxRunning, runs atomic.Int32
+	jobs := scheduler.New()
+	jobs.Register("slow", time.Millisecond, func(ctx context.Context) error {
+		n := running.Add(1)
+		defer running.Add(-1)
+		if n > maxRunning.Load() {
+			maxRunning.Store(n)
+		}
+		runs.Add(1)
+		select {
+		case <-ctx.Done():
+		case <-time.After(20 * time.Millisecond):
+		}
+		return nil
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Act
+	jobs.Start(ctx)
+	require.Eventually(t, func() bool { return runs.Load() >= 2 }, time.Second, time.Millisecond)
+	cancel()
+	jobs.Wait()
+
+	// Assert
+	assert.Equal(t, int32(1), maxRunning.Load())
+}
+
+func TestScheduler_RecoversPanickingJob(t *testing.T) {
+	// Arrange
+	var runs atomic.Int32
+	jobs := scheduler.New()
+	jobs.Register("panics", 5*time.Millisecond, func(context.Context) error {
+		runs.Add(1)
+		panic("boom")
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Act
+	jobs.Start(ctx)
+
+	// Assert
+	require.Eventually(t, func() bool { return runs.Load() >= 2 }, time.Second, time.Millisecond)
+	cancel()
+	jobs.Wait()
+}
+
+func TestPurgeDeletedUsers_DeletesInBatchesPastRetention(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis))
+	retention := 30 * 24 * time.Hour
+	before := time.Now().Add(-retention)
+
+	var cutoff time.Time
+	mockRepo.On("PurgeDeleted", mock.Anything, mock.AnythingOfType("time.Time"), 500).
+		Run(func(args mock.Arguments) { cutoff = args.Get(1).(time.Time) }).
+		Return(int64(500), nil).Once()
+	mockRepo.On("PurgeDeleted", mock.Anything, mock.AnythingOfType("time.Time"), 500).
+		Return(int64(42), nil).Once()
+
+	// Act
+	purged, err := uc.PurgeDeletedUsers(context.Background(), retention)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int64(542), purged)
+	assert.WithinDuration(t, before, cutoff, time.Second)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,337 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	userHttp "github.com/TubagusAldiMY/go-template/internal/domain/user/delivery/http"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/dto"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/entity"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/repository"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/usecase"
+	sharedErrors "github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/TubagusAldiMY/go-template/pkg/appcontext"
+	"github.com/TubagusAldiMY/go-template/pkg/jwt"
+	"github.com/TubagusAldiMY/go-template/pkg/validator"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newSession(userID, id string, lastUsed time.Time) *entity.Session {
+	return &entity.Session{
+		ID:         id,
+		UserID:     userID,
+		TokenID:    id + "-jti",
+		CreatedAt:  lastUsed,
+		LastUsedAt: lastUsed,
+		ExpiresAt:  lastUsed.Add(time.Hour),
+	}
+}
+
+func TestRedisSessionRepository_SaveGetList(t *testing.T) {
+	// Arrange
+	rdb, server := newTestRedis(t)
+	repo := repository.NewRedisSessionRepository(rdb, 24*time.Hour)
+	ctx := context.Background()
+	now := time.Now()
+	older := newSession("user-123", "session-1", now.Add(-time.Minute))
+	newer := newSession("user-123", "session-2", now)
+
+	// Act
+	require.NoError(t, repo.Save(ctx, older))
+	require.NoError(t, repo.Save(ctx, newer))
+	got, getErr := repo.Get(ctx, "user-123", "session-1")
+	sessions, listErr := repo.List(ctx, "user-123")
+
+	// Assert
+	require.NoError(t, getErr)
+	assert.Equal(t, "session-1-jti", got.TokenID)
+	require.NoError(t, listErr)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, "session-2", sessions[0].ID)
+	assert.Equal(t, "session-1", sessions[1].ID)
+	assert.Equal(t, 24*time.Hour, server.TTL("session:user-123"))
+}
+
+func TestRedisSessionRepository_ExpiredSessionsAreGoneAndPruned(t *testing.T) {
+	// Arrange
+	rdb, server := newTestRedis(t)
+	repo := repository.NewRedisSessionRepository(rdb, 24*time.Hour)
+	ctx := context.Background()
+	expired := newSession("user-123", "session-1", time.Now().Add(-2*time.Hour))
+	require.NoError(t, repo.Save(ctx, expired))
+	require.NoError(t, repo.Save(ctx, newSession("user-123", "session-2", time.Now())))
+
+	// Act
+	_, getErr := repo.Get(ctx, "user-123", "session-1")
+	sessions, listErr := repo.List(ctx, "user-123")
+
+	// Assert
+	assert.ErrorIs(t, getErr, sharedErrors.ErrSessionNotFound)
+	require.NoError(t, listErr)
+	require.Len(t, sessions, 1)
+	assert.Equal(t, "session-2", sessions[0].ID)
+	keys, err := server.HKeys("session:user-123")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"session-2"}, keys)
+}
+
+func TestRedisSessionRepository_Delete(t *testing.T) {
+	// Arrange
+	rdb, _ := newTestRedis(t)
+	repo := repository.NewRedisSessionRepository(rdb, 24*time.Hour)
+	ctx := context.Background()
+	require.NoError(t, repo.Save(ctx, newSession("user-123", "session-1", time.Now())))
+	require.NoError(t, repo.Save(ctx, newSession("user-123", "session-2", time.Now())))
+
+	// Act
+	deleteErr := repo.Delete(ctx, "user-123", "session-1")
+	missingErr := repo.Delete(ctx, "user-123", "session-1")
+	otherUserErr := repo.Delete(ctx, "user-456", "session-2")
+	deleteAllErr := repo.DeleteAll(ctx, "user-123")
+
+	// Assert
+	require.NoError(t, deleteErr)
+	assert.ErrorIs(t, missingErr, sharedErrors.ErrSessionNotFound)
+	assert.ErrorIs(t, otherUserErr, sharedErrors.ErrSessionNotFound)
+	require.NoError(t, deleteAllErr)
+	sessions, err := repo.List(ctx, "user-123")
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+}
+
+func TestLogin_RecordsClientOnSession(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockHasher := new(MockPasswordHasher)
+	mockJWT := new(MockJWTManager)
+	mockSessions := new(MockSessionRepository)
+	uc := usecase.NewUserUsecase(mockRepo, mockHasher, mockJWT, new(MockRedis), usecase.WithSessionRepository(mockSessions))
+
+	req := &dto.LoginRequest{Email: "test@example.com", Password: "SecurePass123!"}
+	user := &entity.User{ID: "user-123", Email: req.Email, Password: "hashedpassword", Role: "user", Status: "active"}
+
+	mockRepo.On("GetByEmail", mock.Anything, req.Email).Return(user, nil)
+	mockHasher.On("IsValid", user.Password, req.Password).Return(true)
+	mockHasher.On("NeedsRehash", user.Password).Return(false)
+	mockRepo.On("UpdateLastLogin", mock.Anything, user.ID, mock.AnythingOfType("time.Time")).Return(nil)
+	mockJWT.On("GenerateAccessToken", mock.Anything, user.ID, user.Email, user.Role).Return("access-token", nil)
+	mockJWT.On("GenerateRefreshToken", user.ID, mock.AnythingOfType("string"), mockRefreshTokenDuration).Return("refresh-token", "refresh-jti", nil)
+
+	var saved *entity.Session
+	mockSessions.On("Save", mock.Anything, mock.AnythingOfType("*entity.Session")).
+		Run(func(args mock.Arguments) { saved = args.Get(1).(*entity.Session) }).
+		Return(nil)
+
+	ctx := usecase.WithClient(context.Background(), usecase.Client{UserAgent: "Firefox", IPAddress: "203.0.113.7"})
+
+	// Act
+	_, err := uc.Login(ctx, req)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, saved)
+	assert.NotEmpty(t, saved.ID)
+	assert.Equal(t, "refresh-jti", saved.TokenID)
+	assert.Equal(t, "Firefox", saved.UserAgent)
+	assert.Equal(t, "203.0.113.7", saved.IPAddress)
+	assert.WithinDuration(t, time.Now().Add(mockRefreshTokenDuration), saved.ExpiresAt, time.Minute)
+	mockJWT.AssertCalled(t, "GenerateRefreshToken", user.ID, saved.ID, mockRefreshTokenDuration)
+}
+
+func TestRevokeSession_UnknownSessionIsNotFound(t *testing.T) {
+	// Arrange
+	mockSessions := new(MockSessionRepository)
+	uc := usecase.NewUserUsecase(new(MockUserRepository), new(MockPasswordHasher), new(MockJWTManager), new(MockRedis),
+		usecase.WithSessionRepository(mockSessions))
+	mockSessions.On("Delete", mock.Anything, "user-123", "session-9").Return(sharedErrors.ErrSessionNotFound)
+
+	// Act
+	err := uc.RevokeSession(context.Background(), "user-123", "session-9")
+
+	// Assert
+	assert.ErrorIs(t, err, sharedErrors.ErrSessionNotFound)
+}
+
+func newSessionRouter(t *testing.T, uc *usecase.UserUsecase) *gin.Engine {
+	require.NoError(t, validator.Init())
+	gin.SetMode(gin.TestMode)
+	handler := userHttp.NewUserHandler(uc)
+
+	router := gin.New()
+	router.POST("/api/v1/auth/login", handler.Login)
+	authenticated := router.Group("/api/v1/users", func(c *gin.Context) {
+		c.Request = c.Request.WithContext(appcontext.WithUserID(c.Request.Context(), "user-123"))
+	})
+	authenticated.GET("/sessions", handler.ListSessions)
+	authenticated.DELETE("/sessions/:id", handler.RevokeSession)
+	return router
+}
+
+func TestRedisSessionRepository_RotateComparesTokenID(t *testing.T) {
+	// Arrange
+	rdb, _ := newTestRedis(t)
+	repo := repository.NewRedisSessionRepository(rdb, 24*time.Hour)
+	ctx := context.Background()
+	session := newSession("user-123", "session-1", time.Now())
+	require.NoError(t, repo.Save(ctx, session))
+
+	rotated := *session
+	rotated.Rotate("second-jti", time.Hour)
+	replayed := *session
+	replayed.Rotate("third-jti", time.Hour)
+	missing := newSession("user-123", "session-2", time.Now())
+
+	// Act
+	rotateErr := repo.Rotate(ctx, &rotated, session.TokenID)
+	replayErr := repo.Rotate(ctx, &replayed, session.TokenID)
+	missingErr := repo.Rotate(ctx, missing, missing.TokenID)
+
+	// Assert
+	require.NoError(t, rotateErr)
+	assert.ErrorIs(t, replayErr, sharedErrors.ErrStaleData)
+	assert.ErrorIs(t, missingErr, sharedErrors.ErrSessionNotFound)
+	stored, err := repo.Get(ctx, "user-123", "session-1")
+	require.NoError(t, err)
+	assert.Equal(t, "second-jti", stored.TokenID)
+}
+
+func TestRefreshToken_ConcurrentRefreshesWithOneTokenRotateOnce(t *testing.T) {
+	// Arrange
+	rdb, _ := newTestRedis(t)
+	sessions := repository.NewRedisSessionRepository(rdb, 24*time.Hour)
+	mockRepo := new(MockUserRepository)
+	mockJWT := new(MockJWTManager)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), mockJWT, new(MockRedis), usecase.WithSessionRepository(sessions))
+
+	user := &entity.User{ID: "user-123", Email: "test@example.com", Role: "user", Status: "active"}
+	session := newSession(user.ID, "session-1", time.Now())
+	require.NoError(t, sessions.Save(context.Background(), session))
+
+	mockJWT.On("ValidateRefreshToken", "refresh-token").
+		Return(&jwt.RefreshClaims{UserID: user.ID, TokenID: session.TokenID, SessionID: session.ID, Lifetime: mockRefreshTokenDuration}, nil)
+	mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	mockJWT.On("GenerateAccessToken", mock.Anything, user.ID, user.Email, user.Role).Return("access-token", nil)
+	mockJWT.On("GenerateRefreshToken", user.ID, session.ID, mockRefreshTokenDuration).Return("new-refresh-token", "new-jti", nil)
+
+	const refreshes = 10
+	var wg sync.WaitGroup
+	errs := make([]error, refreshes)
+
+	// Act
+	for i := 0; i < refreshes; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = uc.RefreshToken(context.Background(), &dto.RefreshTokenRequest{RefreshToken: "refresh-token"})
+		}(i)
+	}
+	wg.Wait()
+
+	// Assert
+	rotated := 0
+	for _, err := range errs {
+		if err == nil {
+			rotated++
+			continue
+		}
+		assert.ErrorIs(t, err, sharedErrors.ErrInvalidToken)
+	}
+	assert.Equal(t, 1, rotated)
+	// Every refresh after the first reused the token and revoked the session.
+	_, err := sessions.Get(context.Background(), user.ID, session.ID)
+	assert.ErrorIs(t, err, sharedErrors.ErrSessionNotFound)
+}
+
+func TestLoginHandler_CapturesUserAgentAndIP(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockHasher := new(MockPasswordHasher)
+	mockJWT := new(MockJWTManager)
+	mockSessions := new(MockSessionRepository)
+	router := newSessionRouter(t, usecase.NewUserUsecase(mockRepo, mockHasher, mockJWT, new(MockRedis),
+		usecase.WithSessionRepository(mockSessions)))
+
+	user := &entity.User{ID: "user-123", Email: "test@example.com", Password: "hashedpassword", Role: "user", Status: "active"}
+	mockRepo.On("GetByEmail", mock.Anything, user.Email).Return(user, nil)
+	mockHasher.On("IsValid", user.Password, "SecurePass123!").Return(true)
+	mockHasher.On("NeedsRehash", user.Password).Return(false)
+	mockRepo.On("UpdateLastLogin", mock.Anything, user.ID, mock.AnythingOfType("time.Time")).Return(nil)
+	mockJWT.On("GenerateAccessToken", mock.Anything, user.ID, user.Email, user.Role).Return("access-token", nil)
+	mockJWT.On("GenerateRefreshToken", user.ID, mock.Anything, mockRefreshTokenDuration).Return("refresh-token", "refresh-jti", nil)
+	mockSessions.On("Save", mock.Anything, mock.MatchedBy(func(s *entity.Session) bool {
+		return s.UserAgent == "curl/8.5.0" && s.IPAddress == "192.0.2.10"
+	})).Return(nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login",
+		strings.NewReader(`{"email":"test@example.com","password":"SecurePass123!"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "curl/8.5.0")
+	req.RemoteAddr = "192.0.2.10:51234"
+	rec := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(rec, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, rec.Code)
+	mockSessions.AssertExpectations(t)
+}
+
+func TestListSessionsHandler_ReturnsCallersSessions(t *testing.T) {
+	// Arrange
+	mockSessions := new(MockSessionRepository)
+	router := newSessionRouter(t, usecase.NewUserUsecase(new(MockUserRepository), new(MockPasswordHasher), new(MockJWTManager), new(MockRedis),
+		usecase.WithSessionRepository(mockSessions)))
+
+	session := newSession("user-123", "session-1", time.Date(2026, 5, 1, 10, 0, 0, 0, time.FixedZone("WIB", 7*60*60)))
+	session.UserAgent = "Firefox"
+	mockSessions.On("List", mock.Anything, "user-123").Return([]*entity.Session{session}, nil)
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/users/sessions", nil)
+
+	// Act
+	router.ServeHTTP(rec, req)
+
+	// Assert
+	require.Equal(t, http.StatusOK, rec.Code)
+	var body struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Len(t, body.Data, 1)
+	assert.Equal(t, "session-1", body.Data[0]["id"])
+	assert.Equal(t, "Firefox", body.Data[0]["user_agent"])
+	assert.Equal(t, "2026-05-01T03:00:00Z", body.Data[0]["last_used_at"])
+	assert.NotContains(t, body.Data[0], "token_id")
+}
+
+func TestRevokeSessionHandler(t *testing.T) {
+	// Arrange
+	mockSessions := new(MockSessionRepository)
+	router := newSessionRouter(t, usecase.NewUserUsecase(new(MockUserRepository), new(MockPasswordHasher), new(MockJWTManager), new(MockRedis),
+		usecase.WithSessionRepository(mockSessions)))
+	mockSessions.On("Delete", mock.Anything, "user-123", "session-1").Return(nil)
+	mockSessions.On("Delete", mock.Anything, "user-123", "session-9").Return(sharedErrors.ErrSessionNotFound)
+
+	revoked := httptest.NewRecorder()
+	missing := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(revoked, httptest.NewRequest(http.MethodDelete, "/api/v1/users/sessions/session-1", nil))
+	router.ServeHTTP(missing, httptest.NewRequest(http.MethodDelete, "/api/v1/users/sessions/session-9", nil))
+
+	// Assert
+	assert.Equal(t, http.StatusOK, revoked.Code)
+	assert.Equal(t, http.StatusNotFound, missing.Code)
+	assert.Contains(t, missing.Body.String(), sharedErrors.CodeSessionNotFound)
+}
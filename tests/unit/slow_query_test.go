@@ -0,0 +1,57 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/database"
+	"github.com/TubagusAldiMY/go-template/pkg/logger"
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestSlowQueryLogger_LogsSlowQueriesWithRedactedArgs(t *testing.T) {
+	// Arrange
+	core, logs := observer.New(zap.InfoLevel)
+	ctx := logger.NewContext(context.Background(), zap.New(core).With(zap.String("request_id", "req-1")))
+	tracer := database.NewSlowQueryLogger(10 * time.Millisecond)
+
+	// Act
+	queryCtx := tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{
+		SQL:  "SELECT id\n\t\tFROM users WHERE email = $1 AND deleted_at IS NULL LIMIT $2",
+		Args: []any{"john@example.com", 20, nil},
+	})
+	time.Sleep(15 * time.Millisecond)
+	tracer.TraceQueryEnd(queryCtx, nil, pgx.TraceQueryEndData{Err: errors.New("canceling statement")})
+
+	// Assert
+	require.Equal(t, 1, logs.Len())
+	entry := logs.All()[0]
+	assert.Equal(t, zap.WarnLevel, entry.Level)
+	assert.Equal(t, "slow query", entry.Message)
+	fields := entry.ContextMap()
+	assert.Equal(t, "req-1", fields["request_id"])
+	assert.Equal(t, "SELECT id FROM users WHERE email = $1 AND deleted_at IS NULL LIMIT $2", fields["sql"])
+	assert.Equal(t, []interface{}{"string", "int", "NULL"}, fields["args"])
+	assert.Equal(t, "canceling statement", fields["error"])
+	assert.GreaterOrEqual(t, fields["duration"], 10*time.Millisecond)
+}
+
+func TestSlowQueryLogger_IgnoresFastQueries(t *testing.T) {
+	// Arrange
+	core, logs := observer.New(zap.InfoLevel)
+	ctx := logger.NewContext(context.Background(), zap.New(core))
+	tracer := database.NewSlowQueryLogger(time.Minute)
+
+	// Act
+	queryCtx := tracer.TraceQueryStart(ctx, nil, pgx.TraceQueryStartData{SQL: "SELECT 1"})
+	tracer.TraceQueryEnd(queryCtx, nil, pgx.TraceQueryEndData{})
+
+	// Assert
+	assert.Equal(t, 0, logs.Len())
+}
@@ -0,0 +1,146 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/usecase"
+	"github.com/TubagusAldiMY/go-template/internal/shared/constants"
+	sharedErrors "github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetStats_ServesCachedCounts(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockRedis := new(MockRedis)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), mockRedis)
+
+	mockRedis.On("Get", mock.Anything, constants.CacheKeyUserStats).
+		Return(`{"total":5,"active":3,"inactive":1,"banned":1}`, nil)
+
+	// Act
+	stats, err := uc.GetStats(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), stats.Total)
+	assert.Equal(t, int64(3), stats.Active)
+	mockRepo.AssertNotCalled(t, "CountByStatus", mock.Anything)
+}
+
+func TestGetStats_CountsAndCachesOnMiss(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockRedis := new(MockRedis)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), mockRedis)
+
+	mockRedis.On("Get", mock.Anything, constants.CacheKeyUserStats).Return("", redis.Nil)
+	mockRepo.On("CountByStatus", mock.Anything).Return(map[string]int64{
+		"active":   7,
+		"inactive": 2,
+		"banned":   1,
+	}, nil)
+	mockRedis.On("Set", mock.Anything, constants.CacheKeyUserStats, mock.Anything, time.Minute).Return(nil)
+
+	// Act
+	stats, err := uc.GetStats(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), stats.Total)
+	assert.Equal(t, int64(7), stats.Active)
+	assert.Equal(t, int64(2), stats.Inactive)
+	assert.Equal(t, int64(1), stats.Banned)
+	mockRedis.AssertExpectations(t)
+}
+
+func TestGetStats_CacheFailureFallsBackToDatabase(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockRedis := new(MockRedis)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), mockRedis)
+
+	mockRedis.On("Get", mock.Anything, constants.CacheKeyUserStats).Return("", errors.New("connection refused"))
+	mockRepo.On("CountByStatus", mock.Anything).Return(map[string]int64{"active": 4}, nil)
+	mockRedis.On("Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(errors.New("connection refused"))
+
+	// Act
+	stats, err := uc.GetStats(context.Background())
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), stats.Total)
+	assert.Equal(t, int64(4), stats.Active)
+}
+
+func TestGetStats_ConcurrentMissesShareOneQuery(t *testing.T) {
+	// Arrange
+	const callers = 10
+
+	mockRepo := new(MockUserRepository)
+	mockRedis := new(MockRedis)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), mockRedis)
+
+	var missed sync.WaitGroup
+	missed.Add(callers)
+	release := make(chan struct{})
+
+	mockRedis.On("Get", mock.Anything, constants.CacheKeyUserStats).
+		Run(func(mock.Arguments) { missed.Done() }).
+		Return("", redis.Nil)
+	mockRepo.On("CountByStatus", mock.Anything).
+		Run(func(mock.Arguments) { <-release }).
+		Return(map[string]int64{"active": 1}, nil).
+		Once()
+	mockRedis.On("Set", mock.Anything, constants.CacheKeyUserStats, mock.Anything, mock.Anything).Return(nil)
+
+	// Act
+	var wg sync.WaitGroup
+	results := make([]int64, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			stats, err := uc.GetStats(context.Background())
+			if assert.NoError(t, err) {
+				results[i] = stats.Total
+			}
+		}(i)
+	}
+	missed.Wait()
+	// Let the callers that missed the cache join the query in flight.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	// Assert
+	mockRepo.AssertNumberOfCalls(t, "CountByStatus", 1)
+	for _, total := range results {
+		assert.Equal(t, int64(1), total)
+	}
+}
+
+func TestGetStats_QueryFailure(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockRedis := new(MockRedis)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), mockRedis)
+
+	mockRedis.On("Get", mock.Anything, constants.CacheKeyUserStats).Return("", redis.Nil)
+	mockRepo.On("CountByStatus", mock.Anything).Return(nil, errors.New("connection reset"))
+
+	// Act
+	stats, err := uc.GetStats(context.Background())
+
+	// Assert
+	assert.Nil(t, stats)
+	assert.True(t, errors.Is(err, sharedErrors.ErrInternal))
+	mockRedis.AssertNotCalled(t, "Set", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
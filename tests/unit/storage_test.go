@@ -0,0 +1,93 @@
+package usecase_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/TubagusAldiMY/go-template/pkg/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalStorage_PutGetDelete(t *testing.T) {
+	// Arrange
+	store, err := storage.NewLocal(t.TempDir(), "/uploads/")
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// Act
+	err = store.Put(ctx, "avatars/user-1/a.png", strings.NewReader("image"), 5, "image/png")
+
+	// Assert
+	require.NoError(t, err)
+	file, err := store.Get(ctx, "avatars/user-1/a.png")
+	require.NoError(t, err)
+	content, err := io.ReadAll(file)
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+	assert.Equal(t, "image", string(content))
+	assert.Equal(t, "/uploads/avatars/user-1/a.png", store.URL("avatars/user-1/a.png"))
+
+	require.NoError(t, store.Delete(ctx, "avatars/user-1/a.png"))
+	require.NoError(t, store.Delete(ctx, "avatars/user-1/a.png"))
+	_, err = store.Get(ctx, "avatars/user-1/a.png")
+	assert.ErrorIs(t, err, storage.ErrNotFound)
+}
+
+func TestLocalStorage_RejectsEscapingKeys(t *testing.T) {
+	// Arrange
+	store, err := storage.NewLocal(t.TempDir(), "/uploads")
+	require.NoError(t, err)
+
+	for _, key := range []string{"", "../secret", "avatars/../../secret", "/etc/passwd", "avatars//a.png"} {
+		// Act
+		err := store.Put(context.Background(), key, strings.NewReader("x"), 1, "")
+
+		// Assert
+		assert.Error(t, err, key)
+	}
+}
+
+func TestS3Storage_SignsRequests(t *testing.T) {
+	// Arrange
+	var gotMethod, gotPath, gotAuth, gotBody, gotType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotMethod, gotPath, gotAuth, gotType = r.Method, r.URL.Path, r.Header.Get("Authorization"), r.Header.Get("Content-Type")
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		gotBody = string(body)
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	store, err := storage.NewS3(storage.S3Config{
+		Endpoint:     server.URL,
+		Region:       "eu-west-1",
+		Bucket:       "media",
+		AccessKey:    "AKIDEXAMPLE",
+		SecretKey:    "secret",
+		UsePathStyle: true,
+	})
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	// Act
+	err = store.Put(ctx, "avatars/user-1/a.png", strings.NewReader("image"), 5, "image/png")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, http.MethodPut, gotMethod)
+	assert.Equal(t, "/media/avatars/user-1/a.png", gotPath)
+	assert.Equal(t, "image", gotBody)
+	assert.Equal(t, "image/png", gotType)
+	assert.True(t, strings.HasPrefix(gotAuth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/"), gotAuth)
+	assert.Contains(t, gotAuth, "/eu-west-1/s3/aws4_request")
+	assert.Equal(t, server.URL+"/media/avatars/user-1/a.png", store.URL("avatars/user-1/a.png"))
+	assert.NoError(t, store.Delete(ctx, "avatars/user-1/a.png"), "deleting a missing object succeeds")
+}
@@ -0,0 +1,273 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/database"
+	sharedErrors "github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type widget struct {
+	ID   string
+	Name string
+}
+
+var errWidgetNotFound = sharedErrors.NotFound("WIDGET_NOT_FOUND", "Widget not found")
+
+var widgetTable = database.Table[widget]{
+	Name:       "widgets",
+	Entity:     "widget",
+	Columns:    []string{"id", "COALESCE(name, '')"},
+	Scan:       func(w *widget) []any { return []any{&w.ID, &w.Name} },
+	Insert:     []string{"id", "name"},
+	Values:     func(w *widget) []any { return []any{w.ID, w.Name} },
+	SoftDelete: true,
+	NotFound:   errWidgetNotFound,
+}
+
+// noRowsTx is a pgx.Tx whose QueryRow finds nothing.
+type noRowsTx struct {
+	recordingTx
+}
+
+type noRow struct{}
+
+func (noRow) Scan(...any) error { return pgx.ErrNoRows }
+
+func (tx *noRowsTx) QueryRow(ctx context.Context, sql string, args ...any) pgx.Row {
+	tx.recordingTx.QueryRow(ctx, sql, args...)
+	return noRow{}
+}
+
+func TestStore_GetByFiltersDeletedRows(t *testing.T) {
+	// Arrange
+	store := database.NewStore(nil, 0, widgetTable)
+	tx := &noRowsTx{}
+	ctx := database.ContextWithTx(context.Background(), tx)
+
+	// Act
+	got, err := store.GetBy(ctx, "name", "gear")
+
+	// Assert
+	assert.Nil(t, got)
+	assert.ErrorIs(t, err, errWidgetNotFound)
+	assert.Equal(t, "SELECT id, COALESCE(name, '') FROM widgets WHERE deleted_at IS NULL AND name = $1 LIMIT 1", tx.queries[0])
+	assert.Equal(t, []any{"gear"}, tx.args[0])
+}
+
+func TestStore_ListAppendsSuffixAfterFilter(t *testing.T) {
+	// Arrange
+	store := database.NewStore(nil, 0, widgetTable)
+	tx := &recordingTx{}
+	ctx := database.ContextWithTx(context.Background(), tx)
+	filter := store.Filter().Where("name = ?", "gear")
+
+	// Act
+	_, err := store.List(ctx, filter, " ORDER BY id LIMIT "+filter.Arg(10))
+
+	// Assert
+	require.ErrorIs(t, err, errQueryRecorded)
+	assert.ErrorContains(t, err, "failed to list widgets")
+	assert.Equal(t, "SELECT id, COALESCE(name, '') FROM widgets WHERE deleted_at IS NULL AND name = $1 ORDER BY id LIMIT $2", tx.queries[0])
+	assert.Equal(t, []any{"gear", 10}, tx.args[0])
+}
+
+func TestStore_InsertMapsErrors(t *testing.T) {
+	errDuplicate := sharedErrors.Conflict("WIDGET_EXISTS", "Widget already exists")
+	table := widgetTable
+	table.MapError = func(err error) error {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return errDuplicate
+		}
+		return nil
+	}
+
+	tests := []struct {
+		name    string
+		err     error
+		wantIs  error
+		wantMsg string
+	}{
+		{name: "mapped", err: &pgconn.PgError{Code: "23505"}, wantIs: errDuplicate},
+		{name: "cancelled", err: context.Canceled, wantIs: context.Canceled},
+		{name: "other", err: &pgconn.PgError{Code: "23502"}, wantMsg: "failed to create widget"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			store := database.NewStore(nil, 0, table)
+			ctx := database.ContextWithTx(context.Background(), failingTx{err: tt.err})
+
+			// Act
+			err := store.Insert(ctx, &widget{ID: "w-1", Name: "gear"})
+
+			// Assert
+			if tt.wantIs != nil {
+				assert.ErrorIs(t, err, tt.wantIs)
+			}
+			if tt.wantMsg != "" {
+				assert.ErrorContains(t, err, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestStore_DeleteMissingRowReturnsNotFound(t *testing.T) {
+	// Arrange
+	store := database.NewStore(nil, 0, widgetTable)
+	ctx := database.ContextWithTx(context.Background(), failingTx{})
+
+	// Act
+	err := store.Delete(ctx, "w-1")
+
+	// Assert
+	assert.ErrorIs(t, err, errWidgetNotFound)
+}
+
+func TestStore_InsertSQLNumbersPlaceholders(t *testing.T) {
+	// Arrange
+	store := database.NewStore(nil, 0, widgetTable)
+
+	// Act
+	query := store.InsertSQL()
+
+	// Assert
+	assert.Equal(t, "INSERT INTO widgets (id, name) VALUES ($1, $2)", query)
+}
+
+// cursorTx is a pgx.Tx serving widgets from a server-side cursor. Begin
+// returns the transaction itself, FETCH statements return the next batch of
+// rows, and Rollback records the context it was called with.
+type cursorTx struct {
+	recordingTx
+	rows       []widget
+	fetchErr   error
+	rolledBack bool
+	rollbackOK bool
+}
+
+func (tx *cursorTx) Begin(context.Context) (pgx.Tx, error) {
+	return tx, nil
+}
+
+func (tx *cursorTx) Exec(_ context.Context, sql string, args ...any) (pgconn.CommandTag, error) {
+	tx.queries = append(tx.queries, sql)
+	tx.args = append(tx.args, args)
+	return pgconn.CommandTag{}, nil
+}
+
+func (tx *cursorTx) Query(_ context.Context, sql string, args ...any) (pgx.Rows, error) {
+	tx.queries = append(tx.queries, sql)
+	tx.args = append(tx.args, args)
+	if tx.fetchErr != nil {
+		return nil, tx.fetchErr
+	}
+
+	n := 2 // the batch size the tests use
+	if n > len(tx.rows) {
+		n = len(tx.rows)
+	}
+	batch := tx.rows[:n]
+	tx.rows = tx.rows[n:]
+	return &widgetRows{rows: batch, pos: -1}, nil
+}
+
+func (tx *cursorTx) Rollback(ctx context.Context) error {
+	tx.rolledBack = true
+	tx.rollbackOK = ctx.Err() == nil
+	return nil
+}
+
+type widgetRows struct {
+	pgx.Rows
+	rows []widget
+	pos  int
+}
+
+func (r *widgetRows) Next() bool {
+	r.pos++
+	return r.pos < len(r.rows)
+}
+
+func (r *widgetRows) Scan(dest ...any) error {
+	*dest[0].(*string) = r.rows[r.pos].ID
+	*dest[1].(*string) = r.rows[r.pos].Name
+	return nil
+}
+
+func (r *widgetRows) Err() error { return nil }
+
+func (r *widgetRows) Close() {}
+
+func TestStore_EachStreamsThroughCursorInBatches(t *testing.T) {
+	// Arrange
+	store := database.NewStore(nil, 0, widgetTable)
+	tx := &cursorTx{rows: []widget{{ID: "1"}, {ID: "2"}, {ID: "3"}, {ID: "4"}, {ID: "5"}}}
+	ctx := database.ContextWithTx(context.Background(), tx)
+
+	var got []string
+
+	// Act
+	err := store.Each(ctx, store.Filter().Where("name = ?", "gear"), " ORDER BY id", 2, func(w *widget) error {
+		got = append(got, w.ID)
+		return nil
+	})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1", "2", "3", "4", "5"}, got)
+	assert.Equal(t, []string{
+		"DECLARE widgets_cursor NO SCROLL CURSOR FOR SELECT id, COALESCE(name, '') FROM widgets WHERE deleted_at IS NULL AND name = $1 ORDER BY id",
+		"FETCH 2 FROM widgets_cursor",
+		"FETCH 2 FROM widgets_cursor",
+		"FETCH 2 FROM widgets_cursor",
+	}, tx.queries)
+	assert.Equal(t, []any{"gear"}, tx.args[0])
+	assert.True(t, tx.rolledBack)
+}
+
+func TestStore_EachStopsAtCallbackError(t *testing.T) {
+	// Arrange
+	store := database.NewStore(nil, 0, widgetTable)
+	tx := &cursorTx{rows: []widget{{ID: "1"}, {ID: "2"}, {ID: "3"}}}
+	ctx := database.ContextWithTx(context.Background(), tx)
+	stop := errors.New("client went away")
+
+	calls := 0
+
+	// Act
+	err := store.Each(ctx, store.Filter(), "", 2, func(*widget) error {
+		calls++
+		return stop
+	})
+
+	// Assert
+	assert.Equal(t, stop, err)
+	assert.Equal(t, 1, calls)
+	assert.Len(t, tx.queries, 2) // DECLARE and one FETCH
+	assert.True(t, tx.rolledBack)
+}
+
+func TestStore_EachReleasesCursorWhenCancelled(t *testing.T) {
+	// Arrange
+	store := database.NewStore(nil, 0, widgetTable)
+	tx := &cursorTx{fetchErr: context.Canceled}
+	ctx, cancel := context.WithCancel(database.ContextWithTx(context.Background(), tx))
+	cancel()
+
+	// Act
+	err := store.Each(ctx, store.Filter(), "", 2, func(*widget) error { return nil })
+
+	// Assert
+	assert.Equal(t, context.Canceled, err)
+	assert.True(t, tx.rolledBack)
+	assert.True(t, tx.rollbackOK, "rollback must not run on the cancelled context")
+}
@@ -0,0 +1,103 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/messaging"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisXRead_PagesThroughStream(t *testing.T) {
+	// Arrange
+	rdb, _ := newTestRedis(t)
+	ctx := context.Background()
+	for _, value := range []string{"a", "b", "c"} {
+		_, err := rdb.XAdd(ctx, "events", 0, map[string]interface{}{"value": value})
+		require.NoError(t, err)
+	}
+
+	// Act
+	first, err := rdb.XRead(ctx, "events", "0", 2, 0)
+	require.NoError(t, err)
+	require.Len(t, first, 2)
+	second, err := rdb.XRead(ctx, "events", first[1].ID, 2, 0)
+	require.NoError(t, err)
+	rest, err := rdb.XRead(ctx, "events", second[0].ID, 2, 0)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "a", first[0].Values["value"])
+	require.Len(t, second, 1)
+	assert.Equal(t, "c", second[0].Values["value"])
+	assert.Empty(t, rest)
+}
+
+func TestStreamConsumer_RetriesThenDeadLetters(t *testing.T) {
+	// Arrange
+	rdb, _ := newTestRedis(t)
+	publisher := messaging.NewStreamPublisher(rdb, "user.events", 1000)
+
+	var mu sync.Mutex
+	var handled []messaging.StreamMessage
+	consumer := messaging.NewStreamConsumer(rdb, messaging.StreamConsumerConfig{
+		Stream:     "user.events",
+		Group:      "workers",
+		MaxRetries: 1,
+		Block:      20 * time.Millisecond,
+	}, func(ctx context.Context, msg messaging.StreamMessage) error {
+		mu.Lock()
+		defer mu.Unlock()
+		handled = append(handled, msg)
+		switch msg.RoutingKey {
+		case "user.deleted":
+			return messaging.Permanent(errors.New("unsupported"))
+		case "user.updated":
+			return errors.New("downstream unavailable")
+		}
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- consumer.Run(ctx) }()
+
+	// The group only sees entries added after it is created.
+	require.Eventually(t, func() bool {
+		groups, err := rdb.Client.XInfoGroups(context.Background(), "user.events").Result()
+		return err == nil && len(groups) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// Act
+	require.NoError(t, publisher.Publish(context.Background(), "user.created", map[string]string{"user_id": "1"}))
+	require.NoError(t, publisher.Publish(context.Background(), "user.updated", map[string]string{"user_id": "2"}))
+	require.NoError(t, publisher.Publish(context.Background(), "user.deleted", map[string]string{"user_id": "3"}))
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(handled) == 4
+	}, 2*time.Second, 10*time.Millisecond)
+	cancel()
+	require.NoError(t, <-done)
+
+	// Assert
+	assert.JSONEq(t, `{"user_id":"1"}`, string(handled[0].Body))
+	retried := handled[3]
+	assert.Equal(t, "user.updated", retried.RoutingKey)
+	assert.Equal(t, 1, retried.Retries)
+
+	deadLetters, err := rdb.XRead(context.Background(), "user.events.dlq", "0", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, deadLetters, 2)
+	assert.Equal(t, "user.deleted", deadLetters[0].Values[messaging.StreamFieldRoutingKey])
+	assert.Equal(t, "user.updated", deadLetters[1].Values[messaging.StreamFieldRoutingKey])
+
+	pending, err := rdb.Client.XPending(context.Background(), "user.events", "workers").Result()
+	require.NoError(t, err)
+	assert.Zero(t, pending.Count)
+}
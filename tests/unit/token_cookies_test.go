@@ -0,0 +1,149 @@
+package usecase_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	userHttp "github.com/TubagusAldiMY/go-template/internal/domain/user/delivery/http"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/entity"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/usecase"
+	"github.com/TubagusAldiMY/go-template/internal/shared/constants"
+	"github.com/TubagusAldiMY/go-template/pkg/jwt"
+	"github.com/TubagusAldiMY/go-template/pkg/validator"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTokenCookieRouter(t *testing.T, uc *usecase.UserUsecase) *gin.Engine {
+	require.NoError(t, validator.Init())
+	gin.SetMode(gin.TestMode)
+	handler := userHttp.NewUserHandler(uc, userHttp.WithTokenCookies(userHttp.TokenCookies{
+		Delivery: constants.TokenDeliveryJSON,
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+	}))
+
+	router := gin.New()
+	router.POST("/api/v1/auth/login", handler.Login)
+	router.POST("/api/v1/auth/logout", handler.Logout)
+	return router
+}
+
+func cookiesByName(rec *httptest.ResponseRecorder) map[string]*http.Cookie {
+	cookies := make(map[string]*http.Cookie)
+	for _, cookie := range rec.Result().Cookies() {
+		cookies[cookie.Name] = cookie
+	}
+	return cookies
+}
+
+func TestLoginHandler_CookieDelivery(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockHasher := new(MockPasswordHasher)
+	mockJWT := new(MockJWTManager)
+	mockRedis := new(MockRedis)
+	mockSessions := new(MockSessionRepository)
+	router := newTokenCookieRouter(t, usecase.NewUserUsecase(mockRepo, mockHasher, mockJWT, mockRedis, usecase.WithSessionRepository(mockSessions)))
+
+	user := &entity.User{
+		ID:       "user-123",
+		Email:    "test@example.com",
+		Password: "hashedpassword",
+		Role:     "user",
+		Status:   "active",
+	}
+	mockRepo.On("GetByEmail", mock.Anything, user.Email).Return(user, nil)
+	mockHasher.On("IsValid", user.Password, "SecurePass123!").Return(true)
+	mockHasher.On("NeedsRehash", user.Password).Return(false)
+	mockRepo.On("UpdateLastLogin", mock.Anything, user.ID, mock.AnythingOfType("time.Time")).Return(nil)
+	mockJWT.On("GenerateAccessToken", mock.Anything, user.ID, user.Email, user.Role).Return("access-token", nil)
+	mockJWT.On("GenerateRefreshToken", user.ID, mock.Anything, mockRefreshTokenDuration).Return("refresh-token", "refresh-jti", nil)
+	expectSessionSaved(mockSessions, user.ID, "refresh-jti")
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login?token_delivery=cookie",
+		strings.NewReader(`{"email":"test@example.com","password":"SecurePass123!"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(rec, req)
+
+	// Assert
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var body struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	assert.NotContains(t, body.Data, "access_token")
+	assert.NotContains(t, body.Data, "refresh_token")
+
+	cookies := cookiesByName(rec)
+	access := cookies[constants.CookieAccessToken]
+	require.NotNil(t, access)
+	assert.Equal(t, "access-token", access.Value)
+	assert.Equal(t, "/", access.Path)
+	assert.True(t, access.HttpOnly)
+	assert.True(t, access.Secure)
+	assert.Equal(t, http.SameSiteStrictMode, access.SameSite)
+
+	refresh := cookies[constants.CookieRefreshToken]
+	require.NotNil(t, refresh)
+	assert.Equal(t, "refresh-token", refresh.Value)
+	assert.Equal(t, "/api/v1/auth", refresh.Path)
+	assert.Equal(t, int(mockRefreshTokenDuration.Seconds()), refresh.MaxAge)
+}
+
+func TestLoginHandler_RejectsUnknownDelivery(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	router := newTokenCookieRouter(t, usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis)))
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login?token_delivery=header",
+		strings.NewReader(`{"email":"test@example.com","password":"SecurePass123!"}`))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(rec, req)
+
+	// Assert
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	mockRepo.AssertNotCalled(t, "GetByEmail", mock.Anything, mock.Anything)
+}
+
+func TestLogoutHandler_RevokesCookieTokenAndClearsCookies(t *testing.T) {
+	// Arrange
+	mockJWT := new(MockJWTManager)
+	mockSessions := new(MockSessionRepository)
+	router := newTokenCookieRouter(t, usecase.NewUserUsecase(new(MockUserRepository), new(MockPasswordHasher), mockJWT, new(MockRedis),
+		usecase.WithSessionRepository(mockSessions)))
+
+	mockJWT.On("ValidateRefreshToken", "refresh-token").
+		Return(&jwt.RefreshClaims{UserID: "user-123", TokenID: "refresh-jti", SessionID: "session-1", Lifetime: mockRefreshTokenDuration}, nil)
+	mockSessions.On("Get", mock.Anything, "user-123", "session-1").
+		Return(&entity.Session{ID: "session-1", UserID: "user-123", TokenID: "refresh-jti"}, nil)
+	mockSessions.On("Delete", mock.Anything, "user-123", "session-1").Return(nil)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/auth/logout", nil)
+	req.AddCookie(&http.Cookie{Name: constants.CookieRefreshToken, Value: "refresh-token"})
+	rec := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(rec, req)
+
+	// Assert
+	require.Equal(t, http.StatusOK, rec.Code)
+	cookies := cookiesByName(rec)
+	for _, name := range []string{constants.CookieAccessToken, constants.CookieRefreshToken} {
+		require.Contains(t, cookies, name)
+		assert.Empty(t, cookies[name].Value)
+		assert.Negative(t, cookies[name].MaxAge)
+	}
+	mockSessions.AssertExpectations(t)
+}
@@ -0,0 +1,213 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/internal/delivery/http/middleware"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/dto"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/entity"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/repository"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/usecase"
+	sharedErrors "github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/TubagusAldiMY/go-template/pkg/jwt"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWTManager_RejectsTokensOfOlderEpoch(t *testing.T) {
+	// Arrange
+	rdb, _ := newTestRedis(t)
+	epochs := repository.NewRedisTokenEpochRepository(rdb)
+	manager := jwt.NewManager(testJWTSecret, 15*time.Minute, time.Hour, jwt.WithEpochStore(epochs, false))
+	ctx := context.Background()
+
+	oldToken, err := manager.GenerateAccessToken(ctx, "user-123", "test@example.com", "user")
+	require.NoError(t, err)
+	_, err = epochs.Bump(ctx, "user-123")
+	require.NoError(t, err)
+	newToken, err := manager.GenerateAccessToken(ctx, "user-123", "test@example.com", "user")
+	require.NoError(t, err)
+
+	// Act
+	_, oldErr := manager.ValidateAccessToken(ctx, oldToken)
+	claims, newErr := manager.ValidateAccessToken(ctx, newToken)
+
+	// Assert
+	assert.ErrorIs(t, oldErr, jwt.ErrRevokedToken)
+	require.NoError(t, newErr)
+	assert.Equal(t, int64(1), claims.Epoch)
+}
+
+func TestJWTManager_EpochUnavailable(t *testing.T) {
+	tests := []struct {
+		name     string
+		failOpen bool
+		wantErr  error
+	}{
+		{name: "fail closed", failOpen: false, wantErr: jwt.ErrEpochUnavailable},
+		{name: "fail open", failOpen: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			rdb, server := newTestRedis(t)
+			manager := jwt.NewManager(testJWTSecret, 15*time.Minute, time.Hour,
+				jwt.WithEpochStore(repository.NewRedisTokenEpochRepository(rdb), tt.failOpen))
+			token, err := manager.GenerateAccessToken(context.Background(), "user-123", "test@example.com", "user")
+			require.NoError(t, err)
+			server.SetError("LOADING Redis is loading the dataset in memory")
+
+			// Act
+			claims, err := manager.ValidateAccessToken(context.Background(), token)
+
+			// Assert
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "user-123", claims.UserID)
+		})
+	}
+}
+
+func TestAuthMiddleware_EpochUnavailableIsServiceUnavailable(t *testing.T) {
+	// Arrange
+	rdb, server := newTestRedis(t)
+	manager := jwt.NewManager(testJWTSecret, 15*time.Minute, time.Hour,
+		jwt.WithEpochStore(repository.NewRedisTokenEpochRepository(rdb), false))
+	token, err := manager.GenerateAccessToken(context.Background(), "user-123", "test@example.com", "user")
+	require.NoError(t, err)
+	server.SetError("LOADING Redis is loading the dataset in memory")
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/", middleware.AuthMiddleware(manager), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+
+	// Act
+	router.ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestChangePassword_RevokesTokens(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockHasher := new(MockPasswordHasher)
+	mockSessions := new(MockSessionRepository)
+	rdb, server := newTestRedis(t)
+	uc := usecase.NewUserUsecase(mockRepo, mockHasher, new(MockJWTManager), new(MockRedis),
+		usecase.WithSessionRepository(mockSessions),
+		usecase.WithTokenEpochRepository(repository.NewRedisTokenEpochRepository(rdb)),
+	)
+	user := &entity.User{ID: "user-123", Password: "old-hash", Status: "active"}
+	req := &dto.ChangePasswordRequest{OldPassword: "OldPass123!", NewPassword: "NewPass123!"}
+
+	mockRepo.On("GetByID", mock.Anything, "user-123").Return(user, nil)
+	mockHasher.On("IsValid", "old-hash", req.OldPassword).Return(true)
+	mockHasher.On("Hash", req.NewPassword).Return("new-hash", nil)
+	mockRepo.On("Update", mock.Anything, user).Return(nil)
+	mockSessions.On("DeleteAll", mock.Anything, "user-123").Return(nil)
+
+	// Act
+	err := uc.ChangePassword(context.Background(), "user-123", req)
+
+	// Assert
+	require.NoError(t, err)
+	epoch, getErr := server.Get("token_epoch:user-123")
+	require.NoError(t, getErr)
+	assert.Equal(t, "1", epoch)
+	mockSessions.AssertExpectations(t)
+}
+
+func TestChangePassword_RevocationFailureKeepsOldPassword(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockHasher := new(MockPasswordHasher)
+	mockSessions := new(MockSessionRepository)
+	rdb, server := newTestRedis(t)
+	uc := usecase.NewUserUsecase(mockRepo, mockHasher, new(MockJWTManager), new(MockRedis),
+		usecase.WithSessionRepository(mockSessions),
+		usecase.WithTokenEpochRepository(repository.NewRedisTokenEpochRepository(rdb)),
+	)
+	user := &entity.User{ID: "user-123", Password: "old-hash", Status: "active"}
+	req := &dto.ChangePasswordRequest{OldPassword: "OldPass123!", NewPassword: "NewPass123!"}
+	server.SetError("LOADING Redis is loading the dataset in memory")
+
+	mockRepo.On("GetByID", mock.Anything, "user-123").Return(user, nil)
+	mockHasher.On("IsValid", "old-hash", req.OldPassword).Return(true)
+	mockHasher.On("Hash", req.NewPassword).Return("new-hash", nil)
+	mockSessions.On("DeleteAll", mock.Anything, "user-123").Return(errors.New("connection refused"))
+
+	// Act
+	err := uc.ChangePassword(context.Background(), "user-123", req)
+
+	// Assert
+	assert.ErrorIs(t, err, sharedErrors.ErrSignOutFailed)
+	assert.Equal(t, http.StatusServiceUnavailable, sharedErrors.StatusError(err).Status)
+	assert.Equal(t, "old-hash", user.Password)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	mockSessions.AssertExpectations(t)
+}
+
+func TestDeleteOwnAccount_RevokesTokens(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockHasher := new(MockPasswordHasher)
+	rdb, server := newTestRedis(t)
+	uc := usecase.NewUserUsecase(mockRepo, mockHasher, new(MockJWTManager), expectStatsInvalidation(new(MockRedis)),
+		usecase.WithTokenEpochRepository(repository.NewRedisTokenEpochRepository(rdb)),
+	)
+	user := &entity.User{ID: "user-123", Password: "hashed", Status: "active"}
+
+	mockRepo.On("GetByID", mock.Anything, "user-123").Return(user, nil)
+	mockHasher.On("IsValid", "hashed", "Password123!").Return(true)
+	mockRepo.On("Delete", mock.Anything, "user-123").Return(nil)
+
+	// Act
+	err := uc.DeleteOwnAccount(context.Background(), "user-123", &dto.DeleteAccountRequest{Password: "Password123!"})
+
+	// Assert
+	require.NoError(t, err)
+	epoch, getErr := server.Get("token_epoch:user-123")
+	require.NoError(t, getErr)
+	assert.Equal(t, "1", epoch)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestDeleteOwnAccount_RevocationFailureKeepsAccount(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockHasher := new(MockPasswordHasher)
+	mockSessions := new(MockSessionRepository)
+	uc := usecase.NewUserUsecase(mockRepo, mockHasher, new(MockJWTManager), new(MockRedis),
+		usecase.WithSessionRepository(mockSessions),
+	)
+	user := &entity.User{ID: "user-123", Password: "hashed", Status: "active"}
+
+	mockRepo.On("GetByID", mock.Anything, "user-123").Return(user, nil)
+	mockHasher.On("IsValid", "hashed", "Password123!").Return(true)
+	mockSessions.On("DeleteAll", mock.Anything, "user-123").Return(errors.New("connection refused"))
+
+	// Act
+	err := uc.DeleteOwnAccount(context.Background(), "user-123", &dto.DeleteAccountRequest{Password: "Password123!"})
+
+	// Assert
+	assert.ErrorIs(t, err, sharedErrors.ErrSignOutFailed)
+	mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	mockSessions.AssertExpectations(t)
+}
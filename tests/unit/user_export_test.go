@@ -0,0 +1,169 @@
+package usecase_test
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	userHttp "github.com/TubagusAldiMY/go-template/internal/domain/user/delivery/http"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/dto"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/entity"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/repository"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/usecase"
+	sharedErrors "github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newExportHandler(repo *MockUserRepository) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	uc := usecase.NewUserUsecase(repo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis))
+	router := gin.New()
+	router.GET("/users/export.csv", userHttp.NewUserHandler(uc).ExportUsers)
+	return router
+}
+
+func TestExportUsers_AppliesListFilters(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis))
+	users := []*entity.User{
+		{ID: "user-1", Email: "a@example.com", Role: "admin", Status: "active"},
+		{ID: "user-2", Email: "b@example.com", Role: "admin", Status: "active"},
+	}
+	mockRepo.On("Export", mock.Anything, mock.MatchedBy(func(p repository.ListParams) bool {
+		return p.Search == "example" && p.Role == "admin" && p.Status == "active" && p.SortBy == "email"
+	})).Return(users, nil)
+
+	var got []string
+	req := &dto.ListUsersRequest{Search: "example", Role: "admin", Status: "active", SortBy: "email"}
+
+	// Act
+	err := uc.ExportUsers(context.Background(), req, func(user *dto.UserResponse) error {
+		got = append(got, user.ID)
+		return nil
+	})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"user-1", "user-2"}, got)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestExportUsers_RepositoryFailureIsInternal(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis))
+	mockRepo.On("Export", mock.Anything, mock.Anything).Return(nil, errors.New("connection reset"))
+
+	// Act
+	err := uc.ExportUsers(context.Background(), &dto.ListUsersRequest{}, func(*dto.UserResponse) error {
+		return nil
+	})
+
+	// Assert
+	assert.ErrorIs(t, err, sharedErrors.ErrInternal)
+}
+
+func TestExportUsers_CallbackErrorIsReturnedAsIs(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis))
+	mockRepo.On("Export", mock.Anything, mock.Anything).Return([]*entity.User{{ID: "user-1"}}, nil)
+	writeErr := errors.New("broken pipe")
+
+	// Act
+	err := uc.ExportUsers(context.Background(), &dto.ListUsersRequest{}, func(*dto.UserResponse) error {
+		return writeErr
+	})
+
+	// Assert
+	assert.Equal(t, writeErr, err)
+}
+
+func TestExportUsersHandler_StreamsCSV(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	created := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	users := []*entity.User{
+		{ID: "user-1", Email: "a@example.com", Username: "alice", FullName: "Alice, Jr.", Role: "user", Status: "active", CreatedAt: created, UpdatedAt: created},
+		{ID: "user-2", Email: "b@example.com", Username: "bob", FullName: "=HYPERLINK(\"x\")", Role: "user", Status: "active", CreatedAt: created, UpdatedAt: created},
+	}
+	mockRepo.On("Export", mock.Anything, mock.MatchedBy(func(p repository.ListParams) bool {
+		return p.Status == "active"
+	})).Return(users, nil)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/users/export.csv?status=active", nil)
+
+	// Act
+	newExportHandler(mockRepo).ServeHTTP(w, req)
+
+	// Assert
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), `attachment; filename="users-`)
+
+	records, err := csv.NewReader(strings.NewReader(w.Body.String())).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, "id", records[0][0])
+	assert.Equal(t, "Alice, Jr.", records[1][3])
+	assert.Equal(t, `'=HYPERLINK("x")`, records[2][3])
+	assert.Equal(t, "2026-01-02T03:04:05Z", records[1][10])
+}
+
+func TestExportUsersHandler_EmptyExportHasHeaderRow(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockRepo.On("Export", mock.Anything, mock.Anything).Return(nil, nil)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/users/export.csv", nil)
+
+	// Act
+	newExportHandler(mockRepo).ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, strings.HasPrefix(w.Body.String(), "id,email,username,"))
+}
+
+func TestExportUsersHandler_FailureBeforeFirstRowIsJSON(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockRepo.On("Export", mock.Anything, mock.Anything).Return(nil, errors.New("connection refused"))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/users/export.csv", nil)
+
+	// Act
+	newExportHandler(mockRepo).ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+	assert.Empty(t, w.Header().Get("Content-Disposition"))
+}
+
+func TestExportUsersHandler_RejectsInvalidFilter(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/users/export.csv?role=owner", nil)
+
+	// Act
+	newExportHandler(mockRepo).ServeHTTP(w, req)
+
+	// Assert
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	mockRepo.AssertNotCalled(t, "Export", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,429 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/entity"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/repository"
+	"github.com/TubagusAldiMY/go-template/internal/infrastructure/database"
+	sharedErrors "github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// failingTx is a pgx.Tx whose Exec fails with err. Other methods are not
+// implemented and panic if called.
+type failingTx struct {
+	pgx.Tx
+	err error
+}
+
+func (tx failingTx) Exec(context.Context, string, ...any) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, tx.err
+}
+
+// blockingTx is a pgx.Tx whose Exec waits for ctx to end, like a query
+// stuck on a slow server.
+type blockingTx struct {
+	pgx.Tx
+}
+
+func (blockingTx) Exec(ctx context.Context, _ string, _ ...any) (pgconn.CommandTag, error) {
+	<-ctx.Done()
+	return pgconn.CommandTag{}, fmt.Errorf("timeout: %w", ctx.Err())
+}
+
+func TestPostgresUserRepository_CreateMapsUniqueViolations(t *testing.T) {
+	tests := []struct {
+		name       string
+		constraint string
+		want       error
+	}{
+		{name: "email", constraint: "users_email_key", want: sharedErrors.ErrEmailAlreadyExists},
+		{name: "username", constraint: "users_username_key", want: sharedErrors.ErrUsernameAlreadyExists},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			repo := repository.NewPostgresUserRepository(nil, 0)
+			ctx := database.ContextWithTx(context.Background(), failingTx{
+				err: &pgconn.PgError{Code: "23505", ConstraintName: tt.constraint},
+			})
+			user := entity.NewUser("test@example.com", "testuser", "hashed", "Test User", "user")
+
+			// Act
+			err := repo.Create(ctx, user)
+
+			// Assert
+			assert.ErrorIs(t, err, tt.want)
+		})
+	}
+}
+
+func TestPostgresUserRepository_CreateWrapsOtherErrors(t *testing.T) {
+	// Arrange
+	repo := repository.NewPostgresUserRepository(nil, 0)
+	ctx := database.ContextWithTx(context.Background(), failingTx{
+		err: &pgconn.PgError{Code: "23502", ColumnName: "email"},
+	})
+	user := entity.NewUser("test@example.com", "testuser", "hashed", "Test User", "user")
+
+	// Act
+	err := repo.Create(ctx, user)
+
+	// Assert
+	assert.ErrorContains(t, err, "failed to create user")
+	assert.NotErrorIs(t, err, sharedErrors.ErrEmailAlreadyExists)
+}
+
+func TestPostgresUserRepository_AppliesQueryTimeout(t *testing.T) {
+	// Arrange
+	repo := repository.NewPostgresUserRepository(nil, 10*time.Millisecond)
+	ctx := database.ContextWithTx(context.Background(), blockingTx{})
+
+	// Act
+	err := repo.Delete(ctx, "user-123")
+
+	// Assert
+	assert.ErrorIs(t, err, sharedErrors.ErrTimeout)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestPostgresUserRepository_ReturnsCancellation(t *testing.T) {
+	// Arrange
+	repo := repository.NewPostgresUserRepository(nil, 0)
+	ctx := database.ContextWithTx(context.Background(), failingTx{
+		err: fmt.Errorf("context already done: %w", context.Canceled),
+	})
+
+	// Act
+	err := repo.Restore(ctx, "user-123")
+
+	// Assert
+	assert.Equal(t, context.Canceled, err)
+	assert.NotErrorIs(t, err, sharedErrors.ErrTimeout)
+}
+
+// recordingTx is a pgx.Tx that records the statements it is given. QueryRow
+// scans zeros and Query fails with errQueryRecorded; other methods are not
+// implemented and panic if called.
+type recordingTx struct {
+	pgx.Tx
+	queries []string
+	args    [][]any
+}
+
+var errQueryRecorded = errors.New("query recorded")
+
+type zeroRow struct{}
+
+func (zeroRow) Scan(dest ...any) error {
+	for _, d := range dest {
+		if n, ok := d.(*int64); ok {
+			*n = 0
+		}
+	}
+	return nil
+}
+
+func (tx *recordingTx) QueryRow(_ context.Context, sql string, args ...any) pgx.Row {
+	tx.queries = append(tx.queries, sql)
+	tx.args = append(tx.args, args)
+	return zeroRow{}
+}
+
+func (tx *recordingTx) Query(_ context.Context, sql string, args ...any) (pgx.Rows, error) {
+	tx.queries = append(tx.queries, sql)
+	tx.args = append(tx.args, args)
+	return nil, errQueryRecorded
+}
+
+func TestPostgresUserRepository_ListFuzzySearchRanksBySimilarity(t *testing.T) {
+	// Arrange
+	repo := repository.NewPostgresUserRepository(nil, 0)
+	tx := &recordingTx{}
+	ctx := database.ContextWithTx(context.Background(), tx)
+
+	// Act
+	_, _, err := repo.List(ctx, repository.ListParams{
+		Page:       1,
+		PageSize:   20,
+		Search:     "jonh",
+		SearchMode: repository.SearchModeFuzzy,
+		Status:     "active",
+		SortBy:     "email",
+	})
+
+	// Assert
+	require.ErrorIs(t, err, errQueryRecorded)
+	require.Len(t, tx.queries, 2)
+	assert.Contains(t, tx.queries[0], "similarity(email, $1)")
+	assert.NotContains(t, tx.queries[0], "ILIKE")
+	assert.Equal(t, []any{"jonh", repository.DefaultSimilarityThreshold, "active"}, tx.args[0])
+	assert.Contains(t, tx.queries[1], "similarity(full_name, $1)) DESC, created_at DESC, id DESC")
+	assert.NotContains(t, tx.queries[1], "email ASC")
+}
+
+func TestPostgresUserRepository_ListExactSearchByDefault(t *testing.T) {
+	// Arrange
+	repo := repository.NewPostgresUserRepository(nil, 0)
+	tx := &recordingTx{}
+	ctx := database.ContextWithTx(context.Background(), tx)
+
+	// Act
+	_, _, err := repo.List(ctx, repository.ListParams{Page: 1, PageSize: 20, Search: "john"})
+
+	// Assert
+	require.ErrorIs(t, err, errQueryRecorded)
+	assert.Contains(t, tx.queries[0], "email ILIKE $1")
+	assert.NotContains(t, tx.queries[0], "similarity")
+	assert.Equal(t, []any{"%john%"}, tx.args[0])
+}
+
+func TestPostgresUserRepository_ListOrder(t *testing.T) {
+	tests := []struct {
+		name      string
+		sortBy    string
+		sortOrder string
+		want      string
+	}{
+		{name: "defaults to newest first", want: "ORDER BY created_at DESC, id DESC"},
+		{name: "ascending without sort_by", sortOrder: "asc", want: "ORDER BY created_at ASC, id ASC"},
+		{name: "column without sort_order", sortBy: "email", want: "ORDER BY email DESC, id DESC"},
+		{name: "column and direction", sortBy: "username", sortOrder: "ASC", want: "ORDER BY username ASC, id ASC"},
+		{name: "last update", sortBy: "updated_at", want: "ORDER BY updated_at DESC, id DESC"},
+		{name: "unknown column", sortBy: "password", sortOrder: "asc", want: "ORDER BY created_at ASC, id ASC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			repo := repository.NewPostgresUserRepository(nil, 0)
+			tx := &recordingTx{}
+			ctx := database.ContextWithTx(context.Background(), tx)
+
+			// Act
+			_, _, err := repo.List(ctx, repository.ListParams{Page: 1, PageSize: 20, SortBy: tt.sortBy, SortOrder: tt.sortOrder})
+
+			// Assert
+			require.ErrorIs(t, err, errQueryRecorded)
+			require.Len(t, tx.queries, 2)
+			assert.Contains(t, tx.queries[1], tt.want)
+		})
+	}
+}
+
+func TestPostgresUserRepository_EncryptedListRejectsPlaintextQueries(t *testing.T) {
+	tests := []struct {
+		name   string
+		params repository.ListParams
+	}{
+		{name: "exact search", params: repository.ListParams{Search: "john"}},
+		{name: "fuzzy search", params: repository.ListParams{Search: "jonh", SearchMode: repository.SearchModeFuzzy}},
+		{name: "sort by email", params: repository.ListParams{SortBy: "email"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			repo := repository.NewPostgresUserRepository(nil, 0, repository.WithFieldEncryption(newFieldCipher(t)))
+			tx := &recordingTx{}
+			ctx := database.ContextWithTx(context.Background(), tx)
+			tt.params.Page, tt.params.PageSize = 1, 20
+
+			// Act
+			_, _, listErr := repo.List(ctx, tt.params)
+			_, _, cursorErr := repo.ListByCursor(ctx, tt.params, "")
+			exportErr := repo.Export(ctx, tt.params, func(*entity.User) error { return nil })
+
+			// Assert
+			assert.ErrorIs(t, listErr, sharedErrors.ErrInvalidInput)
+			assert.ErrorIs(t, cursorErr, sharedErrors.ErrInvalidInput)
+			assert.ErrorIs(t, exportErr, sharedErrors.ErrInvalidInput)
+			assert.Empty(t, tx.queries)
+		})
+	}
+}
+
+func TestPostgresUserRepository_EncryptedListSortsByOtherColumns(t *testing.T) {
+	// Arrange
+	repo := repository.NewPostgresUserRepository(nil, 0, repository.WithFieldEncryption(newFieldCipher(t)))
+	tx := &recordingTx{}
+	ctx := database.ContextWithTx(context.Background(), tx)
+
+	// Act
+	_, _, err := repo.List(ctx, repository.ListParams{Page: 1, PageSize: 20, SortBy: "username"})
+
+	// Assert
+	require.ErrorIs(t, err, errQueryRecorded)
+	assert.Contains(t, tx.queries[1], "ORDER BY username DESC, id DESC")
+}
+
+func TestPostgresUserRepository_EmailIndexIgnoresCase(t *testing.T) {
+	// Arrange
+	fields := newFieldCipher(t)
+	repo := repository.NewPostgresUserRepository(nil, 0, repository.WithFieldEncryption(fields))
+	tx := &recordingTx{}
+	ctx := database.ContextWithTx(context.Background(), tx)
+
+	// Act
+	_, err := repo.ExistsByEmail(ctx, " John@Example.com")
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, tx.args, 1)
+	assert.Contains(t, tx.args[0], fields.BlindIndex("john@example.com"))
+}
+
+func TestPostgresUserRepository_ListFiltersByCreationRange(t *testing.T) {
+	// Arrange
+	repo := repository.NewPostgresUserRepository(nil, 0)
+	tx := &recordingTx{}
+	ctx := database.ContextWithTx(context.Background(), tx)
+	after := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	before := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	// Act
+	_, _, err := repo.List(ctx, repository.ListParams{
+		Page:          2,
+		PageSize:      20,
+		Role:          "user",
+		CreatedAfter:  after,
+		CreatedBefore: before,
+	})
+
+	// Assert
+	require.ErrorIs(t, err, errQueryRecorded)
+	require.Len(t, tx.queries, 2)
+	assert.Contains(t, tx.queries[0], "WHERE deleted_at IS NULL AND role = $1 AND created_at > $2 AND created_at < $3")
+	assert.Equal(t, []any{"user", after, before}, tx.args[0])
+	assert.Contains(t, tx.queries[1], "LIMIT $4 OFFSET $5")
+	assert.Equal(t, []any{"user", after, before, 20, 20}, tx.args[1])
+}
+
+func TestPostgresUserRepository_ListFiltersInactiveSince(t *testing.T) {
+	// Arrange
+	repo := repository.NewPostgresUserRepository(nil, 0)
+	tx := &recordingTx{}
+	ctx := database.ContextWithTx(context.Background(), tx)
+	since := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// Act
+	_, _, err := repo.List(ctx, repository.ListParams{Page: 1, PageSize: 20, Status: "active", InactiveSince: since})
+
+	// Assert
+	require.ErrorIs(t, err, errQueryRecorded)
+	assert.Contains(t, tx.queries[0], "status = $1 AND (last_login_at IS NULL OR last_login_at < $2)")
+	assert.Equal(t, []any{"active", since}, tx.args[0])
+}
+
+func TestPostgresUserRepository_CountByStatusSkipsDeletedUsers(t *testing.T) {
+	// Arrange
+	repo := repository.NewPostgresUserRepository(nil, 0)
+	tx := &recordingTx{}
+	ctx := database.ContextWithTx(context.Background(), tx)
+
+	// Act
+	_, err := repo.CountByStatus(ctx)
+
+	// Assert
+	require.ErrorIs(t, err, errQueryRecorded)
+	assert.Contains(t, tx.queries[0], "deleted_at IS NULL")
+	assert.Contains(t, tx.queries[0], "GROUP BY status")
+}
+
+func TestPostgresUserRepository_UniquenessChecksIgnoreDeletedUsers(t *testing.T) {
+	// Arrange
+	repo := repository.NewPostgresUserRepository(nil, 0)
+	tx := &recordingTx{}
+	ctx := database.ContextWithTx(context.Background(), tx)
+
+	// Act
+	_, emailErr := repo.ExistsByEmail(ctx, "test@example.com")
+	_, usernameErr := repo.ExistsByUsername(ctx, "testuser")
+
+	// Assert
+	require.NoError(t, emailErr)
+	require.NoError(t, usernameErr)
+	require.Len(t, tx.queries, 2)
+	assert.Contains(t, tx.queries[0], "WHERE deleted_at IS NULL AND email = $1")
+	assert.Contains(t, tx.queries[1], "WHERE deleted_at IS NULL AND username = $1")
+}
+
+func TestPostgresUserRepository_RestoreMapsTakenEmailToConflict(t *testing.T) {
+	// Arrange
+	repo := repository.NewPostgresUserRepository(nil, 0)
+	ctx := database.ContextWithTx(context.Background(), failingTx{
+		err: &pgconn.PgError{Code: "23505", ConstraintName: "users_email_key"},
+	})
+
+	// Act
+	err := repo.Restore(ctx, "user-123")
+
+	// Assert
+	assert.ErrorIs(t, err, sharedErrors.ErrEmailAlreadyExists)
+}
+
+func TestPostgresUserRepository_ReadsFromReadPool(t *testing.T) {
+	// Arrange
+	primary, err := pgxpool.New(context.Background(), "host=127.0.0.1 port=1 user=app dbname=app connect_timeout=1")
+	require.NoError(t, err)
+	t.Cleanup(primary.Close)
+	replica, err := pgxpool.New(context.Background(), "host=127.0.0.1 port=2 user=app dbname=app connect_timeout=1")
+	require.NoError(t, err)
+	t.Cleanup(replica.Close)
+	repo := repository.NewPostgresUserRepository(primary, 0, repository.WithReadPool(replica))
+
+	// Act
+	_, readErr := repo.GetByID(context.Background(), "user-123")
+	writeErr := repo.Delete(context.Background(), "user-123")
+
+	// Assert
+	require.Error(t, readErr)
+	assert.Contains(t, readErr.Error(), "127.0.0.1:2")
+	require.Error(t, writeErr)
+	assert.Contains(t, writeErr.Error(), "127.0.0.1:1")
+}
+
+func TestPostgresUserRepository_PrimaryRequestedReadsFromPrimary(t *testing.T) {
+	// Arrange
+	primary, err := pgxpool.New(context.Background(), "host=127.0.0.1 port=1 user=app dbname=app connect_timeout=1")
+	require.NoError(t, err)
+	t.Cleanup(primary.Close)
+	replica, err := pgxpool.New(context.Background(), "host=127.0.0.1 port=2 user=app dbname=app connect_timeout=1")
+	require.NoError(t, err)
+	t.Cleanup(replica.Close)
+	repo := repository.NewPostgresUserRepository(primary, 0, repository.WithReadPool(replica))
+
+	// Act
+	_, err = repo.GetByID(database.ContextWithPrimary(context.Background()), "user-123")
+
+	// Assert
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "127.0.0.1:1")
+}
+
+func TestPostgresUserRepository_ReadsInsideTransactionUseIt(t *testing.T) {
+	// Arrange
+	replica, err := pgxpool.New(context.Background(), "host=127.0.0.1 port=2 user=app dbname=app connect_timeout=1")
+	require.NoError(t, err)
+	t.Cleanup(replica.Close)
+	repo := repository.NewPostgresUserRepository(nil, 0, repository.WithReadPool(replica))
+	tx := &recordingTx{}
+	ctx := database.ContextWithTx(context.Background(), tx)
+
+	// Act
+	_, err = repo.ExistsByUsername(ctx, "testuser")
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, tx.queries, 1)
+}
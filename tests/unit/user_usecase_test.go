@@ -2,16 +2,23 @@ package usecase_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/TubagusAldiMY/go-template/internal/domain/user/dto"
 	"github.com/TubagusAldiMY/go-template/internal/domain/user/entity"
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/repository"
 	"github.com/TubagusAldiMY/go-template/internal/domain/user/usecase"
+	"github.com/TubagusAldiMY/go-template/internal/shared/constants"
 	sharedErrors "github.com/TubagusAldiMY/go-template/internal/shared/errors"
+	"github.com/TubagusAldiMY/go-template/pkg/jwt"
+	"github.com/TubagusAldiMY/go-template/pkg/validator"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockUserRepository is a mock implementation of UserRepository
@@ -24,6 +31,11 @@ func (m *MockUserRepository) Create(ctx context.Context, user *entity.User) erro
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) CreateBatch(ctx context.Context, users []*entity.User) error {
+	args := m.Called(ctx, users)
+	return args.Error(0)
+}
+
 func (m *MockUserRepository) GetByID(ctx context.Context, id string) (*entity.User, error) {
 	args := m.Called(ctx, id)
 	if args.Get(0) == nil {
@@ -32,6 +44,14 @@ func (m *MockUserRepository) GetByID(ctx context.Context, id string) (*entity.Us
 	return args.Get(0).(*entity.User), args.Error(1)
 }
 
+func (m *MockUserRepository) GetByIDIncludingDeleted(ctx context.Context, id string) (*entity.User, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.User), args.Error(1)
+}
+
 func (m *MockUserRepository) GetByEmail(ctx context.Context, email string) (*entity.User, error) {
 	args := m.Called(ctx, email)
 	if args.Get(0) == nil {
@@ -53,19 +73,55 @@ func (m *MockUserRepository) Update(ctx context.Context, user *entity.User) erro
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) UpdateLastLogin(ctx context.Context, id string, t time.Time) error {
+	args := m.Called(ctx, id, t)
+	return args.Error(0)
+}
+
 func (m *MockUserRepository) Delete(ctx context.Context, id string) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
-func (m *MockUserRepository) List(ctx context.Context, page, pageSize int, search, role, status string) ([]*entity.User, int64, error) {
-	args := m.Called(ctx, page, pageSize, search, role, status)
+func (m *MockUserRepository) Restore(ctx context.Context, id string) error {
+	args := m.Called(ctx, id)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) PurgeDeleted(ctx context.Context, cutoff time.Time, limit int) (int64, error) {
+	args := m.Called(ctx, cutoff, limit)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockUserRepository) List(ctx context.Context, params repository.ListParams) ([]*entity.User, int64, error) {
+	args := m.Called(ctx, params)
 	if args.Get(0) == nil {
 		return nil, args.Get(1).(int64), args.Error(2)
 	}
 	return args.Get(0).([]*entity.User), args.Get(1).(int64), args.Error(2)
 }
 
+func (m *MockUserRepository) ListByCursor(ctx context.Context, params repository.ListParams, cursor string) ([]*entity.User, string, error) {
+	args := m.Called(ctx, params, cursor)
+	if args.Get(0) == nil {
+		return nil, args.String(1), args.Error(2)
+	}
+	return args.Get(0).([]*entity.User), args.String(1), args.Error(2)
+}
+
+// Export feeds the users given to Return to fn, then returns the error.
+func (m *MockUserRepository) Export(ctx context.Context, params repository.ListParams, fn func(user *entity.User) error) error {
+	args := m.Called(ctx, params)
+	if users, ok := args.Get(0).([]*entity.User); ok {
+		for _, user := range users {
+			if err := fn(user); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
 func (m *MockUserRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
 	args := m.Called(ctx, email)
 	return args.Bool(0), args.Error(1)
@@ -76,6 +132,14 @@ func (m *MockUserRepository) ExistsByUsername(ctx context.Context, username stri
 	return args.Bool(0), args.Error(1)
 }
 
+func (m *MockUserRepository) CountByStatus(ctx context.Context) (map[string]int64, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int64), args.Error(1)
+}
+
 // MockPasswordHasher is a mock implementation of PasswordHasher
 type MockPasswordHasher struct {
 	mock.Mock
@@ -96,21 +160,86 @@ func (m *MockPasswordHasher) IsValid(hashedPassword, password string) bool {
 	return args.Bool(0)
 }
 
+func (m *MockPasswordHasher) NeedsRehash(hashedPassword string) bool {
+	args := m.Called(hashedPassword)
+	return args.Bool(0)
+}
+
+// MockAuditLogger is a mock implementation of AuditLogger
+type MockAuditLogger struct {
+	mock.Mock
+}
+
+func (m *MockAuditLogger) Record(ctx context.Context, action, targetID string, metadata map[string]interface{}) error {
+	args := m.Called(ctx, action, targetID, metadata)
+	return args.Error(0)
+}
+
+// MockEventPublisher is a mock implementation of EventPublisher
+type MockEventPublisher struct {
+	mock.Mock
+}
+
+func (m *MockEventPublisher) Publish(ctx context.Context, routingKey string, event interface{}) error {
+	args := m.Called(ctx, routingKey, event)
+	return args.Error(0)
+}
+
 // MockJWTManager is a mock implementation of JWTManager
 type MockJWTManager struct {
 	mock.Mock
 }
 
-func (m *MockJWTManager) GenerateAccessToken(userID, email, role string) (string, error) {
-	args := m.Called(userID, email, role)
+func (m *MockJWTManager) GenerateAccessToken(ctx context.Context, userID, email, role string) (string, error) {
+	args := m.Called(ctx, userID, email, role)
 	return args.String(0), args.Error(1)
 }
 
-func (m *MockJWTManager) GenerateRefreshToken(userID string) (string, error) {
-	args := m.Called(userID)
+func (m *MockJWTManager) GenerateRefreshToken(userID, sessionID string, duration time.Duration) (string, string, error) {
+	args := m.Called(userID, sessionID, duration)
+	return args.String(0), args.String(1), args.Error(2)
+}
+
+func (m *MockJWTManager) GenerateImpersonationToken(ctx context.Context, userID, email, role, actorID string) (string, error) {
+	args := m.Called(ctx, userID, email, role, actorID)
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockJWTManager) ValidateAccessToken(ctx context.Context, tokenString string) (*jwt.Claims, error) {
+	args := m.Called(ctx, tokenString)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*jwt.Claims), args.Error(1)
+}
+
+func (m *MockJWTManager) ValidateRefreshToken(tokenString string) (*jwt.RefreshClaims, error) {
+	args := m.Called(tokenString)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*jwt.RefreshClaims), args.Error(1)
+}
+
+// Token lifetimes reported by MockJWTManager.
+const (
+	mockRefreshTokenDuration           = 7 * 24 * time.Hour
+	mockRememberMeRefreshTokenDuration = 30 * 24 * time.Hour
+	mockImpersonationTokenDuration     = 5 * time.Minute
+)
+
+func (m *MockJWTManager) RefreshTokenDuration() time.Duration {
+	return mockRefreshTokenDuration
+}
+
+func (m *MockJWTManager) RememberMeRefreshTokenDuration() time.Duration {
+	return mockRememberMeRefreshTokenDuration
+}
+
+func (m *MockJWTManager) ImpersonationTokenDuration() time.Duration {
+	return mockImpersonationTokenDuration
+}
+
 // MockRedis is a mock implementation of Redis
 type MockRedis struct {
 	mock.Mock
@@ -131,12 +260,77 @@ func (m *MockRedis) Delete(ctx context.Context, keys ...string) error {
 	return args.Error(0)
 }
 
+type MockSessionRepository struct {
+	mock.Mock
+}
+
+func (m *MockSessionRepository) Save(ctx context.Context, session *entity.Session) error {
+	args := m.Called(ctx, session)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) Rotate(ctx context.Context, session *entity.Session, previousTokenID string) error {
+	args := m.Called(ctx, session, previousTokenID)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) Get(ctx context.Context, userID, sessionID string) (*entity.Session, error) {
+	args := m.Called(ctx, userID, sessionID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*entity.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) List(ctx context.Context, userID string) ([]*entity.Session, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*entity.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) Delete(ctx context.Context, userID, sessionID string) error {
+	args := m.Called(ctx, userID, sessionID)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) DeleteAll(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+// expectSessionSaved accepts saving a session of userID whose current
+// refresh token is tokenID.
+func expectSessionSaved(m *MockSessionRepository, userID, tokenID string) *MockSessionRepository {
+	m.On("Save", mock.Anything, mock.MatchedBy(func(session *entity.Session) bool {
+		return session.UserID == userID && session.TokenID == tokenID
+	})).Return(nil)
+	return m
+}
+
+// expectSessionRotated accepts rotating a session of userID from the refresh
+// token previousTokenID to tokenID.
+func expectSessionRotated(m *MockSessionRepository, userID, previousTokenID, tokenID string) *MockSessionRepository {
+	m.On("Rotate", mock.Anything, mock.MatchedBy(func(session *entity.Session) bool {
+		return session.UserID == userID && session.TokenID == tokenID
+	}), previousTokenID).Return(nil)
+	return m
+}
+
+// expectStatsInvalidation accepts the user stats invalidation that follows
+// every change to the user counts.
+func expectStatsInvalidation(m *MockRedis) *MockRedis {
+	m.On("Delete", mock.Anything, []string{constants.CacheKeyUserStats}).Return(nil)
+	return m
+}
+
 func TestRegister_Success(t *testing.T) {
 	// Arrange
 	mockRepo := new(MockUserRepository)
 	mockHasher := new(MockPasswordHasher)
 	mockJWT := new(MockJWTManager)
-	mockRedis := new(MockRedis)
+	mockRedis := expectStatsInvalidation(new(MockRedis))
 
 	uc := usecase.NewUserUsecase(mockRepo, mockHasher, mockJWT, mockRedis)
 
@@ -166,6 +360,68 @@ func TestRegister_Success(t *testing.T) {
 	mockHasher.AssertExpectations(t)
 }
 
+func TestRegister_NormalizesEmail(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockHasher := new(MockPasswordHasher)
+	uc := usecase.NewUserUsecase(mockRepo, mockHasher, new(MockJWTManager), expectStatsInvalidation(new(MockRedis)))
+
+	req := &dto.RegisterRequest{
+		Email:    " Test@Example.COM",
+		Username: "testuser",
+		Password: "SecurePass123!",
+	}
+
+	mockRepo.On("ExistsByEmail", mock.Anything, "test@example.com").Return(false, nil)
+	mockRepo.On("ExistsByUsername", mock.Anything, req.Username).Return(false, nil)
+	mockHasher.On("Hash", req.Password).Return("hashedpassword", nil)
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(user *entity.User) bool {
+		return user.Email == "test@example.com"
+	})).Return(nil)
+
+	// Act
+	result, err := uc.Register(context.Background(), req)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "test@example.com", result.Email)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestRegister_ReusesEmailOfDeletedUser(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockHasher := new(MockPasswordHasher)
+	uc := usecase.NewUserUsecase(mockRepo, mockHasher, new(MockJWTManager), expectStatsInvalidation(new(MockRedis)))
+
+	deletedAt := time.Now().Add(-time.Hour)
+	deleted := entity.NewUser("test@example.com", "testuser", "hashed", "Old Account", "user")
+	deleted.DeletedAt = &deletedAt
+	req := &dto.RegisterRequest{
+		Email:    deleted.Email,
+		Username: deleted.Username,
+		Password: "SecurePass123!",
+		FullName: "New Account",
+	}
+
+	// Uniqueness only counts users that are not deleted.
+	mockRepo.On("ExistsByEmail", mock.Anything, req.Email).Return(false, nil)
+	mockRepo.On("ExistsByUsername", mock.Anything, req.Username).Return(false, nil)
+	mockHasher.On("Hash", req.Password).Return("hashedpassword", nil)
+	mockRepo.On("Create", mock.Anything, mock.MatchedBy(func(u *entity.User) bool {
+		return u.Email == deleted.Email && u.ID != deleted.ID && u.DeletedAt == nil
+	})).Return(nil)
+
+	// Act
+	result, err := uc.Register(context.Background(), req)
+
+	// Assert
+	require.NoError(t, err)
+	assert.NotEqual(t, deleted.ID, result.ID)
+	assert.Equal(t, "New Account", result.FullName)
+	mockRepo.AssertExpectations(t)
+}
+
 func TestRegister_EmailAlreadyExists(t *testing.T) {
 	// Arrange
 	mockRepo := new(MockUserRepository)
@@ -195,8 +451,84 @@ func TestRegister_EmailAlreadyExists(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
-func TestLogin_Success(t *testing.T) {
+func bulkCreateRequest(strict bool) *dto.BulkCreateUsersRequest {
+	return &dto.BulkCreateUsersRequest{
+		Strict: strict,
+		Users: []dto.RegisterRequest{
+			{Email: "alice@example.com", Username: "alice", Password: "SecurePass123!", FullName: "Alice"},
+			{Email: "not-an-email", Username: "bob", Password: "SecurePass123!", FullName: "Bob"},
+			{Email: "ALICE@example.com", Username: "alice2", Password: "SecurePass123!", FullName: "Alice Again"},
+			{Email: "carol@example.com", Username: "carol", Password: "SecurePass123!", FullName: "Carol"},
+		},
+	}
+}
+
+func TestRegister_ConcurrentDuplicateIsConflict(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockHasher := new(MockPasswordHasher)
+	uc := usecase.NewUserUsecase(mockRepo, mockHasher, new(MockJWTManager), new(MockRedis))
+
+	req := &dto.RegisterRequest{
+		Email:    "test@example.com",
+		Username: "testuser",
+		Password: "SecurePass123!",
+		FullName: "Test User",
+	}
+
+	// Both existence checks pass, but another registration commits first.
+	mockRepo.On("ExistsByEmail", mock.Anything, req.Email).Return(false, nil)
+	mockRepo.On("ExistsByUsername", mock.Anything, req.Username).Return(false, nil)
+	mockHasher.On("Hash", req.Password).Return("hashedpassword", nil)
+	mockRepo.On("Create", mock.Anything, mock.Anything).Return(sharedErrors.ErrEmailAlreadyExists)
+
+	// Act
+	result, err := uc.Register(context.Background(), req)
+
+	// Assert
+	assert.Nil(t, result)
+	assert.True(t, errors.Is(err, sharedErrors.ErrEmailAlreadyExists))
+}
+
+func TestBulkCreate_LenientReportsFailedRows(t *testing.T) {
+	// Arrange
+	require.NoError(t, validator.Init())
+
+	mockRepo := new(MockUserRepository)
+	mockHasher := new(MockPasswordHasher)
+	mockJWT := new(MockJWTManager)
+	mockRedis := expectStatsInvalidation(new(MockRedis))
+
+	uc := usecase.NewUserUsecase(mockRepo, mockHasher, mockJWT, mockRedis)
+
+	mockRepo.On("ExistsByEmail", mock.Anything, "alice@example.com").Return(false, nil)
+	mockRepo.On("ExistsByUsername", mock.Anything, "alice").Return(false, nil)
+	mockRepo.On("ExistsByEmail", mock.Anything, "carol@example.com").Return(true, nil)
+	mockHasher.On("Hash", "SecurePass123!").Return("hashedpassword", nil)
+	mockRepo.On("CreateBatch", mock.Anything, mock.MatchedBy(func(users []*entity.User) bool {
+		return len(users) == 1 && users[0].Email == "alice@example.com"
+	})).Return(nil)
+
+	// Act
+	result, err := uc.BulkCreate(context.Background(), bulkCreateRequest(false))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Created)
+	assert.Equal(t, 3, result.Failed)
+	assert.True(t, result.Results[0].Success)
+	assert.NotNil(t, result.Results[0].User)
+	assert.Contains(t, result.Results[1].Errors, "email")
+	assert.Equal(t, sharedErrors.ErrEmailAlreadyExists.Error(), result.Results[2].Error)
+	assert.Equal(t, sharedErrors.ErrEmailAlreadyExists.Error(), result.Results[3].Error)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestBulkCreate_StrictRejectsBatch(t *testing.T) {
 	// Arrange
+	require.NoError(t, validator.Init())
+
 	mockRepo := new(MockUserRepository)
 	mockHasher := new(MockPasswordHasher)
 	mockJWT := new(MockJWTManager)
@@ -204,6 +536,84 @@ func TestLogin_Success(t *testing.T) {
 
 	uc := usecase.NewUserUsecase(mockRepo, mockHasher, mockJWT, mockRedis)
 
+	mockRepo.On("ExistsByEmail", mock.Anything, mock.Anything).Return(false, nil)
+	mockRepo.On("ExistsByUsername", mock.Anything, mock.Anything).Return(false, nil)
+	mockHasher.On("Hash", "SecurePass123!").Return("hashedpassword", nil)
+
+	// Act
+	result, err := uc.BulkCreate(context.Background(), bulkCreateRequest(true))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.Created)
+	assert.Equal(t, 2, result.Failed)
+	assert.False(t, result.Results[0].Success)
+	mockRepo.AssertNotCalled(t, "CreateBatch", mock.Anything, mock.Anything)
+}
+
+func TestBulkCreate_CreatesFullBatchInOrder(t *testing.T) {
+	// Arrange
+	require.NoError(t, validator.Init())
+
+	mockRepo := new(MockUserRepository)
+	mockHasher := new(MockPasswordHasher)
+	mockRedis := expectStatsInvalidation(new(MockRedis))
+	uc := usecase.NewUserUsecase(mockRepo, mockHasher, new(MockJWTManager), mockRedis)
+
+	req := &dto.BulkCreateUsersRequest{Users: make([]dto.RegisterRequest, 50)}
+	for i := range req.Users {
+		req.Users[i] = dto.RegisterRequest{
+			Email:    fmt.Sprintf("user%d@example.com", i),
+			Username: fmt.Sprintf("user%d", i),
+			Password: fmt.Sprintf("SecurePass%d!", i),
+			FullName: "Bulk User",
+		}
+		mockHasher.On("Hash", req.Users[i].Password).Return("hashed-"+req.Users[i].Username, nil)
+	}
+	require.NoError(t, validator.Validate(req))
+	mockRepo.On("ExistsByEmail", mock.Anything, mock.Anything).Return(false, nil)
+	mockRepo.On("ExistsByUsername", mock.Anything, mock.Anything).Return(false, nil)
+	var created []*entity.User
+	mockRepo.On("CreateBatch", mock.Anything, mock.Anything).
+		Run(func(args mock.Arguments) { created = args.Get(1).([]*entity.User) }).
+		Return(nil)
+
+	// Act
+	result, err := uc.BulkCreate(context.Background(), req)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 50, result.Created)
+	require.Len(t, created, 50)
+	for i, user := range created {
+		assert.Equal(t, req.Users[i].Email, user.Email)
+		assert.Equal(t, "hashed-"+req.Users[i].Username, user.Password)
+	}
+}
+
+func TestBulkCreateUsersRequest_RejectsMoreThanFiftyUsers(t *testing.T) {
+	// Arrange
+	require.NoError(t, validator.Init())
+	req := &dto.BulkCreateUsersRequest{Users: make([]dto.RegisterRequest, 51)}
+
+	// Act
+	err := validator.Validate(req)
+
+	// Assert
+	require.Error(t, err)
+	assert.Contains(t, validator.FormatValidationErrors(err), "users")
+}
+
+func TestLogin_Success(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockHasher := new(MockPasswordHasher)
+	mockJWT := new(MockJWTManager)
+	mockRedis := new(MockRedis)
+
+	mockSessions := new(MockSessionRepository)
+	uc := usecase.NewUserUsecase(mockRepo, mockHasher, mockJWT, mockRedis, usecase.WithSessionRepository(mockSessions))
+
 	req := &dto.LoginRequest{
 		Email:    "test@example.com",
 		Password: "SecurePass123!",
@@ -221,8 +631,11 @@ func TestLogin_Success(t *testing.T) {
 
 	mockRepo.On("GetByEmail", mock.Anything, req.Email).Return(user, nil)
 	mockHasher.On("IsValid", user.Password, req.Password).Return(true)
-	mockJWT.On("GenerateAccessToken", user.ID, user.Email, user.Role).Return("access-token", nil)
-	mockJWT.On("GenerateRefreshToken", user.ID).Return("refresh-token", nil)
+	mockHasher.On("NeedsRehash", user.Password).Return(false)
+	mockRepo.On("UpdateLastLogin", mock.Anything, user.ID, mock.AnythingOfType("time.Time")).Return(nil)
+	mockJWT.On("GenerateAccessToken", mock.Anything, user.ID, user.Email, user.Role).Return("access-token", nil)
+	mockJWT.On("GenerateRefreshToken", user.ID, mock.Anything, mockRefreshTokenDuration).Return("refresh-token", "refresh-jti", nil)
+	expectSessionSaved(mockSessions, user.ID, "refresh-jti")
 
 	// Act
 	result, err := uc.Login(context.Background(), req)
@@ -237,40 +650,912 @@ func TestLogin_Success(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 	mockHasher.AssertExpectations(t)
 	mockJWT.AssertExpectations(t)
+	mockSessions.AssertExpectations(t)
 }
 
-func TestLogin_InvalidCredentials(t *testing.T) {
+func TestLogin_WithUsername(t *testing.T) {
 	// Arrange
 	mockRepo := new(MockUserRepository)
 	mockHasher := new(MockPasswordHasher)
 	mockJWT := new(MockJWTManager)
 	mockRedis := new(MockRedis)
 
-	uc := usecase.NewUserUsecase(mockRepo, mockHasher, mockJWT, mockRedis)
+	mockSessions := new(MockSessionRepository)
+	uc := usecase.NewUserUsecase(mockRepo, mockHasher, mockJWT, mockRedis, usecase.WithSessionRepository(mockSessions))
 
 	req := &dto.LoginRequest{
-		Email:    "test@example.com",
-		Password: "WrongPassword",
+		Identifier: "testuser",
+		Password:   "SecurePass123!",
 	}
 
 	user := &entity.User{
 		ID:       "user-123",
-		Email:    req.Email,
+		Email:    "test@example.com",
+		Username: req.Identifier,
 		Password: "hashedpassword",
+		Role:     "user",
 		Status:   "active",
 	}
 
-	mockRepo.On("GetByEmail", mock.Anything, req.Email).Return(user, nil)
-	mockHasher.On("IsValid", user.Password, req.Password).Return(false)
+	mockRepo.On("GetByUsername", mock.Anything, req.Identifier).Return(user, nil)
+	mockHasher.On("IsValid", user.Password, req.Password).Return(true)
+	mockHasher.On("NeedsRehash", user.Password).Return(false)
+	mockRepo.On("UpdateLastLogin", mock.Anything, user.ID, mock.AnythingOfType("time.Time")).Return(nil)
+	mockJWT.On("GenerateAccessToken", mock.Anything, user.ID, user.Email, user.Role).Return("access-token", nil)
+	mockJWT.On("GenerateRefreshToken", user.ID, mock.Anything, mockRefreshTokenDuration).Return("refresh-token", "refresh-jti", nil)
+	expectSessionSaved(mockSessions, user.ID, "refresh-jti")
+
+	// Act
+	result, err := uc.Login(context.Background(), req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "access-token", result.AccessToken)
+
+	mockRepo.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "GetByEmail", mock.Anything, mock.Anything)
+}
+
+func TestLogin_UnknownUsername(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockHasher := new(MockPasswordHasher)
+	mockJWT := new(MockJWTManager)
+	mockRedis := new(MockRedis)
+
+	uc := usecase.NewUserUsecase(mockRepo, mockHasher, mockJWT, mockRedis)
+
+	req := &dto.LoginRequest{
+		Identifier: "ghost",
+		Password:   "SecurePass123!",
+	}
+
+	mockRepo.On("GetByUsername", mock.Anything, req.Identifier).Return(nil, sharedErrors.ErrUserNotFound)
 
 	// Act
 	result, err := uc.Login(context.Background(), req)
 
 	// Assert
-	assert.Error(t, err)
 	assert.Nil(t, result)
 	assert.True(t, errors.Is(err, sharedErrors.ErrInvalidCredentials))
 
 	mockRepo.AssertExpectations(t)
-	mockHasher.AssertExpectations(t)
+}
+
+func TestLogin_RehashesOutdatedPassword(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockHasher := new(MockPasswordHasher)
+	mockJWT := new(MockJWTManager)
+	mockRedis := new(MockRedis)
+
+	mockSessions := new(MockSessionRepository)
+	uc := usecase.NewUserUsecase(mockRepo, mockHasher, mockJWT, mockRedis, usecase.WithSessionRepository(mockSessions))
+
+	req := &dto.LoginRequest{
+		Email:    "test@example.com",
+		Password: "SecurePass123!",
+	}
+
+	user := &entity.User{
+		ID:       "user-123",
+		Email:    req.Email,
+		Username: "testuser",
+		Password: "old-cost-hash",
+		Role:     "user",
+		Status:   "active",
+	}
+
+	mockRepo.On("GetByEmail", mock.Anything, req.Email).Return(user, nil)
+	mockHasher.On("IsValid", "old-cost-hash", req.Password).Return(true)
+	mockHasher.On("NeedsRehash", "old-cost-hash").Return(true)
+	mockHasher.On("Hash", req.Password).Return("new-cost-hash", nil)
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(u *entity.User) bool {
+		return u.Password == "new-cost-hash"
+	})).Return(nil)
+	mockRepo.On("UpdateLastLogin", mock.Anything, user.ID, mock.AnythingOfType("time.Time")).Return(nil)
+	mockJWT.On("GenerateAccessToken", mock.Anything, user.ID, user.Email, user.Role).Return("access-token", nil)
+	mockJWT.On("GenerateRefreshToken", user.ID, mock.Anything, mockRefreshTokenDuration).Return("refresh-token", "refresh-jti", nil)
+	expectSessionSaved(mockSessions, user.ID, "refresh-jti")
+
+	// Act
+	result, err := uc.Login(context.Background(), req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "access-token", result.AccessToken)
+
+	mockRepo.AssertExpectations(t)
+	mockHasher.AssertExpectations(t)
+}
+
+func TestLogin_RehashFailureDoesNotBlockLogin(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockHasher := new(MockPasswordHasher)
+	mockJWT := new(MockJWTManager)
+	mockRedis := new(MockRedis)
+
+	mockSessions := new(MockSessionRepository)
+	uc := usecase.NewUserUsecase(mockRepo, mockHasher, mockJWT, mockRedis, usecase.WithSessionRepository(mockSessions))
+
+	req := &dto.LoginRequest{
+		Email:    "test@example.com",
+		Password: "SecurePass123!",
+	}
+
+	user := &entity.User{
+		ID:       "user-123",
+		Email:    req.Email,
+		Username: "testuser",
+		Password: "old-cost-hash",
+		Role:     "user",
+		Status:   "active",
+	}
+
+	mockRepo.On("GetByEmail", mock.Anything, req.Email).Return(user, nil)
+	mockHasher.On("IsValid", "old-cost-hash", req.Password).Return(true)
+	mockHasher.On("NeedsRehash", "old-cost-hash").Return(true)
+	mockHasher.On("Hash", req.Password).Return("new-cost-hash", nil)
+	mockRepo.On("Update", mock.Anything, mock.Anything).Return(errors.New("database unavailable"))
+	mockRepo.On("UpdateLastLogin", mock.Anything, user.ID, mock.AnythingOfType("time.Time")).Return(nil)
+	mockJWT.On("GenerateAccessToken", mock.Anything, user.ID, user.Email, user.Role).Return("access-token", nil)
+	mockJWT.On("GenerateRefreshToken", user.ID, mock.Anything, mockRefreshTokenDuration).Return("refresh-token", "refresh-jti", nil)
+	expectSessionSaved(mockSessions, user.ID, "refresh-jti")
+
+	// Act
+	result, err := uc.Login(context.Background(), req)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "access-token", result.AccessToken)
+	assert.Equal(t, "old-cost-hash", user.Password)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestLogin_InvalidCredentials(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockHasher := new(MockPasswordHasher)
+	mockJWT := new(MockJWTManager)
+	mockRedis := new(MockRedis)
+
+	uc := usecase.NewUserUsecase(mockRepo, mockHasher, mockJWT, mockRedis)
+
+	req := &dto.LoginRequest{
+		Email:    "test@example.com",
+		Password: "WrongPassword",
+	}
+
+	user := &entity.User{
+		ID:       "user-123",
+		Email:    req.Email,
+		Password: "hashedpassword",
+		Status:   "active",
+	}
+
+	mockRepo.On("GetByEmail", mock.Anything, req.Email).Return(user, nil)
+	mockHasher.On("IsValid", user.Password, req.Password).Return(false)
+
+	// Act
+	result, err := uc.Login(context.Background(), req)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.True(t, errors.Is(err, sharedErrors.ErrInvalidCredentials))
+
+	mockRepo.AssertExpectations(t)
+	mockHasher.AssertExpectations(t)
+}
+
+func TestLogin_RecordsLastLogin(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockHasher := new(MockPasswordHasher)
+	mockJWT := new(MockJWTManager)
+	mockRedis := new(MockRedis)
+
+	mockSessions := new(MockSessionRepository)
+	uc := usecase.NewUserUsecase(mockRepo, mockHasher, mockJWT, mockRedis, usecase.WithSessionRepository(mockSessions))
+
+	req := &dto.LoginRequest{Email: "test@example.com", Password: "SecurePass123!"}
+	user := &entity.User{ID: "user-123", Email: req.Email, Password: "hashedpassword", Role: "user", Status: "active"}
+
+	mockRepo.On("GetByEmail", mock.Anything, req.Email).Return(user, nil)
+	mockHasher.On("IsValid", user.Password, req.Password).Return(true)
+	mockHasher.On("NeedsRehash", user.Password).Return(false)
+	mockJWT.On("GenerateAccessToken", mock.Anything, user.ID, user.Email, user.Role).Return("access-token", nil)
+	mockJWT.On("GenerateRefreshToken", user.ID, mock.Anything, mockRefreshTokenDuration).Return("refresh-token", "refresh-jti", nil)
+	expectSessionSaved(mockSessions, user.ID, "refresh-jti")
+	mockRepo.On("UpdateLastLogin", mock.Anything, user.ID, mock.AnythingOfType("time.Time")).Return(nil)
+	before := time.Now()
+
+	// Act
+	result, err := uc.Login(context.Background(), req)
+
+	// Assert
+	require.NoError(t, err)
+	require.NotNil(t, result.User.LastLoginAt)
+	assert.False(t, result.User.LastLoginAt.Before(before))
+	mockRepo.AssertExpectations(t)
+}
+
+func TestLogin_LastLoginFailureDoesNotBlockLogin(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockHasher := new(MockPasswordHasher)
+	mockJWT := new(MockJWTManager)
+	mockRedis := new(MockRedis)
+
+	mockSessions := new(MockSessionRepository)
+	uc := usecase.NewUserUsecase(mockRepo, mockHasher, mockJWT, mockRedis, usecase.WithSessionRepository(mockSessions))
+
+	req := &dto.LoginRequest{Email: "test@example.com", Password: "SecurePass123!"}
+	user := &entity.User{ID: "user-123", Email: req.Email, Password: "hashedpassword", Role: "user", Status: "active"}
+
+	mockRepo.On("GetByEmail", mock.Anything, req.Email).Return(user, nil)
+	mockHasher.On("IsValid", user.Password, req.Password).Return(true)
+	mockHasher.On("NeedsRehash", user.Password).Return(false)
+	mockJWT.On("GenerateAccessToken", mock.Anything, user.ID, user.Email, user.Role).Return("access-token", nil)
+	mockJWT.On("GenerateRefreshToken", user.ID, mock.Anything, mockRefreshTokenDuration).Return("refresh-token", "refresh-jti", nil)
+	expectSessionSaved(mockSessions, user.ID, "refresh-jti")
+	mockRepo.On("UpdateLastLogin", mock.Anything, user.ID, mock.AnythingOfType("time.Time")).Return(errors.New("database unavailable"))
+
+	// Act
+	result, err := uc.Login(context.Background(), req)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "access-token", result.AccessToken)
+	assert.Nil(t, result.User.LastLoginAt)
+}
+
+func TestRefreshToken_RotatesActiveToken(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockHasher := new(MockPasswordHasher)
+	mockJWT := new(MockJWTManager)
+	mockSessions := new(MockSessionRepository)
+
+	uc := usecase.NewUserUsecase(mockRepo, mockHasher, mockJWT, new(MockRedis), usecase.WithSessionRepository(mockSessions))
+
+	user := &entity.User{
+		ID:     "user-123",
+		Email:  "test@example.com",
+		Role:   "user",
+		Status: "active",
+	}
+	session := &entity.Session{ID: "session-1", UserID: user.ID, TokenID: "old-jti", UserAgent: "Firefox"}
+
+	mockJWT.On("ValidateRefreshToken", "old-refresh-token").
+		Return(&jwt.RefreshClaims{UserID: user.ID, TokenID: "old-jti", SessionID: session.ID, Lifetime: mockRefreshTokenDuration}, nil)
+	mockSessions.On("Get", mock.Anything, user.ID, session.ID).Return(session, nil)
+	mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	mockJWT.On("GenerateAccessToken", mock.Anything, user.ID, user.Email, user.Role).Return("access-token", nil)
+	mockJWT.On("GenerateRefreshToken", user.ID, session.ID, mockRefreshTokenDuration).Return("new-refresh-token", "new-jti", nil)
+	mockSessions.On("Rotate", mock.Anything, mock.MatchedBy(func(s *entity.Session) bool {
+		return s.ID == session.ID && s.TokenID == "new-jti" && s.UserAgent == "Firefox"
+	}), "old-jti").Return(nil)
+
+	// Act
+	result, err := uc.RefreshToken(context.Background(), &dto.RefreshTokenRequest{RefreshToken: "old-refresh-token"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "new-refresh-token", result.RefreshToken)
+
+	mockRepo.AssertExpectations(t)
+	mockJWT.AssertExpectations(t)
+	mockSessions.AssertExpectations(t)
+}
+
+func TestRefreshToken_ReuseRevokesSession(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockHasher := new(MockPasswordHasher)
+	mockJWT := new(MockJWTManager)
+	mockSessions := new(MockSessionRepository)
+
+	uc := usecase.NewUserUsecase(mockRepo, mockHasher, mockJWT, new(MockRedis), usecase.WithSessionRepository(mockSessions))
+
+	mockJWT.On("ValidateRefreshToken", "rotated-refresh-token").
+		Return(&jwt.RefreshClaims{UserID: "user-123", TokenID: "rotated-jti", SessionID: "session-1", Lifetime: mockRefreshTokenDuration}, nil)
+	mockSessions.On("Get", mock.Anything, "user-123", "session-1").
+		Return(&entity.Session{ID: "session-1", UserID: "user-123", TokenID: "current-jti"}, nil)
+	mockSessions.On("Delete", mock.Anything, "user-123", "session-1").Return(nil)
+
+	// Act
+	result, err := uc.RefreshToken(context.Background(), &dto.RefreshTokenRequest{RefreshToken: "rotated-refresh-token"})
+
+	// Assert
+	assert.Nil(t, result)
+	assert.True(t, errors.Is(err, sharedErrors.ErrInvalidToken))
+
+	mockSessions.AssertExpectations(t)
+	mockRepo.AssertNotCalled(t, "GetByID", mock.Anything, mock.Anything)
+	mockJWT.AssertNotCalled(t, "GenerateRefreshToken", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRefreshToken_LosingConcurrentRotationRevokesSession(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockJWT := new(MockJWTManager)
+	mockSessions := new(MockSessionRepository)
+
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), mockJWT, new(MockRedis), usecase.WithSessionRepository(mockSessions))
+
+	user := &entity.User{ID: "user-123", Email: "test@example.com", Role: "user", Status: "active"}
+	mockJWT.On("ValidateRefreshToken", "old-refresh-token").
+		Return(&jwt.RefreshClaims{UserID: user.ID, TokenID: "old-jti", SessionID: "session-1", Lifetime: mockRefreshTokenDuration}, nil)
+	mockSessions.On("Get", mock.Anything, user.ID, "session-1").
+		Return(&entity.Session{ID: "session-1", UserID: user.ID, TokenID: "old-jti"}, nil)
+	mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	mockJWT.On("GenerateAccessToken", mock.Anything, user.ID, user.Email, user.Role).Return("access-token", nil)
+	mockJWT.On("GenerateRefreshToken", user.ID, "session-1", mockRefreshTokenDuration).Return("new-refresh-token", "new-jti", nil)
+	// Another refresh with the same token rotated the session in between.
+	mockSessions.On("Rotate", mock.Anything, mock.Anything, "old-jti").Return(sharedErrors.ErrStaleData)
+	mockSessions.On("Delete", mock.Anything, user.ID, "session-1").Return(nil)
+
+	// Act
+	result, err := uc.RefreshToken(context.Background(), &dto.RefreshTokenRequest{RefreshToken: "old-refresh-token"})
+
+	// Assert
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, sharedErrors.ErrInvalidToken)
+	mockSessions.AssertExpectations(t)
+}
+
+func TestRefreshToken_RevokedSessionIsInvalid(t *testing.T) {
+	// Arrange
+	mockJWT := new(MockJWTManager)
+	mockSessions := new(MockSessionRepository)
+
+	uc := usecase.NewUserUsecase(new(MockUserRepository), new(MockPasswordHasher), mockJWT, new(MockRedis), usecase.WithSessionRepository(mockSessions))
+
+	mockJWT.On("ValidateRefreshToken", "refresh-token").
+		Return(&jwt.RefreshClaims{UserID: "user-123", TokenID: "refresh-jti", SessionID: "session-1", Lifetime: mockRefreshTokenDuration}, nil)
+	mockSessions.On("Get", mock.Anything, "user-123", "session-1").Return(nil, sharedErrors.ErrSessionNotFound)
+
+	// Act
+	result, err := uc.RefreshToken(context.Background(), &dto.RefreshTokenRequest{RefreshToken: "refresh-token"})
+
+	// Assert
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, sharedErrors.ErrInvalidToken)
+}
+
+func TestRefreshToken_TokenWithoutSessionIsInvalid(t *testing.T) {
+	// Arrange
+	mockJWT := new(MockJWTManager)
+	mockSessions := new(MockSessionRepository)
+
+	uc := usecase.NewUserUsecase(new(MockUserRepository), new(MockPasswordHasher), mockJWT, new(MockRedis), usecase.WithSessionRepository(mockSessions))
+
+	mockJWT.On("ValidateRefreshToken", "legacy-refresh-token").
+		Return(&jwt.RefreshClaims{UserID: "user-123", TokenID: "legacy-jti", Lifetime: mockRefreshTokenDuration}, nil)
+
+	// Act
+	result, err := uc.RefreshToken(context.Background(), &dto.RefreshTokenRequest{RefreshToken: "legacy-refresh-token"})
+
+	// Assert
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, sharedErrors.ErrInvalidToken)
+	mockSessions.AssertNotCalled(t, "Get", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestLogout_EndsSessionOfActiveRefreshToken(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockHasher := new(MockPasswordHasher)
+	mockJWT := new(MockJWTManager)
+	mockSessions := new(MockSessionRepository)
+
+	uc := usecase.NewUserUsecase(mockRepo, mockHasher, mockJWT, new(MockRedis), usecase.WithSessionRepository(mockSessions))
+
+	mockJWT.On("ValidateRefreshToken", "refresh-token").
+		Return(&jwt.RefreshClaims{UserID: "user-123", TokenID: "refresh-jti", SessionID: "session-1", Lifetime: mockRefreshTokenDuration}, nil)
+	mockSessions.On("Get", mock.Anything, "user-123", "session-1").
+		Return(&entity.Session{ID: "session-1", UserID: "user-123", TokenID: "refresh-jti"}, nil)
+	mockSessions.On("Delete", mock.Anything, "user-123", "session-1").Return(nil)
+
+	// Act
+	err := uc.Logout(context.Background(), &dto.LogoutRequest{RefreshToken: "refresh-token"})
+
+	// Assert
+	assert.NoError(t, err)
+	mockSessions.AssertExpectations(t)
+}
+
+func TestLogout_RotatedTokenRevokesNothing(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockHasher := new(MockPasswordHasher)
+	mockJWT := new(MockJWTManager)
+	mockSessions := new(MockSessionRepository)
+
+	uc := usecase.NewUserUsecase(mockRepo, mockHasher, mockJWT, new(MockRedis), usecase.WithSessionRepository(mockSessions))
+
+	mockJWT.On("ValidateRefreshToken", "rotated-refresh-token").
+		Return(&jwt.RefreshClaims{UserID: "user-123", TokenID: "rotated-jti", SessionID: "session-1", Lifetime: mockRefreshTokenDuration}, nil)
+	mockSessions.On("Get", mock.Anything, "user-123", "session-1").
+		Return(&entity.Session{ID: "session-1", UserID: "user-123", TokenID: "current-jti"}, nil)
+
+	// Act
+	err := uc.Logout(context.Background(), &dto.LogoutRequest{RefreshToken: "rotated-refresh-token"})
+
+	// Assert
+	assert.NoError(t, err)
+	mockSessions.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestDeleteUser_RecordsAuditLog(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockAudit := new(MockAuditLogger)
+
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), expectStatsInvalidation(new(MockRedis)),
+		usecase.WithAuditLogger(mockAudit))
+
+	mockRepo.On("Delete", mock.Anything, "user-123").Return(nil)
+	mockAudit.On("Record", mock.Anything, "user.deleted", "user-123", map[string]interface{}(nil)).Return(nil)
+
+	// Act
+	err := uc.DeleteUser(context.Background(), "user-123")
+
+	// Assert
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockAudit.AssertExpectations(t)
+}
+
+func TestDeleteUser_AuditFailureFailsAction(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockAudit := new(MockAuditLogger)
+
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis),
+		usecase.WithAuditLogger(mockAudit))
+
+	mockRepo.On("Delete", mock.Anything, "user-123").Return(nil)
+	mockAudit.On("Record", mock.Anything, "user.deleted", "user-123", mock.Anything).Return(sharedErrors.ErrInternal)
+
+	// Act
+	err := uc.DeleteUser(context.Background(), "user-123")
+
+	// Assert
+	assert.True(t, errors.Is(err, sharedErrors.ErrInternal))
+}
+
+func TestDeleteOwnAccount_RevokesTokensAndAuditsSelfDeletion(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockHasher := new(MockPasswordHasher)
+	mockRedis := expectStatsInvalidation(new(MockRedis))
+	mockAudit := new(MockAuditLogger)
+	mockPublisher := new(MockEventPublisher)
+	mockSessions := new(MockSessionRepository)
+
+	uc := usecase.NewUserUsecase(mockRepo, mockHasher, new(MockJWTManager), mockRedis,
+		usecase.WithAuditLogger(mockAudit), usecase.WithEventPublisher(mockPublisher), usecase.WithSessionRepository(mockSessions))
+
+	user := &entity.User{ID: "user-123", Password: "hashed", Status: "active"}
+	mockRepo.On("GetByID", mock.Anything, "user-123").Return(user, nil)
+	mockHasher.On("IsValid", "hashed", "Password123!").Return(true)
+	mockRepo.On("Delete", mock.Anything, "user-123").Return(nil)
+	mockAudit.On("Record", mock.Anything, "user.self_deleted", "user-123", map[string]interface{}(nil)).Return(nil)
+	mockPublisher.On("Publish", mock.Anything, "user.deleted", mock.MatchedBy(func(event *dto.UserEvent) bool {
+		return event.UserID == "user-123"
+	})).Return(nil)
+	mockSessions.On("DeleteAll", mock.Anything, "user-123").Return(nil)
+
+	// Act
+	err := uc.DeleteOwnAccount(context.Background(), "user-123", &dto.DeleteAccountRequest{Password: "Password123!"})
+
+	// Assert
+	assert.NoError(t, err)
+	mockRepo.AssertExpectations(t)
+	mockAudit.AssertExpectations(t)
+	mockPublisher.AssertExpectations(t)
+	mockRedis.AssertExpectations(t)
+	mockSessions.AssertExpectations(t)
+}
+
+func TestDeleteOwnAccount_WrongPassword(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockHasher := new(MockPasswordHasher)
+	uc := usecase.NewUserUsecase(mockRepo, mockHasher, new(MockJWTManager), new(MockRedis))
+
+	user := &entity.User{ID: "user-123", Password: "hashed", Status: "active"}
+	mockRepo.On("GetByID", mock.Anything, "user-123").Return(user, nil)
+	mockHasher.On("IsValid", "hashed", "wrong").Return(false)
+
+	// Act
+	err := uc.DeleteOwnAccount(context.Background(), "user-123", &dto.DeleteAccountRequest{Password: "wrong"})
+
+	// Assert
+	assert.True(t, errors.Is(err, sharedErrors.ErrInvalidPassword))
+	mockRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+}
+
+func TestChangeUserStatus_RecordsTransition(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockAudit := new(MockAuditLogger)
+
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), expectStatsInvalidation(new(MockRedis)),
+		usecase.WithAuditLogger(mockAudit))
+
+	user := &entity.User{ID: "user-123", Email: "test@example.com", Status: "active"}
+
+	mockRepo.On("GetByID", mock.Anything, "user-123").Return(user, nil)
+	mockRepo.On("Update", mock.Anything, user).Return(nil)
+	mockAudit.On("Record", mock.Anything, "user.status_changed", "user-123", map[string]interface{}{
+		"from": "active",
+		"to":   "banned",
+	}).Return(nil)
+
+	// Act
+	result, err := uc.ChangeUserStatus(context.Background(), "user-123", "banned")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "banned", result.Status)
+	mockAudit.AssertExpectations(t)
+}
+
+func TestChangeUserStatus_PublishesUpdatedEvent(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockPublisher := new(MockEventPublisher)
+
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), expectStatsInvalidation(new(MockRedis)),
+		usecase.WithEventPublisher(mockPublisher))
+
+	user := &entity.User{ID: "user-123", Email: "test@example.com", Status: "active"}
+
+	mockRepo.On("GetByID", mock.Anything, "user-123").Return(user, nil)
+	mockRepo.On("Update", mock.Anything, user).Return(nil)
+	mockPublisher.On("Publish", mock.Anything, "user.updated", mock.MatchedBy(func(event *dto.UserEvent) bool {
+		return event.Type == "user.updated" && event.UserID == "user-123" && event.Status == "banned"
+	})).Return(nil)
+
+	// Act
+	_, err := uc.ChangeUserStatus(context.Background(), "user-123", "banned")
+
+	// Assert
+	assert.NoError(t, err)
+	mockPublisher.AssertExpectations(t)
+}
+
+func TestDeleteUser_PublishFailureDoesNotFailAction(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockPublisher := new(MockEventPublisher)
+
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), expectStatsInvalidation(new(MockRedis)),
+		usecase.WithEventPublisher(mockPublisher))
+
+	mockRepo.On("Delete", mock.Anything, "user-123").Return(nil)
+	mockPublisher.On("Publish", mock.Anything, "user.deleted", mock.Anything).Return(sharedErrors.ErrInternal)
+
+	// Act
+	err := uc.DeleteUser(context.Background(), "user-123")
+
+	// Assert
+	assert.NoError(t, err)
+	mockPublisher.AssertExpectations(t)
+}
+
+func TestChangeUserStatus_StaleVersionConflicts(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis))
+
+	user := &entity.User{ID: "user-123", Email: "test@example.com", Status: "active", Version: 3}
+
+	mockRepo.On("GetByID", mock.Anything, "user-123").Return(user, nil)
+	mockRepo.On("Update", mock.Anything, user).Return(sharedErrors.ErrStaleData)
+
+	// Act
+	result, err := uc.ChangeUserStatus(context.Background(), "user-123", "banned")
+
+	// Assert
+	assert.Nil(t, result)
+	assert.True(t, errors.Is(err, sharedErrors.ErrStaleData))
+}
+
+func TestUpdateProfile_StaleVersionConflicts(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis))
+
+	user := entity.NewUser("test@example.com", "testuser", "hashed", "Test User", "user")
+
+	mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	mockRepo.On("Update", mock.Anything, user).Return(sharedErrors.ErrStaleData)
+
+	// Act
+	result, err := uc.UpdateProfile(context.Background(), user.ID, &dto.UpdateProfileRequest{FullName: "Renamed"})
+
+	// Assert
+	assert.Equal(t, 1, user.Version)
+	assert.Nil(t, result)
+	assert.True(t, errors.Is(err, sharedErrors.ErrStaleData))
+}
+
+func TestUpdateProfile_ChangesEmailAndUsername(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis))
+
+	user := entity.NewUser("test@example.com", "testuser", "hashed", "Test User", "user")
+	req := &dto.UpdateProfileRequest{Email: "new@example.com", Username: "newuser"}
+
+	mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	mockRepo.On("ExistsByEmail", mock.Anything, req.Email).Return(false, nil)
+	mockRepo.On("ExistsByUsername", mock.Anything, req.Username).Return(false, nil)
+	mockRepo.On("Update", mock.Anything, user).Return(nil)
+
+	// Act
+	result, err := uc.UpdateProfile(context.Background(), user.ID, req)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "new@example.com", result.Email)
+	assert.Equal(t, "newuser", result.Username)
+	assert.Equal(t, "Test User", result.FullName)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateProfile_UnchangedEmailSkipsUniquenessCheck(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis))
+
+	user := entity.NewUser("test@example.com", "testuser", "hashed", "Test User", "user")
+	req := &dto.UpdateProfileRequest{Email: user.Email, Username: user.Username, FullName: "Renamed"}
+
+	mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	mockRepo.On("Update", mock.Anything, user).Return(nil)
+
+	// Act
+	result, err := uc.UpdateProfile(context.Background(), user.ID, req)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed", result.FullName)
+	mockRepo.AssertNotCalled(t, "ExistsByEmail", mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "ExistsByUsername", mock.Anything, mock.Anything)
+}
+
+func TestUpdateProfile_SetsTimezoneAndLocale(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis))
+
+	user := entity.NewUser("test@example.com", "testuser", "hashed", "Test User", "user")
+	user.Locale = "en-US"
+	req := &dto.UpdateProfileRequest{Timezone: "Asia/Jakarta"}
+
+	mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	mockRepo.On("Update", mock.Anything, mock.MatchedBy(func(u *entity.User) bool {
+		return u.Timezone == "Asia/Jakarta" && u.Locale == "en-US"
+	})).Return(nil)
+
+	// Act
+	result, err := uc.UpdateProfile(context.Background(), user.ID, req)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "Asia/Jakarta", result.Timezone)
+	assert.Equal(t, "en-US", result.Locale)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUpdateProfile_EmailTaken(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis))
+
+	user := entity.NewUser("test@example.com", "testuser", "hashed", "Test User", "user")
+
+	mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	mockRepo.On("ExistsByEmail", mock.Anything, "taken@example.com").Return(true, nil)
+
+	// Act
+	result, err := uc.UpdateProfile(context.Background(), user.ID, &dto.UpdateProfileRequest{Email: "taken@example.com"})
+
+	// Assert
+	assert.Nil(t, result)
+	assert.True(t, errors.Is(err, sharedErrors.ErrEmailAlreadyExists))
+	assert.Equal(t, "test@example.com", user.Email)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestUpdateProfile_UsernameTaken(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis))
+
+	user := entity.NewUser("test@example.com", "testuser", "hashed", "Test User", "user")
+
+	mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	mockRepo.On("ExistsByUsername", mock.Anything, "taken").Return(true, nil)
+
+	// Act
+	result, err := uc.UpdateProfile(context.Background(), user.ID, &dto.UpdateProfileRequest{Username: "taken"})
+
+	// Assert
+	assert.Nil(t, result)
+	assert.True(t, errors.Is(err, sharedErrors.ErrUsernameAlreadyExists))
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+}
+
+func TestRestoreUser_Success(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockAudit := new(MockAuditLogger)
+
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), expectStatsInvalidation(new(MockRedis)),
+		usecase.WithAuditLogger(mockAudit))
+
+	deletedAt := time.Now()
+	user := &entity.User{ID: "user-123", Email: "test@example.com", Status: "inactive", DeletedAt: &deletedAt}
+
+	mockRepo.On("GetByIDIncludingDeleted", mock.Anything, "user-123").Return(user, nil)
+	mockRepo.On("Restore", mock.Anything, "user-123").Return(nil)
+	mockAudit.On("Record", mock.Anything, "user.restored", "user-123", map[string]interface{}(nil)).Return(nil)
+
+	// Act
+	result, err := uc.RestoreUser(context.Background(), "user-123")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "active", result.Status)
+	mockRepo.AssertExpectations(t)
+	mockAudit.AssertExpectations(t)
+}
+
+func TestRestoreUser_EmailTakenSinceDeletionConflicts(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockAudit := new(MockAuditLogger)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis),
+		usecase.WithAuditLogger(mockAudit))
+
+	deletedAt := time.Now()
+	user := &entity.User{ID: "user-123", Email: "test@example.com", Status: "inactive", DeletedAt: &deletedAt}
+
+	mockRepo.On("GetByIDIncludingDeleted", mock.Anything, "user-123").Return(user, nil)
+	mockRepo.On("Restore", mock.Anything, "user-123").Return(sharedErrors.ErrEmailAlreadyExists)
+
+	// Act
+	result, err := uc.RestoreUser(context.Background(), "user-123")
+
+	// Assert
+	assert.Nil(t, result)
+	assert.ErrorIs(t, err, sharedErrors.ErrEmailAlreadyExists)
+	mockAudit.AssertNotCalled(t, "Record", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRestoreUser_NotDeletedIsNoop(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis))
+
+	user := &entity.User{ID: "user-123", Email: "test@example.com", Status: "active"}
+	mockRepo.On("GetByIDIncludingDeleted", mock.Anything, "user-123").Return(user, nil)
+
+	// Act
+	result, err := uc.RestoreUser(context.Background(), "user-123")
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "user-123", result.ID)
+	mockRepo.AssertNotCalled(t, "Restore", mock.Anything, mock.Anything)
+}
+
+func TestRestoreUser_NotFound(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis))
+
+	mockRepo.On("GetByIDIncludingDeleted", mock.Anything, "missing").Return(nil, sharedErrors.ErrUserNotFound)
+
+	// Act
+	result, err := uc.RestoreUser(context.Background(), "missing")
+
+	// Assert
+	assert.Nil(t, result)
+	assert.True(t, errors.Is(err, sharedErrors.ErrUserNotFound))
+}
+
+func TestLogin_RememberMeExtendsRefreshToken(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockHasher := new(MockPasswordHasher)
+	mockJWT := new(MockJWTManager)
+	mockRedis := new(MockRedis)
+
+	mockSessions := new(MockSessionRepository)
+	uc := usecase.NewUserUsecase(mockRepo, mockHasher, mockJWT, mockRedis, usecase.WithSessionRepository(mockSessions))
+
+	req := &dto.LoginRequest{
+		Email:      "test@example.com",
+		Password:   "SecurePass123!",
+		RememberMe: true,
+	}
+	user := &entity.User{ID: "user-123", Email: req.Email, Password: "hashedpassword", Role: "user", Status: "active"}
+
+	mockRepo.On("GetByEmail", mock.Anything, req.Email).Return(user, nil)
+	mockHasher.On("IsValid", user.Password, req.Password).Return(true)
+	mockHasher.On("NeedsRehash", user.Password).Return(false)
+	mockRepo.On("UpdateLastLogin", mock.Anything, user.ID, mock.AnythingOfType("time.Time")).Return(nil)
+	mockJWT.On("GenerateAccessToken", mock.Anything, user.ID, user.Email, user.Role).Return("access-token", nil)
+	mockJWT.On("GenerateRefreshToken", user.ID, mock.Anything, mockRememberMeRefreshTokenDuration).Return("refresh-token", "refresh-jti", nil)
+	expectSessionSaved(mockSessions, user.ID, "refresh-jti")
+
+	// Act
+	result, err := uc.Login(context.Background(), req)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "refresh-token", result.RefreshToken)
+	mockJWT.AssertExpectations(t)
+	mockSessions.AssertExpectations(t)
+}
+
+func TestRefreshToken_KeepsRememberMeLifetime(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	mockJWT := new(MockJWTManager)
+	mockSessions := new(MockSessionRepository)
+
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), mockJWT, new(MockRedis), usecase.WithSessionRepository(mockSessions))
+
+	user := &entity.User{ID: "user-123", Email: "test@example.com", Role: "user", Status: "active"}
+
+	mockJWT.On("ValidateRefreshToken", "old-refresh-token").
+		Return(&jwt.RefreshClaims{UserID: user.ID, TokenID: "old-jti", SessionID: "session-1", Lifetime: mockRememberMeRefreshTokenDuration}, nil)
+	mockSessions.On("Get", mock.Anything, user.ID, "session-1").
+		Return(&entity.Session{ID: "session-1", UserID: user.ID, TokenID: "old-jti"}, nil)
+	mockRepo.On("GetByID", mock.Anything, user.ID).Return(user, nil)
+	mockJWT.On("GenerateAccessToken", mock.Anything, user.ID, user.Email, user.Role).Return("access-token", nil)
+	mockJWT.On("GenerateRefreshToken", user.ID, "session-1", mockRememberMeRefreshTokenDuration).Return("new-refresh-token", "new-jti", nil)
+	expectSessionRotated(mockSessions, user.ID, "old-jti", "new-jti")
+
+	// Act
+	result, err := uc.RefreshToken(context.Background(), &dto.RefreshTokenRequest{RefreshToken: "old-refresh-token"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "new-refresh-token", result.RefreshToken)
+	mockJWT.AssertExpectations(t)
+	mockSessions.AssertExpectations(t)
+}
+
+func TestGetProfile_TimestampsAreUTC(t *testing.T) {
+	// Arrange
+	mockRepo := new(MockUserRepository)
+	uc := usecase.NewUserUsecase(mockRepo, new(MockPasswordHasher), new(MockJWTManager), new(MockRedis))
+
+	jakarta := time.FixedZone("WIB", 7*60*60)
+	created := time.Date(2026, 3, 1, 9, 30, 0, 0, jakarta)
+	lastLogin := created.Add(time.Hour)
+	user := &entity.User{ID: "user-123", Status: "active", CreatedAt: created, UpdatedAt: created, LastLoginAt: &lastLogin}
+	mockRepo.On("GetByID", mock.Anything, "user-123").Return(user, nil)
+
+	// Act
+	result, err := uc.GetProfile(context.Background(), "user-123")
+
+	// Assert
+	require.NoError(t, err)
+	body, err := json.Marshal(result)
+	require.NoError(t, err)
+	assert.Contains(t, string(body), `"created_at":"2026-03-01T02:30:00Z"`)
+	assert.Contains(t, string(body), `"last_login_at":"2026-03-01T03:30:00Z"`)
+	assert.Equal(t, jakarta, user.LastLoginAt.Location(), "the entity must not be modified")
 }
@@ -0,0 +1,216 @@
+package usecase_test
+
+import (
+	"testing"
+
+	"github.com/TubagusAldiMY/go-template/internal/domain/user/dto"
+	"github.com/TubagusAldiMY/go-template/internal/shared/constants"
+	"github.com/TubagusAldiMY/go-template/pkg/validator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatValidationErrors_UsesJSONFieldNames(t *testing.T) {
+	// Arrange
+	require.NoError(t, validator.Init())
+	req := &dto.RegisterRequest{
+		Email:    "john@example.com",
+		Username: "john_doe",
+		Password: "Password123!",
+		FullName: "J",
+	}
+
+	// Act
+	errs := validator.FormatValidationErrors(validator.Validate(req))
+
+	// Assert
+	assert.Equal(t, map[string]string{
+		"full_name": "full_name must be at least 2 characters",
+	}, errs)
+}
+
+func TestFormatValidationErrors_NestedPaths(t *testing.T) {
+	// Arrange
+	require.NoError(t, validator.Init())
+	type contact struct {
+		Email string `json:"email" validate:"required,email"`
+	}
+	type request struct {
+		Owner contact   `json:"owner"`
+		Items []contact `json:"items" validate:"dive"`
+	}
+	req := &request{
+		Owner: contact{Email: "owner@example.com"},
+		Items: []contact{{Email: "ok@example.com"}, {Email: "not-an-email"}, {}},
+	}
+
+	// Act
+	errs := validator.FormatValidationErrors(validator.Validate(req))
+
+	// Assert
+	assert.Equal(t, map[string]string{
+		"items[1].email": "invalid email format",
+		"items[2].email": "items[2].email is required",
+	}, errs)
+}
+
+func TestValidate_ProfileTimezoneAndLocale(t *testing.T) {
+	require.NoError(t, validator.Init())
+
+	tests := []struct {
+		name     string
+		timezone string
+		locale   string
+		want     map[string]string
+	}{
+		{name: "unset", want: map[string]string{}},
+		{name: "valid", timezone: "Asia/Jakarta", locale: "id-ID", want: map[string]string{}},
+		{name: "utc and script subtag", timezone: "UTC", locale: "zh-Hant-TW", want: map[string]string{}},
+		{name: "numeric region", timezone: "America/New_York", locale: "es-419", want: map[string]string{}},
+		{
+			name:     "unknown zone and malformed tag",
+			timezone: "Mars/Olympus_Mons",
+			locale:   "english_US",
+			want: map[string]string{
+				"timezone": "timezone must be an IANA time zone name such as Asia/Jakarta",
+				"locale":   "locale must be a BCP-47 language tag such as id-ID",
+			},
+		},
+		{
+			name:     "server local zone",
+			timezone: "Local",
+			want: map[string]string{
+				"timezone": "timezone must be an IANA time zone name such as Asia/Jakarta",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			req := &dto.UpdateProfileRequest{Timezone: tt.timezone, Locale: tt.locale}
+
+			// Act
+			errs := validator.FormatValidationErrors(validator.Validate(req))
+
+			// Assert
+			assert.Equal(t, tt.want, errs)
+		})
+	}
+}
+
+func TestPasswordPolicy_Allows(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   validator.PasswordPolicy
+		password string
+		want     bool
+	}{
+		{"default accepts strong password", validator.DefaultPasswordPolicy(), "Str0ng!pass", true},
+		{"default rejects missing special", validator.DefaultPasswordPolicy(), "Str0ngpass", false},
+		{"default rejects short password", validator.DefaultPasswordPolicy(), "S0!a", false},
+		{"classes can be disabled", validator.PasswordPolicy{MinLength: 12}, "correct horse battery", true},
+		{"max length", validator.PasswordPolicy{MinLength: 1, MaxLength: 5}, "abcdef", false},
+		{"length counts characters", validator.PasswordPolicy{MinLength: 4, MaxLength: 4}, "über", true},
+		{"common password", validator.PasswordPolicy{MinLength: 8, DisallowCommon: true}, "Password123", false},
+		{"uncommon password", validator.PasswordPolicy{MinLength: 8, DisallowCommon: true}, "violet-harbor-42", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := tt.policy.Allows(tt.password)
+
+			// Assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestInitWithPolicy_ReportsPolicyInMessage(t *testing.T) {
+	// Arrange
+	require.NoError(t, validator.InitWithPolicy(validator.PasswordPolicy{
+		MinLength:      12,
+		RequireDigit:   true,
+		DisallowCommon: true,
+	}))
+	t.Cleanup(func() { require.NoError(t, validator.Init()) })
+	req := &dto.RegisterRequest{
+		Email:    "john@example.com",
+		Username: "john_doe",
+		Password: "Password123!",
+		FullName: "John Doe",
+	}
+
+	// Act
+	errs := validator.FormatValidationErrors(validator.Validate(req))
+
+	// Assert
+	assert.Equal(t, map[string]string{
+		"password": "password must be at least 12 characters and contain digit, and must not be a commonly used password",
+	}, errs)
+}
+
+func TestValidate_ListUsersRoleFromPolicyAndStatusFromConstants(t *testing.T) {
+	require.NoError(t, validator.Init())
+	initPolicy(t, "admin=*;moderator=user:read;user=")
+
+	tests := []struct {
+		name   string
+		role   string
+		status string
+		want   map[string]string
+	}{
+		{name: "unset", want: map[string]string{}},
+		{name: "every role and status", role: constants.RoleAdmin, status: constants.UserStatusBanned, want: map[string]string{}},
+		{name: "role added by the policy", role: "moderator", want: map[string]string{}},
+		{
+			name:   "unknown role and status",
+			role:   "superuser",
+			status: "deleted",
+			want: map[string]string{
+				"role":   "role must be one of: admin moderator user",
+				"status": "status must be one of: active inactive banned",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			req := &dto.ListUsersRequest{Role: tt.role, Status: tt.status}
+
+			// Act
+			errs := validator.FormatValidationErrors(validator.Validate(req))
+
+			// Assert
+			assert.Equal(t, tt.want, errs)
+		})
+	}
+}
+
+func TestValidate_ListUsersSortBy(t *testing.T) {
+	require.NoError(t, validator.Init())
+
+	tests := []struct {
+		sortBy string
+		valid  bool
+	}{
+		{sortBy: "", valid: true},
+		{sortBy: "email", valid: true},
+		{sortBy: "created_at", valid: true},
+		{sortBy: "updated_at", valid: true},
+		{sortBy: "password", valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sortBy, func(t *testing.T) {
+			// Act
+			errs := validator.FormatValidationErrors(validator.Validate(&dto.ListUsersRequest{SortBy: tt.sortBy}))
+
+			// Assert
+			_, invalid := errs["sort_by"]
+			assert.Equal(t, !tt.valid, invalid)
+		})
+	}
+}